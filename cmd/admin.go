@@ -178,6 +178,11 @@ var (
 				Usage: "Token name",
 				Value: "gitea-admin",
 			},
+			cli.StringFlag{
+				Name:  "scopes",
+				Usage: `Comma separated scopes of the token, "all" or a level and a category like "read:repository"`,
+				Value: "all",
+			},
 			cli.BoolFlag{
 				Name:  "raw",
 				Usage: "Display only the token value",
@@ -594,8 +599,9 @@ func runCreateUser(c *cli.Context) error {
 
 	if c.Bool("access-token") {
 		t := &models.AccessToken{
-			Name: "gitea-admin",
-			UID:  u.ID,
+			Name:  "gitea-admin",
+			UID:   u.ID,
+			Scope: auth.AccessTokenScopeAll,
 		}
 
 		if err := models.NewAccessToken(t); err != nil {
@@ -700,9 +706,18 @@ func runGenerateAccessToken(c *cli.Context) error {
 		return err
 	}
 
+	scope, err := auth.ParseAccessTokenScope(strings.Split(c.String("scopes"), ","))
+	if err != nil {
+		return err
+	}
+	if scope == "" {
+		return fmt.Errorf("You must provide at least one scope for the token")
+	}
+
 	t := &models.AccessToken{
-		Name: c.String("token-name"),
-		UID:  user.ID,
+		Name:  c.String("token-name"),
+		UID:   user.ID,
+		Scope: scope,
 	}
 
 	if err := models.NewAccessToken(t); err != nil {
@@ -0,0 +1,152 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/ci/runner"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/urfave/cli"
+)
+
+// CmdCIRunner represents the available ci-runner sub-command
+var CmdCIRunner = cli.Command{
+	Name:        "ci-runner",
+	Usage:       "Run the jobs of the CI as local processes",
+	Description: "A simple runner executing the steps of the jobs with the shell of this machine. It has no isolation, only use it for trusted workflows.",
+	Subcommands: []cli.Command{
+		subcmdCIRunnerRegister,
+		subcmdCIRunnerRun,
+	},
+}
+
+var ciRunnerConfigFlag = cli.StringFlag{
+	Name:  "config, c",
+	Value: ".runner",
+	Usage: "File the registration of the runner is saved to",
+}
+
+var subcmdCIRunnerRegister = cli.Command{
+	Name:   "register",
+	Usage:  "Register the runner with a registration token",
+	Action: runCIRunnerRegister,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "instance",
+			Usage: "Root URL of the Gitea instance",
+		},
+		cli.StringFlag{
+			Name:  "token",
+			Usage: "Registration token of a repository or of the instance",
+		},
+		cli.StringFlag{
+			Name:  "name",
+			Usage: "Name of the runner, defaults to the host name",
+		},
+		cli.StringFlag{
+			Name:  "labels",
+			Usage: "Comma separated labels the jobs can ask for with runs-on",
+		},
+		ciRunnerConfigFlag,
+	},
+}
+
+var subcmdCIRunnerRun = cli.Command{
+	Name:   "run",
+	Usage:  "Fetch and run jobs until interrupted",
+	Action: runCIRunnerRun,
+	Flags: []cli.Flag{
+		ciRunnerConfigFlag,
+		cli.StringFlag{
+			Name:  "workdir, w",
+			Value: os.TempDir(),
+			Usage: "Directory the jobs are run in",
+		},
+		cli.DurationFlag{
+			Name:  "poll-interval",
+			Value: 10 * time.Second,
+			Usage: "Interval to ask for new jobs",
+		},
+		cli.BoolFlag{
+			Name:  "once",
+			Usage: "Run a single job if there is one and exit",
+		},
+	},
+}
+
+func setupCIRunnerLogger() {
+	log.DelNamedLogger(log.DEFAULT)
+	log.NewLogger(1000, "console", "console", `{"level":"info","stacktracelevel":"NONE","colorize":false}`)
+}
+
+func runCIRunnerRegister(c *cli.Context) error {
+	if !c.IsSet("instance") || !c.IsSet("token") {
+		return errors.New("--instance and --token are required")
+	}
+	setupCIRunnerLogger()
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	name := c.String("name")
+	if name == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		name = hostname
+	}
+	labels := make([]string, 0, 5)
+	for _, label := range strings.Split(c.String("labels"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	cfg, err := runner.Register(ctx, c.String("instance"), c.String("token"), name, labels)
+	if err != nil {
+		return fmt.Errorf("unable to register the runner: %v", err)
+	}
+	buf, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.String("config"), buf, 0o600); err != nil {
+		return err
+	}
+	fmt.Printf("Runner %s has been registered, its registration is saved to %s\n", cfg.Name, c.String("config"))
+	return nil
+}
+
+func runCIRunnerRun(c *cli.Context) error {
+	setupCIRunnerLogger()
+
+	buf, err := os.ReadFile(c.String("config"))
+	if err != nil {
+		return fmt.Errorf("unable to read the registration of the runner, register it first: %v", err)
+	}
+	cfg := &runner.Config{}
+	if err := json.Unmarshal(buf, cfg); err != nil {
+		return err
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	r := runner.New(cfg, c.String("workdir"))
+	if c.Bool("once") {
+		_, err := r.RunOnce(ctx)
+		return err
+	}
+	log.Info("Runner %s is waiting for jobs of %s", cfg.Name, cfg.URL)
+	return r.Run(ctx, c.Duration("poll-interval"))
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAdminAuditEvents(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	collaboratorURL := fmt.Sprintf("/api/v1/repos/user2/repo1/collaborators/user4?token=%s", token)
+	req := NewRequestWithJSON(t, "PUT", collaboratorURL, &api.AddCollaboratorOption{Permission: &[]string{"write"}[0]})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestWithJSON(t, "PUT", collaboratorURL, &api.AddCollaboratorOption{Permission: &[]string{"admin"}[0]})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "DELETE", collaboratorURL)
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	t.Run("NoAdmin", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestf(t, "GET", "/api/v1/admin/audit?token=%s", token)
+		session.MakeRequest(t, req, http.StatusForbidden)
+	})
+
+	adminSession := loginUser(t, "user1")
+	adminToken := getTokenForLoggedInUser(t, adminSession)
+
+	t.Run("Collaborators", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestf(t, "GET", "/api/v1/admin/audit?actor=user2&scope_type=repository&scope_id=1&token=%s", adminToken)
+		resp := adminSession.MakeRequest(t, req, http.StatusOK)
+
+		var events []*api.AuditEvent
+		DecodeJSON(t, resp, &events)
+		assert.Len(t, events, 3)
+		assert.Equal(t, "3", resp.Header().Get("X-Total-Count"))
+
+		assert.EqualValues(t, audit_model.ActionCollaboratorRemove, events[0].Action)
+		assert.EqualValues(t, audit_model.ActionCollaboratorPermission, events[1].Action)
+		assert.EqualValues(t, audit_model.ActionCollaboratorAdd, events[2].Action)
+		for _, e := range events {
+			assert.EqualValues(t, 2, e.ActorID)
+			assert.Equal(t, "user2/repo1", e.Scope.Name)
+			assert.Equal(t, "user", e.Target.Type)
+			assert.Equal(t, "user4", e.Target.Name)
+		}
+		assert.Empty(t, events[2].Before)
+		assert.JSONEq(t, `{"permission":"write"}`, events[2].After)
+		assert.JSONEq(t, `{"permission":"write"}`, events[1].Before)
+		assert.JSONEq(t, `{"permission":"admin"}`, events[1].After)
+		assert.JSONEq(t, `{"permission":"admin"}`, events[0].Before)
+		assert.Empty(t, events[0].After)
+	})
+
+	t.Run("AccessToken", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestf(t, "GET", "/api/v1/admin/audit?action=access_token_create&actor=user2&token=%s", adminToken)
+		resp := adminSession.MakeRequest(t, req, http.StatusOK)
+
+		var events []*api.AuditEvent
+		DecodeJSON(t, resp, &events)
+		assert.Len(t, events, 1)
+		assert.Equal(t, "access_token", events[0].Target.Type)
+		assert.Equal(t, "user2", events[0].Scope.Name)
+	})
+
+	t.Run("InvalidAction", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestf(t, "GET", "/api/v1/admin/audit?action=unknown&token=%s", adminToken)
+		adminSession.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("AdminPage", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", "/admin/audit?action=collaborator_add&q=user4")
+		resp := adminSession.MakeRequest(t, req, http.StatusOK)
+
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(".admin.audit table tbody tr").Length())
+
+		req = NewRequest(t, "GET", "/admin/audit")
+		session.MakeRequest(t, req, http.StatusForbidden)
+	})
+}
+
+func TestAPIAdminAuditOrgMemberRemove(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// user4 is a member of a team of the organization user3
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "DELETE", "/api/v1/orgs/user3/members/user4?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	adminSession := loginUser(t, "user1")
+	adminToken := getTokenForLoggedInUser(t, adminSession)
+	req = NewRequestf(t, "GET", "/api/v1/admin/audit?action=team_member_remove&actor=user2&token=%s", adminToken)
+	resp := adminSession.MakeRequest(t, req, http.StatusOK)
+
+	var events []*api.AuditEvent
+	DecodeJSON(t, resp, &events)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "user3", events[0].Scope.Name)
+		assert.Equal(t, "user4", events[0].Target.Name)
+		assert.JSONEq(t, `{"team":"team1","permission":"write"}`, events[0].Before)
+		assert.Empty(t, events[0].After)
+	}
+}
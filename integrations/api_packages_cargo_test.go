@@ -0,0 +1,226 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	cargo_module "code.gitea.io/gitea/modules/packages/cargo"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageCargo(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	token := "Bearer " + getUserToken(t, user.Name)
+
+	packageName := "test-crate"
+	packageVersion := "1.0.3"
+	packageDescription := "Test Description"
+	content := []byte("crate content")
+
+	createPublishRequest := func(version string) []byte {
+		metadata := `{"name":"` + packageName + `","vers":"` + version + `","description":"` + packageDescription + `","deps":[{"name":"dep","version_req":"^1.0","features":[],"optional":false,"default_features":true,"target":null,"kind":"normal","registry":null}],"features":{"default":[]}}`
+
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+		buf.WriteString(metadata)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(content)))
+		buf.Write(content)
+		return buf.Bytes()
+	}
+
+	root := fmt.Sprintf("/api/packages/%s/cargo", user.Name)
+
+	type IndexEntry struct {
+		Name     string                     `json:"name"`
+		Version  string                     `json:"vers"`
+		Deps     []*cargo_module.Dependency `json:"deps"`
+		Checksum string                     `json:"cksum"`
+		Features map[string][]string        `json:"features"`
+		Yanked   bool                       `json:"yanked"`
+	}
+
+	getIndex := func(t *testing.T) []*IndexEntry {
+		req := NewRequest(t, "GET", root+"/te/st/"+packageName)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var entries []*IndexEntry
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var entry IndexEntry
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			entries = append(entries, &entry)
+		}
+		return entries
+	}
+
+	t.Run("Config", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", root+"/config.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var config struct {
+			DownloadURL  string `json:"dl"`
+			APIURL       string `json:"api"`
+			AuthRequired bool   `json:"auth-required"`
+		}
+		DecodeJSON(t, resp, &config)
+
+		assert.Equal(t, setting.AppURL+root[1:]+"/api/v1/crates", config.DownloadURL)
+		assert.Equal(t, setting.AppURL+root[1:], config.APIURL)
+		assert.False(t, config.AuthRequired)
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		url := root + "/api/v1/crates/new"
+
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader(createPublishRequest(packageVersion)))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(createPublishRequest(packageVersion)[:10]))
+		addTokenAuthHeader(req, token)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(createPublishRequest(packageVersion)))
+		addTokenAuthHeader(req, token)
+		MakeRequest(t, req, http.StatusOK)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeCargo)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.NotNil(t, pd.SemVer)
+		assert.IsType(t, &cargo_module.Metadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+		assert.Equal(t, packageDescription, pd.Metadata.(*cargo_module.Metadata).Description)
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, fmt.Sprintf("%s-%s.crate", packageName, packageVersion), pfs[0].Name)
+		assert.True(t, pfs[0].IsLead)
+
+		pb, err := packages.GetBlobByID(db.DefaultContext, pfs[0].BlobID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), pb.Size)
+
+		req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(createPublishRequest(packageVersion)))
+		addTokenAuthHeader(req, token)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		entries := getIndex(t)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, packageName, entries[0].Name)
+		assert.Equal(t, packageVersion, entries[0].Version)
+		assert.Len(t, entries[0].Checksum, 64)
+		assert.False(t, entries[0].Yanked)
+		assert.Contains(t, entries[0].Features, "default")
+		if assert.Len(t, entries[0].Deps, 1) {
+			assert.Equal(t, "dep", entries[0].Deps[0].Name)
+			assert.Equal(t, "^1.0", entries[0].Deps[0].Req)
+		}
+
+		req := NewRequest(t, "GET", root+"/xx/yy/"+packageName)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", root+"/un/kn/unknown")
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/api/v1/crates/%s/%s/download", root, packageName, packageVersion))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, content, resp.Body.Bytes())
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeCargo)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+		assert.Equal(t, int64(1), pvs[0].DownloadCount)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", root+"/api/v1/crates?q=test")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result struct {
+			Crates []struct {
+				Name       string `json:"name"`
+				MaxVersion string `json:"max_version"`
+			} `json:"crates"`
+			Meta struct {
+				Total int64 `json:"total"`
+			} `json:"meta"`
+		}
+		DecodeJSON(t, resp, &result)
+
+		assert.EqualValues(t, 1, result.Meta.Total)
+		if assert.Len(t, result.Crates, 1) {
+			assert.Equal(t, packageName, result.Crates[0].Name)
+			assert.Equal(t, packageVersion, result.Crates[0].MaxVersion)
+		}
+	})
+
+	t.Run("Yank", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		url := fmt.Sprintf("%s/api/v1/crates/%s/%s", root, packageName, packageVersion)
+
+		req := NewRequest(t, "DELETE", url+"/yank")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", fmt.Sprintf("%s/api/v1/crates/%s/0.0.0/yank", root, packageName))
+		addTokenAuthHeader(req, token)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "DELETE", url+"/yank")
+		addTokenAuthHeader(req, token)
+		MakeRequest(t, req, http.StatusOK)
+
+		entries := getIndex(t)
+		assert.Len(t, entries, 1)
+		assert.True(t, entries[0].Yanked)
+
+		// yanked versions can still be downloaded
+		req = NewRequest(t, "GET", url+"/download")
+		MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "PUT", url+"/unyank")
+		addTokenAuthHeader(req, token)
+		MakeRequest(t, req, http.StatusOK)
+
+		entries = getIndex(t)
+		assert.Len(t, entries, 1)
+		assert.False(t, entries[0].Yanked)
+	})
+}
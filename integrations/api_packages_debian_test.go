@@ -0,0 +1,229 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/clearsign"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageDebian(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	packageName := "gitea"
+	packageVersion := "1.0.3"
+	packageDescription := "Package Description"
+
+	createPackage := func(name, version, architecture string) []byte {
+		var cbuf bytes.Buffer
+		zw := gzip.NewWriter(&cbuf)
+		tw := tar.NewWriter(zw)
+		control := fmt.Sprintf("Package: %s\nVersion: %s\nArchitecture: %s\nMaintainer: KN4CK3R <dummy@gitea.io>\nDescription: %s\n", name, version, architecture, packageDescription)
+		tw.WriteHeader(&tar.Header{
+			Name: "./control",
+			Mode: 0o600,
+			Size: int64(len(control)),
+		})
+		tw.Write([]byte(control))
+		tw.Close()
+		zw.Close()
+
+		var buf bytes.Buffer
+		buf.WriteString("!<arch>\n")
+		for _, entry := range []struct {
+			name    string
+			content []byte
+		}{
+			{"debian-binary", []byte("2.0\n")},
+			{"control.tar.gz", cbuf.Bytes()},
+		} {
+			fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", entry.name, "0", "0", "0", "644", len(entry.content))
+			buf.Write(entry.content)
+			if len(entry.content)%2 != 0 {
+				buf.WriteByte('\n')
+			}
+		}
+		return buf.Bytes()
+	}
+
+	distribution := "test"
+	component := "main"
+	architectures := []string{"amd64", "arm64"}
+
+	rootURL := fmt.Sprintf("/api/packages/%s/debian", user.Name)
+	poolURL := fmt.Sprintf("%s/pool/%s/%s", rootURL, distribution, component)
+
+	t.Run("RepositoryKey", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/repository.key")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Contains(t, resp.Body.String(), "-----BEGIN PGP PUBLIC KEY BLOCK-----")
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader(createPackage(packageName, packageVersion, architectures[0])))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader([]byte{}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", rootURL+"/pool/Invalid/main/upload", bytes.NewReader(createPackage(packageName, packageVersion, architectures[0])))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		for _, architecture := range architectures {
+			req = NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader(createPackage(packageName, packageVersion, architecture)))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusCreated)
+		}
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeDebian)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.IsType(t, &debian_module.Metadata{}, pd.Metadata)
+		assert.Equal(t, packageName, pd.Package.Name)
+		assert.Equal(t, packageVersion, pd.Version.Version)
+		assert.Equal(t, "KN4CK3R", pd.Metadata.(*debian_module.Metadata).Maintainer)
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 2)
+		for _, pf := range pfs {
+			assert.True(t, pf.IsLead)
+			assert.Equal(t, distribution+"|"+component, pf.CompositeKey)
+		}
+
+		req = NewRequestWithBody(t, "PUT", poolURL+"/upload", bytes.NewReader(createPackage(packageName, packageVersion, architectures[0])))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/%s_%s_%s.deb", poolURL, packageName, packageVersion, architectures[0]))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, createPackage(packageName, packageVersion, architectures[0]), resp.Body.Bytes())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%s_%s_%s.deb", poolURL, packageName, "9.9.9", architectures[0]))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Packages", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		for _, architecture := range architectures {
+			url := fmt.Sprintf("%s/dists/%s/%s/binary-%s/Packages", rootURL, distribution, component, architecture)
+
+			req := NewRequest(t, "GET", url)
+			resp := MakeRequest(t, req, http.StatusOK)
+			body := resp.Body.String()
+
+			assert.Contains(t, body, "Package: "+packageName+"\n")
+			assert.Contains(t, body, "Version: "+packageVersion+"\n")
+			assert.Contains(t, body, "Architecture: "+architecture+"\n")
+			assert.Contains(t, body, fmt.Sprintf("Filename: pool/%s/%s/%s_%s_%s.deb\n", distribution, component, packageName, packageVersion, architecture))
+			assert.Contains(t, body, "SHA256: ")
+
+			req = NewRequest(t, "GET", url+".gz")
+			resp = MakeRequest(t, req, http.StatusOK)
+
+			zr, err := gzip.NewReader(resp.Body)
+			assert.NoError(t, err)
+			uncompressed, err := io.ReadAll(zr)
+			assert.NoError(t, err)
+			assert.Equal(t, body, string(uncompressed))
+		}
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/%s/binary-%s/Packages", rootURL, distribution, component, "i386"))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/%s/binary-%s/Packages", rootURL, "unknown", component, architectures[0]))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Release", rootURL, distribution))
+		resp := MakeRequest(t, req, http.StatusOK)
+		release := resp.Body.Bytes()
+
+		assert.Contains(t, string(release), "Components: "+component+"\n")
+		assert.Contains(t, string(release), "Architectures: "+strings.Join(architectures, " ")+"\n")
+		assert.Contains(t, string(release), fmt.Sprintf("%s/binary-%s/Packages.gz\n", component, architectures[0]))
+
+		req = NewRequest(t, "GET", rootURL+"/repository.key")
+		resp = MakeRequest(t, req, http.StatusOK)
+		keyring, err := openpgp.ReadArmoredKeyRing(resp.Body)
+		assert.NoError(t, err)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Release.gpg", rootURL, distribution))
+		resp = MakeRequest(t, req, http.StatusOK)
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(release), resp.Body)
+		assert.NoError(t, err)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/InRelease", rootURL, distribution))
+		resp = MakeRequest(t, req, http.StatusOK)
+		block, _ := clearsign.Decode(resp.Body.Bytes())
+		assert.NotNil(t, block)
+		assert.Equal(t, release, block.Plaintext)
+		_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body)
+		assert.NoError(t, err)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Unknown", rootURL, distribution))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", fmt.Sprintf("%s/%s/%s/%s", poolURL, packageName, packageVersion, architectures[0]))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		for _, architecture := range architectures {
+			req = NewRequest(t, "DELETE", fmt.Sprintf("%s/%s/%s/%s", poolURL, packageName, packageVersion, architecture))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNoContent)
+		}
+
+		req = NewRequest(t, "DELETE", fmt.Sprintf("%s/%s/%s/%s", poolURL, packageName, packageVersion, architectures[0]))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeDebian)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/dists/%s/Release", rootURL, distribution))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,309 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	goproxy_module "code.gitea.io/gitea/modules/packages/goproxy"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	goproxy_service "code.gitea.io/gitea/services/packages/goproxy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type goModuleVersionInfo struct {
+	Version string
+}
+
+func readGoModuleZip(t *testing.T, content []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		data, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestPackageGoProxy(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	rootURL := fmt.Sprintf("/api/packages/%s/go", user.Name)
+
+	t.Run("Uploaded", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		modulePath := "gitea.io/test/module"
+		moduleVersion := "v1.0.3"
+		goMod := "module " + modulePath + "\n\ngo 1.18\n"
+
+		createModule := func(version string) []byte {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			for name, content := range map[string]string{
+				"go.mod":  goMod,
+				"main.go": "package main",
+			} {
+				w, _ := zw.Create(modulePath + "@" + version + "/" + name)
+				w.Write([]byte(content))
+			}
+			zw.Close()
+			return buf.Bytes()
+		}
+
+		content := createModule(moduleVersion)
+
+		t.Run("Upload", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequestWithBody(t, "PUT", rootURL+"/upload", bytes.NewReader(content))
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequestWithBody(t, "PUT", rootURL+"/upload", bytes.NewReader([]byte("invalid")))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			req = NewRequestWithBody(t, "PUT", rootURL+"/upload", bytes.NewReader(content))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusCreated)
+
+			pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeGo)
+			assert.NoError(t, err)
+			assert.Len(t, pvs, 1)
+
+			pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+			assert.NoError(t, err)
+			assert.IsType(t, &goproxy_module.Metadata{}, pd.Metadata)
+			assert.Equal(t, modulePath, pd.Package.Name)
+			assert.Equal(t, moduleVersion, pd.Version.Version)
+			assert.Equal(t, goMod, pd.Metadata.(*goproxy_module.Metadata).GoMod)
+
+			pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+			assert.NoError(t, err)
+			assert.Len(t, pfs, 1)
+			assert.Equal(t, moduleVersion+".zip", pfs[0].Name)
+			assert.True(t, pfs[0].IsLead)
+
+			req = NewRequestWithBody(t, "PUT", rootURL+"/upload", bytes.NewReader(content))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusConflict)
+
+			req = NewRequestWithBody(t, "PUT", rootURL+"/upload", bytes.NewReader(createModule("v1.1.0-beta.1")))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusCreated)
+		})
+
+		t.Run("List", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/list", rootURL, modulePath))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			assert.Equal(t, moduleVersion+"\nv1.1.0-beta.1\n", resp.Body.String())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/list", rootURL, "gitea.io/unknown"))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("Latest", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@latest", rootURL, modulePath))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var info goModuleVersionInfo
+			DecodeJSON(t, resp, &info)
+			assert.Equal(t, moduleVersion, info.Version)
+		})
+
+		t.Run("Info", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/%s.info", rootURL, modulePath, moduleVersion))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var info goModuleVersionInfo
+			DecodeJSON(t, resp, &info)
+			assert.Equal(t, moduleVersion, info.Version)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/%s.info", rootURL, modulePath, "v9.9.9"))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("GoMod", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/%s.mod", rootURL, modulePath, moduleVersion))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			assert.Equal(t, goMod, resp.Body.String())
+		})
+
+		t.Run("Zip", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/%s.zip", rootURL, modulePath, moduleVersion))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			assert.Equal(t, content, resp.Body.Bytes())
+		})
+	})
+
+}
+
+func TestPackageGoProxyRepository(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+		rootURL := fmt.Sprintf("/api/packages/%s/go", user.Name)
+
+		// module paths require a dot in the host name
+		appURL := setting.AppURL
+		moduleAppURL := "http://gitea.example.com/"
+
+		setting.AppURL = moduleAppURL
+		modulePath := goproxy_service.RepositoryModulePrefix(user) + repo.Name
+		setting.AppURL = appURL
+		goMod := "module " + modulePath + "\n"
+
+		session := loginUser(t, user.Name)
+		token := getTokenForLoggedInUser(t, session)
+
+		for path, content := range map[string]string{
+			"go.mod":             goMod,
+			"main.go":            "package main",
+			"sub/go.mod":         "module " + modulePath + "/sub\n",
+			"sub/sub.go":         "package sub",
+			"vendor/pkg/file.go": "package pkg",
+		} {
+			_, err := createFileInBranch(user, repo, path, repo.DefaultBranch, content)
+			assert.NoError(t, err)
+		}
+		for _, tag := range []string{"v1.0.0", "v1.1.0", "v2.0.0", "invalid", "sub/v0.1.0"} {
+			createNewTagUsingAPI(t, session, token, user.Name, repo.Name, tag, repo.DefaultBranch, "")
+		}
+
+		// the hooks of the pushes above need the url of the running server
+		setting.AppURL = moduleAppURL
+		defer func() {
+			setting.AppURL = appURL
+		}()
+
+		t.Run("List", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/list", rootURL, modulePath))
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, "v1.0.0\nv1.1.0\n", resp.Body.String())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/v2/@v/list", rootURL, modulePath))
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, "v2.0.0\n", resp.Body.String())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/sub/@v/list", rootURL, modulePath))
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, "v0.1.0\n", resp.Body.String())
+		})
+
+		t.Run("Latest", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@latest", rootURL, modulePath))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var info goModuleVersionInfo
+			DecodeJSON(t, resp, &info)
+			assert.Equal(t, "v1.1.0", info.Version)
+		})
+
+		t.Run("GoMod", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/v1.0.0.mod", rootURL, modulePath))
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, goMod, resp.Body.String())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/v2.0.0.mod", rootURL, modulePath))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("Zip", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/v1.0.0.zip", rootURL, modulePath))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			files := readGoModuleZip(t, resp.Body.Bytes())
+			prefix := modulePath + "@v1.0.0/"
+			assert.Equal(t, goMod, files[prefix+"go.mod"])
+			assert.Equal(t, "package main", files[prefix+"main.go"])
+			assert.Contains(t, files, prefix+"README.md")
+			for name := range files {
+				assert.False(t, strings.HasPrefix(name, prefix+"sub/"), name)
+				assert.False(t, strings.HasPrefix(name, prefix+"vendor/"), name)
+			}
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/sub/@v/v0.1.0.zip", rootURL, modulePath))
+			resp = MakeRequest(t, req, http.StatusOK)
+
+			files = readGoModuleZip(t, resp.Body.Bytes())
+			assert.Len(t, files, 2)
+			assert.Equal(t, "package sub", files[modulePath+"/sub@v0.1.0/sub.go"])
+		})
+
+		t.Run("NoAccess", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2})
+			assert.True(t, repo.IsPrivate)
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/list", rootURL, goproxy_service.RepositoryModulePrefix(user)+repo.Name))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("TokenScope", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			// the versions built from the tags require the repository scope
+			packageToken := createScopedToken(t, &api.CreateAccessTokenOption{
+				Name:   "goproxy-read-package",
+				Scopes: []string{"read:package"},
+			}, http.StatusCreated)
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/v1.0.0.zip", rootURL, modulePath))
+			req.SetBasicAuth(user.Name, packageToken.Token)
+			MakeRequest(t, req, http.StatusNotFound)
+
+			repositoryToken := createScopedToken(t, &api.CreateAccessTokenOption{
+				Name:   "goproxy-read-package-repository",
+				Scopes: []string{"read:package", "read:repository"},
+			}, http.StatusCreated)
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/@v/v1.0.0.zip", rootURL, modulePath))
+			req.SetBasicAuth(user.Name, repositoryToken.Token)
+			MakeRequest(t, req, http.StatusOK)
+		})
+	})
+}
@@ -0,0 +1,173 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgProjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/projects?token="+token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	if assert.Len(t, apiProjects, 1) {
+		assert.EqualValues(t, 4, apiProjects[0].ID)
+		assert.Equal(t, "organization", apiProjects[0].Type)
+		assert.Equal(t, "user3", apiProjects[0].Owner.UserName)
+		assert.Nil(t, apiProjects[0].Repo)
+	}
+
+	// user4 can read the projects of the organization but not change them
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequest(t, "GET", "/api/v1/projects/4?token="+token4)
+	session4.MakeRequest(t, req, http.StatusOK)
+	title := "changed"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/projects/4?token="+token4, &api.EditProjectOption{Title: &title})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/projects?token="+token4, &api.CreateProjectOption{Title: "new"})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIUserProject(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/projects?token="+token, &api.CreateProjectOption{
+		Title:     "cross repository board",
+		BoardType: "basic_kanban",
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, "individual", apiProject.Type)
+	assert.Equal(t, "user2", apiProject.Owner.UserName)
+	unittest.AssertExistsAndLoadBean(t, &project_model.Project{ID: apiProject.ID, OwnerID: 2, Type: project_model.TypeIndividual})
+
+	projectURL := fmt.Sprintf("/api/v1/projects/%d", apiProject.ID)
+
+	req = NewRequest(t, "GET", projectURL+"/boards?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiBoards []*api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoards)
+	// the boards of the template plus the "Uncategorized" board for issues not on any board
+	if assert.Len(t, apiBoards, 4) {
+		assert.True(t, apiBoards[0].Default)
+		assert.EqualValues(t, 0, apiBoards[0].ID)
+	}
+
+	// issues of different repositories can be put on the same board
+	for _, issueID := range []int64{1, 4} {
+		req = NewRequestWithJSON(t, "POST", projectURL+"/issues?token="+token, &api.AddProjectIssueOption{
+			IssueID: issueID,
+			BoardID: apiBoards[1].ID,
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+	}
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 4})
+	assert.EqualValues(t, apiProject.ID, issue.ProjectID())
+	assert.EqualValues(t, apiBoards[1].ID, issue.ProjectBoardID())
+
+	req = NewRequest(t, "GET", projectURL+"/issues?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Len(t, apiIssues, 2)
+
+	req = NewRequest(t, "DELETE", projectURL+"/issues/4?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "DELETE", projectURL+"/issues/4?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/api/v1/users/user2/projects?state=all&token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	assert.Len(t, apiProjects, 2)
+
+	req = NewRequest(t, "DELETE", projectURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "GET", projectURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIProjectIssueRequiresWrite(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/projects?token="+token, &api.CreateProjectOption{Title: "own board"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+
+	// user4 can read the issue of user2/repo1 but not change it, or take it from its project
+	projectID := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1}).ProjectID()
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/projects/%d/issues?token=%s", apiProject.ID, token), &api.AddProjectIssueOption{IssueID: 1})
+	session.MakeRequest(t, req, http.StatusForbidden)
+	assert.EqualValues(t, projectID, unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1}).ProjectID())
+
+	// nor remove it from the project of user4
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1})
+	assert.NoError(t, issues_model.ChangeProjectAssign(issue, unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}), apiProject.ID))
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/projects/%d/issues/1?token=%s", apiProject.ID, token))
+	session.MakeRequest(t, req, http.StatusForbidden)
+	assert.EqualValues(t, apiProject.ID, unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1}).ProjectID())
+}
+
+func TestAPIProjectIssuesRequireUnit(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// user15 owns the organization user17 and can read all units of its repositories
+	session15 := loginUser(t, "user15")
+	token15 := getTokenForLoggedInUser(t, session15)
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user17/big_test_private_4/issues?token="+token15, &api.CreateIssueOption{Title: "private issue"})
+	resp := session15.MakeRequest(t, req, http.StatusCreated)
+	var apiIssue api.Issue
+	DecodeJSON(t, resp, &apiIssue)
+
+	session29 := loginUser(t, "user29")
+	token29 := getTokenForLoggedInUser(t, session29)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/projects?token="+token29, &api.CreateProjectOption{Title: "own board"})
+	resp = session29.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: apiIssue.ID})
+	assert.NoError(t, issues_model.ChangeProjectAssign(issue, unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 15}), apiProject.ID))
+
+	// user29 can see the repository through its team, but the team has no access to the issues unit
+	var apiIssues []*api.Issue
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/projects/%d/issues?token=%s", apiProject.ID, token29))
+	resp = session29.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Empty(t, apiIssues)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/projects/%d/issues?token=%s", apiProject.ID, token15))
+	resp = session15.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiIssues)
+	if assert.Len(t, apiIssues, 1) {
+		assert.EqualValues(t, apiIssue.ID, apiIssues[0].ID)
+	}
+}
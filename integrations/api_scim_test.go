@@ -0,0 +1,210 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/scim"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPISCIM(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	setting.SCIM.Enabled = true
+	setting.SCIM.Organization = "user3"
+	c = routers.NormalRoutes()
+	defer func() {
+		setting.SCIM.Enabled = false
+		setting.SCIM.Organization = ""
+		c = routers.NormalRoutes()
+	}()
+
+	token := getTokenForLoggedInUser(t, loginUser(t, "user1"))
+
+	newRequest := func(method, path string, body interface{}) *http.Request {
+		var req *http.Request
+		if body != nil {
+			req = NewRequestWithJSON(t, method, "/api/scim/v2"+path, body)
+		} else {
+			req = NewRequest(t, method, "/api/scim/v2"+path)
+		}
+		req.Header.Add("Authorization", "Bearer "+token)
+		return req
+	}
+
+	t.Run("Authentication", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", "/api/scim/v2/ServiceProviderConfig")
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "GET", "/api/scim/v2/ServiceProviderConfig")
+		req.Header.Add("Authorization", "Bearer "+getTokenForLoggedInUser(t, loginUser(t, "user2")))
+		MakeRequest(t, req, http.StatusForbidden)
+
+		// the token of the site administrator requires the admin scope
+		scopedToken := func(name, scope string) string {
+			req := NewRequestWithJSON(t, "POST", "/api/v1/users/user1/tokens", &api.CreateAccessTokenOption{Name: name, Scopes: []string{scope}})
+			req = AddBasicAuthHeader(req, "user1")
+			var token api.AccessToken
+			DecodeJSON(t, MakeRequest(t, req, http.StatusCreated), &token)
+			return token.Token
+		}
+		req = NewRequest(t, "GET", "/api/scim/v2/ServiceProviderConfig")
+		req.Header.Add("Authorization", "Bearer "+scopedToken("scim-user-scope", "write:user"))
+		MakeRequest(t, req, http.StatusForbidden)
+
+		readToken := scopedToken("scim-read-admin", "read:admin")
+		req = NewRequest(t, "GET", "/api/scim/v2/Users")
+		req.Header.Add("Authorization", "Bearer "+readToken)
+		MakeRequest(t, req, http.StatusOK)
+		req = NewRequestWithJSON(t, "POST", "/api/scim/v2/Users", &scim.User{Schemas: []string{scim.SchemaUser}, UserName: "scim-read-only"})
+		req.Header.Add("Authorization", "Bearer "+readToken)
+		MakeRequest(t, req, http.StatusForbidden)
+
+		resp := MakeRequest(t, newRequest("GET", "/ServiceProviderConfig", nil), http.StatusOK)
+		assert.Equal(t, scim.ContentType+"; charset=utf-8", resp.Header().Get("Content-Type"))
+
+		var config scim.ServiceProviderConfig
+		DecodeJSON(t, resp, &config)
+		assert.True(t, config.Patch.Supported)
+		assert.False(t, config.Bulk.Supported)
+	})
+
+	var userID string
+
+	t.Run("Users", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		su := &scim.User{
+			Schemas:  []string{scim.SchemaUser},
+			UserName: "scim-user",
+			Name:     &scim.Name{GivenName: "Scim", FamilyName: "User"},
+			Emails:   []scim.Email{{Value: "scim-user@example.com", Primary: true}},
+		}
+		resp := MakeRequest(t, newRequest("POST", "/Users", su), http.StatusCreated)
+		var created scim.User
+		DecodeJSON(t, resp, &created)
+		userID = created.ID
+		assert.Equal(t, "scim-user", created.UserName)
+		assert.Equal(t, "Scim User", created.DisplayName)
+		assert.True(t, *created.Active)
+		assert.Equal(t, created.Meta.Location, resp.Header().Get("Location"))
+
+		resp = MakeRequest(t, newRequest("POST", "/Users", su), http.StatusConflict)
+		var scimErr scim.Error
+		DecodeJSON(t, resp, &scimErr)
+		assert.Equal(t, "409", scimErr.Status)
+		assert.Equal(t, scim.ErrorTypeUniqueness, scimErr.ScimType)
+
+		resp = MakeRequest(t, newRequest("GET", "/Users?filter="+url.QueryEscape(`userName eq "Scim-User"`), nil), http.StatusOK)
+		var list struct {
+			TotalResults int64
+			Resources    []*scim.User
+		}
+		DecodeJSON(t, resp, &list)
+		assert.EqualValues(t, 1, list.TotalResults)
+		if assert.Len(t, list.Resources, 1) {
+			assert.Equal(t, userID, list.Resources[0].ID)
+		}
+
+		MakeRequest(t, newRequest("GET", "/Users?filter="+url.QueryEscape(`userName sw "scim"`), nil), http.StatusBadRequest)
+		MakeRequest(t, newRequest("GET", "/Users/3", nil), http.StatusNotFound)
+
+		patch := &scim.PatchRequest{
+			Schemas: []string{scim.SchemaPatchOp},
+			Operations: []scim.PatchOperation{
+				{Op: "Replace", Path: "active", Value: "False"},
+				{Op: "Replace", Path: "displayName", Value: "Deactivated User"},
+			},
+		}
+		resp = MakeRequest(t, newRequest("PATCH", "/Users/"+userID, patch), http.StatusOK)
+		var patched scim.User
+		DecodeJSON(t, resp, &patched)
+		assert.False(t, *patched.Active)
+		assert.Equal(t, "Deactivated User", patched.DisplayName)
+
+		id, _ := strconv.ParseInt(userID, 10, 64)
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: id})
+		assert.False(t, u.IsActive)
+		assert.True(t, u.ProhibitLogin)
+
+		patch.Operations = []scim.PatchOperation{{Op: "replace", Path: "userName", Value: "renamed"}}
+		MakeRequest(t, newRequest("PATCH", "/Users/"+userID, patch), http.StatusBadRequest)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		sg := &scim.Group{
+			Schemas:     []string{scim.SchemaGroup},
+			DisplayName: "scim-team",
+			Members:     []scim.Member{{Value: userID}},
+		}
+		resp := MakeRequest(t, newRequest("POST", "/Groups", sg), http.StatusCreated)
+		var created scim.Group
+		DecodeJSON(t, resp, &created)
+		assert.Equal(t, "scim-team", created.DisplayName)
+		if assert.Len(t, created.Members, 1) {
+			assert.Equal(t, userID, created.Members[0].Value)
+		}
+
+		MakeRequest(t, newRequest("POST", "/Groups", sg), http.StatusConflict)
+
+		patch := &scim.PatchRequest{
+			Schemas: []string{scim.SchemaPatchOp},
+			Operations: []scim.PatchOperation{
+				{Op: "add", Path: "members", Value: []scim.Member{{Value: "4"}}},
+				{Op: "remove", Path: fmt.Sprintf(`members[value eq "%s"]`, userID)},
+				{Op: "replace", Value: map[string]interface{}{"displayName": "scim-team-renamed"}},
+			},
+		}
+		resp = MakeRequest(t, newRequest("PATCH", "/Groups/"+created.ID, patch), http.StatusOK)
+		var patched scim.Group
+		DecodeJSON(t, resp, &patched)
+		assert.Equal(t, "scim-team-renamed", patched.DisplayName)
+		if assert.Len(t, patched.Members, 1) {
+			assert.Equal(t, "4", patched.Members[0].Value)
+		}
+
+		resp = MakeRequest(t, newRequest("GET", "/Groups?filter="+url.QueryEscape(`displayName eq "scim-team-renamed"`), nil), http.StatusOK)
+		var list struct {
+			TotalResults int64
+			Resources    []*scim.Group
+		}
+		DecodeJSON(t, resp, &list)
+		assert.EqualValues(t, 1, list.TotalResults)
+
+		// teams of other organizations are not groups
+		MakeRequest(t, newRequest("GET", "/Groups/5", nil), http.StatusNotFound)
+		MakeRequest(t, newRequest("DELETE", "/Groups/1", nil), http.StatusBadRequest)
+
+		MakeRequest(t, newRequest("DELETE", "/Groups/"+created.ID, nil), http.StatusNoContent)
+		id, _ := strconv.ParseInt(created.ID, 10, 64)
+		unittest.AssertNotExistsBean(t, &organization.Team{ID: id})
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		MakeRequest(t, newRequest("DELETE", "/Users/2", nil), http.StatusNoContent)
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		assert.False(t, u.IsActive)
+		assert.True(t, u.ProhibitLogin)
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoSecrets(t *testing.T) {
+	defer prepareTestEnv(t)()
+	token := getUserToken(t, "user2")
+
+	req := NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/secrets/deploy_token?token="+token, &api.CreateOrUpdateSecretOption{Data: "first"})
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/secrets/DEPLOY_TOKEN?token="+token, &api.CreateOrUpdateSecretOption{Data: "second"})
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/secrets/GITEA_TOKEN?token="+token, &api.CreateOrUpdateSecretOption{Data: "value"})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the value is never returned
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/secrets?token="+token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "second")
+	var secrets []*api.Secret
+	DecodeJSON(t, resp, &secrets)
+	if assert.Len(t, secrets, 1) {
+		assert.Equal(t, "DEPLOY_TOKEN", secrets[0].Name)
+	}
+
+	values, err := secret_model.GetDecryptedSecrets(db.DefaultContext, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", values["DEPLOY_TOKEN"])
+
+	// only the administrators of the repository can manage its secrets
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/secrets?token="+getUserToken(t, "user4"))
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "DELETE", "/api/v1/repos/user2/repo1/secrets/deploy_token?token="+token)
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "DELETE", "/api/v1/repos/user2/repo1/secrets/deploy_token?token="+token)
+	MakeRequest(t, req, http.StatusNotFound)
+	unittest.AssertNotExistsBean(t, &secret_model.Secret{RepoID: 1})
+}
+
+func TestAPIOrgSecrets(t *testing.T) {
+	defer prepareTestEnv(t)()
+	token := getUserToken(t, "user2")
+
+	req := NewRequestWithJSON(t, "PUT", "/api/v1/orgs/user3/secrets/ORG_TOKEN?token="+token, &api.CreateOrUpdateSecretOption{Data: "value"})
+	MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertExistsAndLoadBean(t, &secret_model.Secret{OwnerID: 3, Name: "ORG_TOKEN"})
+
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/secrets?token="+token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var secrets []*api.Secret
+	DecodeJSON(t, resp, &secrets)
+	assert.Len(t, secrets, 1)
+
+	// members who do not own the organization cannot manage its secrets
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/secrets?token="+getUserToken(t, "user4"))
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "DELETE", "/api/v1/orgs/user3/secrets/org_token?token="+token)
+	MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &secret_model.Secret{OwnerID: 3})
+}
+
+func TestRepoSecretsSettings(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/secrets", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/settings/secrets"),
+		"name":  "web_token",
+		"data":  "a-very-secret-value",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	unittest.AssertExistsAndLoadBean(t, &secret_model.Secret{RepoID: 1, Name: "WEB_TOKEN"})
+
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/settings/secrets"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "WEB_TOKEN")
+	assert.NotContains(t, resp.Body.String(), "a-very-secret-value")
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/secrets/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/settings/secrets"),
+		"id":    "WEB_TOKEN",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	unittest.AssertNotExistsBean(t, &secret_model.Secret{RepoID: 1})
+
+	session.MakeRequest(t, NewRequest(t, "GET", "/org/user3/settings/secrets"), http.StatusOK)
+}
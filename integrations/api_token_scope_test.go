@@ -0,0 +1,162 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/auth"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/storage"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createScopedToken(t *testing.T, opts *api.CreateAccessTokenOption, expectedStatus int) *api.AccessToken {
+	req := NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens", opts)
+	req = AddBasicAuthHeader(req, "user2")
+	resp := MakeRequest(t, req, expectedStatus)
+	if expectedStatus != http.StatusCreated {
+		return nil
+	}
+	var token api.AccessToken
+	DecodeJSON(t, resp, &token)
+	return &token
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	token := createScopedToken(t, &api.CreateAccessTokenOption{
+		Name:   "read-repository",
+		Scopes: []string{"read:repository"},
+	}, http.StatusCreated)
+	assert.Equal(t, []string{"read:repository"}, token.Scopes)
+
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1?token="+token.Token), http.StatusOK)
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2?token="+token.Token), http.StatusOK)
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues?token="+token.Token), http.StatusForbidden)
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/user?token="+token.Token), http.StatusForbidden)
+	req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token.Token, &api.EditRepoOption{})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// a token can't create a token with a broader scope
+	req = NewRequestWithJSON(t, "POST", "/api/v1/users/user2/tokens?token="+token.Token, &api.CreateAccessTokenOption{
+		Name:   "broader",
+		Scopes: []string{"all"},
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// the raw files and the release downloads require the repository scope
+	req = NewRequest(t, "GET", "/user2/repo2/raw/blob/6395b68e1feebb1e4c657b4f9f6ba2676a283c0b")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusOK)
+	userToken := createScopedToken(t, &api.CreateAccessTokenOption{
+		Name:   "read-user",
+		Scopes: []string{"read:user"},
+	}, http.StatusCreated)
+	req = NewRequest(t, "GET", "/user2/repo2/raw/blob/6395b68e1feebb1e4c657b4f9f6ba2676a283c0b")
+	req.SetBasicAuth("user2", userToken.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/user2/repo1/releases/download/v1.1/README.md")
+	req.SetBasicAuth("user2", userToken.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// the attachments of the issues require the issue scope, the ones of the releases the repository scope
+	_, err := storage.Attachments.Save(repo_model.AttachmentRelativePath("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19"), strings.NewReader("hello world"), -1)
+	assert.NoError(t, err)
+	req = NewRequest(t, "GET", "/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19")
+	req.SetBasicAuth("user2", userToken.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// the tokens without a scope can access everything, unless they are restricted
+	noScopeToken := createScopedToken(t, &api.CreateAccessTokenOption{Name: "no-scope"}, http.StatusCreated)
+	assert.Equal(t, []string{"all"}, noScopeToken.Scopes)
+	createScopedToken(t, &api.CreateAccessTokenOption{Name: "restricted-no-scope", Repository: "user2/repo1"}, http.StatusUnprocessableEntity)
+	createScopedToken(t, &api.CreateAccessTokenOption{Name: "invalid-scope", Scopes: []string{"read:everything"}}, http.StatusUnprocessableEntity)
+}
+
+func TestAPITokenRestriction(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	token := createScopedToken(t, &api.CreateAccessTokenOption{
+		Name:       "restricted",
+		Scopes:     []string{"read:repository", "write:issue"},
+		Repository: "user2/repo1",
+		ExpiresAt:  &expiresAt,
+	}, http.StatusCreated)
+	assert.Equal(t, "user2/repo1", token.Repository)
+	assert.True(t, expiresAt.Equal(*token.ExpiresAt))
+
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1?token="+token.Token), http.StatusOK)
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues?token="+token.Token), http.StatusOK)
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo2?token="+token.Token), http.StatusNotFound)
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/search?token="+token.Token), http.StatusForbidden)
+
+	req := NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-receive-pack")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequest(t, "GET", "/user2/repo1/raw/blob/4b4851ad51df6a7d9f25c979345979eaeb5b349f")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/user2/repo2/raw/blob/6395b68e1feebb1e4c657b4f9f6ba2676a283c0b")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	_, err := storage.Attachments.Save(repo_model.AttachmentRelativePath("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"), strings.NewReader("hello world"), -1)
+	assert.NoError(t, err)
+	req = NewRequest(t, "GET", "/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12")
+	req.SetBasicAuth("user2", token.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// the restricted tokens can't be granted the scopes beyond the repository
+	createScopedToken(t, &api.CreateAccessTokenOption{
+		Name:       "restricted-user",
+		Scopes:     []string{"read:user"},
+		Repository: "user2/repo1",
+	}, http.StatusUnprocessableEntity)
+}
+
+func TestAPITokenExpiry(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	token := &models.AccessToken{UID: 2, Name: "expiring", Scope: auth.AccessTokenScopeAll, ExpiresUnix: timeutil.TimeStampNow().AddDuration(time.Hour)}
+	assert.NoError(t, models.NewAccessToken(token))
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/user?token="+token.Token), http.StatusOK)
+
+	token.ExpiresUnix = timeutil.TimeStampNow() - 1
+	assert.NoError(t, models.UpdateAccessToken(token))
+	MakeRequest(t, NewRequest(t, "GET", "/api/v1/user?token="+token.Token), http.StatusUnauthorized)
+
+	expiresAt := time.Now().Add(-time.Hour)
+	createScopedToken(t, &api.CreateAccessTokenOption{
+		Name:      "expired",
+		Scopes:    []string{"all"},
+		ExpiresAt: &expiresAt,
+	}, http.StatusUnprocessableEntity)
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func signAppJWT(t *testing.T, app *api.App, privateKey string, lifetime time.Duration) string {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKey))
+	assert.NoError(t, err)
+	now := time.Now()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(app.ID, 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+	}).SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestAPIApp(t *testing.T) {
+	defer prepareTestEnv(t)()
+	token := getUserToken(t, "user2")
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/applications/apps?token="+token, &api.CreateAppOption{
+		Name:        "ci",
+		Permissions: []string{"read:repository"},
+		WebhookURL:  "http://localhost:3000/app-hook",
+	})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var app *api.App
+	DecodeJSON(t, resp, &app)
+	assert.NotEmpty(t, app.PrivateKey)
+	assert.Equal(t, "ci-bot", app.Bot.UserName)
+	unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{AppID: app.ID})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/applications/apps?token="+token, &api.CreateAppOption{
+		Name:        "ci",
+		Permissions: []string{"read:repository"},
+	})
+	MakeRequest(t, req, http.StatusConflict)
+
+	// the private key is never returned again
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/user/applications/apps/%d?token=%s", app.ID, token))
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "PRIVATE KEY")
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/orgs/user3/installations/ci?token="+token, &api.InstallAppOption{
+		Repositories: []string{"repo3"},
+	})
+	MakeRequest(t, req, http.StatusOK)
+
+	// only the owners of the organization can install apps
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/orgs/user3/installations/ci?token="+getUserToken(t, "user4"), &api.InstallAppOption{
+		AllRepositories: true,
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// the app endpoints require a JWT signed by the key of the app
+	req = NewRequest(t, "GET", "/api/v1/app")
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequest(t, "GET", "/api/v1/app")
+	req.Header.Set("Authorization", "Bearer "+signAppJWT(t, app, app.PrivateKey, time.Hour))
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	appJWT := signAppJWT(t, app, app.PrivateKey, 5*time.Minute)
+	req = NewRequest(t, "GET", "/api/v1/app")
+	req.Header.Set("Authorization", "Bearer "+appJWT)
+	MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "GET", "/api/v1/app/installations")
+	req.Header.Set("Authorization", "Bearer "+appJWT)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var installations []*api.AppInstallation
+	DecodeJSON(t, resp, &installations)
+	if assert.Len(t, installations, 1) {
+		assert.Equal(t, []string{"repo3"}, installations[0].Repositories)
+	}
+
+	req = NewRequest(t, "POST", fmt.Sprintf("/api/v1/app/installations/%d/access_tokens", installations[0].ID))
+	req.Header.Set("Authorization", "Bearer "+appJWT)
+	resp = MakeRequest(t, req, http.StatusCreated)
+	var installationToken *api.InstallationToken
+	DecodeJSON(t, resp, &installationToken)
+	assert.NotEmpty(t, installationToken.Token)
+	assert.True(t, installationToken.ExpiresAt.Before(time.Now().Add(time.Hour+time.Minute)))
+
+	// the bot can only access the repositories the app is installed on
+	req = NewRequest(t, "GET", "/api/v1/repos/user3/repo3?token="+installationToken.Token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/api/v1/repos/user3/repo5?token="+installationToken.Token)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", "/api/v1/orgs/user3/installations/ci?token="+token)
+	MakeRequest(t, req, http.StatusNoContent)
+	// the installation tokens are removed with the installation
+	req = NewRequest(t, "GET", "/api/v1/repos/user3/repo3?token="+installationToken.Token)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/user/applications/apps/%d?token=%s", app.ID, token))
+	MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &user_model.User{ID: app.Bot.ID})
+	unittest.AssertNotExistsBean(t, &webhook_model.Webhook{AppID: app.ID})
+}
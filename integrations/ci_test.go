@@ -0,0 +1,243 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	ci_model "code.gitea.io/gitea/models/ci"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/ci/runner"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCIWorkflow = `name: Test
+on:
+  push:
+    branches: [master]
+env:
+  GREETING: hello
+jobs:
+  build:
+    runs-on: linux
+    steps:
+      - name: Greet
+        run: echo "$GREETING from $GITEA_WORKFLOW"
+      - run: test -f README.md
+  fail:
+    runs-on: linux
+    steps:
+      - run: exit 3
+      - run: echo never
+`
+
+func TestCIWorkflow(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := context.Background()
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+		token, err := ci_model.ResetRunnerToken(db.DefaultContext, repo1.ID)
+		assert.NoError(t, err)
+		_, err = runner.Register(ctx, u.String(), "invalid", "test-runner", []string{"linux"})
+		assert.Error(t, err)
+		cfg, err := runner.Register(ctx, u.String(), token.Token, "test-runner", []string{"linux"})
+		assert.NoError(t, err)
+		unittest.AssertExistsAndLoadBean(t, &ci_model.Runner{UUID: cfg.UUID, RepoID: repo1.ID})
+
+		resp, err := createFileInBranch(user2, repo1, ".gitea/workflows/test.yml", repo1.DefaultBranch, testCIWorkflow)
+		assert.NoError(t, err)
+		sha := resp.Commit.SHA
+		assert.NoError(t, queue.GetManager().FlushAll(ctx, 5*time.Second))
+
+		run := unittest.AssertExistsAndLoadBean(t, &ci_model.Run{RepoID: repo1.ID, CommitSHA: sha})
+		assert.Equal(t, "Test", run.Title)
+		assert.Equal(t, "refs/heads/master", run.Ref)
+		assert.Equal(t, ci_model.StatusWaiting, run.Status)
+		unittest.AssertCount(t, &ci_model.Job{RunID: run.ID}, 2)
+
+		r := runner.New(cfg, t.TempDir())
+		for i := 0; i < 2; i++ {
+			ran, err := r.RunOnce(ctx)
+			assert.NoError(t, err)
+			assert.True(t, ran)
+		}
+		ran, err := r.RunOnce(ctx)
+		assert.NoError(t, err)
+		assert.False(t, ran)
+
+		build := unittest.AssertExistsAndLoadBean(t, &ci_model.Job{RunID: run.ID, JobID: "build"})
+		assert.Equal(t, ci_model.StatusSuccess, build.Status)
+		log, err := ci_model.GetJobLog(db.DefaultContext, build.ID)
+		assert.NoError(t, err)
+		assert.Contains(t, log, "hello from test.yml")
+
+		fail := unittest.AssertExistsAndLoadBean(t, &ci_model.Job{RunID: run.ID, JobID: "fail"})
+		assert.Equal(t, ci_model.StatusFailure, fail.Status)
+		log, err = ci_model.GetJobLog(db.DefaultContext, fail.ID)
+		assert.NoError(t, err)
+		assert.NotContains(t, log, "never")
+
+		run = unittest.AssertExistsAndLoadBean(t, &ci_model.Run{ID: run.ID})
+		assert.Equal(t, ci_model.StatusFailure, run.Status)
+
+		// the jobs are reported as commit statuses
+		session := loginUser(t, user2.Name)
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/commits/%s/status", repo1.FullName(), sha))
+		apiResp := session.MakeRequest(t, req, http.StatusOK)
+		var status api.CombinedStatus
+		DecodeJSON(t, apiResp, &status)
+		assert.Equal(t, api.CommitStatusFailure, status.State)
+		if assert.Len(t, status.Statuses, 2) {
+			for _, s := range status.Statuses {
+				switch s.Context {
+				case "Test / build (push)":
+					assert.Equal(t, api.CommitStatusSuccess, s.State)
+				case "Test / fail (push)":
+					assert.Equal(t, api.CommitStatusFailure, s.State)
+				default:
+					assert.Fail(t, "unexpected context", s.Context)
+				}
+			}
+		}
+
+		session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/ci"), http.StatusOK)
+		session.MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/ci/runs/%d", run.Index)), http.StatusOK)
+		session.MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/ci/runs/%d/jobs/%d", run.Index, fail.ID)), http.StatusOK)
+		session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/settings/ci"), http.StatusOK)
+
+		// only the runner the job is assigned to can report on it
+		req = NewRequest(t, "POST", fmt.Sprintf("/api/ci/jobs/%d/logs", build.ID))
+		MakeRequest(t, req, http.StatusUnauthorized)
+	})
+}
+
+func TestCICancelRun(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+		resp, err := createFileInBranch(user2, repo1, ".gitea/workflows/test.yml", repo1.DefaultBranch, testCIWorkflow)
+		assert.NoError(t, err)
+		assert.NoError(t, queue.GetManager().FlushAll(context.Background(), 5*time.Second))
+		run := unittest.AssertExistsAndLoadBean(t, &ci_model.Run{RepoID: repo1.ID, CommitSHA: resp.Commit.SHA})
+
+		session := loginUser(t, "user4")
+		req := NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/ci/runs/%d/cancel", run.Index), map[string]string{
+			"_csrf": GetCSRF(t, session, "/user2/repo1/ci"),
+		})
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		session = loginUser(t, user2.Name)
+		req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/ci/runs/%d/cancel", run.Index), map[string]string{
+			"_csrf": GetCSRF(t, session, "/user2/repo1/ci"),
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+
+		run = unittest.AssertExistsAndLoadBean(t, &ci_model.Run{ID: run.ID})
+		assert.Equal(t, ci_model.StatusCancelled, run.Status)
+		unittest.AssertCount(t, &ci_model.Job{RunID: run.ID, Status: ci_model.StatusCancelled}, 2)
+	})
+}
+
+func TestCISecrets(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := context.Background()
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+		_, err := secret_model.SetSecret(db.DefaultContext, 0, repo1.ID, "DEPLOY_TOKEN", "t0p-s3cr3t")
+		assert.NoError(t, err)
+
+		token, err := ci_model.ResetRunnerToken(db.DefaultContext, repo1.ID)
+		assert.NoError(t, err)
+		cfg, err := runner.Register(ctx, u.String(), token.Token, "test-runner", []string{"linux"})
+		assert.NoError(t, err)
+
+		resp, err := createFileInBranch(user2, repo1, ".gitea/workflows/secrets.yml", repo1.DefaultBranch, `on: push
+jobs:
+  deploy:
+    runs-on: linux
+    env:
+      TOKEN: ${{ secrets.DEPLOY_TOKEN }}
+    steps:
+      - run: test "$TOKEN" = "t0p-s3cr3t" && echo "token is $TOKEN"
+      - run: echo "unknown=[${{ secrets.UNKNOWN }}]"
+`)
+		assert.NoError(t, err)
+		assert.NoError(t, queue.GetManager().FlushAll(ctx, 5*time.Second))
+		run := unittest.AssertExistsAndLoadBean(t, &ci_model.Run{RepoID: repo1.ID, CommitSHA: resp.Commit.SHA})
+
+		ran, err := runner.New(cfg, t.TempDir()).RunOnce(ctx)
+		assert.NoError(t, err)
+		assert.True(t, ran)
+
+		job := unittest.AssertExistsAndLoadBean(t, &ci_model.Job{RunID: run.ID, JobID: "deploy"})
+		assert.Equal(t, ci_model.StatusSuccess, job.Status)
+		log, err := ci_model.GetJobLog(db.DefaultContext, job.ID)
+		assert.NoError(t, err)
+		// the values of the secrets are masked in the logs
+		assert.Contains(t, log, "token is ***")
+		assert.NotContains(t, log, "t0p-s3cr3t")
+		assert.Contains(t, log, "unknown=[]")
+	})
+}
+
+func TestCISecretSplitAcrossLogs(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := context.Background()
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+		_, err := secret_model.SetSecret(db.DefaultContext, 0, repo1.ID, "DEPLOY_TOKEN", "t0p-s3cr3t")
+		assert.NoError(t, err)
+
+		token, err := ci_model.ResetRunnerToken(db.DefaultContext, repo1.ID)
+		assert.NoError(t, err)
+		cfg, err := runner.Register(ctx, u.String(), token.Token, "test-runner", []string{"linux"})
+		assert.NoError(t, err)
+
+		_, err = createFileInBranch(user2, repo1, ".gitea/workflows/split.yml", repo1.DefaultBranch, `on: push
+jobs:
+  deploy:
+    runs-on: linux
+    steps:
+      - run: echo "token is ${{ secrets.DEPLOY_TOKEN }}"
+`)
+		assert.NoError(t, err)
+		assert.NoError(t, queue.GetManager().FlushAll(ctx, 5*time.Second))
+
+		req := NewRequest(t, "POST", "/api/ci/jobs/fetch")
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var job ci_module.FetchedJob
+		DecodeJSON(t, resp, &job)
+
+		// the runner sends its buffered output on every tick, a secret can be split between two chunks
+		for _, chunk := range []string{"token is t0p-", "s3cr3t\ndone\n"} {
+			req = NewRequestWithBody(t, "POST", fmt.Sprintf("/api/ci/jobs/%d/logs", job.ID), strings.NewReader(chunk))
+			req.Header.Set("Authorization", "Bearer "+cfg.Token)
+			MakeRequest(t, req, http.StatusNoContent)
+		}
+
+		log, err := ci_model.GetJobLog(db.DefaultContext, job.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "token is ***\ndone\n", log)
+	})
+}
@@ -0,0 +1,218 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createDeployToken(t *testing.T, link, token string, readOnly bool) *api.DeployToken {
+	req := NewRequestWithJSON(t, "POST", link+"/deploy_tokens?token="+token, &api.CreateDeployTokenOption{
+		Name:     fmt.Sprintf("ci-%t", readOnly),
+		ReadOnly: &readOnly,
+	})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var deployToken *api.DeployToken
+	DecodeJSON(t, resp, &deployToken)
+	assert.NotEmpty(t, deployToken.Token)
+	assert.Equal(t, readOnly, deployToken.ReadOnly)
+	return deployToken
+}
+
+func TestAPIRepoDeployTokens(t *testing.T) {
+	defer prepareTestEnv(t)()
+	token := getUserToken(t, "user2")
+
+	readToken := createDeployToken(t, "/api/v1/repos/user2/repo2", token, true)
+	writeToken := createDeployToken(t, "/api/v1/repos/user2/repo2", token, false)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo2/deploy_tokens?token="+token, &api.CreateDeployTokenOption{Name: "ci-true"})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the values are never returned again
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo2/deploy_tokens?token="+token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), readToken.Token)
+	var deployTokens []*api.DeployToken
+	DecodeJSON(t, resp, &deployTokens)
+	assert.Len(t, deployTokens, 2)
+
+	// only the administrators of the repository can manage its deploy tokens
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo2/deploy_tokens?token="+getUserToken(t, "user4"))
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth(readToken.Token, "")
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-receive-pack")
+	req.SetBasicAuth("ci", readToken.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-receive-pack")
+	req.SetBasicAuth("ci", writeToken.Token)
+	MakeRequest(t, req, http.StatusOK)
+
+	// the token only grants the access to its repository, and not to the API
+	req = NewRequest(t, "GET", "/user2/repo16.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("ci", writeToken.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequest(t, "GET", "/api/v1/user")
+	req.SetBasicAuth("ci", writeToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	// the packages belong to the owner, the tokens of a repository can't access them
+	req = NewRequestWithBody(t, "PUT", "/api/packages/user2/generic/test-package/1.0.0/file.bin", bytes.NewReader([]byte{1, 2, 3}))
+	req.SetBasicAuth("ci", writeToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user2/repo2/deploy_tokens/%d?token=%s", readToken.ID, token))
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user2/repo2/deploy_tokens/%d?token=%s", readToken.ID, token))
+	MakeRequest(t, req, http.StatusNotFound)
+	unittest.AssertNotExistsBean(t, &auth_model.DeployToken{ID: readToken.ID})
+
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("ci", readToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+}
+
+func TestAPIOrgDeployTokens(t *testing.T) {
+	defer prepareTestEnv(t)()
+	token := getUserToken(t, "user2")
+
+	readToken := createDeployToken(t, "/api/v1/orgs/user3", token, true)
+	writeToken := createDeployToken(t, "/api/v1/orgs/user3", token, false)
+
+	// members who do not own the organization cannot manage its deploy tokens
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/deploy_tokens?token="+getUserToken(t, "user4"))
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// the tokens of an organization grant the access to all its repositories
+	req = NewRequest(t, "GET", "/user3/repo3.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("ci", readToken.Token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("ci", readToken.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// and to its packages
+	url := "/api/packages/user3/generic/test-package/1.0.0/file.bin"
+	req = NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{1, 2, 3}))
+	req.SetBasicAuth("ci", readToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{1, 2, 3}))
+	req.SetBasicAuth("ci", writeToken.Token)
+	MakeRequest(t, req, http.StatusCreated)
+	req = NewRequest(t, "GET", url)
+	req.SetBasicAuth("ci", readToken.Token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, []byte{1, 2, 3}, resp.Body.Bytes())
+	req = NewRequestWithBody(t, "PUT", "/api/packages/user2/generic/test-package/1.0.0/file.bin", bytes.NewReader([]byte{1, 2, 3}))
+	req.SetBasicAuth("ci", writeToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequest(t, "DELETE", "/api/packages/user3/generic/test-package/1.0.0")
+	req.SetBasicAuth("ci", writeToken.Token)
+	MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/orgs/user3/deploy_tokens/%d?token=%s", writeToken.ID, token))
+	MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &auth_model.DeployToken{ID: writeToken.ID})
+}
+
+func TestDeployTokenLimitedOwner(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// user2 is only visible to the signed in users
+	_, err := db.GetEngine(db.DefaultContext).ID(2).Cols("visibility").Update(&user_model.User{Visibility: api.VisibleTypeLimited})
+	assert.NoError(t, err)
+
+	url := "/api/packages/user2/generic/test-package/1.0.0/file.bin"
+	req := NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{1, 2, 3}))
+	AddBasicAuthHeader(req, "user2")
+	MakeRequest(t, req, http.StatusCreated)
+	req = NewRequest(t, "GET", url)
+	AddBasicAuthHeader(req, "user4")
+	MakeRequest(t, req, http.StatusOK)
+
+	// the deploy tokens of another owner are not signed in users
+	deployToken := createDeployToken(t, "/api/v1/orgs/user3", getUserToken(t, "user2"), true)
+	req = NewRequest(t, "GET", url)
+	req.SetBasicAuth("ci", deployToken.Token)
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("ci", deployToken.Token)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// and they are not accepted by the raw file and the release download routes
+	req = NewRequest(t, "GET", "/user3/repo3/raw/branch/master/README.md")
+	req.SetBasicAuth("ci", deployToken.Token)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/user2/repo1/raw/blob/4b4851ad51df6a7d9f25c979345979eaeb5b349f")
+	req.SetBasicAuth("ci", deployToken.Token)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "DELETE", "/api/packages/user2/generic/test-package/1.0.0")
+	AddBasicAuthHeader(req, "user2")
+	MakeRequest(t, req, http.StatusNoContent)
+}
+
+func TestGitDeployToken(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		deployToken := createDeployToken(t, "/api/v1/repos/user2/repo2", getUserToken(t, "user2"), false)
+
+		dstPath, err := os.MkdirTemp("", "repo2-deploy-token")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		u.Path = "user2/repo2.git"
+		u.User = url.UserPassword("ci", deployToken.Token)
+		assert.NoError(t, git.Clone(git.DefaultContext, u.String(), dstPath, git.CloneRepoOptions{}))
+
+		// the push is made in the name of the owner of the repository
+		_, err = generateCommitWithNewData(littleSize, dstPath, "user2@example.com", "User Two", "deploy-token-")
+		assert.NoError(t, err)
+		_, _, err = git.NewCommand(git.DefaultContext, "push", "origin", "master").RunStdString(&git.RunOpts{Dir: dstPath})
+		assert.NoError(t, err)
+	})
+}
+
+func TestRepoDeployTokensSettings(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/deploy_tokens", map[string]string{
+		"_csrf":       GetCSRF(t, session, "/user2/repo1/settings/deploy_tokens"),
+		"name":        "web",
+		"is_writable": "1",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	deployToken := unittest.AssertExistsAndLoadBean(t, &auth_model.DeployToken{RepoID: 1, Name: "web"})
+	assert.False(t, deployToken.IsReadOnly())
+
+	resp := session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/settings/deploy_tokens"), http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "web")
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/deploy_tokens/delete", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/settings/deploy_tokens"),
+		"id":    fmt.Sprint(deployToken.ID),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	unittest.AssertNotExistsBean(t, &auth_model.DeployToken{RepoID: 1})
+
+	session.MakeRequest(t, NewRequest(t, "GET", "/org/user3/settings/deploy_tokens"), http.StatusOK)
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	issues_model "code.gitea.io/gitea/models/issues"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
@@ -80,3 +81,60 @@ func TestEventSourceManagerRun(t *testing.T) {
 
 	assert.Eventually(t, expectNotificationCountEvent(1), 30*time.Second, 1*time.Second)
 }
+
+func TestEventSourceIssueUpdates(t *testing.T) {
+	defer prepareTestEnv(t)()
+	manager := eventsource.GetManager()
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: 1, Index: 1})
+	resource := eventsource.IssueResource(issue.ID)
+	eventChan := manager.RegisterResource(resource)
+	defer func() {
+		manager.UnregisterResource(resource, eventChan)
+		// ensure the eventChan is closed
+		for {
+			_, ok := <-eventChan
+			if !ok {
+				break
+			}
+		}
+	}()
+	expectIssueUpdate := func(updateType eventsource.IssueUpdateType) {
+		select {
+		case event := <-eventChan:
+			assert.Equal(t, eventsource.IssueUpdateEventName, event.Name)
+			if data, ok := event.Data.(*eventsource.IssueUpdate); assert.True(t, ok) {
+				assert.Equal(t, updateType, data.Type)
+				assert.Equal(t, issue.ID, data.IssueID)
+			}
+		case <-time.After(10 * time.Second):
+			assert.Fail(t, "no issue update", "%s", updateType)
+		}
+	}
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/%d/comments?token=%s", issue.Index, token), &api.CreateIssueCommentOption{
+		Body: "a live comment",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	expectIssueUpdate(eventsource.IssueUpdateComment)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/%d/labels?token=%s", issue.Index, token), &api.IssueLabelsOption{
+		Labels: []int64{2},
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	expectIssueUpdate(eventsource.IssueUpdateLabels)
+
+	closed := string(api.StateClosed)
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/issues/%d?token=%s", issue.Index, token), &api.EditIssueOption{
+		State: &closed,
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+	expectIssueUpdate(eventsource.IssueUpdateStatus)
+
+	// the updates of the issues of a private repository are only streamed to its readers
+	req = NewRequest(t, "GET", "/user2/repo2/issues/1/events")
+	MakeRequest(t, req, http.StatusNotFound)
+}
@@ -556,3 +556,106 @@ func TestUpdateIssueDeadline(t *testing.T) {
 
 	assert.EqualValues(t, "2022-04-06", apiIssue.Deadline.Format("2006-01-02"))
 }
+
+func TestNewIssueForm(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+		_, err := createFileInBranch(user2, repo1, ".gitea/ISSUE_TEMPLATE/bug.yaml", repo1.DefaultBranch, `name: Bug Report
+description: File a bug report
+title: "[Bug]: "
+body:
+  - type: input
+    id: version
+    attributes:
+      label: Version
+    validations:
+      required: true
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Terms
+      options:
+        - label: I searched the existing issues
+          required: true
+`)
+		assert.NoError(t, err)
+		_, err = createFileInBranch(user2, repo1, ".gitea/ISSUE_TEMPLATE/config.yml", repo1.DefaultBranch, `blank_issues_enabled: false
+contact_links:
+  - name: Forum
+    url: https://forum.example.com
+    about: Ask questions in the forum
+`)
+		assert.NoError(t, err)
+
+		session := loginUser(t, user2.Name)
+
+		// blank issues are disabled
+		req := NewRequest(t, "GET", "/user2/repo1/issues/new")
+		resp := session.MakeRequest(t, req, http.StatusSeeOther)
+		assert.Equal(t, "/user2/repo1/issues/new/choose?", test.RedirectURL(resp))
+
+		req = NewRequest(t, "GET", "/user2/repo1/issues/new/choose")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, `a[href="https://forum.example.com"]`, true)
+		htmlDoc.AssertElement(t, `a[href$="/issues/new?template=bug.yaml"]`, true)
+
+		req = NewRequest(t, "GET", "/user2/repo1/issues/new?template=bug.yaml")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, "#content", false)
+		htmlDoc.AssertElement(t, `input[name="form-field-version"][required]`, true)
+		assert.Equal(t, "[Bug]: ", htmlDoc.GetInputValueByName("title"))
+		assert.Equal(t, ".gitea/ISSUE_TEMPLATE/bug.yaml", htmlDoc.GetInputValueByName("template_file"))
+
+		link, _ := htmlDoc.doc.Find("form#new-issue").Attr("action")
+		values := map[string]string{
+			"_csrf":              htmlDoc.GetCSRF(),
+			"title":              "[Bug]: crash",
+			"template_file":      ".gitea/ISSUE_TEMPLATE/bug.yaml",
+			"form-field-version": "1.17.0",
+		}
+
+		// the required checkbox is missing
+		req = NewRequestWithValues(t, "POST", link, values)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Contains(t, htmlDoc.doc.Find(".ui.negative.message").Text(), `"Terms" is required`)
+		assert.Equal(t, "1.17.0", htmlDoc.GetInputValueByName("form-field-version"))
+
+		values["form-field-terms"] = "0"
+		req = NewRequestWithValues(t, "POST", link, values)
+		resp = session.MakeRequest(t, req, http.StatusSeeOther)
+		issueURL := test.RedirectURL(resp)
+		index, err := strconv.ParseInt(path.Base(issueURL), 10, 64)
+		assert.NoError(t, err)
+
+		issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: repo1.ID, Index: index})
+		assert.Equal(t, "### Version\n\n1.17.0\n\n### Terms\n\n- [x] I searched the existing issues\n", issue.Content)
+
+		// the API exposes the schema of the form and the config
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issue_templates")
+		resp = MakeRequest(t, req, http.StatusOK)
+		var templates []*api.IssueTemplate
+		DecodeJSON(t, resp, &templates)
+		if assert.Len(t, templates, 1) {
+			assert.Equal(t, "File a bug report", templates[0].About)
+			assert.Len(t, templates[0].Fields, 2)
+			assert.True(t, templates[0].Fields[1].Attributes.Options[0].Required)
+		}
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issue_config")
+		resp = MakeRequest(t, req, http.StatusOK)
+		var issueConfig api.IssueConfig
+		DecodeJSON(t, resp, &issueConfig)
+		assert.False(t, issueConfig.BlankIssuesEnabled)
+		assert.Len(t, issueConfig.ContactLinks, 1)
+
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issue_config/validate")
+		resp = MakeRequest(t, req, http.StatusOK)
+		var validation api.IssueConfigValidation
+		DecodeJSON(t, resp, &validation)
+		assert.True(t, validation.Valid)
+	})
+}
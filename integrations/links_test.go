@@ -36,6 +36,10 @@ func TestLinksNoLogin(t *testing.T) {
 		"/user2/repo1/",
 		"/user2/repo1/projects",
 		"/user2/repo1/projects/1",
+		"/user2/-/projects",
+		"/user2/-/projects/5",
+		"/user3/-/projects",
+		"/user3/-/projects/4",
 		"/assets/img/404.png",
 		"/assets/img/500.png",
 	}
@@ -56,6 +60,7 @@ func TestRedirectsNoLogin(t *testing.T) {
 		"/user2/repo1/src/master/directory/file.txt": "/user2/repo1/src/branch/master/directory/file.txt",
 		"/user/avatar/Ghost/-1":                      "/assets/img/avatar_default.png",
 		"/api/v1/swagger":                            "/api/swagger",
+		"/user2?tab=projects":                        "/user2/-/projects",
 	}
 	for link, redirectLink := range redirects {
 		req := NewRequest(t, "GET", link)
@@ -117,6 +122,8 @@ func testLinksAsUser(userName string, t *testing.T) {
 		"/user2",
 		"/user2?tab=stars",
 		"/user2?tab=activity",
+		"/user2/-/projects/new",
+		"/user3/-/projects/new",
 		"/user/settings",
 		"/user/settings/account",
 		"/user/settings/security",
@@ -6,9 +6,11 @@ package integrations
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
@@ -96,3 +98,85 @@ func TestMirrorPull(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, initCount, count)
 }
+
+func TestMirrorPullForcePush(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	if git.CheckGitVersionAtLeast("2.29") != nil {
+		t.Skip("negative refspecs require git 2.29")
+	}
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	repoPath := repo_model.RepoPath(user.Name, repo.Name)
+
+	opts := migration.MigrateOptions{
+		RepoName:  "test_mirror_force_push",
+		Mirror:    true,
+		CloneAddr: repoPath,
+	}
+	mirrorRepo, err := repository.CreateRepository(user, user, models.CreateRepoOptions{
+		Name:     opts.RepoName,
+		IsMirror: opts.Mirror,
+		Status:   repo_model.RepositoryBeingMigrated,
+	})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	mirrorRepo, err = repository.MigrateRepositoryGitData(ctx, user, mirrorRepo, opts, nil)
+	assert.NoError(t, err)
+
+	m, err := repo_model.GetMirrorByRepoID(ctx, mirrorRepo.ID)
+	assert.NoError(t, err)
+	m.DetectForcePush = true
+	assert.NoError(t, repo_model.UpdateMirror(ctx, m))
+
+	oldCommitID, _, err := git.NewCommand(ctx, "rev-parse", "refs/heads/branch2").RunStdString(&git.RunOpts{Dir: repoPath})
+	assert.NoError(t, err)
+	oldCommitID = strings.TrimSpace(oldCommitID)
+	newCommitID, _, err := git.NewCommand(ctx, "rev-parse", "refs/heads/branch2~1").RunStdString(&git.RunOpts{Dir: repoPath})
+	assert.NoError(t, err)
+	newCommitID = strings.TrimSpace(newCommitID)
+
+	// rewind branch2 upstream
+	_, _, err = git.NewCommand(ctx, "update-ref", "refs/heads/branch2", newCommitID).RunStdString(&git.RunOpts{Dir: repoPath})
+	assert.NoError(t, err)
+
+	assert.True(t, mirror_service.SyncPullMirror(ctx, mirrorRepo.ID))
+
+	backups, _, err := repo_model.GetMirrorBackups(ctx, mirrorRepo.ID, db.ListOptions{})
+	assert.NoError(t, err)
+	if !assert.Len(t, backups, 1) {
+		return
+	}
+	backup := backups[0]
+	assert.Equal(t, "refs/heads/branch2", backup.RefName)
+	assert.Equal(t, oldCommitID, backup.OldCommitID)
+	assert.Equal(t, newCommitID, backup.NewCommitID)
+	assert.True(t, strings.HasPrefix(backup.BackupRefName, git.MirrorBackupPrefix))
+
+	mirrorGitRepo, err := git.OpenRepository(ctx, mirrorRepo.RepoPath())
+	assert.NoError(t, err)
+	defer mirrorGitRepo.Close()
+
+	branchCommitID, err := mirrorGitRepo.GetRefCommitID("refs/heads/branch2")
+	assert.NoError(t, err)
+	assert.Equal(t, newCommitID, branchCommitID)
+
+	// the backup ref survives the prune of the next sync
+	assert.True(t, mirror_service.SyncPullMirror(ctx, mirrorRepo.ID))
+	backupCommitID, err := mirrorGitRepo.GetRefCommitID(backup.BackupRefName)
+	assert.NoError(t, err)
+	assert.Equal(t, oldCommitID, backupCommitID)
+
+	// a fast-forward is not a force-push
+	_, _, err = git.NewCommand(ctx, "update-ref", "refs/heads/branch2", oldCommitID).RunStdString(&git.RunOpts{Dir: repoPath})
+	assert.NoError(t, err)
+	assert.True(t, mirror_service.SyncPullMirror(ctx, mirrorRepo.ID))
+	unittest.AssertCount(t, &repo_model.MirrorBackup{RepoID: mirrorRepo.ID}, 1)
+
+	assert.NoError(t, mirror_service.DeleteMirrorBackup(ctx, mirrorRepo, backup))
+	unittest.AssertCount(t, &repo_model.MirrorBackup{RepoID: mirrorRepo.ID}, 0)
+	_, err = mirrorGitRepo.GetRefCommitID(backup.BackupRefName)
+	assert.Error(t, err)
+}
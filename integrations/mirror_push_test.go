@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 
@@ -78,6 +79,86 @@ func testMirrorPush(t *testing.T, u *url.URL) {
 	assert.Len(t, mirrors, 0)
 }
 
+func TestMirrorPushRefFilter(t *testing.T) {
+	onGiteaRun(t, testMirrorPushRefFilter)
+}
+
+func testMirrorPushRefFilter(t *testing.T, u *url.URL) {
+	defer prepareTestEnv(t)()
+
+	setting.Migrations.AllowLocalNetworks = true
+	assert.NoError(t, migrations.Init())
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	srcRepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+	mirrorRepo, err := repository.CreateRepository(user, user, models.CreateRepoOptions{
+		Name: "test-push-mirror-filter",
+	})
+	assert.NoError(t, err)
+
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session)
+	urlPrefix := fmt.Sprintf("/api/v1/repos/%s/%s/push_mirrors", user.Name, srcRepo.Name)
+
+	req := NewRequestWithJSON(t, "POST", urlPrefix+"?token="+token, &api.CreatePushMirrorOption{
+		RemoteAddress:    fmt.Sprintf("%s%s/%s", u.String(), url.PathEscape(user.Name), url.PathEscape(mirrorRepo.Name)),
+		RemoteUsername:   user.LowerName,
+		RemotePassword:   userPassword,
+		Interval:         "0",
+		RefFilter:        "master;refs/tags/v*",
+		ProtectForcePush: true,
+	})
+	resp := MakeRequest(t, req, http.StatusOK)
+	var apiMirror api.PushMirror
+	DecodeJSON(t, resp, &apiMirror)
+	assert.Equal(t, "master;refs/tags/v*", apiMirror.RefFilter)
+	assert.True(t, apiMirror.ProtectForcePush)
+
+	m, err := repo_model.GetPushMirror(db.DefaultContext, repo_model.PushMirrorOptions{RepoID: srcRepo.ID, RemoteName: apiMirror.RemoteName})
+	assert.NoError(t, err)
+	assert.True(t, mirror_service.SyncPushMirror(context.Background(), m.ID))
+
+	mirrorGitRepo, err := git.OpenRepository(git.DefaultContext, mirrorRepo.RepoPath())
+	assert.NoError(t, err)
+	defer mirrorGitRepo.Close()
+
+	branches, _, err := mirrorGitRepo.GetBranchNames(0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master"}, branches)
+	assert.True(t, mirrorGitRepo.IsTagExist("v1.1"))
+
+	// a commit in the mirror makes the push of master a non-fast-forward
+	mirrorRepo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: mirrorRepo.ID})
+	_, err = createFileInBranch(user, mirrorRepo, "diverged.txt", "master", "diverged")
+	assert.NoError(t, err)
+	assert.False(t, mirror_service.SyncPushMirror(context.Background(), m.ID))
+
+	req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/refs?token=%s", urlPrefix, m.RemoteName, token))
+	resp = MakeRequest(t, req, http.StatusOK)
+	var refs []*api.PushMirrorRef
+	DecodeJSON(t, resp, &refs)
+	if assert.Len(t, refs, 2) {
+		assert.Equal(t, "refs/heads/master", refs[0].RefName)
+		assert.Equal(t, "rejected", refs[0].Status)
+		assert.Contains(t, refs[0].Error, "[rejected]")
+		assert.NotEmpty(t, refs[0].CommitID)
+		assert.Equal(t, "refs/tags/v1.1", refs[1].RefName)
+		assert.Equal(t, "synced", refs[1].Status)
+	}
+
+	req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/errors?token=%s", urlPrefix, m.RemoteName, token))
+	resp = MakeRequest(t, req, http.StatusOK)
+	var syncErrors []*api.PushMirrorSyncError
+	DecodeJSON(t, resp, &syncErrors)
+	if assert.Len(t, syncErrors, 1) {
+		assert.Equal(t, "refs/heads/master", syncErrors[0].RefName)
+	}
+
+	req = NewRequest(t, "GET", fmt.Sprintf("%s/unknown/refs?token=%s", urlPrefix, token))
+	MakeRequest(t, req, http.StatusNotFound)
+}
+
 func doCreatePushMirror(ctx APITestContext, address, username, password string) func(t *testing.T) {
 	return func(t *testing.T) {
 		csrf := GetCSRF(t, ctx.Session, fmt.Sprintf("/%s/%s/settings", url.PathEscape(ctx.Username), url.PathEscape(ctx.Reponame)))
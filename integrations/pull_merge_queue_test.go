@@ -0,0 +1,134 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+	files_service "code.gitea.io/gitea/services/repository/files"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullMergeQueue(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "merge-queue")
+		t.Run("CreateRepo", doAPICreateRepository(ctx, false))
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerID: user2.ID, Name: "merge-queue"})
+
+		req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/merge-queue/branch_protections?token=%s", ctx.Token), &api.CreateBranchProtectionOption{
+			BranchName:          repo.DefaultBranch,
+			EnableMergeQueue:    true,
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"ci"},
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusCreated)
+
+		flush := func() {
+			assert.NoError(t, queue.GetManager().FlushAll(context.Background(), 5*time.Second))
+		}
+		setStatus := func(sha string, state api.CommitStatusState) {
+			req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/merge-queue/statuses/%s?token=%s", sha, ctx.Token), &api.CreateStatusOption{
+				State:   state,
+				Context: "ci",
+			})
+			ctx.Session.MakeRequest(t, req, http.StatusCreated)
+			flush()
+		}
+		queuePull := func(branch string) *pull_model.MergeQueueEntry {
+			_, err := files_service.CreateOrUpdateRepoFile(git.DefaultContext, repo, user2, &files_service.UpdateRepoFileOptions{
+				OldBranch: repo.DefaultBranch,
+				NewBranch: branch,
+				TreePath:  branch + ".txt",
+				Content:   branch,
+				IsNewFile: true,
+			})
+			assert.NoError(t, err)
+			pr, err := doAPICreatePullRequest(ctx, "user2", "merge-queue", repo.DefaultBranch, branch)(t)
+			assert.NoError(t, err)
+			setStatus(pr.Head.Sha, api.CommitStatusSuccess)
+
+			mergeCtx := ctx
+			mergeCtx.ExpectedCode = http.StatusAccepted
+			t.Run("Merge "+branch, doAPIMergePullRequest(mergeCtx, "user2", "merge-queue", pr.Index))
+			flush()
+			return unittest.AssertExistsAndLoadBean(t, &pull_model.MergeQueueEntry{PullID: pr.ID})
+		}
+
+		first := queuePull("first")
+		second := queuePull("second")
+		baseCommitID, err := git.GetFullCommitID(git.DefaultContext, repo.RepoPath(), repo.DefaultBranch)
+		assert.NoError(t, err)
+
+		// every entry is speculatively merged onto the one ahead of it
+		assert.Equal(t, baseCommitID, first.BaseCommitID)
+		assert.NotEmpty(t, first.MergeCommitID)
+		assert.Equal(t, first.MergeCommitID, second.BaseCommitID)
+		assert.True(t, git.IsBranchExist(git.DefaultContext, repo.RepoPath(), fmt.Sprintf("gitea-merge-queue/%s/pr-1", repo.DefaultBranch)))
+		assert.True(t, git.IsBranchExist(git.DefaultContext, repo.RepoPath(), fmt.Sprintf("gitea-merge-queue/%s/pr-2", repo.DefaultBranch)))
+
+		// the second entry has to wait for the first one
+		setStatus(second.MergeCommitID, api.CommitStatusSuccess)
+		unittest.AssertExistsAndLoadBean(t, &pull_model.MergeQueueEntry{PullID: second.PullID})
+		pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: second.PullID})
+		assert.False(t, pr.HasMerged)
+
+		// both land once the first one succeeds
+		setStatus(first.MergeCommitID, api.CommitStatusSuccess)
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: first.PullID})
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: second.PullID})
+		pr = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: first.PullID})
+		assert.True(t, pr.HasMerged)
+		assert.Equal(t, first.MergeCommitID, pr.MergedCommitID)
+		pr = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: second.PullID})
+		assert.True(t, pr.HasMerged)
+		baseCommitID, err = git.GetFullCommitID(git.DefaultContext, repo.RepoPath(), repo.DefaultBranch)
+		assert.NoError(t, err)
+		assert.Equal(t, second.MergeCommitID, baseCommitID)
+		assert.False(t, git.IsBranchExist(git.DefaultContext, repo.RepoPath(), fmt.Sprintf("gitea-merge-queue/%s/pr-1", repo.DefaultBranch)))
+		assert.False(t, git.IsBranchExist(git.DefaultContext, repo.RepoPath(), fmt.Sprintf("gitea-merge-queue/%s/pr-2", repo.DefaultBranch)))
+
+		// an entry whose checks fail is ejected
+		third := queuePull("third")
+		assert.Equal(t, baseCommitID, third.BaseCommitID)
+		setStatus(third.MergeCommitID, api.CommitStatusFailure)
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: third.PullID})
+		pr = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: third.PullID})
+		assert.False(t, pr.HasMerged)
+		unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{
+			IssueID: pr.IssueID,
+			Type:    issues_model.CommentTypePRRemovedFromMergeQueue,
+			Content: "checks_failed",
+		})
+
+		// an entry can be removed by hand
+		fourth := queuePull("fourth")
+		pr = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: fourth.PullID})
+		session := loginUser(t, "user2")
+		link := fmt.Sprintf("/user2/merge-queue/pulls/%d", pr.Index)
+		session.MakeRequest(t, NewRequest(t, "GET", link), http.StatusOK)
+		req = NewRequestWithValues(t, "POST", link+"/remove_from_merge_queue", map[string]string{
+			"_csrf": GetCSRF(t, session, link),
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+		unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: fourth.PullID})
+		req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user2/merge-queue/pulls/%d/merge?token=%s", pr.Index, ctx.Token))
+		ctx.Session.MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/automerge"
+	"code.gitea.io/gitea/services/forms"
+	files_service "code.gitea.io/gitea/services/repository/files"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullMergeWindow(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "merge-window")
+		t.Run("CreateRepo", doAPICreateRepository(ctx, false))
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerID: user2.ID, Name: "merge-window"})
+
+		// an invalid window is rejected
+		protectionURL := fmt.Sprintf("/api/v1/repos/user2/merge-window/branch_protections?token=%s", ctx.Token)
+		req := NewRequestWithJSON(t, "POST", protectionURL, &api.CreateBranchProtectionOption{
+			BranchName:   repo.DefaultBranch,
+			MergeWindows: "Mon-Someday",
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		// the only window is tomorrow
+		tomorrow := time.Now().In(setting.DefaultUILocation).AddDate(0, 0, 1).Weekday().String()
+		req = NewRequestWithJSON(t, "POST", protectionURL, &api.CreateBranchProtectionOption{
+			BranchName:   repo.DefaultBranch,
+			MergeWindows: tomorrow,
+		})
+		resp := ctx.Session.MakeRequest(t, req, http.StatusCreated)
+		var protection api.BranchProtection
+		DecodeJSON(t, resp, &protection)
+		assert.Equal(t, tomorrow, protection.MergeWindows)
+
+		_, err := files_service.CreateOrUpdateRepoFile(git.DefaultContext, repo, user2, &files_service.UpdateRepoFileOptions{
+			OldBranch: repo.DefaultBranch,
+			NewBranch: "scheduled",
+			TreePath:  "scheduled.txt",
+			Content:   "scheduled",
+			IsNewFile: true,
+		})
+		assert.NoError(t, err)
+		pr, err := doAPICreatePullRequest(ctx, "user2", "merge-window", repo.DefaultBranch, "scheduled")(t)
+		assert.NoError(t, err)
+
+		session := loginUser(t, "user2")
+		resp = session.MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("/user2/merge-window/pulls/%d", pr.Index)), http.StatusOK)
+		assert.Contains(t, NewHTMLParser(t, resp.Body).doc.Find(".merge.box").Text(), "outside of its merge windows")
+
+		// the merge is scheduled
+		mergeAt := time.Now().Add(time.Hour).Unix()
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/merge-window/pulls/%d/merge?token=%s", pr.Index, ctx.Token), &forms.MergePullRequestForm{
+			Do:      string(repo_model.MergeStyleMerge),
+			MergeAt: mergeAt,
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusCreated)
+		scheduled := unittest.AssertExistsAndLoadBean(t, &pull_model.AutoMerge{PullID: pr.ID})
+		assert.EqualValues(t, mergeAt, scheduled.ScheduledUnix)
+		assert.False(t, scheduled.IsDue())
+
+		// it isn't merged before its time, nor outside of the merge windows
+		flush := func() {
+			assert.NoError(t, queue.GetManager().FlushAll(context.Background(), 5*time.Second))
+		}
+		assert.NoError(t, automerge.MergePendingScheduledPullRequests(db.DefaultContext))
+		flush()
+		assert.False(t, unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: pr.ID}).HasMerged)
+
+		_, err = db.GetEngine(db.DefaultContext).ID(scheduled.ID).Cols("scheduled_unix").Update(&pull_model.AutoMerge{ScheduledUnix: 1})
+		assert.NoError(t, err)
+		assert.NoError(t, automerge.MergePendingScheduledPullRequests(db.DefaultContext))
+		flush()
+		assert.False(t, unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: pr.ID}).HasMerged)
+
+		// it is merged once the windows allow it
+		empty := ""
+		req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/merge-window/branch_protections/%s?token=%s", repo.DefaultBranch, ctx.Token), &api.EditBranchProtectionOption{
+			MergeWindows: &empty,
+		})
+		ctx.Session.MakeRequest(t, req, http.StatusOK)
+		assert.NoError(t, automerge.MergePendingScheduledPullRequests(db.DefaultContext))
+		flush()
+		assert.True(t, unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: pr.ID}).HasMerged)
+		unittest.AssertNotExistsBean(t, &pull_model.AutoMerge{PullID: pr.ID})
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	pull_service "code.gitea.io/gitea/services/pull"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullStackRetarget(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "stack-base", "README.md", "Stack base\n")
+		testEditFileToNewBranch(t, session, "user2", "repo1", "stack-base", "stack-top", "README.md", "Stack top\n")
+
+		createPull := func(head, base string) *api.PullRequest {
+			req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/pulls?token="+token, &api.CreatePullRequestOption{
+				Head:  head,
+				Base:  base,
+				Title: "Stacked " + head,
+			})
+			resp := session.MakeRequest(t, req, http.StatusCreated)
+			var pull *api.PullRequest
+			DecodeJSON(t, resp, &pull)
+			return pull
+		}
+		basePull := createPull("stack-base", "master")
+		topPull := createPull("stack-top", "stack-base")
+
+		// the stack is shown on the pull requests of the stack
+		req := NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/pulls/%d", basePull.Index))
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		stack := NewHTMLParser(t, resp.Body).doc.Find(".pull-stack .item")
+		if assert.Equal(t, 2, stack.Length()) {
+			assert.Contains(t, stack.Eq(0).Text(), fmt.Sprintf("#%d", basePull.Index))
+			assert.True(t, stack.Eq(0).HasClass("current"))
+			assert.Contains(t, stack.Eq(1).Text(), fmt.Sprintf("#%d", topPull.Index))
+		}
+
+		testPullMerge(t, session, "user2", "repo1", fmt.Sprint(basePull.Index), repo_model.MergeStyleSquash)
+
+		// the stacked pull request targets the base branch of the merged one and was rebased on it
+		pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: topPull.ID})
+		assert.Equal(t, "master", pr.BaseBranch)
+		assert.False(t, pr.HasMerged)
+		unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: pr.IssueID, Type: issues_model.CommentTypeChangeTargetBranch, OldRef: "stack-base", NewRef: "master"})
+		divergence, err := pull_service.GetDiverging(git.DefaultContext, pr)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, divergence.Behind)
+		assert.EqualValues(t, 1, divergence.Ahead)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/pulls/%d", topPull.Index))
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Zero(t, NewHTMLParser(t, resp.Body).doc.Find(".pull-stack").Length())
+
+		// the commits of a squashed pull request with several commits are not replayed on the stacked one
+		testEditFileToNewBranch(t, session, "user2", "repo1", "master", "stack-multi-base", "README.md", "Stack multi base 1\n")
+		testEditFile(t, session, "user2", "repo1", "stack-multi-base", "README.md", "Stack multi base 2\n")
+		testEditFileToNewBranch(t, session, "user2", "repo1", "stack-multi-base", "stack-multi-top", "README.md", "Stack multi top 1\n")
+		testEditFile(t, session, "user2", "repo1", "stack-multi-top", "README.md", "Stack multi top 2\n")
+		basePull = createPull("stack-multi-base", "master")
+		topPull = createPull("stack-multi-top", "stack-multi-base")
+
+		testPullMerge(t, session, "user2", "repo1", fmt.Sprint(basePull.Index), repo_model.MergeStyleSquash)
+
+		pr = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: topPull.ID})
+		assert.Equal(t, "master", pr.BaseBranch)
+		divergence, err = pull_service.GetDiverging(git.DefaultContext, pr)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, divergence.Behind)
+		assert.EqualValues(t, 2, divergence.Ahead)
+	})
+}
@@ -7,9 +7,12 @@ package integrations
 import (
 	"net/http"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/test"
 
 	"github.com/stretchr/testify/assert"
@@ -79,3 +82,61 @@ func testViewTimetrackingControls(t *testing.T, session *TestSession, user, repo
 		session.MakeRequest(t, req, http.StatusNotFound)
 	}
 }
+
+func TestTimeReport(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/time-report")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, "select[name=group]", true)
+
+	req = NewRequest(t, "GET", "/user2/repo1/time-report?group=user&format=json")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var report struct {
+		GroupBy      string `json:"group_by"`
+		TotalSeconds int64  `json:"total_seconds"`
+		Count        int    `json:"count"`
+		Rows         []struct {
+			Key     string `json:"key"`
+			Seconds int64  `json:"seconds"`
+		} `json:"rows"`
+	}
+	DecodeJSON(t, resp, &report)
+	assert.Equal(t, "user", report.GroupBy)
+	assert.EqualValues(t, 4083, report.TotalSeconds)
+	assert.Equal(t, 5, report.Count)
+	if assert.Len(t, report.Rows, 2) {
+		assert.Equal(t, "user2", report.Rows[0].Key)
+		assert.EqualValues(t, 3663, report.Rows[0].Seconds)
+	}
+
+	req = NewRequest(t, "GET", "/user2/repo1/time-report?group=issue&format=csv")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n")
+	assert.Equal(t, "issue,title,estimate_hours,hours,seconds,entries", lines[0])
+	assert.Equal(t, "total,,,1.13,4083,5", lines[len(lines)-1])
+
+	// the estimate is shown next to the tracked time of the issue
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/times/estimate", map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+		"hours": "2",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: 1, Index: 1})
+	assert.EqualValues(t, 2*3600, issue.TimeEstimate)
+
+	req = NewRequest(t, "GET", "/org/user3/time-report?group=issue")
+	session.MakeRequest(t, req, http.StatusOK)
+
+	// users who can't write the issues only see their own times
+	session = loginUser(t, "user5")
+	req = NewRequest(t, "GET", "/user2/repo1/time-report?group=user&format=json")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &report)
+	assert.EqualValues(t, 0, report.TotalSeconds)
+}
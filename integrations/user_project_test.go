@@ -0,0 +1,72 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserProjectAddIssue(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	addIssue := func(t *testing.T, userName, title string) int64 {
+		session := loginUser(t, userName)
+		req := NewRequestWithValues(t, "POST", fmt.Sprintf("/%s/-/projects/new", userName), map[string]string{
+			"_csrf":      GetCSRF(t, session, fmt.Sprintf("/%s/-/projects/new", userName)),
+			"title":      title,
+			"board_type": "0",
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+		project := unittest.AssertExistsAndLoadBean(t, &project_model.Project{Title: title})
+
+		projectLink := fmt.Sprintf("/%s/-/projects/%d", userName, project.ID)
+		req = NewRequestWithValues(t, "POST", projectLink+"/issues", map[string]string{
+			"_csrf": GetCSRF(t, session, projectLink),
+			"issue": "user2/repo1#1",
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+		return project.ID
+	}
+
+	// user4 can read the issue of user2/repo1 but not change it, or take it from its project
+	addIssue(t, "user4", "read only")
+	assert.EqualValues(t, 1, unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1}).ProjectID())
+
+	projectID := addIssue(t, "user2", "writable")
+	assert.EqualValues(t, projectID, unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1}).ProjectID())
+}
+
+func TestUserProjectRemoveIssue(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user4")
+	req := NewRequestWithValues(t, "POST", "/user4/-/projects/new", map[string]string{
+		"_csrf":      GetCSRF(t, session, "/user4/-/projects/new"),
+		"title":      "read only",
+		"board_type": "0",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	project := unittest.AssertExistsAndLoadBean(t, &project_model.Project{Title: "read only"})
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1})
+	assert.NoError(t, issues_model.ChangeProjectAssign(issue, unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}), project.ID))
+
+	// user4 can read the issue of user2/repo1 but not change it
+	projectLink := fmt.Sprintf("/user4/-/projects/%d", project.ID)
+	req = NewRequestWithValues(t, "POST", projectLink+"/issues/1/remove", map[string]string{
+		"_csrf": GetCSRF(t, session, projectLink),
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+	assert.EqualValues(t, project.ID, unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1}).ProjectID())
+}
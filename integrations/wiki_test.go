@@ -0,0 +1,177 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWikiSearch(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	repo, err := repo_model.GetRepositoryByOwnerAndName("user2", "repo1")
+	assert.NoError(t, err)
+	executeIndexer(t, repo, code_indexer.UpdateWikiIndexer)
+
+	req := NewRequest(t, "GET", "/user2/repo1/wiki/?action=_search&q=name+with+spaces")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	results := htmlDoc.doc.Find(".repository.search .repo-search-result a.file")
+	if assert.Equal(t, 1, results.Length()) {
+		assert.Equal(t, "Page With Spaced Name", results.Text())
+		href, _ := results.Attr("href")
+		assert.Equal(t, "/user2/repo1/wiki/Page-With-Spaced-Name", href)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/search?q=name+with+spaces")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiResults []*api.WikiSearchResult
+	DecodeJSON(t, resp, &apiResults)
+	if assert.Len(t, apiResults, 1) {
+		assert.Equal(t, "Page With Spaced Name", apiResults[0].Title)
+		assert.Equal(t, "Page-With-Spaced-Name", apiResults[0].SubURL)
+		assert.Contains(t, apiResults[0].Snippet, "a name with spaces")
+		assert.Contains(t, apiResults[0].LineNumbers, 3)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/search?q=nonexistentkeyword")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiResults)
+	assert.Empty(t, apiResults)
+}
+
+func TestWikiCompare(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/wiki/compare/2c54fae...c10d10b/Page-With-Spaced-Name")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	files := htmlDoc.doc.Find(".diff-file-box")
+	if assert.Equal(t, 1, files.Length()) {
+		name, _ := files.Attr("data-new-filename")
+		assert.Equal(t, "Page-With-Spaced-Name.md", name)
+	}
+
+	// the pages which didn't change between the revisions have no diff
+	req = NewRequest(t, "GET", "/user2/repo1/wiki/compare/c10d10b...0dca5bd/Home")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 0, htmlDoc.doc.Find(".diff-file-box").Length())
+
+	req = NewRequest(t, "GET", "/user2/repo1/wiki/compare/2c54fae...c10d10b/Nonexistent")
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/user2/repo1/wiki/Page-With-Spaced-Name?action=_compare&before=2c54fae&after=c10d10b")
+	resp = session.MakeRequest(t, req, http.StatusSeeOther)
+	assert.Equal(t, "/user2/repo1/wiki/compare/2c54fae...c10d10b/Page-With-Spaced-Name", resp.Header().Get("Location"))
+
+	// the revisions page offers to compare the revisions of the page
+	req = NewRequest(t, "GET", "/user2/repo1/wiki/Home?action=_revision")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.Equal(t, 0, htmlDoc.doc.Find("select#before").Length(), "a single revision can't be compared")
+}
+
+func TestWikiHierarchy(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		defer prepareTestEnv(t)()
+
+		// the wikis of the fixtures have no hooks, the created ones get them
+		repo, err := repo_model.GetRepositoryByOwnerAndName("user2", "repo1")
+		assert.NoError(t, err)
+		assert.NoError(t, repo_module.CreateDelegateHooks(repo.WikiPath()))
+
+		// the pages in subdirectories are pushed from a clone of the wiki
+		dstPath, err := os.MkdirTemp("", "wiki_hierarchy")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+		wikiURL, _ := url.Parse(fmt.Sprintf("%suser2/repo1.wiki.git", u.String()))
+		wikiURL.User = url.UserPassword("user2", userPassword)
+		assert.NoError(t, git.CloneWithArgs(context.Background(), wikiURL.String(), dstPath, git.AllowLFSFiltersArgs(), git.CloneRepoOptions{}))
+
+		assert.NoError(t, os.MkdirAll(filepath.Join(dstPath, "docs", "setup"), 0o755))
+		for name, content := range map[string]string{
+			"docs/setup/Install-Guide.md": "# Install\n\n[[Home]] [[Missing Page]] [image](jpeg.jpg)",
+			"docs/_Sidebar.md":            "The docs sidebar",
+			"docs/_Footer.md":             "The docs footer",
+		} {
+			assert.NoError(t, os.WriteFile(filepath.Join(dstPath, name), []byte(content), 0o644))
+		}
+		assert.NoError(t, git.AddChanges(dstPath, true))
+		signature := git.Signature{Email: "user2@example.com", Name: "User Two", When: time.Now()}
+		assert.NoError(t, git.CommitChanges(dstPath, git.CommitChangesOptions{
+			Committer: &signature,
+			Author:    &signature,
+			Message:   "Add nested pages",
+		}))
+		doGitPushTestRepository(dstPath, "origin", "master")(t)
+
+		session := loginUser(t, "user2")
+		req := NewRequest(t, "GET", "/user2/repo1/wiki/docs/setup/Install-Guide")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.Equal(t, "docs / setup /", strings.Join(strings.Fields(htmlDoc.doc.Find(".wiki-breadcrumbs").Text()), " "))
+		assert.Contains(t, htmlDoc.doc.Find(".wiki-content-sidebar").Text(), "The docs sidebar")
+		assert.Contains(t, htmlDoc.doc.Find(".wiki-content-footer").Text(), "The docs footer")
+		assert.Equal(t, "Missing Page", htmlDoc.doc.Find(".wiki-broken-links code").Text())
+
+		// the pages outside of the directory get the generated page tree
+		req = NewRequest(t, "GET", "/user2/repo1/wiki/Home")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Equal(t, 0, htmlDoc.doc.Find(".wiki-content-footer").Length())
+		link, _ := htmlDoc.doc.Find(".wiki-page-tree a:contains('Install Guide')").Attr("href")
+		assert.Equal(t, "/user2/repo1/wiki/docs%2Fsetup%2FInstall-Guide", link)
+
+		req = NewRequest(t, "GET", "/user2/repo1/wiki/?action=_pages")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "docs/setup/Install Guide")
+
+		req = NewRequest(t, "GET", "/user2/repo1/wiki/?action=_broken_links")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		rows := htmlDoc.doc.Find("table tr")
+		if assert.Equal(t, 1, rows.Length()) {
+			assert.Contains(t, rows.Text(), "docs/setup/Install Guide")
+			assert.Equal(t, "Missing Page", rows.Find("code").Text())
+		}
+
+		var page *api.WikiPage
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/page/docs%2Fsetup%2FInstall-Guide")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &page)
+		assert.EqualValues(t, 1, page.CommitCount)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("The docs sidebar")), page.Sidebar)
+
+		// the push updated the search index of the wiki
+		var searchResults []*api.WikiSearchResult
+		req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/search?q=install")
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &searchResults)
+		if assert.Len(t, searchResults, 1) {
+			assert.Equal(t, "docs%2Fsetup%2FInstall-Guide", searchResults[0].SubURL)
+		}
+	})
+}
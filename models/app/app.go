@@ -0,0 +1,173 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrAppNotExist represents an "app does not exist" error
+type ErrAppNotExist struct {
+	ID   int64
+	Name string
+}
+
+// IsErrAppNotExist checks if an error is a ErrAppNotExist
+func IsErrAppNotExist(err error) bool {
+	_, ok := err.(ErrAppNotExist)
+	return ok
+}
+
+func (err ErrAppNotExist) Error() string {
+	return fmt.Sprintf("app does not exist [id: %d, name: %s]", err.ID, err.Name)
+}
+
+// ErrAppAlreadyExist represents an "app already exists" error
+type ErrAppAlreadyExist struct {
+	Name string
+}
+
+// IsErrAppAlreadyExist checks if an error is a ErrAppAlreadyExist
+func IsErrAppAlreadyExist(err error) bool {
+	_, ok := err.(ErrAppAlreadyExist)
+	return ok
+}
+
+func (err ErrAppAlreadyExist) Error() string {
+	return fmt.Sprintf("app already exists [name: %s]", err.Name)
+}
+
+// App is an integration registered by a user. It acts with its own bot user on the repositories of the organizations
+// which installed it, with the permissions of the app, and receives the events of these repositories on its webhook.
+type App struct {
+	ID          int64  `xorm:"pk autoincr"`
+	OwnerID     int64  `xorm:"INDEX NOT NULL"` // the user who registered the app
+	Name        string `xorm:"NOT NULL"`
+	LowerName   string `xorm:"UNIQUE NOT NULL"`
+	Description string `xorm:"TEXT"`
+	HomepageURL string `xorm:"TEXT"`
+	BotID       int64  `xorm:"INDEX NOT NULL"` // the user the app acts with
+	// the permissions granted to the installation tokens, in the format of the scopes of the personal access tokens
+	Permissions auth.AccessTokenScope `xorm:"TEXT"`
+	PublicKey   string                `xorm:"TEXT"`               // verifies the JWTs the app authenticates with, PEM encoded
+	WebhookID   int64                 `xorm:"NOT NULL DEFAULT 0"` // the webhook receiving the events, 0 if the app has none
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(App))
+}
+
+// PermissionCategories are the categories of the scopes an app can be granted,
+// the installation tokens are restricted to an organization so they can't act on the user or as an admin
+var PermissionCategories = []auth.AccessTokenScopeCategory{
+	auth.AccessTokenScopeCategoryRepository,
+	auth.AccessTokenScopeCategoryIssue,
+	auth.AccessTokenScopeCategoryPackage,
+	auth.AccessTokenScopeCategoryOrganization,
+}
+
+// AccessMode returns the access mode of the bot of the app on the repositories it is installed on,
+// the permissions of the app further restrict what it can do with its installation tokens
+func (a *App) AccessMode() perm.AccessMode {
+	for _, category := range []auth.AccessTokenScopeCategory{auth.AccessTokenScopeCategoryRepository, auth.AccessTokenScopeCategoryIssue} {
+		if a.Permissions.HasLevel(category, auth.AccessTokenScopeLevelWrite) {
+			return perm.AccessModeWrite
+		}
+	}
+	return perm.AccessModeRead
+}
+
+// CreateApp saves a new app, the name of an app is unique
+func CreateApp(ctx context.Context, a *App) error {
+	a.LowerName = strings.ToLower(a.Name)
+	has, err := db.GetEngine(ctx).Exist(&App{LowerName: a.LowerName})
+	if err != nil {
+		return err
+	} else if has {
+		return ErrAppAlreadyExist{a.Name}
+	}
+	return db.Insert(ctx, a)
+}
+
+// GetAppByID returns the app with the given id
+func GetAppByID(ctx context.Context, id int64) (*App, error) {
+	a := &App{}
+	has, err := db.GetEngine(ctx).ID(id).Get(a)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAppNotExist{ID: id}
+	}
+	return a, nil
+}
+
+// GetAppByName returns the app with the given name
+func GetAppByName(ctx context.Context, name string) (*App, error) {
+	a := &App{}
+	has, err := db.GetEngine(ctx).Where("lower_name = ?", strings.ToLower(name)).Get(a)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAppNotExist{Name: name}
+	}
+	return a, nil
+}
+
+// GetAppByBotID returns the app acting with the given bot user
+func GetAppByBotID(ctx context.Context, botID int64) (*App, error) {
+	a := &App{}
+	has, err := db.GetEngine(ctx).Where("bot_id = ?", botID).Get(a)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAppNotExist{}
+	}
+	return a, nil
+}
+
+// FindAppsOptions represents the options to find the apps
+type FindAppsOptions struct {
+	db.ListOptions
+	OwnerID int64
+}
+
+// FindApps returns the apps registered by a user
+func FindApps(ctx context.Context, opts FindAppsOptions) ([]*App, int64, error) {
+	sess := db.GetEngine(ctx).Where("owner_id = ?", opts.OwnerID).OrderBy("lower_name")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	apps := make([]*App, 0, 10)
+	count, err := sess.FindAndCount(&apps)
+	return apps, count, err
+}
+
+// DeleteApp removes an app with its installations, its bot user and its webhook must be removed by the caller
+func DeleteApp(ctx context.Context, a *App) error {
+	installationIDs := make([]int64, 0, 10)
+	if err := db.GetEngine(ctx).Table("app_installation").Cols("id").Where("app_id = ?", a.ID).Find(&installationIDs); err != nil {
+		return err
+	}
+	if len(installationIDs) > 0 {
+		if _, err := db.GetEngine(ctx).In("installation_id", installationIDs).Delete(new(InstallationRepo)); err != nil {
+			return err
+		}
+	}
+	if _, err := db.GetEngine(ctx).Where("app_id = ?", a.ID).Delete(new(Installation)); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).ID(a.ID).Delete(new(App))
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateApp(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.True(t, IsErrAppAlreadyExist(CreateApp(db.DefaultContext, &App{OwnerID: 2, Name: "Test-App"})))
+
+	a := &App{OwnerID: 2, Name: "Other-App", Permissions: "read:repository"}
+	assert.NoError(t, CreateApp(db.DefaultContext, a))
+	assert.Equal(t, "other-app", a.LowerName)
+	assert.Equal(t, perm.AccessModeRead, a.AccessMode())
+
+	loaded, err := GetAppByName(db.DefaultContext, "other-APP")
+	assert.NoError(t, err)
+	assert.Equal(t, a.ID, loaded.ID)
+
+	apps, count, err := FindApps(db.DefaultContext, FindAppsOptions{OwnerID: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Equal(t, "other-app", apps[0].LowerName)
+}
+
+func TestSaveInstallation(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	a := unittest.AssertExistsAndLoadBean(t, &App{ID: 1})
+	assert.Equal(t, perm.AccessModeWrite, a.AccessMode())
+
+	installed, err := IsInstalledOnRepo(db.DefaultContext, 1, 3, 3)
+	assert.NoError(t, err)
+	assert.True(t, installed)
+	installed, err = IsInstalledOnRepo(db.DefaultContext, 1, 3, 5)
+	assert.NoError(t, err)
+	assert.False(t, installed)
+
+	// reinstalling replaces the selected repositories
+	i, err := SaveInstallation(db.DefaultContext, 1, 3, false, []int64{5, 5, 32})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, i.ID)
+	repoIDs, err := GetInstallationRepoIDs(db.DefaultContext, i.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 32}, repoIDs)
+
+	apps, err := GetAppsInstalledOnRepo(db.DefaultContext, 3, 5)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 1)
+	apps, err = GetAppsInstalledOnRepo(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 0)
+
+	// an installation on all the repositories has no selected repositories
+	_, err = SaveInstallation(db.DefaultContext, 1, 3, true, []int64{5})
+	assert.NoError(t, err)
+	unittest.AssertNotExistsBean(t, &InstallationRepo{InstallationID: i.ID})
+	installed, err = IsInstalledOnRepo(db.DefaultContext, 1, 3, 3)
+	assert.NoError(t, err)
+	assert.True(t, installed)
+
+	assert.NoError(t, DeleteApp(db.DefaultContext, a))
+	unittest.AssertNotExistsBean(t, &Installation{ID: i.ID})
+	unittest.AssertNotExistsBean(t, &App{ID: a.ID})
+}
+
+func TestDeleteInstallationsByOwnerID(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, DeleteInstallationsByOwnerID(db.DefaultContext, 3))
+	unittest.AssertNotExistsBean(t, &Installation{ID: 1})
+	unittest.AssertNotExistsBean(t, &InstallationRepo{ID: 1})
+}
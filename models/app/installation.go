@@ -0,0 +1,224 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ErrInstallationNotExist represents an "installation does not exist" error
+type ErrInstallationNotExist struct {
+	ID      int64
+	AppID   int64
+	OwnerID int64
+}
+
+// IsErrInstallationNotExist checks if an error is a ErrInstallationNotExist
+func IsErrInstallationNotExist(err error) bool {
+	_, ok := err.(ErrInstallationNotExist)
+	return ok
+}
+
+func (err ErrInstallationNotExist) Error() string {
+	return fmt.Sprintf("app installation does not exist [id: %d, app_id: %d, owner_id: %d]", err.ID, err.AppID, err.OwnerID)
+}
+
+// Installation represents an app installed by an organization, on all of its repositories or on selected ones
+type Installation struct {
+	ID              int64 `xorm:"pk autoincr"`
+	AppID           int64 `xorm:"UNIQUE(app_owner) NOT NULL"`
+	OwnerID         int64 `xorm:"UNIQUE(app_owner) INDEX NOT NULL"`
+	AllRepositories bool  `xorm:"NOT NULL DEFAULT false"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName sets the table name of the installations
+func (Installation) TableName() string {
+	return "app_installation"
+}
+
+// InstallationRepo is a repository selected by an installation which is not on all the repositories
+type InstallationRepo struct {
+	ID             int64 `xorm:"pk autoincr"`
+	InstallationID int64 `xorm:"UNIQUE(s) NOT NULL"`
+	RepoID         int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+}
+
+// TableName sets the table name of the repositories of the installations
+func (InstallationRepo) TableName() string {
+	return "app_installation_repo"
+}
+
+func init() {
+	db.RegisterModel(new(Installation))
+	db.RegisterModel(new(InstallationRepo))
+}
+
+// GetInstallationByID returns the installation with the given id
+func GetInstallationByID(ctx context.Context, id int64) (*Installation, error) {
+	i := &Installation{}
+	has, err := db.GetEngine(ctx).ID(id).Get(i)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrInstallationNotExist{ID: id}
+	}
+	return i, nil
+}
+
+// GetInstallation returns the installation of an app by an organization
+func GetInstallation(ctx context.Context, appID, ownerID int64) (*Installation, error) {
+	i := &Installation{}
+	has, err := db.GetEngine(ctx).Where("app_id = ? AND owner_id = ?", appID, ownerID).Get(i)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrInstallationNotExist{AppID: appID, OwnerID: ownerID}
+	}
+	return i, nil
+}
+
+// FindInstallationsOptions represents the options to find the installations
+type FindInstallationsOptions struct {
+	db.ListOptions
+	AppID   int64
+	OwnerID int64
+}
+
+func (opts *FindInstallationsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.AppID > 0 {
+		cond = cond.And(builder.Eq{"app_id": opts.AppID})
+	}
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	return cond
+}
+
+// FindInstallations returns the installations of an app or of an organization
+func FindInstallations(ctx context.Context, opts FindInstallationsOptions) ([]*Installation, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).OrderBy("id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	installations := make([]*Installation, 0, 10)
+	count, err := sess.FindAndCount(&installations)
+	return installations, count, err
+}
+
+// GetInstallationRepoIDs returns the ids of the repositories selected by an installation
+func GetInstallationRepoIDs(ctx context.Context, installationID int64) ([]int64, error) {
+	repoIDs := make([]int64, 0, 10)
+	return repoIDs, db.GetEngine(ctx).Table("app_installation_repo").Cols("repo_id").
+		Where("installation_id = ?", installationID).OrderBy("repo_id").Find(&repoIDs)
+}
+
+// SaveInstallation creates or updates the installation of an app by an organization,
+// the repositories are only kept when the installation is not on all the repositories
+func SaveInstallation(ctx context.Context, appID, ownerID int64, allRepositories bool, repoIDs []int64) (*Installation, error) {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	i, err := GetInstallation(ctx, appID, ownerID)
+	if err != nil {
+		if !IsErrInstallationNotExist(err) {
+			return nil, err
+		}
+		i = &Installation{AppID: appID, OwnerID: ownerID, AllRepositories: allRepositories}
+		if err := db.Insert(ctx, i); err != nil {
+			return nil, err
+		}
+	} else {
+		i.AllRepositories = allRepositories
+		if _, err := db.GetEngine(ctx).ID(i.ID).Cols("all_repositories").Update(i); err != nil {
+			return nil, err
+		}
+		if _, err := db.GetEngine(ctx).Where("installation_id = ?", i.ID).Delete(new(InstallationRepo)); err != nil {
+			return nil, err
+		}
+	}
+
+	if !allRepositories {
+		seen := make(map[int64]bool, len(repoIDs))
+		for _, repoID := range repoIDs {
+			if seen[repoID] {
+				continue
+			}
+			seen[repoID] = true
+			if err := db.Insert(ctx, &InstallationRepo{InstallationID: i.ID, RepoID: repoID}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return i, committer.Commit()
+}
+
+// DeleteInstallation removes an installation with its repositories
+func DeleteInstallation(ctx context.Context, i *Installation) error {
+	if _, err := db.GetEngine(ctx).Where("installation_id = ?", i.ID).Delete(new(InstallationRepo)); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).ID(i.ID).Delete(new(Installation))
+	return err
+}
+
+// DeleteInstallationsByOwnerID removes all the installations of an organization
+func DeleteInstallationsByOwnerID(ctx context.Context, ownerID int64) error {
+	if _, err := db.GetEngine(ctx).
+		Where(builder.In("installation_id", builder.Select("id").From("app_installation").Where(builder.Eq{"owner_id": ownerID}))).
+		Delete(new(InstallationRepo)); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(new(Installation))
+	return err
+}
+
+// DeleteInstallationReposByRepoID removes a repository from the installations which selected it
+func DeleteInstallationReposByRepoID(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(new(InstallationRepo))
+	return err
+}
+
+// IsInstalledOnRepo returns whether an app is installed on a repository of an organization
+func IsInstalledOnRepo(ctx context.Context, appID, ownerID, repoID int64) (bool, error) {
+	i, err := GetInstallation(ctx, appID, ownerID)
+	if err != nil {
+		if IsErrInstallationNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if i.AllRepositories {
+		return true, nil
+	}
+	return db.GetEngine(ctx).Exist(&InstallationRepo{InstallationID: i.ID, RepoID: repoID})
+}
+
+// GetAppsInstalledOnRepo returns the apps installed on a repository of an organization
+func GetAppsInstalledOnRepo(ctx context.Context, ownerID, repoID int64) ([]*App, error) {
+	apps := make([]*App, 0, 2)
+	return apps, db.GetEngine(ctx).
+		Join("INNER", "app_installation", "app_installation.app_id = app.id").
+		Where(builder.Eq{"app_installation.owner_id": ownerID}.And(
+			builder.Eq{"app_installation.all_repositories": true}.Or(
+				builder.In("app_installation.id", builder.Select("installation_id").From("app_installation_repo").Where(builder.Eq{"repo_id": repoID})),
+			),
+		)).
+		OrderBy("app.id").
+		Find(&apps)
+}
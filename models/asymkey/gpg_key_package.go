@@ -0,0 +1,113 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package asymkey
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	secret_module "code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+)
+
+// This file contains functions related to the keys used to sign package repositories
+
+// PackageSigningKey is a GPG key generated by Gitea to sign the package repositories of an owner
+type PackageSigningKey struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Type        string             `xorm:"UNIQUE(s) NOT NULL"`
+	KeyID       string             `xorm:"CHAR(16) NOT NULL"`
+	PublicKey   string             `xorm:"TEXT NOT NULL"`
+	PrivateKey  string             `xorm:"TEXT NOT NULL"` // encrypted with the SECRET_KEY
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(PackageSigningKey))
+}
+
+// Entity returns the decrypted key
+func (key *PackageSigningKey) Entity() (*openpgp.Entity, error) {
+	private, err := secret_module.DecryptSecret(setting.SecretKey, key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt package signing key %d: %v", key.ID, err)
+	}
+	list, err := openpgp.ReadArmoredKeyRing(strings.NewReader(private))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) != 1 {
+		return nil, fmt.Errorf("package signing key %d contains %d keys", key.ID, len(list))
+	}
+	return list[0], nil
+}
+
+// GetOrCreatePackageSigningKey returns the signing key of the owner for the package type.
+// A new key is generated on first use.
+func GetOrCreatePackageSigningKey(ctx context.Context, owner *user_model.User, packageType string) (*PackageSigningKey, error) {
+	key := &PackageSigningKey{OwnerID: owner.ID, Type: packageType}
+	if has, err := db.GetEngine(ctx).Get(key); err != nil {
+		return nil, err
+	} else if has {
+		return key, nil
+	}
+
+	e, err := openpgp.NewEntity(owner.Name, packageType+" package repository", owner.Email, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// SerializePrivate creates the self signatures, so it must be called before Serialize
+	var public, private bytes.Buffer
+	w, err := armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.SerializePrivate(w, nil); err != nil {
+		return nil, err
+	}
+	w.Close()
+	w, err = armor.Encode(&public, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.Serialize(w); err != nil {
+		return nil, err
+	}
+	w.Close()
+
+	encrypted, err := secret_module.EncryptSecret(setting.SecretKey, private.String())
+	if err != nil {
+		return nil, err
+	}
+
+	key.KeyID = e.PrimaryKey.KeyIdString()
+	key.PublicKey = public.String()
+	key.PrivateKey = encrypted
+	if err := db.Insert(ctx, key); err != nil {
+		// another request may have created the key in the meantime
+		existing := &PackageSigningKey{OwnerID: owner.ID, Type: packageType}
+		if has, err2 := db.GetEngine(ctx).Get(existing); err2 == nil && has {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// DeletePackageSigningKeysByOwnerID removes the package signing keys of an owner
+func DeletePackageSigningKeysByOwnerID(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(new(PackageSigningKey))
+	return err
+}
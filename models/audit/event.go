@@ -0,0 +1,256 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Action is the kind of change recorded by an audit event
+type Action string
+
+// The audited actions
+const (
+	ActionCollaboratorAdd        Action = "collaborator_add"
+	ActionCollaboratorRemove     Action = "collaborator_remove"
+	ActionCollaboratorPermission Action = "collaborator_permission"
+
+	ActionTeamCreate           Action = "team_create"
+	ActionTeamUpdate           Action = "team_update"
+	ActionTeamDelete           Action = "team_delete"
+	ActionTeamMemberAdd        Action = "team_member_add"
+	ActionTeamMemberRemove     Action = "team_member_remove"
+	ActionTeamRepositoryAdd    Action = "team_repository_add"
+	ActionTeamRepositoryRemove Action = "team_repository_remove"
+
+	ActionBranchProtectionCreate Action = "branch_protection_create"
+	ActionBranchProtectionUpdate Action = "branch_protection_update"
+	ActionBranchProtectionDelete Action = "branch_protection_delete"
+
+	ActionPushPolicyUpdate Action = "push_policy_update"
+	ActionPushPolicyDelete Action = "push_policy_delete"
+
+	ActionAccessTokenCreate Action = "access_token_create"
+	ActionAccessTokenDelete Action = "access_token_delete"
+
+	ActionDeployTokenCreate Action = "deploy_token_create"
+	ActionDeployTokenDelete Action = "deploy_token_delete"
+
+	ActionAppInstall   Action = "app_install"
+	ActionAppUninstall Action = "app_uninstall"
+
+	ActionTwoFactorEnable  Action = "two_factor_enable"
+	ActionTwoFactorDisable Action = "two_factor_disable"
+	ActionWebAuthnAdd      Action = "webauthn_add"
+	ActionWebAuthnRemove   Action = "webauthn_remove"
+
+	ActionWebhookCreate Action = "webhook_create"
+	ActionWebhookUpdate Action = "webhook_update"
+	ActionWebhookDelete Action = "webhook_delete"
+
+	ActionRepositoryVisibility Action = "repository_visibility"
+
+	ActionAdminUserCreate       Action = "admin_user_create"
+	ActionAdminUserUpdate       Action = "admin_user_update"
+	ActionAdminUserDelete       Action = "admin_user_delete"
+	ActionAdminRepositoryDelete Action = "admin_repository_delete"
+)
+
+// Actions lists all audited actions
+var Actions = []Action{
+	ActionCollaboratorAdd,
+	ActionCollaboratorRemove,
+	ActionCollaboratorPermission,
+	ActionTeamCreate,
+	ActionTeamUpdate,
+	ActionTeamDelete,
+	ActionTeamMemberAdd,
+	ActionTeamMemberRemove,
+	ActionTeamRepositoryAdd,
+	ActionTeamRepositoryRemove,
+	ActionBranchProtectionCreate,
+	ActionBranchProtectionUpdate,
+	ActionBranchProtectionDelete,
+	ActionPushPolicyUpdate,
+	ActionPushPolicyDelete,
+	ActionAccessTokenCreate,
+	ActionAccessTokenDelete,
+	ActionDeployTokenCreate,
+	ActionDeployTokenDelete,
+	ActionAppInstall,
+	ActionAppUninstall,
+	ActionTwoFactorEnable,
+	ActionTwoFactorDisable,
+	ActionWebAuthnAdd,
+	ActionWebAuthnRemove,
+	ActionWebhookCreate,
+	ActionWebhookUpdate,
+	ActionWebhookDelete,
+	ActionRepositoryVisibility,
+	ActionAdminUserCreate,
+	ActionAdminUserUpdate,
+	ActionAdminUserDelete,
+	ActionAdminRepositoryDelete,
+}
+
+// IsValid checks if the action is a known action
+func (a Action) IsValid() bool {
+	for _, action := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectType is the type of the scope or the target of an audit event
+type ObjectType string
+
+// The types of audited objects
+const (
+	TypeSystem             ObjectType = "system"
+	TypeUser               ObjectType = "user"
+	TypeOrganization       ObjectType = "organization"
+	TypeRepository         ObjectType = "repository"
+	TypeTeam               ObjectType = "team"
+	TypeBranchProtection   ObjectType = "branch_protection"
+	TypePushPolicy         ObjectType = "push_policy"
+	TypeAccessToken        ObjectType = "access_token"
+	TypeDeployToken        ObjectType = "deploy_token"
+	TypeApp                ObjectType = "app"
+	TypeWebAuthnCredential ObjectType = "webauthn_credential"
+	TypeWebhook            ObjectType = "webhook"
+)
+
+// Object identifies the scope or the target of an audit event.
+// The name is stored too because the object may be deleted later.
+type Object struct {
+	Type ObjectType
+	ID   int64
+	Name string
+}
+
+// Event is an entry of the audit log.
+// Events are never changed once written and only removed by the retention cleanup.
+type Event struct {
+	ID          int64              `xorm:"pk autoincr"`
+	Action      Action             `xorm:"INDEX NOT NULL"`
+	ActorID     int64              `xorm:"INDEX"`
+	ActorName   string             `xorm:"NOT NULL"`
+	ScopeType   ObjectType         `xorm:"INDEX(scope) NOT NULL"`
+	ScopeID     int64              `xorm:"INDEX(scope)"`
+	ScopeName   string             `xorm:"NOT NULL"`
+	TargetType  ObjectType         `xorm:"NOT NULL"`
+	TargetID    int64              `xorm:"NOT NULL DEFAULT 0"`
+	TargetName  string             `xorm:"NOT NULL"`
+	Before      string             `xorm:"TEXT"` // JSON encoded state before the change
+	After       string             `xorm:"TEXT"` // JSON encoded state after the change
+	IPAddress   string             `xorm:"NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func init() {
+	db.RegisterModel(new(Event))
+}
+
+// TableName sets the table name of the audit events
+func (Event) TableName() string {
+	return "audit_event"
+}
+
+// Scope returns the object in which the change happened
+func (e *Event) Scope() Object {
+	return Object{Type: e.ScopeType, ID: e.ScopeID, Name: e.ScopeName}
+}
+
+// Target returns the changed object
+func (e *Event) Target() Object {
+	return Object{Type: e.TargetType, ID: e.TargetID, Name: e.TargetName}
+}
+
+// TrStr returns the translation key of the action
+func (e *Event) TrStr() string {
+	return "admin.audit.action." + string(e.Action)
+}
+
+// InsertEvent appends an event to the audit log
+func InsertEvent(ctx context.Context, e *Event) error {
+	return db.Insert(ctx, e)
+}
+
+// SearchEventsOptions are the options to search the audit log
+type SearchEventsOptions struct {
+	db.ListOptions
+	Action    Action
+	ActorID   int64
+	ActorName string
+	ScopeType ObjectType
+	ScopeID   int64
+	Keyword   string // matches the names of the actor, the scope or the target
+	Since     int64
+	Before    int64
+}
+
+func (opts *SearchEventsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.Action != "" {
+		cond = cond.And(builder.Eq{"action": opts.Action})
+	}
+	if opts.ActorID != 0 {
+		cond = cond.And(builder.Eq{"actor_id": opts.ActorID})
+	}
+	if opts.ActorName != "" {
+		cond = cond.And(builder.Eq{"actor_name": opts.ActorName})
+	}
+	if opts.ScopeType != "" {
+		cond = cond.And(builder.Eq{"scope_type": opts.ScopeType})
+		if opts.ScopeID != 0 {
+			cond = cond.And(builder.Eq{"scope_id": opts.ScopeID})
+		}
+	}
+	if opts.Keyword != "" {
+		cond = cond.And(builder.Or(
+			builder.Like{"actor_name", opts.Keyword},
+			builder.Like{"scope_name", opts.Keyword},
+			builder.Like{"target_name", opts.Keyword},
+		))
+	}
+	if opts.Since != 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before != 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+// SearchEvents returns a page of the matching events, the newest first.
+// The log may be large, so the result is always paginated.
+func SearchEvents(ctx context.Context, opts *SearchEventsOptions) ([]*Event, int64, error) {
+	sess := db.GetEngine(ctx).
+		Where(opts.toConds()).
+		Desc("created_unix", "id")
+	sess = db.SetSessionPagination(sess, opts)
+
+	events := make([]*Event, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&events)
+	return events, count, err
+}
+
+// DeleteEventsOlderThan removes the events older than the retention period
+func DeleteEventsOlderThan(ctx context.Context, olderThan time.Duration) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	_, err := db.GetEngine(ctx).Where("created_unix < ?", time.Now().Add(-olderThan).Unix()).Delete(&Event{})
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	events, count, err := SearchEvents(db.DefaultContext, &SearchEventsOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Len(t, events, 3)
+	assert.Equal(t, ActionRepositoryVisibility, events[0].Action)
+	assert.Equal(t, Object{Type: TypeOrganization, ID: 3, Name: "user3"}, events[1].Scope())
+
+	events, count, err = SearchEvents(db.DefaultContext, &SearchEventsOptions{Action: ActionTeamMemberAdd})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Equal(t, "user5", events[0].Target().Name)
+
+	_, count, err = SearchEvents(db.DefaultContext, &SearchEventsOptions{ScopeType: TypeRepository, ScopeID: 3})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	_, count, err = SearchEvents(db.DefaultContext, &SearchEventsOptions{ActorName: "user2"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	_, count, err = SearchEvents(db.DefaultContext, &SearchEventsOptions{Keyword: "newuse"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	_, count, err = SearchEvents(db.DefaultContext, &SearchEventsOptions{Since: 946684810, Before: 946684820})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	events, count, err = SearchEvents(db.DefaultContext, &SearchEventsOptions{ListOptions: db.ListOptions{Page: 2, PageSize: 2}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Len(t, events, 1)
+	assert.EqualValues(t, 1, events[0].ID)
+}
+
+func TestInsertEvent(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	e := &Event{Action: ActionCollaboratorAdd, ActorID: 2, ActorName: "user2", ScopeType: TypeRepository, ScopeID: 1, ScopeName: "user2/repo1", TargetType: TypeUser, TargetID: 4, TargetName: "user4"}
+	assert.NoError(t, InsertEvent(db.DefaultContext, e))
+
+	events, count, err := SearchEvents(db.DefaultContext, &SearchEventsOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, count)
+	assert.Equal(t, e.ID, events[0].ID)
+	assert.NotZero(t, events[0].CreatedUnix)
+}
+
+func TestDeleteEventsOlderThan(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	recent := &Event{Action: ActionAccessTokenCreate, ActorID: 2, ActorName: "user2", ScopeType: TypeUser, ScopeID: 2, ScopeName: "user2", TargetType: TypeAccessToken, TargetID: 2, TargetName: "recent"}
+	assert.NoError(t, InsertEvent(db.DefaultContext, recent))
+
+	// a retention period of 0 keeps all events
+	assert.NoError(t, DeleteEventsOlderThan(db.DefaultContext, 0))
+	unittest.AssertCount(t, &Event{}, 4)
+
+	// the fixture events are much older than a day
+	assert.NoError(t, DeleteEventsOlderThan(db.DefaultContext, 24*time.Hour))
+	unittest.AssertNotExistsBean(t, &Event{ID: 1})
+	unittest.AssertExistsAndLoadBean(t, &Event{ID: recent.ID})
+	unittest.AssertCount(t, &Event{}, 1)
+}
@@ -0,0 +1,267 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	gouuid "github.com/google/uuid"
+	"xorm.io/builder"
+)
+
+// ErrDeployTokenNotExist represents a "deploy token does not exist" error
+type ErrDeployTokenNotExist struct {
+	ID int64
+}
+
+// IsErrDeployTokenNotExist checks if an error is a ErrDeployTokenNotExist
+func IsErrDeployTokenNotExist(err error) bool {
+	_, ok := err.(ErrDeployTokenNotExist)
+	return ok
+}
+
+func (err ErrDeployTokenNotExist) Error() string {
+	return fmt.Sprintf("deploy token does not exist [id: %d]", err.ID)
+}
+
+// ErrDeployTokenNameAlreadyUsed represents a "deploy token name already used" error
+type ErrDeployTokenNameAlreadyUsed struct {
+	Name string
+}
+
+// IsErrDeployTokenNameAlreadyUsed checks if an error is a ErrDeployTokenNameAlreadyUsed
+func IsErrDeployTokenNameAlreadyUsed(err error) bool {
+	_, ok := err.(ErrDeployTokenNameAlreadyUsed)
+	return ok
+}
+
+func (err ErrDeployTokenNameAlreadyUsed) Error() string {
+	return fmt.Sprintf("deploy token name has been used already [name: %s]", err.Name)
+}
+
+// ErrDeployTokenExpiryInPast represents a "deploy token expiry in past" error
+type ErrDeployTokenExpiryInPast struct{}
+
+// IsErrDeployTokenExpiryInPast checks if an error is a ErrDeployTokenExpiryInPast
+func IsErrDeployTokenExpiryInPast(err error) bool {
+	_, ok := err.(ErrDeployTokenExpiryInPast)
+	return ok
+}
+
+func (err ErrDeployTokenExpiryInPast) Error() string {
+	return "the expiry date of a deploy token must be in the future"
+}
+
+// DeployToken grants the access to the Git repositories over HTTP, to their LFS objects and to the package registry
+// without being tied to a user account. A token belongs to either a repository or an organization,
+// the tokens of an organization can access all its repositories and its packages.
+type DeployToken struct {
+	ID             int64           `xorm:"pk autoincr"`
+	OwnerID        int64           `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"` // the organization, 0 for repository tokens
+	RepoID         int64           `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+	Name           string          `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
+	Mode           perm.AccessMode `xorm:"NOT NULL DEFAULT 1"`
+	Token          string          `xorm:"-"`
+	TokenHash      string          `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+	ExpiresUnix       timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"` // the last time the token was used
+	HasRecentActivity bool               `xorm:"-"`
+	HasUsed           bool               `xorm:"-"`
+}
+
+func init() {
+	db.RegisterModel(new(DeployToken))
+}
+
+// AfterLoad is invoked from XORM after setting the values of all fields of this object.
+func (t *DeployToken) AfterLoad() {
+	t.HasUsed = t.UpdatedUnix > t.CreatedUnix
+	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
+}
+
+// IsExpired returns true if the token has an expiry date which has passed
+func (t *DeployToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// IsReadOnly returns true if the token can't push nor publish packages
+func (t *DeployToken) IsReadOnly() bool {
+	return t.Mode < perm.AccessModeWrite
+}
+
+// CanAccessRepo returns true if the token belongs to the repository or to its owner
+func (t *DeployToken) CanAccessRepo(repoID, ownerID int64) bool {
+	return (t.RepoID > 0 && t.RepoID == repoID) || (t.OwnerID > 0 && t.OwnerID == ownerID)
+}
+
+// CanAccessPackages returns true if the token can access the package registry of the owner,
+// only the tokens of an organization can, the packages don't belong to a repository
+func (t *DeployToken) CanAccessPackages(ownerID int64) bool {
+	return t.OwnerID > 0 && t.OwnerID == ownerID
+}
+
+// NewDeployToken generates the token and saves a new deploy token
+func NewDeployToken(ctx context.Context, t *DeployToken) error {
+	if t.IsExpired() {
+		return ErrDeployTokenExpiryInPast{}
+	}
+
+	has, err := db.GetEngine(ctx).Exist(&DeployToken{OwnerID: t.OwnerID, RepoID: t.RepoID, Name: t.Name})
+	if err != nil {
+		return err
+	} else if has {
+		return ErrDeployTokenNameAlreadyUsed{t.Name}
+	}
+
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	t.TokenSalt = salt
+	t.Token = base.EncodeSha1(gouuid.New().String())
+	t.TokenHash = HashToken(t.Token, t.TokenSalt)
+	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	return db.Insert(ctx, t)
+}
+
+// GetDeployTokenBySHA returns the deploy token with the given value, the expired tokens don't exist
+func GetDeployTokenBySHA(ctx context.Context, token string) (*DeployToken, error) {
+	if len(token) != 40 {
+		return nil, ErrDeployTokenNotExist{}
+	}
+
+	tokens := make([]*DeployToken, 0, 1)
+	if err := db.GetEngine(ctx).Where("token_last_eight = ?", token[len(token)-8:]).Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(HashToken(token, t.TokenSalt))) == 1 {
+			if t.IsExpired() {
+				break
+			}
+			return t, nil
+		}
+	}
+	return nil, ErrDeployTokenNotExist{}
+}
+
+// FindDeployTokensBySHA returns the deploy tokens among the given token values, keyed by their value.
+// The candidates are loaded by the last eight characters of the values with as few queries as possible
+// and only they are hashed, the expired tokens are left out.
+func FindDeployTokensBySHA(ctx context.Context, values []string) (map[string]*DeployToken, error) {
+	byLastEight := make(map[string][]string, len(values))
+	for _, value := range values {
+		if len(value) == 40 {
+			lastEight := value[len(value)-8:]
+			byLastEight[lastEight] = append(byLastEight[lastEight], value)
+		}
+	}
+	lastEights := make([]string, 0, len(byLastEight))
+	for lastEight := range byLastEight {
+		lastEights = append(lastEights, lastEight)
+	}
+
+	found := make(map[string]*DeployToken)
+	for len(lastEights) > 0 {
+		limit := db.DefaultMaxInSize
+		if len(lastEights) < limit {
+			limit = len(lastEights)
+		}
+		tokens := make([]*DeployToken, 0, limit)
+		if err := db.GetEngine(ctx).In("token_last_eight", lastEights[:limit]).Find(&tokens); err != nil {
+			return nil, err
+		}
+		for _, t := range tokens {
+			for _, value := range byLastEight[t.TokenLastEight] {
+				if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(HashToken(value, t.TokenSalt))) == 1 && !t.IsExpired() {
+					found[value] = t
+				}
+			}
+		}
+		lastEights = lastEights[limit:]
+	}
+	return found, nil
+}
+
+// GetDeployTokenByID returns the deploy token with the given id
+func GetDeployTokenByID(ctx context.Context, id int64) (*DeployToken, error) {
+	t := &DeployToken{}
+	has, err := db.GetEngine(ctx).ID(id).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDeployTokenNotExist{id}
+	}
+	return t, nil
+}
+
+// UpdateDeployTokenLastUsed records that the token has just been used
+func UpdateDeployTokenLastUsed(ctx context.Context, t *DeployToken) error {
+	t.UpdatedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(t.ID).Cols("updated_unix").NoAutoTime().Update(t)
+	return err
+}
+
+// FindDeployTokensOptions represents the options to find the deploy tokens of an organization or of a repository
+type FindDeployTokensOptions struct {
+	db.ListOptions
+	OwnerID int64
+	RepoID  int64
+}
+
+func (opts *FindDeployTokensOptions) toConds() builder.Cond {
+	return builder.Eq{"owner_id": opts.OwnerID, "repo_id": opts.RepoID}
+}
+
+// FindDeployTokens returns the deploy tokens
+func FindDeployTokens(ctx context.Context, opts FindDeployTokensOptions) ([]*DeployToken, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).OrderBy("name")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	tokens := make([]*DeployToken, 0, 10)
+	return tokens, sess.Find(&tokens)
+}
+
+// CountDeployTokens returns the number of deploy tokens
+func CountDeployTokens(ctx context.Context, opts FindDeployTokensOptions) (int64, error) {
+	return db.GetEngine(ctx).Where(opts.toConds()).Count(new(DeployToken))
+}
+
+// DeleteDeployToken removes a deploy token of an organization or of a repository
+func DeleteDeployToken(ctx context.Context, ownerID, repoID, id int64) error {
+	n, err := db.GetEngine(ctx).ID(id).Delete(&DeployToken{OwnerID: ownerID, RepoID: repoID})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrDeployTokenNotExist{id}
+	}
+	return nil
+}
+
+// DeleteDeployTokensByRepoID removes all deploy tokens of a repository
+func DeleteDeployTokensByRepoID(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(new(DeployToken))
+	return err
+}
+
+// DeleteDeployTokensByOwnerID removes all deploy tokens of an organization
+func DeleteDeployTokensByOwnerID(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = 0", ownerID).Delete(new(DeployToken))
+	return err
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeployToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	token := &DeployToken{RepoID: 1, Name: "ci", Mode: perm.AccessModeRead}
+	assert.NoError(t, NewDeployToken(db.DefaultContext, token))
+	assert.Len(t, token.Token, 40)
+
+	found, err := GetDeployTokenBySHA(db.DefaultContext, token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.True(t, found.CanAccessRepo(1, 2))
+	assert.False(t, found.CanAccessRepo(2, 2))
+	assert.False(t, found.CanAccessPackages(2))
+	assert.True(t, found.IsReadOnly())
+
+	err = NewDeployToken(db.DefaultContext, &DeployToken{RepoID: 1, Name: "ci"})
+	assert.True(t, IsErrDeployTokenNameAlreadyUsed(err))
+	assert.NoError(t, NewDeployToken(db.DefaultContext, &DeployToken{OwnerID: 3, Name: "ci", Mode: perm.AccessModeWrite}))
+
+	err = NewDeployToken(db.DefaultContext, &DeployToken{RepoID: 1, Name: "expired", ExpiresUnix: timeutil.TimeStampNow().Add(-1)})
+	assert.True(t, IsErrDeployTokenExpiryInPast(err))
+
+	_, err = GetDeployTokenBySHA(db.DefaultContext, "0123456789012345678901234567890123456789")
+	assert.True(t, IsErrDeployTokenNotExist(err))
+}
+
+func TestGetDeployTokenBySHA_Expired(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	token := &DeployToken{RepoID: 1, Name: "ci", ExpiresUnix: timeutil.TimeStampNow().AddDuration(time.Hour)}
+	assert.NoError(t, NewDeployToken(db.DefaultContext, token))
+	_, err := db.GetEngine(db.DefaultContext).ID(token.ID).Cols("expires_unix").Update(&DeployToken{ExpiresUnix: timeutil.TimeStampNow().Add(-1)})
+	assert.NoError(t, err)
+
+	_, err = GetDeployTokenBySHA(db.DefaultContext, token.Token)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+}
+
+func TestFindDeployTokensBySHA(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	token := &DeployToken{RepoID: 1, Name: "ci"}
+	assert.NoError(t, NewDeployToken(db.DefaultContext, token))
+	other := &DeployToken{OwnerID: 3, Name: "ci"}
+	assert.NoError(t, NewDeployToken(db.DefaultContext, other))
+
+	tokens, err := FindDeployTokensBySHA(db.DefaultContext, []string{token.Token, "0123456789012345678901234567890123456789", other.Token})
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 2) {
+		assert.Equal(t, token.ID, tokens[token.Token].ID)
+		assert.Equal(t, other.ID, tokens[other.Token].ID)
+	}
+}
+
+func TestDeleteDeployToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repoToken := &DeployToken{RepoID: 1, Name: "ci"}
+	assert.NoError(t, NewDeployToken(db.DefaultContext, repoToken))
+	orgToken := &DeployToken{OwnerID: 3, Name: "ci"}
+	assert.NoError(t, NewDeployToken(db.DefaultContext, orgToken))
+
+	// a token can only be deleted from its repository or organization
+	assert.True(t, IsErrDeployTokenNotExist(DeleteDeployToken(db.DefaultContext, 3, 0, repoToken.ID)))
+	assert.NoError(t, DeleteDeployToken(db.DefaultContext, 0, 1, repoToken.ID))
+	unittest.AssertNotExistsBean(t, &DeployToken{ID: repoToken.ID})
+
+	assert.NoError(t, DeleteDeployTokensByOwnerID(db.DefaultContext, 3))
+	count, err := CountDeployTokens(db.DefaultContext, FindDeployTokensOptions{OwnerID: 3})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
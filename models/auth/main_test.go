@@ -15,6 +15,7 @@ func TestMain(m *testing.M) {
 	unittest.MainTest(m, &unittest.TestOptions{
 		GiteaRootPath: filepath.Join("..", ".."),
 		FixtureFiles: []string{
+			"deploy_token.yml",
 			"login_source.yml",
 			"oauth2_application.yml",
 			"oauth2_authorization_code.yml",
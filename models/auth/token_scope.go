@@ -0,0 +1,165 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccessTokenScopeCategory is a group of API endpoints a personal access token can be granted access to
+type AccessTokenScopeCategory string
+
+// The categories of the scopes of personal access tokens
+const (
+	AccessTokenScopeCategoryRepository   AccessTokenScopeCategory = "repository"
+	AccessTokenScopeCategoryIssue        AccessTokenScopeCategory = "issue"
+	AccessTokenScopeCategoryPackage      AccessTokenScopeCategory = "package"
+	AccessTokenScopeCategoryOrganization AccessTokenScopeCategory = "organization"
+	AccessTokenScopeCategoryUser         AccessTokenScopeCategory = "user"
+	AccessTokenScopeCategoryAdmin        AccessTokenScopeCategory = "admin"
+)
+
+// AllAccessTokenScopeCategories are all the categories in their display order
+var AllAccessTokenScopeCategories = []AccessTokenScopeCategory{
+	AccessTokenScopeCategoryRepository,
+	AccessTokenScopeCategoryIssue,
+	AccessTokenScopeCategoryPackage,
+	AccessTokenScopeCategoryOrganization,
+	AccessTokenScopeCategoryUser,
+	AccessTokenScopeCategoryAdmin,
+}
+
+// AccessTokenScopeLevel is the access level of a personal access token to a category
+type AccessTokenScopeLevel int
+
+// The access levels of personal access tokens, write includes read
+const (
+	AccessTokenScopeLevelNone AccessTokenScopeLevel = iota
+	AccessTokenScopeLevelRead
+	AccessTokenScopeLevelWrite
+)
+
+func (level AccessTokenScopeLevel) String() string {
+	switch level {
+	case AccessTokenScopeLevelRead:
+		return "read"
+	case AccessTokenScopeLevelWrite:
+		return "write"
+	default:
+		return "none"
+	}
+}
+
+// AccessTokenScope is the comma separated list of the scopes of a personal access token,
+// a scope is either "all" or a level and a category like "read:repository" or "write:issue"
+type AccessTokenScope string
+
+// AccessTokenScopeAll grants the access to everything the user can access
+const AccessTokenScopeAll AccessTokenScope = "all"
+
+// ErrInvalidAccessTokenScope represents an error of an unknown scope
+type ErrInvalidAccessTokenScope struct {
+	Scope string
+}
+
+func (err ErrInvalidAccessTokenScope) Error() string {
+	return fmt.Sprintf("invalid access token scope %q", err.Scope)
+}
+
+// IsErrInvalidAccessTokenScope checks if an error is a ErrInvalidAccessTokenScope.
+func IsErrInvalidAccessTokenScope(err error) bool {
+	_, ok := err.(ErrInvalidAccessTokenScope)
+	return ok
+}
+
+// FormatAccessTokenScope returns the scope granting the level of access to the category
+func FormatAccessTokenScope(level AccessTokenScopeLevel, category AccessTokenScopeCategory) string {
+	return level.String() + ":" + string(category)
+}
+
+// ParseAccessTokenScope validates and normalizes the scopes of a personal access token:
+// the write scopes replace the read scopes of their category and "all" replaces every other scope.
+func ParseAccessTokenScope(scopes []string) (AccessTokenScope, error) {
+	levels := make(map[AccessTokenScopeCategory]AccessTokenScopeLevel, len(scopes))
+	isAll := false
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if AccessTokenScope(scope) == AccessTokenScopeAll {
+			isAll = true
+			continue
+		}
+		level, category, ok := parseScope(scope)
+		if !ok {
+			return "", ErrInvalidAccessTokenScope{Scope: scope}
+		}
+		if level > levels[category] {
+			levels[category] = level
+		}
+	}
+	if isAll {
+		return AccessTokenScopeAll, nil
+	}
+
+	normalized := make([]string, 0, len(levels))
+	for _, category := range AllAccessTokenScopeCategories {
+		if level := levels[category]; level != AccessTokenScopeLevelNone {
+			normalized = append(normalized, FormatAccessTokenScope(level, category))
+		}
+	}
+	return AccessTokenScope(strings.Join(normalized, ",")), nil
+}
+
+func parseScope(scope string) (AccessTokenScopeLevel, AccessTokenScopeCategory, bool) {
+	levelName, categoryName, ok := strings.Cut(scope, ":")
+	if !ok {
+		return AccessTokenScopeLevelNone, "", false
+	}
+	var level AccessTokenScopeLevel
+	switch levelName {
+	case "read":
+		level = AccessTokenScopeLevelRead
+	case "write":
+		level = AccessTokenScopeLevelWrite
+	default:
+		return AccessTokenScopeLevelNone, "", false
+	}
+	for _, category := range AllAccessTokenScopeCategories {
+		if string(category) == categoryName {
+			return level, category, true
+		}
+	}
+	return AccessTokenScopeLevelNone, "", false
+}
+
+// Scopes returns the list of the scopes
+func (s AccessTokenScope) Scopes() []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(string(s), ",")
+}
+
+// Level returns the access level granted to the category
+func (s AccessTokenScope) Level(category AccessTokenScopeCategory) AccessTokenScopeLevel {
+	if s == AccessTokenScopeAll {
+		return AccessTokenScopeLevelWrite
+	}
+	level := AccessTokenScopeLevelNone
+	for _, scope := range s.Scopes() {
+		if l, c, ok := parseScope(scope); ok && c == category && l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+// HasLevel returns true if the scope grants at least the level of access to the category
+func (s AccessTokenScope) HasLevel(category AccessTokenScopeCategory, level AccessTokenScopeLevel) bool {
+	return s.Level(category) >= level
+}
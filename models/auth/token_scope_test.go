@@ -0,0 +1,43 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccessTokenScope(t *testing.T) {
+	scope, err := ParseAccessTokenScope([]string{"write:issue", "read:repository", "read:issue", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTokenScope("read:repository,write:issue"), scope)
+
+	scope, err = ParseAccessTokenScope([]string{"read:admin", "all"})
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTokenScopeAll, scope)
+
+	scope, err = ParseAccessTokenScope(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, scope)
+
+	for _, invalid := range []string{"repository", "delete:repository", "read:wiki", "read:"} {
+		_, err = ParseAccessTokenScope([]string{invalid})
+		assert.True(t, IsErrInvalidAccessTokenScope(err), invalid)
+	}
+}
+
+func TestAccessTokenScope_HasLevel(t *testing.T) {
+	scope := AccessTokenScope("read:repository,write:issue")
+	assert.True(t, scope.HasLevel(AccessTokenScopeCategoryRepository, AccessTokenScopeLevelRead))
+	assert.False(t, scope.HasLevel(AccessTokenScopeCategoryRepository, AccessTokenScopeLevelWrite))
+	assert.True(t, scope.HasLevel(AccessTokenScopeCategoryIssue, AccessTokenScopeLevelRead))
+	assert.True(t, scope.HasLevel(AccessTokenScopeCategoryIssue, AccessTokenScopeLevelWrite))
+	assert.False(t, scope.HasLevel(AccessTokenScopeCategoryAdmin, AccessTokenScopeLevelRead))
+	assert.Equal(t, []string{"read:repository", "write:issue"}, scope.Scopes())
+
+	assert.True(t, AccessTokenScopeAll.HasLevel(AccessTokenScopeCategoryAdmin, AccessTokenScopeLevelWrite))
+	assert.False(t, AccessTokenScope("").HasLevel(AccessTokenScopeCategoryUser, AccessTokenScopeLevelRead))
+}
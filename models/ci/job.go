@@ -0,0 +1,157 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ErrJobNotExist indicates a job not exist error
+var ErrJobNotExist = errors.New("Job does not exist")
+
+// Job represents a job of a run, it is executed by a single runner
+type Job struct {
+	ID       int64    `xorm:"pk autoincr"`
+	RunID    int64    `xorm:"INDEX"`
+	RepoID   int64    `xorm:"INDEX"`
+	JobID    string   // the id of the job in the workflow
+	Name     string   `xorm:"NOT NULL"`
+	RunsOn   []string `xorm:"TEXT JSON"`
+	Payload  string   `xorm:"LONGTEXT"` // the steps and environment of the job, handed to the runner as is
+	Status   Status   `xorm:"INDEX"`
+	RunnerID int64    `xorm:"INDEX"`
+
+	StartedUnix timeutil.TimeStamp
+	StoppedUnix timeutil.TimeStamp
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName sets the name of this table
+func (Job) TableName() string {
+	return "ci_job"
+}
+
+func init() {
+	db.RegisterModel(new(Job))
+}
+
+// Duration returns the seconds the job has been running
+func (j *Job) Duration() int64 {
+	return duration(j.StartedUnix, j.StoppedUnix)
+}
+
+// Link returns the relative url of the job
+func (j *Job) Link(run *Run) string {
+	return fmt.Sprintf("%s/jobs/%d", run.Link(), j.ID)
+}
+
+// GetJobByID returns the job with the given id
+func GetJobByID(ctx context.Context, id int64) (*Job, error) {
+	j := &Job{}
+	has, err := db.GetEngine(ctx).ID(id).Get(j)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrJobNotExist
+	}
+	return j, nil
+}
+
+// GetJobsByRunID returns the jobs of a run
+func GetJobsByRunID(ctx context.Context, runID int64) ([]*Job, error) {
+	jobs := make([]*Job, 0, 5)
+	return jobs, db.GetEngine(ctx).Where("run_id = ?", runID).OrderBy("id").Find(&jobs)
+}
+
+// ClaimJob assigns the oldest waiting job the runner is able to run to the runner.
+// It returns nil if there is no such job.
+func ClaimJob(ctx context.Context, runner *Runner) (*Job, error) {
+	cond := builder.Eq{"status": StatusWaiting}
+	if runner.RepoID > 0 {
+		cond["repo_id"] = runner.RepoID
+	}
+
+	const batchSize = 50
+	for start := 0; ; start += batchSize {
+		jobs := make([]*Job, 0, batchSize)
+		if err := db.GetEngine(ctx).Where(cond).OrderBy("id").Limit(batchSize, start).Find(&jobs); err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			if !runner.HasLabels(job.RunsOn) {
+				continue
+			}
+
+			job.Status = StatusRunning
+			job.RunnerID = runner.ID
+			job.StartedUnix = timeutil.TimeStampNow()
+			// another runner may have claimed the job in the meantime
+			n, err := db.GetEngine(ctx).ID(job.ID).Where("status = ?", StatusWaiting).
+				Cols("status", "runner_id", "started_unix").Update(job)
+			if err != nil {
+				return nil, err
+			}
+			if n == 1 {
+				return job, nil
+			}
+		}
+		if len(jobs) < batchSize {
+			return nil, nil
+		}
+	}
+}
+
+// UpdateJobStatus sets the status of the job and updates the status of its run
+func UpdateJobStatus(ctx context.Context, job *Job, status Status) error {
+	return db.WithTx(func(ctx context.Context) error {
+		job.Status = status
+		cols := []string{"status"}
+		if status.IsDone() {
+			job.StoppedUnix = timeutil.TimeStampNow()
+			cols = append(cols, "stopped_unix")
+		}
+		if _, err := db.GetEngine(ctx).ID(job.ID).Cols(cols...).Update(job); err != nil {
+			return err
+		}
+
+		run, err := GetRunByID(ctx, job.RunID)
+		if err != nil {
+			return err
+		}
+		return UpdateRunStatus(ctx, run)
+	}, ctx)
+}
+
+// TouchJob marks the job as updated, telling that its runner is still alive
+func TouchJob(ctx context.Context, job *Job) error {
+	_, err := db.GetEngine(ctx).ID(job.ID).Cols("updated_unix").Update(&Job{})
+	return err
+}
+
+// FindAbandonedJobs returns the running jobs which have not been reported on since the given duration
+func FindAbandonedJobs(ctx context.Context, olderThan time.Duration) ([]*Job, error) {
+	jobs := make([]*Job, 0, 10)
+	return jobs, db.GetEngine(ctx).
+		Where("status = ? AND updated_unix < ?", StatusRunning, time.Now().Add(-olderThan).Unix()).
+		Find(&jobs)
+}
+
+func refName(ref string) string {
+	if strings.HasPrefix(ref, git.BranchPrefix) {
+		return strings.TrimPrefix(ref, git.BranchPrefix)
+	}
+	return strings.TrimPrefix(ref, git.TagPrefix)
+}
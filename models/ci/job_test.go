@@ -0,0 +1,142 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func insertTestRun(t *testing.T, repoID int64, runsOn ...[]string) (*Run, []*Job) {
+	run := &Run{
+		RepoID:        repoID,
+		WorkflowID:    "test.yml",
+		Title:         "Test",
+		TriggerUserID: 2,
+		Event:         "push",
+		Ref:           "refs/heads/master",
+		CommitSHA:     "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+	}
+	jobs := make([]*Job, 0, len(runsOn))
+	for _, labels := range runsOn {
+		jobs = append(jobs, &Job{Name: "job", RunsOn: labels})
+	}
+	assert.NoError(t, InsertRun(db.DefaultContext, run, jobs))
+	return run, jobs
+}
+
+func TestInsertRun(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	run1, jobs := insertTestRun(t, 1, []string{"linux"}, []string{"windows"})
+	run2, _ := insertTestRun(t, 1, []string{"linux"})
+	assert.EqualValues(t, 1, run1.Index)
+	assert.EqualValues(t, 2, run2.Index)
+	assert.Equal(t, StatusWaiting, run1.Status)
+	for _, job := range jobs {
+		assert.Equal(t, run1.ID, job.RunID)
+		assert.EqualValues(t, 1, job.RepoID)
+		assert.Equal(t, StatusWaiting, job.Status)
+	}
+
+	run, err := GetRunByIndex(db.DefaultContext, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, run2.ID, run.ID)
+	assert.NoError(t, run.LoadAttributes(db.DefaultContext))
+	assert.Equal(t, "/user2/repo1/ci/runs/2", run.Link())
+	assert.Equal(t, "master", run.RefName())
+
+	runs, count, err := FindRuns(db.DefaultContext, FindRunsOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Equal(t, run2.ID, runs[0].ID)
+
+	_, err = GetRunByIndex(db.DefaultContext, 1, 3)
+	assert.ErrorIs(t, err, ErrRunNotExist)
+}
+
+func TestClaimJob(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, jobs := insertTestRun(t, 1, []string{"windows"}, []string{"linux"})
+	_, otherJobs := insertTestRun(t, 2, []string{"linux"})
+
+	repoRunner := &Runner{Name: "repo", RepoID: 1, Labels: []string{"linux", "docker"}}
+	assert.NoError(t, NewRunner(db.DefaultContext, repoRunner))
+	instanceRunner := &Runner{Name: "instance", Labels: []string{"linux"}}
+	assert.NoError(t, NewRunner(db.DefaultContext, instanceRunner))
+
+	// the runner of the repository does not have the labels of the first job
+	job, err := ClaimJob(db.DefaultContext, repoRunner)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs[1].ID, job.ID)
+	assert.Equal(t, StatusRunning, job.Status)
+	assert.Equal(t, repoRunner.ID, job.RunnerID)
+
+	// it does not run the jobs of other repositories
+	job, err = ClaimJob(db.DefaultContext, repoRunner)
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+
+	job, err = ClaimJob(db.DefaultContext, instanceRunner)
+	assert.NoError(t, err)
+	assert.Equal(t, otherJobs[0].ID, job.ID)
+
+	job, err = ClaimJob(db.DefaultContext, instanceRunner)
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+}
+
+func TestUpdateJobStatus(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	run, jobs := insertTestRun(t, 1, []string{"linux"}, []string{"linux"})
+
+	assert.NoError(t, UpdateJobStatus(db.DefaultContext, jobs[0], StatusSuccess))
+	run = unittest.AssertExistsAndLoadBean(t, &Run{ID: run.ID})
+	assert.Equal(t, StatusRunning, run.Status)
+	assert.Zero(t, run.StoppedUnix)
+
+	assert.NoError(t, UpdateJobStatus(db.DefaultContext, jobs[1], StatusFailure))
+	run = unittest.AssertExistsAndLoadBean(t, &Run{ID: run.ID})
+	assert.Equal(t, StatusFailure, run.Status)
+	assert.NotZero(t, run.StoppedUnix)
+}
+
+func TestFindAbandonedJobs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, jobs := insertTestRun(t, 1, []string{"linux"}, []string{"linux"})
+	for _, job := range jobs {
+		assert.NoError(t, UpdateJobStatus(db.DefaultContext, job, StatusRunning))
+	}
+	_, err := db.GetEngine(db.DefaultContext).ID(jobs[0].ID).NoAutoTime().
+		Cols("updated_unix").Update(&Job{UpdatedUnix: 1})
+	assert.NoError(t, err)
+
+	abandoned, err := FindAbandonedJobs(db.DefaultContext, time.Hour)
+	assert.NoError(t, err)
+	if assert.Len(t, abandoned, 1) {
+		assert.Equal(t, jobs[0].ID, abandoned[0].ID)
+	}
+}
+
+func TestJobLog(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, AppendJobLog(db.DefaultContext, 1, "hello "))
+	assert.NoError(t, AppendJobLog(db.DefaultContext, 1, ""))
+	assert.NoError(t, AppendJobLog(db.DefaultContext, 1, "world\n"))
+	assert.NoError(t, AppendJobLog(db.DefaultContext, 2, "other"))
+
+	log, err := GetJobLog(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world\n", log)
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// JobLog is a chunk of the output of a job as sent by the runner
+type JobLog struct {
+	ID          int64              `xorm:"pk autoincr"`
+	JobID       int64              `xorm:"INDEX"`
+	Content     string             `xorm:"LONGTEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName sets the name of this table
+func (JobLog) TableName() string {
+	return "ci_job_log"
+}
+
+func init() {
+	db.RegisterModel(new(JobLog))
+}
+
+// AppendJobLog adds output to the log of the job
+func AppendJobLog(ctx context.Context, jobID int64, content string) error {
+	if content == "" {
+		return nil
+	}
+	return db.Insert(ctx, &JobLog{
+		JobID:   jobID,
+		Content: content,
+	})
+}
+
+// GetLastJobLog returns the last chunk of the output of the job, nil if there is none yet
+func GetLastJobLog(ctx context.Context, jobID int64) (*JobLog, error) {
+	l := &JobLog{}
+	has, err := db.GetEngine(ctx).Where("job_id = ?", jobID).OrderBy("id DESC").Get(l)
+	if err != nil || !has {
+		return nil, err
+	}
+	return l, nil
+}
+
+// UpdateJobLog replaces the content of a chunk of the output of a job
+func UpdateJobLog(ctx context.Context, l *JobLog) error {
+	_, err := db.GetEngine(ctx).ID(l.ID).Cols("content").Update(l)
+	return err
+}
+
+// GetJobLog returns the complete output of the job
+func GetJobLog(ctx context.Context, jobID int64) (string, error) {
+	logs := make([]*JobLog, 0, 10)
+	if err := db.GetEngine(ctx).Where("job_id = ?", jobID).OrderBy("id").Find(&logs); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, l := range logs {
+		sb.WriteString(l.Content)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models/unittest"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m, &unittest.TestOptions{
+		GiteaRootPath: filepath.Join("..", ".."),
+		FixtureFiles: []string{
+			"ci_job.yml",
+			"ci_job_log.yml",
+			"ci_run.yml",
+			"ci_run_index.yml",
+			"ci_runner.yml",
+			"ci_runner_token.yml",
+			"repository.yml",
+			"user.yml",
+		},
+	})
+}
@@ -0,0 +1,213 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrRunNotExist indicates a run not exist error
+var ErrRunNotExist = errors.New("Run does not exist")
+
+// Run represents the execution of a workflow triggered by an event
+type Run struct {
+	ID            int64                  `xorm:"pk autoincr"`
+	RepoID        int64                  `xorm:"INDEX UNIQUE(repo_index)"`
+	Repo          *repo_model.Repository `xorm:"-"`
+	Index         int64                  `xorm:"INDEX UNIQUE(repo_index)"` // a unique number for each run of a repository
+	WorkflowID    string                 // the file name of the workflow
+	Title         string
+	TriggerUserID int64
+	TriggerUser   *user_model.User `xorm:"-"`
+	Event         string
+	Ref           string
+	CommitSHA     string `xorm:"VARCHAR(64) INDEX"`
+	Status        Status `xorm:"INDEX"`
+
+	StartedUnix timeutil.TimeStamp
+	StoppedUnix timeutil.TimeStamp
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the name of this table
+func (Run) TableName() string {
+	return "ci_run"
+}
+
+// RunIndex represents the per repository index of the runs
+type RunIndex db.ResourceIndex
+
+// TableName sets the name of this table
+func (RunIndex) TableName() string {
+	return "ci_run_index"
+}
+
+func init() {
+	db.RegisterModel(new(Run))
+	db.RegisterModel(new(RunIndex))
+}
+
+// LoadAttributes loads the repository and the user who triggered the run
+func (r *Run) LoadAttributes(ctx context.Context) (err error) {
+	if r.Repo == nil {
+		if r.Repo, err = repo_model.GetRepositoryByIDCtx(ctx, r.RepoID); err != nil {
+			return err
+		}
+	}
+	if r.TriggerUser == nil {
+		if r.TriggerUser, err = user_model.GetUserByIDCtx(ctx, r.TriggerUserID); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return err
+			}
+			r.TriggerUser = user_model.NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// Link returns the relative url of the run
+func (r *Run) Link() string {
+	return fmt.Sprintf("%s/ci/runs/%d", r.Repo.Link(), r.Index)
+}
+
+// RefName returns the branch or tag name of the ref the run was triggered for
+func (r *Run) RefName() string {
+	return refName(r.Ref)
+}
+
+// Duration returns the seconds the run has been running
+func (r *Run) Duration() int64 {
+	return duration(r.StartedUnix, r.StoppedUnix)
+}
+
+// InsertRun saves the run and its jobs
+func InsertRun(ctx context.Context, run *Run, jobs []*Job) error {
+	index, err := db.GetNextResourceIndex("ci_run_index", run.RepoID)
+	if err != nil {
+		return err
+	}
+	run.Index = index
+	run.Status = StatusWaiting
+
+	return db.WithTx(func(ctx context.Context) error {
+		if err := db.Insert(ctx, run); err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			job.RunID = run.ID
+			job.RepoID = run.RepoID
+			job.Status = StatusWaiting
+			if err := db.Insert(ctx, job); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, ctx)
+}
+
+// GetRunByIndex returns the run of a repository with the given index
+func GetRunByIndex(ctx context.Context, repoID, index int64) (*Run, error) {
+	r := &Run{}
+	has, err := db.GetEngine(ctx).Where("repo_id = ? AND `index` = ?", repoID, index).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunNotExist
+	}
+	return r, nil
+}
+
+// GetRunByID returns the run with the given id
+func GetRunByID(ctx context.Context, id int64) (*Run, error) {
+	r := &Run{}
+	has, err := db.GetEngine(ctx).ID(id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunNotExist
+	}
+	return r, nil
+}
+
+// FindRunsOptions are the options to search the runs of a repository
+type FindRunsOptions struct {
+	db.ListOptions
+	RepoID int64
+}
+
+// FindRuns returns the runs of a repository, most recent first
+func FindRuns(ctx context.Context, opts FindRunsOptions) ([]*Run, int64, error) {
+	sess := db.GetEngine(ctx).Where("repo_id = ?", opts.RepoID).OrderBy("id DESC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	runs := make([]*Run, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&runs)
+	return runs, count, err
+}
+
+// UpdateRunStatus updates the status of the run from the statuses of its jobs
+func UpdateRunStatus(ctx context.Context, run *Run) error {
+	jobs, err := GetJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+	statuses := make([]Status, 0, len(jobs))
+	for _, job := range jobs {
+		statuses = append(statuses, job.Status)
+		if job.StartedUnix > 0 && (run.StartedUnix == 0 || job.StartedUnix < run.StartedUnix) {
+			run.StartedUnix = job.StartedUnix
+		}
+		if job.StoppedUnix > run.StoppedUnix {
+			run.StoppedUnix = job.StoppedUnix
+		}
+	}
+	run.Status = aggregateStatus(statuses)
+	if !run.Status.IsDone() {
+		run.StoppedUnix = 0
+	}
+	_, err = db.GetEngine(ctx).ID(run.ID).Cols("status", "started_unix", "stopped_unix").Update(run)
+	return err
+}
+
+// DeleteRunsByRepoID removes all runs, jobs and logs of a repository
+func DeleteRunsByRepoID(ctx context.Context, repoID int64) error {
+	if _, err := db.GetEngine(ctx).
+		Where("job_id IN (SELECT id FROM ci_job WHERE repo_id = ?)", repoID).
+		Delete(&JobLog{}); err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(&Job{}); err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(&Run{}); err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(&Runner{}); err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(&RunnerToken{}); err != nil {
+		return err
+	}
+	return db.DeleteResouceIndex(ctx, "ci_run_index", repoID)
+}
+
+func duration(started, stopped timeutil.TimeStamp) int64 {
+	if started == 0 {
+		return 0
+	}
+	if stopped == 0 {
+		return int64(timeutil.TimeStampNow() - started)
+	}
+	return int64(stopped - started)
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	gouuid "github.com/google/uuid"
+)
+
+// ErrRunnerNotExist indicates a runner not exist error
+var ErrRunnerNotExist = errors.New("Runner does not exist")
+
+// Runner represents a registered process executing the jobs
+type Runner struct {
+	ID     int64    `xorm:"pk autoincr"`
+	UUID   string   `xorm:"UNIQUE"`
+	Name   string   `xorm:"NOT NULL"`
+	RepoID int64    `xorm:"INDEX"` // 0 for runners available to all repositories
+	Labels []string `xorm:"TEXT JSON"`
+
+	Token          string `xorm:"-"`
+	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+	LastOnline  timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName sets the name of this table
+func (Runner) TableName() string {
+	return "ci_runner"
+}
+
+func init() {
+	db.RegisterModel(new(Runner))
+}
+
+// IsOnline checks if the runner has recently asked for jobs or reported on them
+func (r *Runner) IsOnline() bool {
+	return r.LastOnline.AddDuration(setting.CI.RunnerTimeout) > timeutil.TimeStampNow()
+}
+
+// HasLabels checks if the runner has all the given labels
+func (r *Runner) HasLabels(labels []string) bool {
+	for _, label := range labels {
+		if !util.IsStringInSlice(label, r.Labels, true) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRunner registers a new runner and generates its token
+func NewRunner(ctx context.Context, r *Runner) error {
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	r.UUID = gouuid.New().String()
+	r.TokenSalt = salt
+	r.Token = base.EncodeSha1(gouuid.New().String())
+	r.TokenHash = auth.HashToken(r.Token, r.TokenSalt)
+	r.TokenLastEight = r.Token[len(r.Token)-8:]
+	r.LastOnline = timeutil.TimeStampNow()
+	return db.Insert(ctx, r)
+}
+
+// GetRunnerByToken returns the runner the token was generated for
+func GetRunnerByToken(ctx context.Context, token string) (*Runner, error) {
+	if len(token) != 40 {
+		return nil, ErrRunnerNotExist
+	}
+
+	runners := make([]*Runner, 0, 1)
+	if err := db.GetEngine(ctx).Where("token_last_eight = ?", token[len(token)-8:]).Find(&runners); err != nil {
+		return nil, err
+	}
+	for _, r := range runners {
+		if subtle.ConstantTimeCompare([]byte(r.TokenHash), []byte(auth.HashToken(token, r.TokenSalt))) == 1 {
+			return r, nil
+		}
+	}
+	return nil, ErrRunnerNotExist
+}
+
+// GetRunnerByID returns the runner with the given id
+func GetRunnerByID(ctx context.Context, id int64) (*Runner, error) {
+	r := &Runner{}
+	has, err := db.GetEngine(ctx).ID(id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunnerNotExist
+	}
+	return r, nil
+}
+
+// GetRunnersByRepoID returns the runners registered for a repository, or the instance wide runners if repoID is 0
+func GetRunnersByRepoID(ctx context.Context, repoID int64) ([]*Runner, error) {
+	runners := make([]*Runner, 0, 5)
+	return runners, db.GetEngine(ctx).Where("repo_id = ?", repoID).OrderBy("id").Find(&runners)
+}
+
+// UpdateRunnerLastOnline marks the runner as online
+func UpdateRunnerLastOnline(ctx context.Context, r *Runner) error {
+	r.LastOnline = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(r.ID).Cols("last_online").Update(r)
+	return err
+}
+
+// DeleteRunner removes a runner, the jobs it is running are failed by the abandoned jobs check
+func DeleteRunner(ctx context.Context, r *Runner) error {
+	_, err := db.GetEngine(ctx).ID(r.ID).Delete(&Runner{})
+	return err
+}
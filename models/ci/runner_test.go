@@ -0,0 +1,67 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunnerToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, err := GetRunnerToken(db.DefaultContext, 1)
+	assert.ErrorIs(t, err, ErrRunnerTokenNotExist)
+
+	token, err := ResetRunnerToken(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.Len(t, token.Token, 40)
+
+	// only the hash and the last eight characters are stored
+	stored, err := GetRunnerToken(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, stored.Token)
+	assert.Equal(t, token.Token[32:], stored.TokenLastEight)
+	assert.NotContains(t, stored.TokenHash, token.Token)
+
+	reset, err := ResetRunnerToken(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token.Token, reset.Token)
+
+	_, err = GetRunnerTokenByToken(db.DefaultContext, token.Token)
+	assert.ErrorIs(t, err, ErrRunnerTokenNotExist)
+	_, err = GetRunnerTokenByToken(db.DefaultContext, "")
+	assert.ErrorIs(t, err, ErrRunnerTokenNotExist)
+	found, err := GetRunnerTokenByToken(db.DefaultContext, reset.Token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, found.RepoID)
+}
+
+func TestGetRunnerByToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	runner := &Runner{Name: "runner", RepoID: 1, Labels: []string{"linux"}}
+	assert.NoError(t, NewRunner(db.DefaultContext, runner))
+	assert.Len(t, runner.Token, 40)
+
+	found, err := GetRunnerByToken(db.DefaultContext, runner.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, runner.ID, found.ID)
+	assert.Equal(t, []string{"linux"}, found.Labels)
+	assert.True(t, found.IsOnline())
+	assert.True(t, found.HasLabels([]string{"linux"}))
+	assert.False(t, found.HasLabels([]string{"linux", "windows"}))
+
+	_, err = GetRunnerByToken(db.DefaultContext, "0000000000000000000000000000000000000000")
+	assert.ErrorIs(t, err, ErrRunnerNotExist)
+
+	assert.NoError(t, DeleteRunner(db.DefaultContext, runner))
+	_, err = GetRunnerByToken(db.DefaultContext, runner.Token)
+	assert.ErrorIs(t, err, ErrRunnerNotExist)
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	gouuid "github.com/google/uuid"
+)
+
+// ErrRunnerTokenNotExist indicates a runner registration token not exist error
+var ErrRunnerTokenNotExist = errors.New("Runner registration token does not exist")
+
+// RunnerToken is used to register new runners of a repository or of the instance
+type RunnerToken struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE"` // 0 for the instance wide registration token
+
+	Token          string `xorm:"-"`
+	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName sets the name of this table
+func (RunnerToken) TableName() string {
+	return "ci_runner_token"
+}
+
+func init() {
+	db.RegisterModel(new(RunnerToken))
+}
+
+// GetRunnerToken returns the registration token of the repository, only its hash is stored so the token itself is not set
+func GetRunnerToken(ctx context.Context, repoID int64) (*RunnerToken, error) {
+	t := &RunnerToken{}
+	has, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunnerTokenNotExist
+	}
+	return t, nil
+}
+
+// ResetRunnerToken replaces the registration token of the repository by a new one
+func ResetRunnerToken(ctx context.Context, repoID int64) (*RunnerToken, error) {
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return nil, err
+	}
+	t := &RunnerToken{
+		RepoID:    repoID,
+		Token:     base.EncodeSha1(gouuid.New().String()),
+		TokenSalt: salt,
+	}
+	t.TokenHash = auth.HashToken(t.Token, t.TokenSalt)
+	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	return t, db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(&RunnerToken{}); err != nil {
+			return err
+		}
+		return db.Insert(ctx, t)
+	}, ctx)
+}
+
+// GetRunnerTokenByToken returns the registration token with the given value
+func GetRunnerTokenByToken(ctx context.Context, token string) (*RunnerToken, error) {
+	if len(token) != 40 {
+		return nil, ErrRunnerTokenNotExist
+	}
+
+	tokens := make([]*RunnerToken, 0, 1)
+	if err := db.GetEngine(ctx).Where("token_last_eight = ?", token[len(token)-8:]).Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(auth.HashToken(token, t.TokenSalt))) == 1 {
+			return t, nil
+		}
+	}
+	return nil, ErrRunnerTokenNotExist
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Status represents the state of a job or a run
+type Status int
+
+// enumerate all the statuses of a job or a run
+const (
+	StatusWaiting   Status = iota + 1 // waiting for a runner to pick up the job
+	StatusRunning                     // executed by a runner
+	StatusSuccess                     // all steps have succeeded
+	StatusFailure                     // a step has failed or the runner got lost
+	StatusCancelled                   // cancelled by a user
+)
+
+var statusNames = map[Status]string{
+	StatusWaiting:   "waiting",
+	StatusRunning:   "running",
+	StatusSuccess:   "success",
+	StatusFailure:   "failure",
+	StatusCancelled: "cancelled",
+}
+
+// String returns the name of the status
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// LocaleString returns the locale key of the status
+func (s Status) LocaleString() string {
+	return "repo.ci.status." + s.String()
+}
+
+// IsDone checks if the status is final
+func (s Status) IsDone() bool {
+	return s == StatusSuccess || s == StatusFailure || s == StatusCancelled
+}
+
+// CommitStatusState returns the state of the commit status reporting the status
+func (s Status) CommitStatusState() api.CommitStatusState {
+	switch s {
+	case StatusSuccess:
+		return api.CommitStatusSuccess
+	case StatusFailure:
+		return api.CommitStatusFailure
+	case StatusCancelled:
+		return api.CommitStatusError
+	default:
+		return api.CommitStatusPending
+	}
+}
+
+// ParseStatus returns the status with the given name
+func ParseStatus(name string) (Status, bool) {
+	for s, n := range statusNames {
+		if n == name {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// aggregateStatus returns the status of a run from the statuses of its jobs
+func aggregateStatus(statuses []Status) Status {
+	var waiting, done, failed, cancelled int
+	for _, s := range statuses {
+		switch s {
+		case StatusWaiting:
+			waiting++
+		case StatusFailure:
+			failed++
+		case StatusCancelled:
+			cancelled++
+		}
+		if s.IsDone() {
+			done++
+		}
+	}
+	switch {
+	case waiting == len(statuses):
+		return StatusWaiting
+	case done < len(statuses):
+		return StatusRunning
+	case failed > 0:
+		return StatusFailure
+	case cancelled > 0:
+		return StatusCancelled
+	default:
+		return StatusSuccess
+	}
+}
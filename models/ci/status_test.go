@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateStatus(t *testing.T) {
+	cases := []struct {
+		statuses []Status
+		expected Status
+	}{
+		{[]Status{StatusWaiting, StatusWaiting}, StatusWaiting},
+		{[]Status{StatusWaiting, StatusRunning}, StatusRunning},
+		{[]Status{StatusWaiting, StatusSuccess}, StatusRunning},
+		{[]Status{StatusSuccess, StatusSuccess}, StatusSuccess},
+		{[]Status{StatusSuccess, StatusFailure}, StatusFailure},
+		{[]Status{StatusFailure, StatusCancelled}, StatusFailure},
+		{[]Status{StatusSuccess, StatusCancelled}, StatusCancelled},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, aggregateStatus(c.statuses), "%v", c.statuses)
+	}
+}
@@ -144,6 +144,21 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return "access token is empty"
 }
 
+// ErrAccessTokenScopeNotRestrictable represents a "AccessTokenScopeNotRestrictable" kind of error.
+type ErrAccessTokenScopeNotRestrictable struct {
+	Scope string
+}
+
+// IsErrAccessTokenScopeNotRestrictable checks if an error is a ErrAccessTokenScopeNotRestrictable.
+func IsErrAccessTokenScopeNotRestrictable(err error) bool {
+	_, ok := err.(ErrAccessTokenScopeNotRestrictable)
+	return ok
+}
+
+func (err ErrAccessTokenScopeNotRestrictable) Error() string {
+	return fmt.Sprintf("access token scope can't be granted to a token restricted to a repository or an organization [scope: %s]", err.Scope)
+}
+
 // ErrNoPendingRepoTransfer is an error type for repositories without a pending
 // transfer request
 type ErrNoPendingRepoTransfer struct {
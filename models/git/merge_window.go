@@ -0,0 +1,204 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const minutesPerDay = 24 * 60
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// MergeWindow is a weekly period during which pull requests can be merged into a protected branch
+type MergeWindow struct {
+	Weekdays [7]bool // the days the window starts on, indexed by time.Weekday
+	Start    int     // minutes since midnight
+	End      int     // minutes since midnight, the window ends on the next day if End isn't after Start
+}
+
+// ErrInvalidMergeWindow represents an error of a merge window which can't be parsed
+type ErrInvalidMergeWindow struct {
+	Window string
+	Reason string
+}
+
+func (err ErrInvalidMergeWindow) Error() string {
+	return fmt.Sprintf("invalid merge window %q: %s", err.Window, err.Reason)
+}
+
+// IsErrInvalidMergeWindow checks if an error is a ErrInvalidMergeWindow.
+func IsErrInvalidMergeWindow(err error) bool {
+	_, ok := err.(ErrInvalidMergeWindow)
+	return ok
+}
+
+// ParseMergeWindows parses the merge windows of a protected branch, one window per line,
+// e.g. "Mon-Thu" or "Mon,Wed 09:00-17:00". Empty lines and lines starting with # are ignored.
+func ParseMergeWindows(s string) ([]*MergeWindow, error) {
+	var windows []*MergeWindow
+	for _, line := range nonEmptyLines(s) {
+		window, err := parseMergeWindow(line)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// nonEmptyLines splits the text into trimmed lines, the empty lines and the lines starting with # are skipped
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func parseMergeWindow(line string) (*MergeWindow, error) {
+	fields := strings.Fields(line)
+	if len(fields) > 2 {
+		return nil, ErrInvalidMergeWindow{Window: line, Reason: "expected the days and optionally a time range"}
+	}
+
+	window := &MergeWindow{End: minutesPerDay}
+	for _, days := range strings.Split(fields[0], ",") {
+		if days == "*" {
+			window.Weekdays = [7]bool{true, true, true, true, true, true, true}
+			continue
+		}
+		first, last, isRange := strings.Cut(days, "-")
+		from, ok := parseWeekday(first)
+		if !ok {
+			return nil, ErrInvalidMergeWindow{Window: line, Reason: fmt.Sprintf("unknown day %q", first)}
+		}
+		to := from
+		if isRange {
+			if to, ok = parseWeekday(last); !ok {
+				return nil, ErrInvalidMergeWindow{Window: line, Reason: fmt.Sprintf("unknown day %q", last)}
+			}
+		}
+		// the ranges can wrap around the end of the week, e.g. Fri-Mon
+		for d := from; ; d = (d + 1) % 7 {
+			window.Weekdays[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+
+	if len(fields) == 2 {
+		start, end, ok := strings.Cut(fields[1], "-")
+		if !ok {
+			return nil, ErrInvalidMergeWindow{Window: line, Reason: "expected a time range like 09:00-17:00"}
+		}
+		if window.Start, ok = parseMinutes(start); !ok || window.Start == minutesPerDay {
+			return nil, ErrInvalidMergeWindow{Window: line, Reason: fmt.Sprintf("invalid time %q", start)}
+		}
+		if window.End, ok = parseMinutes(end); !ok {
+			return nil, ErrInvalidMergeWindow{Window: line, Reason: fmt.Sprintf("invalid time %q", end)}
+		}
+		if window.Start == window.End {
+			return nil, ErrInvalidMergeWindow{Window: line, Reason: "the window is empty"}
+		}
+	}
+	return window, nil
+}
+
+// parseWeekday parses the short or the full english name of a day
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for i, name := range weekdayNames {
+		if s == name || s == strings.ToLower(time.Weekday(i).String()) {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+// parseMinutes parses a time of the day like 09:30 or 24:00
+func parseMinutes(s string) (int, bool) {
+	var hours, minutes int
+	if n, err := fmt.Sscanf(s, "%d:%d", &hours, &minutes); err != nil || n != 2 || len(s) != 5 {
+		return 0, false
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > minutesPerDay {
+		return 0, false
+	}
+	return hours*60 + minutes, true
+}
+
+// Contains returns true if the time is within the window
+func (w *MergeWindow) Contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	today, yesterday := t.Weekday(), (t.Weekday()+6)%7
+	if w.Start < w.End {
+		return w.Weekdays[today] && minutes >= w.Start && minutes < w.End
+	}
+	return (w.Weekdays[today] && minutes >= w.Start) || (w.Weekdays[yesterday] && minutes < w.End)
+}
+
+// GetMergeWindows returns the merge windows of the protected branch, the invalid windows are skipped
+func (protectBranch *ProtectedBranch) GetMergeWindows() []*MergeWindow {
+	var windows []*MergeWindow
+	for _, line := range nonEmptyLines(protectBranch.MergeWindows) {
+		window, err := parseMergeWindow(line)
+		if err != nil {
+			log.Info("Invalid merge window of protected branch %d (skipped): %v", protectBranch.ID, err)
+			continue
+		}
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+// IsInMergeWindow returns true if pull requests can be merged into the branch at the time,
+// which is always the case if the branch has no merge windows.
+// The windows are in the default time zone of the instance.
+func (protectBranch *ProtectedBranch) IsInMergeWindow(t time.Time) bool {
+	windows := protectBranch.GetMergeWindows()
+	if len(windows) == 0 {
+		return true
+	}
+	t = t.In(setting.DefaultUILocation)
+	for _, w := range windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextMergeWindow returns when the next merge window of the branch opens after the time,
+// it returns the zero time if the branch has no merge windows
+func (protectBranch *ProtectedBranch) NextMergeWindow(t time.Time) time.Time {
+	var next time.Time
+	t = t.In(setting.DefaultUILocation)
+	for _, w := range protectBranch.GetMergeWindows() {
+		for days := 0; days <= 7; days++ {
+			day := time.Date(t.Year(), t.Month(), t.Day()+days, 0, 0, 0, 0, t.Location())
+			if !w.Weekdays[day.Weekday()] {
+				continue
+			}
+			start := day.Add(time.Duration(w.Start) * time.Minute)
+			if start.After(t) {
+				if next.IsZero() || start.Before(next) {
+					next = start
+				}
+				break
+			}
+		}
+	}
+	return next
+}
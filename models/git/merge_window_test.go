@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git_test
+
+import (
+	"testing"
+	"time"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMergeWindows(t *testing.T) {
+	windows, err := git_model.ParseMergeWindows("# no merges during the weekend\nMon-Thu\n\nfriday 00:00-12:00\nSat,Sun 22:00-02:00")
+	assert.NoError(t, err)
+	if assert.Len(t, windows, 3) {
+		assert.Equal(t, [7]bool{false, true, true, true, true, false, false}, windows[0].Weekdays)
+		assert.Equal(t, 0, windows[0].Start)
+		assert.Equal(t, 24*60, windows[0].End)
+		assert.Equal(t, [7]bool{false, false, false, false, false, true, false}, windows[1].Weekdays)
+		assert.Equal(t, 12*60, windows[1].End)
+		assert.Equal(t, [7]bool{true, false, false, false, false, false, true}, windows[2].Weekdays)
+		assert.Equal(t, 22*60, windows[2].Start)
+		assert.Equal(t, 2*60, windows[2].End)
+	}
+
+	windows, err = git_model.ParseMergeWindows("Fri-Mon")
+	assert.NoError(t, err)
+	if assert.Len(t, windows, 1) {
+		assert.Equal(t, [7]bool{true, true, false, false, false, true, true}, windows[0].Weekdays)
+	}
+
+	for _, invalid := range []string{"Mon-Someday", "Mon 9:00-17:00", "Mon 09:00", "Mon 09:00-09:00", "Mon 09:00-25:00", "Mon 09:00-17:00 UTC"} {
+		_, err := git_model.ParseMergeWindows(invalid)
+		assert.True(t, git_model.IsErrInvalidMergeWindow(err), invalid)
+	}
+}
+
+func TestProtectedBranchMergeWindows(t *testing.T) {
+	defer func(loc *time.Location) {
+		setting.DefaultUILocation = loc
+	}(setting.DefaultUILocation)
+	setting.DefaultUILocation = time.UTC
+
+	protectedBranch := &git_model.ProtectedBranch{}
+	assert.True(t, protectedBranch.IsInMergeWindow(time.Now()))
+	assert.True(t, protectedBranch.NextMergeWindow(time.Now()).IsZero())
+
+	protectedBranch.MergeWindows = "Mon-Thu\nFri 00:00-12:00\nSun 22:00-02:00"
+	for _, c := range []struct {
+		Time     string
+		InWindow bool
+		Next     string
+	}{
+		{"2022-06-06T10:00:00Z", true, "2022-06-07T00:00:00Z"},  // Monday
+		{"2022-06-10T11:59:00Z", true, "2022-06-12T22:00:00Z"},  // Friday
+		{"2022-06-10T12:00:00Z", false, "2022-06-12T22:00:00Z"}, // Friday afternoon
+		{"2022-06-11T10:00:00Z", false, "2022-06-12T22:00:00Z"}, // Saturday
+		{"2022-06-12T23:00:00Z", true, "2022-06-13T00:00:00Z"},  // Sunday night
+	} {
+		now, err := time.Parse(time.RFC3339, c.Time)
+		assert.NoError(t, err)
+		assert.Equal(t, c.InWindow, protectedBranch.IsInMergeWindow(now), c.Time)
+		assert.Equal(t, c.Next, protectedBranch.NextMergeWindow(now).Format(time.RFC3339), c.Time)
+	}
+}
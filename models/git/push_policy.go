@@ -0,0 +1,223 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+// PushPolicy is a set of rules an organization enforces on the commits pushed to its repositories
+type PushPolicy struct {
+	ID                   int64  `xorm:"pk autoincr"`
+	OwnerID              int64  `xorm:"UNIQUE NOT NULL"`
+	DryRun               bool   `xorm:"NOT NULL DEFAULT false"` // the violations are only logged and the pushes are accepted
+	MaxFileSize          int64  `xorm:"NOT NULL DEFAULT 0"`     // in bytes, 0 if there is no limit
+	ForbiddenPaths       string `xorm:"TEXT"`                   // semicolon separated glob patterns
+	ForbiddenExtensions  string `xorm:"TEXT"`                   // semicolon separated file extensions
+	RequireSignedCommits bool   `xorm:"NOT NULL DEFAULT false"`
+	CommitMessagePattern string `xorm:"TEXT"` // a regular expression the commit messages must match
+	AllowedEmailDomains  string `xorm:"TEXT"` // semicolon separated domains of the author emails
+	SecretPatterns       string `xorm:"TEXT"` // regular expressions the added files must not match, one per line
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(PushPolicy))
+}
+
+// ErrInvalidPushPolicy represents an error of a push policy rule which can't be parsed
+type ErrInvalidPushPolicy struct {
+	Rule  string
+	Value string
+	Err   error
+}
+
+func (err ErrInvalidPushPolicy) Error() string {
+	return fmt.Sprintf("invalid %s %q: %v", err.Rule, err.Value, err.Err)
+}
+
+// IsErrInvalidPushPolicy checks if an error is a ErrInvalidPushPolicy.
+func IsErrInvalidPushPolicy(err error) bool {
+	_, ok := err.(ErrInvalidPushPolicy)
+	return ok
+}
+
+// Validate checks that the patterns of the policy can be parsed
+func (p *PushPolicy) Validate() error {
+	if p.MaxFileSize < 0 {
+		return ErrInvalidPushPolicy{Rule: "max_file_size", Value: fmt.Sprint(p.MaxFileSize), Err: fmt.Errorf("the size can't be negative")}
+	}
+	for _, expr := range splitPolicyList(strings.ToLower(p.ForbiddenPaths)) {
+		if _, err := glob.Compile(expr, '.', '/'); err != nil {
+			return ErrInvalidPushPolicy{Rule: "forbidden_paths", Value: expr, Err: err}
+		}
+	}
+	if p.CommitMessagePattern != "" {
+		if _, err := regexp.Compile(p.CommitMessagePattern); err != nil {
+			return ErrInvalidPushPolicy{Rule: "commit_message_pattern", Value: p.CommitMessagePattern, Err: err}
+		}
+	}
+	for _, expr := range nonEmptyLines(p.SecretPatterns) {
+		if _, err := regexp.Compile(expr); err != nil {
+			return ErrInvalidPushPolicy{Rule: "secret_patterns", Value: expr, Err: err}
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns true if the policy has no rules
+func (p *PushPolicy) IsEmpty() bool {
+	return p.MaxFileSize == 0 &&
+		len(p.GetForbiddenPathPatterns()) == 0 &&
+		len(p.GetForbiddenExtensions()) == 0 &&
+		!p.RequireSignedCommits &&
+		p.CommitMessagePattern == "" &&
+		len(p.GetAllowedEmailDomains()) == 0 &&
+		len(p.GetSecretPatterns()) == 0
+}
+
+// HasCommitRules returns true if the policy has rules on the commits themselves
+func (p *PushPolicy) HasCommitRules() bool {
+	return p.RequireSignedCommits || p.CommitMessagePattern != "" || len(p.GetAllowedEmailDomains()) > 0
+}
+
+// HasFileRules returns true if the policy has rules on the files changed by the commits
+func (p *PushPolicy) HasFileRules() bool {
+	return p.MaxFileSize > 0 ||
+		len(p.GetForbiddenPathPatterns()) > 0 ||
+		len(p.GetForbiddenExtensions()) > 0 ||
+		len(p.GetSecretPatterns()) > 0
+}
+
+// GetForbiddenPathPatterns returns the glob patterns of the forbidden paths, the patterns match lower case paths
+func (p *PushPolicy) GetForbiddenPathPatterns() []glob.Glob {
+	return getFilePatterns(p.ForbiddenPaths)
+}
+
+// GetForbiddenExtensions returns the forbidden file extensions in lower case with their leading dot
+func (p *PushPolicy) GetForbiddenExtensions() []string {
+	exts := splitPolicyList(strings.ToLower(p.ForbiddenExtensions))
+	for i, ext := range exts {
+		if !strings.HasPrefix(ext, ".") {
+			exts[i] = "." + ext
+		}
+	}
+	return exts
+}
+
+// GetAllowedEmailDomains returns the allowed domains of the author emails in lower case
+func (p *PushPolicy) GetAllowedEmailDomains() []string {
+	domains := splitPolicyList(strings.ToLower(p.AllowedEmailDomains))
+	for i, domain := range domains {
+		domains[i] = strings.TrimPrefix(domain, "@")
+	}
+	return domains
+}
+
+// IsEmailAllowed returns true if the email is in one of the allowed domains or their subdomains,
+// or if the policy doesn't restrict the domains
+func (p *PushPolicy) IsEmailAllowed(email string) bool {
+	domains := p.GetAllowedEmailDomains()
+	if len(domains) == 0 {
+		return true
+	}
+	idx := strings.LastIndexByte(email, '@')
+	if idx < 0 {
+		return false
+	}
+	emailDomain := strings.ToLower(email[idx+1:])
+	for _, domain := range domains {
+		if emailDomain == domain || strings.HasSuffix(emailDomain, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCommitMessageRegexp returns the regular expression the commit messages must match, nil if there is none
+func (p *PushPolicy) GetCommitMessageRegexp() *regexp.Regexp {
+	if p.CommitMessagePattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(p.CommitMessagePattern)
+	if err != nil {
+		// an invalid pattern must not let all the commits through
+		log.Error("Invalid commit message pattern of push policy %d: %v", p.ID, err)
+		return regexp.MustCompile(`$.^`)
+	}
+	return re
+}
+
+// SecretPattern is a regular expression of the secrets the added files must not contain
+type SecretPattern struct {
+	Pattern string
+	Regexp  *regexp.Regexp
+}
+
+// GetSecretPatterns returns the patterns of the secrets, the invalid patterns are skipped
+func (p *PushPolicy) GetSecretPatterns() []*SecretPattern {
+	var patterns []*SecretPattern
+	for _, expr := range nonEmptyLines(p.SecretPatterns) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			log.Info("Invalid secret pattern of push policy %d (skipped): %v", p.ID, err)
+			continue
+		}
+		patterns = append(patterns, &SecretPattern{Pattern: expr, Regexp: re})
+	}
+	return patterns
+}
+
+func splitPolicyList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GetPushPolicy returns the push policy of the owner, nil if the owner has none
+func GetPushPolicy(ctx context.Context, ownerID int64) (*PushPolicy, error) {
+	p := &PushPolicy{OwnerID: ownerID}
+	has, err := db.GetByBean(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// UpdatePushPolicy creates or updates the push policy of its owner
+func UpdatePushPolicy(ctx context.Context, p *PushPolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.ID == 0 {
+		return db.Insert(ctx, p)
+	}
+	_, err := db.GetEngine(ctx).ID(p.ID).AllCols().Update(p)
+	return err
+}
+
+// DeletePushPolicy removes the push policy of the owner
+func DeletePushPolicy(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Delete(&PushPolicy{OwnerID: ownerID})
+	return err
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git_test
+
+import (
+	"testing"
+
+	git_model "code.gitea.io/gitea/models/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushPolicyValidate(t *testing.T) {
+	assert.NoError(t, (&git_model.PushPolicy{
+		MaxFileSize:          1024,
+		ForbiddenPaths:       "**/id_rsa;secrets/**",
+		CommitMessagePattern: `^[A-Z]+-[0-9]+`,
+		SecretPatterns:       "# AWS access keys\nAKIA[0-9A-Z]{16}\n\n-----BEGIN [A-Z ]*PRIVATE KEY-----",
+	}).Validate())
+
+	for _, invalid := range []*git_model.PushPolicy{
+		{MaxFileSize: -1},
+		{ForbiddenPaths: "[a-"},
+		{CommitMessagePattern: "(unclosed"},
+		{SecretPatterns: "valid\nAKIA[0-9A-Z"},
+	} {
+		assert.True(t, git_model.IsErrInvalidPushPolicy(invalid.Validate()), "%+v", invalid)
+	}
+}
+
+func TestPushPolicyRules(t *testing.T) {
+	p := &git_model.PushPolicy{
+		ForbiddenExtensions: "EXE; .dll;",
+		AllowedEmailDomains: "example.com;@Example.org",
+		SecretPatterns:      "# comment\nAKIA[0-9A-Z]{16}\n(invalid",
+	}
+	assert.Equal(t, []string{".exe", ".dll"}, p.GetForbiddenExtensions())
+	assert.Equal(t, []string{"example.com", "example.org"}, p.GetAllowedEmailDomains())
+
+	assert.True(t, p.IsEmailAllowed("dev@example.com"))
+	assert.True(t, p.IsEmailAllowed("dev@CI.Example.ORG"))
+	assert.False(t, p.IsEmailAllowed("dev@notexample.com"))
+	assert.False(t, p.IsEmailAllowed("dev@example.com.evil.net"))
+	assert.False(t, p.IsEmailAllowed("dev"))
+	assert.True(t, (&git_model.PushPolicy{}).IsEmailAllowed("dev"))
+
+	patterns := p.GetSecretPatterns()
+	if assert.Len(t, patterns, 1) {
+		assert.Equal(t, "AKIA[0-9A-Z]{16}", patterns[0].Pattern)
+	}
+	assert.True(t, p.HasCommitRules())
+	assert.True(t, p.HasFileRules())
+	assert.False(t, (&git_model.PushPolicy{ForbiddenExtensions: "exe"}).HasCommitRules())
+	assert.True(t, (&git_model.PushPolicy{DryRun: true}).IsEmpty())
+}
@@ -128,6 +128,8 @@ type Issue struct {
 	Ref              string
 
 	DeadlineUnix timeutil.TimeStamp `xorm:"INDEX"`
+	// TimeEstimate is the estimated time in seconds to resolve the issue, 0 if there is no estimate
+	TimeEstimate int64 `xorm:"NOT NULL DEFAULT 0"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -2022,6 +2024,15 @@ func UpdateIssueDeadline(issue *Issue, deadlineUnix timeutil.TimeStamp, doer *us
 	return committer.Commit()
 }
 
+// UpdateIssueTimeEstimate updates the time estimate of the issue in seconds, 0 removes the estimate
+func UpdateIssueTimeEstimate(ctx context.Context, issue *Issue, estimate int64) error {
+	if issue.TimeEstimate == estimate {
+		return nil
+	}
+	issue.TimeEstimate = estimate
+	return UpdateIssueCols(ctx, issue, "time_estimate")
+}
+
 // DeleteInIssue delete records in beans with external key issue_id = ?
 func DeleteInIssue(ctx context.Context, issueID int64, beans ...interface{}) error {
 	e := db.GetEngine(ctx)
@@ -11,6 +11,8 @@ import (
 	"code.gitea.io/gitea/models/db"
 	project_model "code.gitea.io/gitea/models/project"
 	user_model "code.gitea.io/gitea/models/user"
+
+	"xorm.io/builder"
 )
 
 // LoadProject load the project the issue was assigned to
@@ -28,6 +30,16 @@ func (issue *Issue) loadProject(ctx context.Context) (err error) {
 			return err
 		}
 		issue.Project = &p
+		if p.ID == 0 {
+			return nil
+		}
+		if p.RepoID == issue.RepoID && issue.Repo != nil {
+			p.Repo = issue.Repo
+		}
+		if err = p.LoadOwner(ctx); err != nil {
+			return err
+		}
+		return p.LoadRepo(ctx)
 	}
 	return err
 }
@@ -60,14 +72,16 @@ func (issue *Issue) projectBoardID(ctx context.Context) int64 {
 	return ip.ProjectBoardID
 }
 
-// LoadIssuesFromBoard load issues assigned to this board
-func LoadIssuesFromBoard(b *project_model.Board) (IssueList, error) {
+// LoadIssuesFromBoard load issues assigned to this board.
+// If repoCond is not nil, only issues of repositories matching it are loaded.
+func LoadIssuesFromBoard(b *project_model.Board, repoCond builder.Cond) (IssueList, error) {
 	issueList := make([]*Issue, 0, 10)
 
 	if b.ID != 0 {
 		issues, err := Issues(&IssuesOptions{
 			ProjectBoardID: b.ID,
 			ProjectID:      b.ProjectID,
+			RepoCond:       repoCond,
 		})
 		if err != nil {
 			return nil, err
@@ -79,6 +93,7 @@ func LoadIssuesFromBoard(b *project_model.Board) (IssueList, error) {
 		issues, err := Issues(&IssuesOptions{
 			ProjectBoardID: -1, // Issues without ProjectBoardID
 			ProjectID:      b.ProjectID,
+			RepoCond:       repoCond,
 		})
 		if err != nil {
 			return nil, err
@@ -94,10 +109,10 @@ func LoadIssuesFromBoard(b *project_model.Board) (IssueList, error) {
 }
 
 // LoadIssuesFromBoardList load issues assigned to the boards
-func LoadIssuesFromBoardList(bs project_model.BoardList) (map[int64]IssueList, error) {
+func LoadIssuesFromBoardList(bs project_model.BoardList, repoCond builder.Cond) (map[int64]IssueList, error) {
 	issuesMap := make(map[int64]IssueList, len(bs))
 	for i := range bs {
-		il, err := LoadIssuesFromBoard(bs[i])
+		il, err := LoadIssuesFromBoard(bs[i], repoCond)
 		if err != nil {
 			return nil, err
 		}
@@ -130,7 +145,8 @@ func addUpdateIssueProject(ctx context.Context, issue *Issue, doer *user_model.U
 		if err != nil {
 			return err
 		}
-		if newProject.RepoID != issue.RepoID {
+		// issues of any repository may be added to individual and organization projects
+		if !newProject.IsOwnerProject() && newProject.RepoID != issue.RepoID {
 			return fmt.Errorf("issue's repository is not the same as project's repository")
 		}
 	}
@@ -60,6 +60,8 @@ type Milestone struct {
 	ClosedDateUnix timeutil.TimeStamp
 	DeadlineString string `xorm:"-"`
 
+	// TimeBudget is the time in seconds which can be spent on the issues of the milestone, 0 if there is no budget
+	TimeBudget       int64 `xorm:"NOT NULL DEFAULT 0"`
 	TotalTrackedTime int64 `xorm:"-"`
 	TimeSinceUpdate  int64 `xorm:"-"`
 }
@@ -68,6 +70,19 @@ func init() {
 	db.RegisterModel(new(Milestone))
 }
 
+// IsOverBudget returns true if more time was tracked than budgeted, the total tracked time must be loaded
+func (m *Milestone) IsOverBudget() bool {
+	return m.TimeBudget > 0 && m.TotalTrackedTime > m.TimeBudget
+}
+
+// BudgetPercentage returns the percentage of the budget which was spent, the total tracked time must be loaded
+func (m *Milestone) BudgetPercentage() int {
+	if m.TimeBudget <= 0 {
+		return 0
+	}
+	return int(m.TotalTrackedTime * 100 / m.TimeBudget)
+}
+
 // BeforeUpdate is invoked from XORM before updating this object.
 func (m *Milestone) BeforeUpdate() {
 	if m.NumIssues > 0 {
@@ -838,7 +838,8 @@ func MergeBlockedByOfficialReviewRequests(ctx context.Context, protectBranch *gi
 	return has
 }
 
-// MergeBlockedByOutdatedBranch returns true if merge is blocked by an outdated head branch
+// MergeBlockedByOutdatedBranch returns true if merge is blocked by an outdated head branch.
+// The merge queue always merges onto the latest base branch, so it never blocks then.
 func MergeBlockedByOutdatedBranch(protectBranch *git_model.ProtectedBranch, pr *PullRequest) bool {
-	return protectBranch.BlockOnOutdatedBranch && pr.CommitsBehind > 0
+	return protectBranch.BlockOnOutdatedBranch && !protectBranch.EnableMergeQueue && pr.CommitsBehind > 0
 }
@@ -0,0 +1,116 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// CodeOwnerGroup represents the owners of the files matched by a single CODEOWNERS rule
+type CodeOwnerGroup struct {
+	Pattern string
+	Users   []*user_model.User
+	Teams   []*organization.Team
+}
+
+// IsEmpty returns true if the group has no owners
+func (g *CodeOwnerGroup) IsEmpty() bool {
+	return len(g.Users) == 0 && len(g.Teams) == 0
+}
+
+// TeamIDs returns the ids of the owning teams
+func (g *CodeOwnerGroup) TeamIDs() []int64 {
+	ids := make([]int64, 0, len(g.Teams))
+	for _, team := range g.Teams {
+		ids = append(ids, team.ID)
+	}
+	return ids
+}
+
+// getValidApproverIDs returns the reviewers whose latest review of pr is an approval which still counts
+func getValidApproverIDs(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest) ([]int64, error) {
+	reviews := make([]*Review, 0, 10)
+	// Get latest approval or rejection of each reviewer
+	if err := db.GetEngine(ctx).SQL("SELECT * FROM review WHERE id IN (SELECT max(id) as id FROM review WHERE issue_id = ? AND reviewer_team_id = 0 AND type in (?, ?) AND dismissed = ? AND original_author_id = 0 GROUP BY issue_id, reviewer_id)",
+		pr.IssueID, ReviewTypeApprove, ReviewTypeReject, false).
+		Find(&reviews); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(reviews))
+	for _, review := range reviews {
+		if review.Type != ReviewTypeApprove || (protectBranch.DismissStaleApprovals && review.Stale) {
+			continue
+		}
+		ids = append(ids, review.ReviewerID)
+	}
+	return ids, nil
+}
+
+// FilterUnapprovedCodeOwnerGroups returns the code owner groups none of whose owners approved pr yet.
+// A team is considered to have approved if one of its members approved. A group without owners is never approved.
+func FilterUnapprovedCodeOwnerGroups(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest, groups []*CodeOwnerGroup) ([]*CodeOwnerGroup, error) {
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	approverIDs, err := getValidApproverIDs(ctx, protectBranch, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	unapproved := make([]*CodeOwnerGroup, 0, len(groups))
+	for _, group := range groups {
+		approved, err := isApprovedByCodeOwnerGroup(ctx, group, approverIDs)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			unapproved = append(unapproved, group)
+		}
+	}
+	return unapproved, nil
+}
+
+func isApprovedByCodeOwnerGroup(ctx context.Context, group *CodeOwnerGroup, approverIDs []int64) (bool, error) {
+	teamIDs := group.TeamIDs()
+	for _, approverID := range approverIDs {
+		for _, user := range group.Users {
+			if user.ID == approverID {
+				return true, nil
+			}
+		}
+		if len(teamIDs) == 0 {
+			continue
+		}
+		inTeam, err := organization.IsUserInTeams(ctx, approverID, teamIDs)
+		if err != nil {
+			return false, err
+		}
+		if inTeam {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MergeBlockedByCodeOwners returns true if merge is blocked because code owners of changed files have not approved
+func MergeBlockedByCodeOwners(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest, groups []*CodeOwnerGroup) bool {
+	if !protectBranch.RequireCodeOwnerApproval {
+		return false
+	}
+	unapproved, err := FilterUnapprovedCodeOwnerGroups(ctx, protectBranch, pr, groups)
+	if err != nil {
+		log.Error("MergeBlockedByCodeOwners: %v", err)
+		return true
+	}
+	return len(unapproved) > 0
+}
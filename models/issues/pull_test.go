@@ -8,8 +8,11 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -275,3 +278,38 @@ func TestDeleteOrphanedObjects(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, countBefore, countAfter)
 }
+
+func TestFilterUnapprovedCodeOwnerGroups(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: 2})
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	user4 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4})
+	team2 := unittest.AssertExistsAndLoadBean(t, &organization.Team{ID: 2})
+
+	groups := []*issues_model.CodeOwnerGroup{
+		{Pattern: "*.go", Users: []*user_model.User{user4}},
+		{Pattern: "docs/", Teams: []*organization.Team{team2}},
+		{Pattern: "*.md", Users: []*user_model.User{user2}},
+		{Pattern: "unowned"},
+	}
+
+	// user 4 has approved, and is a member of team 2, but user 2 requested changes and nobody can approve unowned files
+	protectBranch := &git_model.ProtectedBranch{RequireCodeOwnerApproval: true}
+	unapproved, err := issues_model.FilterUnapprovedCodeOwnerGroups(db.DefaultContext, protectBranch, pr, groups)
+	assert.NoError(t, err)
+	if assert.Len(t, unapproved, 2) {
+		assert.Equal(t, "*.md", unapproved[0].Pattern)
+		assert.Equal(t, "unowned", unapproved[1].Pattern)
+	}
+	assert.True(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, protectBranch, pr, groups))
+	assert.False(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, protectBranch, pr, groups[:2]))
+
+	// the approval of user 4 is stale
+	protectBranch.DismissStaleApprovals = true
+	unapproved, err = issues_model.FilterUnapprovedCodeOwnerGroups(db.DefaultContext, protectBranch, pr, groups)
+	assert.NoError(t, err)
+	assert.Len(t, unapproved, 4)
+
+	protectBranch.RequireCodeOwnerApproval = false
+	assert.False(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, protectBranch, pr, groups))
+}
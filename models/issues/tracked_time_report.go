@@ -0,0 +1,204 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+)
+
+// TimeReportGroup is the grouping of the tracked times of a time report
+type TimeReportGroup string
+
+// enumerate all time report groupings
+const (
+	TimeReportGroupDay   TimeReportGroup = "day"
+	TimeReportGroupWeek  TimeReportGroup = "week"
+	TimeReportGroupUser  TimeReportGroup = "user"
+	TimeReportGroupIssue TimeReportGroup = "issue"
+)
+
+// TimeReportGroups are all time report groupings
+var TimeReportGroups = []TimeReportGroup{TimeReportGroupDay, TimeReportGroupWeek, TimeReportGroupUser, TimeReportGroupIssue}
+
+// IsValid returns true if the grouping is known
+func (g TimeReportGroup) IsValid() bool {
+	for _, group := range TimeReportGroups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeReportOptions are the filters and the grouping of a time report
+type TimeReportOptions struct {
+	RepoIDs     []int64
+	UserID      int64
+	MilestoneID int64
+	LabelIDs    []int64 // the issues must have all labels
+	SinceUnix   int64
+	UntilUnix   int64
+	GroupBy     TimeReportGroup
+}
+
+func (opts *TimeReportOptions) toCond() builder.Cond {
+	cond := builder.NewCond().
+		And(builder.Eq{"tracked_time.deleted": false}).
+		And(builder.In("issue.repo_id", opts.RepoIDs))
+	if opts.UserID != 0 {
+		cond = cond.And(builder.Eq{"tracked_time.user_id": opts.UserID})
+	}
+	if opts.MilestoneID != 0 {
+		cond = cond.And(builder.Eq{"issue.milestone_id": opts.MilestoneID})
+	}
+	for _, labelID := range opts.LabelIDs {
+		cond = cond.And(builder.In("issue.id", builder.Select("issue_id").From("issue_label").Where(builder.Eq{"label_id": labelID})))
+	}
+	if opts.SinceUnix != 0 {
+		cond = cond.And(builder.Gte{"tracked_time.created_unix": opts.SinceUnix})
+	}
+	if opts.UntilUnix != 0 {
+		cond = cond.And(builder.Lte{"tracked_time.created_unix": opts.UntilUnix})
+	}
+	return cond
+}
+
+// TimeReportRow is the time tracked in a group of a time report
+type TimeReportRow struct {
+	Key     string
+	User    *user_model.User // set if grouped by user
+	Issue   *Issue           // set if grouped by issue, with the repository loaded
+	Seconds int64
+	Count   int // the number of tracked times
+}
+
+// IsOverEstimate returns true if more time was tracked for the issue of the row than estimated
+func (r *TimeReportRow) IsOverEstimate() bool {
+	return r.Issue != nil && r.Issue.TimeEstimate > 0 && r.Seconds > r.Issue.TimeEstimate
+}
+
+// TimeReport is the time tracked in the repositories grouped by day, week, user or issue
+type TimeReport struct {
+	GroupBy      TimeReportGroup
+	Rows         []*TimeReportRow
+	TotalSeconds int64
+	Count        int
+}
+
+// GetTimeReport returns the time tracked in the repositories matching the options
+func GetTimeReport(ctx context.Context, opts *TimeReportOptions) (*TimeReport, error) {
+	report := &TimeReport{GroupBy: opts.GroupBy}
+	if len(opts.RepoIDs) == 0 {
+		return report, nil
+	}
+
+	trackedTimes := make(TrackedTimeList, 0, 50)
+	if err := db.GetEngine(ctx).
+		Join("INNER", "issue", "issue.id = tracked_time.issue_id").
+		Where(opts.toCond()).
+		Asc("tracked_time.created_unix").
+		Find(&trackedTimes); err != nil {
+		return nil, err
+	}
+
+	keyFunc, err := report.keyFunc(ctx, trackedTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]*TimeReportRow)
+	for _, t := range trackedTimes {
+		row := keyFunc(t)
+		if existing, ok := rows[row.Key]; ok {
+			row = existing
+		} else {
+			rows[row.Key] = row
+			report.Rows = append(report.Rows, row)
+		}
+		row.Seconds += t.Time
+		row.Count++
+		report.TotalSeconds += t.Time
+		report.Count++
+	}
+
+	switch report.GroupBy {
+	case TimeReportGroupDay, TimeReportGroupWeek:
+		sort.SliceStable(report.Rows, func(i, j int) bool {
+			return report.Rows[i].Key < report.Rows[j].Key
+		})
+	default:
+		sort.SliceStable(report.Rows, func(i, j int) bool {
+			if report.Rows[i].Seconds != report.Rows[j].Seconds {
+				return report.Rows[i].Seconds > report.Rows[j].Seconds
+			}
+			return report.Rows[i].Key < report.Rows[j].Key
+		})
+	}
+	return report, nil
+}
+
+// keyFunc returns the function which creates the row of a tracked time, it loads the users or the issues of the tracked times
+func (report *TimeReport) keyFunc(ctx context.Context, trackedTimes TrackedTimeList) (func(*TrackedTime) *TimeReportRow, error) {
+	switch report.GroupBy {
+	case TimeReportGroupWeek:
+		return func(t *TrackedTime) *TimeReportRow {
+			year, week := time.Unix(t.CreatedUnix, 0).In(setting.DefaultUILocation).ISOWeek()
+			return &TimeReportRow{Key: fmt.Sprintf("%04d-W%02d", year, week)}
+		}, nil
+	case TimeReportGroupUser:
+		userIDs := make([]int64, 0, len(trackedTimes))
+		for _, t := range trackedTimes {
+			userIDs = append(userIDs, t.UserID)
+		}
+		users, err := user_model.GetUsersByIDs(userIDs)
+		if err != nil {
+			return nil, err
+		}
+		usersByID := make(map[int64]*user_model.User, len(users))
+		for _, u := range users {
+			usersByID[u.ID] = u
+		}
+		return func(t *TrackedTime) *TimeReportRow {
+			u, ok := usersByID[t.UserID]
+			if !ok {
+				u = user_model.NewGhostUser()
+			}
+			return &TimeReportRow{Key: u.Name, User: u}
+		}, nil
+	case TimeReportGroupIssue:
+		issueIDs := make([]int64, 0, len(trackedTimes))
+		for _, t := range trackedTimes {
+			issueIDs = append(issueIDs, t.IssueID)
+		}
+		issues, err := GetIssuesByIDs(ctx, issueIDs)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := IssueList(issues).loadRepositories(ctx); err != nil {
+			return nil, err
+		}
+		issuesByID := make(map[int64]*Issue, len(issues))
+		for _, issue := range issues {
+			issuesByID[issue.ID] = issue
+		}
+		return func(t *TrackedTime) *TimeReportRow {
+			issue := issuesByID[t.IssueID]
+			return &TimeReportRow{Key: fmt.Sprintf("%s#%d", issue.Repo.FullName(), issue.Index), Issue: issue}
+		}, nil
+	default:
+		return func(t *TrackedTime) *TimeReportRow {
+			return &TimeReportRow{Key: time.Unix(t.CreatedUnix, 0).In(setting.DefaultUILocation).Format("2006-01-02")}
+		}, nil
+	}
+}
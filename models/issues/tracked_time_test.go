@@ -116,3 +116,43 @@ func TestTotalTimes(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, total, 2)
 }
+
+func TestGetTimeReport(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	report, err := issues_model.GetTimeReport(db.DefaultContext, &issues_model.TimeReportOptions{RepoIDs: []int64{1}, GroupBy: issues_model.TimeReportGroupUser})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4083, report.TotalSeconds)
+	assert.Equal(t, 5, report.Count)
+	if assert.Len(t, report.Rows, 2) {
+		assert.Equal(t, "user2", report.Rows[0].Key)
+		assert.EqualValues(t, 3663, report.Rows[0].Seconds)
+		assert.Equal(t, 3, report.Rows[0].Count)
+		assert.EqualValues(t, 1, report.Rows[1].User.ID)
+		assert.EqualValues(t, 420, report.Rows[1].Seconds)
+	}
+
+	report, err = issues_model.GetTimeReport(db.DefaultContext, &issues_model.TimeReportOptions{RepoIDs: []int64{1}, LabelIDs: []int64{1}, GroupBy: issues_model.TimeReportGroupIssue})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4082, report.TotalSeconds)
+	if assert.Len(t, report.Rows, 2) {
+		assert.Equal(t, "user2/repo1#2", report.Rows[0].Key)
+		assert.EqualValues(t, 2, report.Rows[0].Issue.ID)
+		assert.Equal(t, "user2/repo1#1", report.Rows[1].Key)
+	}
+
+	report, err = issues_model.GetTimeReport(db.DefaultContext, &issues_model.TimeReportOptions{RepoIDs: []int64{1}, MilestoneID: 1, UserID: 2, GroupBy: issues_model.TimeReportGroupWeek})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3662, report.TotalSeconds)
+	assert.Len(t, report.Rows, 1)
+
+	report, err = issues_model.GetTimeReport(db.DefaultContext, &issues_model.TimeReportOptions{RepoIDs: []int64{1, 2}, SinceUnix: 946684812, GroupBy: issues_model.TimeReportGroupDay})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 20+3+71, report.TotalSeconds)
+	assert.Len(t, report.Rows, 2)
+	assert.True(t, report.Rows[0].Key < report.Rows[1].Key)
+
+	report, err = issues_model.GetTimeReport(db.DefaultContext, &issues_model.TimeReportOptions{GroupBy: issues_model.TimeReportGroupDay})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Rows)
+}
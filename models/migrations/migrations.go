@@ -406,6 +406,40 @@ var migrations = []Migration{
 	NewMigration("Drop old CredentialID column", dropOldCredentialIDColumn),
 	// v223 -> v224
 	NewMigration("Rename CredentialIDBytes column to CredentialID", renameCredentialIDBytes),
+	// v224 -> v225
+	NewMigration("Add require_code_owner_approval column to protected_branch table", addRequireCodeOwnerApprovalToProtectedBranch),
+	// v225 -> v226
+	NewMigration("Add owner_id column to project table", addOwnerIDToProject),
+	// v226 -> v227
+	NewMigration("Create CI tables", createCITables),
+	// v227 -> v228
+	NewMigration("Create secret table", createSecretTable),
+	// v228 -> v229
+	NewMigration("Add headers column to webhook table", addHeadersToWebhook),
+	// v229 -> v230
+	NewMigration("Add merge queue", addMergeQueue),
+	// v230 -> v231
+	NewMigration("Add package signing key table", addPackageSigningKeyTable),
+	// v231 -> v232
+	NewMigration("Add audit event table", addAuditEventTable),
+	// v232 -> v233
+	NewMigration("Add ref filter and sync status to push mirrors", addPushMirrorRefFilter),
+	// v233 -> v234
+	NewMigration("Add force-push detection to pull mirrors", addMirrorForcePushDetection),
+	// v234 -> v235
+	NewMigration("Add time estimate to issues and time budget to milestones", addTimeEstimateAndBudget),
+	// v235 -> v236
+	NewMigration("Add merge windows to protected branches and scheduled merges", addMergeWindowsAndScheduledMerges),
+	// v236 -> v237
+	NewMigration("Add scopes, restrictions and expiry dates to access tokens", addScopesAndExpiryToAccessTokens),
+	// v237 -> v238
+	NewMigration("Create deploy token table", createDeployTokenTable),
+	// v238 -> v239
+	NewMigration("Create app tables and add app_id to webhooks", createAppTables),
+	// v239 -> v240
+	NewMigration("Create push policy table", createPushPolicyTable),
+	// v240 -> v241
+	NewMigration("Create secret scanning alert table", createSecretScanningAlertTable),
 }
 
 // GetCurrentDBVersion returns the current db version
@@ -0,0 +1,106 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+type ciRunner struct {
+	ID             int64    `xorm:"pk autoincr"`
+	UUID           string   `xorm:"UNIQUE"`
+	Name           string   `xorm:"NOT NULL"`
+	RepoID         int64    `xorm:"INDEX"`
+	Labels         []string `xorm:"TEXT JSON"`
+	TokenHash      string   `xorm:"UNIQUE"`
+	TokenSalt      string
+	TokenLastEight string             `xorm:"INDEX token_last_eight"`
+	LastOnline     timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+func (ciRunner) TableName() string {
+	return "ci_runner"
+}
+
+type ciRunnerToken struct {
+	ID             int64  `xorm:"pk autoincr"`
+	RepoID         int64  `xorm:"UNIQUE"`
+	TokenHash      string `xorm:"UNIQUE"`
+	TokenSalt      string
+	TokenLastEight string             `xorm:"INDEX token_last_eight"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+func (ciRunnerToken) TableName() string {
+	return "ci_runner_token"
+}
+
+type ciRun struct {
+	ID            int64 `xorm:"pk autoincr"`
+	RepoID        int64 `xorm:"INDEX UNIQUE(repo_index)"`
+	Index         int64 `xorm:"INDEX UNIQUE(repo_index)"`
+	WorkflowID    string
+	Title         string
+	TriggerUserID int64
+	Event         string
+	Ref           string
+	CommitSHA     string `xorm:"VARCHAR(64) INDEX"`
+	Status        int    `xorm:"INDEX"`
+	StartedUnix   timeutil.TimeStamp
+	StoppedUnix   timeutil.TimeStamp
+	CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+}
+
+func (ciRun) TableName() string {
+	return "ci_run"
+}
+
+type ciRunIndex struct {
+	GroupID  int64 `xorm:"pk"`
+	MaxIndex int64 `xorm:"index"`
+}
+
+func (ciRunIndex) TableName() string {
+	return "ci_run_index"
+}
+
+type ciJob struct {
+	ID          int64 `xorm:"pk autoincr"`
+	RunID       int64 `xorm:"INDEX"`
+	RepoID      int64 `xorm:"INDEX"`
+	JobID       string
+	Name        string   `xorm:"NOT NULL"`
+	RunsOn      []string `xorm:"TEXT JSON"`
+	Payload     string   `xorm:"LONGTEXT"`
+	Status      int      `xorm:"INDEX"`
+	RunnerID    int64    `xorm:"INDEX"`
+	StartedUnix timeutil.TimeStamp
+	StoppedUnix timeutil.TimeStamp
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func (ciJob) TableName() string {
+	return "ci_job"
+}
+
+type ciJobLog struct {
+	ID          int64              `xorm:"pk autoincr"`
+	JobID       int64              `xorm:"INDEX"`
+	Content     string             `xorm:"LONGTEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func (ciJobLog) TableName() string {
+	return "ci_job_log"
+}
+
+func createCITables(x *xorm.Engine) error {
+	return x.Sync2(new(ciRunner), new(ciRunnerToken), new(ciRun), new(ciRunIndex), new(ciJob), new(ciJobLog))
+}
@@ -0,0 +1,36 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type MergeQueueEntry struct {
+		ID            int64              `xorm:"pk autoincr"`
+		PullID        int64              `xorm:"UNIQUE"`
+		RepoID        int64              `xorm:"INDEX(s)"`
+		BaseBranch    string             `xorm:"INDEX(s)"`
+		DoerID        int64              `xorm:"NOT NULL"`
+		MergeStyle    string             `xorm:"varchar(30)"`
+		Message       string             `xorm:"LONGTEXT"`
+		HeadCommitID  string             `xorm:"VARCHAR(40)"`
+		BaseCommitID  string             `xorm:"VARCHAR(40)"`
+		MergeCommitID string             `xorm:"VARCHAR(40)"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return err
+	}
+	return x.Table("pull_merge_queue").Sync2(new(MergeQueueEntry))
+}
@@ -0,0 +1,25 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPackageSigningKeyTable(x *xorm.Engine) error {
+	type PackageSigningKey struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OwnerID     int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Type        string             `xorm:"UNIQUE(s) NOT NULL"`
+		KeyID       string             `xorm:"CHAR(16) NOT NULL"`
+		PublicKey   string             `xorm:"TEXT NOT NULL"`
+		PrivateKey  string             `xorm:"TEXT NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(PackageSigningKey))
+}
@@ -0,0 +1,36 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+type auditEventV231 struct {
+	ID          int64              `xorm:"pk autoincr"`
+	Action      string             `xorm:"INDEX NOT NULL"`
+	ActorID     int64              `xorm:"INDEX"`
+	ActorName   string             `xorm:"NOT NULL"`
+	ScopeType   string             `xorm:"INDEX(scope) NOT NULL"`
+	ScopeID     int64              `xorm:"INDEX(scope)"`
+	ScopeName   string             `xorm:"NOT NULL"`
+	TargetType  string             `xorm:"NOT NULL"`
+	TargetID    int64              `xorm:"NOT NULL DEFAULT 0"`
+	TargetName  string             `xorm:"NOT NULL"`
+	Before      string             `xorm:"TEXT"`
+	After       string             `xorm:"TEXT"`
+	IPAddress   string             `xorm:"NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func (auditEventV231) TableName() string {
+	return "audit_event"
+}
+
+func addAuditEventTable(x *xorm.Engine) error {
+	return x.Sync2(new(auditEventV231))
+}
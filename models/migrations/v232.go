@@ -0,0 +1,40 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPushMirrorRefFilter(x *xorm.Engine) error {
+	type PushMirror struct {
+		RefFilter        string `xorm:"TEXT"`
+		ProtectForcePush bool   `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type PushMirrorRef struct {
+		ID           int64              `xorm:"pk autoincr"`
+		RepoID       int64              `xorm:"INDEX"`
+		PushMirrorID int64              `xorm:"UNIQUE(s)"`
+		RefName      string             `xorm:"VARCHAR(255) UNIQUE(s)"`
+		CommitID     string             `xorm:"VARCHAR(40)"`
+		Status       int                `xorm:"NOT NULL DEFAULT 0"`
+		Error        string             `xorm:"TEXT"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type PushMirrorSyncError struct {
+		ID           int64              `xorm:"pk autoincr"`
+		RepoID       int64              `xorm:"INDEX"`
+		PushMirrorID int64              `xorm:"INDEX"`
+		RefName      string             `xorm:"VARCHAR(255)"`
+		Error        string             `xorm:"TEXT"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(PushMirror), new(PushMirrorRef), new(PushMirrorSyncError))
+}
@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMirrorForcePushDetection(x *xorm.Engine) error {
+	type Mirror struct {
+		DetectForcePush bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type MirrorBackup struct {
+		ID            int64              `xorm:"pk autoincr"`
+		RepoID        int64              `xorm:"INDEX"`
+		RefName       string             `xorm:"VARCHAR(255)"`
+		BackupRefName string             `xorm:"VARCHAR(255)"`
+		OldCommitID   string             `xorm:"VARCHAR(40)"`
+		NewCommitID   string             `xorm:"VARCHAR(40)"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync2(new(Mirror), new(MirrorBackup))
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMergeWindowsAndScheduledMerges(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		MergeWindows string `xorm:"TEXT"`
+	}
+
+	type AutoMerge struct {
+		ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return err
+	}
+	return x.Table("pull_auto_merge").Sync2(new(AutoMerge))
+}
@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addScopesAndExpiryToAccessTokens(x *xorm.Engine) error {
+	type AccessToken struct {
+		Scope            string
+		RepoID           int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		OrgID            int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		ExpiresUnix      timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		IsExpiryNotified bool               `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(AccessToken)); err != nil {
+		return err
+	}
+
+	// the existing tokens keep their access to everything
+	_, err := x.Exec("UPDATE `access_token` SET `scope` = 'all' WHERE `scope` IS NULL OR `scope` = ''")
+	return err
+}
@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createDeployTokenTable(x *xorm.Engine) error {
+	type DeployToken struct {
+		ID             int64  `xorm:"pk autoincr"`
+		OwnerID        int64  `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+		RepoID         int64  `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+		Name           string `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
+		Mode           int    `xorm:"NOT NULL DEFAULT 1"`
+		TokenHash      string `xorm:"UNIQUE"`
+		TokenSalt      string
+		TokenLastEight string             `xorm:"INDEX token_last_eight"`
+		ExpiresUnix    timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	return x.Sync2(new(DeployToken))
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createAppTables(x *xorm.Engine) error {
+	type App struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OwnerID     int64              `xorm:"INDEX NOT NULL"`
+		Name        string             `xorm:"NOT NULL"`
+		LowerName   string             `xorm:"UNIQUE NOT NULL"`
+		Description string             `xorm:"TEXT"`
+		HomepageURL string             `xorm:"TEXT"`
+		BotID       int64              `xorm:"INDEX NOT NULL"`
+		Permissions string             `xorm:"TEXT"`
+		PublicKey   string             `xorm:"TEXT"`
+		WebhookID   int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type AppInstallation struct {
+		ID              int64              `xorm:"pk autoincr"`
+		AppID           int64              `xorm:"UNIQUE(app_owner) NOT NULL"`
+		OwnerID         int64              `xorm:"UNIQUE(app_owner) INDEX NOT NULL"`
+		AllRepositories bool               `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type AppInstallationRepo struct {
+		ID             int64 `xorm:"pk autoincr"`
+		InstallationID int64 `xorm:"UNIQUE(s) NOT NULL"`
+		RepoID         int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	}
+
+	type Webhook struct {
+		AppID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(App), new(AppInstallation), new(AppInstallationRepo), new(Webhook))
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createPushPolicyTable(x *xorm.Engine) error {
+	type PushPolicy struct {
+		ID                   int64  `xorm:"pk autoincr"`
+		OwnerID              int64  `xorm:"UNIQUE NOT NULL"`
+		DryRun               bool   `xorm:"NOT NULL DEFAULT false"`
+		MaxFileSize          int64  `xorm:"NOT NULL DEFAULT 0"`
+		ForbiddenPaths       string `xorm:"TEXT"`
+		ForbiddenExtensions  string `xorm:"TEXT"`
+		RequireSignedCommits bool   `xorm:"NOT NULL DEFAULT false"`
+		CommitMessagePattern string `xorm:"TEXT"`
+		AllowedEmailDomains  string `xorm:"TEXT"`
+		SecretPatterns       string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync2(new(PushPolicy))
+}
@@ -0,0 +1,37 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+type secretScanningAlert struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"INDEX UNIQUE(repo_fingerprint)"`
+	Fingerprint string `xorm:"VARCHAR(64) UNIQUE(repo_fingerprint)"`
+	RuleID      string
+	Redacted    string
+	CommitID    string `xorm:"VARCHAR(64)"`
+	Path        string `xorm:"TEXT"`
+	Line        int
+	Status      int `xorm:"INDEX"`
+	Revoked     bool
+
+	ResolverID   int64
+	ResolvedUnix timeutil.TimeStamp
+	CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+}
+
+func (secretScanningAlert) TableName() string {
+	return "secret_scanning_alert"
+}
+
+func createSecretScanningAlertTable(x *xorm.Engine) error {
+	return x.Sync2(new(secretScanningAlert))
+}
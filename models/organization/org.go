@@ -448,8 +448,8 @@ func CountOrgs(opts FindOrgOptions) (int64, error) {
 
 // HasOrgOrUserVisible tells if the given user can see the given org or user
 func HasOrgOrUserVisible(ctx context.Context, orgOrUser, user *user_model.User) bool {
-	// Not SignedUser
-	if user == nil {
+	// Not SignedUser, the fake user of a deploy token isn't signed in either
+	if user == nil || user.IsDeployToken() {
 		return orgOrUser.Visibility == structs.VisibleTypePublic
 	}
 
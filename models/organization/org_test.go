@@ -405,9 +405,11 @@ func TestHasOrgVisibleTypeLimited(t *testing.T) {
 	test1 := organization.HasOrgOrUserVisible(db.DefaultContext, org.AsUser(), owner)
 	test2 := organization.HasOrgOrUserVisible(db.DefaultContext, org.AsUser(), user3)
 	test3 := organization.HasOrgOrUserVisible(db.DefaultContext, org.AsUser(), nil)
+	test4 := organization.HasOrgOrUserVisible(db.DefaultContext, org.AsUser(), user_model.NewDeployTokenUser("ci"))
 	assert.True(t, test1)  // owner of org
 	assert.True(t, test2)  // user not a part of org
 	assert.False(t, test3) // logged out user
+	assert.False(t, test4) // deploy token
 }
 
 func TestHasOrgVisibleTypePrivate(t *testing.T) {
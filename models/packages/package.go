@@ -31,10 +31,13 @@ type Type string
 
 // List of supported packages
 const (
+	TypeCargo     Type = "cargo"
 	TypeComposer  Type = "composer"
 	TypeConan     Type = "conan"
 	TypeContainer Type = "container"
+	TypeDebian    Type = "debian"
 	TypeGeneric   Type = "generic"
+	TypeGo        Type = "go"
 	TypeHelm      Type = "helm"
 	TypeMaven     Type = "maven"
 	TypeNpm       Type = "npm"
@@ -47,14 +50,20 @@ const (
 // Name gets the name of the package type
 func (pt Type) Name() string {
 	switch pt {
+	case TypeCargo:
+		return "Cargo"
 	case TypeComposer:
 		return "Composer"
 	case TypeConan:
 		return "Conan"
 	case TypeContainer:
 		return "Container"
+	case TypeDebian:
+		return "Debian"
 	case TypeGeneric:
 		return "Generic"
+	case TypeGo:
+		return "Go"
 	case TypeHelm:
 		return "Helm"
 	case TypeMaven:
@@ -76,14 +85,20 @@ func (pt Type) Name() string {
 // SVGName gets the name of the package type svg image
 func (pt Type) SVGName() string {
 	switch pt {
+	case TypeCargo:
+		return "octicon-package"
 	case TypeComposer:
 		return "gitea-composer"
 	case TypeConan:
 		return "gitea-conan"
 	case TypeContainer:
 		return "octicon-container"
+	case TypeDebian:
+		return "octicon-package"
 	case TypeGeneric:
 		return "octicon-package"
+	case TypeGo:
+		return "octicon-package"
 	case TypeHelm:
 		return "gitea-helm"
 	case TypeMaven:
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 
+	app_model "code.gitea.io/gitea/models/app"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
@@ -59,6 +60,22 @@ func accessLevel(ctx context.Context, user *user_model.User, repo *repo_model.Re
 	return a.Mode, nil
 }
 
+// botAccessMode returns the access of the bot of an app on a repository, none if the app isn't installed on it
+func botAccessMode(ctx context.Context, bot *user_model.User, repo *repo_model.Repository) (perm.AccessMode, error) {
+	a, err := app_model.GetAppByBotID(ctx, bot.ID)
+	if err != nil {
+		if app_model.IsErrAppNotExist(err) {
+			return perm.AccessModeNone, nil
+		}
+		return perm.AccessModeNone, err
+	}
+	installed, err := app_model.IsInstalledOnRepo(ctx, a.ID, repo.OwnerID, repo.ID)
+	if err != nil || !installed {
+		return perm.AccessModeNone, err
+	}
+	return a.AccessMode(), nil
+}
+
 func maxAccessMode(modes ...perm.AccessMode) perm.AccessMode {
 	max := perm.AccessModeNone
 	for _, mode := range modes {
@@ -374,3 +374,31 @@ func TestRepoPermissionPrivateOrgRepo(t *testing.T) {
 		assert.True(t, perm.CanWrite(unit.Type))
 	}
 }
+
+func TestGetOwnerUnitAccessMode(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	user4 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4})
+	org3 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3})
+
+	cases := []struct {
+		owner, doer *user_model.User
+		mode        perm_model.AccessMode
+	}{
+		{org3, admin, perm_model.AccessModeOwner},
+		{org3, user2, perm_model.AccessModeOwner},
+		// member of a team without the projects unit
+		{org3, user4, perm_model.AccessModeRead},
+		{org3, nil, perm_model.AccessModeRead},
+		{user2, user2, perm_model.AccessModeOwner},
+		{user2, user4, perm_model.AccessModeRead},
+		{user2, nil, perm_model.AccessModeRead},
+	}
+	for _, c := range cases {
+		mode, err := access_model.GetOwnerUnitAccessMode(db.DefaultContext, c.owner, c.doer, unit.TypeProjects)
+		assert.NoError(t, err)
+		assert.Equal(t, c.mode, mode)
+	}
+}
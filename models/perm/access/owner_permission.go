@@ -0,0 +1,62 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package access
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/organization"
+	perm_model "code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+// GetOwnerUnitAccessMode returns the access mode doer has on the given unit of an individual or organization,
+// for example the projects owned by the organization instead of one of its repositories.
+func GetOwnerUnitAccessMode(ctx context.Context, owner, doer *user_model.User, unitType unit.Type) (perm_model.AccessMode, error) {
+	if doer != nil && doer.IsAdmin {
+		return perm_model.AccessModeOwner, nil
+	}
+
+	if owner.IsOrganization() {
+		mode := perm_model.AccessModeNone
+		if doer != nil {
+			isOwner, err := organization.IsOrganizationOwner(ctx, owner.ID, doer.ID)
+			if err != nil {
+				return perm_model.AccessModeNone, err
+			}
+			if isOwner {
+				return perm_model.AccessModeOwner, nil
+			}
+
+			teams, err := organization.GetUserOrgTeams(ctx, owner.ID, doer.ID)
+			if err != nil {
+				return perm_model.AccessModeNone, err
+			}
+			for _, t := range teams {
+				if m := t.UnitAccessModeCtx(ctx, unitType); m > mode {
+					mode = m
+				}
+			}
+		}
+		if mode == perm_model.AccessModeNone && organization.HasOrgOrUserVisible(ctx, owner, doer) {
+			mode = perm_model.AccessModeRead
+		}
+		return mode, nil
+	}
+
+	if doer != nil && !doer.IsGhost() {
+		if doer.ID == owner.ID {
+			return perm_model.AccessModeOwner, nil
+		}
+		if owner.Visibility == structs.VisibleTypePublic || owner.Visibility == structs.VisibleTypeLimited {
+			return perm_model.AccessModeRead, nil
+		}
+	} else if owner.Visibility == structs.VisibleTypePublic {
+		return perm_model.AccessModeRead, nil
+	}
+	return perm_model.AccessModeNone, nil
+}
@@ -180,6 +180,21 @@ func GetUserRepoPermission(ctx context.Context, repo *repo_model.Repository, use
 		return
 	}
 
+	// The bot of an app has the access of the app on the repositories it is installed on
+	if user != nil && user.IsBot() {
+		perm.AccessMode, err = botAccessMode(ctx, user, repo)
+		if err != nil {
+			return
+		}
+		if perm.AccessMode > perm_model.AccessModeNone {
+			if err = repo.LoadUnits(ctx); err != nil {
+				return
+			}
+			perm.Units = repo.Units
+			return
+		}
+	}
+
 	// Prevent strangers from checking out public repo of private organization/users
 	// Allow user if they are collaborator of a repo within a private user or a private organization but not a member of the organization itself
 	if !organization.HasOrgOrUserVisible(ctx, repo.Owner, user) && !is {
@@ -21,6 +21,7 @@ func TestMain(m *testing.M) {
 			"project_board.yml",
 			"project_issue.yml",
 			"repository.yml",
+			"user.yml",
 		},
 	})
 }
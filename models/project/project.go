@@ -8,8 +8,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -76,12 +79,15 @@ type Project struct {
 	Title       string `xorm:"INDEX NOT NULL"`
 	Description string `xorm:"TEXT"`
 	RepoID      int64  `xorm:"INDEX"`
+	OwnerID     int64  `xorm:"INDEX"` // set for individual and organization projects
 	CreatorID   int64  `xorm:"NOT NULL"`
 	IsClosed    bool   `xorm:"INDEX"`
 	BoardType   BoardType
 	Type        Type
 
-	RenderedContent string `xorm:"-"`
+	RenderedContent string                 `xorm:"-"`
+	Owner           *user_model.User       `xorm:"-"`
+	Repo            *repo_model.Repository `xorm:"-"`
 
 	CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -92,6 +98,47 @@ func init() {
 	db.RegisterModel(new(Project))
 }
 
+// IsOwnerProject returns true if the project belongs to an individual or an organization instead of a repository
+func (p *Project) IsOwnerProject() bool {
+	return p.Type == TypeIndividual || p.Type == TypeOrganization
+}
+
+// LoadOwner loads the owner of an individual or organization project
+func (p *Project) LoadOwner(ctx context.Context) (err error) {
+	if p.Owner != nil || !p.IsOwnerProject() {
+		return nil
+	}
+	p.Owner, err = user_model.GetUserByIDCtx(ctx, p.OwnerID)
+	return err
+}
+
+// LoadRepo loads the repository of a repository project
+func (p *Project) LoadRepo(ctx context.Context) (err error) {
+	if p.Repo != nil || p.IsOwnerProject() {
+		return nil
+	}
+	p.Repo, err = repo_model.GetRepositoryByIDCtx(ctx, p.RepoID)
+	return err
+}
+
+// relativePath returns the path of the project relative to the application URL, the owner or the repository must be loaded
+func (p *Project) relativePath() string {
+	if p.IsOwnerProject() {
+		return fmt.Sprintf("%s/-/projects/%d", url.PathEscape(p.Owner.Name), p.ID)
+	}
+	return fmt.Sprintf("%s/%s/projects/%d", url.PathEscape(p.Repo.OwnerName), url.PathEscape(p.Repo.Name), p.ID)
+}
+
+// Link returns the project's relative URL.
+func (p *Project) Link() string {
+	return setting.AppSubURL + "/" + p.relativePath()
+}
+
+// HTMLURL returns the project's full URL.
+func (p *Project) HTMLURL() string {
+	return setting.AppURL + p.relativePath()
+}
+
 // GetProjectsConfig retrieves the types of configurations projects could have
 func GetProjectsConfig() []ProjectsConfig {
 	return []ProjectsConfig{
@@ -104,7 +151,7 @@ func GetProjectsConfig() []ProjectsConfig {
 // IsTypeValid checks if a project type is valid
 func IsTypeValid(p Type) bool {
 	switch p {
-	case TypeRepository:
+	case TypeIndividual, TypeRepository, TypeOrganization:
 		return true
 	default:
 		return false
@@ -114,18 +161,21 @@ func IsTypeValid(p Type) bool {
 // SearchOptions are options for GetProjects
 type SearchOptions struct {
 	RepoID   int64
+	OwnerID  int64
 	Page     int
+	PageSize int // defaults to setting.UI.IssuePagingNum
 	IsClosed util.OptionalBool
 	SortType string
 	Type     Type
 }
 
-// GetProjects returns a list of all projects that have been created in the repository
-func GetProjects(ctx context.Context, opts SearchOptions) ([]*Project, int64, error) {
-	e := db.GetEngine(ctx)
-	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
-
-	var cond builder.Cond = builder.Eq{"repo_id": opts.RepoID}
+func (opts *SearchOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	} else {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
 	switch opts.IsClosed {
 	case util.OptionalBoolTrue:
 		cond = cond.And(builder.Eq{"is_closed": true})
@@ -136,7 +186,20 @@ func GetProjects(ctx context.Context, opts SearchOptions) ([]*Project, int64, er
 	if opts.Type > 0 {
 		cond = cond.And(builder.Eq{"type": opts.Type})
 	}
+	return cond
+}
+
+// CountProjects counts the projects matching the options
+func CountProjects(ctx context.Context, opts SearchOptions) (int64, error) {
+	return db.GetEngine(ctx).Where(opts.toConds()).Count(new(Project))
+}
+
+// GetProjects returns a list of all projects that have been created in the repository or by the owner
+func GetProjects(ctx context.Context, opts SearchOptions) ([]*Project, int64, error) {
+	e := db.GetEngine(ctx)
+	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
 
+	cond := opts.toConds()
 	count, err := e.Where(cond).Count(new(Project))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Count: %v", err)
@@ -145,7 +208,11 @@ func GetProjects(ctx context.Context, opts SearchOptions) ([]*Project, int64, er
 	e = e.Where(cond)
 
 	if opts.Page > 0 {
-		e = e.Limit(setting.UI.IssuePagingNum, (opts.Page-1)*setting.UI.IssuePagingNum)
+		pageSize := setting.UI.IssuePagingNum
+		if opts.PageSize > 0 {
+			pageSize = opts.PageSize
+		}
+		e = e.Limit(pageSize, (opts.Page-1)*pageSize)
 	}
 
 	switch opts.SortType {
@@ -171,6 +238,9 @@ func NewProject(p *Project) error {
 	if !IsTypeValid(p.Type) {
 		return errors.New("project type is not valid")
 	}
+	if p.IsOwnerProject() != (p.OwnerID > 0) {
+		return errors.New("only individual and organization projects must have an owner")
+	}
 
 	ctx, committer, err := db.TxContext()
 	if err != nil {
@@ -182,8 +252,10 @@ func NewProject(p *Project) error {
 		return err
 	}
 
-	if _, err := db.Exec(ctx, "UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
-		return err
+	if p.Type == TypeRepository {
+		if _, err := db.Exec(ctx, "UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
+			return err
+		}
 	}
 
 	if err := createBoardsForProjectsType(ctx, p); err != nil {
@@ -217,6 +289,10 @@ func UpdateProject(ctx context.Context, p *Project) error {
 }
 
 func updateRepositoryProjectCount(ctx context.Context, repoID int64) error {
+	if repoID == 0 {
+		return nil
+	}
+
 	if _, err := db.GetEngine(ctx).Exec(builder.Update(
 		builder.Eq{
 			"`num_projects`": builder.Select("count(*)").From("`project`").
@@ -331,6 +407,21 @@ func DeleteProjectByIDCtx(ctx context.Context, id int64) error {
 	return updateRepositoryProjectCount(ctx, p.RepoID)
 }
 
+// DeleteProjectsByOwnerIDCtx deletes all projects of an individual or an organization.
+func DeleteProjectsByOwnerIDCtx(ctx context.Context, ownerID int64) error {
+	projectIDs := make([]int64, 0, 10)
+	if err := db.GetEngine(ctx).Table("project").Where("owner_id = ?", ownerID).Cols("id").Find(&projectIDs); err != nil {
+		return err
+	}
+
+	for _, projectID := range projectIDs {
+		if err := DeleteProjectByIDCtx(ctx, projectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func DeleteProjectByRepoIDCtx(ctx context.Context, repoID int64) error {
 	switch {
 	case setting.Database.UseSQLite3:
@@ -5,11 +5,14 @@
 package project
 
 import (
+	"strconv"
 	"testing"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,9 +24,9 @@ func TestIsProjectTypeValid(t *testing.T) {
 		typ   Type
 		valid bool
 	}{
-		{TypeIndividual, false},
+		{TypeIndividual, true},
 		{TypeRepository, true},
-		{TypeOrganization, false},
+		{TypeOrganization, true},
 		{UnknownType, false},
 	}
 
@@ -46,6 +49,18 @@ func TestGetProjects(t *testing.T) {
 
 	// 1 value for this repo exists in the fixtures
 	assert.Len(t, projects, 1)
+
+	projects, count, err := GetProjects(db.DefaultContext, SearchOptions{OwnerID: 3, Type: TypeOrganization})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, projects, 1) {
+		assert.EqualValues(t, 4, projects[0].ID)
+		assert.True(t, projects[0].IsOwnerProject())
+	}
+
+	count, err = CountProjects(db.DefaultContext, SearchOptions{OwnerID: 2, IsClosed: util.OptionalBoolFalse})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
 }
 
 func TestProject(t *testing.T) {
@@ -82,3 +97,34 @@ func TestProject(t *testing.T) {
 
 	assert.True(t, projectFromDB.IsClosed)
 }
+
+func TestOwnerProject(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// owner projects need an owner
+	assert.Error(t, NewProject(&Project{
+		Type:      TypeOrganization,
+		Title:     "No owner",
+		CreatorID: 2,
+	}))
+
+	project := &Project{
+		Type:      TypeOrganization,
+		BoardType: BoardTypeBugTriage,
+		Title:     "Organization project",
+		OwnerID:   3,
+		CreatorID: 2,
+	}
+	assert.NoError(t, NewProject(project))
+	assert.NoError(t, project.LoadOwner(db.DefaultContext))
+	assert.Equal(t, "/user3/-/projects/"+strconv.FormatInt(project.ID, 10), project.Link())
+
+	boards, err := GetBoards(db.DefaultContext, project.ID)
+	assert.NoError(t, err)
+	assert.Len(t, boards, len(setting.Project.ProjectBoardBugTriageType)+1)
+
+	assert.NoError(t, DeleteProjectsByOwnerIDCtx(db.DefaultContext, 3))
+	unittest.AssertNotExistsBean(t, &Project{OwnerID: 3})
+	unittest.AssertNotExistsBean(t, &Board{ProjectID: project.ID})
+	unittest.AssertExistsAndLoadBean(t, &Project{ID: 5})
+}
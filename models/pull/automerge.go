@@ -14,15 +14,22 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 )
 
-// AutoMerge represents a pull request scheduled for merging when checks succeed
+// AutoMerge represents a pull request scheduled for merging when checks succeed,
+// and not before ScheduledUnix if it isn't zero
 type AutoMerge struct {
-	ID          int64                 `xorm:"pk autoincr"`
-	PullID      int64                 `xorm:"UNIQUE"`
-	DoerID      int64                 `xorm:"NOT NULL"`
-	Doer        *user_model.User      `xorm:"-"`
-	MergeStyle  repo_model.MergeStyle `xorm:"varchar(30)"`
-	Message     string                `xorm:"LONGTEXT"`
-	CreatedUnix timeutil.TimeStamp    `xorm:"created"`
+	ID            int64                 `xorm:"pk autoincr"`
+	PullID        int64                 `xorm:"UNIQUE"`
+	DoerID        int64                 `xorm:"NOT NULL"`
+	Doer          *user_model.User      `xorm:"-"`
+	MergeStyle    repo_model.MergeStyle `xorm:"varchar(30)"`
+	Message       string                `xorm:"LONGTEXT"`
+	ScheduledUnix timeutil.TimeStamp    `xorm:"INDEX NOT NULL DEFAULT 0"`
+	CreatedUnix   timeutil.TimeStamp    `xorm:"created"`
+}
+
+// IsDue returns true if the scheduled time of the merge has come
+func (am *AutoMerge) IsDue() bool {
+	return am.ScheduledUnix <= timeutil.TimeStampNow()
 }
 
 // TableName return database table name for xorm
@@ -49,8 +56,8 @@ func IsErrAlreadyScheduledToAutoMerge(err error) bool {
 	return ok
 }
 
-// ScheduleAutoMerge schedules a pull request to be merged when all checks succeed
-func ScheduleAutoMerge(ctx context.Context, doer *user_model.User, pullID int64, style repo_model.MergeStyle, message string) error {
+// ScheduleAutoMerge schedules a pull request to be merged when all checks succeed, not before scheduledUnix if it isn't zero
+func ScheduleAutoMerge(ctx context.Context, doer *user_model.User, pullID int64, style repo_model.MergeStyle, message string, scheduledUnix timeutil.TimeStamp) error {
 	// Check if we already have a merge scheduled for that pull request
 	if exists, _, err := GetScheduledMergeByPullID(ctx, pullID); err != nil {
 		return err
@@ -59,10 +66,11 @@ func ScheduleAutoMerge(ctx context.Context, doer *user_model.User, pullID int64,
 	}
 
 	_, err := db.GetEngine(ctx).Insert(&AutoMerge{
-		DoerID:     doer.ID,
-		PullID:     pullID,
-		MergeStyle: style,
-		Message:    message,
+		DoerID:        doer.ID,
+		PullID:        pullID,
+		MergeStyle:    style,
+		Message:       message,
+		ScheduledUnix: scheduledUnix,
 	})
 	return err
 }
@@ -96,3 +104,16 @@ func DeleteScheduledAutoMerge(ctx context.Context, pullID int64) error {
 	_, err = db.GetEngine(ctx).ID(scheduledPRM.ID).Delete(&AutoMerge{})
 	return err
 }
+
+// GetPendingScheduledMerges returns the merges which have to be attempted again although no status of their pull requests changed:
+// the merges whose scheduled time has come and the merges into branches with merge windows
+func GetPendingScheduledMerges(ctx context.Context) ([]*AutoMerge, error) {
+	merges := make([]*AutoMerge, 0, 10)
+	return merges, db.GetEngine(ctx).Table("pull_auto_merge").
+		Join("INNER", "pull_request", "pull_request.id = pull_auto_merge.pull_id").
+		Join("LEFT", "protected_branch", "protected_branch.repo_id = pull_request.base_repo_id AND protected_branch.branch_name = pull_request.base_branch").
+		Where("pull_request.has_merged = ?", false).
+		And("(pull_auto_merge.scheduled_unix > 0 AND pull_auto_merge.scheduled_unix <= ?) OR protected_branch.merge_windows <> ''", timeutil.TimeStampNow()).
+		Select("pull_auto_merge.*").
+		Find(&merges)
+}
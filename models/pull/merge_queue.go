@@ -0,0 +1,135 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of its base branch.
+// Every entry is speculatively merged on top of the entry before it (or the base branch for
+// the first entry); MergeCommitID holds the result and BaseCommitID the commit it was built on.
+type MergeQueueEntry struct {
+	ID            int64                 `xorm:"pk autoincr"`
+	PullID        int64                 `xorm:"UNIQUE"`
+	RepoID        int64                 `xorm:"INDEX(s)"`
+	BaseBranch    string                `xorm:"INDEX(s)"`
+	DoerID        int64                 `xorm:"NOT NULL"`
+	Doer          *user_model.User      `xorm:"-"`
+	MergeStyle    repo_model.MergeStyle `xorm:"varchar(30)"`
+	Message       string                `xorm:"LONGTEXT"`
+	HeadCommitID  string                `xorm:"VARCHAR(40)"`
+	BaseCommitID  string                `xorm:"VARCHAR(40)"`
+	MergeCommitID string                `xorm:"VARCHAR(40)"`
+	CreatedUnix   timeutil.TimeStamp    `xorm:"created"`
+}
+
+// TableName return database table name for xorm
+func (MergeQueueEntry) TableName() string {
+	return "pull_merge_queue"
+}
+
+func init() {
+	db.RegisterModel(new(MergeQueueEntry))
+}
+
+// LoadDoer loads the user who added the pull request to the merge queue
+func (e *MergeQueueEntry) LoadDoer(ctx context.Context) (err error) {
+	if e.Doer != nil {
+		return nil
+	}
+	e.Doer, err = user_model.GetUserByIDCtx(ctx, e.DoerID)
+	if user_model.IsErrUserNotExist(err) {
+		e.Doer = user_model.NewGhostUser()
+		err = nil
+	}
+	return err
+}
+
+// ErrAlreadyInMergeQueue represents a "PullRequestAlreadyInMergeQueue"-error
+type ErrAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+func (err ErrAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// IsErrAlreadyInMergeQueue checks if an error is a ErrAlreadyInMergeQueue.
+func IsErrAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrAlreadyInMergeQueue)
+	return ok
+}
+
+// AddToMergeQueue appends a pull request to the end of the merge queue of its base branch
+func AddToMergeQueue(ctx context.Context, entry *MergeQueueEntry) error {
+	if exists, err := db.GetEngine(ctx).Exist(&MergeQueueEntry{PullID: entry.PullID}); err != nil {
+		return err
+	} else if exists {
+		return ErrAlreadyInMergeQueue{PullID: entry.PullID}
+	}
+
+	return db.Insert(ctx, entry)
+}
+
+// GetMergeQueueEntryByPullID returns the merge queue entry of a pull request
+func GetMergeQueueEntryByPullID(ctx context.Context, pullID int64) (*MergeQueueEntry, error) {
+	entry := &MergeQueueEntry{}
+	has, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Get(entry)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, db.ErrNotExist{ID: pullID}
+	}
+	return entry, nil
+}
+
+// GetMergeQueueEntries returns the merge queue of a branch, in the order the pull requests will be merged
+func GetMergeQueueEntries(ctx context.Context, repoID int64, branch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 5)
+	return entries, db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		OrderBy("id").
+		Find(&entries)
+}
+
+// GetMergeQueuePosition returns the 1-based position of an entry in the merge queue of its branch
+func GetMergeQueuePosition(ctx context.Context, entry *MergeQueueEntry) (int64, error) {
+	count, err := db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ? AND id < ?", entry.RepoID, entry.BaseBranch, entry.ID).
+		Count(new(MergeQueueEntry))
+	return count + 1, err
+}
+
+// UpdateMergeQueueEntryCols updates the given columns of a merge queue entry
+func UpdateMergeQueueEntryCols(ctx context.Context, entry *MergeQueueEntry, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(entry.ID).Cols(cols...).Update(entry)
+	return err
+}
+
+// DeleteMergeQueueEntry removes a pull request from the merge queue
+func DeleteMergeQueueEntry(ctx context.Context, pullID int64) error {
+	n, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Delete(new(MergeQueueEntry))
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return db.ErrNotExist{ID: pullID}
+	}
+	return nil
+}
+
+// GetBranchesWithMergeQueue returns the repositories and branches that have pull requests in their merge queue
+func GetBranchesWithMergeQueue(ctx context.Context) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, db.GetEngine(ctx).
+		Select("DISTINCT repo_id, base_branch").
+		Find(&entries)
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueue(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, pullID := range []int64{3, 1, 2} {
+		assert.NoError(t, AddToMergeQueue(db.DefaultContext, &MergeQueueEntry{
+			PullID:     pullID,
+			RepoID:     1,
+			BaseBranch: "master",
+			DoerID:     2,
+			MergeStyle: repo_model.MergeStyleMerge,
+		}))
+	}
+	assert.NoError(t, AddToMergeQueue(db.DefaultContext, &MergeQueueEntry{PullID: 4, RepoID: 1, BaseBranch: "develop", DoerID: 2}))
+
+	err := AddToMergeQueue(db.DefaultContext, &MergeQueueEntry{PullID: 1, RepoID: 1, BaseBranch: "master", DoerID: 2})
+	assert.True(t, IsErrAlreadyInMergeQueue(err))
+
+	// entries are in the order they were added
+	entries, err := GetMergeQueueEntries(db.DefaultContext, 1, "master")
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 3) {
+		assert.EqualValues(t, 3, entries[0].PullID)
+		assert.EqualValues(t, 1, entries[1].PullID)
+		assert.EqualValues(t, 2, entries[2].PullID)
+	}
+
+	entry, err := GetMergeQueueEntryByPullID(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	position, err := GetMergeQueuePosition(db.DefaultContext, entry)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, position)
+	assert.NoError(t, entry.LoadDoer(db.DefaultContext))
+	assert.Equal(t, "user2", entry.Doer.Name)
+
+	branches, err := GetBranchesWithMergeQueue(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Len(t, branches, 2)
+
+	assert.NoError(t, DeleteMergeQueueEntry(db.DefaultContext, 1))
+	assert.True(t, db.IsErrNotExist(DeleteMergeQueueEntry(db.DefaultContext, 1)))
+	_, err = GetMergeQueueEntryByPullID(db.DefaultContext, 1)
+	assert.True(t, db.IsErrNotExist(err))
+
+	position, err = GetMergeQueuePosition(db.DefaultContext, entry)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, position)
+}
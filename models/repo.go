@@ -12,8 +12,13 @@ import (
 
 	_ "image/jpeg" // Needed for jpeg support
 
+	_ "code.gitea.io/gitea/models/audit" // Needed for the audit event fixtures
+
 	admin_model "code.gitea.io/gitea/models/admin"
+	app_model "code.gitea.io/gitea/models/app"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	auth_model "code.gitea.io/gitea/models/auth"
+	ci_model "code.gitea.io/gitea/models/ci"
 	"code.gitea.io/gitea/models/db"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
@@ -22,6 +27,8 @@ import (
 	access_model "code.gitea.io/gitea/models/perm/access"
 	project_model "code.gitea.io/gitea/models/project"
 	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+	secretscan_model "code.gitea.io/gitea/models/secretscan"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
@@ -289,10 +296,13 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		&repo_model.LanguageStat{RepoID: repoID},
 		&issues_model.Milestone{RepoID: repoID},
 		&repo_model.Mirror{RepoID: repoID},
+		&repo_model.MirrorBackup{RepoID: repoID},
 		&Notification{RepoID: repoID},
 		&git_model.ProtectedBranch{RepoID: repoID},
 		&git_model.ProtectedTag{RepoID: repoID},
 		&repo_model.PushMirror{RepoID: repoID},
+		&repo_model.PushMirrorRef{RepoID: repoID},
+		&repo_model.PushMirrorSyncError{RepoID: repoID},
 		&Release{RepoID: repoID},
 		&repo_model.RepoIndexerStatus{RepoID: repoID},
 		&repo_model.Redirect{RedirectRepoID: repoID},
@@ -346,6 +356,26 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		return fmt.Errorf("unable to delete projects for repo[%d]: %v", repoID, err)
 	}
 
+	if err := ci_model.DeleteRunsByRepoID(ctx, repoID); err != nil {
+		return fmt.Errorf("unable to delete CI runs for repo[%d]: %v", repoID, err)
+	}
+
+	if err := secret_model.DeleteSecretsByRepoID(ctx, repoID); err != nil {
+		return fmt.Errorf("unable to delete secrets for repo[%d]: %v", repoID, err)
+	}
+
+	if err := auth_model.DeleteDeployTokensByRepoID(ctx, repoID); err != nil {
+		return fmt.Errorf("unable to delete deploy tokens for repo[%d]: %v", repoID, err)
+	}
+
+	if err := app_model.DeleteInstallationReposByRepoID(ctx, repoID); err != nil {
+		return fmt.Errorf("unable to delete app installations for repo[%d]: %v", repoID, err)
+	}
+
+	if err := secretscan_model.DeleteAlertsByRepoID(ctx, repoID); err != nil {
+		return fmt.Errorf("unable to delete secret scanning alerts for repo[%d]: %v", repoID, err)
+	}
+
 	// Remove LFS objects
 	var lfsObjects []*git_model.LFSMetaObject
 	if err = sess.Where("repository_id=?", repoID).Find(&lfsObjects); err != nil {
@@ -33,6 +33,9 @@ type Mirror struct {
 	LFS         bool   `xorm:"lfs_enabled NOT NULL DEFAULT false"`
 	LFSEndpoint string `xorm:"lfs_endpoint TEXT"`
 
+	// DetectForcePush preserves the old tip of a branch or tag which was force-pushed upstream
+	DetectForcePush bool `xorm:"NOT NULL DEFAULT false"`
+
 	Address string `xorm:"-"`
 }
 
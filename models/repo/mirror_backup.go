@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrMirrorBackupNotExist mirror backup does not exist error
+var ErrMirrorBackupNotExist = errors.New("MirrorBackup does not exist")
+
+// MirrorBackup records a branch or tag which was force-pushed upstream,
+// the old tip of the ref is preserved under the backup ref so the lost history can be recovered.
+type MirrorBackup struct {
+	ID            int64              `xorm:"pk autoincr"`
+	RepoID        int64              `xorm:"INDEX"`
+	RefName       string             `xorm:"VARCHAR(255)"`
+	BackupRefName string             `xorm:"VARCHAR(255)"`
+	OldCommitID   string             `xorm:"VARCHAR(40)"`
+	NewCommitID   string             `xorm:"VARCHAR(40)"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func init() {
+	db.RegisterModel(new(MirrorBackup))
+}
+
+// InsertMirrorBackup inserts a mirror backup
+func InsertMirrorBackup(ctx context.Context, backup *MirrorBackup) error {
+	return db.Insert(ctx, backup)
+}
+
+// GetMirrorBackup returns the mirror backup of the repository
+func GetMirrorBackup(ctx context.Context, repoID, id int64) (*MirrorBackup, error) {
+	backup := &MirrorBackup{}
+	has, err := db.GetEngine(ctx).Where("id = ? AND repo_id = ?", id, repoID).Get(backup)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMirrorBackupNotExist
+	}
+	return backup, nil
+}
+
+// GetMirrorBackups returns the mirror backups of the repository, newest first
+func GetMirrorBackups(ctx context.Context, repoID int64, listOptions db.ListOptions) ([]*MirrorBackup, int64, error) {
+	sess := db.GetEngine(ctx).Where("repo_id = ?", repoID).OrderBy("id DESC")
+	if listOptions.Page != 0 {
+		sess = db.SetSessionPagination(sess, &listOptions)
+	}
+	backups := make([]*MirrorBackup, 0, 10)
+	count, err := sess.FindAndCount(&backups)
+	return backups, count, err
+}
+
+// DeleteMirrorBackup deletes the record of a mirror backup, the backup ref has to be removed by the caller
+func DeleteMirrorBackup(ctx context.Context, backup *MirrorBackup) error {
+	_, err := db.GetEngine(ctx).ID(backup.ID).Delete(&MirrorBackup{})
+	return err
+}
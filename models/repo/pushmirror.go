@@ -7,12 +7,15 @@ package repo
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	"github.com/gobwas/glob"
 	"xorm.io/builder"
 )
 
@@ -26,6 +29,10 @@ type PushMirror struct {
 	Repo       *Repository `xorm:"-"`
 	RemoteName string
 
+	// RefFilter is a semicolon separated list of glob patterns, an empty filter mirrors all refs
+	RefFilter        string `xorm:"TEXT"`
+	ProtectForcePush bool   `xorm:"NOT NULL DEFAULT false"`
+
 	SyncOnCommit   bool `xorm:"NOT NULL DEFAULT true"`
 	Interval       time.Duration
 	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
@@ -74,6 +81,64 @@ func (m *PushMirror) GetRemoteName() string {
 	return m.RemoteName
 }
 
+// IsFiltered returns true if only a subset of the refs is pushed or the refs are pushed without force,
+// such mirrors push the branches and tags explicitly instead of using "git push --mirror"
+func (m *PushMirror) IsFiltered() bool {
+	return strings.TrimSpace(m.RefFilter) != "" || m.ProtectForcePush
+}
+
+// MatchRef returns true if the ref matches the ref filter of the push mirror
+func (m *PushMirror) MatchRef(refName string) bool {
+	patterns, err := ParsePushMirrorRefFilter(m.RefFilter)
+	if err != nil {
+		log.Error("Invalid ref filter of push mirror %d: %v", m.ID, err)
+		return false
+	}
+	return MatchPushMirrorRef(patterns, refName)
+}
+
+// ParsePushMirrorRefFilter parses a semicolon separated list of ref patterns.
+// A pattern starting with "refs/" is matched against the full ref name,
+// other patterns are matched against the names of the branches and the tags, e.g. "main;v*".
+func ParsePushMirrorRefFilter(filter string) ([]glob.Glob, error) {
+	patterns := make([]glob.Glob, 0, 4)
+	for _, expr := range strings.Split(filter, ";") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		if strings.HasPrefix(expr, "refs/") {
+			g, err := glob.Compile(expr, '/')
+			if err != nil {
+				return nil, fmt.Errorf("invalid ref pattern %q: %w", expr, err)
+			}
+			patterns = append(patterns, g)
+			continue
+		}
+		for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+			g, err := glob.Compile(prefix+expr, '/')
+			if err != nil {
+				return nil, fmt.Errorf("invalid ref pattern %q: %w", expr, err)
+			}
+			patterns = append(patterns, g)
+		}
+	}
+	return patterns, nil
+}
+
+// MatchPushMirrorRef returns true if the ref matches one of the patterns, no patterns match all branches and tags
+func MatchPushMirrorRef(patterns []glob.Glob, refName string) bool {
+	if len(patterns) == 0 {
+		return strings.HasPrefix(refName, "refs/heads/") || strings.HasPrefix(refName, "refs/tags/")
+	}
+	for _, g := range patterns {
+		if g.Match(refName) {
+			return true
+		}
+	}
+	return false
+}
+
 // InsertPushMirror inserts a push-mirror to database
 func InsertPushMirror(ctx context.Context, m *PushMirror) error {
 	_, err := db.GetEngine(ctx).Insert(m)
@@ -87,11 +152,27 @@ func UpdatePushMirror(ctx context.Context, m *PushMirror) error {
 }
 
 func DeletePushMirrors(ctx context.Context, opts PushMirrorOptions) error {
-	if opts.RepoID > 0 {
-		_, err := db.GetEngine(ctx).Where(opts.toConds()).Delete(&PushMirror{})
-		return err
+	if opts.RepoID <= 0 {
+		return errors.New("repoID required and must be set")
 	}
-	return errors.New("repoID required and must be set")
+
+	return db.WithTx(func(ctx context.Context) error {
+		ids := make([]int64, 0, 10)
+		if err := db.GetEngine(ctx).Table("push_mirror").Where(opts.toConds()).Cols("id").Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if _, err := db.GetEngine(ctx).In("push_mirror_id", ids).Delete(&PushMirrorRef{}); err != nil {
+			return err
+		}
+		if _, err := db.GetEngine(ctx).In("push_mirror_id", ids).Delete(&PushMirrorSyncError{}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).In("id", ids).Delete(&PushMirror{})
+		return err
+	}, ctx)
 }
 
 func GetPushMirror(ctx context.Context, opts PushMirrorOptions) (*PushMirror, error) {
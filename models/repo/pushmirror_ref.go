@@ -0,0 +1,150 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PushMirrorRefStatus is the result of the last push of a ref
+type PushMirrorRefStatus int
+
+// enumerate all push mirror ref statuses
+const (
+	PushMirrorRefStatusSynced   PushMirrorRefStatus = iota + 1 // the remote ref points to the same commit
+	PushMirrorRefStatusRejected                                // the push was rejected, e.g. it was not a fast-forward
+	PushMirrorRefStatusFailed                                  // the push failed for other reasons
+)
+
+// String returns the name of the status
+func (s PushMirrorRefStatus) String() string {
+	switch s {
+	case PushMirrorRefStatusSynced:
+		return "synced"
+	case PushMirrorRefStatusRejected:
+		return "rejected"
+	case PushMirrorRefStatusFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// PushMirrorRef is the sync status of a ref of a push mirror
+type PushMirrorRef struct {
+	ID           int64               `xorm:"pk autoincr"`
+	RepoID       int64               `xorm:"INDEX"`
+	PushMirrorID int64               `xorm:"UNIQUE(s)"`
+	RefName      string              `xorm:"VARCHAR(255) UNIQUE(s)"`
+	CommitID     string              `xorm:"VARCHAR(40)"`
+	Status       PushMirrorRefStatus `xorm:"NOT NULL DEFAULT 0"`
+	Error        string              `xorm:"TEXT"`
+	UpdatedUnix  timeutil.TimeStamp  `xorm:"updated"`
+}
+
+// PushMirrorSyncError is an error of a push mirror sync, the ref name is empty if the whole sync failed
+type PushMirrorSyncError struct {
+	ID           int64              `xorm:"pk autoincr"`
+	RepoID       int64              `xorm:"INDEX"`
+	PushMirrorID int64              `xorm:"INDEX"`
+	RefName      string             `xorm:"VARCHAR(255)"`
+	Error        string             `xorm:"TEXT"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+}
+
+// PushMirrorSyncErrorsLimit is the number of sync errors kept for every push mirror
+const PushMirrorSyncErrorsLimit = 50
+
+func init() {
+	db.RegisterModel(new(PushMirrorRef))
+	db.RegisterModel(new(PushMirrorSyncError))
+}
+
+// GetPushMirrorRefs returns the sync status of the refs of the push mirror
+func GetPushMirrorRefs(ctx context.Context, mirrorID int64) ([]*PushMirrorRef, error) {
+	refs := make([]*PushMirrorRef, 0, 10)
+	return refs, db.GetEngine(ctx).
+		Where("push_mirror_id = ?", mirrorID).
+		OrderBy("ref_name ASC").
+		Find(&refs)
+}
+
+// UpdatePushMirrorRefs stores the sync status of the pushed refs and removes the status of the deleted refs
+func UpdatePushMirrorRefs(ctx context.Context, m *PushMirror, refs []*PushMirrorRef, deletedRefNames []string) error {
+	return db.WithTx(func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		if len(deletedRefNames) > 0 {
+			if _, err := e.Where("push_mirror_id = ?", m.ID).In("ref_name", deletedRefNames).Delete(&PushMirrorRef{}); err != nil {
+				return err
+			}
+		}
+		for _, ref := range refs {
+			ref.RepoID = m.RepoID
+			ref.PushMirrorID = m.ID
+
+			existing := &PushMirrorRef{}
+			has, err := e.Where("push_mirror_id = ? AND ref_name = ?", m.ID, ref.RefName).Get(existing)
+			if err != nil {
+				return err
+			}
+			if !has {
+				if _, err := e.Insert(ref); err != nil {
+					return err
+				}
+				continue
+			}
+			if ref.CommitID == "" {
+				// a rejected ref still points to the commit of the last successful push
+				ref.CommitID = existing.CommitID
+			}
+			ref.ID = existing.ID
+			if _, err := e.ID(ref.ID).Cols("commit_id", "status", "error").Update(ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, ctx)
+}
+
+// GetPushMirrorSyncErrors returns the latest sync errors of the push mirror
+func GetPushMirrorSyncErrors(ctx context.Context, mirrorID int64, listOptions db.ListOptions) ([]*PushMirrorSyncError, int64, error) {
+	sess := db.GetEngine(ctx).Where("push_mirror_id = ?", mirrorID).OrderBy("id DESC")
+	if listOptions.Page != 0 {
+		sess = db.SetSessionPagination(sess, &listOptions)
+	}
+	syncErrors := make([]*PushMirrorSyncError, 0, 10)
+	count, err := sess.FindAndCount(&syncErrors)
+	return syncErrors, count, err
+}
+
+// InsertPushMirrorSyncErrors records the errors of a sync and removes the errors exceeding PushMirrorSyncErrorsLimit
+func InsertPushMirrorSyncErrors(ctx context.Context, m *PushMirror, syncErrors ...*PushMirrorSyncError) error {
+	if len(syncErrors) == 0 {
+		return nil
+	}
+	return db.WithTx(func(ctx context.Context) error {
+		for _, syncErr := range syncErrors {
+			syncErr.RepoID = m.RepoID
+			syncErr.PushMirrorID = m.ID
+		}
+		if err := db.Insert(ctx, syncErrors); err != nil {
+			return err
+		}
+
+		var oldest PushMirrorSyncError
+		has, err := db.GetEngine(ctx).
+			Where("push_mirror_id = ?", m.ID).
+			OrderBy("id DESC").
+			Limit(1, PushMirrorSyncErrorsLimit).
+			Get(&oldest)
+		if err != nil || !has {
+			return err
+		}
+		_, err = db.GetEngine(ctx).Where("push_mirror_id = ? AND id <= ?", m.ID, oldest.ID).Delete(&PushMirrorSyncError{})
+		return err
+	}, ctx)
+}
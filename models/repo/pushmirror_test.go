@@ -5,6 +5,7 @@
 package repo_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -50,3 +51,72 @@ func TestPushMirrorsIterate(t *testing.T) {
 		return nil
 	})
 }
+
+func TestPushMirrorRefFilter(t *testing.T) {
+	m := &repo_model.PushMirror{RefFilter: "main; refs/tags/v*"}
+	assert.True(t, m.IsFiltered())
+	assert.True(t, m.MatchRef("refs/heads/main"))
+	assert.True(t, m.MatchRef("refs/tags/main"))
+	assert.True(t, m.MatchRef("refs/tags/v1.0"))
+	assert.False(t, m.MatchRef("refs/heads/v1.0"))
+	assert.False(t, m.MatchRef("refs/heads/feature/main"))
+
+	m = &repo_model.PushMirror{RefFilter: "release/**"}
+	assert.True(t, m.MatchRef("refs/heads/release/1.0"))
+	assert.True(t, m.MatchRef("refs/tags/release/1.0/rc1"))
+
+	m = &repo_model.PushMirror{}
+	assert.False(t, m.IsFiltered())
+	assert.True(t, m.MatchRef("refs/heads/main"))
+	assert.True(t, m.MatchRef("refs/tags/v1.0"))
+	assert.False(t, m.MatchRef("refs/pull/1/head"))
+
+	m.ProtectForcePush = true
+	assert.True(t, m.IsFiltered())
+
+	_, err := repo_model.ParsePushMirrorRefFilter("main;[")
+	assert.Error(t, err)
+}
+
+func TestPushMirrorRefs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	m := &repo_model.PushMirror{RepoID: 1, RemoteName: "test-refs"}
+	assert.NoError(t, repo_model.InsertPushMirror(db.DefaultContext, m))
+
+	assert.NoError(t, repo_model.UpdatePushMirrorRefs(db.DefaultContext, m, []*repo_model.PushMirrorRef{
+		{RefName: "refs/heads/main", CommitID: "65f1bf27bc3bf70f64657658635e66094edbcb4d", Status: repo_model.PushMirrorRefStatusSynced},
+		{RefName: "refs/heads/dev", CommitID: "98f1bf27bc3bf70f64657658635e66094edbcb4d", Status: repo_model.PushMirrorRefStatusSynced},
+	}, nil))
+
+	// a rejected ref keeps the commit of the last successful push
+	assert.NoError(t, repo_model.UpdatePushMirrorRefs(db.DefaultContext, m, []*repo_model.PushMirrorRef{
+		{RefName: "refs/heads/main", Status: repo_model.PushMirrorRefStatusRejected, Error: "[rejected] non-fast-forward"},
+	}, []string{"refs/heads/dev"}))
+
+	refs, err := repo_model.GetPushMirrorRefs(db.DefaultContext, m.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, refs, 1) {
+		assert.Equal(t, "refs/heads/main", refs[0].RefName)
+		assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", refs[0].CommitID)
+		assert.Equal(t, repo_model.PushMirrorRefStatusRejected, refs[0].Status)
+		assert.Equal(t, int64(1), refs[0].RepoID)
+	}
+
+	for i := 0; i < repo_model.PushMirrorSyncErrorsLimit+5; i++ {
+		assert.NoError(t, repo_model.InsertPushMirrorSyncErrors(db.DefaultContext, m, &repo_model.PushMirrorSyncError{
+			RefName: "refs/heads/main",
+			Error:   fmt.Sprintf("error %d", i),
+		}))
+	}
+	syncErrors, count, err := repo_model.GetPushMirrorSyncErrors(db.DefaultContext, m.ID, db.ListOptions{Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, repo_model.PushMirrorSyncErrorsLimit, count)
+	if assert.Len(t, syncErrors, 10) {
+		assert.Equal(t, fmt.Sprintf("error %d", repo_model.PushMirrorSyncErrorsLimit+4), syncErrors[0].Error)
+	}
+
+	assert.NoError(t, repo_model.DeletePushMirrors(db.DefaultContext, repo_model.PushMirrorOptions{ID: m.ID, RepoID: m.RepoID}))
+	unittest.AssertNotExistsBean(t, &repo_model.PushMirrorRef{PushMirrorID: m.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.PushMirrorSyncError{PushMirrorID: m.ID})
+}
@@ -21,6 +21,8 @@ const (
 	RepoIndexerTypeCode RepoIndexerType = iota // 0
 	// RepoIndexerTypeStats repository stats indexer
 	RepoIndexerTypeStats // 1
+	// RepoIndexerTypeWiki wiki indexer
+	RepoIndexerTypeWiki // 2
 )
 
 // RepoIndexerStatus status of a repo's entry in the repo indexer
@@ -74,6 +76,10 @@ func GetIndexerStatus(ctx context.Context, repo *Repository, indexerType RepoInd
 		if repo.StatsIndexerStatus != nil {
 			return repo.StatsIndexerStatus, nil
 		}
+	case RepoIndexerTypeWiki:
+		if repo.WikiIndexerStatus != nil {
+			return repo.WikiIndexerStatus, nil
+		}
 	}
 	status := &RepoIndexerStatus{RepoID: repo.ID}
 	if has, err := db.GetEngine(ctx).Where("`indexer_type` = ?", indexerType).Get(status); err != nil {
@@ -87,6 +93,8 @@ func GetIndexerStatus(ctx context.Context, repo *Repository, indexerType RepoInd
 		repo.CodeIndexerStatus = status
 	case RepoIndexerTypeStats:
 		repo.StatsIndexerStatus = status
+	case RepoIndexerTypeWiki:
+		repo.WikiIndexerStatus = status
 	}
 	return status, nil
 }
@@ -113,3 +121,9 @@ func UpdateIndexerStatus(ctx context.Context, repo *Repository, indexerType Repo
 	}
 	return nil
 }
+
+// DeleteIndexerStatusByType deletes the indexer status of all repositories for the indexer type
+func DeleteIndexerStatusByType(ctx context.Context, indexerType RepoIndexerType) error {
+	_, err := db.GetEngine(ctx).Where("`indexer_type` = ?", indexerType).Delete(new(RepoIndexerStatus))
+	return err
+}
@@ -0,0 +1,220 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	secret_module "code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// MaxSecretSize is the maximum size of the value of a secret
+const MaxSecretSize = 64 * 1024
+
+var namePattern = regexp.MustCompile("^[A-Z_][A-Z0-9_]*$")
+
+// ErrSecretNotExist represents a "secret does not exist" error
+type ErrSecretNotExist struct {
+	Name string
+}
+
+// IsErrSecretNotExist checks if an error is a ErrSecretNotExist
+func IsErrSecretNotExist(err error) bool {
+	_, ok := err.(ErrSecretNotExist)
+	return ok
+}
+
+func (err ErrSecretNotExist) Error() string {
+	return fmt.Sprintf("secret does not exist [name: %s]", err.Name)
+}
+
+// ErrInvalidSecret represents an invalid name or value of a secret
+type ErrInvalidSecret struct {
+	Name   string
+	Reason string
+}
+
+// IsErrInvalidSecret checks if an error is a ErrInvalidSecret
+func IsErrInvalidSecret(err error) bool {
+	_, ok := err.(ErrInvalidSecret)
+	return ok
+}
+
+func (err ErrInvalidSecret) Error() string {
+	return fmt.Sprintf("invalid secret [name: %s]: %s", err.Name, err.Reason)
+}
+
+// Secret represents an encrypted value of an organization or of a repository.
+// The value is never shown again once saved.
+type Secret struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"` // the organization, 0 for repository secrets
+	RepoID      int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+	Name        string             `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
+	Data        string             `xorm:"LONGTEXT"` // encrypted with the SECRET_KEY
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(Secret))
+}
+
+// NormalizeName returns the name of a secret as it is stored, names are case insensitive
+func NormalizeName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// ValidateSecret checks the name and the value of a secret
+func ValidateSecret(name, data string) error {
+	switch {
+	case !namePattern.MatchString(name):
+		return ErrInvalidSecret{name, "the name may only contain alphanumeric characters or underscores and must not start with a number"}
+	case strings.HasPrefix(name, "GITEA_"):
+		return ErrInvalidSecret{name, "the name must not start with GITEA_"}
+	case len(name) > 255:
+		return ErrInvalidSecret{name, "the name is too long"}
+	case data == "":
+		return ErrInvalidSecret{name, "the value is empty"}
+	case len(data) > MaxSecretSize:
+		return ErrInvalidSecret{name, "the value is too large"}
+	}
+	return nil
+}
+
+// SetSecret encrypts and saves the value of a secret, replacing the previous value if there is one
+func SetSecret(ctx context.Context, ownerID, repoID int64, name, data string) (*Secret, error) {
+	name = NormalizeName(name)
+	if err := ValidateSecret(name, data); err != nil {
+		return nil, err
+	}
+	encrypted, err := secret_module.EncryptSecret(setting.SecretKey, data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Secret{OwnerID: ownerID, RepoID: repoID, Name: name}
+	return s, db.WithTx(func(ctx context.Context) error {
+		has, err := db.GetEngine(ctx).Get(s)
+		if err != nil {
+			return err
+		}
+		s.Data = encrypted
+		if has {
+			_, err = db.GetEngine(ctx).ID(s.ID).Cols("data").Update(s)
+			return err
+		}
+		return db.Insert(ctx, s)
+	}, ctx)
+}
+
+// FindSecretsOptions represents the options to find the secrets of an organization or of a repository
+type FindSecretsOptions struct {
+	db.ListOptions
+	OwnerID int64
+	RepoID  int64
+}
+
+func (opts *FindSecretsOptions) toConds() builder.Cond {
+	return builder.Eq{"owner_id": opts.OwnerID, "repo_id": opts.RepoID}
+}
+
+// FindSecrets returns the secrets, without their value
+func FindSecrets(ctx context.Context, opts FindSecretsOptions) ([]*Secret, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).Omit("data").OrderBy("name")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	secrets := make([]*Secret, 0, 10)
+	return secrets, sess.Find(&secrets)
+}
+
+// CountSecrets returns the number of secrets
+func CountSecrets(ctx context.Context, opts FindSecretsOptions) (int64, error) {
+	return db.GetEngine(ctx).Where(opts.toConds()).Count(new(Secret))
+}
+
+// DeleteSecret removes a secret
+func DeleteSecret(ctx context.Context, ownerID, repoID int64, name string) error {
+	name = NormalizeName(name)
+	n, err := db.GetEngine(ctx).Delete(&Secret{OwnerID: ownerID, RepoID: repoID, Name: name})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrSecretNotExist{name}
+	}
+	return nil
+}
+
+// DeleteSecretsByRepoID removes all secrets of a repository
+func DeleteSecretsByRepoID(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(new(Secret))
+	return err
+}
+
+// DeleteSecretsByOwnerID removes all secrets of an organization
+func DeleteSecretsByOwnerID(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = 0", ownerID).Delete(new(Secret))
+	return err
+}
+
+// GetDecryptedSecrets returns the values of the secrets available to a repository by their name.
+// The secrets of the repository take precedence over the secrets of its owner.
+func GetDecryptedSecrets(ctx context.Context, ownerID, repoID int64) (map[string]string, error) {
+	cond := builder.NewCond()
+	if ownerID > 0 {
+		cond = cond.Or(builder.Eq{"owner_id": ownerID, "repo_id": 0})
+	}
+	if repoID > 0 {
+		cond = cond.Or(builder.Eq{"owner_id": 0, "repo_id": repoID})
+	}
+	if !cond.IsValid() {
+		return map[string]string{}, nil
+	}
+
+	secrets := make([]*Secret, 0, 10)
+	// the owner secrets come first to be overridden
+	if err := db.GetEngine(ctx).Where(cond).OrderBy("repo_id").Find(&secrets); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(secrets))
+	for _, s := range secrets {
+		v, err := secret_module.DecryptSecret(setting.SecretKey, s.Data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt secret %s: %v", s.Name, err)
+		}
+		values[s.Name] = v
+	}
+	return values, nil
+}
+
+// ExpandSecrets replaces the references ${{ secrets.NAME }} in s by the values of the secrets.
+// Unknown secrets are replaced by an empty string.
+func ExpandSecrets(s string, values map[string]string) string {
+	return referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		return values[NormalizeName(referencePattern.FindStringSubmatch(ref)[1])]
+	})
+}
+
+// MaskSecrets replaces the values of the secrets in s
+func MaskSecrets(s string, values map[string]string) string {
+	for _, v := range values {
+		// masking very short values would hide random parts of the text
+		if len(v) >= 4 {
+			s = strings.ReplaceAll(s, v, "***")
+		}
+	}
+	return s
+}
+
+var referencePattern = regexp.MustCompile(`\$\{\{\s*secrets\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
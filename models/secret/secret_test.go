@@ -0,0 +1,104 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSecret(t *testing.T) {
+	assert.NoError(t, ValidateSecret("TOKEN", "value"))
+	assert.NoError(t, ValidateSecret("_MY_TOKEN_2", "value"))
+	for _, name := range []string{"", "2TOKEN", "MY-TOKEN", "GITEA_TOKEN", "MY TOKEN"} {
+		assert.True(t, IsErrInvalidSecret(ValidateSecret(name, "value")), name)
+	}
+	assert.True(t, IsErrInvalidSecret(ValidateSecret("TOKEN", "")))
+	assert.True(t, IsErrInvalidSecret(ValidateSecret("TOKEN", string(make([]byte, MaxSecretSize+1)))))
+}
+
+func TestSetSecret(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	s, err := SetSecret(db.DefaultContext, 0, 1, "token", "first")
+	assert.NoError(t, err)
+	assert.Equal(t, "TOKEN", s.Name)
+
+	// the value is stored encrypted
+	stored := unittest.AssertExistsAndLoadBean(t, &Secret{RepoID: 1, Name: "TOKEN"})
+	assert.NotContains(t, stored.Data, "first")
+
+	// an existing secret gets a new value
+	_, err = SetSecret(db.DefaultContext, 0, 1, "TOKEN", "second")
+	assert.NoError(t, err)
+	unittest.AssertCount(t, &Secret{RepoID: 1}, 1)
+
+	values, err := GetDecryptedSecrets(db.DefaultContext, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "second"}, values)
+
+	_, err = SetSecret(db.DefaultContext, 0, 1, "GITEA_TOKEN", "value")
+	assert.True(t, IsErrInvalidSecret(err))
+}
+
+func TestFindAndDeleteSecrets(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, name := range []string{"B", "A"} {
+		_, err := SetSecret(db.DefaultContext, 0, 1, name, "value")
+		assert.NoError(t, err)
+	}
+	_, err := SetSecret(db.DefaultContext, 3, 0, "C", "value")
+	assert.NoError(t, err)
+
+	secrets, err := FindSecrets(db.DefaultContext, FindSecretsOptions{RepoID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, secrets, 2) {
+		assert.Equal(t, "A", secrets[0].Name)
+		assert.Empty(t, secrets[0].Data)
+	}
+
+	assert.NoError(t, DeleteSecret(db.DefaultContext, 0, 1, "a"))
+	assert.True(t, IsErrSecretNotExist(DeleteSecret(db.DefaultContext, 0, 1, "a")))
+	// a secret of another owner cannot be deleted
+	assert.True(t, IsErrSecretNotExist(DeleteSecret(db.DefaultContext, 0, 1, "C")))
+
+	count, err := CountSecrets(db.DefaultContext, FindSecretsOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestGetDecryptedSecrets(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, err := SetSecret(db.DefaultContext, 3, 0, "SHARED", "org")
+	assert.NoError(t, err)
+	_, err = SetSecret(db.DefaultContext, 3, 0, "ORG_ONLY", "org")
+	assert.NoError(t, err)
+	_, err = SetSecret(db.DefaultContext, 0, 32, "SHARED", "repo")
+	assert.NoError(t, err)
+	_, err = SetSecret(db.DefaultContext, 0, 1, "OTHER_REPO", "other")
+	assert.NoError(t, err)
+
+	// the secrets of the repository override the secrets of the organization
+	values, err := GetDecryptedSecrets(db.DefaultContext, 3, 32)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"SHARED": "repo", "ORG_ONLY": "org"}, values)
+}
+
+func TestExpandSecrets(t *testing.T) {
+	values := map[string]string{"TOKEN": "abcdef"}
+	assert.Equal(t, "Bearer abcdef", ExpandSecrets("Bearer ${{ secrets.TOKEN }}", values))
+	assert.Equal(t, "abcdef-abcdef", ExpandSecrets("${{secrets.token}}-${{  secrets.TOKEN  }}", values))
+	assert.Equal(t, "x=", ExpandSecrets("x=${{ secrets.UNKNOWN }}", values))
+	assert.Equal(t, "${{ env.TOKEN }}", ExpandSecrets("${{ env.TOKEN }}", values))
+
+	assert.Equal(t, "token: ***", MaskSecrets("token: abcdef", values))
+	assert.Equal(t, "a b", MaskSecrets("a b", map[string]string{"SHORT": "a"}))
+}
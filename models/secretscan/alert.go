@@ -0,0 +1,198 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secretscan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AlertStatus represents the state of an alert
+type AlertStatus int
+
+// enumerate all the statuses of an alert
+const (
+	AlertStatusOpen      AlertStatus = iota + 1 // the secret has to be handled
+	AlertStatusResolved                         // the secret has been revoked or rotated
+	AlertStatusDismissed                        // the secret is a false positive or is used for tests
+)
+
+var alertStatusNames = map[AlertStatus]string{
+	AlertStatusOpen:      "open",
+	AlertStatusResolved:  "resolved",
+	AlertStatusDismissed: "dismissed",
+}
+
+// String returns the name of the status
+func (s AlertStatus) String() string {
+	return alertStatusNames[s]
+}
+
+// LocaleString returns the locale key of the status
+func (s AlertStatus) LocaleString() string {
+	return "repo.settings.secret_scanning.status." + s.String()
+}
+
+// ParseAlertStatus returns the status with the given name, 0 if there is none
+func ParseAlertStatus(name string) AlertStatus {
+	for s, n := range alertStatusNames {
+		if n == name {
+			return s
+		}
+	}
+	return 0
+}
+
+// ErrAlertNotExist represents a "secret scanning alert does not exist" error
+type ErrAlertNotExist struct {
+	ID int64
+}
+
+// IsErrAlertNotExist checks if an error is a ErrAlertNotExist
+func IsErrAlertNotExist(err error) bool {
+	_, ok := err.(ErrAlertNotExist)
+	return ok
+}
+
+func (err ErrAlertNotExist) Error() string {
+	return fmt.Sprintf("secret scanning alert does not exist [id: %d]", err.ID)
+}
+
+// Alert represents a secret found in a file of a repository, the secret itself is never stored
+type Alert struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"INDEX UNIQUE(repo_fingerprint)"`
+	Fingerprint string `xorm:"VARCHAR(64) UNIQUE(repo_fingerprint)"` // identifies the secret in the file
+	RuleID      string
+	Redacted    string // the secret with most of its characters hidden
+	CommitID    string `xorm:"VARCHAR(64)"` // the commit which added the secret
+	Path        string `xorm:"TEXT"`
+	Line        int
+	Status      AlertStatus `xorm:"INDEX"`
+	Revoked     bool        // the secret was a token of this instance and has been revoked
+
+	ResolverID   int64
+	Resolver     *user_model.User `xorm:"-"`
+	ResolvedUnix timeutil.TimeStamp
+	CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the name of this table
+func (Alert) TableName() string {
+	return "secret_scanning_alert"
+}
+
+func init() {
+	db.RegisterModel(new(Alert))
+}
+
+// Fingerprint returns the fingerprint of a secret found by a rule in a file
+func Fingerprint(ruleID, path, secret string) string {
+	sum := sha256.Sum256([]byte(ruleID + "\x00" + path + "\x00" + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadResolver loads the user who resolved or dismissed the alert
+func (a *Alert) LoadResolver(ctx context.Context) error {
+	if a.ResolverID == 0 || a.Resolver != nil {
+		return nil
+	}
+	u, err := user_model.GetUserByIDCtx(ctx, a.ResolverID)
+	if err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return err
+		}
+		u = user_model.NewGhostUser()
+	}
+	a.Resolver = u
+	return nil
+}
+
+// InsertAlert inserts the alert unless the repository already has an alert with the same fingerprint,
+// it returns false if it was already reported
+func InsertAlert(ctx context.Context, a *Alert) (bool, error) {
+	has, err := db.GetEngine(ctx).Exist(&Alert{RepoID: a.RepoID, Fingerprint: a.Fingerprint})
+	if err != nil || has {
+		return false, err
+	}
+	if a.Status == 0 {
+		a.Status = AlertStatusOpen
+	}
+	return true, db.Insert(ctx, a)
+}
+
+// GetAlertByID returns the alert of the repository with the ID
+func GetAlertByID(ctx context.Context, repoID, id int64) (*Alert, error) {
+	a := &Alert{ID: id, RepoID: repoID}
+	has, err := db.GetEngine(ctx).Get(a)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAlertNotExist{id}
+	}
+	return a, nil
+}
+
+// FindAlertsOptions represents the options to find the alerts of a repository
+type FindAlertsOptions struct {
+	db.ListOptions
+	RepoID int64
+	Status AlertStatus
+}
+
+func (opts *FindAlertsOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": opts.RepoID})
+	if opts.Status > 0 {
+		cond = cond.And(builder.Eq{"status": opts.Status})
+	}
+	return cond
+}
+
+// FindAlerts returns the alerts, the newest first, and their total number
+func FindAlerts(ctx context.Context, opts FindAlertsOptions) ([]*Alert, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).OrderBy("id DESC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	alerts := make([]*Alert, 0, 10)
+	count, err := sess.FindAndCount(&alerts)
+	return alerts, count, err
+}
+
+// CountAlerts returns the number of alerts
+func CountAlerts(ctx context.Context, opts FindAlertsOptions) (int64, error) {
+	return db.GetEngine(ctx).Where(opts.toConds()).Count(new(Alert))
+}
+
+// UpdateAlertStatus resolves, dismisses or reopens an alert
+func UpdateAlertStatus(ctx context.Context, a *Alert, status AlertStatus, doer *user_model.User) error {
+	a.Status = status
+	a.Resolver = nil
+	if status == AlertStatusOpen {
+		a.ResolverID = 0
+		a.ResolvedUnix = 0
+	} else {
+		a.ResolverID = doer.ID
+		a.Resolver = doer
+		a.ResolvedUnix = timeutil.TimeStampNow()
+	}
+	_, err := db.GetEngine(ctx).ID(a.ID).Cols("status", "resolver_id", "resolved_unix").Update(a)
+	return err
+}
+
+// DeleteAlertsByRepoID removes all alerts of a repository
+func DeleteAlertsByRepoID(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(new(Alert))
+	return err
+}
@@ -6,12 +6,15 @@
 package models
 
 import (
+	"context"
 	"crypto/subtle"
 	"fmt"
 	"time"
 
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -32,7 +35,16 @@ type AccessToken struct {
 	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
 	TokenSalt      string
 	TokenLastEight string `xorm:"token_last_eight"`
+	Scope          auth.AccessTokenScope
 
+	// the token can only access the repository or the organization and its repositories if set
+	RepoID int64                  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Repo   *repo_model.Repository `xorm:"-"`
+	OrgID  int64                  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Org    *user_model.User       `xorm:"-"`
+
+	ExpiresUnix       timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	IsExpiryNotified  bool               `xorm:"NOT NULL DEFAULT false"`
 	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
 	HasRecentActivity bool               `xorm:"-"`
@@ -45,6 +57,60 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
 }
 
+// IsExpired returns true if the token has an expiry date which has passed
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// IsRestricted returns true if the token is restricted to a repository or an organization
+func (t *AccessToken) IsRestricted() bool {
+	return t.RepoID > 0 || t.OrgID > 0
+}
+
+// ValidateRestriction checks that the scope of a restricted token only contains the categories
+// of a repository, or of an organization for the tokens restricted to an organization
+func (t *AccessToken) ValidateRestriction() error {
+	if !t.IsRestricted() {
+		return nil
+	}
+	if t.Scope == auth.AccessTokenScopeAll {
+		return ErrAccessTokenScopeNotRestrictable{Scope: string(auth.AccessTokenScopeAll)}
+	}
+	for _, category := range auth.AllAccessTokenScopeCategories {
+		level := t.Scope.Level(category)
+		if level == auth.AccessTokenScopeLevelNone {
+			continue
+		}
+		switch category {
+		case auth.AccessTokenScopeCategoryRepository, auth.AccessTokenScopeCategoryIssue:
+		case auth.AccessTokenScopeCategoryPackage, auth.AccessTokenScopeCategoryOrganization:
+			if t.OrgID == 0 {
+				return ErrAccessTokenScopeNotRestrictable{Scope: auth.FormatAccessTokenScope(level, category)}
+			}
+		default:
+			return ErrAccessTokenScopeNotRestrictable{Scope: auth.FormatAccessTokenScope(level, category)}
+		}
+	}
+	return nil
+}
+
+// LoadRestriction loads the repository or the organization the token is restricted to
+func (t *AccessToken) LoadRestriction(ctx context.Context) (err error) {
+	if t.RepoID > 0 && t.Repo == nil {
+		t.Repo, err = repo_model.GetRepositoryByIDCtx(ctx, t.RepoID)
+		if err != nil && !repo_model.IsErrRepoNotExist(err) {
+			return err
+		}
+	}
+	if t.OrgID > 0 && t.Org == nil {
+		t.Org, err = user_model.GetUserByIDCtx(ctx, t.OrgID)
+		if err != nil && !user_model.IsErrUserNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	db.RegisterModel(new(AccessToken), func() error {
 		if setting.SuccessfulTokensCacheSize > 0 {
@@ -89,8 +155,19 @@ func getAccessTokenIDFromCache(token string) int64 {
 	return t
 }
 
-// GetAccessTokenBySHA returns access token by given token value
+// GetAccessTokenBySHA returns access token by given token value, the expired tokens don't exist
 func GetAccessTokenBySHA(token string) (*AccessToken, error) {
+	t, err := getAccessTokenBySHA(token)
+	if err != nil {
+		return nil, err
+	}
+	if t.IsExpired() {
+		return nil, ErrAccessTokenNotExist{token}
+	}
+	return t, nil
+}
+
+func getAccessTokenBySHA(token string) (*AccessToken, error) {
 	if token == "" {
 		return nil, ErrAccessTokenEmpty{}
 	}
@@ -141,6 +218,56 @@ func GetAccessTokenBySHA(token string) (*AccessToken, error) {
 	return nil, ErrAccessTokenNotExist{token}
 }
 
+// FindAccessTokensBySHA returns the access tokens among the given token values, keyed by their value.
+// The candidates are loaded by the last eight characters of the values with as few queries as possible
+// and only they are hashed, the expired tokens are left out.
+func FindAccessTokensBySHA(ctx context.Context, values []string) (map[string]*AccessToken, error) {
+	byLastEight := make(map[string][]string, len(values))
+	for _, value := range values {
+		if len(value) == 40 {
+			lastEight := value[len(value)-8:]
+			byLastEight[lastEight] = append(byLastEight[lastEight], value)
+		}
+	}
+	lastEights := make([]string, 0, len(byLastEight))
+	for lastEight := range byLastEight {
+		lastEights = append(lastEights, lastEight)
+	}
+
+	found := make(map[string]*AccessToken)
+	for len(lastEights) > 0 {
+		limit := db.DefaultMaxInSize
+		if len(lastEights) < limit {
+			limit = len(lastEights)
+		}
+		tokens := make([]*AccessToken, 0, limit)
+		if err := db.GetEngine(ctx).In("token_last_eight", lastEights[:limit]).Find(&tokens); err != nil {
+			return nil, err
+		}
+		for _, t := range tokens {
+			for _, value := range byLastEight[t.TokenLastEight] {
+				if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(auth.HashToken(value, t.TokenSalt))) == 1 && !t.IsExpired() {
+					found[value] = t
+				}
+			}
+		}
+		lastEights = lastEights[limit:]
+	}
+	return found, nil
+}
+
+// GetAccessTokenByID returns the access token of the user with the given ID.
+func GetAccessTokenByID(id, userID int64) (*AccessToken, error) {
+	t := &AccessToken{}
+	has, err := db.GetEngine(db.DefaultContext).ID(id).Where("uid = ?", userID).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAccessTokenNotExist{}
+	}
+	return t, nil
+}
+
 // AccessTokenByNameExists checks if a token name has been used already by a user.
 func AccessTokenByNameExists(token *AccessToken) (bool, error) {
 	return db.GetEngine(db.DefaultContext).Table("access_token").Where("name = ?", token.Name).And("uid = ?", token.UID).Exist()
@@ -189,6 +316,27 @@ func CountAccessTokens(opts ListAccessTokensOptions) (int64, error) {
 	return sess.Count(&AccessToken{})
 }
 
+// GetAccessTokensToNotifyExpiry returns the tokens which expire before the time and whose owners weren't notified yet
+func GetAccessTokensToNotifyExpiry(ctx context.Context, before timeutil.TimeStamp) ([]*AccessToken, error) {
+	tokens := make([]*AccessToken, 0, 10)
+	return tokens, db.GetEngine(ctx).
+		Where("expires_unix > 0 AND expires_unix < ?", before).
+		And("is_expiry_notified = ?", false).
+		Asc("uid", "expires_unix").
+		Find(&tokens)
+}
+
+// SetAccessTokensExpiryNotified marks the owners of the tokens as notified of their expiry
+func SetAccessTokensExpiryNotified(ctx context.Context, ids []int64) error {
+	_, err := db.GetEngine(ctx).In("id", ids).Cols("is_expiry_notified").NoAutoTime().Update(&AccessToken{IsExpiryNotified: true})
+	return err
+}
+
+// DeleteExpiredAccessTokens deletes the tokens whose expiry date has passed
+func DeleteExpiredAccessTokens(ctx context.Context) (int64, error) {
+	return db.GetEngine(ctx).Where("expires_unix > 0 AND expires_unix <= ?", timeutil.TimeStampNow()).Delete(&AccessToken{})
+}
+
 // DeleteAccessTokenByID deletes access token by given ID.
 func DeleteAccessTokenByID(id, userID int64) error {
 	cnt, err := db.GetEngine(db.DefaultContext).ID(id).Delete(&AccessToken{
@@ -201,3 +349,9 @@ func DeleteAccessTokenByID(id, userID int64) error {
 	}
 	return nil
 }
+
+// DeleteAccessTokensByOrgID deletes the access tokens of a user restricted to an organization
+func DeleteAccessTokensByOrgID(ctx context.Context, userID, orgID int64) error {
+	_, err := db.GetEngine(ctx).Where("uid = ? AND org_id = ?", userID, orgID).Delete(&AccessToken{})
+	return err
+}
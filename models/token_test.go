@@ -7,7 +7,10 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -80,6 +83,47 @@ func TestGetAccessTokenBySHA(t *testing.T) {
 	_, err = GetAccessTokenBySHA("")
 	assert.Error(t, err)
 	assert.True(t, IsErrAccessTokenEmpty(err))
+
+	expired := &AccessToken{UID: 1, Name: "expired", Scope: auth.AccessTokenScopeAll, ExpiresUnix: timeutil.TimeStampNow() - 1}
+	assert.NoError(t, NewAccessToken(expired))
+	_, err = GetAccessTokenBySHA(expired.Token)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+}
+
+func TestFindAccessTokensBySHA(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	expired := &AccessToken{UID: 1, Name: "expired", Scope: auth.AccessTokenScopeAll, ExpiresUnix: timeutil.TimeStampNow() - 1}
+	assert.NoError(t, NewAccessToken(expired))
+
+	tokens, err := FindAccessTokensBySHA(db.DefaultContext, []string{
+		"d2c6c1ba3890b309189a8e618c72a162e4efbf36",
+		// same last eight characters, but not a token
+		"00000000000000000000000000000000e4efbf36",
+		"notahash",
+		expired.Token,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.EqualValues(t, 1, tokens["d2c6c1ba3890b309189a8e618c72a162e4efbf36"].ID)
+	}
+}
+
+func TestAccessToken_ValidateRestriction(t *testing.T) {
+	token := &AccessToken{Scope: auth.AccessTokenScopeAll}
+	assert.NoError(t, token.ValidateRestriction())
+
+	token = &AccessToken{Scope: "write:repository,read:issue", RepoID: 1}
+	assert.NoError(t, token.ValidateRestriction())
+	token.Scope = "read:package"
+	assert.True(t, IsErrAccessTokenScopeNotRestrictable(token.ValidateRestriction()))
+	token.Scope = auth.AccessTokenScopeAll
+	assert.True(t, IsErrAccessTokenScopeNotRestrictable(token.ValidateRestriction()))
+
+	token = &AccessToken{Scope: "read:package,write:organization", OrgID: 3}
+	assert.NoError(t, token.ValidateRestriction())
+	token.Scope = "read:user"
+	assert.True(t, IsErrAccessTokenScopeNotRestrictable(token.ValidateRestriction()))
 }
 
 func TestListAccessTokens(t *testing.T) {
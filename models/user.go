@@ -19,6 +19,7 @@ import (
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/organization"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	project_model "code.gitea.io/gitea/models/project"
 	pull_model "code.gitea.io/gitea/models/pull"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -95,6 +96,10 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) (err error)
 		return err
 	}
 
+	if err := project_model.DeleteProjectsByOwnerIDCtx(ctx, u.ID); err != nil {
+		return fmt.Errorf("DeleteProjectsByOwnerIDCtx: %v", err)
+	}
+
 	if purge || (setting.Service.UserDeleteWithCommentsMaxTime != 0 &&
 		u.CreatedUnix.AsTime().Add(setting.Service.UserDeleteWithCommentsMaxTime).After(time.Now())) {
 
@@ -186,6 +191,9 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) (err error)
 	if _, err = db.DeleteByBean(ctx, &asymkey_model.GPGKey{OwnerID: u.ID}); err != nil {
 		return fmt.Errorf("deleteGPGKeys: %v", err)
 	}
+	if err = asymkey_model.DeletePackageSigningKeysByOwnerID(ctx, u.ID); err != nil {
+		return fmt.Errorf("DeletePackageSigningKeysByOwnerID: %v", err)
+	}
 	// ***** END: GPGPublicKey *****
 
 	// Clear assignee.
@@ -46,6 +46,9 @@ const (
 
 	// UserTypeOrganization defines an organization
 	UserTypeOrganization
+
+	// UserTypeBot defines the user an app acts with
+	UserTypeBot
 )
 
 const (
@@ -451,6 +454,11 @@ func (u *User) IsOrganization() bool {
 	return u.Type == UserTypeOrganization
 }
 
+// IsBot returns true if user is the bot of an app.
+func (u *User) IsBot() bool {
+	return u.Type == UserTypeBot
+}
+
 // DisplayName returns full name if it's not empty,
 // returns username otherwise.
 func (u *User) DisplayName() string {
@@ -568,6 +576,26 @@ func NewReplaceUser(name string) *User {
 	}
 }
 
+// DeployTokenUserID is the id of the fake user authenticating the requests made with a deploy token
+const DeployTokenUserID = -3
+
+// NewDeployTokenUser creates and returns the fake user of the requests made with a deploy token,
+// it has no permission on its own, the routes accepting deploy tokens check the access granted by the token
+func NewDeployTokenUser(name string) *User {
+	return &User{
+		ID:               DeployTokenUserID,
+		Name:             name,
+		LowerName:        strings.ToLower(name),
+		IsActive:         true,
+		KeepEmailPrivate: true,
+	}
+}
+
+// IsDeployToken checks if the user is the fake user of a deploy token
+func (u *User) IsDeployToken() bool {
+	return u != nil && u.ID == DeployTokenUserID
+}
+
 // IsGhost check if user is fake user for a deleted account
 func (u *User) IsGhost() bool {
 	if u == nil {
@@ -8,6 +8,8 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"regexp"
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
@@ -180,6 +182,7 @@ type Webhook struct {
 	ID              int64 `xorm:"pk autoincr"`
 	RepoID          int64 `xorm:"INDEX"` // An ID of 0 indicates either a default or system webhook
 	OrgID           int64 `xorm:"INDEX"`
+	AppID           int64 `xorm:"INDEX NOT NULL DEFAULT 0"` // the app receiving the events of the repositories it is installed on
 	IsSystemWebhook bool
 	URL             string `xorm:"url TEXT"`
 	HTTPMethod      string `xorm:"http_method"`
@@ -190,6 +193,7 @@ type Webhook struct {
 	IsActive        bool       `xorm:"INDEX"`
 	Type            HookType   `xorm:"VARCHAR(16) 'type'"`
 	Meta            string     `xorm:"TEXT"` // store hook-specific attributes
+	Headers         string     `xorm:"TEXT"` // additional request headers, one "Name: value" per line, may reference secrets
 	LastStatus      HookStatus // Last delivery status
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
@@ -391,6 +395,58 @@ func (w *Webhook) EventsArray() []string {
 	return events
 }
 
+// HookHeader is an additional header sent with the deliveries of a webhook
+type HookHeader struct {
+	Name  string
+	Value string
+}
+
+// ParseHookHeaders parses headers given as one "Name: value" per line
+func ParseHookHeaders(s string) ([]HookHeader, error) {
+	headers := make([]HookHeader, 0, 2)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || !validHeaderName.MatchString(name) {
+			return nil, fmt.Errorf("invalid header: %q", line)
+		}
+		if isReservedHookHeader(name) {
+			return nil, fmt.Errorf("reserved header: %q", name)
+		}
+		headers = append(headers, HookHeader{Name: name, Value: strings.TrimSpace(value)})
+	}
+	return headers, nil
+}
+
+var validHeaderName = regexp.MustCompile("^[A-Za-z0-9-]+$")
+
+// reservedHookHeaderPrefixes are the prefixes of the headers describing and signing the deliveries,
+// the additional headers can't replace them
+var reservedHookHeaderPrefixes = []string{"Content-Type", "X-Gitea-", "X-Gogs-", "X-Github-", "X-Hub-Signature"}
+
+func isReservedHookHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	for _, prefix := range reservedHookHeaderPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HookHeaders returns the additional headers of the webhook
+func (w *Webhook) HookHeaders() []HookHeader {
+	headers, err := ParseHookHeaders(w.Headers)
+	if err != nil {
+		log.Error("ParseHookHeaders[%d]: %v", w.ID, err)
+	}
+	return headers
+}
+
 // CreateWebhook creates a new web hook.
 func CreateWebhook(ctx context.Context, w *Webhook) error {
 	w.Type = strings.TrimSpace(w.Type)
@@ -449,6 +505,7 @@ type ListWebhookOptions struct {
 	db.ListOptions
 	RepoID   int64
 	OrgID    int64
+	AppID    int64
 	IsActive util.OptionalBool
 }
 
@@ -460,6 +517,9 @@ func (opts *ListWebhookOptions) toCond() builder.Cond {
 	if opts.OrgID != 0 {
 		cond = cond.And(builder.Eq{"webhook.org_id": opts.OrgID})
 	}
+	if opts.AppID != 0 {
+		cond = cond.And(builder.Eq{"webhook.app_id": opts.AppID})
+	}
 	if !opts.IsActive.IsNone() {
 		cond = cond.And(builder.Eq{"webhook.is_active": opts.IsActive.IsTrue()})
 	}
@@ -491,7 +551,7 @@ func CountWebhooksByOpts(opts *ListWebhookOptions) (int64, error) {
 func GetDefaultWebhooks(ctx context.Context) ([]*Webhook, error) {
 	webhooks := make([]*Webhook, 0, 5)
 	return webhooks, db.GetEngine(ctx).
-		Where("repo_id=? AND org_id=? AND is_system_webhook=?", 0, 0, false).
+		Where("repo_id=? AND org_id=? AND app_id=? AND is_system_webhook=?", 0, 0, 0, false).
 		Find(&webhooks)
 }
 
@@ -499,7 +559,7 @@ func GetDefaultWebhooks(ctx context.Context) ([]*Webhook, error) {
 func GetSystemOrDefaultWebhook(id int64) (*Webhook, error) {
 	webhook := &Webhook{ID: id}
 	has, err := db.GetEngine(db.DefaultContext).
-		Where("repo_id=? AND org_id=?", 0, 0).
+		Where("repo_id=? AND org_id=? AND app_id=?", 0, 0, 0).
 		Get(webhook)
 	if err != nil {
 		return nil, err
@@ -514,11 +574,11 @@ func GetSystemWebhooks(ctx context.Context, isActive util.OptionalBool) ([]*Webh
 	webhooks := make([]*Webhook, 0, 5)
 	if isActive.IsNone() {
 		return webhooks, db.GetEngine(ctx).
-			Where("repo_id=? AND org_id=? AND is_system_webhook=?", 0, 0, true).
+			Where("repo_id=? AND org_id=? AND app_id=? AND is_system_webhook=?", 0, 0, 0, true).
 			Find(&webhooks)
 	}
 	return webhooks, db.GetEngine(ctx).
-		Where("repo_id=? AND org_id=? AND is_system_webhook=? AND is_active = ?", 0, 0, true, isActive.IsTrue()).
+		Where("repo_id=? AND org_id=? AND app_id=? AND is_system_webhook=? AND is_active = ?", 0, 0, 0, true, isActive.IsTrue()).
 		Find(&webhooks)
 }
 
@@ -570,6 +630,14 @@ func DeleteWebhookByOrgID(orgID, id int64) error {
 	})
 }
 
+// DeleteWebhookByAppID deletes webhook of app by given ID.
+func DeleteWebhookByAppID(appID, id int64) error {
+	return deleteWebhook(&Webhook{
+		ID:    id,
+		AppID: appID,
+	})
+}
+
 // DeleteDefaultSystemWebhook deletes an admin-configured default or system webhook (where Org and Repo ID both 0)
 func DeleteDefaultSystemWebhook(id int64) error {
 	ctx, committer, err := db.TxContext()
@@ -579,7 +647,7 @@ func DeleteDefaultSystemWebhook(id int64) error {
 	defer committer.Close()
 
 	count, err := db.GetEngine(ctx).
-		Where("repo_id=? AND org_id=?", 0, 0).
+		Where("repo_id=? AND org_id=? AND app_id=?", 0, 0, 0).
 		Delete(&Webhook{ID: id})
 	if err != nil {
 		return err
@@ -29,6 +29,20 @@ func TestIsValidHookContentType(t *testing.T) {
 	assert.False(t, IsValidHookContentType("invalid"))
 }
 
+func TestParseHookHeaders(t *testing.T) {
+	headers, err := ParseHookHeaders("Authorization: Bearer ${{ secrets.TOKEN }}\n\n  X-Custom:value:with:colons  \n")
+	assert.NoError(t, err)
+	assert.Equal(t, []HookHeader{
+		{Name: "Authorization", Value: "Bearer ${{ secrets.TOKEN }}"},
+		{Name: "X-Custom", Value: "value:with:colons"},
+	}, headers)
+
+	for _, s := range []string{"no colon", "Invalid Name: value", ": value", "x-gitea-signature: forged", "X-Hub-Signature-256: forged", "content-type: text/plain"} {
+		_, err = ParseHookHeaders(s)
+		assert.Error(t, err, s)
+	}
+}
+
 func TestWebhook_History(t *testing.T) {
 	assert.NoError(t, unittest.PrepareTestDatabase())
 	webhook := unittest.AssertExistsAndLoadBean(t, &Webhook{ID: 1})
@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+// The structs below are exchanged between Gitea and the runners over the /api/ci endpoints.
+
+// RegisterRunnerOption is sent by a runner to register itself with a registration token
+type RegisterRunnerOption struct {
+	Token  string   `json:"token" binding:"Required"`
+	Name   string   `json:"name" binding:"Required;MaxSize(255)"`
+	Labels []string `json:"labels"`
+}
+
+// RegisteredRunner is returned to a newly registered runner, the token authenticates all its further requests
+type RegisteredRunner struct {
+	ID     int64    `json:"id"`
+	UUID   string   `json:"uuid"`
+	Name   string   `json:"name"`
+	Token  string   `json:"token"`
+	Labels []string `json:"labels"`
+}
+
+// JobPayload holds the part of a job which is read from the workflow
+type JobPayload struct {
+	Env   map[string]string `json:"env"`
+	Steps []*Step           `json:"steps"`
+}
+
+// FetchedJob is a job assigned to a runner
+type FetchedJob struct {
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	Repository string            `json:"repository"`
+	Workflow   string            `json:"workflow"`
+	Event      string            `json:"event"`
+	Ref        string            `json:"ref"`
+	CommitSHA  string            `json:"sha"`
+	Env        map[string]string `json:"env"`
+	Steps      []*Step           `json:"steps"`
+}
+
+// UpdateJobOption is sent by a runner to report the result of a job
+type UpdateJobOption struct {
+	Status string `json:"status" binding:"Required;In(success,failure)"`
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+var detectionQueue queue.Queue
+
+// DetectionRequest asks to look for workflows triggered by an event
+type DetectionRequest struct {
+	RepoID    int64
+	DoerID    int64
+	Event     string
+	Ref       string // the ref the workflow runs on
+	FilterRef string // the ref matched against the triggers, the target branch of pull requests
+	CommitSHA string
+}
+
+// StartWorkflowDetection starts the queue handling the detection requests
+func StartWorkflowDetection(queueHandle func(data ...queue.Data) []queue.Data) {
+	if !setting.CI.Enabled {
+		return
+	}
+	detectionQueue = queue.CreateQueue("ci_detection", queueHandle, new(DetectionRequest))
+
+	go graceful.GetManager().RunWithShutdownFns(detectionQueue.Run)
+}
+
+// AddDetectionToQueue queues the detection of the workflows triggered by an event
+func AddDetectionToQueue(req *DetectionRequest) {
+	if !setting.CI.Enabled || detectionQueue == nil {
+		return
+	}
+	if err := detectionQueue.Push(req); err != nil {
+		log.Error("Unable to push detection request for repo[%d] to the queue: %v", req.RepoID, err)
+	}
+}
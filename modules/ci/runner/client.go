@@ -0,0 +1,146 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/json"
+)
+
+// ErrJobNotRunning is returned when Gitea refuses reports on a job, e.g. because it has been cancelled
+var ErrJobNotRunning = fmt.Errorf("job is not running")
+
+// Config is the state of a registered runner, it is saved by the runner command
+type Config struct {
+	URL    string   `json:"url"`
+	UUID   string   `json:"uuid"`
+	Name   string   `json:"name"`
+	Token  string   `json:"token"`
+	Labels []string `json:"labels"`
+}
+
+// client talks to the runner API of a Gitea instance
+type client struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+func newClient(url, token string) *client {
+	return &client{
+		url:   strings.TrimSuffix(url, "/") + "/api/ci",
+		token: token,
+		http:  http.DefaultClient,
+	}
+}
+
+func (c *client) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		resp.Body.Close()
+		return nil, ErrJobNotRunning
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp, nil
+}
+
+func (c *client) doJSON(ctx context.Context, method, path string, in, out interface{}) (int, error) {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(buf)
+	}
+	resp, err := c.do(ctx, method, path, "application/json", body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return 0, err
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// Register registers a new runner with the registration token of a repository or of the instance
+func Register(ctx context.Context, url, registrationToken, name string, labels []string) (*Config, error) {
+	registered := &ci_module.RegisteredRunner{}
+	if _, err := newClient(url, "").doJSON(ctx, http.MethodPost, "/register", &ci_module.RegisterRunnerOption{
+		Token:  registrationToken,
+		Name:   name,
+		Labels: labels,
+	}, registered); err != nil {
+		return nil, err
+	}
+	return &Config{
+		URL:    url,
+		UUID:   registered.UUID,
+		Name:   registered.Name,
+		Token:  registered.Token,
+		Labels: registered.Labels,
+	}, nil
+}
+
+// fetchJob returns the next job for the runner, nil if there is none
+func (c *client) fetchJob(ctx context.Context) (*ci_module.FetchedJob, error) {
+	job := &ci_module.FetchedJob{}
+	status, err := c.doJSON(ctx, http.MethodPost, "/jobs/fetch", nil, job)
+	if err != nil || status == http.StatusNoContent {
+		return nil, err
+	}
+	return job, nil
+}
+
+// downloadArchive returns the tar.gz archive of the commit of the job
+func (c *client) downloadArchive(ctx context.Context, jobID int64) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/jobs/%d/archive", jobID), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// appendLog sends output of the job
+func (c *client) appendLog(ctx context.Context, jobID int64, content []byte) error {
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/jobs/%d/logs", jobID), "text/plain", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// updateStatus reports the final status of the job
+func (c *client) updateStatus(ctx context.Context, jobID int64, status string) error {
+	_, err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("/jobs/%d/status", jobID), &ci_module.UpdateJobOption{Status: status}, nil)
+	return err
+}
@@ -0,0 +1,287 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package runner implements a runner executing the steps of the jobs as local processes.
+// It has no isolation at all and should only run trusted workflows.
+package runner
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// logFlushInterval is the interval the output of a job is sent to Gitea
+const logFlushInterval = time.Second
+
+// Runner fetches jobs from Gitea and runs their steps with the shell
+type Runner struct {
+	cfg     *Config
+	workDir string
+	client  *client
+}
+
+// New creates a runner working in the given directory
+func New(cfg *Config, workDir string) *Runner {
+	return &Runner{
+		cfg:     cfg,
+		workDir: workDir,
+		client:  newClient(cfg.URL, cfg.Token),
+	}
+}
+
+// Run fetches and runs jobs until the context is done
+func (r *Runner) Run(ctx context.Context, pollInterval time.Duration) error {
+	for {
+		ran, err := r.RunOnce(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error("Runner %s: %v", r.cfg.Name, err)
+		}
+		if ran {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// RunOnce fetches a job and runs it, it returns false if there was no job to run
+func (r *Runner) RunOnce(ctx context.Context) (bool, error) {
+	job, err := r.client.fetchJob(ctx)
+	if err != nil || job == nil {
+		return false, err
+	}
+	log.Info("Runner %s: running job %d (%s) of %s", r.cfg.Name, job.ID, job.Name, job.Repository)
+	return true, r.runJob(ctx, job)
+}
+
+func (r *Runner) runJob(ctx context.Context, job *ci_module.FetchedJob) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logs := newLogWriter(ctx, r.client, job.ID, cancel)
+	defer logs.Close()
+
+	status := "success"
+	if err := r.executeJob(ctx, job, logs); err != nil {
+		fmt.Fprintf(logs, "\n%v\n", err)
+		status = "failure"
+	}
+	if err := logs.Close(); err != nil && err != ErrJobNotRunning {
+		return err
+	}
+	if ctx.Err() != nil {
+		// the job has been cancelled
+		return nil
+	}
+	if err := r.client.updateStatus(ctx, job.ID, status); err != nil && err != ErrJobNotRunning {
+		return err
+	}
+	return nil
+}
+
+func (r *Runner) executeJob(ctx context.Context, job *ci_module.FetchedJob, logs io.Writer) error {
+	dir := filepath.Join(r.workDir, fmt.Sprintf("job-%d", job.ID))
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	archive, err := r.client.downloadArchive(ctx, job.ID)
+	if err != nil {
+		return fmt.Errorf("unable to download the repository: %v", err)
+	}
+	err = extractArchive(archive, dir)
+	archive.Close()
+	if err != nil {
+		return fmt.Errorf("unable to extract the repository: %v", err)
+	}
+
+	env := append(os.Environ(),
+		"CI=true",
+		"GITEA_CI=true",
+		"GITEA_REPOSITORY="+job.Repository,
+		"GITEA_WORKFLOW="+job.Workflow,
+		"GITEA_JOB="+job.Name,
+		"GITEA_EVENT="+job.Event,
+		"GITEA_REF="+job.Ref,
+		"GITEA_SHA="+job.CommitSHA,
+		"GITEA_WORKSPACE="+dir,
+	)
+	env = appendEnv(env, job.Env)
+
+	for _, step := range job.Steps {
+		fmt.Fprintf(logs, "> %s\n", step.Name)
+		cmd := exec.CommandContext(ctx, "sh", "-e", "-c", step.Run)
+		cmd.Dir = dir
+		cmd.Env = appendEnv(env, step.Env)
+		cmd.Stdout = logs
+		cmd.Stderr = logs
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("step %q failed: %v", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// appendEnv adds the variables in a stable order
+func appendEnv(env []string, vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+vars[k])
+	}
+	return env
+}
+
+// extractArchive extracts a tar.gz archive into dir
+func extractArchive(rd io.Reader, dir string) error {
+	gz, err := gzip.NewReader(rd)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if target != dir && !strings.HasPrefix(target, dir+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in archive: %s", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode)&os.ModePerm)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// logWriter buffers the output of a job and sends it periodically
+type logWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	err    error
+	client *client
+	jobID  int64
+	cancel context.CancelFunc
+	done   chan struct{}
+	closed sync.Once
+	wg     sync.WaitGroup
+}
+
+func newLogWriter(ctx context.Context, c *client, jobID int64, cancel context.CancelFunc) *logWriter {
+	w := &logWriter{
+		client: c,
+		jobID:  jobID,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(logFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.flush(ctx)
+			}
+		}
+	}()
+	return w
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+// flush sends the buffered output, the job is cancelled if Gitea does not accept it anymore
+func (w *logWriter) flush(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() == 0 || w.err != nil {
+		return
+	}
+	if err := w.client.appendLog(ctx, w.jobID, w.buf.Bytes()); err != nil {
+		if err == ErrJobNotRunning {
+			w.err = err
+			w.cancel()
+		} else {
+			log.Warn("Unable to send the log of job %d: %v", w.jobID, err)
+		}
+		return
+	}
+	w.buf.Reset()
+}
+
+// Close stops the periodic sending and sends the remaining output
+func (w *logWriter) Close() error {
+	w.closed.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+		// send what is left even if the job context is already done
+		w.flush(context.Background())
+	})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
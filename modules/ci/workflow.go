@@ -0,0 +1,196 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// WorkflowsPath is the directory of a repository holding the workflow files
+const WorkflowsPath = ".gitea/workflows"
+
+// Events which trigger workflows
+const (
+	EventPush        = "push"
+	EventPullRequest = "pull_request"
+)
+
+// Workflow represents a workflow file
+type Workflow struct {
+	Name string
+	On   map[string]*Trigger
+	Env  map[string]string
+	Jobs []*Job
+}
+
+// Trigger restricts the refs an event triggers a workflow for.
+// For pull requests the branches are matched against the target branch.
+type Trigger struct {
+	Branches []string `yaml:"branches"`
+	Tags     []string `yaml:"tags"`
+}
+
+// Job represents a job of a workflow, all steps of a job are executed by the same runner
+type Job struct {
+	ID     string            `yaml:"-"`
+	Name   string            `yaml:"name"`
+	RunsOn stringList        `yaml:"runs-on"`
+	Env    map[string]string `yaml:"env"`
+	Steps  []*Step           `yaml:"steps"`
+}
+
+// Step represents a shell command executed by a job
+type Step struct {
+	Name string            `yaml:"name" json:"name"`
+	Run  string            `yaml:"run" json:"run"`
+	Env  map[string]string `yaml:"env" json:"env,omitempty"`
+}
+
+// stringList accepts a single string as well as a list of strings
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*l = []string{s}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// triggers accepts an event name, a list of event names or a map of events to their triggers
+type triggers map[string]*Trigger
+
+func (t *triggers) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var events stringList
+	if err := unmarshal(&events); err == nil {
+		*t = make(triggers, len(events))
+		for _, event := range events {
+			(*t)[event] = &Trigger{}
+		}
+		return nil
+	}
+	var m map[string]*Trigger
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	for event, trigger := range m {
+		if trigger == nil {
+			m[event] = &Trigger{}
+		}
+	}
+	*t = m
+	return nil
+}
+
+// ParseWorkflow parses the content of a workflow file
+func ParseWorkflow(content []byte) (*Workflow, error) {
+	var raw struct {
+		Name string            `yaml:"name"`
+		On   triggers          `yaml:"on"`
+		Env  map[string]string `yaml:"env"`
+		Jobs yaml.MapSlice     `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw.On) == 0 {
+		return nil, errors.New("workflow has no trigger")
+	}
+	if len(raw.Jobs) == 0 {
+		return nil, errors.New("workflow has no jobs")
+	}
+
+	w := &Workflow{
+		Name: raw.Name,
+		On:   raw.On,
+		Env:  raw.Env,
+		Jobs: make([]*Job, 0, len(raw.Jobs)),
+	}
+	for _, item := range raw.Jobs {
+		id, ok := item.Key.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid job id: %v", item.Key)
+		}
+		// round trip the generic value to decode it into the job
+		buf, err := yaml.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		job := &Job{ID: id}
+		if err := yaml.Unmarshal(buf, job); err != nil {
+			return nil, fmt.Errorf("job %s: %v", id, err)
+		}
+		if job.Name == "" {
+			job.Name = id
+		}
+		if len(job.RunsOn) == 0 {
+			return nil, fmt.Errorf("job %s: runs-on is required", id)
+		}
+		if len(job.Steps) == 0 {
+			return nil, fmt.Errorf("job %s: at least one step is required", id)
+		}
+		for i, step := range job.Steps {
+			if strings.TrimSpace(step.Run) == "" {
+				return nil, fmt.Errorf("job %s: step %d has nothing to run", id, i+1)
+			}
+			if step.Name == "" {
+				step.Name = strings.SplitN(strings.TrimSpace(step.Run), "\n", 2)[0]
+			}
+		}
+		w.Jobs = append(w.Jobs, job)
+	}
+	return w, nil
+}
+
+// Match checks if the event on the given ref triggers the workflow
+func (w *Workflow) Match(event, ref string) bool {
+	trigger, ok := w.On[event]
+	if !ok {
+		return false
+	}
+	if len(trigger.Branches) == 0 && len(trigger.Tags) == 0 {
+		return true
+	}
+	if strings.HasPrefix(ref, git.BranchPrefix) {
+		return matchPatterns(trigger.Branches, strings.TrimPrefix(ref, git.BranchPrefix))
+	}
+	if strings.HasPrefix(ref, git.TagPrefix) {
+		return matchPatterns(trigger.Tags, strings.TrimPrefix(ref, git.TagPrefix))
+	}
+	return false
+}
+
+func matchPatterns(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			continue
+		}
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWorkflowFile checks if the file name of a tree entry looks like a workflow file
+func IsWorkflowFile(name string) bool {
+	ext := path.Ext(name)
+	return ext == ".yml" || ext == ".yaml"
+}
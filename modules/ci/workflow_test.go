@@ -0,0 +1,107 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	w, err := ParseWorkflow([]byte(`name: Tests
+on:
+  push:
+    branches: [main, "release/*"]
+    tags: ["v*"]
+  pull_request:
+env:
+  A: a
+jobs:
+  test:
+    runs-on: linux
+    env:
+      B: b
+    steps:
+      - run: make test
+  lint:
+    name: Lint
+    runs-on: [linux, go]
+    steps:
+      - name: Lint
+        run: |
+          make lint
+          make vet
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Tests", w.Name)
+	assert.Equal(t, map[string]string{"A": "a"}, w.Env)
+	assert.Len(t, w.On, 2)
+	assert.Equal(t, []string{"main", "release/*"}, w.On[EventPush].Branches)
+	assert.Equal(t, []string{"v*"}, w.On[EventPush].Tags)
+	assert.NotNil(t, w.On[EventPullRequest])
+
+	// jobs keep the order of the file
+	assert.Len(t, w.Jobs, 2)
+	assert.Equal(t, "test", w.Jobs[0].ID)
+	assert.Equal(t, "test", w.Jobs[0].Name)
+	assert.EqualValues(t, []string{"linux"}, w.Jobs[0].RunsOn)
+	assert.Equal(t, map[string]string{"B": "b"}, w.Jobs[0].Env)
+	assert.Equal(t, "make test", w.Jobs[0].Steps[0].Name)
+	assert.Equal(t, "lint", w.Jobs[1].ID)
+	assert.Equal(t, "Lint", w.Jobs[1].Name)
+	assert.EqualValues(t, []string{"linux", "go"}, w.Jobs[1].RunsOn)
+	assert.Equal(t, "make lint\nmake vet\n", w.Jobs[1].Steps[0].Run)
+
+	w, err = ParseWorkflow([]byte(`on: [push, pull_request]
+jobs:
+  build:
+    runs-on: linux
+    steps:
+      - run: make
+`))
+	assert.NoError(t, err)
+	assert.Len(t, w.On, 2)
+	assert.NotNil(t, w.On[EventPush])
+
+	for _, content := range []string{
+		"jobs: {}",
+		"on: push",
+		"on: push\njobs:\n  build:\n    steps:\n      - run: make\n",
+		"on: push\njobs:\n  build:\n    runs-on: linux\n",
+		"on: push\njobs:\n  build:\n    runs-on: linux\n    steps:\n      - name: nothing\n",
+	} {
+		_, err := ParseWorkflow([]byte(content))
+		assert.Error(t, err, content)
+	}
+}
+
+func TestWorkflowMatch(t *testing.T) {
+	w := &Workflow{
+		On: map[string]*Trigger{
+			EventPush: {
+				Branches: []string{"main", "release/*"},
+				Tags:     []string{"v*"},
+			},
+			EventPullRequest: {},
+		},
+	}
+
+	assert.True(t, w.Match(EventPush, "refs/heads/main"))
+	assert.True(t, w.Match(EventPush, "refs/heads/release/1.18"))
+	assert.False(t, w.Match(EventPush, "refs/heads/release/1.18/fix"))
+	assert.False(t, w.Match(EventPush, "refs/heads/feature"))
+	assert.True(t, w.Match(EventPush, "refs/tags/v1.0.0"))
+	assert.False(t, w.Match(EventPush, "refs/tags/1.0.0"))
+	assert.True(t, w.Match(EventPullRequest, "refs/heads/feature"))
+	assert.False(t, w.Match("release", "refs/heads/main"))
+}
+
+func TestIsWorkflowFile(t *testing.T) {
+	assert.True(t, IsWorkflowFile("test.yml"))
+	assert.True(t, IsWorkflowFile("test.yaml"))
+	assert.False(t, IsWorkflowFile("README.md"))
+	assert.False(t, IsWorkflowFile("yml"))
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Paths are the locations a CODEOWNERS file is looked up in, in order of preference
+var Paths = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
+
+// Rule represents a single line of a CODEOWNERS file
+type Rule struct {
+	Pattern string
+	Owners  []string
+
+	matcher *regexp.Regexp
+}
+
+// Match returns true if the given path is matched by the rule
+func (r *Rule) Match(path string) bool {
+	return r.matcher.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// File represents a parsed CODEOWNERS file
+type File struct {
+	Rules []*Rule
+}
+
+// Parse parses the content of a CODEOWNERS file. Invalid lines are skipped and reported as warnings.
+func Parse(content []byte) (*File, []string) {
+	f := &File{}
+	var warnings []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		fields := strings.Fields(line)
+		matcher, err := compilePattern(fields[0])
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: %v", lineNum, err))
+			continue
+		}
+
+		rule := &Rule{
+			Pattern: fields[0],
+			matcher: matcher,
+		}
+		for _, owner := range fields[1:] {
+			if !isValidOwner(owner) {
+				warnings = append(warnings, fmt.Sprintf("line %d: invalid owner %q", lineNum, owner))
+				continue
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+		f.Rules = append(f.Rules, rule)
+	}
+
+	return f, warnings
+}
+
+// OwnersOf returns the rule matching the given path. As in git ignore files the last matching rule wins.
+// Nil is returned if no rule matches.
+func (f *File) OwnersOf(path string) *Rule {
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].Match(path) {
+			return f.Rules[i]
+		}
+	}
+	return nil
+}
+
+// RulesFor returns the rules which own at least one of the given paths.
+// Rules without owners are not returned.
+func (f *File) RulesFor(paths []string) []*Rule {
+	seen := make(map[*Rule]bool)
+	rules := make([]*Rule, 0, len(f.Rules))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		rule := f.OwnersOf(path)
+		if rule == nil || len(rule.Owners) == 0 || seen[rule] {
+			continue
+		}
+		seen[rule] = true
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// IsTeam returns true if the owner is an organization team reference like @org/team
+func IsTeam(owner string) bool {
+	return strings.HasPrefix(owner, "@") && strings.Contains(owner, "/")
+}
+
+// IsEmail returns true if the owner is an email address
+func IsEmail(owner string) bool {
+	return !strings.HasPrefix(owner, "@") && strings.Contains(owner, "@")
+}
+
+func isValidOwner(owner string) bool {
+	if strings.HasPrefix(owner, "@") {
+		return len(owner) > 1 && strings.Count(owner, "/") <= 1 && !strings.HasSuffix(owner, "/")
+	}
+	return IsEmail(owner)
+}
+
+// compilePattern converts a gitignore style pattern into a regular expression
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") {
+		return nil, fmt.Errorf("negated pattern %q is not supported", pattern)
+	}
+
+	isDir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// a pattern containing a slash (other than a trailing one) is relative to the repository root
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if isDir {
+		// directories own everything below them
+		sb.WriteString("/.*$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(sb.String())
+}
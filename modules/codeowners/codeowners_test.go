@@ -0,0 +1,81 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	content := `# comment line
+*       @global-owner
+
+*.js    @js-owner @org/js-team # trailing comment
+/docs/  docs@example.com
+apps/   @apps-owner
+**/logs @log-owner
+/build/generated
+!negated @nobody
+/src/   @invalid/team/name @src-owner
+`
+	f, warnings := Parse([]byte(content))
+	assert.Len(t, warnings, 2)
+	assert.Len(t, f.Rules, 7)
+
+	assert.Equal(t, "*.js", f.Rules[1].Pattern)
+	assert.Equal(t, []string{"@js-owner", "@org/js-team"}, f.Rules[1].Owners)
+	assert.Equal(t, []string{"@src-owner"}, f.Rules[6].Owners)
+}
+
+func TestOwnersOf(t *testing.T) {
+	f, _ := Parse([]byte(`*       @global-owner
+*.js    @js-owner
+/docs/  @docs-owner
+apps/   @apps-owner
+**/logs @log-owner
+/build/generated
+docs/*.md @md-owner
+`))
+
+	cases := []struct {
+		path    string
+		pattern string
+	}{
+		{"README.md", "*"},
+		{"main.js", "*.js"},
+		{"web/src/index.js", "*.js"},
+		{"docs/index.js", "/docs/"},
+		{"docs/deep/file.txt", "/docs/"},
+		{"docs/README.md", "docs/*.md"},
+		{"docs/deep/README.md", "/docs/"},
+		{"sub/docs/file.txt", "*"},
+		{"apps/a.go", "apps/"},
+		{"nested/apps/a.go", "apps/"},
+		{"logs", "**/logs"},
+		{"deep/logs/today.log", "**/logs"},
+		{"build/generated/file.go", "/build/generated"},
+	}
+	for _, c := range cases {
+		rule := f.OwnersOf(c.path)
+		if assert.NotNil(t, rule, c.path) {
+			assert.Equal(t, c.pattern, rule.Pattern, c.path)
+		}
+	}
+
+	rules := f.RulesFor([]string{"README.md", "main.js", "build/generated/a", "other.js", ""})
+	assert.Len(t, rules, 2)
+
+	empty, _ := Parse([]byte("/docs/ @docs-owner"))
+	assert.Nil(t, empty.OwnersOf("README.md"))
+}
+
+func TestOwnerKinds(t *testing.T) {
+	assert.True(t, IsTeam("@org/team"))
+	assert.False(t, IsTeam("@user"))
+	assert.True(t, IsEmail("user@example.com"))
+	assert.False(t, IsEmail("@user"))
+}
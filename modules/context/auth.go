@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -193,3 +194,43 @@ func ToggleAPI(options *ToggleOptions) func(ctx *APIContext) {
 		}
 	}
 }
+
+// AccessToken returns the personal access token the request was authenticated with, nil if there is none
+func (ctx *Context) AccessToken() *models.AccessToken {
+	t, _ := ctx.Data["ApiToken"].(*models.AccessToken)
+	return t
+}
+
+// DeployToken returns the deploy token the request was authenticated with, nil if there is none
+func (ctx *Context) DeployToken() *auth.DeployToken {
+	t, _ := ctx.Data["DeployToken"].(*auth.DeployToken)
+	return t
+}
+
+// TokenScopeAllows returns true if the request wasn't authenticated with a personal access token,
+// or if the scope of the token grants the level of access to the category
+func (ctx *Context) TokenScopeAllows(category auth.AccessTokenScopeCategory, level auth.AccessTokenScopeLevel) bool {
+	t := ctx.AccessToken()
+	return t == nil || t.Scope.HasLevel(category, level)
+}
+
+// IsTokenRestricted returns true if the request was authenticated with a personal access token
+// restricted to a repository or an organization
+func (ctx *Context) IsTokenRestricted() bool {
+	t := ctx.AccessToken()
+	return t != nil && t.IsRestricted()
+}
+
+// TokenAllowsRepo returns false if the request was authenticated with a personal access token
+// restricted to another repository or organization
+func (ctx *Context) TokenAllowsRepo(repoID, ownerID int64) bool {
+	t := ctx.AccessToken()
+	return t == nil || ((t.RepoID == 0 || t.RepoID == repoID) && (t.OrgID == 0 || t.OrgID == ownerID))
+}
+
+// TokenAllowsOwner returns false if the request was authenticated with a personal access token
+// restricted to a repository or to another organization
+func (ctx *Context) TokenAllowsOwner(ownerID int64) bool {
+	t := ctx.AccessToken()
+	return t == nil || (t.RepoID == 0 && (t.OrgID == 0 || t.OrgID == ownerID))
+}
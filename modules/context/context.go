@@ -788,6 +788,8 @@ func Contexter() func(next http.Handler) http.Handler {
 			ctx.Data["EnableOpenIDSignIn"] = setting.Service.EnableOpenIDSignIn
 			ctx.Data["DisableMigrations"] = setting.Repository.DisableMigrations
 			ctx.Data["DisableStars"] = setting.Repository.DisableStars
+			ctx.Data["EnableCI"] = setting.CI.Enabled
+			ctx.Data["EnableSecretScanning"] = setting.SecretScanning.Enabled
 
 			ctx.Data["ManifestData"] = setting.ManifestData
 
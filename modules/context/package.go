@@ -56,7 +56,7 @@ func packageAssignment(ctx *Context, errCb func(int, string, interface{})) {
 		org := organization.OrgFromUser(ctx.Package.Owner)
 
 		// 1. Get user max authorize level for the org (may be none, if user is not member of the org)
-		if ctx.Doer != nil {
+		if ctx.Doer != nil && !ctx.Doer.IsDeployToken() {
 			var err error
 			ctx.Package.AccessMode, err = org.GetOrgUserMaxAuthorizeLevel(ctx.Doer.ID)
 			if err != nil {
@@ -83,7 +83,7 @@ func packageAssignment(ctx *Context, errCb func(int, string, interface{})) {
 			ctx.Package.AccessMode = perm.AccessModeRead
 		}
 	} else {
-		if ctx.Doer != nil && !ctx.Doer.IsGhost() {
+		if ctx.Doer != nil && !ctx.Doer.IsGhost() && !ctx.Doer.IsDeployToken() {
 			// 1. Check if user is package owner
 			if ctx.Doer.ID == ctx.Package.Owner.ID {
 				ctx.Package.AccessMode = perm.AccessModeOwner
@@ -95,6 +95,13 @@ func packageAssignment(ctx *Context, errCb func(int, string, interface{})) {
 		}
 	}
 
+	// the deploy tokens of an organization grant their access mode to its packages,
+	// otherwise they only have the access of the anonymous users
+	if deployToken := ctx.DeployToken(); deployToken != nil && deployToken.CanAccessPackages(ctx.Package.Owner.ID) &&
+		ctx.Package.AccessMode < deployToken.Mode {
+		ctx.Package.AccessMode = deployToken.Mode
+	}
+
 	packageType := ctx.Params("type")
 	name := ctx.Params("name")
 	version := ctx.Params("version")
@@ -9,13 +9,13 @@ import (
 	"context"
 	"fmt"
 	"html"
-	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
@@ -26,8 +26,8 @@ import (
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	issue_template "code.gitea.io/gitea/modules/issue/template"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/markup/markdown"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
@@ -361,6 +361,14 @@ func repoAssignment(ctx *Context, repo *repo_model.Repository) {
 		return
 	}
 
+	// The personal access tokens are accepted by the raw file and the release download routes,
+	// they need the repository scope and mustn't be restricted to another repository or organization
+	if ctx.Data["IsApiToken"] == true &&
+		(!ctx.TokenScopeAllows(auth_model.AccessTokenScopeCategoryRepository, auth_model.AccessTokenScopeLevelRead) || !ctx.TokenAllowsRepo(repo.ID, repo.OwnerID)) {
+		ctx.Error(http.StatusForbidden, "the access token doesn't grant access to the repository")
+		return
+	}
+
 	ctx.Repo.Permission, err = access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
 	if err != nil {
 		ctx.ServerError("GetUserRepoPermission", err)
@@ -1033,69 +1041,53 @@ func UnitTypes() func(ctx *Context) {
 }
 
 // IssueTemplatesFromDefaultBranch checks for issue templates in the repo's default branch
-func (ctx *Context) IssueTemplatesFromDefaultBranch() []api.IssueTemplate {
-	var issueTemplates []api.IssueTemplate
+func (ctx *Context) IssueTemplatesFromDefaultBranch() []*api.IssueTemplate {
+	issueTemplates, _ := ctx.IssueTemplatesAndErrorsFromDefaultBranch()
+	return issueTemplates
+}
 
-	if ctx.Repo.Repository.IsEmpty {
-		return issueTemplates
+// IssueTemplatesAndErrorsFromDefaultBranch checks for issue templates in the repo's default branch,
+// it also returns the errors of the templates which can't be parsed by file path
+func (ctx *Context) IssueTemplatesAndErrorsFromDefaultBranch() ([]*api.IssueTemplate, map[string]error) {
+	commit, err := ctx.defaultBranchCommit()
+	if err != nil || commit == nil {
+		return nil, nil
 	}
 
+	issueTemplates, invalid, err := issue_template.ListFromCommit(commit, IssueTemplateDirCandidates)
+	if err != nil {
+		log.Debug("ListFromCommit: %v", err)
+		return nil, nil
+	}
+	for filename, err := range invalid {
+		log.Debug("Invalid issue template %s in %s: %v", filename, ctx.Repo.Repository.FullName(), err)
+	}
+	return issueTemplates, invalid
+}
+
+// IssueConfigFromDefaultBranch returns the config of the issue templates in the repo's default branch
+func (ctx *Context) IssueConfigFromDefaultBranch() (*api.IssueConfig, error) {
+	commit, err := ctx.defaultBranchCommit()
+	if err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return &api.IssueConfig{BlankIssuesEnabled: true}, nil
+	}
+	return issue_template.ConfigFromCommit(commit, IssueTemplateDirCandidates)
+}
+
+// defaultBranchCommit returns the commit of the default branch, nil if the repository is empty
+func (ctx *Context) defaultBranchCommit() (*git.Commit, error) {
+	if ctx.Repo.Repository.IsEmpty {
+		return nil, nil
+	}
 	if ctx.Repo.Commit == nil {
 		var err error
 		ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
 		if err != nil {
-			return issueTemplates
-		}
-	}
-
-	for _, dirName := range IssueTemplateDirCandidates {
-		tree, err := ctx.Repo.Commit.SubTree(dirName)
-		if err != nil {
-			continue
-		}
-		entries, err := tree.ListEntries()
-		if err != nil {
-			return issueTemplates
-		}
-		for _, entry := range entries {
-			if strings.HasSuffix(entry.Name(), ".md") {
-				if entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
-					log.Debug("Issue template is too large: %s", entry.Name())
-					continue
-				}
-				r, err := entry.Blob().DataAsync()
-				if err != nil {
-					log.Debug("DataAsync: %v", err)
-					continue
-				}
-				closed := false
-				defer func() {
-					if !closed {
-						_ = r.Close()
-					}
-				}()
-				data, err := io.ReadAll(r)
-				if err != nil {
-					log.Debug("ReadAll: %v", err)
-					continue
-				}
-				_ = r.Close()
-				var it api.IssueTemplate
-				content, err := markdown.ExtractMetadata(string(data), &it)
-				if err != nil {
-					log.Debug("ExtractMetadata: %v", err)
-					continue
-				}
-				it.Content = content
-				it.FileName = entry.Name()
-				if it.Valid() {
-					issueTemplates = append(issueTemplates, it)
-				}
-			}
-		}
-		if len(issueTemplates) > 0 {
-			return issueTemplates
+			return nil, err
 		}
 	}
-	return issueTemplates
+	return ctx.Repo.Commit, nil
 }
@@ -0,0 +1,82 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"context"
+
+	app_model "code.gitea.io/gitea/models/app"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToApp converts an app to its API format, the webhook URL is only shown to the owner of the app
+func ToApp(ctx context.Context, a *app_model.App, doer *user_model.User) (*api.App, error) {
+	bot, err := user_model.GetUserByIDCtx(ctx, a.BotID)
+	if err != nil && !user_model.IsErrUserNotExist(err) {
+		return nil, err
+	}
+	apiApp := &api.App{
+		ID:          a.ID,
+		Name:        a.Name,
+		Description: a.Description,
+		HomepageURL: a.HomepageURL,
+		Bot:         ToUser(bot, doer),
+		Permissions: a.Permissions.Scopes(),
+		Created:     a.CreatedUnix.AsTime(),
+	}
+	if a.WebhookID > 0 && doer != nil && doer.ID == a.OwnerID {
+		w, err := webhook_model.GetWebhookByID(a.WebhookID)
+		if err != nil && !webhook_model.IsErrWebhookNotExist(err) {
+			return nil, err
+		}
+		if w != nil {
+			apiApp.WebhookURL = w.URL
+		}
+	}
+	return apiApp, nil
+}
+
+// ToAppInstallation converts an installation to its API format
+func ToAppInstallation(ctx context.Context, i *app_model.Installation, doer *user_model.User) (*api.AppInstallation, error) {
+	a, err := app_model.GetAppByID(ctx, i.AppID)
+	if err != nil {
+		return nil, err
+	}
+	apiApp, err := ToApp(ctx, a, doer)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := user_model.GetUserByIDCtx(ctx, i.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	repoIDs, err := app_model.GetInstallationRepoIDs(ctx, i.ID)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := repo_model.GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(repoIDs))
+	for _, repoID := range repoIDs {
+		if repo, ok := repos[repoID]; ok {
+			names = append(names, repo.Name)
+		}
+	}
+
+	return &api.AppInstallation{
+		ID:              i.ID,
+		App:             apiApp,
+		Owner:           ToUser(owner, doer),
+		AllRepositories: i.AllRepositories,
+		Repositories:    names,
+		Created:         i.CreatedUnix.AsTime(),
+	}, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	audit_model "code.gitea.io/gitea/models/audit"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToAuditEvent converts an audit event to its API format
+func ToAuditEvent(e *audit_model.Event) *api.AuditEvent {
+	return &api.AuditEvent{
+		ID:        e.ID,
+		Action:    string(e.Action),
+		ActorID:   e.ActorID,
+		ActorName: e.ActorName,
+		Scope:     toAuditObject(e.Scope()),
+		Target:    toAuditObject(e.Target()),
+		Before:    e.Before,
+		After:     e.After,
+		IPAddress: e.IPAddress,
+		Created:   e.CreatedUnix.AsTime(),
+	}
+}
+
+func toAuditObject(o audit_model.Object) *api.AuditObject {
+	return &api.AuditObject{
+		Type: string(o.Type),
+		ID:   o.ID,
+		Name: o.Name,
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
@@ -143,8 +144,11 @@ func ToBranchProtection(bp *git_model.ProtectedBranch) *api.BranchProtection {
 		BlockOnOutdatedBranch:         bp.BlockOnOutdatedBranch,
 		DismissStaleApprovals:         bp.DismissStaleApprovals,
 		RequireSignedCommits:          bp.RequireSignedCommits,
+		RequireCodeOwnerApproval:      bp.RequireCodeOwnerApproval,
+		EnableMergeQueue:              bp.EnableMergeQueue,
 		ProtectedFilePatterns:         bp.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       bp.UnprotectedFilePatterns,
+		MergeWindows:                  bp.MergeWindows,
 		Created:                       bp.CreatedUnix.AsTime(),
 		Updated:                       bp.UpdatedUnix.AsTime(),
 	}
@@ -247,6 +251,10 @@ func ToHook(repoLink string, w *webhook.Webhook) *api.Hook {
 		"url":          w.URL,
 		"content_type": w.ContentType.Name(),
 	}
+	if w.Headers != "" {
+		// the headers only hold references to secrets, not their values
+		config["headers"] = w.Headers
+	}
 	if w.Type == webhook.SLACK {
 		s := webhook_service.GetSlackHook(w)
 		config["channel"] = s.Channel
@@ -399,6 +407,28 @@ func ToOAuth2Application(app *auth.OAuth2Application) *api.OAuth2Application {
 	}
 }
 
+// ToAccessToken convert from models.AccessToken to api.AccessToken, the restriction of the token must be loaded
+func ToAccessToken(t *models.AccessToken) *api.AccessToken {
+	apiToken := &api.AccessToken{
+		ID:             t.ID,
+		Name:           t.Name,
+		Token:          t.Token,
+		TokenLastEight: t.TokenLastEight,
+		Scopes:         t.Scope.Scopes(),
+	}
+	if t.Repo != nil {
+		apiToken.Repository = t.Repo.FullName()
+	}
+	if t.Org != nil {
+		apiToken.Organization = t.Org.Name
+	}
+	if t.ExpiresUnix > 0 {
+		expiresAt := t.ExpiresUnix.AsTime()
+		apiToken.ExpiresAt = &expiresAt
+	}
+	return apiToken
+}
+
 // ToLFSLock convert a LFSLock to api.LFSLock
 func ToLFSLock(l *git_model.LFSLock) *api.LFSLock {
 	u, err := user_model.GetUserByID(l.OwnerID)
@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	auth_model "code.gitea.io/gitea/models/auth"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToDeployToken converts a deploy token to its API format, its value is only set right after its creation
+func ToDeployToken(t *auth_model.DeployToken) *api.DeployToken {
+	apiToken := &api.DeployToken{
+		ID:             t.ID,
+		Name:           t.Name,
+		Token:          t.Token,
+		TokenLastEight: t.TokenLastEight,
+		ReadOnly:       t.IsReadOnly(),
+		Created:        t.CreatedUnix.AsTime(),
+	}
+	if t.ExpiresUnix > 0 {
+		expiresAt := t.ExpiresUnix.AsTime()
+		apiToken.ExpiresAt = &expiresAt
+	}
+	return apiToken
+}
@@ -18,16 +18,39 @@ func ToPushMirror(pm *repo_model.PushMirror) (*api.PushMirror, error) {
 		return nil, err
 	}
 	return &api.PushMirror{
-		RepoName:       repo.Name,
-		RemoteName:     pm.RemoteName,
-		RemoteAddress:  remoteAddress,
-		CreatedUnix:    pm.CreatedUnix.FormatLong(),
-		LastUpdateUnix: pm.LastUpdateUnix.FormatLong(),
-		LastError:      pm.LastError,
-		Interval:       pm.Interval.String(),
+		RepoName:         repo.Name,
+		RemoteName:       pm.RemoteName,
+		RemoteAddress:    remoteAddress,
+		CreatedUnix:      pm.CreatedUnix.FormatLong(),
+		LastUpdateUnix:   pm.LastUpdateUnix.FormatLong(),
+		LastError:        pm.LastError,
+		Interval:         pm.Interval.String(),
+		SyncOnCommit:     pm.SyncOnCommit,
+		RefFilter:        pm.RefFilter,
+		ProtectForcePush: pm.ProtectForcePush,
 	}, nil
 }
 
+// ToPushMirrorRef convert from repo_model.PushMirrorRef to api.PushMirrorRef
+func ToPushMirrorRef(ref *repo_model.PushMirrorRef) *api.PushMirrorRef {
+	return &api.PushMirrorRef{
+		RefName:  ref.RefName,
+		CommitID: ref.CommitID,
+		Status:   ref.Status.String(),
+		Error:    ref.Error,
+		Updated:  ref.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToPushMirrorSyncError convert from repo_model.PushMirrorSyncError to api.PushMirrorSyncError
+func ToPushMirrorSyncError(syncErr *repo_model.PushMirrorSyncError) *api.PushMirrorSyncError {
+	return &api.PushMirrorSyncError{
+		RefName: syncErr.RefName,
+		Error:   syncErr.Error,
+		Created: syncErr.CreatedUnix.AsTime(),
+	}
+}
+
 func getRemoteAddress(repo *repo_model.Repository, remoteName string) (string, error) {
 	url, err := git.GetRemoteURL(git.DefaultContext, repo.RepoPath(), remoteName)
 	if err != nil {
@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"context"
+
+	project_model "code.gitea.io/gitea/models/project"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToAPIProject converts a project to its API format
+func ToAPIProject(ctx context.Context, p *project_model.Project, doer *user_model.User) (*api.Project, error) {
+	if err := p.LoadOwner(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+
+	apiProject := &api.Project{
+		ID:           p.ID,
+		Title:        p.Title,
+		Description:  p.Description,
+		State:        api.StateOpen,
+		OpenIssues:   p.NumOpenIssues(),
+		ClosedIssues: p.NumClosedIssues(),
+		HTMLURL:      p.HTMLURL(),
+		Created:      p.CreatedUnix.AsTime(),
+		Updated:      p.UpdatedUnix.AsTimePtr(),
+	}
+	if p.IsClosed {
+		apiProject.State = api.StateClosed
+		apiProject.Closed = p.ClosedDateUnix.AsTimePtr()
+	}
+
+	switch p.Type {
+	case project_model.TypeIndividual:
+		apiProject.Type = "individual"
+	case project_model.TypeOrganization:
+		apiProject.Type = "organization"
+	default:
+		apiProject.Type = "repository"
+	}
+
+	if p.IsOwnerProject() {
+		apiProject.Owner = ToUser(p.Owner, doer)
+	} else {
+		apiProject.Repo = &api.RepositoryMeta{
+			ID:       p.Repo.ID,
+			Name:     p.Repo.Name,
+			Owner:    p.Repo.OwnerName,
+			FullName: p.Repo.FullName(),
+		}
+	}
+
+	creator, err := user_model.GetUserByIDCtx(ctx, p.CreatorID)
+	if err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		creator = user_model.NewGhostUser()
+	}
+	apiProject.Creator = ToUser(creator, doer)
+
+	return apiProject, nil
+}
+
+// ToAPIProjectBoard converts a project board to its API format
+func ToAPIProjectBoard(b *project_model.Board) *api.ProjectBoard {
+	return &api.ProjectBoard{
+		ID:        b.ID,
+		ProjectID: b.ProjectID,
+		Title:     b.Title,
+		Color:     b.Color,
+		Default:   b.Default,
+		Sorting:   b.Sorting,
+		Created:   b.CreatedUnix.AsTime(),
+	}
+}
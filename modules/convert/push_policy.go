@@ -0,0 +1,25 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	git_model "code.gitea.io/gitea/models/git"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToPushPolicy converts a push policy to its API format
+func ToPushPolicy(p *git_model.PushPolicy) *api.PushPolicy {
+	return &api.PushPolicy{
+		DryRun:               p.DryRun,
+		MaxFileSize:          p.MaxFileSize,
+		ForbiddenPaths:       p.ForbiddenPaths,
+		ForbiddenExtensions:  p.ForbiddenExtensions,
+		RequireSignedCommits: p.RequireSignedCommits,
+		CommitMessagePattern: p.CommitMessagePattern,
+		AllowedEmailDomains:  p.AllowedEmailDomains,
+		SecretPatterns:       p.SecretPatterns,
+		Updated:              p.UpdatedUnix.AsTime(),
+	}
+}
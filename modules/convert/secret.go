@@ -0,0 +1,19 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	secret_model "code.gitea.io/gitea/models/secret"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToSecret converts a secret to its API format, without its value
+func ToSecret(s *secret_model.Secret) *api.Secret {
+	return &api.Secret{
+		Name:    s.Name,
+		Created: s.CreatedUnix.AsTime(),
+		Updated: s.UpdatedUnix.AsTime(),
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"fmt"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	secretscan_model "code.gitea.io/gitea/models/secretscan"
+	user_model "code.gitea.io/gitea/models/user"
+	secretscan_module "code.gitea.io/gitea/modules/secretscan"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ToSecretScanningAlert converts a secret scanning alert of the repository to its API format, its resolver must be loaded
+func ToSecretScanningAlert(a *secretscan_model.Alert, repo *repo_model.Repository, doer *user_model.User) *api.SecretScanningAlert {
+	apiAlert := &api.SecretScanningAlert{
+		ID:             a.ID,
+		Rule:           a.RuleID,
+		RedactedSecret: a.Redacted,
+		CommitID:       a.CommitID,
+		Path:           a.Path,
+		Line:           a.Line,
+		HTMLURL:        fmt.Sprintf("%s/src/commit/%s/%s#L%d", repo.HTMLURL(), a.CommitID, util.PathEscapeSegments(a.Path), a.Line),
+		State:          a.Status.String(),
+		Revoked:        a.Revoked,
+		Created:        a.CreatedUnix.AsTime(),
+	}
+	if rule := secretscan_module.GetRule(a.RuleID); rule != nil {
+		apiAlert.RuleName = rule.Name
+	}
+	if a.Resolver != nil {
+		apiAlert.ResolvedBy = ToUser(a.Resolver, doer)
+	}
+	if a.ResolvedUnix > 0 {
+		resolvedAt := a.ResolvedUnix.AsTime()
+		apiAlert.ResolvedAt = &resolvedAt
+	}
+	return apiAlert
+}
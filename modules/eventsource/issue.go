@@ -0,0 +1,46 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import "fmt"
+
+// IssueUpdateType is the kind of change of an issue or a pull request
+type IssueUpdateType string
+
+// enumerate all issue update types
+const (
+	IssueUpdateComment   IssueUpdateType = "comment" // a comment was created, edited or deleted
+	IssueUpdateStatus    IssueUpdateType = "status"  // the issue was closed, reopened or merged
+	IssueUpdateLabels    IssueUpdateType = "labels"
+	IssueUpdateReview    IssueUpdateType = "review"
+	IssueUpdateMergeable IssueUpdateType = "mergeable" // the mergeability of the pull request was checked
+)
+
+// IssueUpdateEventName is the name of the events sent to the pages of an issue
+const IssueUpdateEventName = "issue-update"
+
+// IssueUpdate is the data of the events sent to the pages of an issue
+type IssueUpdate struct {
+	Type      IssueUpdateType `json:"type"`
+	IssueID   int64           `json:"issue_id"`
+	CommentID int64           `json:"comment_id,omitempty"`
+}
+
+// IssueResource returns the resource of the events of an issue
+func IssueResource(issueID int64) string {
+	return fmt.Sprintf("issue/%d", issueID)
+}
+
+// SendIssueUpdate sends an update of an issue to its open pages
+func SendIssueUpdate(issueID int64, updateType IssueUpdateType, commentID int64) {
+	GetManager().SendResourceMessage(IssueResource(issueID), &Event{
+		Name: IssueUpdateEventName,
+		Data: &IssueUpdate{
+			Type:      updateType,
+			IssueID:   issueID,
+			CommentID: commentID,
+		},
+	})
+}
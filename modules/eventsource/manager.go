@@ -13,6 +13,7 @@ type Manager struct {
 	mutex sync.Mutex
 
 	messengers map[int64]*Messenger
+	resources  map[string]*Messenger
 	connection chan struct{}
 }
 
@@ -21,6 +22,7 @@ var manager *Manager
 func init() {
 	manager = &Manager{
 		messengers: make(map[int64]*Messenger),
+		resources:  make(map[string]*Messenger),
 		connection: make(chan struct{}, 1),
 	}
 }
@@ -67,6 +69,10 @@ func (m *Manager) UnregisterAll() {
 		messenger.UnregisterAll()
 	}
 	m.messengers = map[int64]*Messenger{}
+	for _, messenger := range m.resources {
+		messenger.UnregisterAll()
+	}
+	m.resources = map[string]*Messenger{}
 }
 
 // SendMessage sends a message to a particular user
@@ -88,3 +94,39 @@ func (m *Manager) SendMessageBlocking(uid int64, message *Event) {
 		messenger.SendMessageBlocking(message)
 	}
 }
+
+// RegisterResource registers a message channel for the events of a resource,
+// the resources are not bound to a user and their events are not polled
+func (m *Manager) RegisterResource(resource string) <-chan *Event {
+	m.mutex.Lock()
+	messenger, ok := m.resources[resource]
+	if !ok {
+		messenger = NewMessenger(0)
+		m.resources[resource] = messenger
+	}
+	m.mutex.Unlock()
+	return messenger.Register()
+}
+
+// UnregisterResource unregisters a message channel of a resource
+func (m *Manager) UnregisterResource(resource string, channel <-chan *Event) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	messenger, ok := m.resources[resource]
+	if !ok {
+		return
+	}
+	if messenger.Unregister(channel) {
+		delete(m.resources, resource)
+	}
+}
+
+// SendResourceMessage sends a message to the listeners of a resource
+func (m *Manager) SendResourceMessage(resource string, message *Event) {
+	m.mutex.Lock()
+	messenger, ok := m.resources[resource]
+	m.mutex.Unlock()
+	if ok {
+		messenger.SendMessage(message)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ChangedFile is a file added or modified by a commit
+type ChangedFile struct {
+	CommitID string
+	Path     string
+	BlobID   string // empty if the file isn't a regular file
+}
+
+// GetChangedFiles returns the files added or modified by the commits, the environment allows to read quarantined objects.
+// The merge commits list the files which differ from all their parents, that is the files changed by the resolution
+// of the merge, the other changes are those of the merged commits.
+func GetChangedFiles(ctx context.Context, repoPath string, env, commitIDs []string) ([]*ChangedFile, error) {
+	if len(commitIDs) == 0 {
+		return nil, nil
+	}
+	stdout, _, err := NewCommand(ctx, "diff-tree", "--stdin", "-r", "--root", "--no-renames", "--cc", "--raw", "-z").
+		RunStdString(&RunOpts{
+			Dir:   repoPath,
+			Env:   env,
+			Stdin: strings.NewReader(strings.Join(commitIDs, "\n") + "\n"),
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the files changed by the commits: %w", err)
+	}
+
+	// the output is the ID of each commit followed by its changes, each change being
+	// ":<old mode> <new mode> <old blob> <new blob> <status>" and the path, the changes of the merge commits
+	// start with a colon per parent and list the mode and the blob of every parent before the new ones
+	var files []*ChangedFile
+	var commitID string
+	tokens := strings.Split(stdout, "\x00")
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "" {
+			continue
+		}
+		if !strings.HasPrefix(token, ":") {
+			commitID = strings.TrimSpace(token)
+			continue
+		}
+		parents := len(token) - len(strings.TrimLeft(token, ":"))
+		fields := strings.Fields(token[parents:])
+		i++
+		if len(fields) != 2*(parents+1)+1 || i >= len(tokens) {
+			return nil, fmt.Errorf("unexpected diff-tree output: %q", token)
+		}
+		newMode, newBlob := fields[parents], fields[2*parents+1]
+		if newMode == "000000" { // deleted
+			continue
+		}
+		file := &ChangedFile{CommitID: commitID, Path: tokens[i]}
+		if strings.HasPrefix(newMode, "100") {
+			file.BlobID = newBlob
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChangedFiles(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	files, err := GetChangedFiles(DefaultContext, bareRepo1Path, nil, []string{
+		"37991dec2c8e592043f47155ce4808d4580f9123",
+		"8006ff9adbf0cb94da7dad9e537e53817f9fa5c0",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*ChangedFile{
+		{CommitID: "37991dec2c8e592043f47155ce4808d4580f9123", Path: "foo/link_short"},
+		{CommitID: "8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", Path: "foo/bar/link_to_hello"},
+		{CommitID: "8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", Path: "foo/nar/hello", BlobID: "b14df6442ea5a1b382985a6549b85d435376c351"},
+	}, files)
+
+	files, err = GetChangedFiles(DefaultContext, bareRepo1Path, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestGetChangedFilesOfMerge(t *testing.T) {
+	repoPath, err := os.MkdirTemp("", "diff-tree-merge")
+	assert.NoError(t, err)
+	defer util.RemoveAll(repoPath)
+	assert.NoError(t, InitRepository(DefaultContext, repoPath, false))
+
+	env := append(os.Environ(), "GIT_AUTHOR_NAME=user", "GIT_AUTHOR_EMAIL=user@example.com", "GIT_COMMITTER_NAME=user", "GIT_COMMITTER_EMAIL=user@example.com")
+	run := func(args ...string) string {
+		stdout, _, err := NewCommand(DefaultContext, args...).RunStdString(&RunOpts{Dir: repoPath, Env: env})
+		assert.NoError(t, err)
+		return strings.TrimSpace(stdout)
+	}
+	commit := func(name, content string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644))
+		run("add", name)
+		run("commit", "-m", name)
+	}
+
+	commit("base", "base")
+	run("checkout", "-b", "feature")
+	commit("feature", "feature")
+	run("checkout", "-")
+	commit("main", "main")
+	// the merge resolution adds a file of its own
+	run("merge", "--no-ff", "--no-commit", "feature")
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "evil"), []byte("evil"), 0o644))
+	run("add", "evil")
+	run("commit", "-m", "merge")
+	mergeID := run("rev-parse", "HEAD")
+
+	files, err := GetChangedFiles(DefaultContext, repoPath, nil, []string{mergeID})
+	assert.NoError(t, err)
+	assert.Equal(t, []*ChangedFile{
+		{CommitID: mergeID, Path: "evil", BlobID: run("rev-parse", "HEAD:evil")},
+	}, files)
+}
@@ -11,6 +11,8 @@ const (
 	RemotePrefix = "refs/remotes/"
 	// PullPrefix is the base directory of the pull information of git.
 	PullPrefix = "refs/pull/"
+	// MirrorBackupPrefix is the base directory of the refs preserved by pull mirrors when upstream rewrites them.
+	MirrorBackupPrefix = "refs/mirror-backup/"
 
 	pullLen = len(PullPrefix)
 )
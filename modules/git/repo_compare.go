@@ -289,6 +289,16 @@ func (repo *Repository) GetFilesChangedBetween(base, head string) ([]string, err
 	return strings.Split(stdout, "\n"), err
 }
 
+// GetFilesChangedBetweenNoRenames returns a list of all files that have been changed between the given commits,
+// renamed files are listed with both their old and their new path
+func (repo *Repository) GetFilesChangedBetweenNoRenames(base, head string) ([]string, error) {
+	stdout, _, err := NewCommand(repo.Ctx, "diff", "--name-only", "--no-renames", base+".."+head).RunStdString(&RunOpts{Dir: repo.Path})
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(stdout, "\n"), err
+}
+
 // GetDiffFromMergeBase generates and return patch data from merge base to head
 func (repo *Repository) GetDiffFromMergeBase(base, head string, w io.Writer) error {
 	stderr := new(bytes.Buffer)
@@ -7,7 +7,9 @@ package git
 import (
 	"bytes"
 	"io"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/modules/util"
@@ -124,3 +126,35 @@ func TestReadWritePullHead(t *testing.T) {
 	err = repo.RemoveReference(PullPrefix + "1/head")
 	assert.NoError(t, err)
 }
+
+func TestGetFilesChangedBetweenNoRenames(t *testing.T) {
+	repoPath, err := os.MkdirTemp("", "files-changed-renames")
+	assert.NoError(t, err)
+	defer util.RemoveAll(repoPath)
+	assert.NoError(t, InitRepository(DefaultContext, repoPath, false))
+
+	env := append(os.Environ(), "GIT_AUTHOR_NAME=user", "GIT_AUTHOR_EMAIL=user@example.com", "GIT_COMMITTER_NAME=user", "GIT_COMMITTER_EMAIL=user@example.com")
+	run := func(args ...string) string {
+		stdout, _, err := NewCommand(DefaultContext, args...).RunStdString(&RunOpts{Dir: repoPath, Env: env})
+		assert.NoError(t, err)
+		return strings.TrimSpace(stdout)
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(repoPath, "old"), []byte("content which is long enough to be detected as a rename\n"), 0o644))
+	run("add", "old")
+	run("commit", "-m", "add")
+	base := run("rev-parse", "HEAD")
+	run("mv", "old", "new")
+	run("commit", "-m", "rename")
+
+	repo, err := openRepositoryWithDefaultContext(repoPath)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	files, err := repo.GetFilesChangedBetween(base, "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new", ""}, files)
+
+	files, err = repo.GetFilesChangedBetweenNoRenames(base, "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new", "old", ""}, files)
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+)
+
+// PushRefResult is the result of the push of a single ref
+type PushRefResult struct {
+	Flag    byte   // ' ' fast-forward, '+' forced update, '-' deleted, '*' new ref, '!' rejected or failed, '=' up to date
+	From    string // the local ref, empty if the ref was deleted
+	To      string // the remote ref
+	Summary string // e.g. "[rejected]" or "abc1234..def5678"
+	Reason  string // e.g. "non-fast-forward", empty on success
+}
+
+// IsFailed returns true if the ref was not pushed
+func (r *PushRefResult) IsFailed() bool {
+	return r.Flag == '!'
+}
+
+// IsDeleted returns true if the remote ref was deleted
+func (r *PushRefResult) IsDeleted() bool {
+	return r.Flag == '-'
+}
+
+// Error returns the description of the failure
+func (r *PushRefResult) Error() string {
+	if r.Reason == "" {
+		return r.Summary
+	}
+	return fmt.Sprintf("%s %s", r.Summary, r.Reason)
+}
+
+// PushRefs pushes the refspecs and returns the result of every ref.
+// The push of a ref can fail without failing the others, an error is only returned if no ref was pushed at all.
+func PushRefs(ctx context.Context, repoPath string, opts PushOptions, refspecs ...string) ([]*PushRefResult, error) {
+	cmd := NewCommand(ctx)
+	if !opts.Mirror && len(refspecs) > 0 {
+		// a remote added with "--mirror=push" doesn't accept refspecs
+		cmd.AddArguments("-c", "remote."+opts.Remote+".mirror=false")
+	}
+	cmd.AddArguments("push", "--porcelain")
+	if opts.Force {
+		cmd.AddArguments("-f")
+	}
+	if opts.Mirror {
+		cmd.AddArguments("--mirror")
+	}
+	cmd.AddArguments("--", opts.Remote)
+	cmd.AddArguments(refspecs...)
+	remote := opts.Remote
+	if strings.Contains(remote, "://") && strings.Contains(remote, "@") {
+		remote = util.SanitizeCredentialURLs(remote)
+	}
+	cmd.SetDescription(fmt.Sprintf("push %d refspecs to %s (force: %t, mirror: %t)", len(refspecs), remote, opts.Force, opts.Mirror))
+
+	if opts.Timeout == 0 {
+		opts.Timeout = -1
+	}
+
+	var outbuf, errbuf strings.Builder
+	err := cmd.Run(&RunOpts{
+		Env:     opts.Env,
+		Timeout: opts.Timeout,
+		Dir:     repoPath,
+		Stdout:  &outbuf,
+		Stderr:  &errbuf,
+	})
+	results := ParsePushPorcelain(outbuf.String())
+	if err != nil && len(results) == 0 {
+		return nil, ConcatenateError(err, errbuf.String())
+	}
+	return results, nil
+}
+
+// ParsePushPorcelain parses the output of "git push --porcelain":
+//
+//	To <url>
+//	<flag> TAB <from>:<to> TAB <summary> [(<reason>)]
+//	Done
+func ParsePushPorcelain(output string) []*PushRefResult {
+	results := make([]*PushRefResult, 0, 10)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 2 || line[1] != '\t' {
+			continue
+		}
+		fields := strings.SplitN(line[2:], "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		from, to, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			continue
+		}
+
+		result := &PushRefResult{
+			Flag:    line[0],
+			From:    from,
+			To:      to,
+			Summary: fields[1],
+		}
+		if i := strings.Index(fields[1], " ("); i != -1 && strings.HasSuffix(fields[1], ")") {
+			result.Summary = fields[1][:i]
+			result.Reason = fields[1][i+2 : len(fields[1])-1]
+		}
+		results = append(results, result)
+	}
+	return results
+}
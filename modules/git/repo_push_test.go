@@ -0,0 +1,43 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePushPorcelain(t *testing.T) {
+	output := "To https://example.com/user/repo.git\n" +
+		" \trefs/heads/main:refs/heads/main\t1f2e3d4..5a6b7c8\n" +
+		"*\trefs/tags/v1.0:refs/tags/v1.0\t[new tag]\n" +
+		"-\t:refs/heads/old\t[deleted]\n" +
+		"=\trefs/heads/stable:refs/heads/stable\t[up to date]\n" +
+		"!\trefs/heads/dev:refs/heads/dev\t[rejected] (non-fast-forward)\n" +
+		"!\trefs/heads/hook:refs/heads/hook\t[remote rejected] (pre-receive hook declined)\n" +
+		"Done\n"
+
+	results := ParsePushPorcelain(output)
+	if assert.Len(t, results, 6) {
+		assert.Equal(t, &PushRefResult{Flag: ' ', From: "refs/heads/main", To: "refs/heads/main", Summary: "1f2e3d4..5a6b7c8"}, results[0])
+		assert.False(t, results[0].IsFailed())
+
+		assert.Equal(t, byte('*'), results[1].Flag)
+
+		assert.True(t, results[2].IsDeleted())
+		assert.Empty(t, results[2].From)
+		assert.Equal(t, "refs/heads/old", results[2].To)
+
+		assert.True(t, results[4].IsFailed())
+		assert.Equal(t, "[rejected]", results[4].Summary)
+		assert.Equal(t, "non-fast-forward", results[4].Reason)
+		assert.Equal(t, "[rejected] non-fast-forward", results[4].Error())
+
+		assert.Equal(t, "pre-receive hook declined", results[5].Reason)
+	}
+
+	assert.Empty(t, ParsePushPorcelain("fatal: unable to access\n"))
+}
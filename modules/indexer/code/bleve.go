@@ -166,12 +166,23 @@ var _ Indexer = &BleveIndexer{}
 type BleveIndexer struct {
 	indexDir string
 	indexer  bleve.Index
+	isWiki   bool
 }
 
 // NewBleveIndexer creates a new bleve local indexer
 func NewBleveIndexer(indexDir string) (*BleveIndexer, bool, error) {
+	return newBleveIndexer(indexDir, false)
+}
+
+// NewBleveWikiIndexer creates a new bleve local indexer of the wikis
+func NewBleveWikiIndexer(indexDir string) (*BleveIndexer, bool, error) {
+	return newBleveIndexer(indexDir, true)
+}
+
+func newBleveIndexer(indexDir string, isWiki bool) (*BleveIndexer, bool, error) {
 	indexer := &BleveIndexer{
 		indexDir: indexDir,
+		isWiki:   isWiki,
 	}
 	created, err := indexer.init()
 	if err != nil {
@@ -185,7 +196,7 @@ func (b *BleveIndexer) addUpdate(ctx context.Context, batchWriter git.WriteClose
 	update fileUpdate, repo *repo_model.Repository, batch *gitea_bleve.FlushingBatch,
 ) error {
 	// Ignore vendored files in code search
-	if setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
+	if !b.isWiki && setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
 		return nil
 	}
 
@@ -194,7 +205,8 @@ func (b *BleveIndexer) addUpdate(ctx context.Context, batchWriter git.WriteClose
 	var err error
 	if !update.Sized {
 		var stdout string
-		stdout, _, err = git.NewCommand(ctx, "cat-file", "-s", update.BlobSha).RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+		repoPath, _ := indexedGitRepo(repo, b.isWiki)
+		stdout, _, err = git.NewCommand(ctx, "cat-file", "-s", update.BlobSha).RunStdString(&git.RunOpts{Dir: repoPath})
 		if err != nil {
 			return err
 		}
@@ -286,14 +298,15 @@ func (b *BleveIndexer) Ping() bool {
 func (b *BleveIndexer) Index(ctx context.Context, repo *repo_model.Repository, sha string, changes *repoChanges) error {
 	batch := gitea_bleve.NewFlushingBatch(b.indexer, maxBatchSize)
 	if len(changes.Updates) > 0 {
+		repoPath, _ := indexedGitRepo(repo, b.isWiki)
 
 		// Now because of some insanity with git cat-file not immediately failing if not run in a valid git directory we need to run git rev-parse first!
-		if err := git.EnsureValidGitRepository(ctx, repo.RepoPath()); err != nil {
-			log.Error("Unable to open git repo: %s for %-v: %v", repo.RepoPath(), repo, err)
+		if err := git.EnsureValidGitRepository(ctx, repoPath); err != nil {
+			log.Error("Unable to open git repo: %s for %-v: %v", repoPath, repo, err)
 			return err
 		}
 
-		batchWriter, batchReader, cancel := git.CatFileBatch(ctx, repo.RepoPath())
+		batchWriter, batchReader, cancel := git.CatFileBatch(ctx, repoPath)
 		defer cancel()
 
 		for _, update := range changes.Updates {
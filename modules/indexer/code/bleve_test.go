@@ -5,10 +5,12 @@
 package code
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
@@ -37,3 +39,34 @@ func TestBleveIndexAndSearch(t *testing.T) {
 
 	testIndexer("beleve", t, idx)
 }
+
+func TestBleveWikiIndexAndSearch(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	dir, err := os.MkdirTemp("", "bleve.wiki.index")
+	assert.NoError(t, err)
+	defer util.RemoveAll(dir)
+
+	idx, _, err := NewBleveWikiIndexer(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer idx.Close()
+
+	assert.NoError(t, index(git.DefaultContext, idx, 1, true))
+
+	total, res, _, err := idx.Search(context.Background(), []int64{1}, "", "name with spaces", 1, 10, false)
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 1, total) {
+		assert.Equal(t, "Page-With-Spaced-Name.md", res[0].Filename)
+	}
+
+	// the pictures of the wiki are not indexed
+	total, _, _, err = idx.Search(context.Background(), nil, "", "JFIF", 1, 10, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, total)
+
+	total, _, _, err = idx.Search(context.Background(), []int64{2}, "", "name with spaces", 1, 10, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, total)
+}
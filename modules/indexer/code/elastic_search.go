@@ -49,6 +49,7 @@ type ElasticSearchIndexer struct {
 	availabilityCallback func(bool)
 	stopTimer            chan struct{}
 	lock                 sync.RWMutex
+	isWiki               bool
 }
 
 type elasticLogger struct {
@@ -61,6 +62,15 @@ func (l elasticLogger) Printf(format string, args ...interface{}) {
 
 // NewElasticSearchIndexer creates a new elasticsearch indexer
 func NewElasticSearchIndexer(url, indexerName string) (*ElasticSearchIndexer, bool, error) {
+	return newElasticSearchIndexer(url, indexerName, false)
+}
+
+// NewElasticSearchWikiIndexer creates a new elasticsearch indexer of the wikis
+func NewElasticSearchWikiIndexer(url, indexerName string) (*ElasticSearchIndexer, bool, error) {
+	return newElasticSearchIndexer(url, indexerName, true)
+}
+
+func newElasticSearchIndexer(url, indexerName string, isWiki bool) (*ElasticSearchIndexer, bool, error) {
 	opts := []elastic.ClientOptionFunc{
 		elastic.SetURL(url),
 		elastic.SetSniff(false),
@@ -88,6 +98,7 @@ func NewElasticSearchIndexer(url, indexerName string) (*ElasticSearchIndexer, bo
 		indexerAliasName: indexerName,
 		available:        true,
 		stopTimer:        make(chan struct{}),
+		isWiki:           isWiki,
 	}
 
 	ticker := time.NewTicker(10 * time.Second)
@@ -215,7 +226,7 @@ func (b *ElasticSearchIndexer) Ping() bool {
 
 func (b *ElasticSearchIndexer) addUpdate(ctx context.Context, batchWriter git.WriteCloserError, batchReader *bufio.Reader, sha string, update fileUpdate, repo *repo_model.Repository) ([]elastic.BulkableRequest, error) {
 	// Ignore vendored files in code search
-	if setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
+	if !b.isWiki && setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
 		return nil, nil
 	}
 
@@ -223,7 +234,8 @@ func (b *ElasticSearchIndexer) addUpdate(ctx context.Context, batchWriter git.Wr
 	var err error
 	if !update.Sized {
 		var stdout string
-		stdout, _, err = git.NewCommand(ctx, "cat-file", "-s", update.BlobSha).RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+		repoPath, _ := indexedGitRepo(repo, b.isWiki)
+		stdout, _, err = git.NewCommand(ctx, "cat-file", "-s", update.BlobSha).RunStdString(&git.RunOpts{Dir: repoPath})
 		if err != nil {
 			return nil, err
 		}
@@ -283,13 +295,15 @@ func (b *ElasticSearchIndexer) addDelete(filename string, repo *repo_model.Repos
 func (b *ElasticSearchIndexer) Index(ctx context.Context, repo *repo_model.Repository, sha string, changes *repoChanges) error {
 	reqs := make([]elastic.BulkableRequest, 0)
 	if len(changes.Updates) > 0 {
+		repoPath, _ := indexedGitRepo(repo, b.isWiki)
+
 		// Now because of some insanity with git cat-file not immediately failing if not run in a valid git directory we need to run git rev-parse first!
-		if err := git.EnsureValidGitRepository(ctx, repo.RepoPath()); err != nil {
-			log.Error("Unable to open git repo: %s for %-v: %v", repo.RepoPath(), repo, err)
+		if err := git.EnsureValidGitRepository(ctx, repoPath); err != nil {
+			log.Error("Unable to open git repo: %s for %-v: %v", repoPath, repo, err)
 			return err
 		}
 
-		batchWriter, batchReader, cancel := git.CatFileBatch(ctx, repo.RepoPath())
+		batchWriter, batchReader, cancel := git.CatFileBatch(ctx, repoPath)
 		defer cancel()
 
 		for _, update := range changes.Updates {
@@ -28,8 +28,24 @@ type repoChanges struct {
 	RemovedFilenames []string
 }
 
-func getDefaultBranchSha(ctx context.Context, repo *repo_model.Repository) (string, error) {
-	stdout, _, err := git.NewCommand(ctx, "show-ref", "-s", git.BranchPrefix+repo.DefaultBranch).RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+// indexedGitRepo returns the path and the branch of the git repository which is indexed: the code or the wiki of the repository
+func indexedGitRepo(repo *repo_model.Repository, isWiki bool) (string, string) {
+	if isWiki {
+		return repo.WikiPath(), "master"
+	}
+	return repo.RepoPath(), repo.DefaultBranch
+}
+
+func indexerTypeOf(isWiki bool) repo_model.RepoIndexerType {
+	if isWiki {
+		return repo_model.RepoIndexerTypeWiki
+	}
+	return repo_model.RepoIndexerTypeCode
+}
+
+func getDefaultBranchSha(ctx context.Context, repo *repo_model.Repository, isWiki bool) (string, error) {
+	repoPath, branch := indexedGitRepo(repo, isWiki)
+	stdout, _, err := git.NewCommand(ctx, "show-ref", "-s", git.BranchPrefix+branch).RunStdString(&git.RunOpts{Dir: repoPath})
 	if err != nil {
 		return "", err
 	}
@@ -37,22 +53,26 @@ func getDefaultBranchSha(ctx context.Context, repo *repo_model.Repository) (stri
 }
 
 // getRepoChanges returns changes to repo since last indexer update
-func getRepoChanges(ctx context.Context, repo *repo_model.Repository, revision string) (*repoChanges, error) {
-	status, err := repo_model.GetIndexerStatus(ctx, repo, repo_model.RepoIndexerTypeCode)
+func getRepoChanges(ctx context.Context, idx Indexer, repo *repo_model.Repository, revision string, isWiki bool) (*repoChanges, error) {
+	status, err := repo_model.GetIndexerStatus(ctx, repo, indexerTypeOf(isWiki))
 	if err != nil {
 		return nil, err
 	}
 
 	if len(status.CommitSha) == 0 {
-		return genesisChanges(ctx, repo, revision)
+		return genesisChanges(ctx, repo, revision, isWiki)
 	}
-	return nonGenesisChanges(ctx, repo, revision)
+	return nonGenesisChanges(ctx, idx, repo, status.CommitSha, revision, isWiki)
 }
 
-func isIndexable(entry *git.TreeEntry) bool {
+func isIndexable(entry *git.TreeEntry, isWiki bool) bool {
 	if !entry.IsRegular() && !entry.IsExecutable() {
 		return false
 	}
+	if isWiki {
+		// only the pages of the wiki are indexed
+		return strings.HasSuffix(entry.Name(), ".md")
+	}
 	name := strings.ToLower(entry.Name())
 	for _, g := range setting.Indexer.ExcludePatterns {
 		if g.Match(name) {
@@ -68,7 +88,7 @@ func isIndexable(entry *git.TreeEntry) bool {
 }
 
 // parseGitLsTreeOutput parses the output of a `git ls-tree -r --full-name` command
-func parseGitLsTreeOutput(stdout []byte) ([]fileUpdate, error) {
+func parseGitLsTreeOutput(stdout []byte, isWiki bool) ([]fileUpdate, error) {
 	entries, err := git.ParseTreeEntries(stdout)
 	if err != nil {
 		return nil, err
@@ -76,7 +96,7 @@ func parseGitLsTreeOutput(stdout []byte) ([]fileUpdate, error) {
 	idxCount := 0
 	updates := make([]fileUpdate, len(entries))
 	for _, entry := range entries {
-		if isIndexable(entry) {
+		if isIndexable(entry, isWiki) {
 			updates[idxCount] = fileUpdate{
 				Filename: entry.Name(),
 				BlobSha:  entry.ID.String(),
@@ -90,30 +110,32 @@ func parseGitLsTreeOutput(stdout []byte) ([]fileUpdate, error) {
 }
 
 // genesisChanges get changes to add repo to the indexer for the first time
-func genesisChanges(ctx context.Context, repo *repo_model.Repository, revision string) (*repoChanges, error) {
+func genesisChanges(ctx context.Context, repo *repo_model.Repository, revision string, isWiki bool) (*repoChanges, error) {
 	var changes repoChanges
-	stdout, _, runErr := git.NewCommand(ctx, "ls-tree", "--full-tree", "-l", "-r", revision).RunStdBytes(&git.RunOpts{Dir: repo.RepoPath()})
+	repoPath, _ := indexedGitRepo(repo, isWiki)
+	stdout, _, runErr := git.NewCommand(ctx, "ls-tree", "--full-tree", "-l", "-r", revision).RunStdBytes(&git.RunOpts{Dir: repoPath})
 	if runErr != nil {
 		return nil, runErr
 	}
 
 	var err error
-	changes.Updates, err = parseGitLsTreeOutput(stdout)
+	changes.Updates, err = parseGitLsTreeOutput(stdout, isWiki)
 	return &changes, err
 }
 
 // nonGenesisChanges get changes since the previous indexer update
-func nonGenesisChanges(ctx context.Context, repo *repo_model.Repository, revision string) (*repoChanges, error) {
-	diffCmd := git.NewCommand(ctx, "diff", "--name-status", repo.CodeIndexerStatus.CommitSha, revision)
-	stdout, _, runErr := diffCmd.RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+func nonGenesisChanges(ctx context.Context, idx Indexer, repo *repo_model.Repository, indexedSha, revision string, isWiki bool) (*repoChanges, error) {
+	repoPath, _ := indexedGitRepo(repo, isWiki)
+	diffCmd := git.NewCommand(ctx, "diff", "--name-status", indexedSha, revision)
+	stdout, _, runErr := diffCmd.RunStdString(&git.RunOpts{Dir: repoPath})
 	if runErr != nil {
 		// previous commit sha may have been removed by a force push, so
 		// try rebuilding from scratch
 		log.Warn("git diff: %v", runErr)
-		if err := indexer.Delete(repo.ID); err != nil {
+		if err := idx.Delete(repo.ID); err != nil {
 			return nil, err
 		}
-		return genesisChanges(ctx, repo, revision)
+		return genesisChanges(ctx, repo, revision, isWiki)
 	}
 
 	var changes repoChanges
@@ -171,10 +193,10 @@ func nonGenesisChanges(ctx context.Context, repo *repo_model.Repository, revisio
 
 	cmd := git.NewCommand(ctx, "ls-tree", "--full-tree", "-l", revision, "--")
 	cmd.AddArguments(updatedFilenames...)
-	lsTreeStdout, _, err := cmd.RunStdBytes(&git.RunOpts{Dir: repo.RepoPath()})
+	lsTreeStdout, _, err := cmd.RunStdBytes(&git.RunOpts{Dir: repoPath})
 	if err != nil {
 		return nil, err
 	}
-	changes.Updates, err = parseGitLsTreeOutput(lsTreeStdout)
+	changes.Updates, err = parseGitLsTreeOutput(lsTreeStdout, isWiki)
 	return &changes, err
 }
@@ -84,7 +84,7 @@ type IndexerData struct {
 
 var indexerQueue queue.UniqueQueue
 
-func index(ctx context.Context, indexer Indexer, repoID int64) error {
+func index(ctx context.Context, indexer Indexer, repoID int64, isWiki bool) error {
 	repo, err := repo_model.GetRepositoryByID(repoID)
 	if repo_model.IsErrRepoNotExist(err) {
 		return indexer.Delete(repoID)
@@ -92,12 +92,15 @@ func index(ctx context.Context, indexer Indexer, repoID int64) error {
 	if err != nil {
 		return err
 	}
+	if isWiki && !repo.HasWiki() {
+		return indexer.Delete(repoID)
+	}
 
-	sha, err := getDefaultBranchSha(ctx, repo)
+	sha, err := getDefaultBranchSha(ctx, repo, isWiki)
 	if err != nil {
 		return err
 	}
-	changes, err := getRepoChanges(ctx, repo, sha)
+	changes, err := getRepoChanges(ctx, indexer, repo, sha, isWiki)
 	if err != nil {
 		return err
 	} else if changes == nil {
@@ -108,17 +111,45 @@ func index(ctx context.Context, indexer Indexer, repoID int64) error {
 		return err
 	}
 
-	return repo_model.UpdateIndexerStatus(ctx, repo, repo_model.RepoIndexerTypeCode, sha)
+	return repo_model.UpdateIndexerStatus(ctx, repo, indexerTypeOf(isWiki), sha)
 }
 
-// Init initialize the repo indexer
+// Init initialize the repo indexer and the wiki indexer
 func Init() {
 	if !setting.Indexer.RepoIndexerEnabled {
 		indexer.Close()
-		return
+	} else {
+		indexerQueue = initIndexer("Repository", "code_indexer", indexer, false, func() (Indexer, bool, error) {
+			switch setting.Indexer.RepoType {
+			case "bleve":
+				log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoPath)
+				return NewBleveIndexer(setting.Indexer.RepoPath)
+			default:
+				log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoConnStr)
+				return NewElasticSearchIndexer(setting.Indexer.RepoConnStr, setting.Indexer.RepoIndexerName)
+			}
+		})
 	}
 
-	ctx, cancel, finished := process.GetManager().AddTypedContext(context.Background(), "Service: CodeIndexer", process.SystemProcessType, false)
+	if !setting.Indexer.WikiIndexerEnabled {
+		wikiIndexer.Close()
+	} else {
+		wikiIndexerQueue = initIndexer("Wiki", "wiki_indexer", wikiIndexer, true, func() (Indexer, bool, error) {
+			switch setting.Indexer.RepoType {
+			case "bleve":
+				log.Info("PID: %d Initializing Wiki Indexer at: %s", os.Getpid(), setting.Indexer.WikiPath)
+				return NewBleveWikiIndexer(setting.Indexer.WikiPath)
+			default:
+				log.Info("PID: %d Initializing Wiki Indexer at: %s", os.Getpid(), setting.Indexer.RepoConnStr)
+				return NewElasticSearchWikiIndexer(setting.Indexer.RepoConnStr, setting.Indexer.WikiIndexerName)
+			}
+		})
+	}
+}
+
+// initIndexer creates the queue of the indexer and initializes the indexer in the background
+func initIndexer(name, queueName string, wrapped *wrappedIndexer, isWiki bool, newIndexer func() (Indexer, bool, error)) queue.UniqueQueue {
+	ctx, cancel, finished := process.GetManager().AddTypedContext(context.Background(), "Service: "+name+"Indexer", process.SystemProcessType, false)
 
 	graceful.GetManager().RunAtTerminate(func() {
 		select {
@@ -127,21 +158,22 @@ func Init() {
 		default:
 		}
 		cancel()
-		log.Debug("Closing repository indexer")
-		indexer.Close()
-		log.Info("PID: %d Repository Indexer closed", os.Getpid())
+		log.Debug("Closing %s indexer", name)
+		wrapped.Close()
+		log.Info("PID: %d %s Indexer closed", os.Getpid(), name)
 		finished()
 	})
 
 	waitChannel := make(chan time.Duration, 1)
 
 	// Create the Queue
+	var indexQueue queue.UniqueQueue
 	switch setting.Indexer.RepoType {
 	case "bleve", "elasticsearch":
 		handler := func(data ...queue.Data) []queue.Data {
-			idx, err := indexer.get()
+			idx, err := wrapped.get()
 			if idx == nil || err != nil {
-				log.Error("Codes indexer handler: unable to get indexer!")
+				log.Error("%s indexer handler: unable to get indexer!", name)
 				return data
 			}
 
@@ -154,9 +186,9 @@ func Init() {
 				}
 				log.Trace("IndexerData Process Repo: %d", indexerData.RepoID)
 
-				if err := index(ctx, indexer, indexerData.RepoID); err != nil {
+				if err := index(ctx, wrapped, indexerData.RepoID, isWiki); err != nil {
 					log.Error("index: %v", err)
-					if indexer.Ping() {
+					if wrapped.Ping() {
 						continue
 					}
 					// Add back to queue
@@ -166,80 +198,54 @@ func Init() {
 			return unhandled
 		}
 
-		indexerQueue = queue.CreateUniqueQueue("code_indexer", handler, &IndexerData{})
-		if indexerQueue == nil {
-			log.Fatal("Unable to create codes indexer queue")
+		indexQueue = queue.CreateUniqueQueue(queueName, handler, &IndexerData{})
+		if indexQueue == nil {
+			log.Fatal("Unable to create %s indexer queue", name)
 		}
 	default:
-		log.Fatal("Unknown codes indexer type; %s", setting.Indexer.RepoType)
+		log.Fatal("Unknown %s indexer type; %s", name, setting.Indexer.RepoType)
 	}
 
 	go func() {
 		pprof.SetGoroutineLabels(ctx)
 		start := time.Now()
-		var (
-			rIndexer Indexer
-			populate bool
-			err      error
-		)
-		switch setting.Indexer.RepoType {
-		case "bleve":
-			log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoPath)
-			defer func() {
-				if err := recover(); err != nil {
-					log.Error("PANIC whilst initializing repository indexer: %v\nStacktrace: %s", err, log.Stack(2))
-					log.Error("The indexer files are likely corrupted and may need to be deleted")
-					log.Error("You can completely remove the \"%s\" directory to make Gitea recreate the indexes", setting.Indexer.RepoPath)
-				}
-			}()
-
-			rIndexer, populate, err = NewBleveIndexer(setting.Indexer.RepoPath)
-			if err != nil {
-				cancel()
-				indexer.Close()
-				close(waitChannel)
-				log.Fatal("PID: %d Unable to initialize the bleve Repository Indexer at path: %s Error: %v", os.Getpid(), setting.Indexer.RepoPath, err)
+		defer func() {
+			if err := recover(); err != nil {
+				log.Error("PANIC whilst initializing %s indexer: %v\nStacktrace: %s", name, err, log.Stack(2))
+				log.Error("The indexer files are likely corrupted and may need to be deleted")
+				log.Error("You can completely remove the index to make Gitea recreate it")
 			}
-		case "elasticsearch":
-			log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoConnStr)
-			defer func() {
-				if err := recover(); err != nil {
-					log.Error("PANIC whilst initializing repository indexer: %v\nStacktrace: %s", err, log.Stack(2))
-					log.Error("The indexer files are likely corrupted and may need to be deleted")
-					log.Error("You can completely remove the \"%s\" index to make Gitea recreate the indexes", setting.Indexer.RepoConnStr)
-				}
-			}()
+		}()
 
-			rIndexer, populate, err = NewElasticSearchIndexer(setting.Indexer.RepoConnStr, setting.Indexer.RepoIndexerName)
-			if err != nil {
-				cancel()
-				indexer.Close()
-				close(waitChannel)
-				log.Fatal("PID: %d Unable to initialize the elasticsearch Repository Indexer connstr: %s Error: %v", os.Getpid(), setting.Indexer.RepoConnStr, err)
-			}
-		default:
-			log.Fatal("PID: %d Unknown Indexer type: %s", os.Getpid(), setting.Indexer.RepoType)
+		rIndexer, populate, err := newIndexer()
+		if err != nil {
+			cancel()
+			wrapped.Close()
+			close(waitChannel)
+			log.Fatal("PID: %d Unable to initialize the %s Indexer: %v", os.Getpid(), name, err)
 		}
 
-		indexer.set(rIndexer)
+		wrapped.set(rIndexer)
 
-		if queue, ok := indexerQueue.(queue.Pausable); ok {
+		if queue, ok := indexQueue.(queue.Pausable); ok {
 			rIndexer.SetAvailabilityChangeCallback(func(available bool) {
 				if !available {
-					log.Info("Code index queue paused")
+					log.Info("%s index queue paused", name)
 					queue.Pause()
 				} else {
-					log.Info("Code index queue resumed")
+					log.Info("%s index queue resumed", name)
 					queue.Resume()
 				}
 			})
 		}
 
 		// Start processing the queue
-		go graceful.GetManager().RunWithShutdownFns(indexerQueue.Run)
+		go graceful.GetManager().RunWithShutdownFns(indexQueue.Run)
 
 		if populate {
-			go graceful.GetManager().RunWithShutdownContext(populateRepoIndexer)
+			go graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+				populateRepoIndexer(ctx, indexQueue, isWiki)
+			})
 		}
 		select {
 		case waitChannel <- time.Since(start):
@@ -258,24 +264,25 @@ func Init() {
 			}
 			select {
 			case <-graceful.GetManager().IsShutdown():
-				log.Warn("Shutdown before Repository Indexer completed initialization")
+				log.Warn("Shutdown before %s Indexer completed initialization", name)
 				cancel()
-				indexer.Close()
+				wrapped.Close()
 			case duration, ok := <-waitChannel:
 				if !ok {
-					log.Warn("Repository Indexer Initialization failed")
+					log.Warn("%s Indexer Initialization failed", name)
 					cancel()
-					indexer.Close()
+					wrapped.Close()
 					return
 				}
-				log.Info("Repository Indexer Initialization took %v", duration)
+				log.Info("%s Indexer Initialization took %v", name, duration)
 			case <-time.After(timeout):
 				cancel()
-				indexer.Close()
-				log.Fatal("Repository Indexer Initialization Timed-Out after: %v", timeout)
+				wrapped.Close()
+				log.Fatal("%s Indexer Initialization Timed-Out after: %v", name, timeout)
 			}
 		}()
 	}
+	return indexQueue
 }
 
 // UpdateRepoIndexer update a repository's entries in the indexer
@@ -299,8 +306,8 @@ func IsAvailable() bool {
 
 // populateRepoIndexer populate the repo indexer with pre-existing data. This
 // should only be run when the indexer is created for the first time.
-func populateRepoIndexer(ctx context.Context) {
-	log.Info("Populating the repo indexer with existing repositories")
+func populateRepoIndexer(ctx context.Context, indexQueue queue.UniqueQueue, isWiki bool) {
+	log.Info("Populating the repo indexer with existing repositories (wiki: %t)", isWiki)
 
 	exist, err := db.IsTableNotEmpty("repository")
 	if err != nil {
@@ -310,9 +317,12 @@ func populateRepoIndexer(ctx context.Context) {
 	}
 
 	// if there is any existing repo indexer metadata in the DB, delete it
-	// since we are starting afresh. Also, xorm requires deletes to have a
-	// condition, and we want to delete everything, thus 1=1.
-	if err := db.DeleteAllRecords("repo_indexer_status"); err != nil {
+	// since we are starting afresh. Each indexer only deletes its own metadata.
+	indexerType := repo_model.RepoIndexerTypeCode
+	if isWiki {
+		indexerType = repo_model.RepoIndexerTypeWiki
+	}
+	if err = repo_model.DeleteIndexerStatusByType(ctx, indexerType); err != nil {
 		log.Fatal("System error: %v", err)
 	}
 
@@ -331,7 +341,7 @@ func populateRepoIndexer(ctx context.Context) {
 			return
 		default:
 		}
-		ids, err := repo_model.GetUnindexedRepos(repo_model.RepoIndexerTypeCode, maxRepoID, 0, 50)
+		ids, err := repo_model.GetUnindexedRepos(indexerTypeOf(isWiki), maxRepoID, 0, 50)
 		if err != nil {
 			log.Error("populateRepoIndexer: %v", err)
 			return
@@ -345,7 +355,7 @@ func populateRepoIndexer(ctx context.Context) {
 				return
 			default:
 			}
-			if err := indexerQueue.Push(&IndexerData{RepoID: id}); err != nil {
+			if err := indexQueue.Push(&IndexerData{RepoID: id}); err != nil {
 				log.Error("indexerQueue.Push: %v", err)
 				return
 			}
@@ -23,6 +23,7 @@ type Result struct {
 	Language       string
 	Color          string
 	LineNumbers    []int
+	Lines          string // the lines of the result without formatting
 	FormattedLines string
 }
 
@@ -102,6 +103,7 @@ func searchResult(result *SearchResult, startIndex, endIndex int) (*Result, erro
 		Language:       result.Language,
 		Color:          result.Color,
 		LineNumbers:    lineNumbers,
+		Lines:          result.Content[startIndex:endIndex],
 		FormattedLines: highlight.Code(result.Filename, "", formattedLinesBuffer.String()),
 	}, nil
 }
@@ -0,0 +1,65 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"context"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// the wiki pages are indexed by the code indexer backends in their own index
+var (
+	wikiIndexer      = newWrappedIndexer()
+	wikiIndexerQueue queue.UniqueQueue
+)
+
+// UpdateWikiIndexer queues the update of the wiki pages of a repository in the wiki indexer,
+// the pages are removed from the index if the repository or its wiki were deleted
+func UpdateWikiIndexer(repo *repo_model.Repository) {
+	if !setting.Indexer.WikiIndexerEnabled {
+		return
+	}
+	indexData := &IndexerData{RepoID: repo.ID}
+	if err := wikiIndexerQueue.Push(indexData); err != nil {
+		log.Error("Update wiki index data %v failed: %v", indexData, err)
+	}
+}
+
+// IsWikiIndexerAvailable checks if the wiki indexer is available
+func IsWikiIndexerAvailable() bool {
+	idx, err := wikiIndexer.get()
+	if err != nil {
+		log.Error("IsWikiIndexerAvailable(): unable to get indexer: %v", err)
+		return false
+	}
+
+	return idx.Ping()
+}
+
+// PerformWikiSearch searches the wiki pages of the repositories, the file names of the results are the ones of the pages
+func PerformWikiSearch(ctx context.Context, repoIDs []int64, keyword string, page, pageSize int, isMatch bool) (int, []*Result, error) {
+	if len(keyword) == 0 {
+		return 0, nil, nil
+	}
+
+	total, results, _, err := wikiIndexer.Search(ctx, repoIDs, "", keyword, page, pageSize, isMatch)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	displayResults := make([]*Result, len(results))
+	for i, result := range results {
+		startIndex, endIndex := indices(result.Content, result.StartIndex, result.EndIndex)
+		displayResults[i], err = searchResult(result, startIndex, endIndex)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	return int(total), displayResults, nil
+}
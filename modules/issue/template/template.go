@@ -0,0 +1,266 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package template parses the issue templates and the issue forms of a repository
+// and renders the values submitted with an issue form as markdown.
+package template
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+var fieldIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// FieldName returns the name of the form input of the field
+func FieldName(field *api.IssueFormField) string {
+	return "form-field-" + field.ID
+}
+
+// Validate checks that the template is complete and that the fields of an issue form are well-formed
+func Validate(it *api.IssueTemplate) error {
+	if strings.TrimSpace(it.Name) == "" {
+		return fmt.Errorf("'name' is required")
+	}
+	if strings.TrimSpace(it.About) == "" {
+		return fmt.Errorf("'about' is required")
+	}
+
+	ids := make(map[string]bool, len(it.Fields))
+	for i, field := range it.Fields {
+		if err := validateField(field); err != nil {
+			return fmt.Errorf("body[%d]: %w", i, err)
+		}
+		if ids[field.ID] {
+			return fmt.Errorf("body[%d]: 'id' must be unique, %q is used twice", i, field.ID)
+		}
+		ids[field.ID] = true
+	}
+	return nil
+}
+
+func validateField(field *api.IssueFormField) error {
+	if field == nil {
+		return fmt.Errorf("the field is empty")
+	}
+	if field.ID != "" && !fieldIDPattern.MatchString(field.ID) {
+		return fmt.Errorf("'id' must only contain alphanumeric characters, '-' and '_'")
+	}
+
+	attrs, validations := field.Attributes, field.Validations
+	switch field.Type {
+	case api.IssueFormFieldTypeMarkdown:
+		if attrs.Value == "" {
+			return fmt.Errorf("'value' is required for a markdown field")
+		}
+		if validations.Required {
+			return fmt.Errorf("a markdown field can't be required")
+		}
+	case api.IssueFormFieldTypeTextarea, api.IssueFormFieldTypeInput, api.IssueFormFieldTypeDropdown, api.IssueFormFieldTypeCheckboxes:
+		if strings.TrimSpace(attrs.Label) == "" {
+			return fmt.Errorf("'label' is required")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", field.Type)
+	}
+
+	if attrs.Render != "" && field.Type != api.IssueFormFieldTypeTextarea {
+		return fmt.Errorf("'render' is only supported by textarea fields")
+	}
+	if attrs.Multiple && field.Type != api.IssueFormFieldTypeDropdown {
+		return fmt.Errorf("'multiple' is only supported by dropdown fields")
+	}
+	if (validations.IsNumber || validations.Regex != "") && field.Type != api.IssueFormFieldTypeInput {
+		return fmt.Errorf("'is_number' and 'regex' are only supported by input fields")
+	}
+	if validations.Regex != "" {
+		if _, err := regexp.Compile(validations.Regex); err != nil {
+			return fmt.Errorf("invalid 'regex': %w", err)
+		}
+	}
+
+	hasOptions := field.Type == api.IssueFormFieldTypeDropdown || field.Type == api.IssueFormFieldTypeCheckboxes
+	if !hasOptions {
+		if len(attrs.Options) > 0 {
+			return fmt.Errorf("'options' are only supported by dropdown and checkboxes fields")
+		}
+		if attrs.Default != nil {
+			return fmt.Errorf("'default' is only supported by dropdown fields")
+		}
+		return nil
+	}
+	if len(attrs.Options) == 0 {
+		return fmt.Errorf("'options' are required")
+	}
+	for i, option := range attrs.Options {
+		if option == nil || strings.TrimSpace(option.Label) == "" {
+			return fmt.Errorf("options[%d]: 'label' is required", i)
+		}
+		if option.Required && field.Type != api.IssueFormFieldTypeCheckboxes {
+			return fmt.Errorf("options[%d]: only checkboxes can be required", i)
+		}
+	}
+	if attrs.Default != nil {
+		if field.Type != api.IssueFormFieldTypeDropdown {
+			return fmt.Errorf("'default' is only supported by dropdown fields")
+		}
+		if *attrs.Default < 0 || *attrs.Default >= len(attrs.Options) {
+			return fmt.Errorf("'default' must be the index of an option")
+		}
+	}
+	return nil
+}
+
+// ValueErrorReason is the reason a submitted value was refused
+type ValueErrorReason string
+
+// enumerate all reasons of value errors
+const (
+	ValueErrorRequired ValueErrorReason = "required"
+	ValueErrorNumber   ValueErrorReason = "number"
+	ValueErrorRegex    ValueErrorReason = "regex"
+	ValueErrorOption   ValueErrorReason = "option"
+)
+
+// ValueError is returned by ValidateValues if the value of a field is not valid
+type ValueError struct {
+	Field  *api.IssueFormField
+	Reason ValueErrorReason
+}
+
+func (err *ValueError) Error() string {
+	return fmt.Sprintf("invalid value of %q: %s", err.Field.Attributes.Label, err.Reason)
+}
+
+// ValidateValues checks the values submitted with the issue form against the validations of its fields
+func ValidateValues(it *api.IssueTemplate, values url.Values) error {
+	for _, field := range it.Fields {
+		if field.Type == api.IssueFormFieldTypeMarkdown {
+			continue
+		}
+		submitted := values[FieldName(field)]
+		fail := func(reason ValueErrorReason) error {
+			return &ValueError{Field: field, Reason: reason}
+		}
+
+		switch field.Type {
+		case api.IssueFormFieldTypeDropdown, api.IssueFormFieldTypeCheckboxes:
+			checked := make(map[int]bool, len(submitted))
+			for _, v := range submitted {
+				idx, err := strconv.Atoi(v)
+				if err != nil || idx < 0 || idx >= len(field.Attributes.Options) {
+					return fail(ValueErrorOption)
+				}
+				checked[idx] = true
+			}
+			if field.Type == api.IssueFormFieldTypeDropdown {
+				if len(checked) > 1 && !field.Attributes.Multiple {
+					return fail(ValueErrorOption)
+				}
+				if len(checked) == 0 && field.Validations.Required {
+					return fail(ValueErrorRequired)
+				}
+				continue
+			}
+			for i, option := range field.Attributes.Options {
+				if option.Required && !checked[i] {
+					return fail(ValueErrorRequired)
+				}
+			}
+		default:
+			value := strings.TrimSpace(firstValue(submitted))
+			if value == "" {
+				if field.Validations.Required {
+					return fail(ValueErrorRequired)
+				}
+				continue
+			}
+			if field.Validations.IsNumber {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return fail(ValueErrorNumber)
+				}
+			}
+			if field.Validations.Regex != "" {
+				if re, err := regexp.Compile(field.Validations.Regex); err != nil || !re.MatchString(value) {
+					return fail(ValueErrorRegex)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RenderToMarkdown renders the values submitted with the issue form as the markdown content of the issue
+func RenderToMarkdown(it *api.IssueTemplate, values url.Values) string {
+	var sb strings.Builder
+	for _, field := range it.Fields {
+		if field.Type == api.IssueFormFieldTypeMarkdown {
+			continue
+		}
+		submitted := values[FieldName(field)]
+
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "### %s\n\n", strings.TrimSpace(field.Attributes.Label))
+
+		switch field.Type {
+		case api.IssueFormFieldTypeCheckboxes:
+			for i, option := range field.Attributes.Options {
+				mark := " "
+				if containsIndex(submitted, i) {
+					mark = "x"
+				}
+				fmt.Fprintf(&sb, "- [%s] %s\n", mark, option.Label)
+			}
+		case api.IssueFormFieldTypeDropdown:
+			selected := make([]string, 0, len(submitted))
+			for i, option := range field.Attributes.Options {
+				if containsIndex(submitted, i) {
+					selected = append(selected, option.Label)
+				}
+			}
+			writeValue(&sb, strings.Join(selected, ", "))
+		case api.IssueFormFieldTypeTextarea:
+			value := strings.TrimSpace(strings.ReplaceAll(firstValue(submitted), "\r\n", "\n"))
+			if value != "" && field.Attributes.Render != "" {
+				value = fmt.Sprintf("```%s\n%s\n```", field.Attributes.Render, value)
+			}
+			writeValue(&sb, value)
+		default:
+			writeValue(&sb, strings.TrimSpace(firstValue(submitted)))
+		}
+	}
+	return sb.String()
+}
+
+func writeValue(sb *strings.Builder, value string) {
+	if value == "" {
+		value = "_No response_"
+	}
+	sb.WriteString(value)
+	sb.WriteString("\n")
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func containsIndex(values []string, idx int) bool {
+	s := strconv.Itoa(idx)
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"net/url"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bugReportForm = `name: Bug Report
+description: File a bug report
+title: "[Bug]: "
+labels: ["bug", "triage"]
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to fill out this bug report!
+  - type: input
+    id: version
+    attributes:
+      label: Version
+      placeholder: "1.17.0"
+    validations:
+      required: true
+      regex: "^[0-9]+\\.[0-9]+\\.[0-9]+$"
+  - type: textarea
+    id: logs
+    attributes:
+      label: Logs
+      render: shell
+  - type: dropdown
+    id: browsers
+    attributes:
+      label: Browsers
+      multiple: true
+      options:
+        - Firefox
+        - Chrome
+        - Safari
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Code of Conduct
+      options:
+        - label: I agree to follow the Code of Conduct
+          required: true
+        - label: I searched the existing issues
+`
+
+func TestUnmarshal(t *testing.T) {
+	it, err := Unmarshal(".gitea/ISSUE_TEMPLATE/bug.yaml", []byte(bugReportForm))
+	assert.NoError(t, err)
+	assert.Equal(t, "bug.yaml", it.FileName)
+	assert.Equal(t, "Bug Report", it.Name)
+	assert.Equal(t, "File a bug report", it.About)
+	assert.Equal(t, []string{"bug", "triage"}, it.Labels)
+	assert.True(t, it.IsForm())
+	if assert.Len(t, it.Fields, 5) {
+		assert.Equal(t, "0", it.Fields[0].ID)
+		assert.Equal(t, api.IssueFormFieldTypeInput, it.Fields[1].Type)
+		assert.True(t, it.Fields[1].Validations.Required)
+		assert.Equal(t, &api.IssueFormFieldOption{Label: "Chrome"}, it.Fields[3].Attributes.Options[1])
+		assert.Equal(t, &api.IssueFormFieldOption{Label: "I agree to follow the Code of Conduct", Required: true}, it.Fields[4].Attributes.Options[0])
+	}
+
+	it, err = Unmarshal("bug.md", []byte("---\nname: Bug\nabout: File a bug\n---\n\nThe body"))
+	assert.NoError(t, err)
+	assert.Equal(t, "File a bug", it.About)
+	assert.Equal(t, "\nThe body", it.Content)
+	assert.False(t, it.IsForm())
+
+	_, err = Unmarshal("config.yaml", []byte("blank_issues_enabled: false"))
+	assert.Error(t, err)
+	_, err = Unmarshal("empty.yaml", []byte("name: Empty\nabout: No fields"))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]string{
+		"unknown type":           "- type: file\n  attributes:\n    label: File",
+		"missing label":          "- type: input",
+		"markdown without value": "- type: markdown",
+		"missing options":        "- type: dropdown\n  attributes:\n    label: Choice",
+		"duplicate id":           "- type: input\n  id: a\n  attributes:\n    label: A\n- type: input\n  id: a\n  attributes:\n    label: B",
+		"invalid id":             "- type: input\n  id: a b\n  attributes:\n    label: A",
+		"invalid regex":          "- type: input\n  attributes:\n    label: A\n  validations:\n    regex: \"(\"",
+		"regex on textarea":      "- type: textarea\n  attributes:\n    label: A\n  validations:\n    regex: a",
+		"default out of range":   "- type: dropdown\n  attributes:\n    label: A\n    default: 1\n    options: [a]",
+	}
+	for name, body := range cases {
+		_, err := Unmarshal("form.yaml", []byte("name: Form\nabout: Form\nbody:\n"+body))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	it, err := Unmarshal("bug.yaml", []byte(bugReportForm))
+	assert.NoError(t, err)
+
+	valid := url.Values{
+		"form-field-version":  {"1.17.0"},
+		"form-field-browsers": {"0", "2"},
+		"form-field-terms":    {"0"},
+	}
+	assert.NoError(t, ValidateValues(it, valid))
+
+	invalid := func(values url.Values, reason ValueErrorReason) {
+		err := ValidateValues(it, values)
+		if valueErr, ok := err.(*ValueError); assert.True(t, ok, "%v", values) {
+			assert.Equal(t, reason, valueErr.Reason)
+		}
+	}
+	invalid(url.Values{"form-field-terms": {"0"}}, ValueErrorRequired)
+	invalid(url.Values{"form-field-version": {"latest"}, "form-field-terms": {"0"}}, ValueErrorRegex)
+	invalid(url.Values{"form-field-version": {"1.17.0"}}, ValueErrorRequired)
+	invalid(url.Values{"form-field-version": {"1.17.0"}, "form-field-terms": {"0"}, "form-field-browsers": {"3"}}, ValueErrorOption)
+}
+
+func TestRenderToMarkdown(t *testing.T) {
+	it, err := Unmarshal("bug.yaml", []byte(bugReportForm))
+	assert.NoError(t, err)
+
+	assert.Equal(t, `### Version
+
+1.17.0
+
+### Logs
+
+`+"```shell\npanic: oops\n```"+`
+
+### Browsers
+
+Firefox, Safari
+
+### Code of Conduct
+
+- [x] I agree to follow the Code of Conduct
+- [ ] I searched the existing issues
+`, RenderToMarkdown(it, url.Values{
+		"form-field-version":  {" 1.17.0 "},
+		"form-field-logs":     {"panic: oops\r\n"},
+		"form-field-browsers": {"0", "2"},
+		"form-field-terms":    {"0"},
+	}))
+
+	assert.Contains(t, RenderToMarkdown(it, url.Values{}), "### Logs\n\n_No response_\n")
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(""))
+	assert.NoError(t, err)
+	assert.True(t, config.BlankIssuesEnabled)
+
+	config, err = ParseConfig([]byte(`blank_issues_enabled: false
+contact_links:
+  - name: Forum
+    url: https://forum.example.com
+    about: Ask questions in the forum
+`))
+	assert.NoError(t, err)
+	assert.False(t, config.BlankIssuesEnabled)
+	assert.Equal(t, []*api.IssueConfigContactLink{{Name: "Forum", URL: "https://forum.example.com", About: "Ask questions in the forum"}}, config.ContactLinks)
+
+	_, err = ParseConfig([]byte("contact_links:\n  - name: Evil\n    url: javascript:alert(1)\n"))
+	assert.Error(t, err)
+}
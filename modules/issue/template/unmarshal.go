@@ -0,0 +1,184 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFileNames are the names of the config file in the issue template directories
+var ConfigFileNames = []string{"config.yaml", "config.yml"}
+
+// IsFormFile returns true if the file name is the one of an issue form
+func IsFormFile(filename string) bool {
+	ext := path.Ext(filename)
+	return (ext == ".yaml" || ext == ".yml") && !isConfigFile(filename)
+}
+
+// IsTemplateFile returns true if the file name is the one of a markdown template or an issue form
+func IsTemplateFile(filename string) bool {
+	return path.Ext(filename) == ".md" || IsFormFile(filename)
+}
+
+func isConfigFile(filename string) bool {
+	name := path.Base(filename)
+	for _, configName := range ConfigFileNames {
+		if name == configName {
+			return true
+		}
+	}
+	return false
+}
+
+// Unmarshal parses a markdown template or an issue form and validates it
+func Unmarshal(filename string, content []byte) (*api.IssueTemplate, error) {
+	it := &api.IssueTemplate{
+		FileName: path.Base(filename),
+	}
+
+	switch {
+	case IsFormFile(filename):
+		if err := yaml.Unmarshal(content, it); err != nil {
+			return nil, fmt.Errorf("yaml: %w", err)
+		}
+		// the issue forms of GitHub name the about "description"
+		var form struct {
+			Description string `yaml:"description"`
+		}
+		if err := yaml.Unmarshal(content, &form); err != nil {
+			return nil, fmt.Errorf("yaml: %w", err)
+		}
+		if it.About == "" {
+			it.About = form.Description
+		}
+		if len(it.Fields) == 0 {
+			return nil, fmt.Errorf("'body' is required")
+		}
+		for i, field := range it.Fields {
+			if field != nil && field.ID == "" {
+				field.ID = strconv.Itoa(i)
+			}
+		}
+	case path.Ext(filename) == ".md":
+		body, err := markdown.ExtractMetadata(string(content), it)
+		if err != nil {
+			return nil, fmt.Errorf("metadata: %w", err)
+		}
+		it.Content = body
+		it.Fields = nil
+	default:
+		return nil, fmt.Errorf("%s is not an issue template", filename)
+	}
+
+	if err := Validate(it); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// UnmarshalFromEntry reads the template of the tree entry
+func UnmarshalFromEntry(entry *git.TreeEntry) (*api.IssueTemplate, error) {
+	content, err := readEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(entry.Name(), content)
+}
+
+// ListFromCommit returns the templates of the first issue template directory of the commit which contains templates.
+// The templates which can't be parsed are reported in the map of errors by file path.
+func ListFromCommit(commit *git.Commit, dirs []string) ([]*api.IssueTemplate, map[string]error, error) {
+	invalid := make(map[string]error)
+	for _, dir := range dirs {
+		tree, err := commit.SubTree(dir)
+		if err != nil {
+			continue
+		}
+		entries, err := tree.ListEntries()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		templates := make([]*api.IssueTemplate, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.IsRegular() || !IsTemplateFile(entry.Name()) {
+				continue
+			}
+			it, err := UnmarshalFromEntry(entry)
+			if err != nil {
+				invalid[path.Join(dir, entry.Name())] = err
+				continue
+			}
+			templates = append(templates, it)
+		}
+		if len(templates) > 0 {
+			return templates, invalid, nil
+		}
+	}
+	return nil, invalid, nil
+}
+
+// ParseConfig parses the config of the issue templates
+func ParseConfig(content []byte) (*api.IssueConfig, error) {
+	config := &api.IssueConfig{
+		BlankIssuesEnabled: true,
+	}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+	for i, link := range config.ContactLinks {
+		if link == nil || strings.TrimSpace(link.Name) == "" {
+			return nil, fmt.Errorf("contact_links[%d]: 'name' is required", i)
+		}
+		if !strings.HasPrefix(link.URL, "http://") && !strings.HasPrefix(link.URL, "https://") {
+			return nil, fmt.Errorf("contact_links[%d]: 'url' must be a http or https URL", i)
+		}
+	}
+	return config, nil
+}
+
+// ConfigFromCommit returns the config of the issue templates of the commit, the default config if there is none
+func ConfigFromCommit(commit *git.Commit, dirs []string) (*api.IssueConfig, error) {
+	for _, dir := range dirs {
+		for _, name := range ConfigFileNames {
+			entry, err := commit.GetTreeEntryByPath(path.Join(dir, name))
+			if err != nil {
+				if git.IsErrNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+			content, err := readEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+			return ParseConfig(content)
+		}
+	}
+	return &api.IssueConfig{BlankIssuesEnabled: true}, nil
+}
+
+func readEntry(entry *git.TreeEntry) ([]byte, error) {
+	if entry.Blob().Size() >= setting.UI.MaxDisplayFileSize {
+		return nil, fmt.Errorf("%s is too large", entry.Name())
+	}
+	r, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
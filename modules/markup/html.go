@@ -327,6 +327,9 @@ func postProcess(ctx *RenderContext, procs []processor, input io.Reader, output
 	}
 
 	visitNode(ctx, procs, procs, node)
+	if ctx.IsWiki {
+		collectWikiLinks(ctx, node)
+	}
 
 	newNodes := make([]*html.Node, 0, 5)
 
@@ -425,6 +428,28 @@ func visitNode(ctx *RenderContext, procs, textProcs []processor, node *html.Node
 	// ignore everything else
 }
 
+// collectWikiLinks collects the links of the content to the pages of the wiki
+func collectWikiLinks(ctx *RenderContext, node *html.Node) {
+	if node.Type == html.ElementNode && node.Data == "a" {
+		prefix := util.URLJoin(ctx.URLPrefix, "wiki") + "/"
+		for _, attr := range node.Attr {
+			if attr.Key != "href" || !strings.HasPrefix(attr.Val, prefix) {
+				continue
+			}
+			link := strings.TrimPrefix(attr.Val, prefix)
+			if i := strings.IndexAny(link, "?#"); i >= 0 {
+				link = link[:i]
+			}
+			if link != "" && !strings.HasPrefix(link, "raw/") {
+				ctx.WikiLinks = append(ctx.WikiLinks, link)
+			}
+		}
+	}
+	for n := node.FirstChild; n != nil; n = n.NextSibling {
+		collectWikiLinks(ctx, n)
+	}
+}
+
 // textNode runs the passed node through various processors, in order to handle
 // all kinds of special links handled by the post-processing.
 func textNode(ctx *RenderContext, procs []processor, node *html.Node) {
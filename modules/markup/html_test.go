@@ -487,6 +487,28 @@ func TestRender_RelativeImages(t *testing.T) {
 		`<img src="`+util.URLJoin(rawwiki, "icon.png")+`"/>`)
 }
 
+func TestRender_WikiLinks(t *testing.T) {
+	setting.AppURL = TestAppURL
+
+	ctx := &RenderContext{
+		URLPrefix: TestRepoURL,
+		Metas:     localMetas,
+		IsWiki:    true,
+	}
+	_, err := markdown.RenderString(ctx, "[[Link]] [[Other Link|docs/Other-Link]] [a](Page#anchor) [b](Page?action=_edit) "+
+		"[c](https://example.com) [d](#anchor) [e](raw/file.txt) [[image.jpg]]")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Link", "docs/Other-Link", "Page", "Page"}, ctx.WikiLinks)
+
+	ctx = &RenderContext{
+		URLPrefix: TestRepoURL,
+		Metas:     localMetas,
+	}
+	_, err = markdown.RenderString(ctx, "[[Link]]")
+	assert.NoError(t, err)
+	assert.Empty(t, ctx.WikiLinks)
+}
+
 func Test_ParseClusterFuzz(t *testing.T) {
 	setting.AppURL = TestAppURL
 
@@ -55,7 +55,8 @@ type RenderContext struct {
 	ShaExistCache    map[string]bool
 	cancelFn         func()
 	TableOfContents  []Header
-	InStandalonePage bool // used by external render. the router "/org/repo/render/..." will output the rendered content in a standalone page
+	WikiLinks        []string // the sub-URLs of the links to wiki pages, collected by the post-processing of wiki content
+	InStandalonePage bool     // used by external render. the router "/org/repo/render/..." will output the rendered content in a standalone page
 }
 
 // Cancel runs any cleanup functions that have been registered for this Ctx
@@ -37,6 +37,7 @@ type Notifier interface {
 	NotifyNewPullRequest(pr *issues_model.PullRequest, mentions []*user_model.User)
 	NotifyMergePullRequest(*issues_model.PullRequest, *user_model.User)
 	NotifyPullRequestSynchronized(doer *user_model.User, pr *issues_model.PullRequest)
+	NotifyPullRequestMergeabilityChecked(pr *issues_model.PullRequest)
 	NotifyPullRequestReview(pr *issues_model.PullRequest, review *issues_model.Review, comment *issues_model.Comment, mentions []*user_model.User)
 	NotifyPullRequestCodeComment(pr *issues_model.PullRequest, comment *issues_model.Comment, mentions []*user_model.User)
 	NotifyPullRequestChangeTargetBranch(doer *user_model.User, pr *issues_model.PullRequest, oldBranch string)
@@ -55,6 +56,7 @@ type Notifier interface {
 	NotifySyncPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits)
 	NotifySyncCreateRef(doer *user_model.User, repo *repo_model.Repository, refType, refFullName, refID string)
 	NotifySyncDeleteRef(doer *user_model.User, repo *repo_model.Repository, refType, refFullName string)
+	NotifyMirrorForcePush(repo *repo_model.Repository, backup *repo_model.MirrorBackup)
 	NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository)
 	NotifyPackageCreate(doer *user_model.User, pd *packages_model.PackageDescriptor)
 	NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescriptor)
@@ -172,6 +172,14 @@ func (*NullNotifier) NotifySyncCreateRef(doer *user_model.User, repo *repo_model
 func (*NullNotifier) NotifySyncDeleteRef(doer *user_model.User, repo *repo_model.Repository, refType, refFullName string) {
 }
 
+// NotifyPullRequestMergeabilityChecked places a place holder function
+func (*NullNotifier) NotifyPullRequestMergeabilityChecked(pr *issues_model.PullRequest) {
+}
+
+// NotifyMirrorForcePush places a place holder function
+func (*NullNotifier) NotifyMirrorForcePush(repo *repo_model.Repository, backup *repo_model.MirrorBackup) {
+}
+
 // NotifyRepoPendingTransfer places a place holder function
 func (*NullNotifier) NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository) {
 }
@@ -0,0 +1,87 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+)
+
+type ciNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &ciNotifier{}
+
+// NewNotifier create a new ciNotifier notifier
+func NewNotifier() base.Notifier {
+	return &ciNotifier{}
+}
+
+func (m *ciNotifier) NotifyPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, _ *repository.PushCommits) {
+	if opts.IsDelRef() || (!opts.IsBranch() && !opts.IsTag()) {
+		return
+	}
+	ci_module.AddDetectionToQueue(&ci_module.DetectionRequest{
+		RepoID:    repo.ID,
+		DoerID:    pusher.ID,
+		Event:     ci_module.EventPush,
+		Ref:       opts.RefFullName,
+		FilterRef: opts.RefFullName,
+		CommitSHA: opts.NewCommitID,
+	})
+}
+
+func (m *ciNotifier) NotifyNewPullRequest(pr *issues_model.PullRequest, _ []*user_model.User) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return
+	}
+	detectPullRequestWorkflows(pr.Issue.Poster, pr)
+}
+
+func (m *ciNotifier) NotifyPullRequestSynchronized(doer *user_model.User, pr *issues_model.PullRequest) {
+	detectPullRequestWorkflows(doer, pr)
+}
+
+// detectPullRequestWorkflows runs the workflows of the head of the pull request in the base repository
+func detectPullRequestWorkflows(doer *user_model.User, pr *issues_model.PullRequest) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		log.Error("LoadBaseRepo: %v", err)
+		return
+	}
+	// the workflows come from the head branch, do not run code of strangers on the runners of the base repository
+	if pr.HeadRepoID != pr.BaseRepoID {
+		canWrite, err := access_model.HasAccessUnit(db.DefaultContext, doer, pr.BaseRepo, unit.TypeCode, perm.AccessModeWrite)
+		if err != nil {
+			log.Error("HasAccessUnit: %v", err)
+			return
+		}
+		if !canWrite {
+			return
+		}
+	}
+	ci_module.AddDetectionToQueue(&ci_module.DetectionRequest{
+		RepoID:    pr.BaseRepoID,
+		DoerID:    doer.ID,
+		Event:     ci_module.EventPullRequest,
+		Ref:       pr.GetGitRefName(),
+		FilterRef: git.BranchPrefix + pr.BaseBranch,
+	})
+}
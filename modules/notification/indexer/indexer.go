@@ -112,6 +112,7 @@ func (r *indexerNotifier) NotifyDeleteRepository(doer *user_model.User, repo *re
 	if setting.Indexer.RepoIndexerEnabled {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
+	code_indexer.UpdateWikiIndexer(repo)
 }
 
 func (r *indexerNotifier) NotifyMigrateRepository(doer, u *user_model.User, repo *repo_model.Repository) {
@@ -119,6 +120,7 @@ func (r *indexerNotifier) NotifyMigrateRepository(doer, u *user_model.User, repo
 	if setting.Indexer.RepoIndexerEnabled && !repo.IsEmpty {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
+	code_indexer.UpdateWikiIndexer(repo)
 	if err := stats_indexer.UpdateRepoIndexer(repo); err != nil {
 		log.Error("stats_indexer.UpdateRepoIndexer(%d) failed: %v", repo.ID, err)
 	}
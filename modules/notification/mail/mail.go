@@ -205,6 +205,13 @@ func (m *mailNotifier) NotifyNewRelease(rel *models.Release) {
 	mailer.MailNewRelease(ctx, rel)
 }
 
+func (m *mailNotifier) NotifyMirrorForcePush(repo *repo_model.Repository, backup *repo_model.MirrorBackup) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("mailNotifier.NotifyMirrorForcePush %s in [%d]", backup.RefName, repo.ID))
+	defer finished()
+
+	mailer.MailMirrorForcePush(ctx, repo, backup)
+}
+
 func (m *mailNotifier) NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository) {
 	if err := mailer.SendRepoTransferNotifyMail(doer, newOwner, repo); err != nil {
 		log.Error("NotifyRepoPendingTransfer: %v", err)
@@ -12,9 +12,11 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/notification/action"
 	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/notification/ci"
 	"code.gitea.io/gitea/modules/notification/indexer"
 	"code.gitea.io/gitea/modules/notification/mail"
 	"code.gitea.io/gitea/modules/notification/mirror"
+	"code.gitea.io/gitea/modules/notification/secretscan"
 	"code.gitea.io/gitea/modules/notification/ui"
 	"code.gitea.io/gitea/modules/notification/webhook"
 	"code.gitea.io/gitea/modules/repository"
@@ -39,6 +41,12 @@ func NewContext() {
 	RegisterNotifier(webhook.NewNotifier())
 	RegisterNotifier(action.NewNotifier())
 	RegisterNotifier(mirror.NewNotifier())
+	if setting.CI.Enabled {
+		RegisterNotifier(ci.NewNotifier())
+	}
+	if setting.SecretScanning.Enabled {
+		RegisterNotifier(secretscan.NewNotifier())
+	}
 }
 
 // NotifyCreateIssueComment notifies issue comment related message to notifiers
@@ -99,6 +107,13 @@ func NotifyPullRequestReview(pr *issues_model.PullRequest, review *issues_model.
 	}
 }
 
+// NotifyPullRequestMergeabilityChecked notifies the result of the mergeability check of a pull request to notifiers
+func NotifyPullRequestMergeabilityChecked(pr *issues_model.PullRequest) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestMergeabilityChecked(pr)
+	}
+}
+
 // NotifyPullRequestCodeComment notifies new pull request code comment
 func NotifyPullRequestCodeComment(pr *issues_model.PullRequest, comment *issues_model.Comment, mentions []*user_model.User) {
 	for _, notifier := range notifiers {
@@ -304,6 +319,13 @@ func NotifySyncDeleteRef(pusher *user_model.User, repo *repo_model.Repository, r
 	}
 }
 
+// NotifyMirrorForcePush notifies a ref of a pull mirror which was force-pushed upstream to notifiers
+func NotifyMirrorForcePush(repo *repo_model.Repository, backup *repo_model.MirrorBackup) {
+	for _, notifier := range notifiers {
+		notifier.NotifyMirrorForcePush(repo, backup)
+	}
+}
+
 // NotifyRepoPendingTransfer notifies creation of pending transfer to notifiers
 func NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository) {
 	for _, notifier := range notifiers {
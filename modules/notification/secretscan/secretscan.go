@@ -0,0 +1,44 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secretscan
+
+import (
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+	secretscan_module "code.gitea.io/gitea/modules/secretscan"
+)
+
+type secretScanNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &secretScanNotifier{}
+
+// NewNotifier create a new secretScanNotifier notifier
+func NewNotifier() base.Notifier {
+	return &secretScanNotifier{}
+}
+
+func (m *secretScanNotifier) NotifyPushCommits(_ *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, _ *repository.PushCommits) {
+	addScanToQueue(repo, opts)
+}
+
+func (m *secretScanNotifier) NotifySyncPushCommits(_ *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, _ *repository.PushCommits) {
+	addScanToQueue(repo, opts)
+}
+
+func addScanToQueue(repo *repo_model.Repository, opts *repository.PushUpdateOptions) {
+	if opts.IsDelRef() {
+		return
+	}
+	secretscan_module.AddScanToQueue(&secretscan_module.ScanRequest{
+		RepoID:      repo.ID,
+		RefName:     opts.RefFullName,
+		OldCommitID: opts.OldCommitID,
+		NewCommitID: opts.NewCommitID,
+	})
+}
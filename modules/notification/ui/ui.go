@@ -10,6 +10,7 @@ import (
 	issues_model "code.gitea.io/gitea/models/issues"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/eventsource"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification/base"
@@ -64,6 +65,7 @@ func (ns *notificationService) NotifyCreateIssueComment(doer *user_model.User, r
 		opts.CommentID = comment.ID
 	}
 	_ = ns.issueQueue.Push(opts)
+	eventsource.SendIssueUpdate(issue.ID, eventsource.IssueUpdateComment, opts.CommentID)
 	for _, mention := range mentions {
 		opts := issueNotificationOpts{
 			IssueID:              issue.ID,
@@ -96,6 +98,25 @@ func (ns *notificationService) NotifyIssueChangeStatus(doer *user_model.User, is
 		IssueID:              issue.ID,
 		NotificationAuthorID: doer.ID,
 	})
+	eventsource.SendIssueUpdate(issue.ID, eventsource.IssueUpdateStatus, 0)
+}
+
+func (ns *notificationService) NotifyIssueChangeLabels(doer *user_model.User, issue *issues_model.Issue,
+	addedLabels, removedLabels []*issues_model.Label,
+) {
+	eventsource.SendIssueUpdate(issue.ID, eventsource.IssueUpdateLabels, 0)
+}
+
+func (ns *notificationService) NotifyIssueClearLabels(doer *user_model.User, issue *issues_model.Issue) {
+	eventsource.SendIssueUpdate(issue.ID, eventsource.IssueUpdateLabels, 0)
+}
+
+func (ns *notificationService) NotifyUpdateComment(doer *user_model.User, c *issues_model.Comment, oldContent string) {
+	eventsource.SendIssueUpdate(c.IssueID, eventsource.IssueUpdateComment, c.ID)
+}
+
+func (ns *notificationService) NotifyDeleteComment(doer *user_model.User, c *issues_model.Comment) {
+	eventsource.SendIssueUpdate(c.IssueID, eventsource.IssueUpdateComment, c.ID)
 }
 
 func (ns *notificationService) NotifyIssueChangeTitle(doer *user_model.User, issue *issues_model.Issue, oldTitle string) {
@@ -116,6 +137,11 @@ func (ns *notificationService) NotifyMergePullRequest(pr *issues_model.PullReque
 		IssueID:              pr.Issue.ID,
 		NotificationAuthorID: doer.ID,
 	})
+	eventsource.SendIssueUpdate(pr.Issue.ID, eventsource.IssueUpdateStatus, 0)
+}
+
+func (ns *notificationService) NotifyPullRequestMergeabilityChecked(pr *issues_model.PullRequest) {
+	eventsource.SendIssueUpdate(pr.IssueID, eventsource.IssueUpdateMergeable, 0)
 }
 
 func (ns *notificationService) NotifyNewPullRequest(pr *issues_model.PullRequest, mentions []*user_model.User) {
@@ -162,6 +188,7 @@ func (ns *notificationService) NotifyPullRequestReview(pr *issues_model.PullRequ
 		opts.CommentID = c.ID
 	}
 	_ = ns.issueQueue.Push(opts)
+	eventsource.SendIssueUpdate(pr.Issue.ID, eventsource.IssueUpdateReview, opts.CommentID)
 	for _, mention := range mentions {
 		opts := issueNotificationOpts{
 			IssueID:              pr.Issue.ID,
@@ -176,6 +203,7 @@ func (ns *notificationService) NotifyPullRequestReview(pr *issues_model.PullRequ
 }
 
 func (ns *notificationService) NotifyPullRequestCodeComment(pr *issues_model.PullRequest, c *issues_model.Comment, mentions []*user_model.User) {
+	eventsource.SendIssueUpdate(pr.Issue.ID, eventsource.IssueUpdateReview, c.ID)
 	for _, mention := range mentions {
 		_ = ns.issueQueue.Push(issueNotificationOpts{
 			IssueID:              pr.Issue.ID,
@@ -193,6 +221,7 @@ func (ns *notificationService) NotifyPullRequestPushCommits(doer *user_model.Use
 		CommentID:            comment.ID,
 	}
 	_ = ns.issueQueue.Push(opts)
+	eventsource.SendIssueUpdate(pr.IssueID, eventsource.IssueUpdateComment, comment.ID)
 }
 
 func (ns *notificationService) NotifyPullRevieweDismiss(doer *user_model.User, review *issues_model.Review, comment *issues_model.Comment) {
@@ -202,6 +231,7 @@ func (ns *notificationService) NotifyPullRevieweDismiss(doer *user_model.User, r
 		CommentID:            comment.ID,
 	}
 	_ = ns.issueQueue.Push(opts)
+	eventsource.SendIssueUpdate(review.IssueID, eventsource.IssueUpdateReview, comment.ID)
 }
 
 func (ns *notificationService) NotifyIssueChangeAssignee(doer *user_model.User, issue *issues_model.Issue, assignee *user_model.User, removed bool, comment *issues_model.Comment) {
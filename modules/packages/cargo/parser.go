@@ -0,0 +1,198 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"regexp"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/hashicorp/go-version"
+)
+
+// PropertyYanked is the name of the package version property marking a yanked version
+const PropertyYanked = "cargo.yanked"
+
+var (
+	ErrInvalidName      = errors.New("Package name is invalid")
+	ErrInvalidVersion   = errors.New("Package version is invalid")
+	ErrMetadataTooLarge = errors.New("Package metadata is too large")
+)
+
+var namePattern = regexp.MustCompile(`\A[a-zA-Z][a-zA-Z0-9_-]{0,63}\z`)
+
+// maxMetadataSize limits the size of the JSON metadata of a publish request
+const maxMetadataSize = 256 * 1024
+
+// Package represents a Cargo package
+type Package struct {
+	Name        string
+	Version     string
+	Metadata    *Metadata
+	Content     io.Reader
+	ContentSize int64
+}
+
+// Metadata represents the metadata of a Cargo package
+type Metadata struct {
+	Description      string              `json:"description,omitempty"`
+	Authors          []string            `json:"authors,omitempty"`
+	ProjectURL       string              `json:"project_url,omitempty"`
+	RepositoryURL    string              `json:"repository_url,omitempty"`
+	DocumentationURL string              `json:"documentation_url,omitempty"`
+	Readme           string              `json:"readme,omitempty"`
+	Keywords         []string            `json:"keywords,omitempty"`
+	Categories       []string            `json:"categories,omitempty"`
+	License          string              `json:"license,omitempty"`
+	Links            string              `json:"links,omitempty"`
+	Dependencies     []*Dependency       `json:"dependencies,omitempty"`
+	Features         map[string][]string `json:"features,omitempty"`
+}
+
+// Dependency represents a dependency of a Cargo package in the form used by the index
+type Dependency struct {
+	Name            string   `json:"name"`
+	Req             string   `json:"req"`
+	Features        []string `json:"features"`
+	Optional        bool     `json:"optional"`
+	DefaultFeatures bool     `json:"default_features"`
+	Target          *string  `json:"target"`
+	Kind            string   `json:"kind"`
+	Registry        *string  `json:"registry"`
+	Package         *string  `json:"package"`
+}
+
+// https://doc.rust-lang.org/cargo/reference/registries.html#publish
+type publishMetadata struct {
+	Name          string              `json:"name"`
+	Vers          string              `json:"vers"`
+	Deps          []publishDependency `json:"deps"`
+	Features      map[string][]string `json:"features"`
+	Authors       []string            `json:"authors"`
+	Description   string              `json:"description"`
+	Documentation string              `json:"documentation"`
+	Homepage      string              `json:"homepage"`
+	Readme        string              `json:"readme"`
+	Keywords      []string            `json:"keywords"`
+	Categories    []string            `json:"categories"`
+	License       string              `json:"license"`
+	Repository    string              `json:"repository"`
+	Links         string              `json:"links"`
+}
+
+type publishDependency struct {
+	Name               string   `json:"name"`
+	VersionReq         string   `json:"version_req"`
+	Features           []string `json:"features"`
+	Optional           bool     `json:"optional"`
+	DefaultFeatures    bool     `json:"default_features"`
+	Target             *string  `json:"target"`
+	Kind               string   `json:"kind"`
+	Registry           *string  `json:"registry"`
+	ExplicitNameInToml string   `json:"explicit_name_in_toml"`
+}
+
+// ParsePackage reads the body of a publish request. It consists of the length
+// prefixed JSON metadata followed by the length prefixed .crate file.
+// Package.Content reads the .crate file from r.
+func ParsePackage(r io.Reader) (*Package, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > maxMetadataSize {
+		return nil, ErrMetadataTooLarge
+	}
+
+	p, err := parsePublishMetadata(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+
+	p.Content = io.LimitReader(r, int64(size))
+	p.ContentSize = int64(size)
+
+	return p, nil
+}
+
+func parsePublishMetadata(r io.Reader) (*Package, error) {
+	var meta publishMetadata
+	if err := json.NewDecoder(r).Decode(&meta); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(meta.Name) {
+		return nil, ErrInvalidName
+	}
+
+	v, err := version.NewSemver(meta.Vers)
+	if err != nil {
+		return nil, ErrInvalidVersion
+	}
+
+	if !validation.IsValidURL(meta.Homepage) {
+		meta.Homepage = ""
+	}
+	if !validation.IsValidURL(meta.Repository) {
+		meta.Repository = ""
+	}
+	if !validation.IsValidURL(meta.Documentation) {
+		meta.Documentation = ""
+	}
+
+	dependencies := make([]*Dependency, 0, len(meta.Deps))
+	for i := range meta.Deps {
+		dep := &meta.Deps[i]
+		// the index uses the name from Cargo.toml and stores the name of a renamed crate as package
+		name := dep.Name
+		var pkg *string
+		if dep.ExplicitNameInToml != "" {
+			pkg = &dep.Name
+			name = dep.ExplicitNameInToml
+		}
+		features := dep.Features
+		if features == nil {
+			features = []string{}
+		}
+		dependencies = append(dependencies, &Dependency{
+			Name:            name,
+			Req:             dep.VersionReq,
+			Features:        features,
+			Optional:        dep.Optional,
+			DefaultFeatures: dep.DefaultFeatures,
+			Target:          dep.Target,
+			Kind:            dep.Kind,
+			Registry:        dep.Registry,
+			Package:         pkg,
+		})
+	}
+
+	return &Package{
+		Name:    meta.Name,
+		Version: v.String(),
+		Metadata: &Metadata{
+			Description:      meta.Description,
+			Authors:          meta.Authors,
+			ProjectURL:       meta.Homepage,
+			RepositoryURL:    meta.Repository,
+			DocumentationURL: meta.Documentation,
+			Readme:           meta.Readme,
+			Keywords:         meta.Keywords,
+			Categories:       meta.Categories,
+			License:          meta.License,
+			Links:            meta.Links,
+			Dependencies:     dependencies,
+			Features:         meta.Features,
+		},
+	}, nil
+}
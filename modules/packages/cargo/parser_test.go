@@ -0,0 +1,100 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	description = "Package Description"
+	projectURL  = "https://gitea.io"
+	repoURL     = "https://gitea.io/gitea/gitea"
+)
+
+func createPublishRequest(metadata string, content []byte) io.Reader {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+	buf.WriteString(metadata)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(content)))
+	buf.Write(content)
+	return &buf
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "1abc", "a b", "a.b"} {
+			p, err := ParsePackage(createPublishRequest(`{"name":"`+name+`","vers":"1.0.0"}`, nil))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		p, err := ParsePackage(createPublishRequest(`{"name":"test","vers":"1.0.0.a"}`, nil))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidVersion)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		p, err := ParsePackage(bytes.NewReader([]byte{1, 2}))
+		assert.Nil(t, p)
+		assert.Error(t, err)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		content := []byte("crate")
+		p, err := ParsePackage(createPublishRequest(`{
+			"name": "test-crate",
+			"vers": "1.0.1",
+			"description": "`+description+`",
+			"homepage": "`+projectURL+`",
+			"repository": "`+repoURL+`",
+			"documentation": "not a url",
+			"authors": ["Gitea"],
+			"features": {"default": ["std"], "std": []},
+			"deps": [
+				{"name": "serde", "version_req": "^1.0", "features": ["derive"], "optional": false, "default_features": true, "target": null, "kind": "normal", "registry": null},
+				{"name": "original", "version_req": "0.1", "optional": true, "default_features": false, "kind": "dev", "explicit_name_in_toml": "renamed"}
+			]
+		}`, content))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, "test-crate", p.Name)
+		assert.Equal(t, "1.0.1", p.Version)
+		assert.Equal(t, description, p.Metadata.Description)
+		assert.Equal(t, projectURL, p.Metadata.ProjectURL)
+		assert.Equal(t, repoURL, p.Metadata.RepositoryURL)
+		assert.Empty(t, p.Metadata.DocumentationURL)
+		assert.Equal(t, []string{"Gitea"}, p.Metadata.Authors)
+		assert.Equal(t, []string{"std"}, p.Metadata.Features["default"])
+
+		assert.Len(t, p.Metadata.Dependencies, 2)
+		dep := p.Metadata.Dependencies[0]
+		assert.Equal(t, "serde", dep.Name)
+		assert.Equal(t, "^1.0", dep.Req)
+		assert.Equal(t, []string{"derive"}, dep.Features)
+		assert.True(t, dep.DefaultFeatures)
+		assert.Nil(t, dep.Package)
+		dep = p.Metadata.Dependencies[1]
+		assert.Equal(t, "renamed", dep.Name)
+		assert.Equal(t, "original", *dep.Package)
+		assert.Equal(t, "dev", dep.Kind)
+		assert.True(t, dep.Optional)
+		assert.Empty(t, dep.Features)
+		assert.NotNil(t, dep.Features)
+
+		assert.Equal(t, int64(len(content)), p.ContentSize)
+		data, err := io.ReadAll(p.Content)
+		assert.NoError(t, err)
+		assert.Equal(t, content, data)
+	})
+}
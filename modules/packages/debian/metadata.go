@@ -0,0 +1,254 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	PropertyDistribution = "debian.distribution"
+	PropertyComponent    = "debian.component"
+	PropertyArchitecture = "debian.architecture"
+	PropertyControl      = "debian.control"
+
+	// ArchitectureAll is used by packages which can be installed on every architecture
+	ArchitectureAll = "all"
+)
+
+var (
+	ErrMissingControlFile     = errors.New("Control file is missing")
+	ErrUnsupportedCompression = errors.New("Unsupported compression algorithm")
+	ErrInvalidName            = errors.New("Package name is invalid")
+	ErrInvalidVersion         = errors.New("Package version is invalid")
+	ErrInvalidArchitecture    = errors.New("Package architecture is invalid")
+
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#source
+	namePattern = regexp.MustCompile(`\A[a-z0-9][a-z0-9+.-]+\z`)
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+	versionPattern = regexp.MustCompile(`\A(?:[0-9]:)?[a-zA-Z0-9.+~]+(?:-[a-zA-Z0-9.+~-]+)?\z`)
+	// distributions, components and architectures are used as path segments
+	namePartPattern = regexp.MustCompile(`\A[a-z0-9][a-z0-9_.-]*\z`)
+)
+
+// maxControlFileSize limits the size of the control file read from a package
+const maxControlFileSize = 1 << 20
+
+// Package represents a Debian package
+type Package struct {
+	Name         string
+	Version      string
+	Architecture string
+	Control      string
+	Metadata     *Metadata
+}
+
+// Metadata represents the metadata of a Debian package
+type Metadata struct {
+	Maintainer   string   `json:"maintainer,omitempty"`
+	ProjectURL   string   `json:"project_url,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// IsValidNamePart checks if a distribution, component or architecture name is valid
+func IsValidNamePart(s string) bool {
+	return namePartPattern.MatchString(s)
+}
+
+// ParsePackage parses the Debian package file
+// https://manpages.debian.org/bullseye/dpkg-dev/deb.5.en.html
+func ParsePackage(r io.Reader) (*Package, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != "!<arch>\n" {
+		return nil, errors.New("invalid ar archive")
+	}
+
+	header := make([]byte, 60)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF {
+				return nil, ErrMissingControlFile
+			}
+			return nil, err
+		}
+		if header[58] != '`' || header[59] != '\n' {
+			return nil, errors.New("invalid ar file header")
+		}
+
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return nil, errors.New("invalid ar file size")
+		}
+
+		if strings.HasPrefix(name, "control.tar") {
+			var inner io.Reader = io.LimitReader(br, size)
+			switch strings.TrimPrefix(name, "control.tar") {
+			case "":
+			case ".gz":
+				gzr, err := gzip.NewReader(inner)
+				if err != nil {
+					return nil, err
+				}
+				defer gzr.Close()
+				inner = gzr
+			case ".xz":
+				xzr, err := xz.NewReader(inner)
+				if err != nil {
+					return nil, err
+				}
+				inner = xzr
+			case ".zst":
+				zr, err := zstd.NewReader(inner)
+				if err != nil {
+					return nil, err
+				}
+				defer zr.Close()
+				inner = zr
+			default:
+				return nil, ErrUnsupportedCompression
+			}
+			return parseControlArchive(inner)
+		}
+
+		// the data of an entry is padded to an even size
+		if _, err := io.CopyN(io.Discard, br, size+size%2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func parseControlArchive(r io.Reader) (*Package, error) {
+	tr := tar.NewReader(r)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			return nil, ErrMissingControlFile
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if strings.TrimPrefix(hd.Name, "./") == "control" {
+			return ParseControlFile(io.LimitReader(tr, maxControlFileSize))
+		}
+	}
+}
+
+// ParseControlFile parses a Debian control file to retrieve the metadata of a package
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#binary-package-control-files-debian-control
+func ParseControlFile(r io.Reader) (*Package, error) {
+	p := &Package{
+		Metadata: &Metadata{},
+	}
+
+	var control strings.Builder
+	var depends string
+	var key string
+	var description strings.Builder
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxControlFileSize)
+	for s.Scan() {
+		line := s.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			// continuation of the previous field
+			if key == "Description" {
+				description.WriteByte('\n')
+				if trimmed != "." {
+					description.WriteString(trimmed)
+				}
+			} else if key == "Depends" {
+				depends += " " + trimmed
+			}
+		} else {
+			parts := strings.SplitN(trimmed, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid control file line: %s", trimmed)
+			}
+			key = strings.TrimSpace(parts[0])
+			value := strings.TrimSpace(parts[1])
+			switch key {
+			case "Package":
+				p.Name = value
+			case "Version":
+				p.Version = value
+			case "Architecture":
+				p.Architecture = value
+			case "Maintainer":
+				a, err := mail.ParseAddress(value)
+				if err != nil || a.Name == "" {
+					p.Metadata.Maintainer = value
+				} else {
+					p.Metadata.Maintainer = a.Name
+				}
+			case "Description":
+				description.WriteString(value)
+			case "Homepage":
+				if validation.IsValidURL(value) {
+					p.Metadata.ProjectURL = value
+				}
+			case "Depends":
+				depends = value
+			}
+		}
+
+		control.WriteString(strings.TrimRight(line, " \t"))
+		control.WriteByte('\n')
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return nil, ErrInvalidName
+	}
+	if !versionPattern.MatchString(p.Version) {
+		return nil, ErrInvalidVersion
+	}
+	if !namePartPattern.MatchString(p.Architecture) {
+		return nil, ErrInvalidArchitecture
+	}
+
+	for _, dep := range strings.Split(depends, ",") {
+		if dep = strings.TrimSpace(dep); dep != "" {
+			p.Metadata.Dependencies = append(p.Metadata.Dependencies, dep)
+		}
+	}
+
+	p.Metadata.Description = description.String()
+	p.Control = strings.TrimSpace(control.String())
+
+	return p, nil
+}
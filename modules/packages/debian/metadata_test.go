@@ -0,0 +1,169 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	packageName    = "gitea"
+	packageVersion = "1:1.0.0-1~test+1"
+	packageArch    = "amd64"
+	maintainer     = "KN4CK3R"
+	projectURL     = "https://gitea.io"
+)
+
+const controlContent = `Package: ` + packageName + `
+Version: ` + packageVersion + `
+Architecture: ` + packageArch + `
+Maintainer: ` + maintainer + ` <dummy@gitea.io>
+Homepage: ` + projectURL + `
+Depends: a,
+ b (>= 1.0)
+Description: Package Description
+ with multiple
+ .
+ lines
+`
+
+func createArchive(name string, files map[string][]byte) []byte {
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	for filename, content := range files {
+		tw.WriteHeader(&tar.Header{
+			Name: filename,
+			Mode: 0o600,
+			Size: int64(len(content)),
+		})
+		tw.Write(content)
+	}
+	tw.Close()
+
+	var compressed bytes.Buffer
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		w := gzip.NewWriter(&compressed)
+		w.Write(tarBuf.Bytes())
+		w.Close()
+	case strings.HasSuffix(name, ".xz"):
+		w, _ := xz.NewWriter(&compressed)
+		w.Write(tarBuf.Bytes())
+		w.Close()
+	case strings.HasSuffix(name, ".zst"):
+		w, _ := zstd.NewWriter(&compressed)
+		w.Write(tarBuf.Bytes())
+		w.Close()
+	default:
+		compressed = tarBuf
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("!<arch>\n")
+	for _, entry := range []struct {
+		name    string
+		content []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{name, compressed.Bytes()},
+	} {
+		fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", entry.name, "0", "0", "0", "644", len(entry.content))
+		buf.Write(entry.content)
+		if len(entry.content)%2 != 0 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Run("InvalidArchive", func(t *testing.T) {
+		p, err := ParsePackage(bytes.NewReader([]byte("invalid archive")))
+		assert.Nil(t, p)
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingControlFile", func(t *testing.T) {
+		p, err := ParsePackage(bytes.NewReader(createArchive("control.tar", map[string][]byte{"dummy": {}})))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrMissingControlFile)
+	})
+
+	t.Run("UnsupportedCompression", func(t *testing.T) {
+		p, err := ParsePackage(bytes.NewReader(createArchive("control.tar.bz2", map[string][]byte{"./control": []byte(controlContent)})))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrUnsupportedCompression)
+	})
+
+	for _, name := range []string{"control.tar", "control.tar.gz", "control.tar.xz", "control.tar.zst"} {
+		t.Run(name, func(t *testing.T) {
+			p, err := ParsePackage(bytes.NewReader(createArchive(name, map[string][]byte{"./control": []byte(controlContent)})))
+			assert.NoError(t, err)
+			assert.NotNil(t, p)
+			assert.Equal(t, packageName, p.Name)
+			assert.Equal(t, packageVersion, p.Version)
+		})
+	}
+}
+
+func TestParseControlFile(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "-cd", "a", "a_b", "A"} {
+			p, err := ParseControlFile(strings.NewReader("Package: " + name + "\nVersion: 1.0\nArchitecture: all\n"))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName, name)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "1 0", "1_0", "a:1.0"} {
+			p, err := ParseControlFile(strings.NewReader("Package: test\nVersion: " + version + "\nArchitecture: all\n"))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion, version)
+		}
+	})
+
+	t.Run("InvalidArchitecture", func(t *testing.T) {
+		for _, arch := range []string{"", "a/b", "../x"} {
+			p, err := ParseControlFile(strings.NewReader("Package: test\nVersion: 1.0\nArchitecture: " + arch + "\n"))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidArchitecture, arch)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParseControlFile(io.MultiReader(strings.NewReader(controlContent), strings.NewReader("\n")))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, packageArch, p.Architecture)
+		assert.Equal(t, maintainer, p.Metadata.Maintainer)
+		assert.Equal(t, projectURL, p.Metadata.ProjectURL)
+		assert.Equal(t, "Package Description\nwith multiple\n\nlines", p.Metadata.Description)
+		assert.Equal(t, []string{"a", "b (>= 1.0)"}, p.Metadata.Dependencies)
+		assert.Equal(t, strings.TrimSpace(controlContent), p.Control)
+	})
+}
+
+func TestIsValidNamePart(t *testing.T) {
+	assert.True(t, IsValidNamePart("bullseye"))
+	assert.True(t, IsValidNamePart("main"))
+	assert.True(t, IsValidNamePart("linux-amd64"))
+	assert.False(t, IsValidNamePart(""))
+	assert.False(t, IsValidNamePart(".."))
+	assert.False(t, IsValidNamePart("a/b"))
+}
@@ -0,0 +1,141 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	modzip "golang.org/x/mod/zip"
+)
+
+var (
+	ErrInvalidStructure = errors.New("Module archive has an invalid structure")
+	ErrInvalidModFile   = errors.New("Module go.mod file is invalid")
+	ErrInvalidPath      = errors.New("Module path is invalid")
+	ErrInvalidVersion   = errors.New("Module version is invalid")
+)
+
+const maxReadmeSize = 1 << 20
+
+// Package represents a Go module
+type Package struct {
+	Name     string
+	Version  string
+	Metadata *Metadata
+}
+
+// Metadata represents the metadata of a Go module
+type Metadata struct {
+	GoMod  string `json:"go_mod"`
+	Readme string `json:"readme,omitempty"`
+}
+
+// ParsePackage parses the module zip file as specified by
+// https://go.dev/ref/mod#zip-files
+func ParsePackage(r io.ReaderAt, size int64) (*Package, error) {
+	if size > modzip.MaxZipFile {
+		return nil, ErrInvalidStructure
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(archive.File) == 0 {
+		return nil, ErrInvalidStructure
+	}
+
+	// every file is located in the directory "{module path}@{version}/"
+	modulePath, rest, ok := strings.Cut(archive.File[0].Name, "@")
+	if !ok {
+		return nil, ErrInvalidStructure
+	}
+	version, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return nil, ErrInvalidStructure
+	}
+	prefix := modulePath + "@" + version
+	if err := module.CheckPath(modulePath); err != nil {
+		return nil, ErrInvalidPath
+	}
+	if !IsValidVersion(version) {
+		return nil, ErrInvalidVersion
+	}
+	if err := module.Check(modulePath, version); err != nil {
+		return nil, ErrInvalidVersion
+	}
+
+	p := &Package{
+		Name:     modulePath,
+		Version:  version,
+		Metadata: &Metadata{},
+	}
+
+	var hasGoMod bool
+	for _, file := range archive.File {
+		name := strings.TrimPrefix(file.Name, prefix+"/")
+		if name == file.Name {
+			return nil, ErrInvalidStructure
+		}
+		if err := module.CheckFilePath(name); err != nil {
+			return nil, ErrInvalidStructure
+		}
+
+		switch {
+		case name == "go.mod":
+			if file.UncompressedSize64 > modzip.MaxGoMod {
+				return nil, ErrInvalidModFile
+			}
+			content, err := readFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if modfile.ModulePath(content) != modulePath {
+				return nil, ErrInvalidModFile
+			}
+			p.Metadata.GoMod = string(content)
+			hasGoMod = true
+		case strings.EqualFold(name, "readme.md") && file.UncompressedSize64 <= maxReadmeSize:
+			content, err := readFile(file)
+			if err != nil {
+				return nil, err
+			}
+			p.Metadata.Readme = string(content)
+		}
+	}
+
+	if !hasGoMod {
+		p.Metadata.GoMod = SynthesizeGoMod(modulePath)
+	}
+
+	return p, nil
+}
+
+// IsValidVersion checks if the version is a canonical semantic version
+func IsValidVersion(version string) bool {
+	return semver.IsValid(version) && semver.Canonical(version) == strings.TrimSuffix(version, "+incompatible")
+}
+
+// SynthesizeGoMod returns the go.mod file which the go command uses for modules without one
+func SynthesizeGoMod(modulePath string) string {
+	return fmt.Sprintf("module %s\n", modfile.AutoQuote(modulePath))
+}
+
+func readFile(file *zip.File) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	modulePath    = "gitea.io/go/module"
+	moduleVersion = "v1.2.3"
+	goMod         = "module " + modulePath + "\n\ngo 1.18\n"
+	readme        = "# Module"
+)
+
+func createArchive(files map[string]string) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestParsePackage(t *testing.T) {
+	prefix := modulePath + "@" + moduleVersion + "/"
+
+	parse := func(files map[string]string) (*Package, error) {
+		r := createArchive(files)
+		return ParsePackage(r, r.Size())
+	}
+
+	t.Run("InvalidStructure", func(t *testing.T) {
+		for _, files := range []map[string]string{
+			{"main.go": ""},
+			{prefix + "main.go": "", "gitea.io/other@v1.0.0/main.go": ""},
+			{modulePath + "@" + moduleVersion: ""},
+		} {
+			p, err := parse(files)
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidStructure)
+		}
+	})
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		p, err := parse(map[string]string{"invalid@" + moduleVersion + "/main.go": ""})
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"1.2.3", "v1.2", "v2.0.0"} {
+			p, err := parse(map[string]string{modulePath + "@" + version + "/main.go": ""})
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion, version)
+		}
+	})
+
+	t.Run("InvalidModFile", func(t *testing.T) {
+		p, err := parse(map[string]string{prefix + "go.mod": "module other.io/module\n"})
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidModFile)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := parse(map[string]string{
+			prefix + "go.mod":    goMod,
+			prefix + "README.md": readme,
+			prefix + "main.go":   "package main",
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, modulePath, p.Name)
+		assert.Equal(t, moduleVersion, p.Version)
+		assert.Equal(t, goMod, p.Metadata.GoMod)
+		assert.Equal(t, readme, p.Metadata.Readme)
+	})
+
+	t.Run("MissingModFile", func(t *testing.T) {
+		p, err := parse(map[string]string{prefix + "main.go": "package main"})
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, "module "+modulePath+"\n", p.Metadata.GoMod)
+	})
+}
+
+func TestIsValidVersion(t *testing.T) {
+	assert.True(t, IsValidVersion("v1.0.0"))
+	assert.True(t, IsValidVersion("v1.0.0-beta.1"))
+	assert.True(t, IsValidVersion("v2.0.0+incompatible"))
+	assert.False(t, IsValidVersion("v1.0"))
+	assert.False(t, IsValidVersion("1.0.0"))
+	assert.False(t, IsValidVersion("v1.0.0+build"))
+}
@@ -57,6 +57,7 @@ type HookOptions struct {
 	GitPushOptions                  GitPushOptions
 	PullRequestID                   int64
 	DeployKeyID                     int64 // if the pusher is a DeployKey, then UserID is the repo's org user.
+	DeployTokenID                   int64 // if the pusher is a DeployToken, then UserID is the repo's owner.
 	IsWiki                          bool
 }
 
@@ -0,0 +1,100 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+// Filter is an equality filter like `userName eq "alice"`.
+// Identity providers only use equality filters to look up resources, so other operators are not supported.
+type Filter struct {
+	// Attribute is the lower cased attribute path, e.g. "username" or "emails.value"
+	Attribute string
+	Value     string
+}
+
+var filterPattern = regexp.MustCompile(`^\s*([A-Za-z][\w.:]*)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// ParseFilter parses a filter, an empty filter matches all resources and is returned as nil
+func ParseFilter(filter string) (*Filter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	m := filterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidFilter, "unsupported filter: %s", filter)
+	}
+
+	var value string
+	if err := json.Unmarshal([]byte(m[2]), &value); err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidFilter, "invalid filter value: %s", m[2])
+	}
+
+	return &Filter{
+		Attribute: normalizeAttribute(m[1]),
+		Value:     value,
+	}, nil
+}
+
+// Path is the target of a patch operation like `name.givenName` or `members[value eq "2"]`
+type Path struct {
+	// Attribute is the lower cased attribute name, it includes the sub-attribute if there is no value filter
+	Attribute string
+	// Filter selects the values of a multi-valued attribute, its attribute is relative to the values
+	Filter *Filter
+	// SubAttribute is the lower cased sub-attribute of the filtered values
+	SubAttribute string
+}
+
+// ParsePath parses the path of a patch operation
+func ParsePath(path string) (*Path, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return &Path{}, nil
+	}
+
+	open := strings.IndexByte(path, '[')
+	if open == -1 {
+		return &Path{Attribute: normalizeAttribute(path)}, nil
+	}
+
+	end := strings.LastIndexByte(path, ']')
+	if end < open {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "invalid path: %s", path)
+	}
+	filter, err := ParseFilter(path[open+1 : end])
+	if err != nil || filter == nil {
+		return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "unsupported path: %s", path)
+	}
+
+	p := &Path{
+		Attribute: normalizeAttribute(path[:open]),
+		Filter:    filter,
+	}
+	if rest := path[end+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") {
+			return nil, NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "invalid path: %s", path)
+		}
+		p.SubAttribute = strings.ToLower(rest[1:])
+	}
+	return p, nil
+}
+
+// normalizeAttribute removes the core schema prefix of an attribute and lower cases it because attribute names are case insensitive
+func normalizeAttribute(attr string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(attr) > len(schema) && strings.EqualFold(attr[:len(schema)+1], schema+":") {
+			attr = attr[len(schema)+1:]
+			break
+		}
+	}
+	return strings.ToLower(attr)
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	f, err := ParseFilter("")
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+
+	cases := map[string]Filter{
+		`userName eq "alice"`:                                          {Attribute: "username", Value: "alice"},
+		` displayName EQ "Dev \"Ops\""`:                                {Attribute: "displayname", Value: `Dev "Ops"`},
+		`emails.value eq "a@b.c"`:                                      {Attribute: "emails.value", Value: "a@b.c"},
+		`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bob"`: {Attribute: "username", Value: "bob"},
+	}
+	for filter, expected := range cases {
+		f, err := ParseFilter(filter)
+		assert.NoError(t, err, filter)
+		assert.Equal(t, expected, *f, filter)
+	}
+
+	for _, filter := range []string{
+		`userName sw "al"`,
+		`userName eq alice`,
+		`userName eq "alice" and active eq "true"`,
+	} {
+		_, err := ParseFilter(filter)
+		assert.Error(t, err, filter)
+		assert.Equal(t, ErrorTypeInvalidFilter, err.(*Error).ScimType)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	cases := map[string]Path{
+		"":               {},
+		"active":         {Attribute: "active"},
+		"name.givenName": {Attribute: "name.givenname"},
+		`members[value eq "2"]`: {
+			Attribute: "members",
+			Filter:    &Filter{Attribute: "value", Value: "2"},
+		},
+		`emails[type eq "work"].value`: {
+			Attribute:    "emails",
+			Filter:       &Filter{Attribute: "type", Value: "work"},
+			SubAttribute: "value",
+		},
+	}
+	for path, expected := range cases {
+		p, err := ParsePath(path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expected, *p, path)
+	}
+
+	for _, path := range []string{
+		`members[value eq "2"`,
+		`members[value gt "2"]`,
+		`emails[type eq "work"]value`,
+	} {
+		_, err := ParsePath(path)
+		assert.Error(t, err, path)
+	}
+}
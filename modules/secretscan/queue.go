@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secretscan
+
+import (
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+var scanQueue queue.Queue
+
+// ScanRequest asks to scan the files added by the commits of a ref update
+type ScanRequest struct {
+	RepoID      int64
+	RefName     string
+	OldCommitID string
+	NewCommitID string
+}
+
+// StartScanning starts the queue handling the scan requests
+func StartScanning(queueHandle func(data ...queue.Data) []queue.Data) {
+	if !setting.SecretScanning.Enabled {
+		return
+	}
+	scanQueue = queue.CreateQueue("secret_scanning", queueHandle, new(ScanRequest))
+
+	go graceful.GetManager().RunWithShutdownFns(scanQueue.Run)
+}
+
+// AddScanToQueue queues the scan of a ref update
+func AddScanToQueue(req *ScanRequest) {
+	if !setting.SecretScanning.Enabled || scanQueue == nil {
+		return
+	}
+	if err := scanQueue.Push(req); err != nil {
+		log.Error("Unable to push scan request for repo[%d] to the queue: %v", req.RepoID, err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secretscan
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// RuleGiteaToken is the ID of the rule detecting the access tokens and deploy tokens of Gitea.
+// They can't be told apart from the other SHA1 sums, so its matches must be verified.
+const RuleGiteaToken = "gitea_token"
+
+// Rule detects a kind of credential
+type Rule struct {
+	ID   string
+	Name string
+	// the secret is the first group of the match, or the whole match if there is no group
+	Regexp *regexp.Regexp
+}
+
+// Rules are the rules of the known credentials
+var Rules = []*Rule{
+	{ID: "aws_access_key_id", Name: "AWS access key ID", Regexp: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{ID: "aws_secret_access_key", Name: "AWS secret access key", Regexp: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([0-9A-Za-z/+]{40})\b`)},
+	{ID: "google_api_key", Name: "Google API key", Regexp: regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})`)},
+	{ID: "azure_storage_account_key", Name: "Azure storage account key", Regexp: regexp.MustCompile(`AccountKey=([0-9A-Za-z+/]{86}==)`)},
+	{ID: "private_key", Name: "Private key", Regexp: regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{ID: "github_token", Name: "GitHub token", Regexp: regexp.MustCompile(`\b(gh[pousr]_[0-9A-Za-z]{36}|github_pat_[0-9A-Za-z_]{82})\b`)},
+	{ID: "gitlab_token", Name: "GitLab personal access token", Regexp: regexp.MustCompile(`\b(glpat-[0-9A-Za-z_\-]{20})\b`)},
+	{ID: "slack_token", Name: "Slack token", Regexp: regexp.MustCompile(`\b(xox[abposr]-[0-9A-Za-z\-]{10,})`)},
+	{ID: "slack_webhook", Name: "Slack webhook URL", Regexp: regexp.MustCompile(`(https://hooks\.slack\.com/services/T[0-9A-Za-z_]+/B[0-9A-Za-z_]+/[0-9A-Za-z_]+)`)},
+	{ID: "stripe_secret_key", Name: "Stripe secret key", Regexp: regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{24,})\b`)},
+	{ID: "npm_token", Name: "npm access token", Regexp: regexp.MustCompile(`\b(npm_[0-9A-Za-z]{36})\b`)},
+	{ID: "pypi_token", Name: "PyPI API token", Regexp: regexp.MustCompile(`\b(pypi-AgEIcHlwaS5vcmc[0-9A-Za-z_\-]{50,})`)},
+	{ID: RuleGiteaToken, Name: "Gitea token", Regexp: regexp.MustCompile(`\b([0-9a-f]{40})\b`)},
+}
+
+// GetRule returns the rule with the ID, nil if there is none
+func GetRule(id string) *Rule {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Finding is a secret found in a file
+type Finding struct {
+	Rule   *Rule
+	Secret string
+	Line   int
+}
+
+// Scan returns the secrets found in the content of a text file, each secret is only reported at its first line
+func Scan(content []byte) []*Finding {
+	var findings []*Finding
+	seen := make(map[string]bool)
+	for i, line := range bytes.Split(content, []byte{'\n'}) {
+		for _, rule := range Rules {
+			for _, match := range rule.Regexp.FindAllSubmatch(line, -1) {
+				secret := match[0]
+				if len(match) > 1 {
+					secret = match[1]
+				}
+				key := rule.ID + "\x00" + string(secret)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				findings = append(findings, &Finding{Rule: rule, Secret: string(secret), Line: i + 1})
+			}
+		}
+	}
+	return findings
+}
+
+// Redact hides a secret but the beginning and the end of the long ones, so that it can be recognized
+func Redact(secret string) string {
+	if len(secret) < 16 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-8) + secret[len(secret)-4:]
+}
@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Audit settings
+var (
+	Audit = struct {
+		Enabled         bool
+		RetentionPeriod time.Duration
+	}{
+		Enabled:         true,
+		RetentionPeriod: 0,
+	}
+)
+
+func newAuditService() {
+	if err := Cfg.Section("audit").MapTo(&Audit); err != nil {
+		log.Fatal("Failed to map Audit settings: %v", err)
+	}
+}
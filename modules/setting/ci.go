@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// CI settings
+var (
+	CI = struct {
+		Enabled       bool
+		RunnerTimeout time.Duration
+	}{
+		Enabled:       false,
+		RunnerTimeout: 10 * time.Minute,
+	}
+)
+
+func newCI() {
+	if err := Cfg.Section("ci").MapTo(&CI); err != nil {
+		log.Fatal("Failed to map CI settings: %v", err)
+	}
+}
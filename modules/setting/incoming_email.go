@@ -0,0 +1,59 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IncomingEmailTokenPlaceholder is replaced by the reply token in the reply-to address
+const IncomingEmailTokenPlaceholder = "%{token}"
+
+// IncomingEmail settings
+var IncomingEmail = struct {
+	Enabled            bool
+	ReplyToAddress     string
+	ListenAddr         string
+	MaximumMessageSize int64
+}{
+	Enabled:            false,
+	ReplyToAddress:     "",
+	ListenAddr:         "127.0.0.1:2424",
+	MaximumMessageSize: 10485760,
+}
+
+func newIncomingEmail() {
+	if err := Cfg.Section("email.incoming").MapTo(&IncomingEmail); err != nil {
+		log.Fatal("Failed to map incoming email settings: %v", err)
+	}
+	if !IncomingEmail.Enabled {
+		return
+	}
+
+	if err := checkReplyToAddress(IncomingEmail.ReplyToAddress); err != nil {
+		log.Fatal("Invalid incoming email REPLY_TO_ADDRESS (%s): %v", IncomingEmail.ReplyToAddress, err)
+	}
+}
+
+func checkReplyToAddress(address string) error {
+	addr, err := mail.ParseAddress(address)
+	if err != nil {
+		return err
+	}
+	if addr.Name != "" {
+		return errors.New("the address must not contain a display name")
+	}
+	if strings.Count(address, IncomingEmailTokenPlaceholder) != 1 {
+		return errors.New("the address must contain the " + IncomingEmailTokenPlaceholder + " placeholder exactly once")
+	}
+	if !strings.Contains(address[:strings.LastIndex(address, "@")], IncomingEmailTokenPlaceholder) {
+		return errors.New("the " + IncomingEmailTokenPlaceholder + " placeholder must be in the local part of the address")
+	}
+	return nil
+}
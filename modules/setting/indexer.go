@@ -31,6 +31,10 @@ var Indexer = struct {
 	IncludePatterns    []glob.Glob
 	ExcludePatterns    []glob.Glob
 	ExcludeVendored    bool
+
+	WikiIndexerEnabled bool
+	WikiPath           string
+	WikiIndexerName    string
 }{
 	IssueType:        "bleve",
 	IssuePath:        "indexers/issues.bleve",
@@ -44,6 +48,10 @@ var Indexer = struct {
 	RepoIndexerName:    "gitea_codes",
 	MaxIndexerFileSize: 1024 * 1024,
 	ExcludeVendored:    true,
+
+	WikiIndexerEnabled: false,
+	WikiPath:           "indexers/wikis.bleve",
+	WikiIndexerName:    "gitea_wikis",
 }
 
 func newIndexerService() {
@@ -77,6 +85,14 @@ func newIndexerService() {
 	Indexer.ExcludePatterns = IndexerGlobFromString(sec.Key("REPO_INDEXER_EXCLUDE").MustString(""))
 	Indexer.ExcludeVendored = sec.Key("REPO_INDEXER_EXCLUDE_VENDORED").MustBool(true)
 	Indexer.MaxIndexerFileSize = sec.Key("MAX_FILE_SIZE").MustInt64(1024 * 1024)
+
+	// the wikis are indexed with the same backend as the code, in a separate index
+	Indexer.WikiIndexerEnabled = sec.Key("WIKI_INDEXER_ENABLED").MustBool(false)
+	Indexer.WikiPath = filepath.ToSlash(sec.Key("WIKI_INDEXER_PATH").MustString(filepath.ToSlash(filepath.Join(AppDataPath, "indexers/wikis.bleve"))))
+	if !filepath.IsAbs(Indexer.WikiPath) {
+		Indexer.WikiPath = filepath.ToSlash(filepath.Join(AppWorkPath, Indexer.WikiPath))
+	}
+	Indexer.WikiIndexerName = sec.Key("WIKI_INDEXER_NAME").MustString("gitea_wikis")
 	Indexer.StartupTimeout = sec.Key("STARTUP_TIMEOUT").MustDuration(30 * time.Second)
 }
 
@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/modules/log"
+)
+
+// SecretScanning settings
+var (
+	SecretScanning = struct {
+		Enabled          bool
+		MaxFileSize      int64
+		AutoRevokeTokens bool
+	}{
+		Enabled:          false,
+		MaxFileSize:      1024 * 1024,
+		AutoRevokeTokens: true,
+	}
+)
+
+func newSecretScanning() {
+	if err := Cfg.Section("secret_scanning").MapTo(&SecretScanning); err != nil {
+		log.Fatal("Failed to map secret scanning settings: %v", err)
+	}
+}
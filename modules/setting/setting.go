@@ -1025,6 +1025,12 @@ func loadFromConf(allowEmpty bool, extraConfig string) {
 
 	newPackages()
 
+	newCI()
+
+	newSCIM()
+
+	newSecretScanning()
+
 	if err = Cfg.Section("ui").MapTo(&UI); err != nil {
 		log.Fatal("Failed to map UI settings: %v", err)
 	} else if err = Cfg.Section("markdown").MapTo(&Markdown); err != nil {
@@ -1277,6 +1283,7 @@ func NewServices() {
 	newSessionService()
 	newCORSService()
 	newMailService()
+	newIncomingEmail()
 	newRegisterMailService()
 	newNotifyMailService()
 	newProxyService()
@@ -1288,6 +1295,7 @@ func NewServices() {
 	newProject()
 	newMimeTypeMap()
 	newFederationService()
+	newAuditService()
 }
 
 // NewServicesForInstall initializes the services for install
@@ -0,0 +1,68 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// App represents an app which organizations install on their repositories
+type App struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HomepageURL string `json:"homepage_url"`
+	// the user the app acts with
+	Bot *User `json:"bot"`
+	// the scopes of the installation tokens
+	Permissions []string `json:"permissions"`
+	// the URL receiving the events of the repositories the app is installed on
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// the PEM encoded private key the app signs its JWTs with, only returned once after its creation
+	PrivateKey string `json:"private_key,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateAppOption options for creating an app
+type CreateAppOption struct {
+	// required: true
+	Name        string `json:"name" binding:"Required;AlphaDashDot;MaxSize(36)"`
+	Description string `json:"description" binding:"MaxSize(255)"`
+	HomepageURL string `json:"homepage_url" binding:"ValidUrl"`
+	// the scopes of the installation tokens, only the repository, issue, package and organization scopes are allowed
+	// required: true
+	Permissions []string `json:"permissions" binding:"Required"`
+	// the URL receiving all the events of the repositories the app is installed on
+	WebhookURL    string `json:"webhook_url" binding:"ValidUrl"`
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// AppInstallation represents an app installed by an organization
+type AppInstallation struct {
+	ID    int64 `json:"id"`
+	App   *App  `json:"app"`
+	Owner *User `json:"owner"`
+	// whether the app is installed on all the repositories of the organization
+	AllRepositories bool `json:"all_repositories"`
+	// the names of the repositories the app is installed on, empty if installed on all of them
+	Repositories []string `json:"repositories"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// InstallAppOption options for installing an app on an organization
+type InstallAppOption struct {
+	// whether to install the app on all the repositories of the organization
+	AllRepositories bool `json:"all_repositories"`
+	// the names of the repositories to install the app on if not on all of them
+	Repositories []string `json:"repositories"`
+}
+
+// InstallationToken represents a short-lived access token of an app on an installation
+type InstallationToken struct {
+	Token       string   `json:"token"`
+	Permissions []string `json:"permissions"`
+	// swagger:strfmt date-time
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// AuditObject identifies the scope or the target of an audit event
+type AuditObject struct {
+	// enum: system,user,organization,repository,team,branch_protection,push_policy,access_token,deploy_token,app,webauthn_credential,webhook
+	Type string `json:"type"`
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// AuditEvent represents an entry of the audit log
+type AuditEvent struct {
+	ID        int64  `json:"id"`
+	Action    string `json:"action"`
+	ActorID   int64  `json:"actor_id"`
+	ActorName string `json:"actor_name"`
+	// the repository, organization or user in which the change happened
+	Scope *AuditObject `json:"scope"`
+	// the changed object
+	Target *AuditObject `json:"target"`
+	// JSON encoded state of the target before the change, empty if it was created
+	Before string `json:"before"`
+	// JSON encoded state of the target after the change, empty if it was deleted
+	After     string `json:"after"`
+	IPAddress string `json:"ip_address"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// DeployToken represents a deploy token of a repository or of an organization
+type DeployToken struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// the value of the token, only returned once after its creation
+	Token          string `json:"token,omitempty"`
+	TokenLastEight string `json:"token_last_eight"`
+	ReadOnly       bool   `json:"read_only"`
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateDeployTokenOption options for creating a deploy token
+type CreateDeployTokenOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// whether the token can push and publish packages, read-only by default
+	ReadOnly *bool `json:"read_only"`
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -35,6 +35,7 @@ type HookList []*Hook
 
 // CreateHookOptionConfig has all config options in it
 // required are "content_type" and "url" Required
+// "headers" holds additional request headers, one "Name: value" per line, which can reference secrets
 type CreateHookOptionConfig map[string]string
 
 // CreateHookOption options when create a hook
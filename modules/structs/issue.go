@@ -123,16 +123,113 @@ type IssueDeadline struct {
 // IssueTemplate represents an issue template for a repository
 // swagger:model
 type IssueTemplate struct {
-	Name     string   `json:"name" yaml:"name"`
-	Title    string   `json:"title" yaml:"title"`
-	About    string   `json:"about" yaml:"about"`
-	Labels   []string `json:"labels" yaml:"labels"`
-	Ref      string   `json:"ref" yaml:"ref"`
-	Content  string   `json:"content" yaml:"-"`
-	FileName string   `json:"file_name" yaml:"-"`
+	Name   string   `json:"name" yaml:"name"`
+	Title  string   `json:"title" yaml:"title"`
+	About  string   `json:"about" yaml:"about"`
+	Labels []string `json:"labels" yaml:"labels"`
+	Ref    string   `json:"ref" yaml:"ref"`
+	// the content of a markdown template
+	Content string `json:"content" yaml:"-"`
+	// the fields of an issue form
+	Fields   []*IssueFormField `json:"body" yaml:"body"`
+	FileName string            `json:"file_name" yaml:"-"`
 }
 
 // Valid checks whether an IssueTemplate is considered valid, e.g. at least name and about
 func (it IssueTemplate) Valid() bool {
 	return strings.TrimSpace(it.Name) != "" && strings.TrimSpace(it.About) != ""
 }
+
+// IsForm returns true if the template is an issue form instead of a markdown template
+func (it IssueTemplate) IsForm() bool {
+	return len(it.Fields) > 0
+}
+
+// IssueFormFieldType is the type of a field of an issue form
+type IssueFormFieldType string
+
+// enumerate all issue form field types
+const (
+	IssueFormFieldTypeMarkdown   IssueFormFieldType = "markdown"   // text shown in the form, not part of the issue
+	IssueFormFieldTypeTextarea   IssueFormFieldType = "textarea"   // multi-line text
+	IssueFormFieldTypeInput      IssueFormFieldType = "input"      // single-line text
+	IssueFormFieldTypeDropdown   IssueFormFieldType = "dropdown"   // selection of one or more options
+	IssueFormFieldTypeCheckboxes IssueFormFieldType = "checkboxes" // list of checkboxes
+)
+
+// IssueFormField is a field of an issue form
+// swagger:model
+type IssueFormField struct {
+	// enum: markdown,textarea,input,dropdown,checkboxes
+	Type        IssueFormFieldType        `json:"type" yaml:"type"`
+	ID          string                    `json:"id" yaml:"id"`
+	Attributes  IssueFormFieldAttributes  `json:"attributes" yaml:"attributes"`
+	Validations IssueFormFieldValidations `json:"validations" yaml:"validations"`
+}
+
+// IssueFormFieldAttributes are the attributes of a field of an issue form
+type IssueFormFieldAttributes struct {
+	Label       string `json:"label,omitempty" yaml:"label"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Placeholder string `json:"placeholder,omitempty" yaml:"placeholder"`
+	// the default value of an input or a textarea, the text of a markdown field
+	Value string `json:"value,omitempty" yaml:"value"`
+	// the language of a textarea, its value is rendered as code block
+	Render string `json:"render,omitempty" yaml:"render"`
+	// whether more than one option of a dropdown can be selected
+	Multiple bool `json:"multiple,omitempty" yaml:"multiple"`
+	// the index of the option of a dropdown selected by default
+	Default *int                    `json:"default,omitempty" yaml:"default"`
+	Options []*IssueFormFieldOption `json:"options,omitempty" yaml:"options"`
+}
+
+// IsDefault returns true if the option of a dropdown is selected by default
+func (a IssueFormFieldAttributes) IsDefault(idx int) bool {
+	return a.Default != nil && *a.Default == idx
+}
+
+// IssueFormFieldOption is an option of a dropdown or a checkboxes field
+type IssueFormFieldOption struct {
+	Label string `json:"label" yaml:"label"`
+	// whether the checkbox must be checked
+	Required bool `json:"required,omitempty" yaml:"required"`
+}
+
+// UnmarshalYAML accepts the options of a dropdown, which are plain strings
+func (o *IssueFormFieldOption) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&o.Label); err == nil {
+		return nil
+	}
+	type option IssueFormFieldOption
+	return unmarshal((*option)(o))
+}
+
+// IssueFormFieldValidations are the validations of a value of a field of an issue form
+type IssueFormFieldValidations struct {
+	Required bool `json:"required,omitempty" yaml:"required"`
+	// the value of an input must be a number
+	IsNumber bool `json:"is_number,omitempty" yaml:"is_number"`
+	// the value of an input must match the regular expression
+	Regex string `json:"regex,omitempty" yaml:"regex"`
+}
+
+// IssueConfigContactLink is a link shown in the list of the issue templates
+type IssueConfigContactLink struct {
+	Name  string `json:"name" yaml:"name"`
+	URL   string `json:"url" yaml:"url"`
+	About string `json:"about" yaml:"about"`
+}
+
+// IssueConfig is the configuration of the issue templates of a repository
+// swagger:model
+type IssueConfig struct {
+	BlankIssuesEnabled bool                      `json:"blank_issues_enabled" yaml:"blank_issues_enabled"`
+	ContactLinks       []*IssueConfigContactLink `json:"contact_links" yaml:"contact_links"`
+}
+
+// IssueConfigValidation is the result of the validation of the issue config and the issue templates
+// swagger:model
+type IssueConfigValidation struct {
+	Valid   bool   `json:"valid"`
+	Message string `json:"message"`
+}
@@ -4,22 +4,54 @@
 
 package structs
 
+import "time"
+
 // CreatePushMirrorOption represents need information to create a push mirror of a repository.
 type CreatePushMirrorOption struct {
 	RemoteAddress  string `json:"remote_address"`
 	RemoteUsername string `json:"remote_username"`
 	RemotePassword string `json:"remote_password"`
 	Interval       string `json:"interval"`
+	// push to the mirror on every push to the repository
+	SyncOnCommit bool `json:"sync_on_commit"`
+	// semicolon separated list of ref patterns, e.g. "main;v*", all branches and tags are pushed if empty
+	RefFilter string `json:"ref_filter"`
+	// push the refs without force, rewritten and deleted refs are not pushed
+	ProtectForcePush bool `json:"protect_force_push"`
 }
 
 // PushMirror represents information of a push mirror
 // swagger:model
 type PushMirror struct {
-	RepoName       string `json:"repo_name"`
-	RemoteName     string `json:"remote_name"`
-	RemoteAddress  string `json:"remote_address"`
-	CreatedUnix    string `json:"created"`
-	LastUpdateUnix string `json:"last_update"`
-	LastError      string `json:"last_error"`
-	Interval       string `json:"interval"`
+	RepoName         string `json:"repo_name"`
+	RemoteName       string `json:"remote_name"`
+	RemoteAddress    string `json:"remote_address"`
+	CreatedUnix      string `json:"created"`
+	LastUpdateUnix   string `json:"last_update"`
+	LastError        string `json:"last_error"`
+	Interval         string `json:"interval"`
+	SyncOnCommit     bool   `json:"sync_on_commit"`
+	RefFilter        string `json:"ref_filter"`
+	ProtectForcePush bool   `json:"protect_force_push"`
+}
+
+// PushMirrorRef represents the sync status of a ref of a push mirror
+type PushMirrorRef struct {
+	RefName string `json:"ref_name"`
+	// the commit of the last successful push
+	CommitID string `json:"commit_id"`
+	// enum: synced,rejected,failed
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated"`
+}
+
+// PushMirrorSyncError represents an error of a push mirror sync
+type PushMirrorSyncError struct {
+	// the ref which could not be pushed, empty if the whole sync failed
+	RefName string `json:"ref_name"`
+	Error   string `json:"error"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created"`
 }
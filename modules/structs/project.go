@@ -0,0 +1,90 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Project represents a project board of a repository, an individual or an organization
+type Project struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// enum: individual,repository,organization
+	Type string `json:"type"`
+	// the individual or organization owning the project, not set for repository projects
+	Owner *User `json:"owner,omitempty"`
+	// the repository of the project, not set for individual and organization projects
+	Repo         *RepositoryMeta `json:"repository,omitempty"`
+	Creator      *User           `json:"creator"`
+	State        StateType       `json:"state"`
+	OpenIssues   int             `json:"open_issues"`
+	ClosedIssues int             `json:"closed_issues"`
+	HTMLURL      string          `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated *time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	Closed *time.Time `json:"closed_at"`
+}
+
+// CreateProjectOption options for creating a project
+type CreateProjectOption struct {
+	// required:true
+	Title       string `json:"title" binding:"Required;MaxSize(100)"`
+	Description string `json:"description"`
+	// the template used to create the initial boards
+	// enum: none,basic_kanban,bug_triage
+	BoardType string `json:"board_type"`
+}
+
+// EditProjectOption options for editing a project
+type EditProjectOption struct {
+	Title       *string `json:"title" binding:"MaxSize(100)"`
+	Description *string `json:"description"`
+	// enum: open,closed
+	State *string `json:"state"`
+}
+
+// ProjectBoard represents a board (column) of a project
+type ProjectBoard struct {
+	ID        int64  `json:"id"`
+	ProjectID int64  `json:"project_id"`
+	Title     string `json:"title"`
+	Color     string `json:"color"`
+	// issues not assigned to a board are shown on the default board
+	Default bool `json:"default"`
+	Sorting int8 `json:"sorting"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateProjectBoardOption options for creating a project board
+type CreateProjectBoardOption struct {
+	// required:true
+	Title string `json:"title" binding:"Required;MaxSize(100)"`
+	// example: #00aabb
+	Color string `json:"color" binding:"MaxSize(7)"`
+}
+
+// EditProjectBoardOption options for editing a project board
+type EditProjectBoardOption struct {
+	Title   *string `json:"title" binding:"MaxSize(100)"`
+	Color   *string `json:"color" binding:"MaxSize(7)"`
+	Sorting *int8   `json:"sorting"`
+	// make the board the default board of the project
+	Default *bool `json:"default"`
+}
+
+// AddProjectIssueOption options for adding an issue or pull request to a project
+type AddProjectIssueOption struct {
+	// id of the issue or pull request, which may belong to any repository the doer can read for individual and organization projects
+	// required:true
+	IssueID int64 `json:"issue_id" binding:"Required"`
+	// board the issue is put on, the default board if not set
+	BoardID int64 `json:"board_id"`
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// PushPolicy represents the rules an organization enforces on the commits pushed to its repositories
+type PushPolicy struct {
+	// whether the violations are only logged instead of rejecting the pushes
+	DryRun bool `json:"dry_run"`
+	// the maximum size of a file in bytes, 0 if there is no limit
+	MaxFileSize int64 `json:"max_file_size"`
+	// semicolon separated glob patterns of the forbidden paths
+	ForbiddenPaths string `json:"forbidden_paths"`
+	// semicolon separated forbidden file extensions
+	ForbiddenExtensions  string `json:"forbidden_extensions"`
+	RequireSignedCommits bool   `json:"require_signed_commits"`
+	// regular expression the commit messages must match
+	CommitMessagePattern string `json:"commit_message_pattern" binding:"RegexPattern"`
+	// semicolon separated domains the author emails must belong to
+	AllowedEmailDomains string `json:"allowed_email_domains"`
+	// regular expressions of the secrets the added files must not contain, one per line
+	SecretPatterns string `json:"secret_patterns"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// EditPushPolicyOption options for setting the push policy of an organization
+type EditPushPolicyOption struct {
+	DryRun               bool   `json:"dry_run"`
+	MaxFileSize          int64  `json:"max_file_size"`
+	ForbiddenPaths       string `json:"forbidden_paths"`
+	ForbiddenExtensions  string `json:"forbidden_extensions"`
+	RequireSignedCommits bool   `json:"require_signed_commits"`
+	CommitMessagePattern string `json:"commit_message_pattern" binding:"RegexPattern"`
+	AllowedEmailDomains  string `json:"allowed_email_domains"`
+	SecretPatterns       string `json:"secret_patterns"`
+}
@@ -43,8 +43,11 @@ type BranchProtection struct {
 	BlockOnOutdatedBranch         bool     `json:"block_on_outdated_branch"`
 	DismissStaleApprovals         bool     `json:"dismiss_stale_approvals"`
 	RequireSignedCommits          bool     `json:"require_signed_commits"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
+	MergeWindows                  string   `json:"merge_windows"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -73,8 +76,11 @@ type CreateBranchProtectionOption struct {
 	BlockOnOutdatedBranch         bool     `json:"block_on_outdated_branch"`
 	DismissStaleApprovals         bool     `json:"dismiss_stale_approvals"`
 	RequireSignedCommits          bool     `json:"require_signed_commits"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
+	MergeWindows                  string   `json:"merge_windows"`
 }
 
 // EditBranchProtectionOption options for editing a branch protection
@@ -98,6 +104,9 @@ type EditBranchProtectionOption struct {
 	BlockOnOutdatedBranch         *bool    `json:"block_on_outdated_branch"`
 	DismissStaleApprovals         *bool    `json:"dismiss_stale_approvals"`
 	RequireSignedCommits          *bool    `json:"require_signed_commits"`
+	RequireCodeOwnerApproval      *bool    `json:"require_code_owner_approval"`
+	EnableMergeQueue              *bool    `json:"enable_merge_queue"`
 	ProtectedFilePatterns         *string  `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       *string  `json:"unprotected_file_patterns"`
+	MergeWindows                  *string  `json:"merge_windows"`
 }
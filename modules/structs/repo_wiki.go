@@ -40,6 +40,19 @@ type CreateWikiPageOptions struct {
 	Message string `json:"message"`
 }
 
+// WikiSearchResult a wiki page matching a search
+type WikiSearchResult struct {
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	SubURL  string `json:"sub_url"`
+	// sha of the indexed revision of the page
+	CommitID string `json:"sha"`
+	// numbers of the lines of the snippet
+	LineNumbers []int `json:"line_numbers"`
+	// the lines of the page around the match
+	Snippet string `json:"snippet"`
+}
+
 // WikiCommitList commit/revision list
 type WikiCommitList struct {
 	WikiCommits []*WikiCommit `json:"commits"`
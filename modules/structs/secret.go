@@ -0,0 +1,24 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// Secret represents a secret of a repository or of an organization, its value is never returned
+type Secret struct {
+	// the name of the secret, it is referenced by name in the workflows and in the headers of the webhooks
+	Name string `json:"name"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateOrUpdateSecretOption options for creating a secret or replacing its value
+type CreateOrUpdateSecretOption struct {
+	// the value of the secret, it cannot be read back
+	// required: true
+	Data string `json:"data" binding:"Required"`
+}
@@ -0,0 +1,38 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// SecretScanningAlert represents a secret found in a file of a repository
+type SecretScanningAlert struct {
+	ID int64 `json:"id"`
+	// the ID of the rule which found the secret, such as aws_access_key_id
+	Rule     string `json:"rule"`
+	RuleName string `json:"rule_name"`
+	// the secret with most of its characters hidden
+	RedactedSecret string `json:"redacted_secret"`
+	// the commit which added the secret
+	CommitID string `json:"commit_id"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	HTMLURL  string `json:"html_url"`
+	// enum: open,resolved,dismissed
+	State string `json:"state"`
+	// whether the secret was a token of this instance and has been revoked
+	Revoked    bool  `json:"revoked"`
+	ResolvedBy *User `json:"resolved_by"`
+	// swagger:strfmt date-time
+	ResolvedAt *time.Time `json:"resolved_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// EditSecretScanningAlertOption options for resolving, dismissing or reopening a secret scanning alert
+type EditSecretScanningAlertOption struct {
+	// required: true
+	// enum: open,resolved,dismissed
+	State string `json:"state" binding:"Required;In(open,resolved,dismissed)"`
+}
@@ -12,10 +12,17 @@ import (
 // AccessToken represents an API access token.
 // swagger:response AccessToken
 type AccessToken struct {
-	ID             int64  `json:"id"`
-	Name           string `json:"name"`
-	Token          string `json:"sha1"`
-	TokenLastEight string `json:"token_last_eight"`
+	ID             int64    `json:"id"`
+	Name           string   `json:"name"`
+	Token          string   `json:"sha1"`
+	TokenLastEight string   `json:"token_last_eight"`
+	Scopes         []string `json:"scopes"`
+	// the full name of the repository the token is restricted to
+	Repository string `json:"repository,omitempty"`
+	// the name of the organization the token is restricted to
+	Organization string `json:"organization,omitempty"`
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // AccessTokenList represents a list of API access token.
@@ -26,6 +33,15 @@ type AccessTokenList []*AccessToken
 // swagger:parameters userCreateToken
 type CreateAccessTokenOption struct {
 	Name string `json:"name" binding:"Required"`
+	// the scopes of the token, "all" or a level and a category like "read:repository" or "write:issue",
+	// the unrestricted tokens without a scope can access everything
+	Scopes []string `json:"scopes"`
+	// the full name of the repository to restrict the token to
+	Repository string `json:"repository"`
+	// the name of the organization to restrict the token to
+	Organization string `json:"organization"`
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 // CreateOAuth2ApplicationOptions holds options to create an oauth2 application
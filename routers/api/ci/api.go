@@ -0,0 +1,101 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package ci implements the API used by the runners to register, fetch jobs and report on them.
+package ci
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	ci_model "code.gitea.io/gitea/models/ci"
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+
+	"gitea.com/go-chi/binding"
+)
+
+// bind binding an obj to a func(ctx *context.APIContext)
+func bind(obj interface{}) http.HandlerFunc {
+	tp := reflect.TypeOf(obj)
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	return web.Wrap(func(ctx *context.APIContext) {
+		theObj := reflect.New(tp).Interface() // create a new form obj for every request but not use obj directly
+		errs := binding.Bind(ctx.Req, theObj)
+		if len(errs) > 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "validationError", fmt.Sprintf("%s: %s", errs[0].FieldNames, errs[0].Error()))
+			return
+		}
+		web.SetForm(ctx, theObj)
+	})
+}
+
+// reqRunner authenticates the runner by the token it got on registration
+func reqRunner() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		fields := strings.SplitN(ctx.Req.Header.Get("Authorization"), " ", 2)
+		if len(fields) != 2 || fields[0] != "Bearer" {
+			ctx.Error(http.StatusUnauthorized, "reqRunner", "runner token is required")
+			return
+		}
+		runner, err := ci_model.GetRunnerByToken(ctx, fields[1])
+		if err != nil {
+			if err == ci_model.ErrRunnerNotExist {
+				ctx.Error(http.StatusUnauthorized, "reqRunner", "invalid runner token")
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetRunnerByToken", err)
+			}
+			return
+		}
+		if err := ci_model.UpdateRunnerLastOnline(ctx, runner); err != nil {
+			ctx.Error(http.StatusInternalServerError, "UpdateRunnerLastOnline", err)
+			return
+		}
+		ctx.Data["CIRunner"] = runner
+	}
+}
+
+// jobAssignment loads the job from the url, it must be assigned to the authenticated runner
+func jobAssignment() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		runner := ctx.Data["CIRunner"].(*ci_model.Runner)
+		job, err := ci_model.GetJobByID(ctx, ctx.ParamsInt64(":id"))
+		if err != nil {
+			if err == ci_model.ErrJobNotExist {
+				ctx.NotFound()
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetJobByID", err)
+			}
+			return
+		}
+		if job.RunnerID != runner.ID {
+			ctx.NotFound()
+			return
+		}
+		ctx.Data["CIJob"] = job
+	}
+}
+
+// Routes registers the routes of the runner API
+func Routes() *web.Route {
+	r := web.NewRoute()
+	r.Use(context.APIContexter())
+
+	r.Post("/register", bind(ci_module.RegisterRunnerOption{}), RegisterRunner)
+	r.Group("/jobs", func() {
+		r.Post("/fetch", FetchJob)
+		r.Group("/{id}", func() {
+			r.Get("/archive", DownloadArchive)
+			r.Post("/logs", AppendLog)
+			r.Post("/status", bind(ci_module.UpdateJobOption{}), UpdateJob)
+		}, jobAssignment())
+	}, reqRunner())
+
+	return r
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	ci_model "code.gitea.io/gitea/models/ci"
+	repo_model "code.gitea.io/gitea/models/repo"
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/web"
+	ci_service "code.gitea.io/gitea/services/ci"
+)
+
+// maxLogSize is the maximum size of a chunk of log sent at once
+const maxLogSize = 1024 * 1024
+
+// RegisterRunner registers a new runner with a registration token
+func RegisterRunner(ctx *context.APIContext) {
+	form := web.GetForm(ctx).(*ci_module.RegisterRunnerOption)
+	runner, err := ci_service.RegisterRunner(ctx, form)
+	if err != nil {
+		if err == ci_model.ErrRunnerTokenNotExist {
+			ctx.Error(http.StatusUnauthorized, "RegisterRunner", "invalid registration token")
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RegisterRunner", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, &ci_module.RegisteredRunner{
+		ID:     runner.ID,
+		UUID:   runner.UUID,
+		Name:   runner.Name,
+		Token:  runner.Token,
+		Labels: runner.Labels,
+	})
+}
+
+// FetchJob assigns a waiting job to the runner, it responds with 204 if there is none
+func FetchJob(ctx *context.APIContext) {
+	job, err := ci_service.FetchJob(ctx, ctx.Data["CIRunner"].(*ci_model.Runner))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FetchJob", err)
+		return
+	}
+	if job == nil {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// DownloadArchive sends the content of the commit of the job as a tar.gz archive
+func DownloadArchive(ctx *context.APIContext) {
+	job := ctx.Data["CIJob"].(*ci_model.Job)
+	run, err := ci_model.GetRunByID(ctx, job.RunID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+		return
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	gitRepo, err := git.OpenRepository(ctx, run.Repo.RepoPath())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
+		return
+	}
+	defer gitRepo.Close()
+
+	ctx.Resp.Header().Set("Content-Type", "application/gzip")
+	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.tar.gz"`, run.CommitSHA))
+	if err := gitRepo.CreateArchive(ctx, git.TARGZ, ctx.Resp, false, run.CommitSHA); err != nil {
+		ctx.ServerError("CreateArchive", err)
+	}
+}
+
+// AppendLog adds the plain text body to the log of the job
+func AppendLog(ctx *context.APIContext) {
+	job := ctx.Data["CIJob"].(*ci_model.Job)
+	if job.Status != ci_model.StatusRunning {
+		ctx.Error(http.StatusConflict, "AppendLog", "job is not running")
+		return
+	}
+	content, err := io.ReadAll(io.LimitReader(ctx.Req.Body, maxLogSize+1))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ReadAll", err)
+		return
+	}
+	if len(content) > maxLogSize {
+		ctx.Error(http.StatusRequestEntityTooLarge, "AppendLog", "log is too large")
+		return
+	}
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, job.RepoID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepositoryByID", err)
+		return
+	}
+	if err := ci_service.AppendJobLog(ctx, job, repo, string(content)); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AppendJobLog", err)
+		return
+	}
+	if err := ci_model.TouchJob(ctx, job); err != nil {
+		ctx.Error(http.StatusInternalServerError, "TouchJob", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// UpdateJob sets the final status of the job
+func UpdateJob(ctx *context.APIContext) {
+	form := web.GetForm(ctx).(*ci_module.UpdateJobOption)
+	job := ctx.Data["CIJob"].(*ci_model.Job)
+	if job.Status != ci_model.StatusRunning {
+		ctx.Error(http.StatusConflict, "UpdateJob", "job is not running")
+		return
+	}
+	status, _ := ci_model.ParseStatus(form.Status)
+	if err := ci_service.UpdateJobStatus(ctx, job, status); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateJobStatus", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -9,14 +9,18 @@ import (
 	"regexp"
 	"strings"
 
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/packages/cargo"
 	"code.gitea.io/gitea/routers/api/packages/composer"
 	"code.gitea.io/gitea/routers/api/packages/conan"
 	"code.gitea.io/gitea/routers/api/packages/container"
+	"code.gitea.io/gitea/routers/api/packages/debian"
 	"code.gitea.io/gitea/routers/api/packages/generic"
+	"code.gitea.io/gitea/routers/api/packages/goproxy"
 	"code.gitea.io/gitea/routers/api/packages/helm"
 	"code.gitea.io/gitea/routers/api/packages/maven"
 	"code.gitea.io/gitea/routers/api/packages/npm"
@@ -35,6 +39,15 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
 			ctx.Error(http.StatusUnauthorized, "reqPackageAccess", "user should have specific permission or be a site admin")
 			return
 		}
+		level := auth_model.AccessTokenScopeLevelRead
+		if accessMode > perm.AccessModeRead {
+			level = auth_model.AccessTokenScopeLevelWrite
+		}
+		if !ctx.TokenScopeAllows(auth_model.AccessTokenScopeCategoryPackage, level) || !ctx.TokenAllowsOwner(ctx.Package.Owner.ID) {
+			ctx.Resp.Header().Set("WWW-Authenticate", `Basic realm="Gitea Package API"`)
+			ctx.Error(http.StatusUnauthorized, "reqPackageAccess", "the access token doesn't grant the access to the packages of this owner")
+			return
+		}
 	}
 }
 
@@ -59,6 +72,22 @@ func Routes() *web.Route {
 	})
 
 	r.Group("/{username}", func() {
+		r.Group("/cargo", func() {
+			r.Get("/config.json", cargo.RepositoryConfig)
+			r.Get("/1/{package}", cargo.PackageIndex)
+			r.Get("/2/{package}", cargo.PackageIndex)
+			r.Get("/3/{_}/{package}", cargo.PackageIndex)
+			r.Get("/{_}/{__}/{package}", cargo.PackageIndex)
+			r.Group("/api/v1/crates", func() {
+				r.Get("", cargo.SearchPackages)
+				r.Put("/new", reqPackageAccess(perm.AccessModeWrite), cargo.UploadPackage)
+				r.Group("/{package}/{version}", func() {
+					r.Get("/download", cargo.DownloadPackageFile)
+					r.Delete("/yank", reqPackageAccess(perm.AccessModeWrite), cargo.YankPackage)
+					r.Put("/unyank", reqPackageAccess(perm.AccessModeWrite), cargo.UnyankPackage)
+				})
+			})
+		})
 		r.Group("/composer", func() {
 			r.Get("/packages.json", composer.ServiceIndex)
 			r.Get("/search.json", composer.SearchPackages)
@@ -156,6 +185,18 @@ func Routes() *web.Route {
 				})
 			})
 		})
+		r.Group("/debian", func() {
+			r.Get("/repository.key", debian.GetRepositoryKey)
+			r.Group("/dists/{distribution}", func() {
+				r.Get("/{filename}", debian.GetRepositoryFile)
+				r.Get("/{component}/binary-{architecture}/{filename}", debian.GetPackagesIndex)
+			}, debian.VerifyNameParts)
+			r.Group("/pool/{distribution}/{component}", func() {
+				r.Put("/upload", reqPackageAccess(perm.AccessModeWrite), debian.UploadPackageFile)
+				r.Get("/{filename}", debian.DownloadPackageFile)
+				r.Delete("/{name}/{version}/{architecture}", reqPackageAccess(perm.AccessModeWrite), debian.DeletePackageFile)
+			}, debian.VerifyNameParts)
+		})
 		r.Group("/generic", func() {
 			r.Group("/{packagename}/{packageversion}", func() {
 				r.Delete("", reqPackageAccess(perm.AccessModeWrite), generic.DeletePackage)
@@ -168,6 +209,10 @@ func Routes() *web.Route {
 				})
 			})
 		})
+		r.Group("/go", func() {
+			r.Put("/upload", reqPackageAccess(perm.AccessModeWrite), goproxy.UploadPackage)
+			r.Get("/*", goproxy.ServeProxy)
+		})
 		r.Group("/helm", func() {
 			r.Get("/index.yaml", helm.Index)
 			r.Get("/{filename}", helm.DownloadPackageFile)
@@ -0,0 +1,354 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	cargo_module "code.gitea.io/gitea/modules/packages/cargo"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	type Error struct {
+		Detail string `json:"detail"`
+	}
+	type ErrorWrapper struct {
+		Errors []Error `json:"errors"`
+	}
+
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.JSON(status, ErrorWrapper{
+			Errors: []Error{
+				{Detail: message},
+			},
+		})
+	})
+}
+
+func baseURL(ctx *context.Context) string {
+	return setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/cargo"
+}
+
+// indexPath returns the path of the index file of a crate
+// https://doc.rust-lang.org/cargo/reference/registry-index.html#index-files
+func indexPath(name string) string {
+	name = strings.ToLower(name)
+	switch len(name) {
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	default:
+		return name[:2] + "/" + name[2:4] + "/" + name
+	}
+}
+
+// RepositoryConfig serves the configuration of the sparse index
+// https://doc.rust-lang.org/cargo/reference/registry-index.html#index-configuration
+func RepositoryConfig(ctx *context.Context) {
+	type Config struct {
+		DownloadURL  string `json:"dl"`
+		APIURL       string `json:"api"`
+		AuthRequired bool   `json:"auth-required"`
+	}
+
+	ctx.JSON(http.StatusOK, &Config{
+		DownloadURL:  baseURL(ctx) + "/api/v1/crates",
+		APIURL:       baseURL(ctx),
+		AuthRequired: !ctx.Package.Owner.Visibility.IsPublic(),
+	})
+}
+
+type indexEntry struct {
+	Name     string                     `json:"name"`
+	Version  string                     `json:"vers"`
+	Deps     []*cargo_module.Dependency `json:"deps"`
+	Checksum string                     `json:"cksum"`
+	Features map[string][]string        `json:"features"`
+	Yanked   bool                       `json:"yanked"`
+	Links    *string                    `json:"links"`
+}
+
+// PackageIndex serves the index file of a crate, one JSON document per version
+// https://doc.rust-lang.org/cargo/reference/registry-index.html#json-schema
+func PackageIndex(ctx *context.Context) {
+	packageName := ctx.Params("package")
+
+	if !strings.HasSuffix(ctx.Req.URL.Path, "/cargo/"+indexPath(packageName)) {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeCargo, packageName)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	for _, pd := range pds {
+		metadata := pd.Metadata.(*cargo_module.Metadata)
+
+		entry := &indexEntry{
+			Name:     pd.Package.Name,
+			Version:  pd.Version.Version,
+			Deps:     metadata.Dependencies,
+			Checksum: pd.Files[0].Blob.HashSHA256,
+			Features: metadata.Features,
+			Yanked:   pd.VersionProperties.GetByName(cargo_module.PropertyYanked) == "true",
+		}
+		if entry.Deps == nil {
+			entry.Deps = []*cargo_module.Dependency{}
+		}
+		if entry.Features == nil {
+			entry.Features = map[string][]string{}
+		}
+		if metadata.Links != "" {
+			entry.Links = &metadata.Links
+		}
+
+		if err := json.NewEncoder(&buf).Encode(entry); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.PlainTextBytes(http.StatusOK, buf.Bytes())
+}
+
+// SearchPackages searches the crates of the owner
+// https://doc.rust-lang.org/cargo/reference/registry-web-api.html#search
+func SearchPackages(ctx *context.Context) {
+	perPage := ctx.FormInt("per_page")
+	if perPage <= 0 {
+		perPage = 10
+	} else if perPage > 100 {
+		perPage = 100
+	}
+
+	pvs, total, err := packages_model.SearchLatestVersions(ctx, &packages_model.PackageSearchOptions{
+		OwnerID:    ctx.Package.Owner.ID,
+		Type:       packages_model.TypeCargo,
+		Name:       packages_model.SearchValue{Value: ctx.FormTrim("q")},
+		IsInternal: util.OptionalBoolFalse,
+		Paginator:  db.NewAbsoluteListOptions(0, perPage),
+	})
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type Crate struct {
+		Name        string `json:"name"`
+		MaxVersion  string `json:"max_version"`
+		Description string `json:"description"`
+	}
+	type Meta struct {
+		Total int64 `json:"total"`
+	}
+	type SearchResult struct {
+		Crates []*Crate `json:"crates"`
+		Meta   Meta     `json:"meta"`
+	}
+
+	crates := make([]*Crate, 0, len(pds))
+	for _, pd := range pds {
+		crates = append(crates, &Crate{
+			Name:        pd.Package.Name,
+			MaxVersion:  pd.Version.Version,
+			Description: pd.Metadata.(*cargo_module.Metadata).Description,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, SearchResult{
+		Crates: crates,
+		Meta:   Meta{Total: total},
+	})
+}
+
+// UploadPackage publishes a new crate version
+// https://doc.rust-lang.org/cargo/reference/registry-web-api.html#publish
+func UploadPackage(ctx *context.Context) {
+	defer ctx.Req.Body.Close()
+
+	cp, err := cargo_module.ParsePackage(ctx.Req.Body)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(cp.Content, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	if buf.Size() != cp.ContentSize {
+		apiError(ctx, http.StatusBadRequest, "invalid content size")
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeCargo,
+				Name:        cp.Name,
+				Version:     cp.Version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         cp.Metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: strings.ToLower(fmt.Sprintf("%s-%s.crate", cp.Name, cp.Version)),
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageVersion {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type Warnings struct {
+		InvalidCategories []string `json:"invalid_categories"`
+		InvalidBadges     []string `json:"invalid_badges"`
+		Other             []string `json:"other"`
+	}
+	type Result struct {
+		Warnings Warnings `json:"warnings"`
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Warnings: Warnings{
+			InvalidCategories: []string{},
+			InvalidBadges:     []string{},
+			Other:             []string{},
+		},
+	})
+}
+
+// YankPackage marks a crate version as yanked
+// https://doc.rust-lang.org/cargo/reference/registry-web-api.html#yank
+func YankPackage(ctx *context.Context) {
+	yankPackage(ctx, true)
+}
+
+// UnyankPackage removes the yanked mark of a crate version
+// https://doc.rust-lang.org/cargo/reference/registry-web-api.html#unyank
+func UnyankPackage(ctx *context.Context) {
+	yankPackage(ctx, false)
+}
+
+func yankPackage(ctx *context.Context, yank bool) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeCargo, ctx.Params("package"), ctx.Params("version"))
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = db.WithTx(func(ctx gocontext.Context) error {
+		if err := packages_model.DeletePropertyByName(ctx, packages_model.PropertyTypeVersion, pv.ID, cargo_module.PropertyYanked); err != nil {
+			return err
+		}
+		if yank {
+			_, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, cargo_module.PropertyYanked, "true")
+			return err
+		}
+		return nil
+	}, ctx)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type Result struct {
+		OK bool `json:"ok"`
+	}
+
+	ctx.JSON(http.StatusOK, Result{OK: true})
+}
+
+// DownloadPackageFile serves the .crate file of a crate version
+// https://doc.rust-lang.org/cargo/reference/registry-index.html#index-configuration
+func DownloadPackageFile(ctx *context.Context) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeCargo, ctx.Params("package"), ctx.Params("version"))
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pfs) != 1 {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	s, pf, err := packages_service.GetPackageFileStream(ctx, pfs[0])
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
@@ -21,7 +21,7 @@ func (a *Auth) Name() string {
 
 // Verify extracts the user from the Bearer token
 func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataStore, sess auth.SessionStore) *user_model.User {
-	uid, err := packages.ParseAuthorizationToken(req)
+	uid, accessToken, deployToken, err := packages.ParseAuthorizationToken(req)
 	if err != nil {
 		log.Trace("ParseAuthorizationToken: %v", err)
 		return nil
@@ -30,12 +30,19 @@ func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataS
 	if uid == 0 {
 		return nil
 	}
+	if deployToken != nil {
+		auth.StoreDeployToken(store, deployToken)
+		return user_model.NewDeployTokenUser(deployToken.Name)
+	}
 
 	u, err := user_model.GetUserByID(uid)
 	if err != nil {
 		log.Error("GetUserByID:  %v", err)
 		return nil
 	}
+	if accessToken != nil {
+		auth.StoreAccessToken(store, accessToken)
+	}
 
 	return u
 }
@@ -118,7 +118,7 @@ func Authenticate(ctx *context.Context) {
 		return
 	}
 
-	token, err := packages_service.CreateAuthorizationToken(ctx.Doer)
+	token, err := packages_service.CreateAuthorizationToken(ctx.Doer, ctx.AccessToken(), ctx.DeployToken())
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
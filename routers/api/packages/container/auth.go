@@ -22,7 +22,7 @@ func (a *Auth) Name() string {
 // Verify extracts the user from the Bearer token
 // If it's an anonymous session a ghost user is returned
 func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataStore, sess auth.SessionStore) *user_model.User {
-	uid, err := packages.ParseAuthorizationToken(req)
+	uid, accessToken, deployToken, err := packages.ParseAuthorizationToken(req)
 	if err != nil {
 		log.Trace("ParseAuthorizationToken: %v", err)
 		return nil
@@ -34,12 +34,19 @@ func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataS
 	if uid == -1 {
 		return user_model.NewGhostUser()
 	}
+	if deployToken != nil {
+		auth.StoreDeployToken(store, deployToken)
+		return user_model.NewDeployTokenUser(deployToken.Name)
+	}
 
 	u, err := user_model.GetUserByID(uid)
 	if err != nil {
 		log.Error("GetUserByID:  %v", err)
 		return nil
 	}
+	if accessToken != nil {
+		auth.StoreAccessToken(store, accessToken)
+	}
 
 	return u
 }
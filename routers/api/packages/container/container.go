@@ -140,7 +140,7 @@ func Authenticate(ctx *context.Context) {
 		u = user_model.NewGhostUser()
 	}
 
-	token, err := packages_service.CreateAuthorizationToken(u)
+	token, err := packages_service.CreateAuthorizationToken(u, ctx.AccessToken(), ctx.DeployToken())
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
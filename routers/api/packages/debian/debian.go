@@ -0,0 +1,261 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	debian_service "code.gitea.io/gitea/services/packages/debian"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+// VerifyNameParts checks the distribution, component and architecture path parameters
+func VerifyNameParts(ctx *context.Context) {
+	for _, param := range []string{"distribution", "component", "architecture"} {
+		if v := ctx.Params(param); v != "" && !debian_module.IsValidNamePart(v) {
+			apiError(ctx, http.StatusBadRequest, fmt.Sprintf("invalid %s", param))
+			return
+		}
+	}
+}
+
+// GetRepositoryKey serves the public key used to sign the repository
+func GetRepositoryKey(ctx *context.Context) {
+	key, err := debian_service.GetSigningKey(ctx, ctx.Package.Owner)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent("repository.key", strings.NewReader(key.PublicKey))
+}
+
+// GetRepositoryFile serves the Release, InRelease and Release.gpg files of a distribution
+// https://wiki.debian.org/DebianRepository/Format#A.22Release.22_files
+func GetRepositoryFile(ctx *context.Context) {
+	repo, err := debian_service.BuildRepository(ctx, ctx.Package.Owner, ctx.Params("distribution"))
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := ctx.Params("filename")
+
+	content := repo.Release
+	if filename != "Release" {
+		inRelease, signature, err := debian_service.SignRelease(ctx, ctx.Package.Owner, repo.Release)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		switch filename {
+		case "InRelease":
+			content = inRelease
+		case "Release.gpg":
+			content = signature
+		default:
+			apiError(ctx, http.StatusNotFound, nil)
+			return
+		}
+	}
+
+	ctx.ServeContent(filename, bytes.NewReader(content))
+}
+
+// GetPackagesIndex serves the Packages files of a component and architecture
+// https://wiki.debian.org/DebianRepository/Format#A.22Packages.22_Indices
+func GetPackagesIndex(ctx *context.Context) {
+	repo, err := debian_service.BuildRepository(ctx, ctx.Package.Owner, ctx.Params("distribution"))
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := ctx.Params("filename")
+	content, ok := repo.Indexes[fmt.Sprintf("%s/binary-%s/%s", ctx.Params("component"), ctx.Params("architecture"), filename)]
+	if !ok {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	ctx.ServeContent(filename, bytes.NewReader(content))
+}
+
+// UploadPackageFile adds a package to a component of a distribution
+func UploadPackageFile(ctx *context.Context) {
+	distribution := ctx.Params("distribution")
+	component := ctx.Params("component")
+
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pck, err := debian_module.ParsePackage(buf)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeDebian,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			Creator:  ctx.Doer,
+			Metadata: pck.Metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename:     debian_service.PackageFilename(pck.Name, pck.Version, pck.Architecture),
+				CompositeKey: debian_service.CompositeKey(distribution, component),
+			},
+			Data:   buf,
+			IsLead: true,
+			Properties: map[string]string{
+				debian_module.PropertyDistribution: distribution,
+				debian_module.PropertyComponent:    component,
+				debian_module.PropertyArchitecture: pck.Architecture,
+				debian_module.PropertyControl:      pck.Control,
+			},
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageFile {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DownloadPackageFile serves a package file from the pool
+func DownloadPackageFile(ctx *context.Context) {
+	filename := ctx.Params("filename")
+	parts := strings.Split(strings.TrimSuffix(filename, ".deb"), "_")
+	if len(parts) != 3 || !strings.HasSuffix(filename, ".deb") {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeDebian,
+			Name:        parts[0],
+			Version:     parts[1],
+		},
+		&packages_service.PackageFileInfo{
+			Filename:     filename,
+			CompositeKey: debian_service.CompositeKey(ctx.Params("distribution"), ctx.Params("component")),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// DeletePackageFile removes a package from a component of a distribution
+func DeletePackageFile(ctx *context.Context) {
+	name := ctx.Params("name")
+	version := ctx.Params("version")
+	architecture := ctx.Params("architecture")
+
+	pv, pf, err := func() (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
+		pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeDebian, name, version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, debian_service.PackageFilename(name, version, architecture), debian_service.CompositeKey(ctx.Params("distribution"), ctx.Params("component")))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return pv, pf, nil
+	}()
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(pfs) == 1 {
+		if err := packages_service.RemovePackageVersion(ctx.Doer, pv); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	} else {
+		if err := packages_service.DeletePackageFile(ctx, pf); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,277 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	packages_model "code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	goproxy_module "code.gitea.io/gitea/modules/packages/goproxy"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	goproxy_service "code.gitea.io/gitea/services/packages/goproxy"
+
+	"golang.org/x/mod/module"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+// ServeProxy maps the requests of the module proxy protocol
+// https://go.dev/ref/mod#goproxy-protocol
+func ServeProxy(ctx *context.Context) {
+	p := ctx.Params("*")
+
+	if escapedPath := strings.TrimSuffix(p, "/@latest"); escapedPath != p {
+		m := getModule(ctx, escapedPath)
+		if ctx.Written() {
+			return
+		}
+		serveLatest(ctx, m)
+		return
+	}
+
+	escapedPath, file, ok := strings.Cut(p, "/@v/")
+	if !ok {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	m := getModule(ctx, escapedPath)
+	if ctx.Written() {
+		return
+	}
+
+	if file == "list" {
+		serveVersionList(ctx, m)
+		return
+	}
+
+	pos := strings.LastIndex(file, ".")
+	if pos == -1 {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+	version, err := module.UnescapeVersion(file[:pos])
+	if err != nil {
+		apiError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	switch file[pos:] {
+	case ".info":
+		serveInfo(ctx, m, version)
+	case ".mod":
+		serveGoMod(ctx, m, version)
+	case ".zip":
+		serveZip(ctx, m, version)
+	default:
+		apiError(ctx, http.StatusNotFound, nil)
+	}
+}
+
+func getModule(ctx *context.Context, escapedPath string) *goproxy_service.Module {
+	modulePath, err := module.UnescapePath(escapedPath)
+	if err != nil {
+		apiError(ctx, http.StatusNotFound, err)
+		return nil
+	}
+
+	// the modules built from repository tags contain the code of the repository,
+	// so the access token must also grant the access to the repository
+	allowRepo := func(repo *repo_model.Repository) bool {
+		return ctx.TokenScopeAllows(auth_model.AccessTokenScopeCategoryRepository, auth_model.AccessTokenScopeLevelRead) &&
+			ctx.TokenAllowsRepo(repo.ID, repo.OwnerID)
+	}
+
+	m, err := goproxy_service.GetModule(ctx, ctx.Doer, ctx.Package.Owner, modulePath, allowRepo)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return nil
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return nil
+	}
+	return m
+}
+
+func serveVersionList(ctx *context.Context, m *goproxy_service.Module) {
+	versions, err := m.Versions(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	var sb strings.Builder
+	for _, version := range versions {
+		sb.WriteString(version)
+		sb.WriteByte('\n')
+	}
+
+	ctx.PlainText(http.StatusOK, sb.String())
+}
+
+func serveLatest(ctx *context.Context, m *goproxy_service.Module) {
+	info, err := m.Latest(ctx)
+	if err != nil {
+		if err == goproxy_service.ErrVersionNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+func serveInfo(ctx *context.Context, m *goproxy_service.Module, version string) {
+	info, err := m.Info(ctx, version)
+	if err != nil {
+		if err == goproxy_service.ErrVersionNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+func serveGoMod(ctx *context.Context, m *goproxy_service.Module, version string) {
+	goMod, err := m.GoMod(ctx, version)
+	if err != nil {
+		if err == goproxy_service.ErrVersionNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.PlainText(http.StatusOK, goMod)
+}
+
+func serveZip(ctx *context.Context, m *goproxy_service.Module, version string) {
+	filename := fmt.Sprintf("%s.zip", version)
+
+	if pv := m.UploadedVersion(version); pv != nil {
+		s, pf, err := packages_service.GetFileStreamByPackageVersion(
+			ctx,
+			pv,
+			&packages_service.PackageFileInfo{
+				Filename: filename,
+			},
+		)
+		if err != nil {
+			if err == packages_model.ErrPackageFileNotExist {
+				apiError(ctx, http.StatusNotFound, err)
+				return
+			}
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		defer s.Close()
+
+		ctx.ServeStream(s, pf.Name)
+		return
+	}
+
+	buf, err := packages_module.NewHashedBuffer(32 * 1024 * 1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	if err := m.WriteRepositoryZip(ctx, buf, version); err != nil {
+		if err == goproxy_service.ErrVersionNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent(filename, buf)
+}
+
+// UploadPackage publishes a module zip file
+func UploadPackage(ctx *context.Context) {
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pck, err := goproxy_module.ParsePackage(buf, buf.Size())
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeGo,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         pck.Metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: fmt.Sprintf("%s.zip", pck.Version),
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageVersion {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
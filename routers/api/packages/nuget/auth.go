@@ -40,6 +40,7 @@ func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataS
 	if err := models.UpdateAccessToken(token); err != nil {
 		log.Error("UpdateAccessToken:  %v", err)
 	}
+	auth.StoreAccessToken(store, token)
 
 	return u
 }
@@ -0,0 +1,183 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package scim implements the SCIM 2.0 API used by identity providers to provision users and groups.
+package scim
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/scim"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/auth"
+)
+
+// reqSCIMClient authenticates the identity provider, it has to use an access token of a site administrator
+// with the admin scope which isn't restricted to a repository or an organization
+func reqSCIMClient() func(ctx *context.APIContext) {
+	authGroup := auth.NewGroup(&auth.OAuth2{})
+	return func(ctx *context.APIContext) {
+		ctx.Doer = authGroup.Verify(ctx.Req, ctx.Resp, ctx, nil)
+		if ctx.Doer == nil || !ctx.Doer.IsActive || ctx.Doer.ProhibitLogin {
+			writeError(ctx, scim.NewError(http.StatusUnauthorized, "", "a valid access token is required"))
+			return
+		}
+		if !ctx.Doer.IsAdmin {
+			writeError(ctx, scim.NewError(http.StatusForbidden, "", "the access token must belong to a site administrator"))
+			return
+		}
+		level := auth_model.AccessTokenScopeLevelWrite
+		if ctx.Req.Method == http.MethodGet || ctx.Req.Method == http.MethodHead {
+			level = auth_model.AccessTokenScopeLevelRead
+		}
+		if !ctx.TokenScopeAllows(auth_model.AccessTokenScopeCategoryAdmin, level) {
+			writeError(ctx, scim.NewError(http.StatusForbidden, "", "the access token requires the scope "+auth_model.FormatAccessTokenScope(level, auth_model.AccessTokenScopeCategoryAdmin)))
+			return
+		}
+		if ctx.IsTokenRestricted() {
+			writeError(ctx, scim.NewError(http.StatusForbidden, "", "the access token is restricted to a repository or an organization"))
+			return
+		}
+		ctx.IsSigned = true
+	}
+}
+
+// Routes registers the routes of the SCIM API
+func Routes() *web.Route {
+	r := web.NewRoute()
+	r.Use(context.APIContexter())
+
+	r.Group("", func() {
+		r.Get("/ServiceProviderConfig", ServiceProviderConfig)
+		r.Group("/Users", func() {
+			r.Get("", ListUsers)
+			r.Post("", CreateUser)
+			r.Get("/{id}", GetUser)
+			r.Put("/{id}", ReplaceUser)
+			r.Patch("/{id}", PatchUser)
+			r.Delete("/{id}", DeleteUser)
+		})
+		r.Group("/Groups", func() {
+			r.Get("", ListGroups)
+			r.Post("", CreateGroup)
+			r.Get("/{id}", GetGroup)
+			r.Put("/{id}", ReplaceGroup)
+			r.Patch("/{id}", PatchGroup)
+			r.Delete("/{id}", DeleteGroup)
+		})
+	}, reqSCIMClient())
+
+	return r
+}
+
+// ServiceProviderConfig describes the supported features
+func ServiceProviderConfig(ctx *context.APIContext) {
+	writeResponse(ctx, http.StatusOK, &scim.ServiceProviderConfig{
+		Schemas:          []string{scim.SchemaServiceProviderConfig},
+		DocumentationURI: "https://docs.gitea.io/en-us/scim/",
+		Patch:            scim.Supported{Supported: true},
+		Filter: scim.FilterSupport{
+			Supported:  true,
+			MaxResults: setting.API.MaxResponseItems,
+		},
+		AuthenticationSchemes: []scim.AuthenticationScheme{
+			{
+				Type:        "oauthbearertoken",
+				Name:        "OAuth Bearer Token",
+				Description: "Access token of a site administrator",
+				Primary:     true,
+			},
+		},
+		Meta: &scim.Meta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     setting.AppURL + "api/scim/v2/ServiceProviderConfig",
+		},
+	})
+}
+
+// writeResponse writes the object as SCIM message
+func writeResponse(ctx *context.APIContext, status int, obj interface{}) {
+	ctx.Resp.Header().Set("Content-Type", scim.ContentType+"; charset=utf-8")
+	ctx.Resp.WriteHeader(status)
+	if err := json.NewEncoder(ctx.Resp).Encode(obj); err != nil {
+		log.Error("Failed to write SCIM response: %v", err)
+	}
+}
+
+// writeError writes the error as SCIM error response, the errors of the models are mapped to the matching SCIM errors
+func writeError(ctx *context.APIContext, err error) {
+	var scimErr *scim.Error
+	switch {
+	case errors.As(err, &scimErr):
+	case user_model.IsErrUserNotExist(err) || organization.IsErrTeamNotExist(err) || organization.IsErrOrgNotExist(err):
+		scimErr = scim.NewError(http.StatusNotFound, "", "resource not found")
+	case user_model.IsErrUserAlreadyExist(err) || user_model.IsErrEmailAlreadyUsed(err):
+		scimErr = scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, "%v", err)
+	case db.IsErrNameReserved(err) ||
+		db.IsErrNameCharsNotAllowed(err) ||
+		db.IsErrNamePatternNotAllowed(err) ||
+		user_model.IsErrEmailCharIsNotSupported(err) ||
+		user_model.IsErrEmailInvalid(err):
+		scimErr = scim.InvalidValue("%v", err)
+	default:
+		log.Error("SCIM request %s %s failed: %v", ctx.Req.Method, ctx.Req.URL.Path, err)
+		scimErr = scim.NewError(http.StatusInternalServerError, "", "internal server error")
+	}
+
+	status, _ := strconv.Atoi(scimErr.Status)
+	writeResponse(ctx, status, scimErr)
+}
+
+// readBody decodes the JSON body of the request
+func readBody(ctx *context.APIContext, v interface{}) bool {
+	if err := json.NewDecoder(ctx.Req.Body).Decode(v); err != nil {
+		writeError(ctx, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, "invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// listOptions returns the filter, the 1-based start index and the maximum number of resources of a query
+func listOptions(ctx *context.APIContext) (*scim.Filter, int, int, bool) {
+	filter, err := scim.ParseFilter(ctx.FormString("filter"))
+	if err != nil {
+		writeError(ctx, err)
+		return nil, 0, 0, false
+	}
+
+	startIndex := ctx.FormInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := setting.API.DefaultPagingNum
+	if ctx.Req.Form.Has("count") {
+		count = ctx.FormInt("count")
+	}
+	if count < 0 {
+		count = 0
+	} else if count > setting.API.MaxResponseItems {
+		count = setting.API.MaxResponseItems
+	}
+	return filter, startIndex, count, true
+}
+
+func writeList(ctx *context.APIContext, total int64, startIndex int, resources interface{}, length int) {
+	writeResponse(ctx, http.StatusOK, &scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: length,
+		Resources:    resources,
+	})
+}
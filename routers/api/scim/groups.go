@@ -0,0 +1,153 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/scim"
+	scim_service "code.gitea.io/gitea/services/scim"
+)
+
+func getOrganization(ctx *context.APIContext) *organization.Organization {
+	org, err := scim_service.GetOrganization(ctx)
+	if err != nil {
+		writeError(ctx, err)
+		return nil
+	}
+	return org
+}
+
+func writeGroup(ctx *context.APIContext, status int, t *organization.Team) {
+	sg, err := scim_service.ToGroup(ctx, t)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeResponse(ctx, status, sg)
+}
+
+// ListGroups returns the teams of the organization matching the filter
+func ListGroups(ctx *context.APIContext) {
+	org := getOrganization(ctx)
+	if org == nil {
+		return
+	}
+	filter, startIndex, count, ok := listOptions(ctx)
+	if !ok {
+		return
+	}
+
+	teams, total, err := scim_service.FindGroups(ctx, org, filter, startIndex, count)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	resources := make([]*scim.Group, 0, len(teams))
+	for _, t := range teams {
+		sg, err := scim_service.ToGroup(ctx, t)
+		if err != nil {
+			writeError(ctx, err)
+			return
+		}
+		resources = append(resources, sg)
+	}
+	writeList(ctx, total, startIndex, resources, len(resources))
+}
+
+// CreateGroup creates a team in the organization
+func CreateGroup(ctx *context.APIContext) {
+	org := getOrganization(ctx)
+	if org == nil {
+		return
+	}
+	sg := &scim.Group{}
+	if !readBody(ctx, sg) {
+		return
+	}
+
+	t, err := scim_service.CreateGroup(ctx, ctx.Doer, ctx.RemoteAddr(), org, sg)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Resp.Header().Set("Location", scim_service.ResourceLocation("Group", t.ID))
+	writeGroup(ctx, http.StatusCreated, t)
+}
+
+func getGroup(ctx *context.APIContext) *organization.Team {
+	org := getOrganization(ctx)
+	if org == nil {
+		return nil
+	}
+	t, err := scim_service.GetGroup(ctx, org, ctx.Params(":id"))
+	if err != nil {
+		writeError(ctx, err)
+		return nil
+	}
+	return t
+}
+
+// GetGroup returns the team
+func GetGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if t == nil {
+		return
+	}
+	writeGroup(ctx, http.StatusOK, t)
+}
+
+// ReplaceGroup renames the team and replaces its members
+func ReplaceGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if t == nil {
+		return
+	}
+	sg := &scim.Group{}
+	if !readBody(ctx, sg) {
+		return
+	}
+
+	if err := scim_service.ReplaceGroup(ctx, ctx.Doer, ctx.RemoteAddr(), t, sg); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeGroup(ctx, http.StatusOK, t)
+}
+
+// PatchGroup changes the name or the members of the team
+func PatchGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if t == nil {
+		return
+	}
+	req := &scim.PatchRequest{}
+	if !readBody(ctx, req) {
+		return
+	}
+
+	if err := scim_service.PatchGroup(ctx, ctx.Doer, ctx.RemoteAddr(), t, req.Operations); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeGroup(ctx, http.StatusOK, t)
+}
+
+// DeleteGroup deletes the team
+func DeleteGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if t == nil {
+		return
+	}
+
+	if err := scim_service.DeleteGroup(ctx, ctx.Doer, ctx.RemoteAddr(), t); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/scim"
+	scim_service "code.gitea.io/gitea/services/scim"
+)
+
+// ListUsers returns the users matching the filter
+func ListUsers(ctx *context.APIContext) {
+	filter, startIndex, count, ok := listOptions(ctx)
+	if !ok {
+		return
+	}
+
+	users, total, err := scim_service.FindUsers(ctx, filter, startIndex, count)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	resources := make([]*scim.User, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, scim_service.ToUser(u))
+	}
+	writeList(ctx, total, startIndex, resources, len(resources))
+}
+
+// CreateUser provisions a user
+func CreateUser(ctx *context.APIContext) {
+	su := &scim.User{}
+	if !readBody(ctx, su) {
+		return
+	}
+
+	u, err := scim_service.CreateUser(ctx, ctx.Doer, ctx.RemoteAddr(), su)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Resp.Header().Set("Location", scim_service.ResourceLocation("User", u.ID))
+	writeResponse(ctx, http.StatusCreated, scim_service.ToUser(u))
+}
+
+func getUser(ctx *context.APIContext) *user_model.User {
+	u, err := scim_service.GetUser(ctx, ctx.Params(":id"))
+	if err != nil {
+		writeError(ctx, err)
+		return nil
+	}
+	return u
+}
+
+// GetUser returns the user
+func GetUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	writeResponse(ctx, http.StatusOK, scim_service.ToUser(u))
+}
+
+// ReplaceUser replaces the attributes of the user
+func ReplaceUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	su := &scim.User{}
+	if !readBody(ctx, su) {
+		return
+	}
+
+	if err := scim_service.ReplaceUser(ctx, ctx.Doer, ctx.RemoteAddr(), u, su); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeResponse(ctx, http.StatusOK, scim_service.ToUser(u))
+}
+
+// PatchUser changes attributes of the user
+func PatchUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	req := &scim.PatchRequest{}
+	if !readBody(ctx, req) {
+		return
+	}
+
+	if err := scim_service.PatchUser(ctx, ctx.Doer, ctx.RemoteAddr(), u, req.Operations); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	writeResponse(ctx, http.StatusOK, scim_service.ToUser(u))
+}
+
+// DeleteUser deactivates the user.
+// The user is not deleted because its repositories and contributions must be kept.
+func DeleteUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+
+	if err := scim_service.DeactivateUser(ctx, ctx.Doer, ctx.RemoteAddr(), u); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListAuditEvents api for exporting the audit log
+func ListAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/audit admin adminListAuditEvents
+	// ---
+	// summary: List the events of the audit log, the newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: action
+	//   in: query
+	//   description: only show events of this action
+	//   type: string
+	// - name: actor
+	//   in: query
+	//   description: only show events of the user with this name
+	//   type: string
+	// - name: scope_type
+	//   in: query
+	//   description: only show events in scopes of this type
+	//   type: string
+	//   enum: [system, user, organization, repository]
+	// - name: scope_id
+	//   in: query
+	//   description: only show events in the scope with this id, requires scope_type
+	//   type: integer
+	//   format: int64
+	// - name: q
+	//   in: query
+	//   description: keyword matching the names of the actor, the scope or the target
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only show events recorded after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show events recorded before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AuditEventList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	action := audit_model.Action(ctx.FormTrim("action"))
+	if action != "" && !action.IsValid() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown action: %s", action))
+		return
+	}
+
+	before, since, err := context.GetQueryBeforeSince(ctx.Context)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	events, count, err := audit_model.SearchEvents(ctx, &audit_model.SearchEventsOptions{
+		ListOptions: listOptions,
+		Action:      action,
+		ActorName:   ctx.FormTrim("actor"),
+		ScopeType:   audit_model.ObjectType(ctx.FormTrim("scope_type")),
+		ScopeID:     ctx.FormInt64("scope_id"),
+		Keyword:     ctx.FormTrim("q"),
+		Since:       since,
+		Before:      before,
+	})
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiEvents := make([]*api.AuditEvent, 0, len(events))
+	for _, e := range events {
+		apiEvents = append(apiEvents, convert.ToAuditEvent(e))
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiEvents)
+}
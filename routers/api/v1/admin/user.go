@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -27,6 +28,7 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/mailer"
 	user_service "code.gitea.io/gitea/services/user"
 )
@@ -136,6 +138,7 @@ func CreateUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, audit_model.ActionAdminUserCreate, ctx.Doer, ctx.RemoteAddr(), audit_service.System(), audit_service.User(u), nil, audit_service.UserStateOf(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -173,6 +176,8 @@ func EditUser(ctx *context.APIContext) {
 
 	form := web.GetForm(ctx).(*api.EditUserOption)
 
+	before := audit_service.UserStateOf(ctx.ContextUser)
+
 	parseAuthSource(ctx, ctx.ContextUser, form.SourceID, form.LoginName)
 	if ctx.Written() {
 		return
@@ -280,6 +285,7 @@ func EditUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit_service.Record(ctx, audit_model.ActionAdminUserUpdate, ctx.Doer, ctx.RemoteAddr(), audit_service.System(), audit_service.User(ctx.ContextUser), before, audit_service.UserStateOf(ctx.ContextUser))
 
 	ctx.JSON(http.StatusOK, convert.ToUser(ctx.ContextUser, ctx.Doer))
 }
@@ -327,6 +333,7 @@ func DeleteUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account deleted by admin(%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit_service.Record(ctx, audit_model.ActionAdminUserDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.System(), audit_service.User(ctx.ContextUser), audit_service.UserStateOf(ctx.ContextUser), nil)
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -70,6 +70,7 @@ import (
 	"reflect"
 	"strings"
 
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -83,10 +84,12 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/activitypub"
 	"code.gitea.io/gitea/routers/api/v1/admin"
+	"code.gitea.io/gitea/routers/api/v1/app"
 	"code.gitea.io/gitea/routers/api/v1/misc"
 	"code.gitea.io/gitea/routers/api/v1/notify"
 	"code.gitea.io/gitea/routers/api/v1/org"
 	"code.gitea.io/gitea/routers/api/v1/packages"
+	"code.gitea.io/gitea/routers/api/v1/project"
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	"code.gitea.io/gitea/routers/api/v1/settings"
 	"code.gitea.io/gitea/routers/api/v1/user"
@@ -97,6 +100,7 @@ import (
 	_ "code.gitea.io/gitea/routers/api/v1/swagger" // for swagger generation
 
 	"gitea.com/go-chi/binding"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 )
 
@@ -109,6 +113,10 @@ func sudo() func(ctx *context.APIContext) {
 
 		if len(sudo) > 0 {
 			if ctx.IsSigned && ctx.Doer.IsAdmin {
+				tokenRequiresScopes(auth_model.AccessTokenScopeCategoryAdmin)(ctx)
+				if ctx.Written() {
+					return
+				}
 				user, err := user_model.GetUserByName(ctx, sudo)
 				if err != nil {
 					if user_model.IsErrUserNotExist(err) {
@@ -190,7 +198,7 @@ func repoAssignment() func(ctx *context.APIContext) {
 			return
 		}
 
-		if !ctx.Repo.HasAccess() {
+		if !ctx.Repo.HasAccess() || !ctx.TokenAllowsRepo(repo.ID, repo.OwnerID) {
 			ctx.NotFound()
 			return
 		}
@@ -203,6 +211,55 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.APIContext)
 			ctx.Error(http.StatusForbidden, "reqPackageAccess", "user should have specific permission or be a site admin")
 			return
 		}
+		if !ctx.TokenAllowsOwner(ctx.Package.Owner.ID) {
+			ctx.Error(http.StatusForbidden, "reqPackageAccess", "the access token is restricted to another repository or organization")
+			return
+		}
+	}
+}
+
+// tokenScopeLevel returns the level of access of the request, reading or writing
+func tokenScopeLevel(ctx *context.APIContext) auth_model.AccessTokenScopeLevel {
+	if ctx.Req.Method == http.MethodGet || ctx.Req.Method == http.MethodHead {
+		return auth_model.AccessTokenScopeLevelRead
+	}
+	return auth_model.AccessTokenScopeLevelWrite
+}
+
+// tokenRequiresScopes requires the personal access token of the request to have a scope of the category,
+// reading requires its read or its write scope, writing requires its write scope
+func tokenRequiresScopes(category auth_model.AccessTokenScopeCategory) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		level := tokenScopeLevel(ctx)
+		if !ctx.TokenScopeAllows(category, level) {
+			ctx.Error(http.StatusForbidden, "tokenRequiresScopes", fmt.Sprintf("the access token requires the scope %s", auth_model.FormatAccessTokenScope(level, category)))
+			return
+		}
+	}
+}
+
+// restrictedTokenRoutes are the routes the personal access tokens restricted to a repository or an organization can access,
+// repoAssignment, orgAssignment and reqPackageAccess check the repository or the organization of the request
+var restrictedTokenRoutes = []string{
+	"/repos/{username}/{reponame}",
+	"/orgs/{org}",
+	"/teams/{teamid}",
+	"/packages/{username}",
+}
+
+// tokenRestriction rejects the requests of the restricted personal access tokens to the other routes
+func tokenRestriction() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if !ctx.IsTokenRestricted() {
+			return
+		}
+		pattern := strings.TrimPrefix(chi.RouteContext(ctx.Req.Context()).RoutePattern(), "/api/v1")
+		for _, route := range restrictedTokenRoutes {
+			if pattern == route || strings.HasPrefix(pattern, route+"/") {
+				return
+			}
+		}
+		ctx.Error(http.StatusForbidden, "tokenRestriction", "the access token is restricted to a repository or an organization")
 	}
 }
 
@@ -240,6 +297,11 @@ func reqBasicOrRevProxyAuth() func(ctx *context.APIContext) {
 			ctx.Error(http.StatusUnauthorized, "reqBasicOrRevProxyAuth", "auth required")
 			return
 		}
+		// the tokens with fewer permissions can't create the tokens with more permissions
+		if t := ctx.AccessToken(); t != nil && t.Scope != auth_model.AccessTokenScopeAll {
+			ctx.Error(http.StatusUnauthorized, "reqBasicOrRevProxyAuth", "the access token isn't allowed to manage access tokens")
+			return
+		}
 		ctx.CheckForOTP()
 	}
 }
@@ -464,6 +526,10 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				}
 				return
 			}
+			if !ctx.TokenAllowsOwner(ctx.Org.Organization.ID) {
+				ctx.NotFound()
+				return
+			}
 			ctx.ContextUser = ctx.Org.Organization.AsUser()
 		}
 
@@ -477,6 +543,10 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				}
 				return
 			}
+			if !ctx.TokenAllowsOwner(ctx.Org.Team.OrgID) {
+				ctx.NotFound()
+				return
+			}
 		}
 	}
 }
@@ -670,7 +740,7 @@ func Routes() *web.Route {
 			m.Combo("/threads/{id}").
 				Get(notify.GetThread).
 				Patch(notify.ReadThread)
-		}, reqToken())
+		}, reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryUser))
 
 		// Users
 		m.Group("/users", func() {
@@ -690,7 +760,7 @@ func Routes() *web.Route {
 					m.Combo("/{id}").Delete(user.DeleteAccessToken)
 				}, reqBasicOrRevProxyAuth())
 			}, context_service.UserAssignmentAPI())
-		})
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryUser))
 
 		m.Group("/users", func() {
 			m.Group("/{username}", func() {
@@ -707,7 +777,7 @@ func Routes() *web.Route {
 
 				m.Get("/subscriptions", user.GetWatchedRepos)
 			}, context_service.UserAssignmentAPI())
-		}, reqToken())
+		}, reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryUser))
 
 		m.Group("/user", func() {
 			m.Get("", user.GetAuthenticatedUser)
@@ -743,6 +813,12 @@ func Routes() *web.Route {
 					Delete(user.DeleteOauth2Application).
 					Patch(bind(api.CreateOAuth2ApplicationOptions{}), user.UpdateOauth2Application).
 					Get(user.GetOauth2Application)
+				m.Combo("/apps").
+					Get(user.ListApps).
+					Post(bind(api.CreateAppOption{}), user.CreateApp)
+				m.Combo("/apps/{id}").
+					Get(user.GetApp).
+					Delete(user.DeleteApp)
 			}, reqToken())
 
 			m.Group("/gpg_keys", func() {
@@ -755,7 +831,7 @@ func Routes() *web.Route {
 			m.Get("/gpg_key_token", user.GetVerificationToken)
 			m.Post("/gpg_key_verify", bind(api.VerifyGPGKeyOption{}), user.VerifyUserGPGKey)
 
-			m.Combo("/repos").Get(user.ListMyRepos).
+			m.Combo("/repos", tokenRequiresScopes(auth_model.AccessTokenScopeCategoryRepository)).Get(user.ListMyRepos).
 				Post(bind(api.CreateRepoOption{}), repo.Create)
 
 			m.Group("/starred", func() {
@@ -773,18 +849,16 @@ func Routes() *web.Route {
 			m.Get("/subscriptions", user.GetMyWatchedRepos)
 
 			m.Get("/teams", org.ListUserTeams)
-		}, reqToken())
+		}, reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryUser))
 
 		// Repositories
-		m.Post("/org/{org}/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepoDeprecated)
+		m.Post("/org/{org}/repos", reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryRepository), bind(api.CreateRepoOption{}), repo.CreateOrgRepoDeprecated)
 
-		m.Combo("/repositories/{id}", reqToken()).Get(repo.GetByID)
+		m.Combo("/repositories/{id}", reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryRepository)).Get(repo.GetByID)
 
 		m.Group("/repos", func() {
 			m.Get("/search", repo.Search)
 
-			m.Get("/issues/search", repo.SearchIssues)
-
 			m.Post("/migrate", reqToken(), bind(api.MigrateRepoOptions{}), repo.Migrate)
 
 			m.Group("/{username}/{reponame}", func() {
@@ -816,6 +890,22 @@ func Routes() *web.Route {
 						m.Post("/tests", context.ReferencesGitRepo(), context.RepoRefForAPI, repo.TestHook)
 					})
 				}, reqToken(), reqAdmin(), reqWebhooksEnabled())
+				m.Group("/secrets", func() {
+					m.Get("", repo.ListSecrets)
+					m.Combo("/{secretname}").
+						Put(bind(api.CreateOrUpdateSecretOption{}), repo.CreateOrUpdateSecret).
+						Delete(repo.DeleteSecret)
+				}, reqToken(), reqAdmin())
+				m.Group("/deploy_tokens", func() {
+					m.Combo("").Get(repo.ListDeployTokens).
+						Post(bind(api.CreateDeployTokenOption{}), repo.CreateDeployToken)
+					m.Delete("/{id}", repo.DeleteDeployToken)
+				}, reqToken(), reqAdmin())
+				m.Group("/secret_scanning/alerts", func() {
+					m.Get("", repo.ListSecretScanningAlerts)
+					m.Combo("/{id}").Get(repo.GetSecretScanningAlert).
+						Patch(bind(api.EditSecretScanningAlertOption{}), repo.EditSecretScanningAlert)
+				}, reqToken(), reqAdmin())
 				m.Group("/collaborators", func() {
 					m.Get("", reqAnyRepoReader(), repo.ListCollaborators)
 					m.Group("/{collaborator}", func() {
@@ -865,10 +955,6 @@ func Routes() *web.Route {
 					m.Combo("/{id}").Get(repo.GetDeployKey).
 						Delete(repo.DeleteDeploykey)
 				}, reqToken(), reqAdmin())
-				m.Group("/times", func() {
-					m.Combo("").Get(repo.ListTrackedTimesByRepository)
-					m.Combo("/{timetrackingusername}").Get(repo.ListTrackedTimesByUser)
-				}, mustEnableIssues, reqToken())
 				m.Group("/wiki", func() {
 					m.Combo("/page/{pageName}").
 						Get(repo.GetWikiPage).
@@ -877,82 +963,10 @@ func Routes() *web.Route {
 					m.Get("/revisions/{pageName}", repo.ListPageRevisions)
 					m.Post("/new", mustNotBeArchived, reqRepoWriter(unit.TypeWiki), bind(api.CreateWikiPageOptions{}), repo.NewWikiPage)
 					m.Get("/pages", repo.ListWikiPages)
+					m.Get("/search", repo.SearchWikiPages)
 				}, mustEnableWiki)
-				m.Group("/issues", func() {
-					m.Combo("").Get(repo.ListIssues).
-						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue)
-					m.Group("/comments", func() {
-						m.Get("", repo.ListRepoIssueComments)
-						m.Group("/{id}", func() {
-							m.Combo("").
-								Get(repo.GetIssueComment).
-								Patch(mustNotBeArchived, reqToken(), bind(api.EditIssueCommentOption{}), repo.EditIssueComment).
-								Delete(reqToken(), repo.DeleteIssueComment)
-							m.Combo("/reactions").
-								Get(repo.GetIssueCommentReactions).
-								Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueCommentReaction).
-								Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueCommentReaction)
-						})
-					})
-					m.Group("/{index}", func() {
-						m.Combo("").Get(repo.GetIssue).
-							Patch(reqToken(), bind(api.EditIssueOption{}), repo.EditIssue).
-							Delete(reqToken(), reqAdmin(), repo.DeleteIssue)
-						m.Group("/comments", func() {
-							m.Combo("").Get(repo.ListIssueComments).
-								Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueCommentOption{}), repo.CreateIssueComment)
-							m.Combo("/{id}", reqToken()).Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueCommentDeprecated).
-								Delete(repo.DeleteIssueCommentDeprecated)
-						})
-						m.Get("/timeline", repo.ListIssueCommentsAndTimeline)
-						m.Group("/labels", func() {
-							m.Combo("").Get(repo.ListIssueLabels).
-								Post(reqToken(), bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
-								Put(reqToken(), bind(api.IssueLabelsOption{}), repo.ReplaceIssueLabels).
-								Delete(reqToken(), repo.ClearIssueLabels)
-							m.Delete("/{id}", reqToken(), repo.DeleteIssueLabel)
-						})
-						m.Group("/times", func() {
-							m.Combo("").
-								Get(repo.ListTrackedTimes).
-								Post(bind(api.AddTimeOption{}), repo.AddTime).
-								Delete(repo.ResetIssueTime)
-							m.Delete("/{id}", repo.DeleteTime)
-						}, reqToken())
-						m.Combo("/deadline").Post(reqToken(), bind(api.EditDeadlineOption{}), repo.UpdateIssueDeadline)
-						m.Group("/stopwatch", func() {
-							m.Post("/start", reqToken(), repo.StartIssueStopwatch)
-							m.Post("/stop", reqToken(), repo.StopIssueStopwatch)
-							m.Delete("/delete", reqToken(), repo.DeleteIssueStopwatch)
-						})
-						m.Group("/subscriptions", func() {
-							m.Get("", repo.GetIssueSubscribers)
-							m.Get("/check", reqToken(), repo.CheckIssueSubscription)
-							m.Put("/{user}", reqToken(), repo.AddIssueSubscription)
-							m.Delete("/{user}", reqToken(), repo.DelIssueSubscription)
-						})
-						m.Combo("/reactions").
-							Get(repo.GetIssueReactions).
-							Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueReaction).
-							Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueReaction)
-					})
-				}, mustEnableIssuesOrPulls)
-				m.Group("/labels", func() {
-					m.Combo("").Get(repo.ListLabels).
-						Post(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.CreateLabelOption{}), repo.CreateLabel)
-					m.Combo("/{id}").Get(repo.GetLabel).
-						Patch(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
-						Delete(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), repo.DeleteLabel)
-				})
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
-				m.Group("/milestones", func() {
-					m.Combo("").Get(repo.ListMilestones).
-						Post(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
-					m.Combo("/{id}").Get(repo.GetMilestone).
-						Patch(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
-						Delete(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), repo.DeleteMilestone)
-				})
 				m.Get("/stargazers", repo.ListStargazers)
 				m.Get("/subscribers", repo.ListSubscribers)
 				m.Group("/subscription", func() {
@@ -989,6 +1003,8 @@ func Routes() *web.Route {
 					m.Combo("/{name}").
 						Delete(repo.DeletePushMirrorByRemoteName).
 						Get(repo.GetPushMirrorByName)
+					m.Get("/{name}/refs", repo.ListPushMirrorRefs)
+					m.Get("/{name}/errors", repo.ListPushMirrorSyncErrors)
 				}, reqAdmin())
 
 				m.Get("/editorconfig/{filename}", context.ReferencesGitRepo(), context.RepoRefForAPI, reqRepoReader(unit.TypeCode), repo.GetEditorconfig)
@@ -1067,9 +1083,116 @@ func Routes() *web.Route {
 					}, reqAdmin())
 				}, reqAnyRepoReader())
 				m.Get("/issue_templates", context.ReferencesGitRepo(), repo.GetIssueTemplates)
+				m.Get("/issue_config", context.ReferencesGitRepo(), repo.GetIssueConfig)
+				m.Get("/issue_config/validate", context.ReferencesGitRepo(), repo.ValidateIssueConfig)
 				m.Get("/languages", reqRepoReader(unit.TypeCode), repo.GetLanguages)
 			}, repoAssignment())
-		})
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryRepository))
+
+		// Issues
+		m.Group("/repos", func() {
+			m.Get("/issues/search", repo.SearchIssues)
+
+			m.Group("/{username}/{reponame}", func() {
+				m.Group("/times", func() {
+					m.Combo("").Get(repo.ListTrackedTimesByRepository)
+					m.Combo("/{timetrackingusername}").Get(repo.ListTrackedTimesByUser)
+				}, mustEnableIssues, reqToken())
+				m.Group("/issues", func() {
+					m.Combo("").Get(repo.ListIssues).
+						Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueOption{}), repo.CreateIssue)
+					m.Group("/comments", func() {
+						m.Get("", repo.ListRepoIssueComments)
+						m.Group("/{id}", func() {
+							m.Combo("").
+								Get(repo.GetIssueComment).
+								Patch(mustNotBeArchived, reqToken(), bind(api.EditIssueCommentOption{}), repo.EditIssueComment).
+								Delete(reqToken(), repo.DeleteIssueComment)
+							m.Combo("/reactions").
+								Get(repo.GetIssueCommentReactions).
+								Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueCommentReaction).
+								Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueCommentReaction)
+						})
+					})
+					m.Group("/{index}", func() {
+						m.Combo("").Get(repo.GetIssue).
+							Patch(reqToken(), bind(api.EditIssueOption{}), repo.EditIssue).
+							Delete(reqToken(), reqAdmin(), repo.DeleteIssue)
+						m.Group("/comments", func() {
+							m.Combo("").Get(repo.ListIssueComments).
+								Post(reqToken(), mustNotBeArchived, bind(api.CreateIssueCommentOption{}), repo.CreateIssueComment)
+							m.Combo("/{id}", reqToken()).Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueCommentDeprecated).
+								Delete(repo.DeleteIssueCommentDeprecated)
+						})
+						m.Get("/timeline", repo.ListIssueCommentsAndTimeline)
+						m.Group("/labels", func() {
+							m.Combo("").Get(repo.ListIssueLabels).
+								Post(reqToken(), bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
+								Put(reqToken(), bind(api.IssueLabelsOption{}), repo.ReplaceIssueLabels).
+								Delete(reqToken(), repo.ClearIssueLabels)
+							m.Delete("/{id}", reqToken(), repo.DeleteIssueLabel)
+						})
+						m.Group("/times", func() {
+							m.Combo("").
+								Get(repo.ListTrackedTimes).
+								Post(bind(api.AddTimeOption{}), repo.AddTime).
+								Delete(repo.ResetIssueTime)
+							m.Delete("/{id}", repo.DeleteTime)
+						}, reqToken())
+						m.Combo("/deadline").Post(reqToken(), bind(api.EditDeadlineOption{}), repo.UpdateIssueDeadline)
+						m.Group("/stopwatch", func() {
+							m.Post("/start", reqToken(), repo.StartIssueStopwatch)
+							m.Post("/stop", reqToken(), repo.StopIssueStopwatch)
+							m.Delete("/delete", reqToken(), repo.DeleteIssueStopwatch)
+						})
+						m.Group("/subscriptions", func() {
+							m.Get("", repo.GetIssueSubscribers)
+							m.Get("/check", reqToken(), repo.CheckIssueSubscription)
+							m.Put("/{user}", reqToken(), repo.AddIssueSubscription)
+							m.Delete("/{user}", reqToken(), repo.DelIssueSubscription)
+						})
+						m.Combo("/reactions").
+							Get(repo.GetIssueReactions).
+							Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueReaction).
+							Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueReaction)
+					})
+				}, mustEnableIssuesOrPulls)
+				m.Group("/labels", func() {
+					m.Combo("").Get(repo.ListLabels).
+						Post(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.CreateLabelOption{}), repo.CreateLabel)
+					m.Combo("/{id}").Get(repo.GetLabel).
+						Patch(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
+						Delete(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), repo.DeleteLabel)
+				})
+				m.Group("/milestones", func() {
+					m.Combo("").Get(repo.ListMilestones).
+						Post(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
+					m.Combo("/{id}").Get(repo.GetMilestone).
+						Patch(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
+						Delete(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), repo.DeleteMilestone)
+				})
+			}, repoAssignment())
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryIssue))
+
+		// Projects
+		m.Get("/users/{username}/projects", tokenRequiresScopes(auth_model.AccessTokenScopeCategoryIssue), context_service.UserAssignmentAPI(), project.MustEnableProjects, project.ListUserProjects)
+		m.Post("/user/projects", reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryIssue), project.MustEnableProjects, bind(api.CreateProjectOption{}), project.CreateUserProject)
+		m.Group("/projects/{id}", func() {
+			m.Combo("").Get(project.GetProject).
+				Patch(reqToken(), bind(api.EditProjectOption{}), project.EditProject).
+				Delete(reqToken(), project.DeleteProject)
+			m.Group("/boards", func() {
+				m.Combo("").Get(project.ListProjectBoards).
+					Post(reqToken(), bind(api.CreateProjectBoardOption{}), project.CreateProjectBoard)
+				m.Combo("/{board_id}").Patch(reqToken(), bind(api.EditProjectBoardOption{}), project.EditProjectBoard).
+					Delete(reqToken(), project.DeleteProjectBoard)
+			})
+			m.Group("/issues", func() {
+				m.Combo("").Get(project.ListProjectIssues).
+					Post(reqToken(), bind(api.AddProjectIssueOption{}), project.AddProjectIssue)
+				m.Delete("/{issue_id}", reqToken(), project.RemoveProjectIssue)
+			})
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryIssue), project.MustEnableProjects)
 
 		m.Group("/packages/{username}", func() {
 			m.Group("/{type}/{name}/{version}", func() {
@@ -1078,16 +1201,16 @@ func Routes() *web.Route {
 				m.Get("/files", packages.ListPackageFiles)
 			})
 			m.Get("/", packages.ListPackages)
-		}, context_service.UserAssignmentAPI(), context.PackageAssignmentAPI(), reqPackageAccess(perm.AccessModeRead))
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryPackage), context_service.UserAssignmentAPI(), context.PackageAssignmentAPI(), reqPackageAccess(perm.AccessModeRead))
 
 		// Organizations
-		m.Get("/user/orgs", reqToken(), org.ListMyOrgs)
+		m.Get("/user/orgs", reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization), org.ListMyOrgs)
 		m.Group("/users/{username}/orgs", func() {
 			m.Get("", org.ListUserOrgs)
 			m.Get("/{org}/permissions", reqToken(), org.GetUserOrgsPermissions)
-		}, context_service.UserAssignmentAPI())
-		m.Post("/orgs", reqToken(), bind(api.CreateOrgOption{}), org.Create)
-		m.Get("/orgs", org.GetAll)
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization), context_service.UserAssignmentAPI())
+		m.Post("/orgs", reqToken(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization), bind(api.CreateOrgOption{}), org.Create)
+		m.Get("/orgs", tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization), org.GetAll)
 		m.Group("/orgs/{org}", func() {
 			m.Combo("").Get(org.Get).
 				Patch(reqToken(), reqOrgOwnership(), bind(api.EditOrgOption{}), org.Edit).
@@ -1124,7 +1247,30 @@ func Routes() *web.Route {
 					Patch(bind(api.EditHookOption{}), org.EditHook).
 					Delete(org.DeleteHook)
 			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
-		}, orgAssignment(true))
+			m.Group("/secrets", func() {
+				m.Get("", org.ListSecrets)
+				m.Combo("/{secretname}").
+					Put(bind(api.CreateOrUpdateSecretOption{}), org.CreateOrUpdateSecret).
+					Delete(org.DeleteSecret)
+			}, reqToken(), reqOrgOwnership())
+			m.Group("/deploy_tokens", func() {
+				m.Combo("").Get(org.ListDeployTokens).
+					Post(bind(api.CreateDeployTokenOption{}), org.CreateDeployToken)
+				m.Delete("/{id}", org.DeleteDeployToken)
+			}, reqToken(), reqOrgOwnership())
+			m.Combo("/push_policy", reqToken(), reqOrgOwnership()).
+				Get(org.GetPushPolicy).
+				Put(bind(api.EditPushPolicyOption{}), org.EditPushPolicy).
+				Delete(org.DeletePushPolicy)
+			m.Group("/installations", func() {
+				m.Get("", org.ListInstallations)
+				m.Combo("/{app}").
+					Put(bind(api.InstallAppOption{}), org.InstallApp).
+					Delete(org.UninstallApp)
+			}, reqToken(), reqOrgOwnership())
+			m.Combo("/projects", project.MustEnableProjects).Get(project.ListOrgProjects).
+				Post(reqToken(), bind(api.CreateProjectOption{}), project.CreateOrgProject)
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization), orgAssignment(true))
 		m.Group("/teams/{teamid}", func() {
 			m.Combo("").Get(org.GetTeam).
 				Patch(reqOrgOwnership(), bind(api.EditTeamOption{}), org.EditTeam).
@@ -1143,7 +1289,7 @@ func Routes() *web.Route {
 					Delete(org.RemoveTeamRepository).
 					Get(org.GetTeamRepo)
 			})
-		}, orgAssignment(false, true), reqToken(), reqTeamMembership())
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization), orgAssignment(false, true), reqToken(), reqTeamMembership())
 
 		m.Group("/admin", func() {
 			m.Group("/cron", func() {
@@ -1166,17 +1312,25 @@ func Routes() *web.Route {
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				}, context_service.UserAssignmentAPI())
 			})
+			m.Get("/audit", admin.ListAuditEvents)
 			m.Group("/unadopted", func() {
 				m.Get("", admin.ListUnadoptedRepositories)
 				m.Post("/{username}/{reponame}", admin.AdoptRepository)
 				m.Delete("/{username}/{reponame}", admin.DeleteUnadoptedRepository)
 			})
-		}, reqToken(), reqSiteAdmin())
+		}, reqToken(), reqSiteAdmin(), tokenRequiresScopes(auth_model.AccessTokenScopeCategoryAdmin))
 
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
-		})
-	}, sudo())
+		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryRepository))
+
+		// Apps, authenticated with the JWT of the app
+		m.Group("/app", func() {
+			m.Get("", app.GetAuthenticated)
+			m.Get("/installations", app.ListInstallations)
+			m.Post("/installations/{id}/access_tokens", app.CreateInstallationToken)
+		}, app.ReqJWT())
+	}, sudo(), tokenRestriction())
 
 	return m
 }
@@ -0,0 +1,153 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	app_model "code.gitea.io/gitea/models/app"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	app_service "code.gitea.io/gitea/services/app"
+)
+
+const appKey = "App"
+
+// ReqJWT requires the request to be authenticated with a JWT signed by an app
+func ReqJWT() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		auth := strings.Fields(ctx.Req.Header.Get("Authorization"))
+		if len(auth) != 2 || !strings.EqualFold(auth[0], "bearer") {
+			ctx.Error(http.StatusUnauthorized, "ReqJWT", "the request must be authenticated with the JWT of an app")
+			return
+		}
+		a, err := app_service.VerifyJWT(ctx, auth[1])
+		if err != nil {
+			ctx.Error(http.StatusUnauthorized, "VerifyJWT", err)
+			return
+		}
+		ctx.Data[appKey] = a
+	}
+}
+
+func getApp(ctx *context.APIContext) *app_model.App {
+	return ctx.Data[appKey].(*app_model.App)
+}
+
+// GetAuthenticated get the app the request is authenticated with
+func GetAuthenticated(ctx *context.APIContext) {
+	// swagger:operation GET /app app appGetAuthenticated
+	// ---
+	// summary: Get the app the request is authenticated with, requires the JWT of the app
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/App"
+	//   "401":
+	//     "$ref": "#/responses/error"
+
+	apiApp, err := convert.ToApp(ctx, getApp(ctx), nil)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToApp", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiApp)
+}
+
+// ListInstallations list the installations of the app the request is authenticated with
+func ListInstallations(ctx *context.APIContext) {
+	// swagger:operation GET /app/installations app appListInstallations
+	// ---
+	// summary: List the installations of the app the request is authenticated with, requires the JWT of the app
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AppInstallationList"
+	//   "401":
+	//     "$ref": "#/responses/error"
+
+	installations, total, err := app_model.FindInstallations(ctx, app_model.FindInstallationsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		AppID:       getApp(ctx).ID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindInstallations", err)
+		return
+	}
+
+	apiInstallations := make([]*api.AppInstallation, len(installations))
+	for i := range installations {
+		if apiInstallations[i], err = convert.ToAppInstallation(ctx, installations[i], nil); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToAppInstallation", err)
+			return
+		}
+	}
+
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, &apiInstallations)
+}
+
+// CreateInstallationToken creates an installation token of the app the request is authenticated with
+func CreateInstallationToken(ctx *context.APIContext) {
+	// swagger:operation POST /app/installations/{id}/access_tokens app appCreateInstallationToken
+	// ---
+	// summary: Create a short-lived access token of the bot of the app on an installation, requires the JWT of the app
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the installation
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/InstallationToken"
+	//   "401":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	a := getApp(ctx)
+	i, err := app_model.GetInstallationByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if app_model.IsErrInstallationNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetInstallationByID", err)
+		}
+		return
+	}
+	if i.AppID != a.ID {
+		ctx.NotFound()
+		return
+	}
+
+	t, err := app_service.CreateInstallationToken(ctx, a, i)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateInstallationToken", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, &api.InstallationToken{
+		Token:       t.Token,
+		Permissions: t.Scope.Scopes(),
+		ExpiresAt:   t.ExpiresUnix.AsTime(),
+	})
+}
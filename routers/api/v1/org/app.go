@@ -0,0 +1,195 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	app_model "code.gitea.io/gitea/models/app"
+	audit_model "code.gitea.io/gitea/models/audit"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	app_service "code.gitea.io/gitea/services/app"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// ListInstallations list the apps installed by an organization
+func ListInstallations(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/installations organization orgListAppInstallations
+	// ---
+	// summary: List the apps installed by an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AppInstallationList"
+
+	installations, total, err := app_model.FindInstallations(ctx, app_model.FindInstallationsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		OwnerID:     ctx.Org.Organization.ID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindInstallations", err)
+		return
+	}
+
+	apiInstallations := make([]*api.AppInstallation, len(installations))
+	for i := range installations {
+		if apiInstallations[i], err = convert.ToAppInstallation(ctx, installations[i], ctx.Doer); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToAppInstallation", err)
+			return
+		}
+	}
+
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, &apiInstallations)
+}
+
+func getAppByName(ctx *context.APIContext) *app_model.App {
+	a, err := app_model.GetAppByName(ctx, ctx.Params(":app"))
+	if err != nil {
+		if app_model.IsErrAppNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAppByName", err)
+		}
+		return nil
+	}
+	return a
+}
+
+// InstallApp installs an app on an organization
+func InstallApp(ctx *context.APIContext) {
+	// swagger:operation PUT /orgs/{org}/installations/{app} organization orgInstallApp
+	// ---
+	// summary: Install an app on all the repositories of an organization or on the selected ones, replaces the repositories of an existing installation
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: app
+	//   in: path
+	//   description: name of the app
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/InstallAppOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AppInstallation"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.InstallAppOption)
+
+	a := getAppByName(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	repoIDs := make([]int64, 0, len(form.Repositories))
+	if !form.AllRepositories {
+		for _, name := range form.Repositories {
+			repo, err := repo_model.GetRepositoryByName(ctx.Org.Organization.ID, name)
+			if err != nil {
+				if repo_model.IsErrRepoNotExist(err) {
+					ctx.Error(http.StatusUnprocessableEntity, "GetRepositoryByName", err)
+				} else {
+					ctx.Error(http.StatusInternalServerError, "GetRepositoryByName", err)
+				}
+				return
+			}
+			repoIDs = append(repoIDs, repo.ID)
+		}
+	}
+
+	i, err := app_service.Install(ctx, a, ctx.Org.Organization.AsUser(), form.AllRepositories, repoIDs)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Install", err)
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionAppInstall, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.App(a), nil, nil)
+
+	apiInstallation, err := convert.ToAppInstallation(ctx, i, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToAppInstallation", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiInstallation)
+}
+
+// UninstallApp uninstalls an app from an organization
+func UninstallApp(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/installations/{app} organization orgUninstallApp
+	// ---
+	// summary: Uninstall an app, its installation tokens are revoked
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: app
+	//   in: path
+	//   description: name of the app
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	a := getAppByName(ctx)
+	if ctx.Written() {
+		return
+	}
+	i, err := app_model.GetInstallation(ctx, a.ID, ctx.Org.Organization.ID)
+	if err != nil {
+		if app_model.IsErrInstallationNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetInstallation", err)
+		}
+		return
+	}
+	if err := app_service.Uninstall(ctx, a, i); err != nil {
+		ctx.Error(http.StatusInternalServerError, "Uninstall", err)
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionAppUninstall, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.App(a), nil, nil)
+	ctx.Status(http.StatusNoContent)
+}
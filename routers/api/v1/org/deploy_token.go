@@ -0,0 +1,98 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// ListDeployTokens list the deploy tokens of an organization
+func ListDeployTokens(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/deploy_tokens organization orgListDeployTokens
+	// ---
+	// summary: List the deploy tokens of an organization, their values are never returned
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeployTokenList"
+
+	utils.ListDeployTokens(ctx, ctx.Org.Organization.ID, 0)
+}
+
+// CreateDeployToken creates a deploy token of an organization
+func CreateDeployToken(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/deploy_tokens organization orgCreateDeployToken
+	// ---
+	// summary: Create a deploy token, its value is only returned once
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateDeployTokenOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/DeployToken"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.CreateDeployToken(ctx, ctx.Org.Organization.ID, 0, audit_service.User(ctx.Org.Organization.AsUser()), web.GetForm(ctx).(*api.CreateDeployTokenOption))
+}
+
+// DeleteDeployToken removes a deploy token of an organization
+func DeleteDeployToken(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/deploy_tokens/{id} organization orgDeleteDeployToken
+	// ---
+	// summary: Delete a deploy token
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the deploy token
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteDeployToken(ctx, ctx.Org.Organization.ID, 0, audit_service.User(ctx.Org.Organization.AsUser()))
+}
@@ -7,12 +7,14 @@ package org
 import (
 	"net/http"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 )
 
 // ListHooks list an organziation's webhooks
@@ -190,8 +192,11 @@ func DeleteHook(ctx *context.APIContext) {
 	//     "$ref": "#/responses/empty"
 
 	org := ctx.Org.Organization
-	hookID := ctx.ParamsInt64(":id")
-	if err := webhook.DeleteWebhookByOrgID(org.ID, hookID); err != nil {
+	hook, err := utils.GetOrgHook(ctx, org.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	if err := webhook.DeleteWebhookByOrgID(org.ID, hook.ID); err != nil {
 		if webhook.IsErrWebhookNotExist(err) {
 			ctx.NotFound()
 		} else {
@@ -199,5 +204,6 @@ func DeleteHook(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit_service.RecordWebhook(ctx, audit_model.ActionWebhookDelete, ctx.Doer, ctx.Req.RemoteAddr, hook, audit_service.WebhookStateOf(hook), nil)
 	ctx.Status(http.StatusNoContent)
 }
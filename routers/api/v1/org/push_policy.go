@@ -0,0 +1,145 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// GetPushPolicy get the push policy of an organization
+func GetPushPolicy(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/push_policy organization orgGetPushPolicy
+	// ---
+	// summary: Get the push policy of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushPolicy"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p, err := git_model.GetPushPolicy(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushPolicy", err)
+		return
+	}
+	if p == nil {
+		ctx.NotFound()
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPushPolicy(p))
+}
+
+// EditPushPolicy sets the push policy of an organization
+func EditPushPolicy(ctx *context.APIContext) {
+	// swagger:operation PUT /orgs/{org}/push_policy organization orgEditPushPolicy
+	// ---
+	// summary: Set the push policy of an organization, the pushes to its repositories are checked against it
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EditPushPolicyOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushPolicy"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPushPolicyOption)
+	p, err := git_model.GetPushPolicy(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushPolicy", err)
+		return
+	}
+	var before *audit_service.PushPolicyState
+	if p == nil {
+		p = &git_model.PushPolicy{OwnerID: ctx.Org.Organization.ID}
+	} else {
+		before = audit_service.PushPolicyStateOf(p)
+	}
+
+	p.DryRun = form.DryRun
+	p.MaxFileSize = form.MaxFileSize
+	p.ForbiddenPaths = form.ForbiddenPaths
+	p.ForbiddenExtensions = form.ForbiddenExtensions
+	p.RequireSignedCommits = form.RequireSignedCommits
+	p.CommitMessagePattern = form.CommitMessagePattern
+	p.AllowedEmailDomains = form.AllowedEmailDomains
+	p.SecretPatterns = form.SecretPatterns
+	if err := git_model.UpdatePushPolicy(ctx, p); err != nil {
+		if git_model.IsErrInvalidPushPolicy(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdatePushPolicy", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdatePushPolicy", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionPushPolicyUpdate, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.PushPolicy(p), before, audit_service.PushPolicyStateOf(p))
+
+	ctx.JSON(http.StatusOK, convert.ToPushPolicy(p))
+}
+
+// DeletePushPolicy removes the push policy of an organization
+func DeletePushPolicy(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/push_policy organization orgDeletePushPolicy
+	// ---
+	// summary: Delete the push policy of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p, err := git_model.GetPushPolicy(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushPolicy", err)
+		return
+	}
+	if p == nil {
+		ctx.NotFound()
+		return
+	}
+	if err := git_model.DeletePushPolicy(ctx, ctx.Org.Organization.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeletePushPolicy", err)
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionPushPolicyDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.PushPolicy(p), audit_service.PushPolicyStateOf(p), nil)
+
+	ctx.Status(http.StatusNoContent)
+}
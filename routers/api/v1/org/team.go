@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -22,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 )
 
 // ListTeams list all the teams of an organization
@@ -222,6 +224,7 @@ func CreateTeam(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamCreate, ctx.Doer, ctx.Req.RemoteAddr, team, nil, audit_service.TeamStateOf(team))
 
 	apiTeam, err := convert.ToTeam(team)
 	if err != nil {
@@ -260,6 +263,7 @@ func EditTeam(ctx *context.APIContext) {
 		ctx.InternalServerError(err)
 		return
 	}
+	before := audit_service.TeamStateOf(team)
 
 	if form.CanCreateOrgRepo != nil {
 		team.CanCreateOrgRepo = *form.CanCreateOrgRepo
@@ -305,6 +309,11 @@ func EditTeam(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "EditTeam", err)
 		return
 	}
+	if err := team.GetUnits(); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamUpdate, ctx.Doer, ctx.Req.RemoteAddr, team, before, audit_service.TeamStateOf(team))
 
 	apiTeam, err := convert.ToTeam(team)
 	if err != nil {
@@ -330,10 +339,17 @@ func DeleteTeam(ctx *context.APIContext) {
 	//   "204":
 	//     description: team deleted
 
+	if err := ctx.Org.Team.GetUnits(); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	before := audit_service.TeamStateOf(ctx.Org.Team)
+
 	if err := models.DeleteTeam(ctx.Org.Team); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteTeam", err)
 		return
 	}
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamDelete, ctx.Doer, ctx.Req.RemoteAddr, ctx.Org.Team, before, nil)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -464,6 +480,7 @@ func AddTeamMember(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "AddMember", err)
 		return
 	}
+	audit_service.RecordTeamMember(ctx, ctx.Doer, ctx.Req.RemoteAddr, ctx.Org.Team, u, true)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -501,6 +518,7 @@ func RemoveTeamMember(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "RemoveTeamMember", err)
 		return
 	}
+	audit_service.RecordTeamMember(ctx, ctx.Doer, ctx.Req.RemoteAddr, ctx.Org.Team, u, false)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -660,6 +678,7 @@ func AddTeamRepository(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "AddRepository", err)
 		return
 	}
+	audit_service.RecordTeamRepository(ctx, ctx.Doer, ctx.Req.RemoteAddr, ctx.Org.Team, repo, true)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -710,6 +729,7 @@ func RemoveTeamRepository(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "RemoveRepository", err)
 		return
 	}
+	audit_service.RecordTeamRepository(ctx, ctx.Doer, ctx.Req.RemoteAddr, ctx.Org.Team, repo, false)
 	ctx.Status(http.StatusNoContent)
 }
 
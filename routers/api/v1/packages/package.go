@@ -41,7 +41,7 @@ func ListPackages(ctx *context.APIContext) {
 	//   in: query
 	//   description: package type filter
 	//   type: string
-	//   enum: [composer, conan, container, generic, helm, maven, npm, nuget, pub, pypi, rubygems]
+	//   enum: [cargo, composer, conan, container, debian, generic, go, helm, maven, npm, nuget, pub, pypi, rubygems]
 	// - name: q
 	//   in: query
 	//   description: name filter
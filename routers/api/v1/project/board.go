@@ -0,0 +1,244 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/perm"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// getProjectBoardByParams returns the board given by the :board_id parameter if it belongs to the project
+func getProjectBoardByParams(ctx *context.APIContext, p *project_model.Project) *project_model.Board {
+	board, err := project_model.GetBoard(ctx, ctx.ParamsInt64(":board_id"))
+	if err != nil {
+		if project_model.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBoard", err)
+		}
+		return nil
+	}
+	if board.ProjectID != p.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return board
+}
+
+// ListProjectBoards lists the boards of a project
+func ListProjectBoards(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id}/boards project projectListProjectBoards
+	// ---
+	// summary: List the boards of a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProjectByParams(ctx, perm.AccessModeRead)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := project_model.GetBoards(ctx, p.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBoards", err)
+		return
+	}
+
+	apiBoards := make([]*api.ProjectBoard, len(boards))
+	for i := range boards {
+		apiBoards[i] = convert.ToAPIProjectBoard(boards[i])
+	}
+	ctx.JSON(http.StatusOK, apiBoards)
+}
+
+// CreateProjectBoard creates a board of a project
+func CreateProjectBoard(ctx *context.APIContext) {
+	// swagger:operation POST /projects/{id}/boards project projectCreateProjectBoard
+	// ---
+	// summary: Create a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectBoardOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateProjectBoardOption)
+	p := getProjectByParams(ctx, perm.AccessModeWrite)
+	if ctx.Written() {
+		return
+	}
+
+	if len(form.Color) != 0 && !project_model.BoardColorPattern.MatchString(form.Color) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "invalid color code")
+		return
+	}
+
+	board := &project_model.Board{
+		ProjectID: p.ID,
+		Title:     form.Title,
+		Color:     form.Color,
+		CreatorID: ctx.Doer.ID,
+	}
+	if err := project_model.NewBoard(board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewBoard", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToAPIProjectBoard(board))
+}
+
+// EditProjectBoard updates a board of a project
+func EditProjectBoard(ctx *context.APIContext) {
+	// swagger:operation PATCH /projects/{id}/boards/{board_id} project projectEditProjectBoard
+	// ---
+	// summary: Update a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectBoardOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoard"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditProjectBoardOption)
+	p := getProjectByParams(ctx, perm.AccessModeWrite)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoardByParams(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != nil && len(*form.Title) > 0 {
+		board.Title = *form.Title
+	}
+	if form.Color != nil {
+		if len(*form.Color) != 0 && !project_model.BoardColorPattern.MatchString(*form.Color) {
+			ctx.Error(http.StatusUnprocessableEntity, "", "invalid color code")
+			return
+		}
+		board.Color = *form.Color
+	}
+	if form.Sorting != nil {
+		board.Sorting = *form.Sorting
+	}
+	if err := project_model.UpdateBoard(ctx, board); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateBoard", err)
+		return
+	}
+
+	if form.Default != nil && *form.Default && !board.Default {
+		if err := project_model.SetDefaultBoard(p.ID, board.ID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "SetDefaultBoard", err)
+			return
+		}
+		board.Default = true
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToAPIProjectBoard(board))
+}
+
+// DeleteProjectBoard deletes a board of a project
+func DeleteProjectBoard(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id}/boards/{board_id} project projectDeleteProjectBoard
+	// ---
+	// summary: Delete a board of a project
+	// description: Issues on the deleted board are moved to the default board
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProjectByParams(ctx, perm.AccessModeWrite)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoardByParams(ctx, p)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.DeleteBoardByID(board.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteBoardByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,263 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"net/http"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	project_model "code.gitea.io/gitea/models/project"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+
+	"xorm.io/builder"
+)
+
+// ListProjectIssues lists the issues and pull requests of a project
+func ListProjectIssues(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id}/issues project projectListProjectIssues
+	// ---
+	// summary: List the issues and pull requests of a project
+	// description: Only issues and pull requests of repositories the authenticated user can read are listed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProjectByParams(ctx, perm.AccessModeRead)
+	if ctx.Written() {
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+
+	opts := &issues_model.IssuesOptions{
+		ListOptions: listOptions,
+		ProjectID:   p.ID,
+		// issues and pull requests are only listed from repositories whose matching unit the doer can read
+		RepoCond: builder.Or(
+			builder.And(
+				builder.Eq{"issue.is_pull": false},
+				repo_model.AccessibleRepositoryCondition(ctx.Doer, unit.TypeIssues),
+			),
+			builder.And(
+				builder.Eq{"issue.is_pull": true},
+				repo_model.AccessibleRepositoryCondition(ctx.Doer, unit.TypePullRequests),
+			),
+		),
+		SortType: "project-column-sorting",
+	}
+
+	issues, err := issues_model.Issues(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Issues", err)
+		return
+	}
+	total, err := issues_model.CountIssues(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountIssues", err)
+		return
+	}
+
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
+}
+
+// AddProjectIssue adds an issue or pull request to a project
+func AddProjectIssue(ctx *context.APIContext) {
+	// swagger:operation POST /projects/{id}/issues project projectAddProjectIssue
+	// ---
+	// summary: Add an issue or pull request to a project
+	// description: An issue can only be assigned to one project, so it is removed from its previous project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/AddProjectIssueOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Issue"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.AddProjectIssueOption)
+	p := getProjectByParams(ctx, perm.AccessModeWrite)
+	if ctx.Written() {
+		return
+	}
+
+	issue, err := issues_model.GetIssueByID(ctx, form.IssueID)
+	if err != nil {
+		if issues_model.IsErrIssueNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", "issue does not exist")
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByID", err)
+		}
+		return
+	}
+	if err := issue.LoadRepo(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadRepo", err)
+		return
+	}
+	permission, err := access_model.GetUserRepoPermission(ctx, issue.Repo, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return
+	}
+	if !permission.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "issue does not exist")
+		return
+	}
+	// adding the issue changes it and removes it from its current project
+	if !permission.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "", "you must be able to write the issue to add it to a project")
+		return
+	}
+	if !p.IsOwnerProject() && p.RepoID != issue.RepoID {
+		ctx.Error(http.StatusUnprocessableEntity, "", "issue does not belong to the repository of the project")
+		return
+	}
+
+	var board *project_model.Board
+	if form.BoardID > 0 {
+		board, err = project_model.GetBoard(ctx, form.BoardID)
+		if err != nil && !project_model.IsErrProjectBoardNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, "GetBoard", err)
+			return
+		}
+		if board == nil || board.ProjectID != p.ID {
+			ctx.Error(http.StatusUnprocessableEntity, "", "board does not exist")
+			return
+		}
+	}
+
+	if issue.ProjectID() != p.ID {
+		if err := issues_model.ChangeProjectAssign(issue, ctx.Doer, p.ID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeProjectAssign", err)
+			return
+		}
+	}
+	if board != nil {
+		if err := issues_model.MoveIssueAcrossProjectBoards(issue, board); err != nil {
+			ctx.Error(http.StatusInternalServerError, "MoveIssueAcrossProjectBoards", err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToAPIIssue(issue))
+}
+
+// RemoveProjectIssue removes an issue or pull request from a project
+func RemoveProjectIssue(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id}/issues/{issue_id} project projectRemoveProjectIssue
+	// ---
+	// summary: Remove an issue or pull request from a project
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: issue_id
+	//   in: path
+	//   description: id of the issue or pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProjectByParams(ctx, perm.AccessModeWrite)
+	if ctx.Written() {
+		return
+	}
+
+	issue, err := issues_model.GetIssueByID(ctx, ctx.ParamsInt64(":issue_id"))
+	if err != nil {
+		if issues_model.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByID", err)
+		}
+		return
+	}
+	if issue.ProjectID() != p.ID {
+		ctx.NotFound()
+		return
+	}
+	if err := issue.LoadRepo(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadRepo", err)
+		return
+	}
+	permission, err := access_model.GetUserRepoPermission(ctx, issue.Repo, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return
+	}
+	if !permission.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound()
+		return
+	}
+	// removing the issue changes it
+	if !permission.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(http.StatusForbidden, "", "you must be able to write the issue to remove it from a project")
+		return
+	}
+
+	if err := issues_model.ChangeProjectAssign(issue, ctx.Doer, 0); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ChangeProjectAssign", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
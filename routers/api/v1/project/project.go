@@ -0,0 +1,432 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// MustEnableProjects responds with 404 if projects are disabled on the instance
+func MustEnableProjects(ctx *context.APIContext) {
+	if unit.TypeProjects.UnitGlobalDisabled() {
+		ctx.NotFound()
+	}
+}
+
+// getProjectAccessMode returns the access mode of the doer to the project
+func getProjectAccessMode(ctx *context.APIContext, p *project_model.Project) (perm.AccessMode, error) {
+	if p.IsOwnerProject() {
+		if err := p.LoadOwner(ctx); err != nil {
+			return perm.AccessModeNone, err
+		}
+		return access_model.GetOwnerUnitAccessMode(ctx, p.Owner, ctx.Doer, unit.TypeProjects)
+	}
+
+	if err := p.LoadRepo(ctx); err != nil {
+		return perm.AccessModeNone, err
+	}
+	permission, err := access_model.GetUserRepoPermission(ctx, p.Repo, ctx.Doer)
+	if err != nil {
+		return perm.AccessModeNone, err
+	}
+	if p.Repo.IsArchived && permission.UnitAccessMode(unit.TypeProjects) > perm.AccessModeRead {
+		return perm.AccessModeRead, nil
+	}
+	return permission.UnitAccessMode(unit.TypeProjects), nil
+}
+
+// getProjectByParams returns the project given by the :id parameter if the doer has at least the given access mode
+func getProjectByParams(ctx *context.APIContext, mode perm.AccessMode) *project_model.Project {
+	p, err := project_model.GetProjectByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if project_model.IsErrProjectNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetProjectByID", err)
+		}
+		return nil
+	}
+
+	accessMode, err := getProjectAccessMode(ctx, p)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "getProjectAccessMode", err)
+		return nil
+	}
+	if accessMode < perm.AccessModeRead {
+		ctx.NotFound()
+		return nil
+	}
+	if accessMode < mode {
+		ctx.Error(http.StatusForbidden, "", "user should have write permission to the projects")
+		return nil
+	}
+	return p
+}
+
+func listOwnerProjects(ctx *context.APIContext, owner *user_model.User) {
+	accessMode, err := access_model.GetOwnerUnitAccessMode(ctx, owner, ctx.Doer, unit.TypeProjects)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOwnerUnitAccessMode", err)
+		return
+	}
+	if accessMode < perm.AccessModeRead {
+		ctx.NotFound()
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+
+	var isClosed util.OptionalBool
+	switch ctx.FormString("state") {
+	case "closed":
+		isClosed = util.OptionalBoolTrue
+	case "all":
+		isClosed = util.OptionalBoolNone
+	default:
+		isClosed = util.OptionalBoolFalse
+	}
+
+	projects, total, err := project_model.GetProjects(ctx, project_model.SearchOptions{
+		OwnerID:  owner.ID,
+		Page:     listOptions.Page,
+		PageSize: listOptions.PageSize,
+		IsClosed: isClosed,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProjects", err)
+		return
+	}
+
+	apiProjects := make([]*api.Project, 0, len(projects))
+	for _, p := range projects {
+		p.Owner = owner
+		apiProject, err := convert.ToAPIProject(ctx, p, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToAPIProject", err)
+			return
+		}
+		apiProjects = append(apiProjects, apiProject)
+	}
+
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiProjects)
+}
+
+func createOwnerProject(ctx *context.APIContext, owner *user_model.User) {
+	form := web.GetForm(ctx).(*api.CreateProjectOption)
+
+	accessMode, err := access_model.GetOwnerUnitAccessMode(ctx, owner, ctx.Doer, unit.TypeProjects)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOwnerUnitAccessMode", err)
+		return
+	}
+	if accessMode < perm.AccessModeWrite {
+		ctx.Error(http.StatusForbidden, "", "user should have write permission to the projects")
+		return
+	}
+
+	var boardType project_model.BoardType
+	switch form.BoardType {
+	case "", "none":
+		boardType = project_model.BoardTypeNone
+	case "basic_kanban":
+		boardType = project_model.BoardTypeBasicKanban
+	case "bug_triage":
+		boardType = project_model.BoardTypeBugTriage
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", "invalid board type")
+		return
+	}
+
+	projectType := project_model.TypeIndividual
+	if owner.IsOrganization() {
+		projectType = project_model.TypeOrganization
+	}
+
+	p := &project_model.Project{
+		OwnerID:     owner.ID,
+		Title:       form.Title,
+		Description: form.Description,
+		CreatorID:   ctx.Doer.ID,
+		BoardType:   boardType,
+		Type:        projectType,
+	}
+	if err := project_model.NewProject(p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewProject", err)
+		return
+	}
+
+	apiProject, err := convert.ToAPIProject(ctx, p, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToAPIProject", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, apiProject)
+}
+
+// ListOrgProjects lists the projects of an organization
+func ListOrgProjects(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/projects project projectListOrgProjects
+	// ---
+	// summary: List the projects of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, Recognized values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listOwnerProjects(ctx, ctx.Org.Organization.AsUser())
+}
+
+// CreateOrgProject creates a project of an organization
+func CreateOrgProject(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/projects project projectCreateOrgProject
+	// ---
+	// summary: Create a project of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	createOwnerProject(ctx, ctx.Org.Organization.AsUser())
+}
+
+// ListUserProjects lists the projects of an individual
+func ListUserProjects(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/projects project projectListUserProjects
+	// ---
+	// summary: List the projects of a user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: Project state, Recognized values are open, closed and all. Defaults to "open"
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listOwnerProjects(ctx, ctx.ContextUser)
+}
+
+// CreateUserProject creates a project of the authenticated user
+func CreateUserProject(ctx *context.APIContext) {
+	// swagger:operation POST /user/projects project projectCreateUserProject
+	// ---
+	// summary: Create a project of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	createOwnerProject(ctx, ctx.Doer)
+}
+
+// GetProject gets a project
+func GetProject(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id} project projectGetProject
+	// ---
+	// summary: Get a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProjectByParams(ctx, perm.AccessModeRead)
+	if ctx.Written() {
+		return
+	}
+
+	apiProject, err := convert.ToAPIProject(ctx, p, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToAPIProject", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiProject)
+}
+
+// EditProject updates a project
+func EditProject(ctx *context.APIContext) {
+	// swagger:operation PATCH /projects/{id} project projectEditProject
+	// ---
+	// summary: Update a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	form := web.GetForm(ctx).(*api.EditProjectOption)
+	p := getProjectByParams(ctx, perm.AccessModeWrite)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != nil && len(*form.Title) > 0 {
+		p.Title = *form.Title
+	}
+	if form.Description != nil {
+		p.Description = *form.Description
+	}
+	if err := project_model.UpdateProject(ctx, p); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProject", err)
+		return
+	}
+
+	if form.State != nil {
+		isClosed := api.StateClosed == api.StateType(*form.State)
+		if isClosed != p.IsClosed {
+			if err := project_model.ChangeProjectStatus(p, isClosed); err != nil {
+				ctx.Error(http.StatusInternalServerError, "ChangeProjectStatus", err)
+				return
+			}
+		}
+	}
+
+	apiProject, err := convert.ToAPIProject(ctx, p, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToAPIProject", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiProject)
+}
+
+// DeleteProject deletes a project
+func DeleteProject(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id} project projectDeleteProject
+	// ---
+	// summary: Delete a project
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p := getProjectByParams(ctx, perm.AccessModeWrite)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.DeleteProjectByID(p.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProjectByID", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	git_model "code.gitea.io/gitea/models/git"
 	"code.gitea.io/gitea/models/organization"
 	user_model "code.gitea.io/gitea/models/user"
@@ -20,6 +21,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -427,6 +429,11 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		requiredApprovals = form.RequiredApprovals
 	}
 
+	if _, err := git_model.ParseMergeWindows(form.MergeWindows); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "ParseMergeWindows", err)
+		return
+	}
+
 	whitelistUsers, err := user_model.GetUserIDsByNames(form.PushWhitelistUsernames, false)
 	if err != nil {
 		if user_model.IsErrUserNotExist(err) {
@@ -500,8 +507,11 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		BlockOnOfficialReviewRequests: form.BlockOnOfficialReviewRequests,
 		DismissStaleApprovals:         form.DismissStaleApprovals,
 		RequireSignedCommits:          form.RequireSignedCommits,
+		RequireCodeOwnerApproval:      form.RequireCodeOwnerApproval,
+		EnableMergeQueue:              form.EnableMergeQueue,
 		ProtectedFilePatterns:         form.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       form.UnprotectedFilePatterns,
+		MergeWindows:                  form.MergeWindows,
 		BlockOnOutdatedBranch:         form.BlockOnOutdatedBranch,
 	}
 
@@ -534,6 +544,8 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
+	audit_service.Record(ctx, audit_model.ActionBranchProtectionCreate, ctx.Doer, ctx.RemoteAddr(), audit_service.Repository(repo), audit_service.BranchProtection(bp), nil, audit_service.BranchProtectionStateOf(bp))
+
 	ctx.JSON(http.StatusCreated, convert.ToBranchProtection(bp))
 }
 
@@ -585,6 +597,7 @@ func EditBranchProtection(ctx *context.APIContext) {
 		ctx.NotFound()
 		return
 	}
+	before := audit_service.BranchProtectionStateOf(protectBranch)
 
 	if form.EnablePush != nil {
 		if !*form.EnablePush {
@@ -642,6 +655,14 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.RequireSignedCommits = *form.RequireSignedCommits
 	}
 
+	if form.RequireCodeOwnerApproval != nil {
+		protectBranch.RequireCodeOwnerApproval = *form.RequireCodeOwnerApproval
+	}
+
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
 	if form.ProtectedFilePatterns != nil {
 		protectBranch.ProtectedFilePatterns = *form.ProtectedFilePatterns
 	}
@@ -650,6 +671,14 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.UnprotectedFilePatterns = *form.UnprotectedFilePatterns
 	}
 
+	if form.MergeWindows != nil {
+		if _, err := git_model.ParseMergeWindows(*form.MergeWindows); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "ParseMergeWindows", err)
+			return
+		}
+		protectBranch.MergeWindows = *form.MergeWindows
+	}
+
 	if form.BlockOnOutdatedBranch != nil {
 		protectBranch.BlockOnOutdatedBranch = *form.BlockOnOutdatedBranch
 	}
@@ -769,6 +798,8 @@ func EditBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
+	audit_service.Record(ctx, audit_model.ActionBranchProtectionUpdate, ctx.Doer, ctx.RemoteAddr(), audit_service.Repository(repo), audit_service.BranchProtection(bp), before, audit_service.BranchProtectionStateOf(bp))
+
 	ctx.JSON(http.StatusOK, convert.ToBranchProtection(bp))
 }
 
@@ -817,6 +848,7 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
 		return
 	}
+	audit_service.Record(ctx, audit_model.ActionBranchProtectionDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.Repository(repo), audit_service.BranchProtection(bp), audit_service.BranchProtectionStateOf(bp), nil)
 
 	ctx.Status(http.StatusNoContent)
 }
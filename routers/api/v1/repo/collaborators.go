@@ -19,6 +19,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 )
 
 // ListCollaborators list a repository's collaborators
@@ -174,6 +175,12 @@ func AddCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	before, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollaboration", err)
+		return
+	}
+
 	if err := models.AddCollaborator(ctx.Repo.Repository, collaborator); err != nil {
 		ctx.Error(http.StatusInternalServerError, "AddCollaborator", err)
 		return
@@ -186,6 +193,13 @@ func AddCollaborator(ctx *context.APIContext) {
 		}
 	}
 
+	after, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollaboration", err)
+		return
+	}
+	audit_service.RecordCollaboration(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Repo.Repository, collaborator, before, after)
+
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -228,10 +242,17 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	before, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollaboration", err)
+		return
+	}
+
 	if err := models.DeleteCollaboration(ctx.Repo.Repository, collaborator.ID); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteCollaboration", err)
 		return
 	}
+	audit_service.RecordCollaboration(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Repo.Repository, collaborator, before, nil)
 	ctx.Status(http.StatusNoContent)
 }
 
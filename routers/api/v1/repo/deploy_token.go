@@ -0,0 +1,113 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// ListDeployTokens list the deploy tokens of a repository
+func ListDeployTokens(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/deploy_tokens repository repoListDeployTokens
+	// ---
+	// summary: List the deploy tokens of a repository, their values are never returned
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeployTokenList"
+
+	utils.ListDeployTokens(ctx, 0, ctx.Repo.Repository.ID)
+}
+
+// CreateDeployToken creates a deploy token of a repository
+func CreateDeployToken(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/deploy_tokens repository repoCreateDeployToken
+	// ---
+	// summary: Create a deploy token, its value is only returned once
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateDeployTokenOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/DeployToken"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.CreateDeployToken(ctx, 0, ctx.Repo.Repository.ID, audit_service.Repository(ctx.Repo.Repository), web.GetForm(ctx).(*api.CreateDeployTokenOption))
+}
+
+// DeleteDeployToken removes a deploy token of a repository
+func DeleteDeployToken(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/deploy_tokens/{id} repository repoDeleteDeployToken
+	// ---
+	// summary: Delete a deploy token
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the deploy token
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteDeployToken(ctx, 0, ctx.Repo.Repository.ID, audit_service.Repository(ctx.Repo.Repository))
+}
@@ -8,6 +8,7 @@ package repo
 import (
 	"net/http"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/context"
@@ -16,6 +17,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 	webhook_service "code.gitea.io/gitea/services/webhook"
 )
 
@@ -278,7 +280,11 @@ func DeleteHook(ctx *context.APIContext) {
 	//     "$ref": "#/responses/empty"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
-	if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, hook.ID); err != nil {
 		if webhook.IsErrWebhookNotExist(err) {
 			ctx.NotFound()
 		} else {
@@ -286,5 +292,6 @@ func DeleteHook(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit_service.RecordWebhook(ctx, audit_model.ActionWebhookDelete, ctx.Doer, ctx.Req.RemoteAddr, hook, audit_service.WebhookStateOf(hook), nil)
 	ctx.Status(http.StatusNoContent)
 }
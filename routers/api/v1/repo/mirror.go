@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -234,6 +235,137 @@ func GetPushMirrorByName(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, m)
 }
 
+// ListPushMirrorRefs get the sync status of the refs of a push mirror
+func ListPushMirrorRefs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors/{name}/refs repository repoListPushMirrorRefs
+	// ---
+	// summary: Get the sync status of the refs of a push mirror
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: remote name of push mirror
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirrorRefList"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pushMirror := getPushMirrorByName(ctx)
+	if pushMirror == nil {
+		return
+	}
+
+	refs, err := repo_model.GetPushMirrorRefs(ctx, pushMirror.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushMirrorRefs", err)
+		return
+	}
+
+	apiRefs := make([]*api.PushMirrorRef, 0, len(refs))
+	for _, ref := range refs {
+		apiRefs = append(apiRefs, convert.ToPushMirrorRef(ref))
+	}
+	ctx.JSON(http.StatusOK, apiRefs)
+}
+
+// ListPushMirrorSyncErrors get the latest sync errors of a push mirror
+func ListPushMirrorSyncErrors(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors/{name}/errors repository repoListPushMirrorSyncErrors
+	// ---
+	// summary: Get the latest sync errors of a push mirror
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: remote name of push mirror
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirrorSyncErrorList"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pushMirror := getPushMirrorByName(ctx)
+	if pushMirror == nil {
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	syncErrors, count, err := repo_model.GetPushMirrorSyncErrors(ctx, pushMirror.ID, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushMirrorSyncErrors", err)
+		return
+	}
+
+	apiSyncErrors := make([]*api.PushMirrorSyncError, 0, len(syncErrors))
+	for _, syncErr := range syncErrors {
+		apiSyncErrors = append(apiSyncErrors, convert.ToPushMirrorSyncError(syncErr))
+	}
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiSyncErrors)
+}
+
+func getPushMirrorByName(ctx *context.APIContext) *repo_model.PushMirror {
+	if !setting.Mirror.Enabled {
+		ctx.Error(http.StatusBadRequest, "GetPushMirrorByRemoteName", "Mirror feature is disabled")
+		return nil
+	}
+
+	pushMirror, err := repo_model.GetPushMirror(ctx, repo_model.PushMirrorOptions{RepoID: ctx.Repo.Repository.ID, RemoteName: ctx.Params(":name")})
+	if err != nil {
+		if errors.Is(err, repo_model.ErrPushMirrorNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPushMirror", err)
+		}
+		return nil
+	}
+	return pushMirror
+}
+
 // AddPushMirror adds a push mirror to a repository
 func AddPushMirror(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors repository repoAddPushMirror
@@ -330,6 +462,11 @@ func CreatePushMirror(ctx *context.APIContext, mirrorOption *api.CreatePushMirro
 		return
 	}
 
+	if _, err := repo_model.ParsePushMirrorRefFilter(mirrorOption.RefFilter); err != nil {
+		ctx.Error(http.StatusBadRequest, "CreatePushMirror", err)
+		return
+	}
+
 	address, err := forms.ParseRemoteAddr(mirrorOption.RemoteAddress, mirrorOption.RemoteUsername, mirrorOption.RemotePassword)
 	if err == nil {
 		err = migrations.IsMigrateURLAllowed(address, ctx.ContextUser)
@@ -346,10 +483,13 @@ func CreatePushMirror(ctx *context.APIContext, mirrorOption *api.CreatePushMirro
 	}
 
 	pushMirror := &repo_model.PushMirror{
-		RepoID:     repo.ID,
-		Repo:       repo,
-		RemoteName: fmt.Sprintf("remote_mirror_%s", remoteSuffix),
-		Interval:   interval,
+		RepoID:           repo.ID,
+		Repo:             repo,
+		RemoteName:       fmt.Sprintf("remote_mirror_%s", remoteSuffix),
+		RefFilter:        strings.TrimSpace(mirrorOption.RefFilter),
+		ProtectForcePush: mirrorOption.ProtectForcePush,
+		SyncOnCommit:     mirrorOption.SyncOnCommit,
+		Interval:         interval,
 	}
 
 	if err = repo_model.InsertPushMirror(ctx, pushMirror); err != nil {
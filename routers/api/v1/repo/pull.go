@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	pull_model "code.gitea.io/gitea/models/pull"
@@ -723,6 +724,8 @@ func MergePullRequest(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     description: the pull request was added to the merge queue of its base branch
 	//   "405":
 	//     "$ref": "#/responses/empty"
 	//   "409":
@@ -820,8 +823,8 @@ func MergePullRequest(ctx *context.APIContext) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
-	if form.MergeWhenChecksSucceed {
-		scheduled, err := automerge.ScheduleAutoMerge(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message)
+	if form.MergeWhenChecksSucceed || form.MergeAt > 0 {
+		scheduled, err := automerge.ScheduleAutoMerge(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message, timeutil.TimeStamp(form.MergeAt))
 		if err != nil {
 			if pull_model.IsErrAlreadyScheduledToAutoMerge(err) {
 				ctx.Error(http.StatusConflict, "ScheduleAutoMerge", err)
@@ -836,6 +839,30 @@ func MergePullRequest(ctx *context.APIContext) {
 		}
 	}
 
+	if !force {
+		mergeQueueEnabled, err := pull_service.IsMergeQueueEnabled(ctx, pr)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsMergeQueueEnabled", err)
+			return
+		}
+		if mergeQueueEnabled {
+			if err := pull_service.AddToMergeQueue(ctx, pr, ctx.Doer, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
+				if models.IsErrInvalidMergeStyle(err) {
+					ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", repo_model.MergeStyle(form.Do)))
+				} else if models.IsErrSHADoesNotMatch(err) {
+					ctx.Error(http.StatusConflict, "AddToMergeQueue", "head out of date")
+				} else if pull_model.IsErrAlreadyInMergeQueue(err) {
+					ctx.Error(http.StatusConflict, "AddToMergeQueue", err)
+				} else {
+					ctx.Error(http.StatusInternalServerError, "AddToMergeQueue", err)
+				}
+				return
+			}
+			ctx.Status(http.StatusAccepted)
+			return
+		}
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", repo_model.MergeStyle(form.Do)))
@@ -1148,7 +1175,7 @@ func UpdatePullRequest(ctx *context.APIContext) {
 func CancelScheduledAutoMerge(ctx *context.APIContext) {
 	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge repository repoCancelScheduledAutoMerge
 	// ---
-	// summary: Cancel the scheduled auto merge for the given pull request
+	// summary: Cancel the scheduled auto merge for the given pull request or remove it from the merge queue
 	// produces:
 	// - application/json
 	// parameters:
@@ -1193,7 +1220,7 @@ func CancelScheduledAutoMerge(ctx *context.APIContext) {
 		return
 	}
 	if !exist {
-		ctx.NotFound()
+		removeFromMergeQueue(ctx, pull)
 		return
 	}
 
@@ -1216,6 +1243,36 @@ func CancelScheduledAutoMerge(ctx *context.APIContext) {
 	}
 }
 
+func removeFromMergeQueue(ctx *context.APIContext, pull *issues_model.PullRequest) {
+	entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pull.ID)
+	if err != nil {
+		if db.IsErrNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.InternalServerError(err)
+		return
+	}
+
+	if ctx.Doer.ID != entry.DoerID {
+		allowed, err := access_model.IsUserRepoAdmin(ctx, ctx.Repo.Repository, ctx.Doer)
+		if err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		if !allowed {
+			ctx.Error(http.StatusForbidden, "No permission to remove", "user has no permission to remove the pull request from the merge queue")
+			return
+		}
+	}
+
+	if err := pull_service.RemoveFromMergeQueue(ctx, pull, ctx.Doer); err != nil {
+		ctx.InternalServerError(err)
+	} else {
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
 // GetPullRequestCommits gets all commits associated with a given PR
 func GetPullRequestCommits(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/commits repository repoGetPullRequestCommits
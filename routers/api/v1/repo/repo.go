@@ -8,10 +8,12 @@ package repo
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
@@ -30,6 +32,7 @@ import (
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
@@ -721,6 +724,9 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 		ctx.Error(http.StatusInternalServerError, "UpdateRepository", err)
 		return err
 	}
+	if visibilityChanged {
+		audit_service.Record(ctx, audit_model.ActionRepositoryVisibility, ctx.Doer, ctx.RemoteAddr(), audit_service.Repository(repo), audit_service.Repository(repo), &audit_service.VisibilityState{Private: !repo.IsPrivate}, audit_service.Visibility(repo))
+	}
 
 	log.Trace("Repository basic settings updated: %s/%s", owner.Name, repo.Name)
 	return nil
@@ -1082,3 +1088,77 @@ func GetIssueTemplates(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, ctx.IssueTemplatesFromDefaultBranch())
 }
+
+// GetIssueConfig returns the issue config for a repository
+func GetIssueConfig(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_config repository repoGetIssueConfig
+	// ---
+	// summary: Returns the issue config for a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoIssueConfig"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	issueConfig, err := ctx.IssueConfigFromDefaultBranch()
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "IssueConfigFromDefaultBranch", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, issueConfig)
+}
+
+// ValidateIssueConfig returns the validation of the issue config and the issue templates of a repository
+func ValidateIssueConfig(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issue_config/validate repository repoValidateIssueConfig
+	// ---
+	// summary: Returns the validation of the issue config and the issue templates of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoIssueConfigValidation"
+
+	messages := make([]string, 0, 5)
+	if _, err := ctx.IssueConfigFromDefaultBranch(); err != nil {
+		messages = append(messages, fmt.Sprintf("config: %v", err))
+	}
+	_, invalid := ctx.IssueTemplatesAndErrorsFromDefaultBranch()
+	filenames := make([]string, 0, len(invalid))
+	for filename := range invalid {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		messages = append(messages, fmt.Sprintf("%s: %v", filename, invalid[filename]))
+	}
+
+	ctx.JSON(http.StatusOK, api.IssueConfigValidation{
+		Valid:   len(messages) == 0,
+		Message: strings.Join(messages, "\n"),
+	})
+}
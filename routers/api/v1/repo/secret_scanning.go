@@ -0,0 +1,194 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	secretscan_model "code.gitea.io/gitea/models/secretscan"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListSecretScanningAlerts list the secret scanning alerts of a repository
+func ListSecretScanningAlerts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/secret_scanning/alerts repository repoListSecretScanningAlerts
+	// ---
+	// summary: List the secrets found in the files of a repository, the newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: only list the alerts in this state
+	//   type: string
+	//   enum: [open, resolved, dismissed]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SecretScanningAlertList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := secretscan_model.FindAlertsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		RepoID:      ctx.Repo.Repository.ID,
+	}
+	if state := ctx.FormString("state"); state != "" {
+		if opts.Status = secretscan_model.ParseAlertStatus(state); opts.Status == 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "invalid state")
+			return
+		}
+	}
+
+	alerts, count, err := secretscan_model.FindAlerts(ctx, opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiAlerts := make([]*api.SecretScanningAlert, len(alerts))
+	for i, a := range alerts {
+		if err := a.LoadResolver(ctx); err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		apiAlerts[i] = convert.ToSecretScanningAlert(a, ctx.Repo.Repository, ctx.Doer)
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiAlerts)
+}
+
+func getSecretScanningAlert(ctx *context.APIContext) *secretscan_model.Alert {
+	a, err := secretscan_model.GetAlertByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if secretscan_model.IsErrAlertNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.InternalServerError(err)
+		}
+		return nil
+	}
+	return a
+}
+
+// GetSecretScanningAlert get a secret scanning alert of a repository
+func GetSecretScanningAlert(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/secret_scanning/alerts/{id} repository repoGetSecretScanningAlert
+	// ---
+	// summary: Get a secret scanning alert
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the alert
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SecretScanningAlert"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	a := getSecretScanningAlert(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := a.LoadResolver(ctx); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToSecretScanningAlert(a, ctx.Repo.Repository, ctx.Doer))
+}
+
+// EditSecretScanningAlert resolves, dismisses or reopens a secret scanning alert of a repository
+func EditSecretScanningAlert(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/secret_scanning/alerts/{id} repository repoEditSecretScanningAlert
+	// ---
+	// summary: Resolve, dismiss or reopen a secret scanning alert
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the alert
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EditSecretScanningAlertOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SecretScanningAlert"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditSecretScanningAlertOption)
+	a := getSecretScanningAlert(ctx)
+	if ctx.Written() {
+		return
+	}
+	if status := secretscan_model.ParseAlertStatus(form.State); status != a.Status {
+		if err := secretscan_model.UpdateAlertStatus(ctx, a, status, ctx.Doer); err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+	}
+	if err := a.LoadResolver(ctx); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToSecretScanningAlert(a, ctx.Repo.Repository, ctx.Doer))
+}
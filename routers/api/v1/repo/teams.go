@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	audit_service "code.gitea.io/gitea/services/audit"
 )
 
 // ListTeams list a repository's teams
@@ -211,6 +212,7 @@ func changeRepoTeam(ctx *context.APIContext, add bool) {
 		ctx.InternalServerError(err)
 		return
 	}
+	audit_service.RecordTeamRepository(ctx, ctx.Doer, ctx.Req.RemoteAddr, team, ctx.Repo.Repository, add)
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -14,10 +14,12 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 	wiki_service "code.gitea.io/gitea/services/wiki"
 )
 
@@ -173,14 +175,21 @@ func getWikiPage(ctx *context.APIContext, title string) *api.WikiPage {
 		return nil
 	}
 
-	sidebarContent, _ := wikiContentsByName(ctx, commit, "_Sidebar", true)
-	if ctx.Written() {
-		return nil
+	// the nearest sidebar and footer of the page
+	var sidebarContent, footerContent string
+	for _, name := range wiki_service.SpecialPageCandidates(title, wiki_service.SidebarPageName) {
+		if sidebarContent, _ = wikiContentsByName(ctx, commit, name, true); ctx.Written() {
+			return nil
+		} else if sidebarContent != "" {
+			break
+		}
 	}
-
-	footerContent, _ := wikiContentsByName(ctx, commit, "_Footer", true)
-	if ctx.Written() {
-		return nil
+	for _, name := range wiki_service.SpecialPageCandidates(title, wiki_service.FooterPageName) {
+		if footerContent, _ = wikiContentsByName(ctx, commit, name, true); ctx.Written() {
+			return nil
+		} else if footerContent != "" {
+			break
+		}
 	}
 
 	// get commit count - wiki revisions
@@ -297,22 +306,90 @@ func ListWikiPages(ctx *context.APIContext) {
 	skip := (page - 1) * limit
 	max := page * limit
 
-	entries, err := commit.ListEntries()
+	pageList, err := wiki_service.ListPages(commit)
 	if err != nil {
-		ctx.ServerError("ListEntries", err)
+		ctx.Error(http.StatusInternalServerError, "ListPages", err)
 		return
 	}
-	pages := make([]*api.WikiPageMetaData, 0, len(entries))
-	for i, entry := range entries {
-		if i < skip || i >= max || !entry.IsRegular() {
+	pages := make([]*api.WikiPageMetaData, 0, limit)
+	for i, page := range pageList.Pages {
+		if i < skip || i >= max {
 			continue
 		}
-		c, err := wikiRepo.GetCommitByPath(entry.Name())
+		c, err := wikiRepo.GetCommitByPath(page.Entry.Name())
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
 			return
 		}
-		wikiName, err := wiki_service.FilenameToName(entry.Name())
+		pages = append(pages, convert.ToWikiPageMetaData(page.Name, c, ctx.Repo.Repository))
+	}
+
+	ctx.SetTotalCountHeader(int64(len(pageList.Pages)))
+	ctx.JSON(http.StatusOK, pages)
+}
+
+// SearchWikiPages search the wiki pages
+func SearchWikiPages(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/wiki/search repository repoSearchWikiPages
+	// ---
+	// summary: Search the wiki pages
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: keyword
+	//   type: string
+	//   required: true
+	// - name: exact
+	//   in: query
+	//   description: only return the pages containing the exact keyword instead of fuzzy matches
+	//   type: boolean
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiSearchResultList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "500":
+	//     "$ref": "#/responses/error"
+
+	if !setting.Indexer.WikiIndexerEnabled || !ctx.Repo.Repository.HasWiki() {
+		ctx.NotFound()
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	total, results, err := code_indexer.PerformWikiSearch(ctx, []int64{ctx.Repo.Repository.ID},
+		ctx.FormTrim("q"), listOptions.Page, listOptions.PageSize, ctx.FormBool("exact"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "PerformWikiSearch", err)
+		return
+	}
+
+	apiResults := make([]*api.WikiSearchResult, 0, len(results))
+	for _, result := range results {
+		wikiName, err := wiki_service.FilenameToName(result.Filename)
 		if err != nil {
 			if models.IsErrWikiInvalidFileName(err) {
 				continue
@@ -320,11 +397,19 @@ func ListWikiPages(ctx *context.APIContext) {
 			ctx.Error(http.StatusInternalServerError, "WikiFilenameToName", err)
 			return
 		}
-		pages = append(pages, convert.ToWikiPageMetaData(wikiName, c, ctx.Repo.Repository))
-	}
-
-	ctx.SetTotalCountHeader(int64(len(entries)))
-	ctx.JSON(http.StatusOK, pages)
+		subURL := wiki_service.NameToSubURL(wikiName)
+		apiResults = append(apiResults, &api.WikiSearchResult{
+			Title:       wikiName,
+			HTMLURL:     util.URLJoin(ctx.Repo.Repository.HTMLURL(), "wiki", subURL),
+			SubURL:      subURL,
+			CommitID:    result.CommitID,
+			LineNumbers: result.LineNumbers,
+			Snippet:     result.Lines,
+		})
+	}
+
+	ctx.SetTotalCountHeader(int64(total))
+	ctx.JSON(http.StatusOK, apiResults)
 }
 
 // GetWikiPage get single wiki page
@@ -440,7 +525,7 @@ func ListPageRevisions(ctx *context.APIContext) {
 // findEntryForFile finds the tree entry for a target filepath.
 func findEntryForFile(commit *git.Commit, target string) (*git.TreeEntry, error) {
 	entry, err := commit.GetTreeEntryByPath(target)
-	if err != nil {
+	if err != nil && !git.IsErrNotExist(err) {
 		return nil, err
 	}
 	if entry != nil {
@@ -511,5 +596,9 @@ func wikiContentsByName(ctx *context.APIContext, commit *git.Commit, wikiName st
 		}
 		return "", ""
 	}
+	if entry.Name() != pageFilename {
+		// the entry was found by the unescaped file name, which contains the directories of the page
+		pageFilename, _ = url.QueryUnescape(pageFilename)
+	}
 	return wikiContentsByEntry(ctx, entry), pageFilename
 }
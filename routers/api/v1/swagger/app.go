@@ -1,4 +1,4 @@
-// Copyright 2020 The Gitea Authors. All rights reserved.
+// Copyright 2022 The Gitea Authors. All rights reserved.
 // Use of this source code is governed by a MIT-style
 // license that can be found in the LICENSE file.
 
@@ -8,16 +8,37 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 )
 
-// OAuth2Application
-// swagger:response OAuth2Application
-type swaggerResponseOAuth2Application struct {
+// App
+// swagger:response App
+type swaggerResponseApp struct {
 	// in:body
-	Body api.OAuth2Application `json:"body"`
+	Body api.App `json:"body"`
 }
 
-// AccessToken represents an API access token.
-// swagger:response AccessToken
-type swaggerResponseAccessToken struct {
+// AppList
+// swagger:response AppList
+type swaggerResponseAppList struct {
 	// in:body
-	Body api.AccessToken `json:"body"`
+	Body []api.App `json:"body"`
+}
+
+// AppInstallation
+// swagger:response AppInstallation
+type swaggerResponseAppInstallation struct {
+	// in:body
+	Body api.AppInstallation `json:"body"`
+}
+
+// AppInstallationList
+// swagger:response AppInstallationList
+type swaggerResponseAppInstallationList struct {
+	// in:body
+	Body []api.AppInstallation `json:"body"`
+}
+
+// InstallationToken
+// swagger:response InstallationToken
+type swaggerResponseInstallationToken struct {
+	// in:body
+	Body api.InstallationToken `json:"body"`
 }
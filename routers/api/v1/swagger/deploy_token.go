@@ -0,0 +1,23 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// DeployToken
+// swagger:response DeployToken
+type swaggerResponseDeployToken struct {
+	// in:body
+	Body api.DeployToken `json:"body"`
+}
+
+// DeployTokenList
+// swagger:response DeployTokenList
+type swaggerResponseDeployTokenList struct {
+	// in:body
+	Body []api.DeployToken `json:"body"`
+}
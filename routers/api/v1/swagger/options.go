@@ -172,4 +172,37 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	CreatePushMirrorOption api.CreatePushMirrorOption
+
+	// in:body
+	CreateProjectOption api.CreateProjectOption
+
+	// in:body
+	EditProjectOption api.EditProjectOption
+
+	// in:body
+	CreateProjectBoardOption api.CreateProjectBoardOption
+
+	// in:body
+	EditProjectBoardOption api.EditProjectBoardOption
+
+	// in:body
+	AddProjectIssueOption api.AddProjectIssueOption
+
+	// in:body
+	CreateOrUpdateSecretOption api.CreateOrUpdateSecretOption
+
+	// in:body
+	CreateDeployTokenOption api.CreateDeployTokenOption
+
+	// in:body
+	CreateAppOption api.CreateAppOption
+
+	// in:body
+	InstallAppOption api.InstallAppOption
+
+	// in:body
+	EditPushPolicyOption api.EditPushPolicyOption
+
+	// in:body
+	EditSecretScanningAlertOption api.EditSecretScanningAlertOption
 }
@@ -42,3 +42,10 @@ type swaggerResponseOrganizationPermissions struct {
 	// in:body
 	Body api.OrganizationPermissions `json:"body"`
 }
+
+// PushPolicy
+// swagger:response PushPolicy
+type swaggerResponsePushPolicy struct {
+	// in:body
+	Body api.PushPolicy `json:"body"`
+}
@@ -338,6 +338,13 @@ type swaggerWikiPage struct {
 	Body api.WikiPage `json:"body"`
 }
 
+// WikiSearchResultList
+// swagger:response WikiSearchResultList
+type swaggerWikiSearchResultList struct {
+	// in:body
+	Body []api.WikiSearchResult `json:"body"`
+}
+
 // WikiCommitList
 // swagger:response WikiCommitList
 type swaggerWikiCommitList struct {
@@ -359,6 +366,20 @@ type swaggerPushMirrorList struct {
 	Body []api.PushMirror `json:"body"`
 }
 
+// PushMirrorRefList
+// swagger:response PushMirrorRefList
+type swaggerPushMirrorRefList struct {
+	// in:body
+	Body []api.PushMirrorRef `json:"body"`
+}
+
+// PushMirrorSyncErrorList
+// swagger:response PushMirrorSyncErrorList
+type swaggerPushMirrorSyncErrorList struct {
+	// in:body
+	Body []api.PushMirrorSyncError `json:"body"`
+}
+
 // RepoCollaboratorPermission
 // swagger:response RepoCollaboratorPermission
 type swaggerRepoCollaboratorPermission struct {
@@ -0,0 +1,23 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// SecretScanningAlert
+// swagger:response SecretScanningAlert
+type swaggerResponseSecretScanningAlert struct {
+	// in:body
+	Body api.SecretScanningAlert `json:"body"`
+}
+
+// SecretScanningAlertList
+// swagger:response SecretScanningAlertList
+type swaggerResponseSecretScanningAlertList struct {
+	// in:body
+	Body []api.SecretScanningAlert `json:"body"`
+}
@@ -12,12 +12,21 @@ import (
 	"strconv"
 
 	"code.gitea.io/gitea/models"
+	app_model "code.gitea.io/gitea/models/app"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	app_service "code.gitea.io/gitea/services/app"
+	audit_service "code.gitea.io/gitea/services/audit"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 // ListAccessTokens list all the access tokens
@@ -60,11 +69,11 @@ func ListAccessTokens(ctx *context.APIContext) {
 
 	apiTokens := make([]*api.AccessToken, len(tokens))
 	for i := range tokens {
-		apiTokens[i] = &api.AccessToken{
-			ID:             tokens[i].ID,
-			Name:           tokens[i].Name,
-			TokenLastEight: tokens[i].TokenLastEight,
+		if err := tokens[i].LoadRestriction(ctx); err != nil {
+			ctx.InternalServerError(err)
+			return
 		}
+		apiTokens[i] = convert.ToAccessToken(tokens[i])
 	}
 
 	ctx.SetTotalCountHeader(count)
@@ -95,6 +104,8 @@ func CreateAccessToken(ctx *context.APIContext) {
 	//     "$ref": "#/responses/AccessToken"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.CreateAccessTokenOption)
 
@@ -113,16 +124,32 @@ func CreateAccessToken(ctx *context.APIContext) {
 		return
 	}
 
-	if err := models.NewAccessToken(t); err != nil {
-		ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
+	opts := &user_service.AccessTokenOptions{
+		Name:         form.Name,
+		Scopes:       form.Scopes,
+		Repository:   form.Repository,
+		Organization: form.Organization,
+	}
+	if form.ExpiresAt != nil {
+		opts.ExpiresAt = *form.ExpiresAt
+	}
+	t, err = user_service.NewAccessToken(ctx, ctx.Doer, opts)
+	if err != nil {
+		if err == user_service.ErrAccessTokenNoScope || err == user_service.ErrAccessTokenRestrictedTwice || err == user_service.ErrAccessTokenExpiryInPast ||
+			auth.IsErrInvalidAccessTokenScope(err) || models.IsErrAccessTokenScopeNotRestrictable(err) ||
+			repo_model.IsErrRepoNotExist(err) || organization.IsErrOrgNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "NewAccessToken", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
+		}
 		return
 	}
-	ctx.JSON(http.StatusCreated, &api.AccessToken{
-		Name:           t.Name,
-		Token:          t.Token,
-		ID:             t.ID,
-		TokenLastEight: t.TokenLastEight,
-	})
+	if err := t.LoadRestriction(ctx); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionAccessTokenCreate, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.AccessToken(t), nil, nil)
+	ctx.JSON(http.StatusCreated, convert.ToAccessToken(t))
 }
 
 // DeleteAccessToken delete access tokens
@@ -180,7 +207,17 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		return
 	}
 
-	if err := models.DeleteAccessTokenByID(tokenID, ctx.Doer.ID); err != nil {
+	t, err := models.GetAccessTokenByID(tokenID, ctx.Doer.ID)
+	if err != nil {
+		if models.IsErrAccessTokenNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAccessTokenByID", err)
+		}
+		return
+	}
+
+	if err := models.DeleteAccessTokenByID(t.ID, ctx.Doer.ID); err != nil {
 		if models.IsErrAccessTokenNotExist(err) {
 			ctx.NotFound()
 		} else {
@@ -188,6 +225,7 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit_service.Record(ctx, audit_model.ActionAccessTokenDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.AccessToken(t), nil, nil)
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -385,3 +423,178 @@ func UpdateOauth2Application(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, convert.ToOAuth2Application(app))
 }
+
+// ListApps list the apps of the authenticated user
+func ListApps(ctx *context.APIContext) {
+	// swagger:operation GET /user/applications/apps user userListApps
+	// ---
+	// summary: List the apps of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AppList"
+
+	apps, total, err := app_model.FindApps(ctx, app_model.FindAppsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		OwnerID:     ctx.Doer.ID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindApps", err)
+		return
+	}
+
+	apiApps := make([]*api.App, len(apps))
+	for i := range apps {
+		if apiApps[i], err = convert.ToApp(ctx, apps[i], ctx.Doer); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToApp", err)
+			return
+		}
+	}
+
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, &apiApps)
+}
+
+// CreateApp registers an app of the authenticated user
+func CreateApp(ctx *context.APIContext) {
+	// swagger:operation POST /user/applications/apps user userCreateApp
+	// ---
+	// summary: Register an app, the private key it signs its JWTs with is only returned once
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateAppOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/App"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateAppOption)
+
+	a, privateKey, err := app_service.CreateApp(ctx, ctx.Doer, &app_service.CreateAppOptions{
+		Name:          form.Name,
+		Description:   form.Description,
+		HomepageURL:   form.HomepageURL,
+		Permissions:   form.Permissions,
+		WebhookURL:    form.WebhookURL,
+		WebhookSecret: form.WebhookSecret,
+	})
+	if err != nil {
+		if app_model.IsErrAppAlreadyExist(err) || user_model.IsErrUserAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "CreateApp", err)
+		} else if err == app_service.ErrAppNoPermission || auth.IsErrInvalidAccessTokenScope(err) || models.IsErrAccessTokenScopeNotRestrictable(err) ||
+			db.IsErrNameReserved(err) || db.IsErrNamePatternNotAllowed(err) || db.IsErrNameCharsNotAllowed(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "CreateApp", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateApp", err)
+		}
+		return
+	}
+
+	apiApp, err := convert.ToApp(ctx, a, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToApp", err)
+		return
+	}
+	apiApp.PrivateKey = privateKey
+	ctx.JSON(http.StatusCreated, apiApp)
+}
+
+func getMyApp(ctx *context.APIContext) *app_model.App {
+	a, err := app_model.GetAppByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if app_model.IsErrAppNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAppByID", err)
+		}
+		return nil
+	}
+	if a.OwnerID != ctx.Doer.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return a
+}
+
+// GetApp get an app of the authenticated user
+func GetApp(ctx *context.APIContext) {
+	// swagger:operation GET /user/applications/apps/{id} user userGetApp
+	// ---
+	// summary: Get an app of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the app
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/App"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	a := getMyApp(ctx)
+	if ctx.Written() {
+		return
+	}
+	apiApp, err := convert.ToApp(ctx, a, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToApp", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiApp)
+}
+
+// DeleteApp removes an app of the authenticated user
+func DeleteApp(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/applications/apps/{id} user userDeleteApp
+	// ---
+	// summary: Delete an app with its installations and its bot user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the app
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	a := getMyApp(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := app_service.DeleteApp(ctx, a); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteApp", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// ListDeployTokens writes the deploy tokens of an organization or of a repository to `ctx`, without their values
+func ListDeployTokens(ctx *context.APIContext, ownerID, repoID int64) {
+	opts := auth_model.FindDeployTokensOptions{
+		ListOptions: GetListOptions(ctx),
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+	}
+
+	count, err := auth_model.CountDeployTokens(ctx, opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	tokens, err := auth_model.FindDeployTokens(ctx, opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiTokens := make([]*api.DeployToken, len(tokens))
+	for i, t := range tokens {
+		apiTokens[i] = convert.ToDeployToken(t)
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiTokens)
+}
+
+// CreateDeployToken creates a deploy token of an organization or of a repository and writes it to `ctx` with its value
+func CreateDeployToken(ctx *context.APIContext, ownerID, repoID int64, scope audit_model.Object, form *api.CreateDeployTokenOption) {
+	t := &auth_model.DeployToken{
+		OwnerID: ownerID,
+		RepoID:  repoID,
+		Name:    form.Name,
+		Mode:    perm.AccessModeRead,
+	}
+	if form.ReadOnly != nil && !*form.ReadOnly {
+		t.Mode = perm.AccessModeWrite
+	}
+	if form.ExpiresAt != nil {
+		t.ExpiresUnix = timeutil.TimeStamp(form.ExpiresAt.Unix())
+	}
+
+	if err := auth_model.NewDeployToken(ctx, t); err != nil {
+		if auth_model.IsErrDeployTokenNameAlreadyUsed(err) || auth_model.IsErrDeployTokenExpiryInPast(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "NewDeployToken", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionDeployTokenCreate, ctx.Doer, ctx.RemoteAddr(), scope, audit_service.DeployToken(t), nil, nil)
+
+	ctx.JSON(http.StatusCreated, convert.ToDeployToken(t))
+}
+
+// DeleteDeployToken removes a deploy token of an organization or of a repository
+func DeleteDeployToken(ctx *context.APIContext, ownerID, repoID int64, scope audit_model.Object) {
+	t, err := auth_model.GetDeployTokenByID(ctx, ctx.ParamsInt64(":id"))
+	if err == nil && (t.OwnerID != ownerID || t.RepoID != repoID) {
+		err = auth_model.ErrDeployTokenNotExist{ID: t.ID}
+	}
+	if err == nil {
+		err = auth_model.DeleteDeployToken(ctx, ownerID, repoID, t.ID)
+	}
+	if err != nil {
+		if auth_model.IsErrDeployTokenNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteDeployToken", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionDeployTokenDelete, ctx.Doer, ctx.RemoteAddr(), scope, audit_service.DeployToken(t), nil, nil)
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -9,12 +9,14 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/json"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
+	audit_service "code.gitea.io/gitea/services/audit"
 	webhook_service "code.gitea.io/gitea/services/webhook"
 )
 
@@ -65,6 +67,10 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid content type")
 		return false
 	}
+	if _, err := webhook.ParseHookHeaders(form.Config["headers"]); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return false
+	}
 	return true
 }
 
@@ -106,6 +112,7 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		URL:         form.Config["url"],
 		ContentType: webhook.ToHookContentType(form.Config["content_type"]),
 		Secret:      form.Config["secret"],
+		Headers:     form.Config["headers"],
 		HTTPMethod:  "POST",
 		HookEvent: &webhook.HookEvent{
 			ChooseEvents: true,
@@ -167,6 +174,7 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		ctx.Error(http.StatusInternalServerError, "CreateWebhook", err)
 		return nil, false
 	}
+	audit_service.RecordWebhook(ctx, audit_model.ActionWebhookCreate, ctx.Doer, ctx.Req.RemoteAddr, w, nil, audit_service.WebhookStateOf(w))
 	return w, true
 }
 
@@ -207,6 +215,8 @@ func EditRepoHook(ctx *context.APIContext, form *api.EditHookOption, hookID int6
 // editHook edit the webhook `w` according to `form`. If an error occurs, write
 // to `ctx` accordingly and return the error. Return whether successful
 func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webhook) bool {
+	before := audit_service.WebhookStateOf(w)
+
 	if form.Config != nil {
 		if url, ok := form.Config["url"]; ok {
 			w.URL = url
@@ -218,6 +228,13 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 			}
 			w.ContentType = webhook.ToHookContentType(ct)
 		}
+		if headers, ok := form.Config["headers"]; ok {
+			if _, err := webhook.ParseHookHeaders(headers); err != nil {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+				return false
+			}
+			w.Headers = headers
+		}
 
 		if w.Type == webhook.SLACK {
 			if channel, ok := form.Config["channel"]; ok {
@@ -281,5 +298,6 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 		ctx.Error(http.StatusInternalServerError, "UpdateWebhook", err)
 		return false
 	}
+	audit_service.RecordWebhook(ctx, audit_model.ActionWebhookUpdate, ctx.Doer, ctx.Req.RemoteAddr, w, before, audit_service.WebhookStateOf(w))
 	return true
 }
@@ -0,0 +1,69 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListSecrets writes the secrets of an organization or of a repository to `ctx`, without their values
+func ListSecrets(ctx *context.APIContext, ownerID, repoID int64) {
+	opts := secret_model.FindSecretsOptions{
+		ListOptions: GetListOptions(ctx),
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+	}
+
+	count, err := secret_model.CountSecrets(ctx, opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	secrets, err := secret_model.FindSecrets(ctx, opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiSecrets := make([]*api.Secret, len(secrets))
+	for i, s := range secrets {
+		apiSecrets[i] = convert.ToSecret(s)
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiSecrets)
+}
+
+// CreateOrUpdateSecret saves the value of a secret of an organization or of a repository
+func CreateOrUpdateSecret(ctx *context.APIContext, ownerID, repoID int64, form *api.CreateOrUpdateSecretOption) {
+	if _, err := secret_model.SetSecret(ctx, ownerID, repoID, ctx.Params(":secretname"), form.Data); err != nil {
+		if secret_model.IsErrInvalidSecret(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetSecret", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// DeleteSecret removes a secret of an organization or of a repository
+func DeleteSecret(ctx *context.APIContext, ownerID, repoID int64) {
+	if err := secret_model.DeleteSecret(ctx, ownerID, repoID, ctx.Params(":secretname")); err != nil {
+		if secret_model.IsErrSecretNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteSecret", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
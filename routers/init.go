@@ -30,7 +30,9 @@ import (
 	"code.gitea.io/gitea/modules/translation"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	ci_router "code.gitea.io/gitea/routers/api/ci"
 	packages_router "code.gitea.io/gitea/routers/api/packages"
+	scim_router "code.gitea.io/gitea/routers/api/scim"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/private"
@@ -38,13 +40,16 @@ import (
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/automerge"
+	ci_service "code.gitea.io/gitea/services/ci"
 	"code.gitea.io/gitea/services/cron"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/mailer/incoming"
 	repo_migrations "code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/repository/archiver"
+	secretscan_service "code.gitea.io/gitea/services/secretscan"
 	"code.gitea.io/gitea/services/task"
 	"code.gitea.io/gitea/services/webhook"
 )
@@ -147,6 +152,8 @@ func GlobalInitInstalled(ctx context.Context) {
 	mustInit(webhook.Init)
 	mustInit(pull_service.Init)
 	mustInit(automerge.Init)
+	ci_service.Init()
+	secretscan_service.Init()
 	mustInit(task.Init)
 	mustInit(repo_migrations.Init)
 	eventsource.GetManager().Init()
@@ -154,6 +161,7 @@ func GlobalInitInstalled(ctx context.Context) {
 	mustInitCtx(ctx, syncAppPathForGit)
 
 	mustInit(ssh.Init)
+	mustInit(incoming.Init)
 
 	auth.Init()
 	svg.Init()
@@ -176,5 +184,11 @@ func NormalRoutes() *web.Route {
 		r.Mount("/api/packages", packages_router.Routes())
 		r.Mount("/v2", packages_router.ContainerRoutes())
 	}
+	if setting.CI.Enabled {
+		r.Mount("/api/ci", ci_router.Routes())
+	}
+	if setting.SCIM.Enabled {
+		r.Mount("/api/scim/v2", scim_router.Routes())
+	}
 	return r
 }
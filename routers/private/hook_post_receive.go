@@ -15,6 +15,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	gitea_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
 	repo_module "code.gitea.io/gitea/modules/repository"
@@ -36,6 +37,24 @@ func HookPostReceive(ctx *gitea_context.PrivateContext) {
 	ownerName := ctx.Params(":owner")
 	repoName := ctx.Params(":repo")
 
+	// the pushes to a wiki only update the wiki indexer
+	if opts.IsWiki {
+		for _, refFullName := range opts.RefFullNames {
+			if refFullName != git.BranchPrefix+"master" {
+				continue
+			}
+			repo := loadRepository(ctx, ownerName, repoName)
+			if ctx.Written() {
+				// Error handled in loadRepository
+				return
+			}
+			code_indexer.UpdateWikiIndexer(repo)
+			break
+		}
+		ctx.JSON(http.StatusOK, private.HookPostReceiveResult{})
+		return
+	}
+
 	// defer getting the repository at this point - as we should only retrieve it if we're going to call update
 	var repo *repo_model.Repository
 
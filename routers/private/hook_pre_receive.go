@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	auth_model "code.gitea.io/gitea/models/auth"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	perm_model "code.gitea.io/gitea/models/perm"
@@ -25,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
+	pushpolicy_service "code.gitea.io/gitea/services/pushpolicy"
 )
 
 type preReceiveContext struct {
@@ -45,6 +47,9 @@ type preReceiveContext struct {
 	protectedTags    []*git_model.ProtectedTag
 	gotProtectedTags bool
 
+	pushPolicy    *git_model.PushPolicy
+	gotPushPolicy bool
+
 	env []string
 
 	opts *private.HookOptions
@@ -130,7 +135,7 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		default:
 			ourCtx.AssertCanWriteCode()
 		}
-		if ctx.Written() {
+		if ctx.Written() || !ourCtx.AssertPushPolicy(newCommitID, refFullName) {
 			return
 		}
 	}
@@ -138,6 +143,57 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
+// AssertPushPolicy returns true if the commits pushed to the ref comply with the push policy of the owner of the repository,
+// the violations of a policy in dry-run mode are only logged
+func (ctx *preReceiveContext) AssertPushPolicy(newCommitID, refFullName string) bool {
+	if newCommitID == git.EmptySHA || ctx.opts.IsWiki {
+		return true
+	}
+
+	repo := ctx.Repo.Repository
+	if !ctx.gotPushPolicy {
+		var err error
+		ctx.pushPolicy, err = git_model.GetPushPolicy(ctx, repo.OwnerID)
+		if err != nil {
+			log.Error("Unable to get the push policy of the owner of %-v Error: %v", repo, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return false
+		}
+		ctx.gotPushPolicy = true
+	}
+	if ctx.pushPolicy == nil {
+		return true
+	}
+
+	violations, err := pushpolicy_service.Check(ctx, ctx.Repo.GitRepo, ctx.pushPolicy, newCommitID, ctx.env)
+	if err != nil {
+		log.Error("Unable to check the push policy for %s in %-v: %v", refFullName, repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to check the push policy for %s: %v", refFullName, err),
+		})
+		return false
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+	if ctx.pushPolicy.DryRun {
+		log.Warn("Push policy (dry run): %s in %-v violates the push policy of %s:\n%s", refFullName, repo, repo.OwnerName, strings.Join(messages, "\n"))
+		return true
+	}
+	log.Warn("Forbidden: %s in %-v violates the push policy of %s:\n%s", refFullName, repo, repo.OwnerName, strings.Join(messages, "\n"))
+	ctx.JSON(http.StatusForbidden, private.Response{
+		Err: fmt.Sprintf("%s violates the push policy of %s:\n%s", refFullName, repo.OwnerName, strings.Join(messages, "\n")),
+	})
+	return false
+}
+
 func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullName string) {
 	branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 	ctx.branchName = branchName
@@ -249,7 +305,7 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullN
 
 	// 5. Check if the doer is allowed to push
 	var canPush bool
-	if ctx.opts.DeployKeyID != 0 {
+	if ctx.opts.DeployKeyID != 0 || ctx.opts.DeployTokenID != 0 {
 		canPush = !changedProtectedfiles && protectBranch.CanPush && (!protectBranch.EnableWhitelist || protectBranch.WhitelistDeployKeys)
 	} else {
 		canPush = !changedProtectedfiles && protectBranch.CanUserPush(ctx.opts.UserID)
@@ -495,6 +551,16 @@ func (ctx *preReceiveContext) loadPusherAndPermission() bool {
 			return false
 		}
 		ctx.deployKeyAccessMode = deployKey.Mode
+	} else if ctx.opts.DeployTokenID != 0 {
+		deployToken, err := auth_model.GetDeployTokenByID(ctx, ctx.opts.DeployTokenID)
+		if err != nil {
+			log.Error("Unable to get DeployToken id %d Error: %v", ctx.opts.DeployTokenID, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to get DeployToken id %d Error: %v", ctx.opts.DeployTokenID, err),
+			})
+			return false
+		}
+		ctx.deployKeyAccessMode = deployToken.Mode
 	}
 
 	ctx.loadedPusher = true
@@ -0,0 +1,61 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplAudit base.TplName = "admin/audit"
+)
+
+// AuditEvents shows the audit log
+func AuditEvents(ctx *context.Context) {
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	query := ctx.FormTrim("q")
+	action := audit_model.Action(ctx.FormTrim("action"))
+	if !action.IsValid() {
+		action = ""
+	}
+
+	events, total, err := audit_model.SearchEvents(ctx, &audit_model.SearchEventsOptions{
+		ListOptions: db.ListOptions{
+			PageSize: setting.UI.Admin.NoticePagingNum,
+			Page:     page,
+		},
+		Action:  action,
+		Keyword: query,
+	})
+	if err != nil {
+		ctx.ServerError("SearchEvents", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("admin.audit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAudit"] = true
+	ctx.Data["Query"] = query
+	ctx.Data["Action"] = action
+	ctx.Data["Actions"] = audit_model.Actions
+	ctx.Data["Events"] = events
+	ctx.Data["Total"] = total
+
+	pager := context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	pager.AddParamString("q", query)
+	pager.AddParamString("action", string(action))
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplAudit)
+}
@@ -7,11 +7,13 @@ package admin
 import (
 	"net/http"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
+	audit_service "code.gitea.io/gitea/services/audit"
 )
 
 const (
@@ -61,9 +63,13 @@ func DefaultOrSystemWebhooks(ctx *context.Context) {
 
 // DeleteDefaultOrSystemWebhook handler to delete an admin-defined system or default webhook
 func DeleteDefaultOrSystemWebhook(ctx *context.Context) {
-	if err := webhook.DeleteDefaultSystemWebhook(ctx.FormInt64("id")); err != nil {
+	w, err := webhook.GetSystemOrDefaultWebhook(ctx.FormInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetSystemOrDefaultWebhook: " + err.Error())
+	} else if err := webhook.DeleteDefaultSystemWebhook(w.ID); err != nil {
 		ctx.Flash.Error("DeleteDefaultWebhook: " + err.Error())
 	} else {
+		audit_service.RecordWebhook(ctx, audit_model.ActionWebhookDelete, ctx.Doer, ctx.Req.RemoteAddr, w, audit_service.WebhookStateOf(w), nil)
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -19,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/web/explore"
+	audit_service "code.gitea.io/gitea/services/audit"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
@@ -57,6 +59,7 @@ func DeleteRepo(ctx *context.Context) {
 		return
 	}
 	log.Trace("Repository deleted: %s", repo.FullName())
+	audit_service.Record(ctx, audit_model.ActionAdminRepositoryDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.System(), audit_service.Repository(repo), nil, nil)
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
 	ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -24,6 +25,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/explore"
 	user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	user_service "code.gitea.io/gitea/services/user"
@@ -196,6 +198,7 @@ func NewUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, audit_model.ActionAdminUserCreate, ctx.Doer, ctx.RemoteAddr(), audit_service.System(), audit_service.User(u), nil, audit_service.UserStateOf(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -281,6 +284,8 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 
+	before := audit_service.UserStateOf(u)
+
 	fields := strings.Split(form.LoginType, "-")
 	if len(fields) == 2 {
 		loginType, _ := strconv.ParseInt(fields[0], 10, 0)
@@ -350,6 +355,7 @@ func EditUserPost(ctx *context.Context) {
 				ctx.ServerError("auth.DeleteTwoFactorByID", err)
 				return
 			}
+			audit_service.Record(ctx, audit_model.ActionTwoFactorDisable, ctx.Doer, ctx.RemoteAddr(), audit_service.User(u), audit_service.User(u), nil, nil)
 		}
 
 		wn, err := auth.GetWebAuthnCredentialsByUID(u.ID)
@@ -362,6 +368,7 @@ func EditUserPost(ctx *context.Context) {
 				ctx.ServerError("auth.DeleteCredential", err)
 				return
 			}
+			audit_service.Record(ctx, audit_model.ActionWebAuthnRemove, ctx.Doer, ctx.RemoteAddr(), audit_service.User(u), audit_service.WebAuthnCredential(cred), nil, nil)
 		}
 
 	}
@@ -403,6 +410,7 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, audit_model.ActionAdminUserUpdate, ctx.Doer, ctx.RemoteAddr(), audit_service.System(), audit_service.User(u), before, audit_service.UserStateOf(u))
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + url.PathEscape(ctx.Params(":userid")))
@@ -440,6 +448,7 @@ func DeleteUser(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account deleted by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, audit_model.ActionAdminUserDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.System(), audit_service.User(u), audit_service.UserStateOf(u), nil)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.deletion_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users")
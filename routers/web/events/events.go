@@ -18,13 +18,7 @@ import (
 // Events listens for events
 func Events(ctx *context.Context) {
 	// FIXME: Need to check if resp is actually a http.Flusher! - how though?
-
-	// Set the headers related to event streaming.
-	ctx.Resp.Header().Set("Content-Type", "text/event-stream")
-	ctx.Resp.Header().Set("Cache-Control", "no-cache")
-	ctx.Resp.Header().Set("Connection", "keep-alive")
-	ctx.Resp.Header().Set("X-Accel-Buffering", "no")
-	ctx.Resp.WriteHeader(http.StatusOK)
+	writeHeaders(ctx)
 
 	if !ctx.IsSigned {
 		// Return unauthorized status event
@@ -37,30 +31,82 @@ func Events(ctx *context.Context) {
 		return
 	}
 
-	// Listen to connection close and un-register messageChan
-	notify := ctx.Done()
-	ctx.Resp.Flush()
-
-	shutdownCtx := graceful.GetManager().ShutdownContext()
-
 	uid := ctx.Doer.ID
-
 	messageChan := eventsource.GetManager().Register(uid)
-
 	unregister := func() {
 		eventsource.GetManager().Unregister(uid, messageChan)
-		// ensure the messageChan is closed
-		for {
-			_, ok := <-messageChan
-			if !ok {
-				break
-			}
+	}
+
+	stream(ctx, messageChan, unregister, func(event *eventsource.Event) (*eventsource.Event, bool) {
+		// Handle logout
+		if event.Name != "logout" {
+			return event, false
+		}
+		if ctx.Session.ID() == event.Data {
+			_, _ = (&eventsource.Event{
+				Name: "logout",
+				Data: "here",
+			}).WriteTo(ctx.Resp)
+			ctx.Resp.Flush()
+			go drain(messageChan, unregister)
+			auth.HandleSignOut(ctx)
+			return nil, true
+		}
+		// Replace the event - we don't want to expose the session ID to the user
+		return &eventsource.Event{
+			Name: "logout",
+			Data: "elsewhere",
+		}, false
+	})
+}
+
+// ResourceEvents listens for the events of a resource, like the updates of an issue,
+// the caller must check that the user can read the resource
+func ResourceEvents(ctx *context.Context, resource string) {
+	writeHeaders(ctx)
+
+	messageChan := eventsource.GetManager().RegisterResource(resource)
+	unregister := func() {
+		eventsource.GetManager().UnregisterResource(resource, messageChan)
+	}
+
+	stream(ctx, messageChan, unregister, func(event *eventsource.Event) (*eventsource.Event, bool) {
+		return event, false
+	})
+}
+
+// writeHeaders sets the headers related to event streaming
+func writeHeaders(ctx *context.Context) {
+	ctx.Resp.Header().Set("Content-Type", "text/event-stream")
+	ctx.Resp.Header().Set("Cache-Control", "no-cache")
+	ctx.Resp.Header().Set("Connection", "keep-alive")
+	ctx.Resp.Header().Set("X-Accel-Buffering", "no")
+	ctx.Resp.WriteHeader(http.StatusOK)
+}
+
+// drain un-registers the messageChan and ensures it is closed
+func drain(messageChan <-chan *eventsource.Event, unregister func()) {
+	unregister()
+	for {
+		_, ok := <-messageChan
+		if !ok {
+			break
 		}
 	}
+}
+
+// stream writes the events of the messageChan until the connection or the messageChan is closed,
+// handle may replace an event or stop the stream once it has handled the event itself
+func stream(ctx *context.Context, messageChan <-chan *eventsource.Event, unregister func(), handle func(*eventsource.Event) (*eventsource.Event, bool)) {
+	// Listen to connection close and un-register messageChan
+	notify := ctx.Done()
+	ctx.Resp.Flush()
+
+	shutdownCtx := graceful.GetManager().ShutdownContext()
 
 	if _, err := ctx.Resp.Write([]byte("\n")); err != nil {
 		log.Error("Unable to write to EventStream: %v", err)
-		unregister()
+		drain(messageChan, unregister)
 		return
 	}
 
@@ -75,45 +121,31 @@ loop:
 			}
 			_, err := event.WriteTo(ctx.Resp)
 			if err != nil {
-				log.Error("Unable to write to EventStream for user %s: %v", ctx.Doer.Name, err)
-				go unregister()
+				log.Error("Unable to write to EventStream for %s: %v", ctx.Req.URL.Path, err)
+				go drain(messageChan, unregister)
 				break loop
 			}
 			ctx.Resp.Flush()
 		case <-notify:
-			go unregister()
+			go drain(messageChan, unregister)
 			break loop
 		case <-shutdownCtx.Done():
-			go unregister()
+			go drain(messageChan, unregister)
 			break loop
 		case event, ok := <-messageChan:
 			if !ok {
 				break loop
 			}
 
-			// Handle logout
-			if event.Name == "logout" {
-				if ctx.Session.ID() == event.Data {
-					_, _ = (&eventsource.Event{
-						Name: "logout",
-						Data: "here",
-					}).WriteTo(ctx.Resp)
-					ctx.Resp.Flush()
-					go unregister()
-					auth.HandleSignOut(ctx)
-					break loop
-				}
-				// Replace the event - we don't want to expose the session ID to the user
-				event = &eventsource.Event{
-					Name: "logout",
-					Data: "elsewhere",
-				}
+			event, stop := handle(event)
+			if stop {
+				break loop
 			}
 
 			_, err := event.WriteTo(ctx.Resp)
 			if err != nil {
-				log.Error("Unable to write to EventStream for user %s: %v", ctx.Doer.Name, err)
-				go unregister()
+				log.Error("Unable to write to EventStream for %s: %v", ctx.Req.URL.Path, err)
+				go drain(messageChan, unregister)
 				break loop
 			}
 			ctx.Resp.Flush()
@@ -8,12 +8,13 @@ package org
 import (
 	"net/http"
 
-	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	org_service "code.gitea.io/gitea/services/org"
 )
 
 const (
@@ -98,7 +99,17 @@ func MembersAction(ctx *context.Context) {
 			ctx.Error(http.StatusNotFound)
 			return
 		}
-		err = models.RemoveOrgUser(org.ID, uid)
+		var member *user_model.User
+		member, err = user_model.GetUserByIDCtx(ctx, uid)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.NotFound("GetUserByID", err)
+			} else {
+				ctx.ServerError("GetUserByID", err)
+			}
+			return
+		}
+		err = org_service.RemoveOrgUser(ctx, ctx.Doer, ctx.RemoteAddr(), org, member)
 		if organization.IsErrLastOrgOwner(err) {
 			ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
 			ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -107,7 +118,7 @@ func MembersAction(ctx *context.Context) {
 			return
 		}
 	case "leave":
-		err = models.RemoveOrgUser(org.ID, ctx.Doer.ID)
+		err = org_service.RemoveOrgUser(ctx, ctx.Doer, ctx.RemoteAddr(), org, ctx.Doer)
 		if organization.IsErrLastOrgOwner(err) {
 			ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
 			ctx.JSON(http.StatusOK, map[string]interface{}{
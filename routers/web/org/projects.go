@@ -0,0 +1,639 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	project_model "code.gitea.io/gitea/models/project"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplProjects     base.TplName = "org/projects/list"
+	tplProjectsNew  base.TplName = "org/projects/new"
+	tplProjectsView base.TplName = "org/projects/view"
+)
+
+// MustEnableProjects check if projects are enabled in settings and the projects of the context user are visible to the doer
+func MustEnableProjects(ctx *context.Context) {
+	if unit.TypeProjects.UnitGlobalDisabled() {
+		ctx.NotFound("EnableKanbanBoard", nil)
+		return
+	}
+
+	accessMode, err := access_model.GetOwnerUnitAccessMode(ctx, ctx.ContextUser, ctx.Doer, unit.TypeProjects)
+	if err != nil {
+		ctx.ServerError("GetOwnerUnitAccessMode", err)
+		return
+	}
+	if accessMode < perm.AccessModeRead {
+		ctx.NotFound("MustEnableProjects", nil)
+		return
+	}
+	ctx.Data["CanWriteProjects"] = accessMode >= perm.AccessModeWrite
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["ProjectsLink"] = projectsLink(ctx)
+	ctx.Data["IsProjectsPage"] = true
+
+	// TODO: context/org -> HandleOrgAssignment() can not be used
+	if ctx.ContextUser.IsOrganization() {
+		org := organization.OrgFromUser(ctx.ContextUser)
+		ctx.Data["Org"] = org
+		ctx.Data["OrgLink"] = ctx.ContextUser.OrganisationLink()
+
+		if ctx.Doer != nil {
+			ctx.Data["IsOrganizationMember"], _ = organization.IsOrganizationMember(ctx, org.ID, ctx.Doer.ID)
+			ctx.Data["IsOrganizationOwner"], _ = organization.IsOrganizationOwner(ctx, org.ID, ctx.Doer.ID)
+		} else {
+			ctx.Data["IsOrganizationMember"] = false
+			ctx.Data["IsOrganizationOwner"] = false
+		}
+	}
+}
+
+// MustBeAbleToWriteProjects checks if the doer can modify the projects of the context user
+func MustBeAbleToWriteProjects(ctx *context.Context) {
+	if canWrite, _ := ctx.Data["CanWriteProjects"].(bool); !canWrite {
+		ctx.NotFound("MustBeAbleToWriteProjects", nil)
+	}
+}
+
+func projectsLink(ctx *context.Context) string {
+	return ctx.ContextUser.HomeLink() + "/-/projects"
+}
+
+func renderProjectDescription(ctx *context.Context, p *project_model.Project) (err error) {
+	p.RenderedContent, err = markdown.RenderString(&markup.RenderContext{
+		URLPrefix: ctx.ContextUser.HomeLink(),
+		Ctx:       ctx,
+	}, p.Description)
+	return err
+}
+
+// getOwnerProject returns the project given by the :id parameter if it belongs to the context user
+func getOwnerProject(ctx *context.Context) *project_model.Project {
+	p, err := project_model.GetProjectByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if project_model.IsErrProjectNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectByID", err)
+		}
+		return nil
+	}
+	if !p.IsOwnerProject() || p.OwnerID != ctx.ContextUser.ID {
+		ctx.NotFound("", nil)
+		return nil
+	}
+	return p
+}
+
+// Projects renders the list of projects of an individual or organization
+func Projects(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.project_board")
+
+	sortType := ctx.FormTrim("sort")
+	isShowClosed := strings.ToLower(ctx.FormTrim("state")) == "closed"
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	opts := project_model.SearchOptions{
+		OwnerID:  ctx.ContextUser.ID,
+		IsClosed: util.OptionalBoolFalse,
+	}
+	openCount, err := project_model.CountProjects(ctx, opts)
+	if err != nil {
+		ctx.ServerError("CountProjects", err)
+		return
+	}
+	opts.IsClosed = util.OptionalBoolTrue
+	closedCount, err := project_model.CountProjects(ctx, opts)
+	if err != nil {
+		ctx.ServerError("CountProjects", err)
+		return
+	}
+	ctx.Data["OpenCount"] = openCount
+	ctx.Data["ClosedCount"] = closedCount
+
+	projects, total, err := project_model.GetProjects(ctx, project_model.SearchOptions{
+		OwnerID:  ctx.ContextUser.ID,
+		Page:     page,
+		IsClosed: util.OptionalBoolOf(isShowClosed),
+		SortType: sortType,
+	})
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+
+	for _, p := range projects {
+		if err := renderProjectDescription(ctx, p); err != nil {
+			ctx.ServerError("RenderString", err)
+			return
+		}
+	}
+
+	ctx.Data["Projects"] = projects
+
+	if isShowClosed {
+		ctx.Data["State"] = "closed"
+	} else {
+		ctx.Data["State"] = "open"
+	}
+
+	pager := context.NewPagination(int(total), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "state", "State")
+	ctx.Data["Page"] = pager
+
+	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.Data["SortType"] = sortType
+
+	ctx.HTML(http.StatusOK, tplProjects)
+}
+
+// NewProject render creating a project page
+func NewProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["ProjectTypes"] = project_model.GetProjectsConfig()
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// NewProjectPost creates a new project
+func NewProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+
+	if ctx.HasError() {
+		ctx.Data["ProjectTypes"] = project_model.GetProjectsConfig()
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	projectType := project_model.TypeIndividual
+	if ctx.ContextUser.IsOrganization() {
+		projectType = project_model.TypeOrganization
+	}
+
+	if err := project_model.NewProject(&project_model.Project{
+		OwnerID:     ctx.ContextUser.ID,
+		Title:       form.Title,
+		Description: form.Content,
+		CreatorID:   ctx.Doer.ID,
+		BoardType:   form.BoardType,
+		Type:        projectType,
+	}); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
+	ctx.Redirect(projectsLink(ctx))
+}
+
+// ChangeProjectStatus updates the status of a project between "open" and "close"
+func ChangeProjectStatus(ctx *context.Context) {
+	p := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.ChangeProjectStatus(p, ctx.Params(":action") == "close"); err != nil {
+		ctx.ServerError("ChangeProjectStatus", err)
+		return
+	}
+	ctx.Redirect(projectsLink(ctx) + "?state=" + url.QueryEscape(ctx.Params(":action")))
+}
+
+// DeleteProject delete a project
+func DeleteProject(ctx *context.Context) {
+	p := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.DeleteProjectByID(p.ID); err != nil {
+		ctx.Flash.Error("DeleteProjectByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": projectsLink(ctx),
+	})
+}
+
+// EditProject allows a project to be edited
+func EditProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProjects"] = true
+
+	p := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["title"] = p.Title
+	ctx.Data["content"] = p.Description
+
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// EditProjectPost response for editing a project
+func EditProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsEditProjects"] = true
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	p := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	p.Title = form.Title
+	p.Description = form.Content
+	if err := project_model.UpdateProject(ctx, p); err != nil {
+		ctx.ServerError("UpdateProjects", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.edit_success", p.Title))
+	ctx.Redirect(projectsLink(ctx))
+}
+
+// ViewProject renders the project board for a project
+func ViewProject(ctx *context.Context) {
+	project := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := project_model.GetBoards(ctx, project.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+
+	if boards[0].ID == 0 {
+		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
+	}
+
+	// issues of repositories the doer can't read must not be shown
+	issuesMap, err := issues_model.LoadIssuesFromBoardList(boards, repo_model.AccessibleRepositoryCondition(ctx.Doer, unit.TypeInvalid))
+	if err != nil {
+		ctx.ServerError("LoadIssuesOfBoards", err)
+		return
+	}
+
+	linkedPrsMap := make(map[int64][]*issues_model.Issue)
+	for _, issuesList := range issuesMap {
+		for _, issue := range issuesList {
+			var referencedIds []int64
+			for _, comment := range issue.Comments {
+				if comment.RefIssueID != 0 && comment.RefIsPull {
+					referencedIds = append(referencedIds, comment.RefIssueID)
+				}
+			}
+
+			if len(referencedIds) > 0 {
+				if linkedPrs, err := issues_model.Issues(&issues_model.IssuesOptions{
+					IssueIDs: referencedIds,
+					IsPull:   util.OptionalBoolTrue,
+					RepoCond: repo_model.AccessibleRepositoryCondition(ctx.Doer, unit.TypePullRequests),
+				}); err == nil {
+					linkedPrsMap[issue.ID] = linkedPrs
+				}
+			}
+		}
+	}
+	ctx.Data["LinkedPRs"] = linkedPrsMap
+
+	if err := renderProjectDescription(ctx, project); err != nil {
+		ctx.ServerError("RenderString", err)
+		return
+	}
+
+	ctx.Data["Title"] = project.Title
+	ctx.Data["Project"] = project
+	ctx.Data["IssuesMap"] = issuesMap
+	ctx.Data["Boards"] = boards
+
+	ctx.HTML(http.StatusOK, tplProjectsView)
+}
+
+// AddIssueToProjectPost adds an issue of any repository the doer can write the issues of to the project.
+// The issue is given as a reference in the form of owner/repo#index.
+func AddIssueToProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AddIssueToProjectForm)
+	project := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	projectLink := fmt.Sprintf("%s/%d", projectsLink(ctx), project.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(projectLink)
+		return
+	}
+
+	repoName, index, ok := strings.Cut(strings.TrimSpace(form.Issue), "#")
+	ownerName, repoName, hasOwner := strings.Cut(repoName, "/")
+	issueIndex, err := strconv.ParseInt(index, 10, 64)
+	if !ok || !hasOwner || err != nil {
+		ctx.Flash.Error(ctx.Tr("repo.projects.issue.invalid_reference", form.Issue))
+		ctx.Redirect(projectLink)
+		return
+	}
+
+	issue, canWrite, err := getReadableIssue(ctx, ownerName, repoName, issueIndex)
+	if err != nil {
+		ctx.ServerError("getReadableIssue", err)
+		return
+	}
+	if issue == nil {
+		ctx.Flash.Error(ctx.Tr("repo.projects.issue.not_found", form.Issue))
+		ctx.Redirect(projectLink)
+		return
+	}
+	// adding the issue changes it and removes it from its current project
+	if !canWrite {
+		ctx.Flash.Error(ctx.Tr("repo.projects.issue.not_writable", form.Issue))
+		ctx.Redirect(projectLink)
+		return
+	}
+
+	if issue.ProjectID() != project.ID {
+		if err := issues_model.ChangeProjectAssign(issue, ctx.Doer, project.ID); err != nil {
+			ctx.ServerError("ChangeProjectAssign", err)
+			return
+		}
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.issue.add_success", form.Issue))
+	ctx.Redirect(projectLink)
+}
+
+// getReadableIssue returns the issue or pull request if the doer can read it, or nil otherwise,
+// and whether the doer can write it
+func getReadableIssue(ctx *context.Context, ownerName, repoName string, index int64) (*issues_model.Issue, bool, error) {
+	repo, err := repo_model.GetRepositoryByOwnerAndNameCtx(ctx, ownerName, repoName)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	issue, err := issues_model.GetIssueByIndex(repo.ID, index)
+	if err != nil {
+		if issues_model.IsErrIssueNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	permission, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
+	if err != nil {
+		return nil, false, err
+	}
+	if !permission.CanReadIssuesOrPulls(issue.IsPull) {
+		return nil, false, nil
+	}
+	return issue, permission.CanWriteIssuesOrPulls(issue.IsPull), nil
+}
+
+// RemoveIssueFromProject removes an issue from the project
+func RemoveIssueFromProject(ctx *context.Context) {
+	project := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	issue, err := issues_model.GetIssueByID(ctx, ctx.ParamsInt64(":issueID"))
+	if err != nil {
+		if issues_model.IsErrIssueNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetIssueByID", err)
+		}
+		return
+	}
+	if issue.ProjectID() != project.ID {
+		ctx.NotFound("", nil)
+		return
+	}
+	if err := issue.LoadRepo(ctx); err != nil {
+		ctx.ServerError("LoadRepo", err)
+		return
+	}
+	issue, canWrite, err := getReadableIssue(ctx, issue.Repo.OwnerName, issue.Repo.Name, issue.Index)
+	if err != nil {
+		ctx.ServerError("getReadableIssue", err)
+		return
+	}
+	if issue == nil {
+		ctx.NotFound("", nil)
+		return
+	}
+	// removing the issue changes it
+	if !canWrite {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	if err := issues_model.ChangeProjectAssign(issue, ctx.Doer, 0); err != nil {
+		ctx.ServerError("ChangeProjectAssign", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// AddBoardToProjectPost allows a new board to be added to a project.
+func AddBoardToProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditProjectBoardForm)
+	project := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.NewBoard(&project_model.Board{
+		ProjectID: project.ID,
+		Title:     form.Title,
+		Color:     form.Color,
+		CreatorID: ctx.Doer.ID,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+func getOwnerProjectBoard(ctx *context.Context) (*project_model.Project, *project_model.Board) {
+	project := getOwnerProject(ctx)
+	if ctx.Written() {
+		return nil, nil
+	}
+
+	board, err := project_model.GetBoard(ctx, ctx.ParamsInt64(":boardID"))
+	if err != nil {
+		if project_model.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("ProjectBoardNotExist", nil)
+		} else {
+			ctx.ServerError("GetProjectBoard", err)
+		}
+		return nil, nil
+	}
+	if board.ProjectID != project.ID {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+			"message": fmt.Sprintf("ProjectBoard[%d] is not in Project[%d] as expected", board.ID, project.ID),
+		})
+		return nil, nil
+	}
+	return project, board
+}
+
+// EditProjectBoard allows a project board's to be updated
+func EditProjectBoard(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.EditProjectBoardForm)
+	_, board := getOwnerProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != "" {
+		board.Title = form.Title
+	}
+
+	board.Color = form.Color
+
+	if form.Sorting != 0 {
+		board.Sorting = form.Sorting
+	}
+
+	if err := project_model.UpdateBoard(ctx, board); err != nil {
+		ctx.ServerError("UpdateProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// DeleteProjectBoard allows for the deletion of a project board
+func DeleteProjectBoard(ctx *context.Context) {
+	_, board := getOwnerProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.DeleteBoardByID(board.ID); err != nil {
+		ctx.ServerError("DeleteProjectBoardByID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// SetDefaultProjectBoard set default board for uncategorized issues/pulls
+func SetDefaultProjectBoard(ctx *context.Context) {
+	project, board := getOwnerProjectBoard(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := project_model.SetDefaultBoard(project.ID, board.ID); err != nil {
+		ctx.ServerError("SetDefaultBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// MoveIssues moves or keeps issues in a column and sorts them inside that column
+func MoveIssues(ctx *context.Context) {
+	project := getOwnerProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var board *project_model.Board
+	if ctx.ParamsInt64(":boardID") == 0 {
+		board = &project_model.Board{
+			ID:        0,
+			ProjectID: project.ID,
+			Title:     ctx.Tr("repo.projects.type.uncategorized"),
+		}
+	} else {
+		_, board = getOwnerProjectBoard(ctx)
+		if ctx.Written() {
+			return
+		}
+	}
+
+	type movedIssuesForm struct {
+		Issues []struct {
+			IssueID int64 `json:"issueID"`
+			Sorting int64 `json:"sorting"`
+		} `json:"issues"`
+	}
+
+	form := &movedIssuesForm{}
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&form); err != nil {
+		ctx.ServerError("DecodeMovedIssuesForm", err)
+		return
+	}
+
+	sortedIssueIDs := make(map[int64]int64)
+	for _, issue := range form.Issues {
+		sortedIssueIDs[issue.Sorting] = issue.IssueID
+	}
+
+	// MoveIssuesOnProjectBoard makes sure that all the issues belong to the project
+	if err := project_model.MoveIssuesOnProjectBoard(board, sortedIssueIDs); err != nil {
+		ctx.ServerError("MoveIssuesOnProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+	"strings"
+
+	app_model "code.gitea.io/gitea/models/app"
+	audit_model "code.gitea.io/gitea/models/audit"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	app_service "code.gitea.io/gitea/services/app"
+	audit_service "code.gitea.io/gitea/services/audit"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplSettingsApps base.TplName = "org/settings/apps"
+)
+
+// installationView is an installation with its app and the repositories it is installed on
+type installationView struct {
+	*app_model.Installation
+	App   *app_model.App
+	Repos []*repo_model.Repository
+}
+
+// Apps renders the apps installed by the organization
+func Apps(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings.apps")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsApps"] = true
+
+	installations, _, err := app_model.FindInstallations(ctx, app_model.FindInstallationsOptions{OwnerID: ctx.Org.Organization.ID})
+	if err != nil {
+		ctx.ServerError("FindInstallations", err)
+		return
+	}
+	views := make([]*installationView, 0, len(installations))
+	for _, i := range installations {
+		a, err := app_model.GetAppByID(ctx, i.AppID)
+		if err != nil {
+			ctx.ServerError("GetAppByID", err)
+			return
+		}
+		repoIDs, err := app_model.GetInstallationRepoIDs(ctx, i.ID)
+		if err != nil {
+			ctx.ServerError("GetInstallationRepoIDs", err)
+			return
+		}
+		reposMap, err := repo_model.GetRepositoriesMapByIDs(repoIDs)
+		if err != nil {
+			ctx.ServerError("GetRepositoriesMapByIDs", err)
+			return
+		}
+		view := &installationView{Installation: i, App: a, Repos: make([]*repo_model.Repository, 0, len(repoIDs))}
+		for _, repoID := range repoIDs {
+			if repo, ok := reposMap[repoID]; ok {
+				view.Repos = append(view.Repos, repo)
+			}
+		}
+		views = append(views, view)
+	}
+	ctx.Data["Installations"] = views
+
+	ctx.HTML(http.StatusOK, tplSettingsApps)
+}
+
+// InstallAppPost installs an app on the organization, installing it again replaces the repositories it is installed on
+func InstallAppPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.InstallAppForm)
+	link := ctx.Org.OrgLink + "/settings/apps"
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return
+	}
+
+	a, err := app_model.GetAppByName(ctx, form.AppName)
+	if err != nil {
+		if app_model.IsErrAppNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("org.settings.apps.app_not_exist", form.AppName))
+			ctx.Redirect(link)
+		} else {
+			ctx.ServerError("GetAppByName", err)
+		}
+		return
+	}
+
+	var repoIDs []int64
+	if !form.AllRepositories {
+		for _, name := range strings.Split(form.Repositories, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			repo, err := repo_model.GetRepositoryByName(ctx.Org.Organization.ID, name)
+			if err != nil {
+				if repo_model.IsErrRepoNotExist(err) {
+					ctx.Flash.Error(ctx.Tr("org.settings.apps.repo_not_exist", name))
+					ctx.Redirect(link)
+				} else {
+					ctx.ServerError("GetRepositoryByName", err)
+				}
+				return
+			}
+			repoIDs = append(repoIDs, repo.ID)
+		}
+		if len(repoIDs) == 0 {
+			ctx.Flash.Error(ctx.Tr("org.settings.apps.no_repository"))
+			ctx.Redirect(link)
+			return
+		}
+	}
+
+	if _, err := app_service.Install(ctx, a, ctx.Org.Organization.AsUser(), form.AllRepositories, repoIDs); err != nil {
+		ctx.ServerError("Install", err)
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionAppInstall, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.App(a), nil, nil)
+
+	ctx.Flash.Success(ctx.Tr("org.settings.apps.install_success", a.Name))
+	ctx.Redirect(link)
+}
+
+// UninstallApp uninstalls an app from the organization
+func UninstallApp(ctx *context.Context) {
+	i, err := app_model.GetInstallationByID(ctx, ctx.FormInt64("id"))
+	if err == nil && i.OwnerID != ctx.Org.Organization.ID {
+		err = app_model.ErrInstallationNotExist{ID: i.ID}
+	}
+	var a *app_model.App
+	if err == nil {
+		a, err = app_model.GetAppByID(ctx, i.AppID)
+	}
+	if err == nil {
+		err = app_service.Uninstall(ctx, a, i)
+	}
+	if err != nil {
+		if app_model.IsErrInstallationNotExist(err) {
+			ctx.NotFound("UninstallApp", nil)
+		} else {
+			ctx.ServerError("UninstallApp", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionAppUninstall, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.App(a), nil, nil)
+
+	ctx.Flash.Success(ctx.Tr("org.settings.apps.uninstall_success", a.Name))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/apps",
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
+	"code.gitea.io/gitea/services/forms"
+	pushpolicy_service "code.gitea.io/gitea/services/pushpolicy"
+
+	"github.com/dustin/go-humanize"
+)
+
+const tplSettingsPushPolicy base.TplName = "org/settings/push_policy"
+
+// formatMaxFileSize formats the size in the largest unit which keeps it exact,
+// so that saving the form again doesn't change a limit set through the API
+func formatMaxFileSize(size int64) string {
+	switch {
+	case size == 0:
+		return ""
+	case size%humanize.MiByte == 0:
+		return fmt.Sprintf("%d MiB", size/humanize.MiByte)
+	case size%humanize.KiByte == 0:
+		return fmt.Sprintf("%d KiB", size/humanize.KiByte)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func preparePushPolicyData(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings.push_policy")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPushPolicy"] = true
+	ctx.Data["MaxScannedFileSize"] = base.FileSize(pushpolicy_service.MaxScannedFileSize)
+}
+
+// PushPolicy renders the push policy of the organization
+func PushPolicy(ctx *context.Context) {
+	preparePushPolicyData(ctx)
+
+	p, err := git_model.GetPushPolicy(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetPushPolicy", err)
+		return
+	}
+	if p == nil {
+		p = &git_model.PushPolicy{}
+	}
+	ctx.Data["PushPolicy"] = p
+	ctx.Data["MaxFileSize"] = formatMaxFileSize(p.MaxFileSize)
+
+	ctx.HTML(http.StatusOK, tplSettingsPushPolicy)
+}
+
+// PushPolicyPost creates or updates the push policy of the organization
+func PushPolicyPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.PushPolicyForm)
+	preparePushPolicyData(ctx)
+
+	p, err := git_model.GetPushPolicy(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetPushPolicy", err)
+		return
+	}
+	var before *audit_service.PushPolicyState
+	if p == nil {
+		p = &git_model.PushPolicy{OwnerID: ctx.Org.Organization.ID}
+	} else {
+		before = audit_service.PushPolicyStateOf(p)
+	}
+
+	p.DryRun = form.DryRun
+	p.ForbiddenPaths = form.ForbiddenPaths
+	p.ForbiddenExtensions = form.ForbiddenExtensions
+	p.RequireSignedCommits = form.RequireSignedCommits
+	p.CommitMessagePattern = form.CommitMessagePattern
+	p.AllowedEmailDomains = form.AllowedEmailDomains
+	p.SecretPatterns = form.SecretPatterns
+	// the submitted values are shown again if they are invalid
+	ctx.Data["PushPolicy"] = p
+	ctx.Data["MaxFileSize"] = form.MaxFileSize
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsPushPolicy)
+		return
+	}
+
+	p.MaxFileSize = 0
+	if size := strings.TrimSpace(form.MaxFileSize); size != "" {
+		parsed, err := humanize.ParseBytes(size)
+		if err != nil || parsed > math.MaxInt64 {
+			ctx.Data["Err_MaxFileSize"] = true
+			ctx.RenderWithErr(ctx.Tr("org.settings.push_policy.invalid_max_file_size", size), tplSettingsPushPolicy, form)
+			return
+		}
+		p.MaxFileSize = int64(parsed)
+	}
+
+	if err := git_model.UpdatePushPolicy(ctx, p); err != nil {
+		if git_model.IsErrInvalidPushPolicy(err) {
+			ctx.RenderWithErr(ctx.Tr("org.settings.push_policy.invalid", err.Error()), tplSettingsPushPolicy, form)
+		} else {
+			ctx.ServerError("UpdatePushPolicy", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionPushPolicyUpdate, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.PushPolicy(p), before, audit_service.PushPolicyStateOf(p))
+
+	ctx.Flash.Success(ctx.Tr("org.settings.push_policy.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/push_policy")
+}
+
+// DeletePushPolicy removes the push policy of the organization
+func DeletePushPolicy(ctx *context.Context) {
+	p, err := git_model.GetPushPolicy(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetPushPolicy", err)
+		return
+	}
+	if p != nil {
+		if err := git_model.DeletePushPolicy(ctx, ctx.Org.Organization.ID); err != nil {
+			ctx.ServerError("DeletePushPolicy", err)
+			return
+		}
+		audit_service.Record(ctx, audit_model.ActionPushPolicyDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Org.Organization.AsUser()), audit_service.PushPolicy(p), audit_service.PushPolicyStateOf(p), nil)
+		ctx.Flash.Success(ctx.Tr("org.settings.push_policy.delete_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/push_policy",
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
@@ -25,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 )
 
@@ -73,6 +75,9 @@ func TeamsAction(ctx *context.Context) {
 			return
 		}
 		err = models.AddTeamMember(ctx.Org.Team, ctx.Doer.ID)
+		if err == nil {
+			audit_service.RecordTeamMember(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Org.Team, ctx.Doer, true)
+		}
 	case "leave":
 		err = models.RemoveTeamMember(ctx.Org.Team, ctx.Doer.ID)
 		if err != nil {
@@ -86,6 +91,8 @@ func TeamsAction(ctx *context.Context) {
 				})
 				return
 			}
+		} else {
+			audit_service.RecordTeamMember(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Org.Team, ctx.Doer, false)
 		}
 		ctx.JSON(http.StatusOK,
 			map[string]interface{}{
@@ -97,7 +104,11 @@ func TeamsAction(ctx *context.Context) {
 			ctx.Error(http.StatusNotFound)
 			return
 		}
-		err = models.RemoveTeamMember(ctx.Org.Team, uid)
+		var u *user_model.User
+		u, err = user_model.GetUserByID(uid)
+		if err == nil {
+			err = models.RemoveTeamMember(ctx.Org.Team, uid)
+		}
 		if err != nil {
 			if organization.IsErrLastOrgOwner(err) {
 				ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
@@ -109,6 +120,8 @@ func TeamsAction(ctx *context.Context) {
 				})
 				return
 			}
+		} else {
+			audit_service.RecordTeamMember(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Org.Team, u, false)
 		}
 		ctx.JSON(http.StatusOK,
 			map[string]interface{}{
@@ -143,6 +156,9 @@ func TeamsAction(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("org.teams.add_duplicate_users"))
 		} else {
 			err = models.AddTeamMember(ctx.Org.Team, u.ID)
+			if err == nil {
+				audit_service.RecordTeamMember(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Org.Team, u, true)
+			}
 		}
 
 		page = "team"
@@ -195,8 +211,18 @@ func TeamsRepoAction(ctx *context.Context) {
 			return
 		}
 		err = models.AddRepository(ctx.Org.Team, repo)
+		if err == nil {
+			audit_service.RecordTeamRepository(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Org.Team, repo, true)
+		}
 	case "remove":
-		err = models.RemoveRepository(ctx.Org.Team, ctx.FormInt64("repoid"))
+		var repo *repo_model.Repository
+		repo, err = repo_model.GetRepositoryByID(ctx.FormInt64("repoid"))
+		if err == nil {
+			err = models.RemoveRepository(ctx.Org.Team, repo.ID)
+		}
+		if err == nil {
+			audit_service.RecordTeamRepository(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Org.Team, repo, false)
+		}
 	case "addall":
 		err = models.AddAllRepositories(ctx.Org.Team)
 	case "removeall":
@@ -302,6 +328,7 @@ func NewTeamPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Team created: %s/%s", ctx.Org.Organization.Name, t.Name)
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamCreate, ctx.Doer, ctx.RemoteAddr(), t, nil, audit_service.TeamStateOf(t))
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + url.PathEscape(t.LowerName))
 }
 
@@ -397,6 +424,12 @@ func EditTeamPost(ctx *context.Context) {
 	ctx.Data["Team"] = t
 	ctx.Data["Units"] = unit_model.Units
 
+	if err := t.GetUnits(); err != nil {
+		ctx.ServerError("GetUnits", err)
+		return
+	}
+	before := audit_service.TeamStateOf(t)
+
 	if !t.IsOwnerTeam() {
 		// Validate permission level.
 		newAccessMode := perm.ParseAccessMode(form.Permission)
@@ -455,14 +488,25 @@ func EditTeamPost(ctx *context.Context) {
 		}
 		return
 	}
+	if err := t.GetUnits(); err != nil {
+		log.Error("GetUnits: %v", err)
+	}
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamUpdate, ctx.Doer, ctx.RemoteAddr(), t, before, audit_service.TeamStateOf(t))
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + url.PathEscape(t.LowerName))
 }
 
 // DeleteTeam response for the delete team request
 func DeleteTeam(ctx *context.Context) {
+	if err := ctx.Org.Team.GetUnits(); err != nil {
+		ctx.ServerError("GetUnits", err)
+		return
+	}
+	before := audit_service.TeamStateOf(ctx.Org.Team)
+
 	if err := models.DeleteTeam(ctx.Org.Team); err != nil {
 		ctx.Flash.Error("DeleteTeam: " + err.Error())
 	} else {
+		audit_service.RecordTeam(ctx, audit_model.ActionTeamDelete, ctx.Doer, ctx.RemoteAddr(), ctx.Org.Team, before, nil)
 		ctx.Flash.Success(ctx.Tr("org.teams.delete_team_success"))
 	}
 
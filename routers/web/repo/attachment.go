@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"net/http"
 
+	auth_model "code.gitea.io/gitea/models/auth"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/log"
@@ -106,7 +108,23 @@ func GetAttachment(ctx *context.Context) {
 			ctx.Error(http.StatusNotFound)
 			return
 		}
+		if ctx.IsTokenRestricted() {
+			ctx.Error(http.StatusForbidden, "the access token is restricted to a repository or an organization")
+			return
+		}
 	} else { // If we have the repository we check access
+		// the personal access tokens need the scope of the attachments and mustn't be restricted to another repository or organization
+		if ctx.Data["IsApiToken"] == true {
+			category := auth_model.AccessTokenScopeCategoryIssue
+			if unitType == unit.TypeReleases {
+				category = auth_model.AccessTokenScopeCategoryRepository
+			}
+			if !ctx.TokenScopeAllows(category, auth_model.AccessTokenScopeLevelRead) || !ctx.TokenAllowsRepo(repository.ID, repository.OwnerID) {
+				ctx.Error(http.StatusForbidden, "the access token doesn't grant access to the attachment")
+				return
+			}
+		}
+
 		perm, err := access_model.GetUserRepoPermission(ctx, repository, ctx.Doer)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err.Error())
@@ -0,0 +1,130 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	ci_model "code.gitea.io/gitea/models/ci"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	ci_service "code.gitea.io/gitea/services/ci"
+)
+
+const (
+	tplCIRuns base.TplName = "repo/ci/list"
+	tplCIRun  base.TplName = "repo/ci/view"
+)
+
+// CIRuns renders the runs of the workflows of a repository
+func CIRuns(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.ci")
+	ctx.Data["PageIsCI"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	runs, total, err := ci_model.FindRuns(ctx, ci_model.FindRunsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		RepoID: ctx.Repo.Repository.ID,
+	})
+	if err != nil {
+		ctx.ServerError("FindRuns", err)
+		return
+	}
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		if err := run.LoadAttributes(ctx); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
+	ctx.Data["Runs"] = runs
+
+	pager := context.NewPagination(int(total), setting.UI.IssuePagingNum, page, 5)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplCIRuns)
+}
+
+func getCIRun(ctx *context.Context) *ci_model.Run {
+	run, err := ci_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if err == ci_model.ErrRunNotExist {
+			ctx.NotFound("GetRunByIndex", err)
+		} else {
+			ctx.ServerError("GetRunByIndex", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	return run
+}
+
+// ViewCIRun renders a run with the log of one of its jobs, the first one by default
+func ViewCIRun(ctx *context.Context) {
+	run := getCIRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	jobs, err := ci_model.GetJobsByRunID(ctx, run.ID)
+	if err != nil {
+		ctx.ServerError("GetJobsByRunID", err)
+		return
+	}
+
+	var current *ci_model.Job
+	jobID := ctx.ParamsInt64(":id")
+	for _, job := range jobs {
+		if job.ID == jobID || (jobID == 0 && current == nil) {
+			current = job
+		}
+	}
+	if current == nil {
+		ctx.NotFound("ViewCIRun", nil)
+		return
+	}
+	log, err := ci_model.GetJobLog(ctx, current.ID)
+	if err != nil {
+		ctx.ServerError("GetJobLog", err)
+		return
+	}
+
+	ctx.Data["Title"] = run.Title
+	ctx.Data["PageIsCI"] = true
+	ctx.Data["Run"] = run
+	ctx.Data["Jobs"] = jobs
+	ctx.Data["CurrentJob"] = current
+	ctx.Data["JobLog"] = log
+	ctx.Data["CanCancel"] = ctx.Repo.CanWrite(unit.TypeCode) && !run.Status.IsDone()
+
+	ctx.HTML(http.StatusOK, tplCIRun)
+}
+
+// CancelCIRun cancels the jobs of a run which are not done yet
+func CancelCIRun(ctx *context.Context) {
+	run := getCIRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := ci_service.CancelRun(ctx, run); err != nil {
+		ctx.ServerError("CancelRun", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.ci.run.cancel_success"))
+	ctx.Redirect(run.Link())
+}
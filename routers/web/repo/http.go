@@ -181,7 +181,39 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 			return
 		}
 
-		if repoExist {
+		// the personal access tokens require a repository scope and can be restricted to a repository or an organization
+		tokenLevel := auth.AccessTokenScopeLevelRead
+		if !isPull {
+			tokenLevel = auth.AccessTokenScopeLevelWrite
+		}
+		if !ctx.TokenScopeAllows(auth.AccessTokenScopeCategoryRepository, tokenLevel) ||
+			(repoExist && !ctx.TokenAllowsRepo(repo.ID, repo.OwnerID)) || (!repoExist && ctx.IsTokenRestricted()) {
+			ctx.PlainText(http.StatusForbidden, "The access token doesn't grant the access to this repository")
+			return
+		}
+
+		// the deploy tokens can only access the existing repositories of their repository or organization,
+		// like the deploy keys the pushes are made in the name of the owner of the repository
+		pusher := ctx.Doer
+		deployToken := ctx.DeployToken()
+		if deployToken != nil {
+			if !repoExist || !deployToken.CanAccessRepo(repo.ID, repo.OwnerID) || deployToken.Mode < accessMode ||
+				!repo.UnitEnabledCtx(ctx, unitType) {
+				ctx.PlainText(http.StatusForbidden, "The deploy token doesn't grant the access to this repository")
+				return
+			}
+
+			if !isPull && repo.IsMirror {
+				ctx.PlainText(http.StatusForbidden, "mirror repository is read-only")
+				return
+			}
+
+			if err := repo.GetOwner(ctx); err != nil {
+				ctx.ServerError("GetOwner", err)
+				return
+			}
+			pusher = repo.Owner
+		} else if repoExist {
 			p, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
 			if err != nil {
 				ctx.ServerError("GetUserRepoPermission", err)
@@ -207,13 +239,17 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		environ = []string{
 			repo_module.EnvRepoUsername + "=" + username,
 			repo_module.EnvRepoName + "=" + reponame,
-			repo_module.EnvPusherName + "=" + ctx.Doer.Name,
-			repo_module.EnvPusherID + fmt.Sprintf("=%d", ctx.Doer.ID),
+			repo_module.EnvPusherName + "=" + pusher.Name,
+			repo_module.EnvPusherID + fmt.Sprintf("=%d", pusher.ID),
 			repo_module.EnvAppURL + "=" + setting.AppURL,
 		}
 
-		if !ctx.Doer.KeepEmailPrivate {
-			environ = append(environ, repo_module.EnvPusherEmail+"="+ctx.Doer.Email)
+		if !pusher.KeepEmailPrivate {
+			environ = append(environ, repo_module.EnvPusherEmail+"="+pusher.Email)
+		}
+
+		if deployToken != nil {
+			environ = append(environ, repo_module.EnvDeployTokenID+fmt.Sprintf("=%d", deployToken.ID))
 		}
 
 		if isWiki {
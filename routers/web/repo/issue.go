@@ -24,6 +24,7 @@ import (
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	project_model "code.gitea.io/gitea/models/project"
 	pull_model "code.gitea.io/gitea/models/pull"
@@ -35,6 +36,7 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/git"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	issue_template "code.gitea.io/gitea/modules/issue/template"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/markdown"
@@ -461,9 +463,7 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *repo_model.R
 }
 
 func retrieveProjects(ctx *context.Context, repo *repo_model.Repository) {
-	var err error
-
-	ctx.Data["OpenProjects"], _, err = project_model.GetProjects(ctx, project_model.SearchOptions{
+	openProjects, _, err := project_model.GetProjects(ctx, project_model.SearchOptions{
 		RepoID:   repo.ID,
 		Page:     -1,
 		IsClosed: util.OptionalBoolFalse,
@@ -474,7 +474,7 @@ func retrieveProjects(ctx *context.Context, repo *repo_model.Repository) {
 		return
 	}
 
-	ctx.Data["ClosedProjects"], _, err = project_model.GetProjects(ctx, project_model.SearchOptions{
+	closedProjects, _, err := project_model.GetProjects(ctx, project_model.SearchOptions{
 		RepoID:   repo.ID,
 		Page:     -1,
 		IsClosed: util.OptionalBoolTrue,
@@ -484,6 +484,45 @@ func retrieveProjects(ctx *context.Context, repo *repo_model.Repository) {
 		ctx.ServerError("GetProjects", err)
 		return
 	}
+
+	for _, p := range openProjects {
+		p.Repo = repo
+	}
+	for _, p := range closedProjects {
+		p.Repo = repo
+	}
+
+	// issues can also be added to the projects of the repository owner
+	if err := repo.GetOwner(ctx); err != nil {
+		ctx.ServerError("GetOwner", err)
+		return
+	}
+	accessMode, err := access_model.GetOwnerUnitAccessMode(ctx, repo.Owner, ctx.Doer, unit.TypeProjects)
+	if err != nil {
+		ctx.ServerError("GetOwnerUnitAccessMode", err)
+		return
+	}
+	if accessMode >= perm.AccessModeWrite {
+		ownerProjects, _, err := project_model.GetProjects(ctx, project_model.SearchOptions{
+			OwnerID: repo.OwnerID,
+			Page:    -1,
+		})
+		if err != nil {
+			ctx.ServerError("GetProjects", err)
+			return
+		}
+		for _, p := range ownerProjects {
+			p.Owner = repo.Owner
+			if p.IsClosed {
+				closedProjects = append(closedProjects, p)
+			} else {
+				openProjects = append(openProjects, p)
+			}
+		}
+	}
+
+	ctx.Data["OpenProjects"] = openProjects
+	ctx.Data["ClosedProjects"] = closedProjects
 }
 
 // repoReviewerSelection items to bee shown
@@ -750,7 +789,8 @@ func getFileContentFromDefaultBranch(ctx *context.Context, filename string) (str
 	return string(bytes), true
 }
 
-func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleDirs, possibleFiles []string) {
+// setTemplateIfExists loads the first template which exists, it returns false if there is none
+func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleDirs, possibleFiles []string) bool {
 	templateCandidates := make([]string, 0, len(possibleFiles))
 	if ctx.FormString("template") != "" {
 		for _, dirName := range possibleDirs {
@@ -760,43 +800,75 @@ func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleDirs,
 	templateCandidates = append(templateCandidates, possibleFiles...) // Append files to the end because they should be fallback
 	for _, filename := range templateCandidates {
 		templateContent, found := getFileContentFromDefaultBranch(ctx, filename)
-		if found {
-			var meta api.IssueTemplate
-			templateBody, err := markdown.ExtractMetadata(templateContent, &meta)
+		if !found {
+			continue
+		}
+
+		if ctxDataKey == issueTemplateKey && issue_template.IsFormFile(filename) {
+			form, err := issue_template.Unmarshal(filename, []byte(templateContent))
 			if err != nil {
-				log.Debug("could not extract metadata from %s [%s]: %v", filename, ctx.Repo.Repository.FullName(), err)
-				ctx.Data[ctxDataKey] = templateContent
-				return
+				log.Debug("invalid issue form %s [%s]: %v", filename, ctx.Repo.Repository.FullName(), err)
+				continue
 			}
-			ctx.Data[issueTemplateTitleKey] = meta.Title
-			ctx.Data[ctxDataKey] = templateBody
-			labelIDs := make([]string, 0, len(meta.Labels))
-			if repoLabels, err := issues_model.GetLabelsByRepoID(ctx, ctx.Repo.Repository.ID, "", db.ListOptions{}); err == nil {
-				ctx.Data["Labels"] = repoLabels
-				if ctx.Repo.Owner.IsOrganization() {
-					if orgLabels, err := issues_model.GetLabelsByOrgID(ctx, ctx.Repo.Owner.ID, ctx.FormString("sort"), db.ListOptions{}); err == nil {
-						ctx.Data["OrgLabels"] = orgLabels
-						repoLabels = append(repoLabels, orgLabels...)
-					}
-				}
+			ctx.Data["IssueTemplateFile"] = filename
+			ctx.Data["IssueTemplateFields"] = form.Fields
+			setTemplateMeta(ctx, form)
+			return true
+		}
 
-				for _, metaLabel := range meta.Labels {
-					for _, repoLabel := range repoLabels {
-						if strings.EqualFold(repoLabel.Name, metaLabel) {
-							repoLabel.IsChecked = true
-							labelIDs = append(labelIDs, strconv.FormatInt(repoLabel.ID, 10))
-							break
-						}
-					}
+		var meta api.IssueTemplate
+		templateBody, err := markdown.ExtractMetadata(templateContent, &meta)
+		if err != nil {
+			log.Debug("could not extract metadata from %s [%s]: %v", filename, ctx.Repo.Repository.FullName(), err)
+			ctx.Data[ctxDataKey] = templateContent
+			return true
+		}
+		ctx.Data[ctxDataKey] = templateBody
+		setTemplateMeta(ctx, &meta)
+		return true
+	}
+	return false
+}
+
+// setTemplateMeta presets the title, the labels and the ref of the template
+func setTemplateMeta(ctx *context.Context, meta *api.IssueTemplate) {
+	ctx.Data[issueTemplateTitleKey] = meta.Title
+	labelIDs := make([]string, 0, len(meta.Labels))
+	if repoLabels, err := issues_model.GetLabelsByRepoID(ctx, ctx.Repo.Repository.ID, "", db.ListOptions{}); err == nil {
+		ctx.Data["Labels"] = repoLabels
+		if ctx.Repo.Owner.IsOrganization() {
+			if orgLabels, err := issues_model.GetLabelsByOrgID(ctx, ctx.Repo.Owner.ID, ctx.FormString("sort"), db.ListOptions{}); err == nil {
+				ctx.Data["OrgLabels"] = orgLabels
+				repoLabels = append(repoLabels, orgLabels...)
+			}
+		}
+
+		for _, metaLabel := range meta.Labels {
+			for _, repoLabel := range repoLabels {
+				if strings.EqualFold(repoLabel.Name, metaLabel) {
+					repoLabel.IsChecked = true
+					labelIDs = append(labelIDs, strconv.FormatInt(repoLabel.ID, 10))
+					break
 				}
 			}
-			ctx.Data["HasSelectedLabel"] = len(labelIDs) > 0
-			ctx.Data["label_ids"] = strings.Join(labelIDs, ",")
-			ctx.Data["Reference"] = meta.Ref
-			ctx.Data["RefEndName"] = git.RefEndName(meta.Ref)
-			return
 		}
 	}
+	ctx.Data["HasSelectedLabel"] = len(labelIDs) > 0
+	ctx.Data["label_ids"] = strings.Join(labelIDs, ",")
+	ctx.Data["Reference"] = meta.Ref
+	ctx.Data["RefEndName"] = git.RefEndName(meta.Ref)
+}
+
+// loadIssueForm loads the issue form submitted with a new issue, it must be in an issue template directory
+func loadIssueForm(ctx *context.Context, filename string) (*api.IssueTemplate, error) {
+	if !issue_template.IsFormFile(filename) || !util.IsStringInSlice(path.Dir(filename), context.IssueTemplateDirCandidates) {
+		return nil, fmt.Errorf("%s is not an issue form", filename)
+	}
+	content, found := getFileContentFromDefaultBranch(ctx, filename)
+	if !found {
+		return nil, fmt.Errorf("%s does not exist", filename)
+	}
+	return issue_template.Unmarshal(filename, []byte(content))
 }
 
 // NewIssue render creating issue page
@@ -845,11 +917,21 @@ func NewIssue(ctx *context.Context) {
 	}
 
 	RetrieveRepoMetas(ctx, ctx.Repo.Repository, false)
-	setTemplateIfExists(ctx, issueTemplateKey, context.IssueTemplateDirCandidates, IssueTemplateCandidates)
+	hasTemplate := setTemplateIfExists(ctx, issueTemplateKey, context.IssueTemplateDirCandidates, IssueTemplateCandidates)
 	if ctx.Written() {
 		return
 	}
 
+	if !hasTemplate && ctx.Data["NewIssueChooseTemplate"].(bool) {
+		issueConfig, err := ctx.IssueConfigFromDefaultBranch()
+		if err != nil {
+			log.Debug("invalid issue config [%s]: %v", ctx.Repo.Repository.FullName(), err)
+		} else if !issueConfig.BlankIssuesEnabled {
+			ctx.Redirect(fmt.Sprintf("%s/issues/new/choose?%s", ctx.Repo.RepoLink, ctx.Req.URL.RawQuery), http.StatusSeeOther)
+			return
+		}
+	}
+
 	ctx.Data["HasIssuesOrPullsWritePermission"] = ctx.Repo.CanWrite(unit.TypeIssues)
 
 	ctx.HTML(http.StatusOK, tplIssueNew)
@@ -860,10 +942,23 @@ func NewIssueChooseTemplate(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.issues.new")
 	ctx.Data["PageIsIssueList"] = true
 
-	issueTemplates := ctx.IssueTemplatesFromDefaultBranch()
+	issueTemplates, invalidTemplates := ctx.IssueTemplatesAndErrorsFromDefaultBranch()
 	ctx.Data["IssueTemplates"] = issueTemplates
 
-	if len(issueTemplates) == 0 {
+	issueConfig, err := ctx.IssueConfigFromDefaultBranch()
+	if err != nil {
+		log.Debug("invalid issue config [%s]: %v", ctx.Repo.Repository.FullName(), err)
+		issueConfig = &api.IssueConfig{BlankIssuesEnabled: true}
+		if ctx.Repo.CanWrite(unit.TypeCode) {
+			ctx.Data["IssueConfigError"] = err.Error()
+		}
+	}
+	ctx.Data["IssueConfig"] = issueConfig
+	if len(invalidTemplates) > 0 && ctx.Repo.CanWrite(unit.TypeCode) {
+		ctx.Data["IssueTemplateErrors"] = invalidTemplates
+	}
+
+	if len(issueTemplates) == 0 && len(issueConfig.ContactLinks) == 0 {
 		// The "issues/new" and "issues/new/choose" share the same query parameters "project" and "milestone", if no template here, just redirect to the "issues/new" page with these parameters.
 		ctx.Redirect(fmt.Sprintf("%s/issues/new?%s", ctx.Repo.Repository.HTMLURL(), ctx.Req.URL.RawQuery), http.StatusSeeOther)
 		return
@@ -1021,6 +1116,20 @@ func NewIssuePost(ctx *context.Context) {
 		attachments = form.Files
 	}
 
+	var issueForm *api.IssueTemplate
+	if form.TemplateFile != "" {
+		var err error
+		issueForm, err = loadIssueForm(ctx, form.TemplateFile)
+		if err != nil {
+			log.Debug("loadIssueForm: %v", err)
+			ctx.RenderWithErr(ctx.Tr("repo.issues.form.invalid"), tplIssueNew, form)
+			return
+		}
+		ctx.Data["IssueTemplateFile"] = form.TemplateFile
+		ctx.Data["IssueTemplateFields"] = issueForm.Fields
+		ctx.Data["IssueFormValues"] = ctx.Req.Form
+	}
+
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplIssueNew)
 		return
@@ -1031,6 +1140,19 @@ func NewIssuePost(ctx *context.Context) {
 		return
 	}
 
+	if issueForm != nil {
+		if err := issue_template.ValidateValues(issueForm, ctx.Req.Form); err != nil {
+			var valueErr *issue_template.ValueError
+			if !errors.As(err, &valueErr) {
+				ctx.ServerError("ValidateValues", err)
+				return
+			}
+			ctx.RenderWithErr(ctx.Tr("repo.issues.form.value_"+string(valueErr.Reason), valueErr.Field.Attributes.Label), tplIssueNew, form)
+			return
+		}
+		form.Content = issue_template.RenderToMarkdown(issueForm, ctx.Req.Form)
+	}
+
 	issue := &issues_model.Issue{
 		RepoID:      repo.ID,
 		Repo:        repo,
@@ -1057,6 +1179,13 @@ func NewIssuePost(ctx *context.Context) {
 			ctx.Error(http.StatusBadRequest, "user hasn't permissions to read projects")
 			return
 		}
+		if canAssign, err := canAssignIssuesToProject(ctx, projectID); err != nil {
+			ctx.ServerError("canAssignIssuesToProject", err)
+			return
+		} else if !canAssign {
+			ctx.Error(http.StatusBadRequest, "user hasn't permissions to add issues to the project")
+			return
+		}
 		if err := issues_model.ChangeProjectAssign(issue, ctx.Doer, projectID); err != nil {
 			ctx.ServerError("ChangeProjectAssign", err)
 			return
@@ -1544,6 +1673,15 @@ func ViewIssue(ctx *context.Context) {
 		canDelete := false
 		ctx.Data["AllowMerge"] = false
 
+		if !issue.IsClosed {
+			stack, err := pull_service.GetPullRequestStack(ctx, pull)
+			if err != nil {
+				ctx.ServerError("GetPullRequestStack", err)
+				return
+			}
+			ctx.Data["PullStack"] = stack
+		}
+
 		if ctx.IsSigned {
 			if err := pull.LoadHeadRepoCtx(ctx); err != nil {
 				log.Error("LoadHeadRepo: %v", err)
@@ -1644,6 +1782,16 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["IsBlockedByRejection"] = issues_model.MergeBlockedByRejectedReview(ctx, pull.ProtectedBranch, pull)
 			ctx.Data["IsBlockedByOfficialReviewRequests"] = issues_model.MergeBlockedByOfficialReviewRequests(ctx, pull.ProtectedBranch, pull)
 			ctx.Data["IsBlockedByOutdatedBranch"] = issues_model.MergeBlockedByOutdatedBranch(pull.ProtectedBranch, pull)
+			if now := time.Now(); !pull.ProtectedBranch.IsInMergeWindow(now) {
+				ctx.Data["IsBlockedByMergeWindow"] = true
+				ctx.Data["NextMergeWindow"] = pull.ProtectedBranch.NextMergeWindow(now)
+			}
+			unapprovedCodeOwnerGroups, err := pull_service.GetUnapprovedCodeOwnerGroups(ctx, pull, ctx.Repo.GitRepo)
+			if err != nil {
+				log.Error("GetUnapprovedCodeOwnerGroups for %-v: %v", pull, err)
+			}
+			ctx.Data["IsBlockedByCodeOwners"] = len(unapprovedCodeOwnerGroups) > 0
+			ctx.Data["UnapprovedCodeOwnerGroups"] = unapprovedCodeOwnerGroups
 			ctx.Data["GrantedApprovals"] = issues_model.GetGrantedApprovalsCount(ctx, pull.ProtectedBranch, pull)
 			ctx.Data["RequireSigned"] = pull.ProtectedBranch.RequireSignedCommits
 			ctx.Data["ChangedProtectedFiles"] = pull.ChangedProtectedFiles
@@ -1706,6 +1854,24 @@ func ViewIssue(ctx *context.Context) {
 			ctx.ServerError("GetScheduledMergeByPullID", err)
 			return
 		}
+
+		// Check if the pr is waiting in the merge queue
+		if entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pull.ID); err == nil {
+			if err := entry.LoadDoer(ctx); err != nil {
+				ctx.ServerError("LoadDoer", err)
+				return
+			}
+			ctx.Data["MergeQueueEntry"] = entry
+			ctx.Data["MergeQueuePosition"], err = pull_model.GetMergeQueuePosition(ctx, entry)
+			if err != nil {
+				ctx.ServerError("GetMergeQueuePosition", err)
+				return
+			}
+			ctx.Data["CanRemoveFromMergeQueue"] = ctx.Doer != nil && (entry.DoerID == ctx.Doer.ID || ctx.Repo.IsAdmin())
+		} else if !db.IsErrNotExist(err) {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+			return
+		}
 	}
 
 	// Get Dependencies
@@ -86,3 +86,31 @@ func DeleteTime(c *context.Context) {
 	c.Flash.Success(c.Tr("repo.issues.del_time_history", util.SecToTime(t.Time)))
 	c.Redirect(issue.HTMLURL())
 }
+
+// UpdateTimeEstimate sets the time estimate of the issue, an empty estimate removes it
+func UpdateTimeEstimate(c *context.Context) {
+	form := web.GetForm(c).(*forms.AddTimeManuallyForm)
+	issue := GetActionIssue(c)
+	if c.Written() {
+		return
+	}
+	if !c.Repo.Repository.IsTimetrackerEnabled() || !c.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		c.NotFound("CanWriteIssuesOrPulls", nil)
+		return
+	}
+	url := issue.HTMLURL()
+
+	if c.HasError() {
+		c.Flash.Error(c.GetErrMsg())
+		c.Redirect(url)
+		return
+	}
+
+	total := time.Duration(form.Hours)*time.Hour + time.Duration(form.Minutes)*time.Minute
+	if err := issues_model.UpdateIssueTimeEstimate(c, issue, int64(total.Seconds())); err != nil {
+		c.ServerError("UpdateIssueTimeEstimate", err)
+		return
+	}
+
+	c.Redirect(url, http.StatusSeeOther)
+}
@@ -117,6 +117,7 @@ func NewMilestone(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.milestones.new")
 	ctx.Data["PageIsIssueList"] = true
 	ctx.Data["PageIsMilestones"] = true
+	ctx.Data["IsTimetrackerEnabled"] = ctx.Repo.Repository.IsTimetrackerEnabled()
 	ctx.HTML(http.StatusOK, tplMilestoneNew)
 }
 
@@ -126,6 +127,7 @@ func NewMilestonePost(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.milestones.new")
 	ctx.Data["PageIsIssueList"] = true
 	ctx.Data["PageIsMilestones"] = true
+	ctx.Data["IsTimetrackerEnabled"] = ctx.Repo.Repository.IsTimetrackerEnabled()
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplMilestoneNew)
@@ -148,6 +150,7 @@ func NewMilestonePost(ctx *context.Context) {
 		Name:         form.Title,
 		Content:      form.Content,
 		DeadlineUnix: timeutil.TimeStamp(deadline.Unix()),
+		TimeBudget:   int64(form.TimeBudget) * 3600,
 	}); err != nil {
 		ctx.ServerError("NewMilestone", err)
 		return
@@ -162,6 +165,7 @@ func EditMilestone(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.milestones.edit")
 	ctx.Data["PageIsMilestones"] = true
 	ctx.Data["PageIsEditMilestone"] = true
+	ctx.Data["IsTimetrackerEnabled"] = ctx.Repo.Repository.IsTimetrackerEnabled()
 
 	m, err := issues_model.GetMilestoneByRepoID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
 	if err != nil {
@@ -177,6 +181,9 @@ func EditMilestone(ctx *context.Context) {
 	if len(m.DeadlineString) > 0 {
 		ctx.Data["deadline"] = m.DeadlineString
 	}
+	if m.TimeBudget > 0 {
+		ctx.Data["time_budget"] = m.TimeBudget / 3600
+	}
 	ctx.HTML(http.StatusOK, tplMilestoneNew)
 }
 
@@ -186,6 +193,7 @@ func EditMilestonePost(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.milestones.edit")
 	ctx.Data["PageIsMilestones"] = true
 	ctx.Data["PageIsEditMilestone"] = true
+	ctx.Data["IsTimetrackerEnabled"] = ctx.Repo.Repository.IsTimetrackerEnabled()
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplMilestoneNew)
@@ -215,6 +223,9 @@ func EditMilestonePost(ctx *context.Context) {
 	m.Name = form.Title
 	m.Content = form.Content
 	m.DeadlineUnix = timeutil.TimeStamp(deadline.Unix())
+	if ctx.Repo.Repository.IsTimetrackerEnabled() {
+		m.TimeBudget = int64(form.TimeBudget) * 3600
+	}
 	if err = issues_model.UpdateMilestone(m, m.IsClosed); err != nil {
 		ctx.ServerError("UpdateMilestone", err)
 		return
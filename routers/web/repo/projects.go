@@ -13,6 +13,7 @@ import (
 
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
 	project_model "code.gitea.io/gitea/models/project"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/base"
@@ -297,7 +298,7 @@ func ViewProject(ctx *context.Context) {
 		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
 	}
 
-	issuesMap, err := issues_model.LoadIssuesFromBoardList(boards)
+	issuesMap, err := issues_model.LoadIssuesFromBoardList(boards, nil)
 	if err != nil {
 		ctx.ServerError("LoadIssuesOfBoards", err)
 		return
@@ -345,6 +346,30 @@ func ViewProject(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplProjectsView)
 }
 
+// canAssignIssuesToProject checks if the doer may add issues of the current repository to the project.
+// Besides the projects of the repository, these are the projects of individuals and organizations the doer can write to.
+func canAssignIssuesToProject(ctx *context.Context, projectID int64) (bool, error) {
+	p, err := project_model.GetProjectByID(ctx, projectID)
+	if err != nil {
+		if project_model.IsErrProjectNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !p.IsOwnerProject() {
+		return p.RepoID == ctx.Repo.Repository.ID, nil
+	}
+
+	if err := p.LoadOwner(ctx); err != nil {
+		return false, err
+	}
+	accessMode, err := access_model.GetOwnerUnitAccessMode(ctx, p.Owner, ctx.Doer, unit.TypeProjects)
+	if err != nil {
+		return false, err
+	}
+	return accessMode >= perm.AccessModeWrite, nil
+}
+
 // UpdateIssueProject change an issue's project
 func UpdateIssueProject(ctx *context.Context) {
 	issues := getActionIssues(ctx)
@@ -353,6 +378,16 @@ func UpdateIssueProject(ctx *context.Context) {
 	}
 
 	projectID := ctx.FormInt64("id")
+	if projectID > 0 {
+		if canAssign, err := canAssignIssuesToProject(ctx, projectID); err != nil {
+			ctx.ServerError("canAssignIssuesToProject", err)
+			return
+		} else if !canAssign {
+			ctx.Error(http.StatusForbidden, "user hasn't permissions to add issues to the project")
+			return
+		}
+	}
+
 	for _, issue := range issues {
 		oldProjectID := issue.ProjectID()
 		if oldProjectID == projectID {
@@ -33,6 +33,7 @@ import (
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/upload"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
@@ -972,22 +973,53 @@ func MergePullRequest(ctx *context.Context) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
-	if form.MergeWhenChecksSucceed {
+	if form.MergeWhenChecksSucceed || form.MergeAt > 0 {
 		// delete all scheduled auto merges
 		_ = pull_model.DeleteScheduledAutoMerge(ctx, pr.ID)
 		// schedule auto merge
-		scheduled, err := automerge.ScheduleAutoMerge(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message)
+		scheduled, err := automerge.ScheduleAutoMerge(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message, timeutil.TimeStamp(form.MergeAt))
 		if err != nil {
 			ctx.ServerError("ScheduleAutoMerge", err)
 			return
 		} else if scheduled {
 			// nothing more to do ...
-			ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_newly_scheduled"))
+			if timeutil.TimeStamp(form.MergeAt) > timeutil.TimeStampNow() {
+				ctx.Flash.Success(ctx.Tr("repo.pulls.scheduled_merge_newly_scheduled", timeutil.TimeStamp(form.MergeAt).FormatLong()))
+			} else {
+				ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_newly_scheduled"))
+			}
 			ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, pr.Index))
 			return
 		}
 	}
 
+	if !forceMerge {
+		mergeQueueEnabled, err := pull_service.IsMergeQueueEnabled(ctx, pr)
+		if err != nil {
+			ctx.ServerError("IsMergeQueueEnabled", err)
+			return
+		}
+		if mergeQueueEnabled {
+			if err := pull_service.AddToMergeQueue(ctx, pr, ctx.Doer, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
+				if models.IsErrInvalidMergeStyle(err) {
+					ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+				} else if models.IsErrSHADoesNotMatch(err) {
+					ctx.Flash.Error(ctx.Tr("repo.pulls.head_out_of_date"))
+				} else if pull_model.IsErrAlreadyInMergeQueue(err) {
+					ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_already_added"))
+				} else {
+					ctx.ServerError("AddToMergeQueue", err)
+					return
+				}
+				ctx.Redirect(issue.Link())
+				return
+			}
+			ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_newly_added"))
+			ctx.Redirect(issue.Link())
+			return
+		}
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
@@ -1112,6 +1144,41 @@ func CancelAutoMergePullRequest(ctx *context.Context) {
 	ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index))
 }
 
+// RemoveFromMergeQueue removes a pr from the merge queue of its base branch
+func RemoveFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, issue.PullRequest.ID)
+	if err != nil {
+		if db.IsErrNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_not_added"))
+			ctx.Redirect(issue.Link())
+			return
+		}
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return
+	}
+	if entry.DoerID != ctx.Doer.ID && !ctx.Repo.IsAdmin() {
+		ctx.NotFound("RemoveFromMergeQueue", nil)
+		return
+	}
+
+	if err := pull_service.RemoveFromMergeQueue(ctx, issue.PullRequest, ctx.Doer); err != nil {
+		if db.IsErrNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_not_added"))
+			ctx.Redirect(issue.Link())
+			return
+		}
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_removed"))
+	ctx.Redirect(issue.Link())
+}
+
 func stopTimerIfAvailable(user *user_model.User, issue *issues_model.Issue) error {
 	if issues_model.StopwatchExists(user.ID, issue.ID) {
 		if err := issues_model.CreateOrStopIssueStopwatch(user, issue); err != nil {
@@ -16,6 +16,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
@@ -39,6 +40,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	"code.gitea.io/gitea/services/migrations"
@@ -96,6 +98,30 @@ func SettingsCtxData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["PushMirrors"] = pushMirrors
+
+	pushMirrorRefs := make(map[int64][]*repo_model.PushMirrorRef, len(pushMirrors))
+	pushMirrorSyncErrors := make(map[int64][]*repo_model.PushMirrorSyncError, len(pushMirrors))
+	for _, m := range pushMirrors {
+		if pushMirrorRefs[m.ID], err = repo_model.GetPushMirrorRefs(ctx, m.ID); err != nil {
+			ctx.ServerError("GetPushMirrorRefs", err)
+			return
+		}
+		if pushMirrorSyncErrors[m.ID], _, err = repo_model.GetPushMirrorSyncErrors(ctx, m.ID, db.ListOptions{Page: 1, PageSize: 10}); err != nil {
+			ctx.ServerError("GetPushMirrorSyncErrors", err)
+			return
+		}
+	}
+	ctx.Data["PushMirrorRefs"] = pushMirrorRefs
+	ctx.Data["PushMirrorSyncErrors"] = pushMirrorSyncErrors
+
+	if ctx.Repo.Repository.IsMirror {
+		mirrorBackups, _, err := repo_model.GetMirrorBackups(ctx, ctx.Repo.Repository.ID, db.ListOptions{Page: 1, PageSize: 20})
+		if err != nil {
+			ctx.ServerError("GetMirrorBackups", err)
+			return
+		}
+		ctx.Data["MirrorBackups"] = mirrorBackups
+	}
 }
 
 // Settings show a repository's settings page
@@ -188,6 +214,9 @@ func SettingsPost(ctx *context.Context) {
 			ctx.ServerError("UpdateRepository", err)
 			return
 		}
+		if visibilityChanged {
+			audit_service.Record(ctx, audit_model.ActionRepositoryVisibility, ctx.Doer, ctx.RemoteAddr(), audit_service.Repository(repo), audit_service.Repository(repo), &audit_service.VisibilityState{Private: !repo.IsPrivate}, audit_service.Visibility(repo))
+		}
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
@@ -210,7 +239,13 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		if form.DetectForcePush && git.CheckGitVersionAtLeast("2.29") != nil {
+			ctx.RenderWithErr(ctx.Tr("repo.mirror_detect_force_push_unsupported"), tplSettingsOptions, &form)
+			return
+		}
+
 		ctx.Repo.Mirror.EnablePrune = form.EnablePrune
+		ctx.Repo.Mirror.DetectForcePush = form.DetectForcePush
 		ctx.Repo.Mirror.Interval = interval
 		ctx.Repo.Mirror.ScheduleNextUpdate()
 		if err := repo_model.UpdateMirror(ctx, ctx.Repo.Mirror); err != nil {
@@ -278,6 +313,30 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Info(ctx.Tr("repo.settings.mirror_sync_in_progress"))
 		ctx.Redirect(repo.Link() + "/settings")
 
+	case "mirror-backup-delete":
+		if !setting.Mirror.Enabled || !repo.IsMirror {
+			ctx.NotFound("", nil)
+			return
+		}
+
+		backup, err := repo_model.GetMirrorBackup(ctx, repo.ID, ctx.FormInt64("mirror_backup_id"))
+		if err != nil {
+			if errors.Is(err, repo_model.ErrMirrorBackupNotExist) {
+				ctx.NotFound("", nil)
+			} else {
+				ctx.ServerError("GetMirrorBackup", err)
+			}
+			return
+		}
+
+		if err := mirror_service.DeleteMirrorBackup(ctx, repo, backup); err != nil {
+			ctx.ServerError("DeleteMirrorBackup", err)
+			return
+		}
+
+		ctx.Flash.Success(ctx.Tr("repo.mirror_backup_deleted"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "push-mirror-sync":
 		if !setting.Mirror.Enabled {
 			ctx.NotFound("", nil)
@@ -341,6 +400,12 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		if _, err := repo_model.ParsePushMirrorRefFilter(form.PushMirrorRefFilter); err != nil {
+			ctx.Data["Err_PushMirrorRefFilter"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.mirror_settings.push_mirror.ref_filter_invalid", err.Error()), tplSettingsOptions, &form)
+			return
+		}
+
 		address, err := forms.ParseRemoteAddr(form.PushMirrorAddress, form.PushMirrorUsername, form.PushMirrorPassword)
 		if err == nil {
 			err = migrations.IsMigrateURLAllowed(address, ctx.Doer)
@@ -358,11 +423,13 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		m := &repo_model.PushMirror{
-			RepoID:       repo.ID,
-			Repo:         repo,
-			RemoteName:   fmt.Sprintf("remote_mirror_%s", remoteSuffix),
-			SyncOnCommit: form.PushMirrorSyncOnCommit,
-			Interval:     interval,
+			RepoID:           repo.ID,
+			Repo:             repo,
+			RemoteName:       fmt.Sprintf("remote_mirror_%s", remoteSuffix),
+			RefFilter:        strings.TrimSpace(form.PushMirrorRefFilter),
+			ProtectForcePush: form.PushMirrorProtectForcePush,
+			SyncOnCommit:     form.PushMirrorSyncOnCommit,
+			Interval:         interval,
 		}
 		if err := repo_model.InsertPushMirror(ctx, m); err != nil {
 			ctx.ServerError("InsertPushMirror", err)
@@ -917,6 +984,12 @@ func CollaborationPost(ctx *context.Context) {
 		ctx.ServerError("AddCollaborator", err)
 		return
 	}
+	collaboration, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, u.ID)
+	if err != nil {
+		ctx.ServerError("GetCollaboration", err)
+		return
+	}
+	audit_service.RecordCollaboration(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Repo.Repository, u, nil, collaboration)
 
 	if setting.Service.EnableNotifyMail {
 		mailer.SendCollaboratorMail(u, ctx.Doer, ctx.Repo.Repository)
@@ -928,19 +1001,53 @@ func CollaborationPost(ctx *context.Context) {
 
 // ChangeCollaborationAccessMode response for changing access of a collaboration
 func ChangeCollaborationAccessMode(ctx *context.Context) {
+	uid := ctx.FormInt64("uid")
+	before, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, uid)
+	if err != nil {
+		log.Error("GetCollaboration: %v", err)
+		return
+	}
 	if err := repo_model.ChangeCollaborationAccessMode(
 		ctx.Repo.Repository,
-		ctx.FormInt64("uid"),
+		uid,
 		perm.AccessMode(ctx.FormInt("mode"))); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+		return
 	}
+	if before == nil {
+		return
+	}
+
+	after, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, uid)
+	if err != nil {
+		log.Error("GetCollaboration: %v", err)
+		return
+	}
+	u, err := user_model.GetUserByID(uid)
+	if err != nil {
+		log.Error("GetUserByID: %v", err)
+		return
+	}
+	audit_service.RecordCollaboration(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Repo.Repository, u, before, after)
 }
 
 // DeleteCollaboration delete a collaboration for a repository
 func DeleteCollaboration(ctx *context.Context) {
-	if err := models.DeleteCollaboration(ctx.Repo.Repository, ctx.FormInt64("id")); err != nil {
+	uid := ctx.FormInt64("id")
+	before, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, uid)
+	if err == nil {
+		err = models.DeleteCollaboration(ctx.Repo.Repository, uid)
+	}
+	if err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		if before != nil {
+			if u, err := user_model.GetUserByID(uid); err != nil {
+				log.Error("GetUserByID: %v", err)
+			} else {
+				audit_service.RecordCollaboration(ctx, ctx.Doer, ctx.RemoteAddr(), ctx.Repo.Repository, u, before, nil)
+			}
+		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -990,6 +1097,7 @@ func AddTeamPost(ctx *context.Context) {
 		ctx.ServerError("team.AddRepository", err)
 		return
 	}
+	audit_service.RecordTeamRepository(ctx, ctx.Doer, ctx.RemoteAddr(), team, ctx.Repo.Repository, true)
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_team_success"))
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
@@ -1013,6 +1121,7 @@ func DeleteTeam(ctx *context.Context) {
 		ctx.ServerError("team.RemoveRepositorys", err)
 		return
 	}
+	audit_service.RecordTeamRepository(ctx, ctx.Doer, ctx.RemoteAddr(), team, ctx.Repo.Repository, false)
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.remove_team_success"))
 	ctx.JSON(http.StatusOK, map[string]interface{}{
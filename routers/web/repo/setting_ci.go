@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"path"
+
+	ci_model "code.gitea.io/gitea/models/ci"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplSettingsCIRunners base.TplName = "repo/settings/ci"
+	tplAdminCIRunners    base.TplName = "admin/ci/runners"
+)
+
+// ciRunnersCtx distinguishes the runners of a repository from the instance wide runners
+type ciRunnersCtx struct {
+	RepoID   int64
+	Link     string
+	Template base.TplName
+}
+
+func getCIRunnersCtx(ctx *context.Context) *ciRunnersCtx {
+	if len(ctx.Repo.RepoLink) > 0 {
+		ctx.Data["PageIsSettingsCI"] = true
+		return &ciRunnersCtx{
+			RepoID:   ctx.Repo.Repository.ID,
+			Link:     path.Join(ctx.Repo.RepoLink, "settings/ci"),
+			Template: tplSettingsCIRunners,
+		}
+	}
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminCIRunners"] = true
+	return &ciRunnersCtx{
+		Link:     path.Join(setting.AppSubURL, "/admin/ci/runners"),
+		Template: tplAdminCIRunners,
+	}
+}
+
+// CIRunners renders the registration token and the registered runners
+func CIRunners(ctx *context.Context) {
+	rCtx := getCIRunnersCtx(ctx)
+	ctx.Data["Title"] = ctx.Tr("repo.settings.ci")
+	ctx.Data["BaseLink"] = rCtx.Link
+
+	token, err := ci_model.GetRunnerToken(ctx, rCtx.RepoID)
+	if err != nil && err != ci_model.ErrRunnerTokenNotExist {
+		ctx.ServerError("GetRunnerToken", err)
+		return
+	}
+	ctx.Data["RegistrationToken"] = token
+
+	runners, err := ci_model.GetRunnersByRepoID(ctx, rCtx.RepoID)
+	if err != nil {
+		ctx.ServerError("GetRunnersByRepoID", err)
+		return
+	}
+	ctx.Data["Runners"] = runners
+
+	ctx.HTML(http.StatusOK, rCtx.Template)
+}
+
+// ResetCIRunnerToken replaces the registration token, the registered runners keep working
+func ResetCIRunnerToken(ctx *context.Context) {
+	rCtx := getCIRunnersCtx(ctx)
+	token, err := ci_model.ResetRunnerToken(ctx, rCtx.RepoID)
+	if err != nil {
+		ctx.ServerError("ResetRunnerToken", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.ci.reset_token_success"))
+	ctx.Flash.Info(token.Token)
+	ctx.Redirect(rCtx.Link)
+}
+
+// DeleteCIRunner removes a runner
+func DeleteCIRunner(ctx *context.Context) {
+	rCtx := getCIRunnersCtx(ctx)
+	runner, err := ci_model.GetRunnerByID(ctx, ctx.FormInt64("id"))
+	if err != nil || runner.RepoID != rCtx.RepoID {
+		if err == nil || err == ci_model.ErrRunnerNotExist {
+			ctx.NotFound("GetRunnerByID", nil)
+		} else {
+			ctx.ServerError("GetRunnerByID", err)
+		}
+		return
+	}
+	if err := ci_model.DeleteRunner(ctx, runner); err != nil {
+		ctx.Flash.Error("DeleteRunner: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.ci.delete_runner_success"))
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": rCtx.Link,
+	})
+}
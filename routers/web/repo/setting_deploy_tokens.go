@@ -0,0 +1,148 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"path"
+	"time"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplSettingsDeployTokens    base.TplName = "repo/settings/deploy_tokens"
+	tplOrgSettingsDeployTokens base.TplName = "org/settings/deploy_tokens"
+)
+
+// deployTokensCtx distinguishes the deploy tokens of a repository from the deploy tokens of an organization
+type deployTokensCtx struct {
+	OwnerID  int64
+	RepoID   int64
+	Link     string
+	Template base.TplName
+	Scope    audit_model.Object
+}
+
+func getDeployTokensCtx(ctx *context.Context) *deployTokensCtx {
+	if len(ctx.Repo.RepoLink) > 0 {
+		ctx.Data["PageIsSettingsDeployTokens"] = true
+		return &deployTokensCtx{
+			RepoID:   ctx.Repo.Repository.ID,
+			Link:     path.Join(ctx.Repo.RepoLink, "settings/deploy_tokens"),
+			Template: tplSettingsDeployTokens,
+			Scope:    audit_service.Repository(ctx.Repo.Repository),
+		}
+	}
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsDeployTokens"] = true
+	return &deployTokensCtx{
+		OwnerID:  ctx.Org.Organization.ID,
+		Link:     path.Join(ctx.Org.OrgLink, "settings/deploy_tokens"),
+		Template: tplOrgSettingsDeployTokens,
+		Scope:    audit_service.User(ctx.Org.Organization.AsUser()),
+	}
+}
+
+// DeployTokens renders the deploy tokens, their values are only shown once after their creation
+func DeployTokens(ctx *context.Context) {
+	dCtx := getDeployTokensCtx(ctx)
+	ctx.Data["Title"] = ctx.Tr("repo.settings.deploy_tokens")
+	ctx.Data["BaseLink"] = dCtx.Link
+	ctx.Data["IsOrgDeployTokens"] = dCtx.OwnerID > 0
+
+	tokens, err := auth_model.FindDeployTokens(ctx, auth_model.FindDeployTokensOptions{OwnerID: dCtx.OwnerID, RepoID: dCtx.RepoID})
+	if err != nil {
+		ctx.ServerError("FindDeployTokens", err)
+		return
+	}
+	ctx.Data["DeployTokens"] = tokens
+
+	ctx.HTML(http.StatusOK, dCtx.Template)
+}
+
+// DeployTokensPost adds a deploy token
+func DeployTokensPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AddDeployTokenForm)
+	dCtx := getDeployTokensCtx(ctx)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(dCtx.Link)
+		return
+	}
+
+	t := &auth_model.DeployToken{
+		OwnerID: dCtx.OwnerID,
+		RepoID:  dCtx.RepoID,
+		Name:    form.Name,
+		Mode:    perm.AccessModeRead,
+	}
+	if form.IsWritable {
+		t.Mode = perm.AccessModeWrite
+	}
+	if form.ExpiresAt != "" {
+		expiresAt, err := time.ParseInLocation("2006-01-02", form.ExpiresAt, setting.DefaultUILocation)
+		if err != nil {
+			ctx.Flash.Error(ctx.Tr("repo.settings.deploy_tokens.expiry_invalid"))
+			ctx.Redirect(dCtx.Link)
+			return
+		}
+		t.ExpiresUnix = timeutil.TimeStamp(expiresAt.Unix())
+	}
+
+	if err := auth_model.NewDeployToken(ctx, t); err != nil {
+		switch {
+		case auth_model.IsErrDeployTokenNameAlreadyUsed(err):
+			ctx.Flash.Error(ctx.Tr("repo.settings.deploy_tokens.name_used", form.Name))
+		case auth_model.IsErrDeployTokenExpiryInPast(err):
+			ctx.Flash.Error(ctx.Tr("repo.settings.deploy_tokens.expiry_invalid"))
+		default:
+			ctx.ServerError("NewDeployToken", err)
+			return
+		}
+		ctx.Redirect(dCtx.Link)
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionDeployTokenCreate, ctx.Doer, ctx.RemoteAddr(), dCtx.Scope, audit_service.DeployToken(t), nil, nil)
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.deploy_tokens.add_success", t.Name))
+	ctx.Flash.Info(t.Token)
+	ctx.Redirect(dCtx.Link)
+}
+
+// DeleteDeployToken removes a deploy token
+func DeleteDeployToken(ctx *context.Context) {
+	dCtx := getDeployTokensCtx(ctx)
+	t, err := auth_model.GetDeployTokenByID(ctx, ctx.FormInt64("id"))
+	if err == nil && (t.OwnerID != dCtx.OwnerID || t.RepoID != dCtx.RepoID) {
+		err = auth_model.ErrDeployTokenNotExist{ID: t.ID}
+	}
+	if err == nil {
+		err = auth_model.DeleteDeployToken(ctx, dCtx.OwnerID, dCtx.RepoID, t.ID)
+	}
+	if err != nil {
+		if auth_model.IsErrDeployTokenNotExist(err) {
+			ctx.NotFound("DeleteDeployToken", nil)
+		} else {
+			ctx.ServerError("DeleteDeployToken", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, audit_model.ActionDeployTokenDelete, ctx.Doer, ctx.RemoteAddr(), dCtx.Scope, audit_service.DeployToken(t), nil, nil)
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.deploy_tokens.delete_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": dCtx.Link,
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	git_model "code.gitea.io/gitea/models/git"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
@@ -22,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
@@ -192,6 +194,13 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		}
 	}
 
+	action := audit_model.ActionBranchProtectionCreate
+	var before interface{}
+	if protectBranch != nil {
+		action = audit_model.ActionBranchProtectionUpdate
+		before = audit_service.BranchProtectionStateOf(protectBranch)
+	}
+
 	if f.Protected {
 		if protectBranch == nil {
 			// No options found, create defaults.
@@ -204,6 +213,11 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("repo.settings.protected_branch_required_approvals_min"))
 			ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, util.PathEscapeSegments(branch)))
 		}
+		if _, err := git_model.ParseMergeWindows(f.MergeWindows); err != nil {
+			ctx.Flash.Error(ctx.Tr("repo.settings.protect_merge_windows_invalid", err.Error()))
+			ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, util.PathEscapeSegments(branch)))
+			return
+		}
 
 		var whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams, approvalsWhitelistUsers, approvalsWhitelistTeams []int64
 		switch f.EnablePush {
@@ -258,8 +272,11 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		protectBranch.BlockOnOfficialReviewRequests = f.BlockOnOfficialReviewRequests
 		protectBranch.DismissStaleApprovals = f.DismissStaleApprovals
 		protectBranch.RequireSignedCommits = f.RequireSignedCommits
+		protectBranch.RequireCodeOwnerApproval = f.RequireCodeOwnerApproval
+		protectBranch.EnableMergeQueue = f.EnableMergeQueue
 		protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
 		protectBranch.UnprotectedFilePatterns = f.UnprotectedFilePatterns
+		protectBranch.MergeWindows = f.MergeWindows
 		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
 
 		err = git_model.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
@@ -274,6 +291,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.ServerError("UpdateProtectBranch", err)
 			return
 		}
+		audit_service.Record(ctx, action, ctx.Doer, ctx.RemoteAddr(), audit_service.Repository(ctx.Repo.Repository), audit_service.BranchProtection(protectBranch), before, audit_service.BranchProtectionStateOf(protectBranch))
 		if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 			ctx.ServerError("CheckPrsForBaseBranch", err)
 			return
@@ -286,6 +304,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			audit_service.Record(ctx, audit_model.ActionBranchProtectionDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.Repository(ctx.Repo.Repository), audit_service.BranchProtection(protectBranch), before, nil)
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
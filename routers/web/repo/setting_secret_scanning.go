@@ -0,0 +1,108 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/db"
+	secretscan_model "code.gitea.io/gitea/models/secretscan"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	secretscan_module "code.gitea.io/gitea/modules/secretscan"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplSettingsSecretScanning base.TplName = "repo/settings/secret_scanning"
+
+// SecretScanningAlerts renders the secrets found in the files of the repository
+func SecretScanningAlerts(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.secret_scanning")
+	ctx.Data["PageIsSettingsSecretScanning"] = true
+	ctx.Data["AutoRevokeTokens"] = setting.SecretScanning.AutoRevokeTokens
+
+	status := secretscan_model.ParseAlertStatus(ctx.FormString("state"))
+	if status == 0 {
+		status = secretscan_model.AlertStatusOpen
+	}
+	ctx.Data["State"] = status.String()
+
+	statuses := []secretscan_model.AlertStatus{secretscan_model.AlertStatusOpen, secretscan_model.AlertStatusResolved, secretscan_model.AlertStatusDismissed}
+	counts := make(map[string]int64, len(statuses))
+	for _, s := range statuses {
+		count, err := secretscan_model.CountAlerts(ctx, secretscan_model.FindAlertsOptions{RepoID: ctx.Repo.Repository.ID, Status: s})
+		if err != nil {
+			ctx.ServerError("CountAlerts", err)
+			return
+		}
+		counts[s.String()] = count
+	}
+	ctx.Data["Statuses"] = statuses
+	ctx.Data["Counts"] = counts
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	alerts, total, err := secretscan_model.FindAlerts(ctx, secretscan_model.FindAlertsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.ExplorePagingNum,
+		},
+		RepoID: ctx.Repo.Repository.ID,
+		Status: status,
+	})
+	if err != nil {
+		ctx.ServerError("FindAlerts", err)
+		return
+	}
+	for _, a := range alerts {
+		if err := a.LoadResolver(ctx); err != nil {
+			ctx.ServerError("LoadResolver", err)
+			return
+		}
+	}
+	ctx.Data["Alerts"] = alerts
+
+	ruleNames := make(map[string]string, len(secretscan_module.Rules))
+	for _, rule := range secretscan_module.Rules {
+		ruleNames[rule.ID] = rule.Name
+	}
+	ctx.Data["RuleNames"] = ruleNames
+
+	pager := context.NewPagination(int(total), setting.UI.ExplorePagingNum, page, 5)
+	pager.AddParam(ctx, "state", "State")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplSettingsSecretScanning)
+}
+
+// SecretScanningAlertStatusPost resolves, dismisses or reopens an alert
+func SecretScanningAlertStatusPost(ctx *context.Context) {
+	a, err := secretscan_model.GetAlertByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if secretscan_model.IsErrAlertNotExist(err) {
+			ctx.NotFound("GetAlertByID", err)
+		} else {
+			ctx.ServerError("GetAlertByID", err)
+		}
+		return
+	}
+	status := secretscan_model.ParseAlertStatus(ctx.FormString("status"))
+	if status == 0 {
+		ctx.Error(http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	previous := a.Status
+	if status != previous {
+		if err := secretscan_model.UpdateAlertStatus(ctx, a, status, ctx.Doer); err != nil {
+			ctx.ServerError("UpdateAlertStatus", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.settings.secret_scanning." + status.String() + "_success"))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/secret_scanning?state=" + previous.String())
+}
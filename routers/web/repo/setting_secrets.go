@@ -0,0 +1,115 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"path"
+
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplSettingsSecrets    base.TplName = "repo/settings/secrets"
+	tplOrgSettingsSecrets base.TplName = "org/settings/secrets"
+)
+
+// secretsCtx distinguishes the secrets of a repository from the secrets of an organization
+type secretsCtx struct {
+	OwnerID  int64
+	RepoID   int64
+	Link     string
+	Template base.TplName
+}
+
+func getSecretsCtx(ctx *context.Context) *secretsCtx {
+	if len(ctx.Repo.RepoLink) > 0 {
+		ctx.Data["PageIsSettingsSecrets"] = true
+		return &secretsCtx{
+			RepoID:   ctx.Repo.Repository.ID,
+			Link:     path.Join(ctx.Repo.RepoLink, "settings/secrets"),
+			Template: tplSettingsSecrets,
+		}
+	}
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsSecrets"] = true
+	return &secretsCtx{
+		OwnerID:  ctx.Org.Organization.ID,
+		Link:     path.Join(ctx.Org.OrgLink, "settings/secrets"),
+		Template: tplOrgSettingsSecrets,
+	}
+}
+
+func loadSecrets(ctx *context.Context, sCtx *secretsCtx) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.secrets")
+	ctx.Data["BaseLink"] = sCtx.Link
+
+	secrets, err := secret_model.FindSecrets(ctx, secret_model.FindSecretsOptions{OwnerID: sCtx.OwnerID, RepoID: sCtx.RepoID})
+	if err != nil {
+		ctx.ServerError("FindSecrets", err)
+		return
+	}
+	ctx.Data["Secrets"] = secrets
+}
+
+// Secrets renders the names of the secrets, their values are never shown
+func Secrets(ctx *context.Context) {
+	sCtx := getSecretsCtx(ctx)
+	loadSecrets(ctx, sCtx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, sCtx.Template)
+}
+
+// SecretsPost adds a secret or replaces the value of an existing secret
+func SecretsPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AddSecretForm)
+	sCtx := getSecretsCtx(ctx)
+	loadSecrets(ctx, sCtx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, sCtx.Template)
+		return
+	}
+
+	s, err := secret_model.SetSecret(ctx, sCtx.OwnerID, sCtx.RepoID, form.Name, form.Data)
+	if err != nil {
+		if secret_model.IsErrInvalidSecret(err) {
+			ctx.Data["Err_Name"] = true
+			// the value is not sent back to the browser
+			ctx.RenderWithErr(ctx.Tr("repo.settings.secrets.invalid", err.(secret_model.ErrInvalidSecret).Reason), sCtx.Template, &forms.AddSecretForm{Name: form.Name})
+		} else {
+			ctx.ServerError("SetSecret", err)
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.secrets.add_success", s.Name))
+	ctx.Redirect(sCtx.Link)
+}
+
+// DeleteSecret removes a secret
+func DeleteSecret(ctx *context.Context) {
+	sCtx := getSecretsCtx(ctx)
+	if err := secret_model.DeleteSecret(ctx, sCtx.OwnerID, sCtx.RepoID, ctx.FormString("id")); err != nil {
+		if secret_model.IsErrSecretNotExist(err) {
+			ctx.NotFound("DeleteSecret", nil)
+		} else {
+			ctx.ServerError("DeleteSecret", err)
+		}
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.secrets.delete_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": sCtx.Link,
+	})
+}
@@ -0,0 +1,326 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	tplTimeReport    base.TplName = "repo/time_report"
+	tplOrgTimeReport base.TplName = "org/time_report"
+
+	timeReportDateLayout = "2006-01-02"
+)
+
+// TimeReport renders the time tracked in the issues of the repository
+func TimeReport(ctx *context.Context) {
+	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
+		ctx.NotFound("TimeReport", nil)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.time_report")
+	ctx.Data["PageIsIssueList"] = true
+	ctx.Data["PageIsTimeReport"] = true
+
+	milestones, _, err := issues_model.GetMilestones(issues_model.GetMilestonesOption{
+		ListOptions: db.ListOptions{PageSize: setting.UI.IssuePagingNum * 10},
+		RepoID:      ctx.Repo.Repository.ID,
+		State:       api.StateAll,
+	})
+	if err != nil {
+		ctx.ServerError("GetMilestones", err)
+		return
+	}
+	if err := milestones.LoadTotalTrackedTimes(); err != nil {
+		ctx.ServerError("LoadTotalTrackedTimes", err)
+		return
+	}
+	ctx.Data["Milestones"] = milestones
+
+	labels, err := issues_model.GetLabelsByRepoID(ctx, ctx.Repo.Repository.ID, "", db.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetLabelsByRepoID", err)
+		return
+	}
+	if ctx.Repo.Owner.IsOrganization() {
+		orgLabels, err := issues_model.GetLabelsByOrgID(ctx, ctx.Repo.Owner.ID, "", db.ListOptions{})
+		if err != nil {
+			ctx.ServerError("GetLabelsByOrgID", err)
+			return
+		}
+		labels = append(labels, orgLabels...)
+	}
+	ctx.Data["Labels"] = labels
+
+	// like in the API, only the writers of the issues see the times of the other users
+	canSeeAllUsers := ctx.Doer.IsAdmin || ctx.Repo.CanWrite(unit.TypeIssues)
+	opts := parseTimeReportOptions(ctx, []int64{ctx.Repo.Repository.ID}, canSeeAllUsers)
+	if ctx.Written() {
+		return
+	}
+	for _, m := range milestones {
+		if m.ID == opts.MilestoneID {
+			ctx.Data["Milestone"] = m
+		}
+	}
+
+	renderTimeReport(ctx, opts, ctx.Repo.RepoLink+"/time-report", tplTimeReport)
+}
+
+// OrgTimeReport renders the time tracked in the repositories of the organization whose issues the user can write
+func OrgTimeReport(ctx *context.Context) {
+	if !setting.Service.EnableTimetracking {
+		ctx.NotFound("OrgTimeReport", nil)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.time_report")
+	ctx.Data["PageIsTimeReport"] = true
+
+	org := ctx.Org.Organization
+	env, err := organization.AccessibleReposEnv(ctx, org, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("AccessibleReposEnv", err)
+		return
+	}
+	count, err := env.CountRepos()
+	if err != nil {
+		ctx.ServerError("CountRepos", err)
+		return
+	}
+	repos, err := env.Repos(1, int(count))
+	if err != nil {
+		ctx.ServerError("Repos", err)
+		return
+	}
+
+	reported := make([]*repo_model.Repository, 0, len(repos))
+	repoIDs := make([]int64, 0, len(repos))
+	for _, repo := range repos {
+		if !repo.IsTimetrackerEnabledCtx(ctx) {
+			continue
+		}
+		perm, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
+		if err != nil {
+			ctx.ServerError("GetUserRepoPermission", err)
+			return
+		}
+		if !ctx.Doer.IsAdmin && !perm.CanWrite(unit.TypeIssues) {
+			continue
+		}
+		reported = append(reported, repo)
+		repoIDs = append(repoIDs, repo.ID)
+	}
+	ctx.Data["Repos"] = reported
+
+	labels, err := issues_model.GetLabelsByOrgID(ctx, org.ID, "", db.ListOptions{})
+	if err != nil {
+		ctx.ServerError("GetLabelsByOrgID", err)
+		return
+	}
+	ctx.Data["Labels"] = labels
+
+	opts := parseTimeReportOptions(ctx, repoIDs, true)
+	if ctx.Written() {
+		return
+	}
+	renderTimeReport(ctx, opts, setting.AppSubURL+"/org/"+url.PathEscape(org.Name)+"/time-report", tplOrgTimeReport)
+}
+
+// parseTimeReportOptions reads the filters of the time report from the query
+func parseTimeReportOptions(ctx *context.Context, repoIDs []int64, canSeeAllUsers bool) *issues_model.TimeReportOptions {
+	opts := &issues_model.TimeReportOptions{
+		RepoIDs:     repoIDs,
+		MilestoneID: ctx.FormInt64("milestone"),
+		GroupBy:     issues_model.TimeReportGroup(ctx.FormString("group")),
+	}
+	if !opts.GroupBy.IsValid() {
+		opts.GroupBy = issues_model.TimeReportGroupUser
+	}
+
+	if labels := ctx.FormString("labels"); labels != "" {
+		labelIDs, err := base.StringsToInt64s(strings.Split(labels, ","))
+		if err != nil {
+			ctx.Error(http.StatusBadRequest, "labels", err.Error())
+			return nil
+		}
+		opts.LabelIDs = labelIDs
+	}
+
+	if !canSeeAllUsers {
+		opts.UserID = ctx.Doer.ID
+	} else if name := ctx.FormTrim("user"); name != "" {
+		u, err := user_model.GetUserByName(ctx, name)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.NotFound("GetUserByName", err)
+			} else {
+				ctx.ServerError("GetUserByName", err)
+			}
+			return nil
+		}
+		opts.UserID = u.ID
+	}
+
+	since, until := ctx.FormTrim("since"), ctx.FormTrim("until")
+	if since != "" {
+		t, err := time.ParseInLocation(timeReportDateLayout, since, setting.DefaultUILocation)
+		if err != nil {
+			ctx.Error(http.StatusBadRequest, "since", err.Error())
+			return nil
+		}
+		opts.SinceUnix = t.Unix()
+	}
+	if until != "" {
+		t, err := time.ParseInLocation(timeReportDateLayout, until, setting.DefaultUILocation)
+		if err != nil {
+			ctx.Error(http.StatusBadRequest, "until", err.Error())
+			return nil
+		}
+		// the whole day is included
+		opts.UntilUnix = t.AddDate(0, 0, 1).Unix() - 1
+	}
+
+	ctx.Data["CanSeeAllUsers"] = canSeeAllUsers
+	ctx.Data["ReportUser"] = ctx.FormTrim("user")
+	ctx.Data["Since"] = since
+	ctx.Data["Until"] = until
+	ctx.Data["MilestoneID"] = opts.MilestoneID
+	ctx.Data["LabelsQuery"] = ctx.FormString("labels")
+	ctx.Data["GroupBy"] = string(opts.GroupBy)
+	ctx.Data["TimeReportGroups"] = issues_model.TimeReportGroups
+	return opts
+}
+
+func renderTimeReport(ctx *context.Context, opts *issues_model.TimeReportOptions, link string, tpl base.TplName) {
+	report, err := issues_model.GetTimeReport(ctx, opts)
+	if err != nil {
+		ctx.ServerError("GetTimeReport", err)
+		return
+	}
+
+	switch ctx.FormString("format") {
+	case "csv":
+		ctx.SetServeHeaders("time-report.csv")
+		ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		if err := writeTimeReportCSV(ctx.Resp, report); err != nil {
+			ctx.ServerError("writeTimeReportCSV", err)
+		}
+		return
+	case "json":
+		ctx.JSON(http.StatusOK, toTimeReportJSON(report))
+		return
+	}
+
+	query := ctx.Req.URL.Query()
+	query.Del("format")
+	ctx.Data["Link"] = link
+	ctx.Data["Query"] = query.Encode()
+	ctx.Data["Report"] = report
+	ctx.HTML(http.StatusOK, tpl)
+}
+
+// timeReportJSONRow is a row of the JSON export of a time report
+type timeReportJSONRow struct {
+	Key          string `json:"key"`
+	User         string `json:"user,omitempty"`
+	Repo         string `json:"repo,omitempty"`
+	Index        int64  `json:"index,omitempty"`
+	Title        string `json:"title,omitempty"`
+	TimeEstimate int64  `json:"time_estimate,omitempty"`
+	Seconds      int64  `json:"seconds"`
+	Count        int    `json:"count"`
+}
+
+// timeReportJSON is the JSON export of a time report
+type timeReportJSON struct {
+	GroupBy      string               `json:"group_by"`
+	TotalSeconds int64                `json:"total_seconds"`
+	Count        int                  `json:"count"`
+	Rows         []*timeReportJSONRow `json:"rows"`
+}
+
+func toTimeReportJSON(report *issues_model.TimeReport) *timeReportJSON {
+	result := &timeReportJSON{
+		GroupBy:      string(report.GroupBy),
+		TotalSeconds: report.TotalSeconds,
+		Count:        report.Count,
+		Rows:         make([]*timeReportJSONRow, 0, len(report.Rows)),
+	}
+	for _, row := range report.Rows {
+		r := &timeReportJSONRow{
+			Key:     row.Key,
+			Seconds: row.Seconds,
+			Count:   row.Count,
+		}
+		if row.User != nil {
+			r.User = row.User.Name
+		}
+		if row.Issue != nil {
+			r.Repo = row.Issue.Repo.FullName()
+			r.Index = row.Issue.Index
+			r.Title = row.Issue.Title
+			r.TimeEstimate = row.Issue.TimeEstimate
+		}
+		result.Rows = append(result.Rows, r)
+	}
+	return result
+}
+
+func writeTimeReportCSV(w http.ResponseWriter, report *issues_model.TimeReport) error {
+	hours := func(seconds int64) string {
+		return fmt.Sprintf("%.2f", float64(seconds)/3600)
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{string(report.GroupBy)}
+	if report.GroupBy == issues_model.TimeReportGroupIssue {
+		header = append(header, "title", "estimate_hours")
+	}
+	header = append(header, "hours", "seconds", "entries")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		record := []string{row.Key}
+		if report.GroupBy == issues_model.TimeReportGroupIssue {
+			record = append(record, row.Issue.Title, hours(row.Issue.TimeEstimate))
+		}
+		record = append(record, hours(row.Seconds), strconv.FormatInt(row.Seconds, 10), strconv.Itoa(row.Count))
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	total := []string{"total"}
+	if report.GroupBy == issues_model.TimeReportGroupIssue {
+		total = append(total, "", "")
+	}
+	total = append(total, hours(report.TotalSeconds), strconv.FormatInt(report.TotalSeconds, 10), strconv.Itoa(report.Count))
+	if err := cw.Write(total); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
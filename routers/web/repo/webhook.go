@@ -13,6 +13,7 @@ import (
 	"path"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/perm"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
@@ -25,6 +26,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	webhook_service "code.gitea.io/gitea/services/webhook"
 )
@@ -190,6 +192,7 @@ type webhookCreationParams struct {
 	ContentType webhook.HookContentType
 	Secret      string
 	HTTPMethod  string
+	Headers     string
 	WebhookForm forms.WebhookForm
 	Type        string
 	Meta        interface{}
@@ -213,6 +216,11 @@ func createWebhook(ctx *context.Context, params webhookCreationParams) {
 		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
 		return
 	}
+	if _, err := webhook.ParseHookHeaders(params.Headers); err != nil {
+		ctx.Data["Err_Headers"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.webhook.invalid_headers", err), orCtx.NewTemplate, nil)
+		return
+	}
 
 	var meta []byte
 	if params.Meta != nil {
@@ -229,6 +237,7 @@ func createWebhook(ctx *context.Context, params webhookCreationParams) {
 		HTTPMethod:      params.HTTPMethod,
 		ContentType:     params.ContentType,
 		Secret:          params.Secret,
+		Headers:         params.Headers,
 		HookEvent:       ParseHookEvent(params.WebhookForm),
 		IsActive:        params.WebhookForm.Active,
 		Type:            params.Type,
@@ -243,6 +252,7 @@ func createWebhook(ctx *context.Context, params webhookCreationParams) {
 		ctx.ServerError("CreateWebhook", err)
 		return
 	}
+	audit_service.RecordWebhook(ctx, audit_model.ActionWebhookCreate, ctx.Doer, ctx.Req.RemoteAddr, w, nil, audit_service.WebhookStateOf(w))
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
@@ -262,6 +272,7 @@ func GiteaHooksNewPost(ctx *context.Context) {
 		ContentType: contentType,
 		Secret:      form.Secret,
 		HTTPMethod:  form.HTTPMethod,
+		Headers:     form.Headers,
 		WebhookForm: form.WebhookForm,
 		Type:        webhook.GITEA,
 	})
@@ -465,6 +476,19 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *webhook.Webhook) {
 	return orCtx, w
 }
 
+// updateWebhook saves the changed webhook and records the change in the audit log
+func updateWebhook(ctx *context.Context, w *webhook.Webhook) error {
+	old, err := webhook.GetWebhookByID(w.ID)
+	if err != nil {
+		return err
+	}
+	if err := webhook.UpdateWebhook(w); err != nil {
+		return err
+	}
+	audit_service.RecordWebhook(ctx, audit_model.ActionWebhookUpdate, ctx.Doer, ctx.Req.RemoteAddr, w, audit_service.WebhookStateOf(old), audit_service.WebhookStateOf(w))
+	return nil
+}
+
 // WebHooksEdit render editing web hook page
 func WebHooksEdit(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings.update_webhook")
@@ -503,16 +527,23 @@ func WebHooksEditPost(ctx *context.Context) {
 		contentType = webhook.ContentTypeForm
 	}
 
+	if _, err := webhook.ParseHookHeaders(form.Headers); err != nil {
+		ctx.Data["Err_Headers"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.webhook.invalid_headers", err), orCtx.NewTemplate, nil)
+		return
+	}
+
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
 	w.Secret = form.Secret
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	w.HTTPMethod = form.HTTPMethod
+	w.Headers = form.Headers
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("WebHooksEditPost", err)
 		return
 	}
@@ -552,7 +583,7 @@ func GogsHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("GogsHooksEditPost", err)
 		return
 	}
@@ -597,7 +628,7 @@ func SlackHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -640,7 +671,7 @@ func DiscordHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -673,7 +704,7 @@ func DingtalkHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -715,7 +746,7 @@ func TelegramHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -760,7 +791,7 @@ func MatrixHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -793,7 +824,7 @@ func MSTeamsHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -826,7 +857,7 @@ func FeishuHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -859,7 +890,7 @@ func WechatworkHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -903,7 +934,7 @@ func PackagistHooksEditPost(ctx *context.Context) {
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
-	} else if err := webhook.UpdateWebhook(w); err != nil {
+	} else if err := updateWebhook(ctx, w); err != nil {
 		ctx.ServerError("UpdateWebhook", err)
 		return
 	}
@@ -993,9 +1024,13 @@ func ReplayWebhook(ctx *context.Context) {
 
 // DeleteWebhook delete a webhook
 func DeleteWebhook(ctx *context.Context) {
-	if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.FormInt64("id")); err != nil {
+	w, err := webhook.GetWebhookByRepoID(ctx.Repo.Repository.ID, ctx.FormInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetWebhookByRepoID: " + err.Error())
+	} else if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, w.ID); err != nil {
 		ctx.Flash.Error("DeleteWebhookByRepoID: " + err.Error())
 	} else {
+		audit_service.RecordWebhook(ctx, audit_model.ActionWebhookDelete, ctx.Doer, ctx.Req.RemoteAddr, w, audit_service.WebhookStateOf(w), nil)
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
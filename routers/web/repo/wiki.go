@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -22,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/markdown"
@@ -31,6 +33,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/gitdiff"
 	wiki_service "code.gitea.io/gitea/services/wiki"
 )
 
@@ -40,6 +43,9 @@ const (
 	tplWikiRevision base.TplName = "repo/wiki/revision"
 	tplWikiNew      base.TplName = "repo/wiki/new"
 	tplWikiPages    base.TplName = "repo/wiki/pages"
+	tplWikiSearch   base.TplName = "repo/wiki/search"
+	tplWikiCompare  base.TplName = "repo/wiki/compare"
+	tplWikiBroken   base.TplName = "repo/wiki/broken_links"
 )
 
 // MustEnableWiki check if wiki is enabled, if external then redirect
@@ -73,6 +79,19 @@ type PageMeta struct {
 	UpdatedUnix timeutil.TimeStamp
 }
 
+// PageBrokenLinks the links of a wiki page to pages which don't exist
+type PageBrokenLinks struct {
+	PageMeta
+	Links []string
+}
+
+// WikiSearchResult a wiki page matching a search
+type WikiSearchResult struct {
+	*code_indexer.Result
+	Name   string
+	SubURL string
+}
+
 // findEntryForFile finds the tree entry for a target filepath.
 func findEntryForFile(commit *git.Commit, target string) (*git.TreeEntry, error) {
 	entry, err := commit.GetTreeEntryByPath(target)
@@ -133,10 +152,33 @@ func wikiContentsByName(ctx *context.Context, commit *git.Commit, wikiName strin
 	} else if entry == nil {
 		return nil, nil, "", true
 	}
-	return wikiContentsByEntry(ctx, entry), entry, pageFilename, false
+	return wikiContentsByEntry(ctx, entry), entry, wikiEntryPath(entry, pageFilename), false
+}
+
+// wikiEntryPath returns the path of the file of the entry found by findEntryForFile,
+// the entries found by the unescaped file name are named without the directories of the page
+func wikiEntryPath(entry *git.TreeEntry, pageFilename string) string {
+	if entry.Name() == pageFilename {
+		return pageFilename
+	}
+	unescaped, _ := url.QueryUnescape(pageFilename)
+	return unescaped
 }
 
-func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
+// specialPageContents returns the contents and the name of the nearest sidebar or footer of the page,
+// nil if there is none
+func specialPageContents(ctx *context.Context, commit *git.Commit, pages *wiki_service.PageList, pageName, special string) ([]byte, string) {
+	for _, name := range wiki_service.SpecialPageCandidates(pageName, special) {
+		if !pages.HasPage(name) {
+			continue
+		}
+		data, _, _, _ := wikiContentsByName(ctx, commit, name)
+		return data, name
+	}
+	return nil, ""
+}
+
+func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry, string) {
 	wikiRepo, commit, err := findWikiRepoCommit(ctx)
 	if err != nil {
 		if wikiRepo != nil {
@@ -145,42 +187,28 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 		if !git.IsErrNotExist(err) {
 			ctx.ServerError("GetBranchCommit", err)
 		}
-		return nil, nil
+		return nil, nil, ""
 	}
 
 	// Get page list.
-	entries, err := commit.ListEntries()
+	pageList, err := wiki_service.ListPages(commit)
 	if err != nil {
 		if wikiRepo != nil {
 			wikiRepo.Close()
 		}
-		ctx.ServerError("ListEntries", err)
-		return nil, nil
+		ctx.ServerError("ListPages", err)
+		return nil, nil, ""
 	}
-	pages := make([]PageMeta, 0, len(entries))
-	for _, entry := range entries {
-		if !entry.IsRegular() {
-			continue
-		}
-		wikiName, err := wiki_service.FilenameToName(entry.Name())
-		if err != nil {
-			if models.IsErrWikiInvalidFileName(err) {
-				continue
-			}
-			if wikiRepo != nil {
-				wikiRepo.Close()
-			}
-			ctx.ServerError("WikiFilenameToName", err)
-			return nil, nil
-		} else if wikiName == "_Sidebar" || wikiName == "_Footer" {
-			continue
-		}
+	names := pageList.Names()
+	pages := make([]PageMeta, 0, len(names))
+	for _, wikiName := range names {
 		pages = append(pages, PageMeta{
 			Name:   wikiName,
 			SubURL: wiki_service.NameToSubURL(wikiName),
 		})
 	}
 	ctx.Data["Pages"] = pages
+	ctx.Data["PageTree"] = pageList.Tree()
 
 	// get requested pagename
 	pageName := wiki_service.NormalizeWikiName(ctx.Params("*"))
@@ -191,9 +219,11 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 	ctx.Data["old_title"] = pageName
 	ctx.Data["Title"] = pageName
 	ctx.Data["title"] = pageName
+	ctx.Data["Breadcrumbs"] = pageList.Breadcrumbs(pageName)
+	ctx.Data["PageBaseName"] = path.Base(pageName)
 
-	isSideBar := pageName == "_Sidebar"
-	isFooter := pageName == "_Footer"
+	isSideBar := path.Base(pageName) == wiki_service.SidebarPageName
+	isFooter := path.Base(pageName) == wiki_service.FooterPageName
 
 	// lookup filename in wiki - get filecontent, gitTree entry , real filename
 	data, entry, pageFilename, noEntry := wikiContentsByName(ctx, commit, pageName)
@@ -204,17 +234,19 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 		if wikiRepo != nil {
 			wikiRepo.Close()
 		}
-		return nil, nil
+		return nil, nil, ""
 	}
 
 	var sidebarContent []byte
 	if !isSideBar {
-		sidebarContent, _, _, _ = wikiContentsByName(ctx, commit, "_Sidebar")
+		var sidebarName string
+		sidebarContent, sidebarName = specialPageContents(ctx, commit, pageList, pageName, wiki_service.SidebarPageName)
+		ctx.Data["sidebarURL"] = wiki_service.NameToSubURL(sidebarName)
 		if ctx.Written() {
 			if wikiRepo != nil {
 				wikiRepo.Close()
 			}
-			return nil, nil
+			return nil, nil, ""
 		}
 	} else {
 		sidebarContent = data
@@ -222,12 +254,14 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 
 	var footerContent []byte
 	if !isFooter {
-		footerContent, _, _, _ = wikiContentsByName(ctx, commit, "_Footer")
+		var footerName string
+		footerContent, footerName = specialPageContents(ctx, commit, pageList, pageName, wiki_service.FooterPageName)
+		ctx.Data["footerURL"] = wiki_service.NameToSubURL(footerName)
 		if ctx.Written() {
 			if wikiRepo != nil {
 				wikiRepo.Close()
 			}
-			return nil, nil
+			return nil, nil, ""
 		}
 	} else {
 		footerContent = data
@@ -265,8 +299,10 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 			wikiRepo.Close()
 		}
 		ctx.ServerError("Render", err)
-		return nil, nil
+		return nil, nil, ""
 	}
+	// the links of the sidebar and the footer are reported on their own pages
+	ctx.Data["BrokenLinks"] = pageList.BrokenLinks(rctx.WikiLinks)
 
 	if !isSideBar {
 		buf.Reset()
@@ -276,7 +312,7 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 				wikiRepo.Close()
 			}
 			ctx.ServerError("Render", err)
-			return nil, nil
+			return nil, nil, ""
 		}
 		ctx.Data["sidebarPresent"] = sidebarContent != nil
 	} else {
@@ -291,7 +327,7 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 				wikiRepo.Close()
 			}
 			ctx.ServerError("Render", err)
-			return nil, nil
+			return nil, nil, ""
 		}
 		ctx.Data["footerPresent"] = footerContent != nil
 	} else {
@@ -304,10 +340,10 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 	commitsCount, _ := wikiRepo.FileCommitsCount("master", pageFilename)
 	ctx.Data["CommitCount"] = commitsCount
 
-	return wikiRepo, entry
+	return wikiRepo, entry, pageFilename
 }
 
-func renderRevisionPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
+func renderRevisionPage(ctx *context.Context) (*git.Repository, *git.TreeEntry, string) {
 	wikiRepo, commit, err := findWikiRepoCommit(ctx)
 	if err != nil {
 		if wikiRepo != nil {
@@ -316,7 +352,7 @@ func renderRevisionPage(ctx *context.Context) (*git.Repository, *git.TreeEntry)
 		if !git.IsErrNotExist(err) {
 			ctx.ServerError("GetBranchCommit", err)
 		}
-		return nil, nil
+		return nil, nil, ""
 	}
 
 	// get requested pagename
@@ -340,7 +376,7 @@ func renderRevisionPage(ctx *context.Context) (*git.Repository, *git.TreeEntry)
 		if wikiRepo != nil {
 			wikiRepo.Close()
 		}
-		return nil, nil
+		return nil, nil, ""
 	}
 
 	ctx.Data["content"] = string(data)
@@ -366,7 +402,7 @@ func renderRevisionPage(ctx *context.Context) (*git.Repository, *git.TreeEntry)
 			wikiRepo.Close()
 		}
 		ctx.ServerError("CommitsByFileAndRange", err)
-		return nil, nil
+		return nil, nil, ""
 	}
 	ctx.Data["Commits"] = git_model.ConvertFromGitCommit(commitsHistory, ctx.Repo.Repository)
 
@@ -374,7 +410,7 @@ func renderRevisionPage(ctx *context.Context) (*git.Repository, *git.TreeEntry)
 	pager.SetDefaultParams(ctx)
 	ctx.Data["Page"] = pager
 
-	return wikiRepo, entry
+	return wikiRepo, entry, pageFilename
 }
 
 func renderEditPage(ctx *context.Context) {
@@ -457,6 +493,17 @@ func Wiki(ctx *context.Context) {
 	case "_revision":
 		WikiRevision(ctx)
 		return
+	case "_search":
+		WikiSearch(ctx)
+		return
+	case "_broken_links":
+		WikiBrokenLinks(ctx)
+		return
+	case "_compare":
+		// the diff page has its own route to keep the diff options in the query
+		ctx.Redirect(fmt.Sprintf("%s/wiki/compare/%s...%s/%s", ctx.Repo.RepoLink,
+			url.PathEscape(ctx.FormString("before")), url.PathEscape(ctx.FormString("after")), wiki_service.NameToSubURL(wiki_service.NormalizeWikiName(ctx.Params("*")))))
+		return
 	case "_edit":
 		if !ctx.Repo.CanWrite(unit.TypeWiki) {
 			ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
@@ -479,7 +526,7 @@ func Wiki(ctx *context.Context) {
 		return
 	}
 
-	wikiRepo, entry := renderViewPage(ctx)
+	wikiRepo, entry, wikiPath := renderViewPage(ctx)
 	defer func() {
 		if wikiRepo != nil {
 			wikiRepo.Close()
@@ -494,7 +541,6 @@ func Wiki(ctx *context.Context) {
 		return
 	}
 
-	wikiPath := entry.Name()
 	if markup.Type(wikiPath) != markdown.MarkupName {
 		ext := strings.ToUpper(filepath.Ext(wikiPath))
 		ctx.Data["FormatWarning"] = fmt.Sprintf("%s rendering is not supported at the moment. Rendered as Markdown.", ext)
@@ -520,7 +566,7 @@ func WikiRevision(ctx *context.Context) {
 		return
 	}
 
-	wikiRepo, entry := renderRevisionPage(ctx)
+	wikiRepo, entry, wikiPath := renderRevisionPage(ctx)
 	defer func() {
 		if wikiRepo != nil {
 			wikiRepo.Close()
@@ -537,7 +583,6 @@ func WikiRevision(ctx *context.Context) {
 	}
 
 	// Get last change information.
-	wikiPath := entry.Name()
 	lastCommit, err := wikiRepo.GetCommitByPath(wikiPath)
 	if err != nil {
 		ctx.ServerError("GetCommitByPath", err)
@@ -548,6 +593,204 @@ func WikiRevision(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplWikiRevision)
 }
 
+// WikiBrokenLinks renders the links of the wiki pages to pages which don't exist
+func WikiBrokenLinks(ctx *context.Context) {
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.Redirect(ctx.Repo.RepoLink + "/wiki")
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.wiki.broken_links")
+
+	wikiRepo, commit, err := findWikiRepoCommit(ctx)
+	if err != nil {
+		if wikiRepo != nil {
+			wikiRepo.Close()
+		}
+		if !git.IsErrNotExist(err) {
+			ctx.ServerError("GetBranchCommit", err)
+		}
+		return
+	}
+	defer wikiRepo.Close()
+
+	pageList, err := wiki_service.ListPages(commit)
+	if err != nil {
+		ctx.ServerError("ListPages", err)
+		return
+	}
+
+	report := make([]*PageBrokenLinks, 0, 10)
+	for _, page := range pageList.Pages {
+		data := wikiContentsByEntry(ctx, page.Entry)
+		if ctx.Written() {
+			return
+		}
+		// the links are collected while rendering the page like it is viewed
+		rctx := &markup.RenderContext{
+			Ctx:       ctx,
+			URLPrefix: ctx.Repo.RepoLink,
+			Metas:     ctx.Repo.Repository.ComposeDocumentMetas(),
+			IsWiki:    true,
+		}
+		if err := markdown.Render(rctx, bytes.NewReader(data), io.Discard); err != nil {
+			ctx.ServerError("Render", err)
+			return
+		}
+		if links := pageList.BrokenLinks(rctx.WikiLinks); len(links) > 0 {
+			report = append(report, &PageBrokenLinks{
+				PageMeta: PageMeta{
+					Name:   page.Name,
+					SubURL: wiki_service.NameToSubURL(page.Name),
+				},
+				Links: links,
+			})
+		}
+	}
+	ctx.Data["BrokenLinks"] = report
+
+	ctx.HTML(http.StatusOK, tplWikiBroken)
+}
+
+// WikiSearch renders the wiki pages matching the keyword
+func WikiSearch(ctx *context.Context) {
+	if !setting.Indexer.WikiIndexerEnabled || !ctx.Repo.Repository.HasWiki() {
+		ctx.Redirect(ctx.Repo.RepoLink + "/wiki")
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.wiki.search")
+
+	keyword := ctx.FormTrim("q")
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	queryType := ctx.FormTrim("t")
+	isMatch := queryType == "match"
+
+	total, results, err := code_indexer.PerformWikiSearch(ctx, []int64{ctx.Repo.Repository.ID},
+		keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
+	if err != nil {
+		if code_indexer.IsWikiIndexerAvailable() {
+			ctx.ServerError("PerformWikiSearch", err)
+			return
+		}
+		ctx.Data["WikiIndexerUnavailable"] = true
+	} else {
+		ctx.Data["WikiIndexerUnavailable"] = !code_indexer.IsWikiIndexerAvailable()
+	}
+
+	searchResults := make([]*WikiSearchResult, 0, len(results))
+	for _, result := range results {
+		wikiName, err := wiki_service.FilenameToName(result.Filename)
+		if err != nil {
+			if models.IsErrWikiInvalidFileName(err) {
+				continue
+			}
+			ctx.ServerError("WikiFilenameToName", err)
+			return
+		}
+		searchResults = append(searchResults, &WikiSearchResult{
+			Result: result,
+			Name:   wikiName,
+			SubURL: wiki_service.NameToSubURL(wikiName),
+		})
+	}
+
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["queryType"] = queryType
+	ctx.Data["SearchResults"] = searchResults
+
+	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParamString("action", "_search")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplWikiSearch)
+}
+
+// WikiCompare renders the changes of a wiki page between two revisions
+func WikiCompare(ctx *context.Context) {
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.NotFound("WikiCompare", nil)
+		return
+	}
+
+	pageName := wiki_service.NormalizeWikiName(ctx.Params("*"))
+	if len(pageName) == 0 {
+		pageName = "Home"
+	}
+	ctx.Data["PageURL"] = wiki_service.NameToSubURL(pageName)
+	ctx.Data["Title"] = pageName
+	ctx.Data["title"] = pageName
+
+	wikiRepo, err := git.OpenRepository(ctx, ctx.Repo.Repository.WikiPath())
+	if err != nil {
+		ctx.ServerError("OpenRepository", err)
+		return
+	}
+	defer wikiRepo.Close()
+
+	getCommit := func(sha string) *git.Commit {
+		commit, err := wikiRepo.GetCommit(sha)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				ctx.NotFound("GetCommit", err)
+			} else {
+				ctx.ServerError("GetCommit", err)
+			}
+			return nil
+		}
+		return commit
+	}
+	beforeCommit := getCommit(ctx.Params("before"))
+	if ctx.Written() {
+		return
+	}
+	afterCommit := getCommit(ctx.Params("after"))
+	if ctx.Written() {
+		return
+	}
+
+	// the page may have been created or deleted between the revisions
+	pageFilename := wiki_service.NameToFilename(pageName)
+	var entry *git.TreeEntry
+	for _, commit := range []*git.Commit{afterCommit, beforeCommit} {
+		entry, err = findEntryForFile(commit, pageFilename)
+		if err != nil && !git.IsErrNotExist(err) {
+			ctx.ServerError("findEntryForFile", err)
+			return
+		}
+		if entry != nil {
+			break
+		}
+	}
+	if entry == nil {
+		ctx.NotFound("findEntryForFile", nil)
+		return
+	}
+
+	diff, err := gitdiff.GetDiff(wikiRepo, &gitdiff.DiffOptions{
+		BeforeCommitID:     beforeCommit.ID.String(),
+		AfterCommitID:      afterCommit.ID.String(),
+		MaxLines:           setting.Git.MaxGitDiffLines,
+		MaxLineCharacters:  setting.Git.MaxGitDiffLineCharacters,
+		MaxFiles:           setting.Git.MaxGitDiffFiles,
+		WhitespaceBehavior: gitdiff.GetWhitespaceFlag(ctx.Data["WhitespaceBehavior"].(string)),
+		DirectComparison:   true,
+	}, wikiEntryPath(entry, pageFilename))
+	if err != nil {
+		ctx.ServerError("GetDiff", err)
+		return
+	}
+
+	setCompareContext(ctx, beforeCommit, afterCommit, ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
+	ctx.Data["AfterCommitID"] = afterCommit.ID.String()
+	ctx.Data["Diff"] = diff
+	ctx.Data["DiffNotAvailable"] = diff.NumFiles == 0
+
+	ctx.HTML(http.StatusOK, tplWikiCompare)
+}
+
 // WikiPages render wiki pages list page
 func WikiPages(ctx *context.Context) {
 	if !ctx.Repo.Repository.HasWiki() {
@@ -571,36 +814,26 @@ func WikiPages(ctx *context.Context) {
 		}
 	}()
 
-	entries, err := commit.ListEntries()
+	pageList, err := wiki_service.ListPages(commit)
 	if err != nil {
-		ctx.ServerError("ListEntries", err)
+		ctx.ServerError("ListPages", err)
 		return
 	}
-	pages := make([]PageMeta, 0, len(entries))
-	for _, entry := range entries {
-		if !entry.IsRegular() {
-			continue
-		}
-		c, err := wikiRepo.GetCommitByPath(entry.Name())
+	pages := make([]PageMeta, 0, len(pageList.Pages))
+	for _, page := range pageList.Pages {
+		c, err := wikiRepo.GetCommitByPath(page.Entry.Name())
 		if err != nil {
 			ctx.ServerError("GetCommit", err)
 			return
 		}
-		wikiName, err := wiki_service.FilenameToName(entry.Name())
-		if err != nil {
-			if models.IsErrWikiInvalidFileName(err) {
-				continue
-			}
-			ctx.ServerError("WikiFilenameToName", err)
-			return
-		}
 		pages = append(pages, PageMeta{
-			Name:        wikiName,
-			SubURL:      wiki_service.NameToSubURL(wikiName),
+			Name:        page.Name,
+			SubURL:      wiki_service.NameToSubURL(page.Name),
 			UpdatedUnix: timeutil.TimeStamp(c.Author.When.Unix()),
 		})
 	}
 	ctx.Data["Pages"] = pages
+	ctx.Data["PageTree"] = pageList.Tree()
 
 	ctx.HTML(http.StatusOK, tplWikiPages)
 }
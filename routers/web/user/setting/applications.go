@@ -7,14 +7,20 @@ package setting
 
 import (
 	"net/http"
+	"time"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 const (
@@ -60,10 +66,46 @@ func ApplicationsPost(ctx *context.Context) {
 		return
 	}
 
-	if err := models.NewAccessToken(t); err != nil {
-		ctx.ServerError("NewAccessToken", err)
+	opts := &user_service.AccessTokenOptions{
+		Name:         form.Name,
+		Scopes:       form.Scopes,
+		Repository:   form.Repository,
+		Organization: form.Organization,
+	}
+	if form.ExpiresAt != "" {
+		opts.ExpiresAt, err = time.ParseInLocation("2006-01-02", form.ExpiresAt, setting.DefaultUILocation)
+		if err != nil {
+			ctx.Flash.Error(ctx.Tr("settings.access_token_expiry_invalid"))
+			ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+			return
+		}
+	}
+
+	t, err = user_service.NewAccessToken(ctx, ctx.Doer, opts)
+	if err != nil {
+		switch {
+		case err == user_service.ErrAccessTokenNoScope:
+			ctx.Flash.Error(ctx.Tr("settings.access_token_scope_required"))
+		case auth.IsErrInvalidAccessTokenScope(err):
+			ctx.Flash.Error(ctx.Tr("settings.access_token_scope_invalid", err.(auth.ErrInvalidAccessTokenScope).Scope))
+		case models.IsErrAccessTokenScopeNotRestrictable(err):
+			ctx.Flash.Error(ctx.Tr("settings.access_token_scope_not_restrictable", err.(models.ErrAccessTokenScopeNotRestrictable).Scope))
+		case err == user_service.ErrAccessTokenRestrictedTwice:
+			ctx.Flash.Error(ctx.Tr("settings.access_token_restricted_twice"))
+		case err == user_service.ErrAccessTokenExpiryInPast:
+			ctx.Flash.Error(ctx.Tr("settings.access_token_expiry_invalid"))
+		case repo_model.IsErrRepoNotExist(err):
+			ctx.Flash.Error(ctx.Tr("settings.access_token_repo_not_exist", form.Repository))
+		case organization.IsErrOrgNotExist(err):
+			ctx.Flash.Error(ctx.Tr("settings.access_token_org_not_exist", form.Organization))
+		default:
+			ctx.ServerError("NewAccessToken", err)
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
 		return
 	}
+	audit_service.Record(ctx, audit_model.ActionAccessTokenCreate, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.AccessToken(t), nil, nil)
 
 	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
 	ctx.Flash.Info(t.Token)
@@ -73,9 +115,14 @@ func ApplicationsPost(ctx *context.Context) {
 
 // DeleteApplication response for delete user access token
 func DeleteApplication(ctx *context.Context) {
-	if err := models.DeleteAccessTokenByID(ctx.FormInt64("id"), ctx.Doer.ID); err != nil {
+	t, err := models.GetAccessTokenByID(ctx.FormInt64("id"), ctx.Doer.ID)
+	if err == nil {
+		err = models.DeleteAccessTokenByID(t.ID, ctx.Doer.ID)
+	}
+	if err != nil {
 		ctx.Flash.Error("DeleteAccessTokenByID: " + err.Error())
 	} else {
+		audit_service.Record(ctx, audit_model.ActionAccessTokenDelete, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.AccessToken(t), nil, nil)
 		ctx.Flash.Success(ctx.Tr("settings.delete_token_success"))
 	}
 
@@ -90,7 +137,14 @@ func loadApplicationsData(ctx *context.Context) {
 		ctx.ServerError("ListAccessTokens", err)
 		return
 	}
+	for _, t := range tokens {
+		if err := t.LoadRestriction(ctx); err != nil {
+			ctx.ServerError("LoadRestriction", err)
+			return
+		}
+	}
 	ctx.Data["Tokens"] = tokens
+	ctx.Data["AccessTokenScopeCategories"] = auth.AllAccessTokenScopeCategories
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 	if setting.OAuth2.Enable {
 		ctx.Data["Applications"], err = auth.GetOAuth2ApplicationsByUserID(ctx, ctx.Doer.ID)
@@ -0,0 +1,128 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	app_model "code.gitea.io/gitea/models/app"
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	app_service "code.gitea.io/gitea/services/app"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplSettingsApps base.TplName = "user/settings/apps"
+)
+
+// Apps render the apps of the user
+func Apps(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.apps")
+	ctx.Data["PageIsSettingsApps"] = true
+
+	loadAppsData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsApps)
+}
+
+// AppsPost registers an app, its private key is only shown once
+func AppsPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewAppForm)
+	ctx.Data["Title"] = ctx.Tr("settings.apps")
+	ctx.Data["PageIsSettingsApps"] = true
+
+	loadAppsData(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsApps)
+		return
+	}
+
+	a, privateKey, err := app_service.CreateApp(ctx, ctx.Doer, &app_service.CreateAppOptions{
+		Name:          form.Name,
+		Description:   form.Description,
+		HomepageURL:   form.HomepageURL,
+		Permissions:   form.Permissions,
+		WebhookURL:    form.WebhookURL,
+		WebhookSecret: form.WebhookSecret,
+	})
+	if err != nil {
+		switch {
+		case app_model.IsErrAppAlreadyExist(err), user_model.IsErrUserAlreadyExist(err):
+			ctx.Data["Err_Name"] = true
+			ctx.RenderWithErr(ctx.Tr("settings.apps.name_used", form.Name), tplSettingsApps, form)
+		case db.IsErrNameReserved(err), db.IsErrNamePatternNotAllowed(err), db.IsErrNameCharsNotAllowed(err):
+			ctx.Data["Err_Name"] = true
+			ctx.RenderWithErr(ctx.Tr("settings.apps.name_invalid", form.Name), tplSettingsApps, form)
+		case err == app_service.ErrAppNoPermission:
+			ctx.RenderWithErr(ctx.Tr("settings.apps.no_permission"), tplSettingsApps, form)
+		case auth.IsErrInvalidAccessTokenScope(err):
+			ctx.RenderWithErr(ctx.Tr("settings.access_token_scope_invalid", err.(auth.ErrInvalidAccessTokenScope).Scope), tplSettingsApps, form)
+		case models.IsErrAccessTokenScopeNotRestrictable(err):
+			ctx.RenderWithErr(ctx.Tr("settings.access_token_scope_not_restrictable", err.(models.ErrAccessTokenScopeNotRestrictable).Scope), tplSettingsApps, form)
+		default:
+			ctx.ServerError("CreateApp", err)
+		}
+		return
+	}
+
+	// the page is rendered instead of redirecting to show the private key, which isn't stored
+	loadAppsData(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["CreatedApp"] = a
+	ctx.Data["PrivateKey"] = privateKey
+	ctx.Flash.Success(ctx.Tr("settings.apps.add_success", a.Name), true)
+	ctx.HTML(http.StatusOK, tplSettingsApps)
+}
+
+// DeleteApp removes an app with its installations and its bot user
+func DeleteApp(ctx *context.Context) {
+	a, err := app_model.GetAppByID(ctx, ctx.FormInt64("id"))
+	if err == nil && a.OwnerID != ctx.Doer.ID {
+		err = app_model.ErrAppNotExist{ID: a.ID}
+	}
+	if err == nil {
+		err = app_service.DeleteApp(ctx, a)
+	}
+	if err != nil {
+		if app_model.IsErrAppNotExist(err) {
+			ctx.NotFound("DeleteApp", nil)
+		} else {
+			ctx.ServerError("DeleteApp", err)
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.apps.delete_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/apps",
+	})
+}
+
+func loadAppsData(ctx *context.Context) {
+	apps, _, err := app_model.FindApps(ctx, app_model.FindAppsOptions{OwnerID: ctx.Doer.ID})
+	if err != nil {
+		ctx.ServerError("FindApps", err)
+		return
+	}
+	ctx.Data["Apps"] = apps
+	ctx.Data["AppPermissionCategories"] = app_model.PermissionCategories
+	ctx.Data["BotSuffix"] = app_service.BotName("")
+}
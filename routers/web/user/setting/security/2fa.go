@@ -13,11 +13,13 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 
 	"github.com/pquerna/otp"
@@ -78,6 +80,7 @@ func DisableTwoFactor(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to DeleteTwoFactorByID", err)
 		return
 	}
+	audit_service.Record(ctx, audit_model.ActionTwoFactorDisable, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.User(ctx.Doer), nil, nil)
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_disabled"))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
@@ -244,6 +247,7 @@ func EnrollTwoFactorPost(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to save two factor", err)
 		return
 	}
+	audit_service.Record(ctx, audit_model.ActionTwoFactorEnable, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.User(ctx.Doer), nil, nil)
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_enrolled", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
@@ -8,12 +8,14 @@ import (
 	"errors"
 	"net/http"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	wa "code.gitea.io/gitea/modules/auth/webauthn"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 
 	"github.com/duo-labs/webauthn/protocol"
@@ -98,11 +100,12 @@ func WebauthnRegisterPost(ctx *context.Context) {
 	}
 
 	// Create the credential
-	_, err = auth.CreateCredential(ctx.Doer.ID, name, cred)
+	dbCred, err = auth.CreateCredential(ctx.Doer.ID, name, cred)
 	if err != nil {
 		ctx.ServerError("CreateCredential", err)
 		return
 	}
+	audit_service.Record(ctx, audit_model.ActionWebAuthnAdd, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.WebAuthnCredential(dbCred), nil, nil)
 	_ = ctx.Session.Delete("webauthnName")
 
 	ctx.JSON(http.StatusCreated, cred)
@@ -111,10 +114,19 @@ func WebauthnRegisterPost(ctx *context.Context) {
 // WebauthnDelete deletes an security key by id
 func WebauthnDelete(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.WebauthnDeleteForm)
-	if _, err := auth.DeleteCredential(form.ID, ctx.Doer.ID); err != nil {
+	cred, err := auth.GetWebAuthnCredentialByID(form.ID)
+	if err != nil && !auth.IsErrWebAuthnCredentialNotExist(err) {
+		ctx.ServerError("GetWebAuthnCredentialByID", err)
+		return
+	}
+	had, err := auth.DeleteCredential(form.ID, ctx.Doer.ID)
+	if err != nil {
 		ctx.ServerError("GetWebAuthnCredentialByID", err)
 		return
 	}
+	if had {
+		audit_service.Record(ctx, audit_model.ActionWebAuthnRemove, ctx.Doer, ctx.RemoteAddr(), audit_service.User(ctx.Doer), audit_service.WebAuthnCredential(cred), nil, nil)
+	}
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"redirect": setting.AppSubURL + "/user/settings/security",
 	})
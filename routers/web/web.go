@@ -430,6 +430,9 @@ func RegisterRoutes(m *web.Route) {
 		m.Combo("/applications").Get(user_setting.Applications).
 			Post(bindIgnErr(forms.NewAccessTokenForm{}), user_setting.ApplicationsPost)
 		m.Post("/applications/delete", user_setting.DeleteApplication)
+		m.Combo("/apps").Get(user_setting.Apps).
+			Post(bindIgnErr(forms.NewAppForm{}), user_setting.AppsPost)
+		m.Post("/apps/delete", user_setting.DeleteApp)
 		m.Combo("/keys").Get(user_setting.Keys).
 			Post(bindIgnErr(forms.AddKeyForm{}), user_setting.KeysPost)
 		m.Post("/keys/delete", user_setting.DeleteKey)
@@ -518,6 +521,14 @@ func RegisterRoutes(m *web.Route) {
 			})
 		}
 
+		if setting.CI.Enabled {
+			m.Group("/ci/runners", func() {
+				m.Get("", repo.CIRunners)
+				m.Post("/reset_token", repo.ResetCIRunnerToken)
+				m.Post("/delete", repo.DeleteCIRunner)
+			})
+		}
+
 		m.Group("/hooks", func() {
 			m.Get("", admin.DefaultOrSystemWebhooks)
 			m.Post("/delete", admin.DeleteDefaultOrSystemWebhook)
@@ -566,6 +577,8 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/delete", admin.DeleteNotices)
 			m.Post("/empty", admin.EmptyNotices)
 		})
+
+		m.Get("/audit", admin.AuditEvents)
 	}, adminReq)
 	// ***** END: Admin *****
 
@@ -637,6 +650,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/milestones/{team}", reqMilestonesDashboardPageEnabled, user.Milestones)
 			m.Post("/members/action/{action}", org.MembersAction)
 			m.Get("/teams", org.Teams)
+			m.Get("/time-report", repo.OrgTimeReport)
 		}, context.OrgAssignment(true, false, true))
 
 		m.Group("/{org}", func() {
@@ -698,6 +712,30 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/initialize", bindIgnErr(forms.InitializeLabelsForm{}), org.InitializeLabels)
 				})
 
+				m.Group("/secrets", func() {
+					m.Get("", repo.Secrets)
+					m.Post("", bindIgnErr(forms.AddSecretForm{}), repo.SecretsPost)
+					m.Post("/delete", repo.DeleteSecret)
+				})
+
+				m.Group("/deploy_tokens", func() {
+					m.Get("", repo.DeployTokens)
+					m.Post("", bindIgnErr(forms.AddDeployTokenForm{}), repo.DeployTokensPost)
+					m.Post("/delete", repo.DeleteDeployToken)
+				})
+
+				m.Group("/apps", func() {
+					m.Get("", org.Apps)
+					m.Post("", bindIgnErr(forms.InstallAppForm{}), org.InstallAppPost)
+					m.Post("/uninstall", org.UninstallApp)
+				})
+
+				m.Group("/push_policy", func() {
+					m.Get("", org.PushPolicy)
+					m.Post("", bindIgnErr(forms.PushPolicyForm{}), org.PushPolicyPost)
+					m.Post("/delete", org.DeletePushPolicy)
+				})
+
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 		}, context.OrgAssignment(true, true))
@@ -735,6 +773,34 @@ func RegisterRoutes(m *web.Route) {
 				})
 			}, ignSignIn, context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
 		}
+
+		m.Group("/projects", func() {
+			m.Get("", org.Projects)
+			m.Get("/{id}", org.ViewProject)
+			m.Group("", func() {
+				m.Get("/new", org.NewProject)
+				m.Post("/new", bindIgnErr(forms.CreateProjectForm{}), org.NewProjectPost)
+				m.Group("/{id}", func() {
+					m.Post("", bindIgnErr(forms.EditProjectBoardForm{}), org.AddBoardToProjectPost)
+					m.Post("/delete", org.DeleteProject)
+
+					m.Get("/edit", org.EditProject)
+					m.Post("/edit", bindIgnErr(forms.CreateProjectForm{}), org.EditProjectPost)
+					m.Post("/{action:open|close}", org.ChangeProjectStatus)
+
+					m.Post("/issues", bindIgnErr(forms.AddIssueToProjectForm{}), org.AddIssueToProjectPost)
+					m.Post("/issues/{issueID}/remove", org.RemoveIssueFromProject)
+
+					m.Group("/{boardID}", func() {
+						m.Put("", bindIgnErr(forms.EditProjectBoardForm{}), org.EditProjectBoard)
+						m.Delete("", org.DeleteProjectBoard)
+						m.Post("/default", org.SetDefaultProjectBoard)
+
+						m.Post("/move", org.MoveIssues)
+					})
+				})
+			}, reqSignIn, org.MustBeAbleToWriteProjects)
+		}, ignSignIn, org.MustEnableProjects)
 	}, context_service.UserAssignmentWeb())
 
 	// ***** Release Attachment Download without Signin
@@ -813,6 +879,33 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/packagist/{id}", bindIgnErr(forms.NewPackagistHookForm{}), repo.PackagistHooksEditPost)
 			}, webhooksEnabled)
 
+			m.Group("/secrets", func() {
+				m.Get("", repo.Secrets)
+				m.Post("", bindIgnErr(forms.AddSecretForm{}), repo.SecretsPost)
+				m.Post("/delete", repo.DeleteSecret)
+			})
+
+			m.Group("/deploy_tokens", func() {
+				m.Get("", repo.DeployTokens)
+				m.Post("", bindIgnErr(forms.AddDeployTokenForm{}), repo.DeployTokensPost)
+				m.Post("/delete", repo.DeleteDeployToken)
+			})
+
+			if setting.SecretScanning.Enabled {
+				m.Group("/secret_scanning", func() {
+					m.Get("", repo.SecretScanningAlerts)
+					m.Post("/{id}/status", repo.SecretScanningAlertStatusPost)
+				})
+			}
+
+			if setting.CI.Enabled {
+				m.Group("/ci", func() {
+					m.Get("", repo.CIRunners)
+					m.Post("/reset_token", repo.ResetCIRunnerToken)
+					m.Post("/delete", repo.DeleteCIRunner)
+				})
+			}
+
 			m.Group("/keys", func() {
 				m.Combo("").Get(repo.DeployKeys).
 					Post(bindIgnErr(forms.AddKeyForm{}), repo.DeployKeysPost)
@@ -890,6 +983,7 @@ func RegisterRoutes(m *web.Route) {
 				m.Group("/times", func() {
 					m.Post("/add", bindIgnErr(forms.AddTimeManuallyForm{}), repo.AddTimeManually)
 					m.Post("/{timeid}/delete", repo.DeleteTime)
+					m.Post("/estimate", bindIgnErr(forms.AddTimeManuallyForm{}), repo.UpdateTimeEstimate)
 					m.Group("/stopwatch", func() {
 						m.Post("/toggle", repo.IssueStopwatch)
 						m.Post("/cancel", repo.CancelStopwatch)
@@ -1040,6 +1134,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/labels", reqRepoIssuesOrPullsReader, repo.RetrieveLabels, repo.Labels)
 			m.Get("/milestones", reqRepoIssuesOrPullsReader, repo.Milestones)
 		}, context.RepoRef())
+		m.Get("/{type:issues|pulls}/{index}/events", routing.MarkLongPolling, repo.IssueEvents)
 
 		if setting.Packages.Enabled {
 			m.Get("/packages", repo.Packages)
@@ -1070,6 +1165,17 @@ func RegisterRoutes(m *web.Route) {
 			}, reqRepoProjectsWriter, context.RepoMustNotBeArchived())
 		}, reqRepoProjectsReader, repo.MustEnableProjects)
 
+		if setting.CI.Enabled {
+			m.Group("/ci", func() {
+				m.Get("", repo.CIRuns)
+				m.Group("/runs/{index}", func() {
+					m.Get("", repo.ViewCIRun)
+					m.Get("/jobs/{id}", repo.ViewCIRun)
+					m.Post("/cancel", reqSignIn, reqRepoCodeWriter, repo.CancelCIRun)
+				})
+			}, repo.MustBeNotEmpty, reqRepoCodeReader)
+		}
+
 		m.Group("/wiki", func() {
 			m.Combo("/").
 				Get(repo.Wiki).
@@ -1087,8 +1193,10 @@ func RegisterRoutes(m *web.Route) {
 					repo.WikiPost)
 			m.Get("/commit/{sha:[a-f0-9]{7,40}}", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.Diff)
 			m.Get("/commit/{sha:[a-f0-9]{7,40}}.{ext:patch|diff}", repo.RawDiff)
+			m.Get("/compare/{before:[a-f0-9]{7,40}}...{after:[a-f0-9]{7,40}}/*", repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.WikiCompare)
 		}, repo.MustEnableWiki, func(ctx *context.Context) {
 			ctx.Data["PageIsWiki"] = true
+			ctx.Data["WikiIndexerEnabled"] = setting.Indexer.WikiIndexerEnabled
 			ctx.Data["CloneButtonOriginLink"] = ctx.Repo.Repository.WikiCloneLink()
 		})
 
@@ -1101,6 +1209,8 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/{period}", repo.Activity)
 		}, context.RepoRef(), repo.MustBeNotEmpty, context.RequireRepoReaderOr(unit.TypePullRequests, unit.TypeIssues, unit.TypeReleases))
 
+		m.Get("/time-report", reqSignIn, reqRepoIssueReader, repo.TimeReport)
+
 		m.Group("/activity_author_data", func() {
 			m.Get("", repo.ActivityAuthors)
 			m.Get("/{period}", repo.ActivityAuthors)
@@ -1143,6 +1253,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(forms.MergePullRequestForm{}), repo.MergePullRequest)
 			m.Post("/cancel_auto_merge", context.RepoMustNotBeArchived(), repo.CancelAutoMergePullRequest)
+			m.Post("/remove_from_merge_queue", context.RepoMustNotBeArchived(), repo.RemoveFromMergeQueue)
 			m.Post("/update", repo.UpdatePullRequest)
 			m.Post("/set_allow_maintainer_edit", bindIgnErr(forms.UpdateAllowEditsForm{}), repo.SetAllowEdits)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
@@ -0,0 +1,202 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	app_model "code.gitea.io/gitea/models/app"
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ErrAppNoPermission is returned if a new app has no permission
+var ErrAppNoPermission = errors.New("an app requires at least one permission")
+
+// CreateAppOptions are the options of a new app
+type CreateAppOptions struct {
+	Name          string
+	Description   string
+	HomepageURL   string
+	Permissions   []string // in the format of the scopes of the personal access tokens
+	WebhookURL    string   // the app receives all the events of the repositories it is installed on if set
+	WebhookSecret string
+}
+
+// BotName returns the name of the bot user of an app
+func BotName(appName string) string {
+	return appName + "-bot"
+}
+
+// CreateApp registers an app of the user with its bot user and its webhook.
+// It returns the PEM encoded private key the app signs its JWTs with, which is not stored.
+func CreateApp(ctx context.Context, doer *user_model.User, opts *CreateAppOptions) (*app_model.App, string, error) {
+	scope, err := auth.ParseAccessTokenScope(opts.Permissions)
+	if err != nil {
+		return nil, "", err
+	}
+	if scope == "" {
+		return nil, "", ErrAppNoPermission
+	}
+	// the installation tokens are restricted to the organization which installed the app
+	if err := (&models.AccessToken{Scope: scope, OrgID: -1}).ValidateRestriction(); err != nil {
+		return nil, "", err
+	}
+
+	name := strings.TrimSpace(opts.Name)
+	if err := user_model.IsUsableUsername(BotName(name)); err != nil {
+		return nil, "", err
+	}
+	if _, err := app_model.GetAppByName(ctx, name); err == nil {
+		return nil, "", app_model.ErrAppAlreadyExist{Name: name}
+	} else if !app_model.IsErrAppNotExist(err) {
+		return nil, "", err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, "", err
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, "", err
+	}
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bot, err := createBot(BotName(name))
+	if err != nil {
+		return nil, "", err
+	}
+
+	a := &app_model.App{
+		OwnerID:     doer.ID,
+		Name:        name,
+		Description: opts.Description,
+		HomepageURL: opts.HomepageURL,
+		BotID:       bot.ID,
+		Permissions: scope,
+		PublicKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+	}
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := app_model.CreateApp(ctx, a); err != nil {
+			return err
+		}
+		if opts.WebhookURL == "" {
+			return nil
+		}
+		w := &webhook_model.Webhook{
+			AppID:       a.ID,
+			URL:         opts.WebhookURL,
+			HTTPMethod:  "POST",
+			ContentType: webhook_model.ContentTypeJSON,
+			Secret:      opts.WebhookSecret,
+			HookEvent: &webhook_model.HookEvent{
+				SendEverything: true,
+			},
+			IsActive: true,
+			Type:     webhook_model.GITEA,
+		}
+		if err := w.UpdateEvent(); err != nil {
+			return err
+		}
+		if err := webhook_model.CreateWebhook(ctx, w); err != nil {
+			return err
+		}
+		a.WebhookID = w.ID
+		_, err := db.GetEngine(ctx).ID(a.ID).Cols("webhook_id").Update(a)
+		return err
+	}, ctx); err != nil {
+		if err := deleteBot(ctx, bot); err != nil {
+			log.Error("Unable to delete the bot %s of the app: %v", bot.Name, err)
+		}
+		return nil, "", err
+	}
+
+	return a, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKey})), nil
+}
+
+func createBot(name string) (*user_model.User, error) {
+	passwd, err := util.CryptoRandomString(40)
+	if err != nil {
+		return nil, err
+	}
+	bot := &user_model.User{
+		Name:   name,
+		Email:  fmt.Sprintf("%s@%s", strings.ToLower(name), setting.Service.NoReplyAddress),
+		Passwd: passwd,
+		Type:   user_model.UserTypeBot,
+	}
+	maxRepoCreation := 0
+	if err := user_model.CreateUser(bot, &user_model.CreateUserOverwriteOptions{
+		KeepEmailPrivate:        util.OptionalBoolTrue,
+		AllowCreateOrganization: util.OptionalBoolFalse,
+		MaxRepoCreation:         &maxRepoCreation,
+		IsRestricted:            util.OptionalBoolFalse,
+		IsActive:                util.OptionalBoolTrue,
+	}); err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+func deleteBot(ctx context.Context, bot *user_model.User) error {
+	return db.WithTx(func(ctx context.Context) error {
+		return models.DeleteUser(ctx, bot, false)
+	}, ctx)
+}
+
+// DeleteApp removes an app with its installations, its webhook and its bot user with the installation tokens
+func DeleteApp(ctx context.Context, a *app_model.App) error {
+	if a.WebhookID > 0 {
+		if err := webhook_model.DeleteWebhookByAppID(a.ID, a.WebhookID); err != nil && !webhook_model.IsErrWebhookNotExist(err) {
+			return err
+		}
+	}
+
+	bot, err := user_model.GetUserByIDCtx(ctx, a.BotID)
+	if err != nil && !user_model.IsErrUserNotExist(err) {
+		return err
+	}
+
+	return db.WithTx(func(ctx context.Context) error {
+		if err := app_model.DeleteApp(ctx, a); err != nil {
+			return err
+		}
+		if bot == nil {
+			return nil
+		}
+		return models.DeleteUser(ctx, bot, false)
+	}, ctx)
+}
+
+// DeleteAppsByOwnerID removes all the apps registered by a user
+func DeleteAppsByOwnerID(ctx context.Context, ownerID int64) error {
+	apps, _, err := app_model.FindApps(ctx, app_model.FindAppsOptions{OwnerID: ownerID})
+	if err != nil {
+		return err
+	}
+	for _, a := range apps {
+		if err := DeleteApp(ctx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
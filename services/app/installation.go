@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	app_model "code.gitea.io/gitea/models/app"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// InstallationTokenLifetime is the lifetime of the installation tokens
+const InstallationTokenLifetime = time.Hour
+
+// Install installs an app on all the repositories of an organization or on the selected ones,
+// installing it again replaces the repositories it is installed on
+func Install(ctx context.Context, a *app_model.App, org *user_model.User, allRepositories bool, repoIDs []int64) (*app_model.Installation, error) {
+	if !allRepositories {
+		for _, repoID := range repoIDs {
+			repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+			if err != nil {
+				return nil, err
+			}
+			if repo.OwnerID != org.ID {
+				return nil, repo_model.ErrRepoNotExist{ID: repoID}
+			}
+		}
+	}
+	return app_model.SaveInstallation(ctx, a.ID, org.ID, allRepositories, repoIDs)
+}
+
+// Uninstall removes an installation and revokes its installation tokens
+func Uninstall(ctx context.Context, a *app_model.App, i *app_model.Installation) error {
+	return db.WithTx(func(ctx context.Context) error {
+		if err := app_model.DeleteInstallation(ctx, i); err != nil {
+			return err
+		}
+		return models.DeleteAccessTokensByOrgID(ctx, a.BotID, i.OwnerID)
+	}, ctx)
+}
+
+// CreateInstallationToken creates a short-lived access token of the bot of an app,
+// restricted to the organization of the installation and to the permissions of the app
+func CreateInstallationToken(ctx context.Context, a *app_model.App, i *app_model.Installation) (*models.AccessToken, error) {
+	t := &models.AccessToken{
+		UID:         a.BotID,
+		Name:        fmt.Sprintf("installation-%d", i.ID),
+		Scope:       a.Permissions,
+		OrgID:       i.OwnerID,
+		ExpiresUnix: timeutil.TimeStampNow().AddDuration(InstallationTokenLifetime),
+		// nobody reads the mails of a bot
+		IsExpiryNotified: true,
+	}
+	if err := models.NewAccessToken(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	app_model "code.gitea.io/gitea/models/app"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// MaxJWTLifetime is the longest lifetime accepted for the JWTs the apps authenticate with
+const MaxJWTLifetime = 10 * time.Minute
+
+// jwtClockSkew is the tolerated difference between the clock of the app and ours
+const jwtClockSkew = time.Minute
+
+// VerifyJWT returns the app which signed the JWT. The issuer of the JWT is the id of the app,
+// it must be signed with RS256 by the private key of the app and expire within MaxJWTLifetime.
+func VerifyJWT(ctx context.Context, tokenString string) (*app_model.App, error) {
+	var a *app_model.App
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		id, err := strconv.ParseInt(claims.Issuer, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issuer: %q", claims.Issuer)
+		}
+		a, err = app_model.GetAppByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return jwt.ParseRSAPublicKeyFromPEM([]byte(a.PublicKey))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt == nil || claims.ExpiresAt.After(now.Add(MaxJWTLifetime+jwtClockSkew)) {
+		return nil, fmt.Errorf("the token must expire within %v", MaxJWTLifetime)
+	}
+	return a, nil
+}
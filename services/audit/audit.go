@@ -0,0 +1,233 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"net"
+
+	"code.gitea.io/gitea/models"
+	app_model "code.gitea.io/gitea/models/app"
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// Record appends an event to the audit log.
+// Before and after are the states of the target, nil if the target didn't exist before or doesn't exist anymore.
+// A failure is only logged because the audited change is already done.
+func Record(ctx context.Context, action audit_model.Action, doer *user_model.User, remoteAddr string, scope, target audit_model.Object, before, after interface{}) {
+	if !setting.Audit.Enabled {
+		return
+	}
+
+	e := &audit_model.Event{
+		Action:     action,
+		ScopeType:  scope.Type,
+		ScopeID:    scope.ID,
+		ScopeName:  scope.Name,
+		TargetType: target.Type,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		Before:     marshalState(before),
+		After:      marshalState(after),
+		IPAddress:  remoteIP(remoteAddr),
+	}
+	if doer != nil {
+		e.ActorID = doer.ID
+		e.ActorName = doer.Name
+	}
+
+	// the request context may be cancelled after the change was done
+	if ctx.Err() != nil {
+		ctx = db.DefaultContext
+	}
+	if err := audit_model.InsertEvent(ctx, e); err != nil {
+		log.Error("Unable to record audit event %s on %s %s by %s: %v", action, target.Type, target.Name, e.ActorName, err)
+	}
+}
+
+func marshalState(state interface{}) string {
+	if state == nil {
+		return ""
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Error("Unable to marshal audit state: %v", err)
+		return ""
+	}
+	return string(data)
+}
+
+func remoteIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// System is the scope of instance wide changes
+func System() audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeSystem}
+}
+
+// User returns the audit object of a user or of an organization
+func User(u *user_model.User) audit_model.Object {
+	if u.IsOrganization() {
+		return audit_model.Object{Type: audit_model.TypeOrganization, ID: u.ID, Name: u.Name}
+	}
+	return audit_model.Object{Type: audit_model.TypeUser, ID: u.ID, Name: u.Name}
+}
+
+// Repository returns the audit object of a repository
+func Repository(repo *repo_model.Repository) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeRepository, ID: repo.ID, Name: repo.FullName()}
+}
+
+// Team returns the audit object of a team
+func Team(t *organization.Team) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeTeam, ID: t.ID, Name: t.Name}
+}
+
+// BranchProtection returns the audit object of a branch protection rule
+func BranchProtection(pb *git_model.ProtectedBranch) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeBranchProtection, ID: pb.ID, Name: pb.BranchName}
+}
+
+// PushPolicy returns the audit object of the push policy of an organization
+func PushPolicy(p *git_model.PushPolicy) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypePushPolicy, ID: p.ID}
+}
+
+// AccessToken returns the audit object of an access token
+func AccessToken(t *models.AccessToken) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeAccessToken, ID: t.ID, Name: t.Name}
+}
+
+// App returns the audit object of an app
+func App(a *app_model.App) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeApp, ID: a.ID, Name: a.Name}
+}
+
+// DeployToken returns the audit object of a deploy token
+func DeployToken(t *auth_model.DeployToken) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeDeployToken, ID: t.ID, Name: t.Name}
+}
+
+// WebAuthnCredential returns the audit object of a security key
+func WebAuthnCredential(cred *auth_model.WebAuthnCredential) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeWebAuthnCredential, ID: cred.ID, Name: cred.Name}
+}
+
+// Webhook returns the audit object of a webhook
+func Webhook(w *webhook.Webhook) audit_model.Object {
+	return audit_model.Object{Type: audit_model.TypeWebhook, ID: w.ID, Name: util.SanitizeCredentialURLs(w.URL)}
+}
+
+// RecordCollaboration records the change of a collaboration between two states, nil if the user is no collaborator
+func RecordCollaboration(ctx context.Context, doer *user_model.User, remoteAddr string, repo *repo_model.Repository, collaborator *user_model.User, before, after *repo_model.Collaboration) {
+	var action audit_model.Action
+	var beforeState, afterState interface{}
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		action = audit_model.ActionCollaboratorAdd
+		afterState = Permission(after.Mode)
+	case after == nil:
+		action = audit_model.ActionCollaboratorRemove
+		beforeState = Permission(before.Mode)
+	case before.Mode != after.Mode:
+		action = audit_model.ActionCollaboratorPermission
+		beforeState = Permission(before.Mode)
+		afterState = Permission(after.Mode)
+	default:
+		return
+	}
+	Record(ctx, action, doer, remoteAddr, Repository(repo), User(collaborator), beforeState, afterState)
+}
+
+// teamScope returns the organization of the team
+func teamScope(ctx context.Context, t *organization.Team) audit_model.Object {
+	org, err := organization.GetOrgByID(ctx, t.OrgID)
+	if err != nil {
+		log.Error("Unable to load organization %d of team %d: %v", t.OrgID, t.ID, err)
+		return audit_model.Object{Type: audit_model.TypeOrganization, ID: t.OrgID}
+	}
+	return User(org.AsUser())
+}
+
+// RecordTeam records the creation, change or deletion of a team
+func RecordTeam(ctx context.Context, action audit_model.Action, doer *user_model.User, remoteAddr string, t *organization.Team, before, after *TeamState) {
+	var beforeState, afterState interface{}
+	if before != nil {
+		beforeState = before
+	}
+	if after != nil {
+		afterState = after
+	}
+	Record(ctx, action, doer, remoteAddr, teamScope(ctx, t), Team(t), beforeState, afterState)
+}
+
+// RecordTeamMember records that the user was added to or removed from the team
+func RecordTeamMember(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team, member *user_model.User, added bool) {
+	if added {
+		Record(ctx, audit_model.ActionTeamMemberAdd, doer, remoteAddr, teamScope(ctx, t), User(member), nil, TeamRefOf(t))
+	} else {
+		Record(ctx, audit_model.ActionTeamMemberRemove, doer, remoteAddr, teamScope(ctx, t), User(member), TeamRefOf(t), nil)
+	}
+}
+
+// RecordTeamRepository records that the repository was added to or removed from the team
+func RecordTeamRepository(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team, repo *repo_model.Repository, added bool) {
+	if added {
+		Record(ctx, audit_model.ActionTeamRepositoryAdd, doer, remoteAddr, teamScope(ctx, t), Repository(repo), nil, TeamRefOf(t))
+	} else {
+		Record(ctx, audit_model.ActionTeamRepositoryRemove, doer, remoteAddr, teamScope(ctx, t), Repository(repo), TeamRefOf(t), nil)
+	}
+}
+
+// webhookScope returns the repository or the organization of the webhook, the system for default and system webhooks
+func webhookScope(ctx context.Context, w *webhook.Webhook) audit_model.Object {
+	switch {
+	case w.RepoID > 0:
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, w.RepoID)
+		if err != nil {
+			log.Error("Unable to load repository %d of webhook %d: %v", w.RepoID, w.ID, err)
+			return audit_model.Object{Type: audit_model.TypeRepository, ID: w.RepoID}
+		}
+		return Repository(repo)
+	case w.OrgID > 0:
+		org, err := user_model.GetUserByIDCtx(ctx, w.OrgID)
+		if err != nil {
+			log.Error("Unable to load organization %d of webhook %d: %v", w.OrgID, w.ID, err)
+			return audit_model.Object{Type: audit_model.TypeOrganization, ID: w.OrgID}
+		}
+		return User(org)
+	default:
+		return System()
+	}
+}
+
+// RecordWebhook records the creation, change or deletion of a webhook
+func RecordWebhook(ctx context.Context, action audit_model.Action, doer *user_model.User, remoteAddr string, w *webhook.Webhook, before, after *WebhookState) {
+	var beforeState, afterState interface{}
+	if before != nil {
+		beforeState = before
+	}
+	if after != nil {
+		afterState = after
+	}
+	Record(ctx, action, doer, remoteAddr, webhookScope(ctx, w), Webhook(w), beforeState, afterState)
+}
@@ -0,0 +1,218 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// The states are the recorded before and after values of the targets.
+// Secrets like webhook secrets or token hashes are never part of a state.
+
+// PermissionState is the state of a collaboration
+type PermissionState struct {
+	Permission string `json:"permission"`
+}
+
+// Permission returns the state of a collaboration with the access mode
+func Permission(mode perm.AccessMode) *PermissionState {
+	return &PermissionState{Permission: mode.String()}
+}
+
+// VisibilityState is the state of the visibility of a repository
+type VisibilityState struct {
+	Private bool `json:"private"`
+}
+
+// Visibility returns the visibility state of the repository
+func Visibility(repo *repo_model.Repository) *VisibilityState {
+	return &VisibilityState{Private: repo.IsPrivate}
+}
+
+// TeamState is the state of a team
+type TeamState struct {
+	Name                    string            `json:"name"`
+	Description             string            `json:"description"`
+	Permission              string            `json:"permission"`
+	IncludesAllRepositories bool              `json:"includes_all_repositories"`
+	CanCreateOrgRepo        bool              `json:"can_create_org_repo"`
+	Units                   map[string]string `json:"units,omitempty"`
+}
+
+// TeamStateOf returns the state of the team, the units must be loaded
+func TeamStateOf(t *organization.Team) *TeamState {
+	return &TeamState{
+		Name:                    t.Name,
+		Description:             t.Description,
+		Permission:              t.AccessMode.String(),
+		IncludesAllRepositories: t.IncludesAllRepositories,
+		CanCreateOrgRepo:        t.CanCreateOrgRepo,
+		Units:                   t.GetUnitsMap(),
+	}
+}
+
+// TeamRef is the state of a team membership or of a repository of a team
+type TeamRef struct {
+	Team       string `json:"team"`
+	Permission string `json:"permission"`
+}
+
+// TeamRefOf returns the reference to the team
+func TeamRefOf(t *organization.Team) *TeamRef {
+	return &TeamRef{Team: t.Name, Permission: t.AccessMode.String()}
+}
+
+// BranchProtectionState is the state of a branch protection rule
+type BranchProtectionState struct {
+	BranchName                    string   `json:"branch_name"`
+	CanPush                       bool     `json:"can_push"`
+	EnableWhitelist               bool     `json:"enable_whitelist"`
+	WhitelistUserIDs              []int64  `json:"whitelist_user_ids"`
+	WhitelistTeamIDs              []int64  `json:"whitelist_team_ids"`
+	WhitelistDeployKeys           bool     `json:"whitelist_deploy_keys"`
+	EnableMergeWhitelist          bool     `json:"enable_merge_whitelist"`
+	MergeWhitelistUserIDs         []int64  `json:"merge_whitelist_user_ids"`
+	MergeWhitelistTeamIDs         []int64  `json:"merge_whitelist_team_ids"`
+	EnableStatusCheck             bool     `json:"enable_status_check"`
+	StatusCheckContexts           []string `json:"status_check_contexts"`
+	EnableApprovalsWhitelist      bool     `json:"enable_approvals_whitelist"`
+	ApprovalsWhitelistUserIDs     []int64  `json:"approvals_whitelist_user_ids"`
+	ApprovalsWhitelistTeamIDs     []int64  `json:"approvals_whitelist_team_ids"`
+	RequiredApprovals             int64    `json:"required_approvals"`
+	BlockOnRejectedReviews        bool     `json:"block_on_rejected_reviews"`
+	BlockOnOfficialReviewRequests bool     `json:"block_on_official_review_requests"`
+	BlockOnOutdatedBranch         bool     `json:"block_on_outdated_branch"`
+	DismissStaleApprovals         bool     `json:"dismiss_stale_approvals"`
+	RequireSignedCommits          bool     `json:"require_signed_commits"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
+	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
+	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
+	MergeWindows                  string   `json:"merge_windows"`
+}
+
+// BranchProtectionStateOf returns the state of the branch protection rule
+func BranchProtectionStateOf(pb *git_model.ProtectedBranch) *BranchProtectionState {
+	return &BranchProtectionState{
+		BranchName:                    pb.BranchName,
+		CanPush:                       pb.CanPush,
+		EnableWhitelist:               pb.EnableWhitelist,
+		WhitelistUserIDs:              pb.WhitelistUserIDs,
+		WhitelistTeamIDs:              pb.WhitelistTeamIDs,
+		WhitelistDeployKeys:           pb.WhitelistDeployKeys,
+		EnableMergeWhitelist:          pb.EnableMergeWhitelist,
+		MergeWhitelistUserIDs:         pb.MergeWhitelistUserIDs,
+		MergeWhitelistTeamIDs:         pb.MergeWhitelistTeamIDs,
+		EnableStatusCheck:             pb.EnableStatusCheck,
+		StatusCheckContexts:           pb.StatusCheckContexts,
+		EnableApprovalsWhitelist:      pb.EnableApprovalsWhitelist,
+		ApprovalsWhitelistUserIDs:     pb.ApprovalsWhitelistUserIDs,
+		ApprovalsWhitelistTeamIDs:     pb.ApprovalsWhitelistTeamIDs,
+		RequiredApprovals:             pb.RequiredApprovals,
+		BlockOnRejectedReviews:        pb.BlockOnRejectedReviews,
+		BlockOnOfficialReviewRequests: pb.BlockOnOfficialReviewRequests,
+		BlockOnOutdatedBranch:         pb.BlockOnOutdatedBranch,
+		DismissStaleApprovals:         pb.DismissStaleApprovals,
+		RequireSignedCommits:          pb.RequireSignedCommits,
+		RequireCodeOwnerApproval:      pb.RequireCodeOwnerApproval,
+		EnableMergeQueue:              pb.EnableMergeQueue,
+		ProtectedFilePatterns:         pb.ProtectedFilePatterns,
+		UnprotectedFilePatterns:       pb.UnprotectedFilePatterns,
+		MergeWindows:                  pb.MergeWindows,
+	}
+}
+
+// PushPolicyState is the state of the push policy of an organization
+type PushPolicyState struct {
+	DryRun               bool   `json:"dry_run"`
+	MaxFileSize          int64  `json:"max_file_size"`
+	ForbiddenPaths       string `json:"forbidden_paths"`
+	ForbiddenExtensions  string `json:"forbidden_extensions"`
+	RequireSignedCommits bool   `json:"require_signed_commits"`
+	CommitMessagePattern string `json:"commit_message_pattern"`
+	AllowedEmailDomains  string `json:"allowed_email_domains"`
+	SecretPatterns       string `json:"secret_patterns"`
+}
+
+// PushPolicyStateOf returns the state of the push policy
+func PushPolicyStateOf(p *git_model.PushPolicy) *PushPolicyState {
+	return &PushPolicyState{
+		DryRun:               p.DryRun,
+		MaxFileSize:          p.MaxFileSize,
+		ForbiddenPaths:       p.ForbiddenPaths,
+		ForbiddenExtensions:  p.ForbiddenExtensions,
+		RequireSignedCommits: p.RequireSignedCommits,
+		CommitMessagePattern: p.CommitMessagePattern,
+		AllowedEmailDomains:  p.AllowedEmailDomains,
+		SecretPatterns:       p.SecretPatterns,
+	}
+}
+
+// WebhookState is the state of a webhook without its secret and headers
+type WebhookState struct {
+	Type        string             `json:"type"`
+	URL         string             `json:"url"`
+	HTTPMethod  string             `json:"http_method"`
+	ContentType string             `json:"content_type"`
+	Events      *webhook.HookEvent `json:"events"`
+	Active      bool               `json:"active"`
+}
+
+// WebhookStateOf returns the state of the webhook
+func WebhookStateOf(w *webhook.Webhook) *WebhookState {
+	state := &WebhookState{
+		Type:        w.Type,
+		URL:         util.SanitizeCredentialURLs(w.URL),
+		HTTPMethod:  w.HTTPMethod,
+		ContentType: w.ContentType.Name(),
+		Active:      w.IsActive,
+	}
+	if w.HookEvent != nil {
+		// copy the events because the webhook may be changed afterwards
+		events := *w.HookEvent
+		state.Events = &events
+	}
+	return state
+}
+
+// UserState is the state of the account settings an admin can change
+type UserState struct {
+	Email                   string `json:"email"`
+	LoginSource             int64  `json:"login_source"`
+	LoginName               string `json:"login_name"`
+	IsActive                bool   `json:"active"`
+	IsAdmin                 bool   `json:"admin"`
+	IsRestricted            bool   `json:"restricted"`
+	ProhibitLogin           bool   `json:"prohibit_login"`
+	AllowGitHook            bool   `json:"allow_git_hook"`
+	AllowImportLocal        bool   `json:"allow_import_local"`
+	AllowCreateOrganization bool   `json:"allow_create_organization"`
+	MaxRepoCreation         int    `json:"max_repo_creation"`
+	Visibility              string `json:"visibility"`
+}
+
+// UserStateOf returns the state of the user
+func UserStateOf(u *user_model.User) *UserState {
+	return &UserState{
+		Email:                   u.Email,
+		LoginSource:             u.LoginSource,
+		LoginName:               u.LoginName,
+		IsActive:                u.IsActive,
+		IsAdmin:                 u.IsAdmin,
+		IsRestricted:            u.IsRestricted,
+		ProhibitLogin:           u.ProhibitLogin,
+		AllowGitHook:            u.AllowGitHook,
+		AllowImportLocal:        u.AllowImportLocal,
+		AllowCreateOrganization: u.AllowCreateOrganization,
+		MaxRepoCreation:         u.MaxRepoCreation,
+		Visibility:              u.Visibility.String(),
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"regexp"
 	"strings"
 
+	"code.gitea.io/gitea/models"
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/auth/webauthn"
@@ -39,6 +41,7 @@ func isContainerPath(req *http.Request) bool {
 var (
 	gitRawReleasePathRe = regexp.MustCompile(`^/[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+/(?:(?:git-(?:(?:upload)|(?:receive))-pack$)|(?:info/refs$)|(?:HEAD$)|(?:objects/)|(?:raw/)|(?:releases/download/))`)
 	lfsPathRe           = regexp.MustCompile(`^/[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+/info/lfs/`)
+	gitSmartHTTPPathRe  = regexp.MustCompile(`^/[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+/(?:(?:git-(?:(?:upload)|(?:receive))-pack$)|(?:info/refs$))`)
 )
 
 func isGitRawReleaseOrLFSPath(req *http.Request) bool {
@@ -51,6 +54,32 @@ func isGitRawReleaseOrLFSPath(req *http.Request) bool {
 	return false
 }
 
+// StoreAccessToken marks the request as authenticated with the personal access token,
+// its scope and its restriction are checked by the routes
+func StoreAccessToken(store DataStore, t *models.AccessToken) {
+	store.GetData()["IsApiToken"] = true
+	store.GetData()["ApiToken"] = t
+}
+
+// isDeployTokenPath checks if the request targets an endpoint accepting the deploy tokens:
+// the Git smart HTTP and LFS endpoints and the package registry
+func isDeployTokenPath(req *http.Request) bool {
+	if gitSmartHTTPPathRe.MatchString(req.URL.Path) || isContainerPath(req) || strings.HasPrefix(req.URL.Path, "/api/packages/") {
+		return true
+	}
+	if setting.LFS.StartServer {
+		return lfsPathRe.MatchString(req.URL.Path)
+	}
+	return false
+}
+
+// StoreDeployToken marks the request as authenticated with the deploy token,
+// the repositories and the packages it can access are checked by the routes
+func StoreDeployToken(store DataStore, t *auth_model.DeployToken) {
+	store.GetData()["IsApiToken"] = true
+	store.GetData()["DeployToken"] = t
+}
+
 // handleSignIn clears existing session variables and stores new ones for the specified user object
 func handleSignIn(resp http.ResponseWriter, req *http.Request, sess SessionStore, user *user_model.User) {
 	// We need to regenerate the session...
@@ -129,3 +129,36 @@ func Test_isGitRawOrLFSPath(t *testing.T) {
 	}
 	setting.LFS.StartServer = origLFSStartServer
 }
+
+func Test_isDeployTokenPath(t *testing.T) {
+	tests := []struct {
+		path string
+
+		want bool
+	}{
+		{"/owner/repo/git-upload-pack", true},
+		{"/owner/repo.git/git-receive-pack", true},
+		{"/owner/repo.wiki.git/info/refs", true},
+		{"/owner/repo/info/lfs/objects/batch", true},
+		{"/api/packages/owner/generic/name/1.0.0/file.bin", true},
+		{"/v2/owner/image/manifests/latest", true},
+		{"/owner/repo/HEAD", false},
+		{"/owner/repo/objects/info/packs", false},
+		{"/owner/repo/raw/branch/master/README.md", false},
+		{"/owner/repo/releases/download/tag/repo.tar.gz", false},
+		{"/api/v1/repos/owner/repo", false},
+	}
+
+	origLFSStartServer := setting.LFS.StartServer
+	setting.LFS.StartServer = true
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://localhost"+tt.path, nil)
+			if got := isDeployTokenPath(req); got != tt.want {
+				t.Errorf("isDeployTokenPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	setting.LFS.StartServer = origLFSStartServer
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
@@ -99,12 +101,27 @@ func (b *Basic) Verify(req *http.Request, w http.ResponseWriter, store DataStore
 			log.Error("UpdateAccessToken:  %v", err)
 		}
 
-		store.GetData()["IsApiToken"] = true
+		StoreAccessToken(store, token)
 		return u
 	} else if !models.IsErrAccessTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
 		log.Error("GetAccessTokenBySha: %v", err)
 	}
 
+	if isDeployTokenPath(req) {
+		deployToken, err := auth_model.GetDeployTokenBySHA(db.DefaultContext, authToken)
+		if err == nil {
+			log.Trace("Basic Authorization: Valid DeployToken[%d]", deployToken.ID)
+			if err = auth_model.UpdateDeployTokenLastUsed(db.DefaultContext, deployToken); err != nil {
+				log.Error("UpdateDeployTokenLastUsed: %v", err)
+			}
+
+			StoreDeployToken(store, deployToken)
+			return user_model.NewDeployTokenUser(deployToken.Name)
+		} else if !auth_model.IsErrDeployTokenNotExist(err) {
+			log.Error("GetDeployTokenBySHA: %v", err)
+		}
+	}
+
 	if !setting.Service.EnableBasicAuth {
 		return nil
 	}
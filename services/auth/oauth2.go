@@ -102,7 +102,7 @@ func (o *OAuth2) userIDFromToken(req *http.Request, store DataStore) int64 {
 	if err = models.UpdateAccessToken(t); err != nil {
 		log.Error("UpdateAccessToken: %v", err)
 	}
-	store.GetData()["IsApiToken"] = true
+	StoreAccessToken(store, t)
 	return t.UID
 }
 
@@ -77,7 +77,8 @@ func UserSignIn(username, password string) (*user_model.User, *auth.Source, erro
 
 			// WARN: DON'T check user.IsActive, that will be checked on reqSign so that
 			// user could be hint to resend confirm email.
-			if user.ProhibitLogin {
+			// the bots of the apps can only authenticate with installation tokens
+			if user.ProhibitLogin || user.IsBot() {
 				return nil, nil, user_model.ErrUserProhibitLogin{UID: user.ID, Name: user.Name}
 			}
 
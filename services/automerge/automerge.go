@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
@@ -22,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/timeutil"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
 
@@ -61,25 +63,39 @@ func addToQueue(pr *issues_model.PullRequest, sha string) {
 	}
 }
 
-// ScheduleAutoMerge if schedule is false and no error, pull can be merged directly
-func ScheduleAutoMerge(ctx context.Context, doer *user_model.User, pull *issues_model.PullRequest, style repo_model.MergeStyle, message string) (scheduled bool, err error) {
+// ScheduleAutoMerge schedules the merge of a pull request when all checks succeed and, if scheduledUnix is in the future,
+// not before scheduledUnix. If schedule is false and no error, pull can be merged directly
+func ScheduleAutoMerge(ctx context.Context, doer *user_model.User, pull *issues_model.PullRequest, style repo_model.MergeStyle, message string, scheduledUnix timeutil.TimeStamp) (scheduled bool, err error) {
+	if scheduledUnix <= timeutil.TimeStampNow() {
+		scheduledUnix = 0
+	}
+
 	err = db.WithTx(func(ctx context.Context) error {
-		lastCommitStatus, err := pull_service.GetPullRequestCommitStatusState(ctx, pull)
-		if err != nil {
-			return err
-		}
+		if scheduledUnix == 0 {
+			lastCommitStatus, err := pull_service.GetPullRequestCommitStatusState(ctx, pull)
+			if err != nil {
+				return err
+			}
+			if err := pull.LoadProtectedBranchCtx(ctx); err != nil {
+				return err
+			}
 
-		// we don't need to schedule
-		if lastCommitStatus.IsSuccess() {
-			return nil
+			// we don't need to schedule
+			if lastCommitStatus.IsSuccess() && (pull.ProtectedBranch == nil || pull.ProtectedBranch.IsInMergeWindow(time.Now())) {
+				return nil
+			}
 		}
 
-		if err := pull_model.ScheduleAutoMerge(ctx, doer, pull.ID, style, message); err != nil {
+		if err := pull_model.ScheduleAutoMerge(ctx, doer, pull.ID, style, message, scheduledUnix); err != nil {
 			return err
 		}
 		scheduled = true
 
-		_, err = issues_model.CreateAutoMergeComment(ctx, issues_model.CommentTypePRScheduledToAutoMerge, pull, doer)
+		var content string
+		if scheduledUnix > 0 {
+			content = scheduledUnix.FormatLong()
+		}
+		_, err = issues_model.CreateAutoMergeComment(ctx, issues_model.CommentTypePRScheduledToAutoMerge, pull, doer, content)
 		return err
 	}, ctx)
 	return scheduled, err
@@ -92,7 +108,7 @@ func RemoveScheduledAutoMerge(ctx context.Context, doer *user_model.User, pull *
 			return err
 		}
 
-		_, err := issues_model.CreateAutoMergeComment(ctx, issues_model.CommentTypePRUnScheduledToAutoMerge, pull, doer)
+		_, err := issues_model.CreateAutoMergeComment(ctx, issues_model.CommentTypePRUnScheduledToAutoMerge, pull, doer, "")
 		return err
 	}, ctx)
 }
@@ -113,6 +129,40 @@ func MergeScheduledPullRequest(ctx context.Context, sha string, repo *repo_model
 	return nil
 }
 
+// MergePendingScheduledPullRequests attempts again the merges which wait for their scheduled time or for a merge window,
+// and processes the merge queues waiting for a merge window
+func MergePendingScheduledPullRequests(ctx context.Context) error {
+	merges, err := pull_model.GetPendingScheduledMerges(ctx)
+	if err != nil {
+		return err
+	}
+	for _, scheduled := range merges {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("while merging the scheduled pull requests")
+		default:
+		}
+
+		pr, err := issues_model.GetPullRequestByID(ctx, scheduled.PullID)
+		if err != nil {
+			log.Error("GetPullRequestByID[%d]: %v", scheduled.PullID, err)
+			continue
+		}
+		if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+			log.Error("pull[%d] LoadBaseRepoCtx: %v", pr.ID, err)
+			continue
+		}
+		sha, err := git.GetFullCommitID(ctx, pr.BaseRepo.RepoPath(), pr.GetGitRefName())
+		if err != nil {
+			log.Error("pull[%d] GetFullCommitID: %v", pr.ID, err)
+			continue
+		}
+		addToQueue(pr, sha)
+	}
+
+	return pull_service.AddMergeWindowMergeQueueTasks(ctx)
+}
+
 func getPullRequestsByHeadSHA(ctx context.Context, sha string, repo *repo_model.Repository, filter func(*issues_model.PullRequest) bool) (map[int64]*issues_model.PullRequest, error) {
 	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
 	if err != nil {
@@ -183,6 +233,10 @@ func handlePull(pullID int64, sha string) {
 	if !exists {
 		return
 	}
+	if !scheduledPRM.IsDue() {
+		log.Trace("Scheduled merge of pr %d is not due yet", pr.ID)
+		return
+	}
 
 	// Get all checks for this pr
 	// We get the latest sha commit hash again to handle the case where the check of a previous push
@@ -240,6 +294,23 @@ func handlePull(pullID int64, sha string) {
 		return
 	}
 
+	// The merge is attempted again by the cron task once the merge window opens
+	if pr.ProtectedBranch != nil && !pr.ProtectedBranch.IsInMergeWindow(time.Now()) {
+		log.Trace("The base branch of the scheduled auto merge pr %d is outside of its merge windows", pr.ID)
+		return
+	}
+
+	// Let the merge queue merge it if it is enabled for the base branch
+	if enabled, err := pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+		log.Error("IsMergeQueueEnabled: %v", err)
+		return
+	} else if enabled {
+		if err := pull_service.AddToMergeQueue(ctx, pr, doer, scheduledPRM.MergeStyle, "", scheduledPRM.Message); err != nil {
+			log.Error("pull_service.AddToMergeQueue: %v", err)
+		}
+		return
+	}
+
 	var baseGitRepo *git.Repository
 	if pr.BaseRepoID == pr.HeadRepoID {
 		baseGitRepo = headGitRepo
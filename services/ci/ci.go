@@ -0,0 +1,229 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ci_model "code.gitea.io/gitea/models/ci"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+	user_model "code.gitea.io/gitea/models/user"
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+var statusDescriptions = map[ci_model.Status]string{
+	ci_model.StatusWaiting:   "Waiting for a runner",
+	ci_model.StatusRunning:   "Running",
+	ci_model.StatusSuccess:   "Successful",
+	ci_model.StatusFailure:   "Failed",
+	ci_model.StatusCancelled: "Cancelled",
+}
+
+func queueHandle(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		req := datum.(*ci_module.DetectionRequest)
+		if err := detectWorkflows(graceful.GetManager().ShutdownContext(), req); err != nil {
+			log.Error("Unable to detect the workflows of repo[%d] for %s on %s: %v", req.RepoID, req.Event, req.Ref, err)
+		}
+	}
+	return nil
+}
+
+// Init starts the detection of the workflows triggered by the events
+func Init() {
+	ci_module.StartWorkflowDetection(queueHandle)
+}
+
+// createCommitStatus reports the status of a job on the commit of its run
+func createCommitStatus(ctx context.Context, run *ci_model.Run, job *ci_model.Job) error {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return err
+	}
+	return files_service.CreateCommitStatus(ctx, run.Repo, run.TriggerUser, run.CommitSHA, &git_model.CommitStatus{
+		State:       job.Status.CommitStatusState(),
+		TargetURL:   setting.AppURL + job.Link(run)[1:],
+		Description: statusDescriptions[job.Status],
+		Context:     fmt.Sprintf("%s / %s (%s)", run.Title, job.Name, run.Event),
+	})
+}
+
+// UpdateJobStatus changes the status of a job and reports it on the commit
+func UpdateJobStatus(ctx context.Context, job *ci_model.Job, status ci_model.Status) error {
+	if err := ci_model.UpdateJobStatus(ctx, job, status); err != nil {
+		return err
+	}
+	run, err := ci_model.GetRunByID(ctx, job.RunID)
+	if err != nil {
+		return err
+	}
+	return createCommitStatus(ctx, run, job)
+}
+
+// CancelRun cancels all jobs of the run which are not done yet
+func CancelRun(ctx context.Context, run *ci_model.Run) error {
+	jobs, err := ci_model.GetJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.Status.IsDone() {
+			continue
+		}
+		if err := UpdateJobStatus(ctx, job, ci_model.StatusCancelled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StopAbandonedJobs fails the running jobs whose runner has not reported on for too long
+func StopAbandonedJobs(ctx context.Context) error {
+	jobs, err := ci_model.FindAbandonedJobs(ctx, setting.CI.RunnerTimeout)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("aborted")
+		default:
+		}
+		log.Trace("Stopping abandoned CI job %d", job.ID)
+		if err := ci_model.AppendJobLog(ctx, job.ID, "\nThe runner stopped reporting on the job.\n"); err != nil {
+			return err
+		}
+		if err := UpdateJobStatus(ctx, job, ci_model.StatusFailure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterRunner registers a new runner with a registration token
+func RegisterRunner(ctx context.Context, opts *ci_module.RegisterRunnerOption) (*ci_model.Runner, error) {
+	token, err := ci_model.GetRunnerTokenByToken(ctx, opts.Token)
+	if err != nil {
+		return nil, err
+	}
+	runner := &ci_model.Runner{
+		Name:   opts.Name,
+		RepoID: token.RepoID,
+		Labels: opts.Labels,
+	}
+	if runner.Labels == nil {
+		runner.Labels = []string{}
+	}
+	return runner, ci_model.NewRunner(ctx, runner)
+}
+
+// FetchJob assigns a waiting job to the runner, it returns nil if there is no job the runner can run
+func FetchJob(ctx context.Context, runner *ci_model.Runner) (*ci_module.FetchedJob, error) {
+	job, err := ci_model.ClaimJob(ctx, runner)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	if err := UpdateJobStatus(ctx, job, ci_model.StatusRunning); err != nil {
+		return nil, err
+	}
+
+	run, err := ci_model.GetRunByID(ctx, job.RunID)
+	if err != nil {
+		return nil, err
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+	payload, err := unmarshalPayload(job.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := expandSecrets(ctx, run.Repo, payload); err != nil {
+		return nil, err
+	}
+	return &ci_module.FetchedJob{
+		ID:         job.ID,
+		Name:       job.Name,
+		Repository: run.Repo.FullName(),
+		Workflow:   run.WorkflowID,
+		Event:      run.Event,
+		Ref:        run.Ref,
+		CommitSHA:  run.CommitSHA,
+		Env:        payload.Env,
+		Steps:      payload.Steps,
+	}, nil
+}
+
+// expandSecrets replaces the references to secrets in the environment and in the scripts of the job
+func expandSecrets(ctx context.Context, repo *repo_model.Repository, payload *ci_module.JobPayload) error {
+	secrets, err := secret_model.GetDecryptedSecrets(ctx, repo.OwnerID, repo.ID)
+	if err != nil {
+		return err
+	}
+	for k, v := range payload.Env {
+		payload.Env[k] = secret_model.ExpandSecrets(v, secrets)
+	}
+	for _, step := range payload.Steps {
+		step.Run = secret_model.ExpandSecrets(step.Run, secrets)
+		for k, v := range step.Env {
+			step.Env[k] = secret_model.ExpandSecrets(v, secrets)
+		}
+	}
+	return nil
+}
+
+// AppendJobLog hides the values of the secrets available to the repository in the output and adds it to the log of the job.
+// The runner sends the output in chunks, so a secret can start at the end of the log and continue in the new output.
+func AppendJobLog(ctx context.Context, job *ci_model.Job, repo *repo_model.Repository, content string) error {
+	secrets, err := secret_model.GetDecryptedSecrets(ctx, repo.OwnerID, repo.ID)
+	if err != nil {
+		return err
+	}
+	maxLen := 0
+	for _, v := range secrets {
+		if len(v) > maxLen {
+			maxLen = len(v)
+		}
+	}
+
+	return db.WithTx(func(ctx context.Context) error {
+		last, err := ci_model.GetLastJobLog(ctx, job.ID)
+		if err != nil {
+			return err
+		}
+		if last == nil || maxLen < 2 {
+			return ci_model.AppendJobLog(ctx, job.ID, secret_model.MaskSecrets(content, secrets))
+		}
+
+		tail := last.Content
+		if len(tail) > maxLen-1 {
+			tail = tail[len(tail)-(maxLen-1):]
+		}
+		masked := secret_model.MaskSecrets(tail+content, secrets)
+		if strings.HasPrefix(masked, tail) {
+			return ci_model.AppendJobLog(ctx, job.ID, masked[len(tail):])
+		}
+		// a secret starts in the last chunk, it is replaced to hide the secret
+		last.Content = last.Content[:len(last.Content)-len(tail)] + masked
+		return ci_model.UpdateJobLog(ctx, last)
+	}, ctx)
+}
+
+func getTriggerUser(ctx context.Context, id int64) (*user_model.User, error) {
+	u, err := user_model.GetUserByIDCtx(ctx, id)
+	if user_model.IsErrUserNotExist(err) {
+		return user_model.NewGhostUser(), nil
+	}
+	return u, err
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	ci_model "code.gitea.io/gitea/models/ci"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	ci_module "code.gitea.io/gitea/modules/ci"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+// maxWorkflowSize is the maximum size of a workflow file which is read
+const maxWorkflowSize = 1024 * 1024
+
+// detectWorkflows creates the runs of the workflows triggered by the event
+func detectWorkflows(ctx context.Context, req *ci_module.DetectionRequest) error {
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, req.RepoID)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if repo.IsEmpty || repo.IsArchived {
+		return nil
+	}
+	doer, err := getTriggerUser(ctx, req.DoerID)
+	if err != nil {
+		return err
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	if req.CommitSHA == "" {
+		if req.CommitSHA, err = gitRepo.GetRefCommitID(req.Ref); err != nil {
+			return err
+		}
+	}
+	commit, err := gitRepo.GetCommit(req.CommitSHA)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.SubTree(ci_module.WorkflowsPath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsRegular() || !ci_module.IsWorkflowFile(entry.Name()) {
+			continue
+		}
+		content, err := readBlob(entry.Blob())
+		if err != nil {
+			return err
+		}
+		workflow, err := ci_module.ParseWorkflow(content)
+		if err != nil {
+			log.Trace("Invalid workflow %s in %-v: %v", entry.Name(), repo, err)
+			// tell the user why nothing happens
+			if err := files_service.CreateCommitStatus(ctx, repo, doer, req.CommitSHA, &git_model.CommitStatus{
+				State:       api.CommitStatusError,
+				Description: fmt.Sprintf("Invalid workflow: %v", err),
+				Context:     entry.Name(),
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		if !workflow.Match(req.Event, req.FilterRef) {
+			continue
+		}
+
+		run := &ci_model.Run{
+			RepoID:        repo.ID,
+			Repo:          repo,
+			WorkflowID:    entry.Name(),
+			Title:         workflow.Name,
+			TriggerUserID: doer.ID,
+			TriggerUser:   doer,
+			Event:         req.Event,
+			Ref:           req.Ref,
+			CommitSHA:     req.CommitSHA,
+		}
+		if run.Title == "" {
+			run.Title = entry.Name()
+		}
+		jobs := make([]*ci_model.Job, 0, len(workflow.Jobs))
+		for _, j := range workflow.Jobs {
+			payload, err := marshalPayload(workflow, j)
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, &ci_model.Job{
+				JobID:   j.ID,
+				Name:    j.Name,
+				RunsOn:  j.RunsOn,
+				Payload: payload,
+			})
+		}
+		if err := ci_model.InsertRun(ctx, run, jobs); err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if err := createCommitStatus(ctx, run, job); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readBlob(blob *git.Blob) ([]byte, error) {
+	if blob.Size() > maxWorkflowSize {
+		return nil, fmt.Errorf("workflow %s is too large", blob.Name())
+	}
+	rd, err := blob.DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return io.ReadAll(rd)
+}
+
+// marshalPayload stores the steps of a job with the environment of the workflow merged into its own
+func marshalPayload(workflow *ci_module.Workflow, job *ci_module.Job) (string, error) {
+	env := make(map[string]string, len(workflow.Env)+len(job.Env))
+	for k, v := range workflow.Env {
+		env[k] = v
+	}
+	for k, v := range job.Env {
+		env[k] = v
+	}
+	buf, err := json.Marshal(&ci_module.JobPayload{
+		Env:   env,
+		Steps: job.Steps,
+	})
+	return string(buf), err
+}
+
+func unmarshalPayload(payload string) (*ci_module.JobPayload, error) {
+	p := &ci_module.JobPayload{}
+	return p, json.Unmarshal([]byte(payload), p)
+}
@@ -9,16 +9,20 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	git_model "code.gitea.io/gitea/models/git"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/auth"
+	"code.gitea.io/gitea/services/automerge"
+	ci_service "code.gitea.io/gitea/services/ci"
 	"code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	packages_service "code.gitea.io/gitea/services/packages"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 func registerUpdateMirrorTask() {
@@ -155,6 +159,54 @@ func registerCleanupPackages() {
 	})
 }
 
+func registerStopAbandonedCIJobs() {
+	RegisterTaskFatal("stop_abandoned_ci_jobs", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 5m",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return ci_service.StopAbandonedJobs(ctx)
+	})
+}
+
+func registerDeleteOldAuditEvents() {
+	RegisterTaskFatal("delete_old_audit_events", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return audit_model.DeleteEventsOlderThan(ctx, setting.Audit.RetentionPeriod)
+	})
+}
+
+func registerMergeScheduledPullRequests() {
+	RegisterTaskFatal("merge_scheduled_pull_requests", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 1m",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return automerge.MergePendingScheduledPullRequests(ctx)
+	})
+}
+
+func registerDeleteExpiredAccessTokens() {
+	type DeleteExpiredAccessTokensConfig struct {
+		BaseConfig
+		NotifyBefore time.Duration
+	}
+	RegisterTaskFatal("delete_expired_access_tokens", &DeleteExpiredAccessTokensConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: true,
+			Schedule:   "@every 1h",
+		},
+		NotifyBefore: 7 * 24 * time.Hour,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		realConfig := config.(*DeleteExpiredAccessTokensConfig)
+		return user_service.NotifyAndDeleteExpiredAccessTokens(ctx, realConfig.NotifyBefore)
+	})
+}
+
 func initBasicTasks() {
 	if setting.Mirror.Enabled {
 		registerUpdateMirrorTask()
@@ -168,7 +220,15 @@ func initBasicTasks() {
 		registerUpdateMigrationPosterID()
 	}
 	registerCleanupHookTaskTable()
+	registerMergeScheduledPullRequests()
+	registerDeleteExpiredAccessTokens()
 	if setting.Packages.Enabled {
 		registerCleanupPackages()
 	}
+	if setting.CI.Enabled {
+		registerStopAbandonedCIJobs()
+	}
+	if setting.Audit.Enabled {
+		registerDeleteOldAuditEvents()
+	}
 }
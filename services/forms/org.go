@@ -74,3 +74,34 @@ func (f *CreateTeamForm) Validate(req *http.Request, errs binding.Errors) bindin
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// PushPolicyForm form for the push policy of an organization
+type PushPolicyForm struct {
+	DryRun               bool
+	MaxFileSize          string // a size such as 10 MiB, parsed by the handler
+	ForbiddenPaths       string
+	ForbiddenExtensions  string
+	RequireSignedCommits bool
+	CommitMessagePattern string `binding:"RegexPattern" locale:"org.settings.push_policy.commit_message_pattern"`
+	AllowedEmailDomains  string
+	SecretPatterns       string
+}
+
+// Validate validates the fields
+func (f *PushPolicyForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// InstallAppForm form for installing an app on an organization
+type InstallAppForm struct {
+	AppName         string `binding:"Required"`
+	AllRepositories bool
+	Repositories    string // the names of the selected repositories, separated by commas
+}
+
+// Validate validates the fields
+func (f *InstallAppForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
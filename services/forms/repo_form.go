@@ -115,24 +115,27 @@ func ParseRemoteAddr(remoteAddr, authUsername, authPassword string) (string, err
 
 // RepoSettingForm form for changing repository settings
 type RepoSettingForm struct {
-	RepoName               string `binding:"Required;AlphaDashDot;MaxSize(100)"`
-	Description            string `binding:"MaxSize(255)"`
-	Website                string `binding:"ValidUrl;MaxSize(255)"`
-	Interval               string
-	MirrorAddress          string
-	MirrorUsername         string
-	MirrorPassword         string
-	LFS                    bool   `form:"mirror_lfs"`
-	LFSEndpoint            string `form:"mirror_lfs_endpoint"`
-	PushMirrorID           string
-	PushMirrorAddress      string
-	PushMirrorUsername     string
-	PushMirrorPassword     string
-	PushMirrorSyncOnCommit bool
-	PushMirrorInterval     string
-	Private                bool
-	Template               bool
-	EnablePrune            bool
+	RepoName                   string `binding:"Required;AlphaDashDot;MaxSize(100)"`
+	Description                string `binding:"MaxSize(255)"`
+	Website                    string `binding:"ValidUrl;MaxSize(255)"`
+	Interval                   string
+	MirrorAddress              string
+	MirrorUsername             string
+	MirrorPassword             string
+	LFS                        bool   `form:"mirror_lfs"`
+	LFSEndpoint                string `form:"mirror_lfs_endpoint"`
+	PushMirrorID               string
+	PushMirrorAddress          string
+	PushMirrorUsername         string
+	PushMirrorPassword         string
+	PushMirrorSyncOnCommit     bool
+	PushMirrorInterval         string
+	PushMirrorRefFilter        string
+	PushMirrorProtectForcePush bool
+	Private                    bool
+	Template                   bool
+	EnablePrune                bool
+	DetectForcePush            bool
 
 	// Advanced settings
 	EnableWiki                            bool
@@ -205,8 +208,11 @@ type ProtectBranchForm struct {
 	BlockOnOutdatedBranch         bool
 	DismissStaleApprovals         bool
 	RequireSignedCommits          bool
+	RequireCodeOwnerApproval      bool
+	EnableMergeQueue              bool
 	ProtectedFilePatterns         string
 	UnprotectedFilePatterns       string
+	MergeWindows                  string
 }
 
 // Validate validates the fields
@@ -263,12 +269,38 @@ func (f WebhookForm) ChooseEvents() bool {
 	return f.Events == "choose_events"
 }
 
+// AddSecretForm form for adding a secret or replacing its value
+type AddSecretForm struct {
+	Name string `binding:"Required;MaxSize(255)"`
+	Data string `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *AddSecretForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AddDeployTokenForm form for adding a deploy token
+type AddDeployTokenForm struct {
+	Name       string `binding:"Required;MaxSize(255)"`
+	IsWritable bool
+	ExpiresAt  string // the date the token expires on, empty if the token never expires
+}
+
+// Validate validates the fields
+func (f *AddDeployTokenForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // NewWebhookForm form for creating web hook
 type NewWebhookForm struct {
 	PayloadURL  string `binding:"Required;ValidUrl"`
 	HTTPMethod  string `binding:"Required;In(POST,GET)"`
 	ContentType int    `binding:"Required"`
 	Secret      string
+	Headers     string
 	WebhookForm
 }
 
@@ -438,6 +470,7 @@ type CreateIssueForm struct {
 	Content             string
 	Files               []string
 	AllowMaintainerEdit bool
+	TemplateFile        string `form:"template_file"`
 }
 
 // Validate validates the fields
@@ -527,6 +560,17 @@ type EditProjectBoardForm struct {
 	Color   string `binding:"MaxSize(7)"`
 }
 
+// AddIssueToProjectForm is a form for adding an issue of any repository to an individual or organization project
+type AddIssueToProjectForm struct {
+	Issue string `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *AddIssueToProjectForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -536,9 +580,10 @@ type EditProjectBoardForm struct {
 
 // CreateMilestoneForm form for creating milestone
 type CreateMilestoneForm struct {
-	Title    string `binding:"Required;MaxSize(50)"`
-	Content  string
-	Deadline string
+	Title      string `binding:"Required;MaxSize(50)"`
+	Content    string
+	Deadline   string
+	TimeBudget int `binding:"Range(0,1000000)"` // in hours
 }
 
 // Validate validates the fields
@@ -598,7 +643,9 @@ type MergePullRequestForm struct {
 	HeadCommitID           string `json:"head_commit_id,omitempty"`
 	ForceMerge             *bool  `json:"force_merge,omitempty"`
 	MergeWhenChecksSucceed bool   `json:"merge_when_checks_succeed,omitempty"`
-	DeleteBranchAfterMerge bool   `json:"delete_branch_after_merge,omitempty"`
+	// unix time the merge is scheduled at, it is done when all checks succeed after this time
+	MergeAt                int64 `json:"merge_at,omitempty"`
+	DeleteBranchAfterMerge bool  `json:"delete_branch_after_merge,omitempty"`
 }
 
 // Validate validates the fields
@@ -368,7 +368,11 @@ func (f *AddKeyForm) Validate(req *http.Request, errs binding.Errors) binding.Er
 
 // NewAccessTokenForm form for creating access token
 type NewAccessTokenForm struct {
-	Name string `binding:"Required;MaxSize(255)"`
+	Name         string `binding:"Required;MaxSize(255)"`
+	Scopes       []string
+	Repository   string
+	Organization string
+	ExpiresAt    string // the date the token expires on, empty if the token never expires
 }
 
 // Validate validates the fields
@@ -377,6 +381,22 @@ func (f *NewAccessTokenForm) Validate(req *http.Request, errs binding.Errors) bi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewAppForm form for registering an app
+type NewAppForm struct {
+	Name          string `binding:"Required;AlphaDashDot;MaxSize(36)"`
+	Description   string `binding:"MaxSize(255)"`
+	HomepageURL   string `binding:"ValidUrl"`
+	Permissions   []string
+	WebhookURL    string `binding:"ValidUrl"`
+	WebhookSecret string
+}
+
+// Validate validates the fields
+func (f *NewAppForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // EditOAuth2ApplicationForm form for editing oauth2 applications
 type EditOAuth2ApplicationForm struct {
 	Name        string `binding:"Required;MaxSize(255)" form:"application_name"`
@@ -155,6 +155,14 @@ func PostLockHandler(ctx *context.Context) {
 		return
 	}
 
+	// the locks are owned by users, the deploy tokens can't create them
+	if ctx.DeployToken() != nil {
+		ctx.JSON(http.StatusForbidden, api.LFSLockError{
+			Message: "Deploy tokens can't create locks",
+		})
+		return
+	}
+
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
 	var req api.LFSLockRequest
@@ -288,6 +296,14 @@ func UnLockHandler(ctx *context.Context) {
 		return
 	}
 
+	// the locks are owned by users, the deploy tokens can't delete them
+	if ctx.DeployToken() != nil {
+		ctx.JSON(http.StatusForbidden, api.LFSLockError{
+			Message: "Deploy tokens can't delete locks",
+		})
+		return
+	}
+
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
 	var req api.LFSLockDeleteRequest
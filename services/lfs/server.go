@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"strings"
 
+	auth_model "code.gitea.io/gitea/models/auth"
 	git_model "code.gitea.io/gitea/models/git"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -488,6 +489,12 @@ func authenticate(ctx *context.Context, repository *repo_model.Repository, autho
 		accessMode = perm.AccessModeWrite
 	}
 
+	// the deploy tokens grant the access to the repositories of their repository or organization
+	if deployToken := ctx.DeployToken(); deployToken != nil {
+		return deployToken.CanAccessRepo(repository.ID, repository.OwnerID) && deployToken.Mode >= accessMode &&
+			repository.UnitEnabledCtx(ctx, unit.TypeCode)
+	}
+
 	// ctx.IsSigned is unnecessary here, this will be checked in perm.CanAccess
 	perm, err := access_model.GetUserRepoPermission(ctx, repository, ctx.Doer)
 	if err != nil {
@@ -495,6 +502,14 @@ func authenticate(ctx *context.Context, repository *repo_model.Repository, autho
 		return false
 	}
 
+	tokenLevel := auth_model.AccessTokenScopeLevelRead
+	if requireWrite {
+		tokenLevel = auth_model.AccessTokenScopeLevelWrite
+	}
+	if !ctx.TokenScopeAllows(auth_model.AccessTokenScopeCategoryRepository, tokenLevel) || !ctx.TokenAllowsRepo(repository.ID, repository.OwnerID) {
+		return false
+	}
+
 	canRead := perm.CanAccess(accessMode, unit.TypeCode)
 	if canRead && (!requireSigned || ctx.IsSigned) {
 		return true
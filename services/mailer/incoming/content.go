@@ -0,0 +1,198 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// isAutoReply tells if the mail was sent automatically, e.g. by an out of office responder.
+// Such mails must not be posted to prevent mail loops.
+func isAutoReply(h mail.Header) bool {
+	if autoSubmitted := strings.ToLower(strings.TrimSpace(h.Get("Auto-Submitted"))); autoSubmitted != "" && autoSubmitted != "no" {
+		return true
+	}
+	if h.Get("X-Autoreply") != "" || h.Get("X-Autorespond") != "" {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(h.Get("Precedence"))) {
+	case "auto_reply", "bulk", "junk", "list":
+		return true
+	}
+	return false
+}
+
+// extractText returns the text of the message body, the plain text part is preferred over the HTML part
+func extractText(msg *mail.Message) (string, error) {
+	plain, htmlText, err := findTextParts(msg.Header, msg.Body)
+	if err != nil {
+		return "", err
+	}
+	if plain != "" {
+		return plain, nil
+	}
+	return htmlText, nil
+}
+
+// findTextParts walks the mime tree and returns the first plain text and the first HTML part converted to text
+func findTextParts(header map[string][]string, body io.Reader) (string, string, error) {
+	get := func(key string) string {
+		if values := header[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(get("Content-Type"))
+	if err != nil {
+		// RFC 2045: the default content type is plain text
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	if disposition, _, _ := mime.ParseMediaType(get("Content-Disposition")); disposition == "attachment" {
+		return "", "", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		var plain, htmlText string
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", "", err
+			}
+			p, h, err := findTextParts(part.Header, part)
+			if err != nil {
+				return "", "", err
+			}
+			if plain == "" {
+				plain = p
+			}
+			if htmlText == "" {
+				htmlText = h
+			}
+		}
+		return plain, htmlText, nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	if label, ok := params["charset"]; ok {
+		if body, err = charset.NewReaderLabel(label, body); err != nil {
+			return "", "", fmt.Errorf("unsupported charset %s: %w", label, err)
+		}
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return "", "", err
+	}
+	if mediaType == "text/html" {
+		return "", htmlToText(content), nil
+	}
+	return string(content), "", nil
+}
+
+// htmlToText converts HTML to plain text, quoted content in blockquote elements is dropped
+func htmlToText(content []byte) string {
+	var sb strings.Builder
+	skipDepth := 0
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return blankLinesPattern.ReplaceAllString(sb.String(), "\n\n")
+		case html.TextToken:
+			if skipDepth == 0 {
+				sb.WriteString(strings.Join(strings.Fields(string(z.Text())), " "))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "blockquote", "style", "script", "head":
+				skipDepth++
+			case "br", "p", "div", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6":
+				sb.WriteString("\n")
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "blockquote", "style", "script", "head":
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			case "p", "div", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6":
+				sb.WriteString("\n")
+			}
+		}
+	}
+}
+
+var (
+	blankLinesPattern = regexp.MustCompile(`\n\s*\n(\s*\n)+`)
+
+	// "On Mon, Oct 10, 2022 at 10:00 AM Alice <alice@example.com> wrote:", the attribution can be wrapped
+	attributionPattern = regexp.MustCompile(`(?s)^On\s.+\swrote:$`)
+	// Outlook starts the quote with a separator or a header block
+	outlookSeparatorPattern = regexp.MustCompile(`^(-{3,}\s*Original Message\s*-{3,}|_{20,})$`)
+	outlookHeaderPattern    = regexp.MustCompile(`^\*?From:\*?\s`)
+)
+
+// stripQuotedText removes the quoted mail and the signature from the reply
+func stripQuotedText(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+
+	result := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "--" {
+			// signature separator
+			break
+		}
+		if outlookSeparatorPattern.MatchString(trimmed) {
+			break
+		}
+		if i > 0 && outlookHeaderPattern.MatchString(trimmed) && strings.TrimSpace(lines[i-1]) == "" {
+			break
+		}
+		if attributionPattern.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, "On ") && i+1 < len(lines) && attributionPattern.MatchString(trimmed+" "+strings.TrimSpace(lines[i+1])) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return strings.TrimSpace(strings.Join(result, "\n"))
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripQuotedText(t *testing.T) {
+	cases := map[string]string{
+		"Looks good to me.\n\nOn Mon, Oct 10, 2022 at 10:00 AM Alice <alice@example.com> wrote:\n> Please review\n> the change": "Looks good to me.",
+		"Looks good to me.\r\n\r\nOn Mon, Oct 10, 2022 at 10:00 AM Alice\r\n<alice@example.com> wrote:\r\n> Please review":      "Looks good to me.",
+		"First\n> quoted\nSecond\n\n-- \nBob\nACME Inc.":                                                                        "First\nSecond",
+		"Agreed\n\n-----Original Message-----\nFrom: Alice":                                                                     "Agreed",
+		"Agreed\n\nFrom: Gitea <gitea@example.com>\nSent: Monday, October 10, 2022 10:00 AM":                                    "Agreed",
+		"From: the start of the line is kept\nbecause it is not a header block":                                                 "From: the start of the line is kept\nbecause it is not a header block",
+		"> only quoted text": "",
+	}
+	for content, expected := range cases {
+		assert.Equal(t, expected, stripQuotedText(content), content)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	cases := map[string]string{
+		"plain": "Content-Type: text/plain; charset=utf-8\r\n\r\nHello",
+		"quoted-printable": "Content-Type: text/plain; charset=iso-8859-1\r\n" +
+			"Content-Transfer-Encoding: quoted-printable\r\n\r\nH=E9llo",
+		"alternative": "Content-Type: multipart/alternative; boundary=b\r\n\r\n" +
+			"--b\r\nContent-Type: text/html\r\n\r\n<p>ignored</p>\r\n" +
+			"--b\r\nContent-Type: text/plain\r\nContent-Transfer-Encoding: base64\r\n\r\nSGVsbG8=\r\n" +
+			"--b--\r\n",
+		"html": "Content-Type: multipart/mixed; boundary=m\r\n\r\n" +
+			"--m\r\nContent-Type: text/html\r\n\r\n<div>Hello<br>World</div><blockquote>quoted</blockquote>\r\n" +
+			"--m\r\nContent-Type: text/plain\r\nContent-Disposition: attachment; filename=a.txt\r\n\r\nattached\r\n" +
+			"--m--\r\n",
+	}
+	expected := map[string]string{
+		"plain":            "Hello",
+		"quoted-printable": "Héllo",
+		"alternative":      "Hello",
+		"html":             "Hello\nWorld",
+	}
+	for name, raw := range cases {
+		msg, err := mail.ReadMessage(strings.NewReader("From: alice@example.com\r\n" + raw))
+		assert.NoError(t, err, name)
+		text, err := extractText(msg)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected[name], strings.TrimSpace(text), name)
+	}
+}
+
+func TestIsAutoReply(t *testing.T) {
+	assert.False(t, isAutoReply(mail.Header{}))
+	assert.False(t, isAutoReply(mail.Header{"Auto-Submitted": {"no"}}))
+	assert.True(t, isAutoReply(mail.Header{"Auto-Submitted": {"auto-replied"}}))
+	assert.True(t, isAutoReply(mail.Header{"Precedence": {"bulk"}}))
+	assert.True(t, isAutoReply(mail.Header{"X-Autoreply": {"yes"}}))
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	comment_service "code.gitea.io/gitea/services/comments"
+	"code.gitea.io/gitea/services/mailer/token"
+)
+
+// rejectError is a permanent failure, the mail server bounces the mail with the message instead of retrying the delivery
+type rejectError struct {
+	message string
+}
+
+func reject(format string, args ...interface{}) error {
+	return &rejectError{message: fmt.Sprintf(format, args...)}
+}
+
+func (err *rejectError) Error() string {
+	return err.message
+}
+
+// handler processes a mail whose token has been verified
+type handler func(ctx context.Context, doer *user_model.User, data int64, msg *mail.Message) error
+
+var handlers = map[token.HandlerType]handler{
+	token.ReplyHandlerType:       replyHandler,
+	token.UnsubscribeHandlerType: unsubscribeHandler,
+}
+
+// deliver processes a mail sent to the reply address with the token
+func deliver(ctx context.Context, tokenString string, data []byte) error {
+	ht, doer, payload, err := token.ExtractToken(ctx, tokenString)
+	if err != nil {
+		if errors.Is(err, token.ErrInvalidToken) || user_model.IsErrUserNotExist(err) {
+			return reject("invalid reply address")
+		}
+		return err
+	}
+
+	h, ok := handlers[ht]
+	if !ok {
+		return reject("invalid reply address")
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return reject("invalid message: %v", err)
+	}
+	if isAutoReply(msg.Header) {
+		log.Trace("Ignoring automatic reply from %s", msg.Header.Get("From"))
+		return nil
+	}
+
+	if !doer.IsActive || doer.ProhibitLogin {
+		return reject("the user is not allowed to sign in")
+	}
+
+	return h(ctx, doer, payload, msg)
+}
+
+// loadIssue loads the issue the user replies to, the user must be allowed to read it
+func loadIssue(ctx context.Context, doer *user_model.User, issueID int64) (*issues_model.Issue, access_model.Permission, error) {
+	issue, err := issues_model.GetIssueByID(ctx, issueID)
+	if err != nil {
+		if issues_model.IsErrIssueNotExist(err) {
+			return nil, access_model.Permission{}, reject("the issue does not exist anymore")
+		}
+		return nil, access_model.Permission{}, err
+	}
+	if err := issue.LoadRepo(ctx); err != nil {
+		return nil, access_model.Permission{}, err
+	}
+
+	perm, err := access_model.GetUserRepoPermission(ctx, issue.Repo, doer)
+	if err != nil {
+		return nil, access_model.Permission{}, err
+	}
+	if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+		return nil, access_model.Permission{}, reject("the user can't access the issue")
+	}
+	return issue, perm, nil
+}
+
+// replyHandler posts the reply as comment, a reply which only says "unsubscribe" unsubscribes the user instead
+func replyHandler(ctx context.Context, doer *user_model.User, issueID int64, msg *mail.Message) error {
+	issue, perm, err := loadIssue(ctx, doer, issueID)
+	if err != nil {
+		return err
+	}
+
+	text, err := extractText(msg)
+	if err != nil {
+		return reject("can't read the message: %v", err)
+	}
+	content := stripQuotedText(text)
+	if content == "" {
+		log.Trace("Ignoring empty reply of %-v to issue %d", doer, issue.ID)
+		return nil
+	}
+	if strings.EqualFold(strings.TrimRight(content, ".!"), "unsubscribe") {
+		return issues_model.CreateOrUpdateIssueWatch(doer.ID, issue.ID, false)
+	}
+
+	if issue.Repo.IsArchived {
+		return reject("the repository is archived")
+	}
+	if issue.IsLocked && !perm.CanWriteIssuesOrPulls(issue.IsPull) && !doer.IsAdmin {
+		return reject("the issue is locked")
+	}
+
+	_, err = comment_service.CreateIssueComment(doer, issue.Repo, issue, content, nil)
+	return err
+}
+
+// unsubscribeHandler stops the notifications of the issue for the user, it is triggered by the List-Unsubscribe header
+func unsubscribeHandler(ctx context.Context, doer *user_model.User, issueID int64, msg *mail.Message) error {
+	issue, _, err := loadIssue(ctx, doer, issueID)
+	if err != nil {
+		return err
+	}
+	return issues_model.CreateOrUpdateIssueWatch(doer.ID, issue.ID, false)
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package incoming receives the replies to notification mails.
+// The mail server delivers the mails sent to the reply-to address with LMTP (RFC 2033).
+package incoming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const commandTimeout = 5 * time.Minute
+
+// tokenFromAddress returns the token of a reply-to address or an empty string if the address doesn't match
+func tokenFromAddress(pattern *regexp.Regexp, address string) string {
+	m := pattern.FindStringSubmatch(address)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+func addressPattern(replyToAddress string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(replyToAddress)
+	quoted = strings.Replace(quoted, regexp.QuoteMeta(setting.IncomingEmailTokenPlaceholder), "([A-Za-z2-7]+)", 1)
+	return regexp.MustCompile("(?i)^" + quoted + "$")
+}
+
+// Init starts the LMTP server if incoming mails are enabled
+func Init() error {
+	if !setting.IncomingEmail.Enabled {
+		return nil
+	}
+
+	network, address := "tcp", setting.IncomingEmail.ListenAddr
+	if filepath.IsAbs(address) {
+		network = "unix"
+		if err := util.Remove(address); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to remove the old LMTP socket %s: %w", address, err)
+		}
+	}
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return fmt.Errorf("unable to listen for incoming mails on %s: %w", address, err)
+	}
+	log.Info("Listening for incoming mails with LMTP on %s:%s", network, address)
+
+	pattern := addressPattern(setting.IncomingEmail.ReplyToAddress)
+	go graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+		go func() {
+			<-ctx.Done()
+			_ = l.Close()
+		}()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					log.Info("LMTP Listener: %s Closed", address)
+					return
+				}
+				log.Error("Unable to accept LMTP connection: %v", err)
+				continue
+			}
+			s := &session{
+				conn:     conn,
+				text:     textproto.NewConn(conn),
+				pattern:  pattern,
+				maxSize:  setting.IncomingEmail.MaximumMessageSize,
+				delivery: deliver,
+			}
+			go s.serve(ctx)
+		}
+	})
+	return nil
+}
+
+// session is a LMTP connection of the mail server
+type session struct {
+	conn     net.Conn
+	text     *textproto.Conn
+	pattern  *regexp.Regexp
+	maxSize  int64
+	delivery func(ctx context.Context, token string, data []byte) error
+
+	hasSender bool
+	tokens    []string
+}
+
+func (s *session) reply(code int, format string, args ...interface{}) {
+	_ = s.text.PrintfLine("%d %s", code, fmt.Sprintf(format, args...))
+}
+
+func (s *session) reset() {
+	s.hasSender = false
+	s.tokens = nil
+}
+
+func (s *session) serve(ctx context.Context) {
+	defer s.text.Close()
+
+	s.reply(220, "%s LMTP Gitea ready", setting.Domain)
+	for {
+		_ = s.conn.SetDeadline(time.Now().Add(commandTimeout))
+		line, err := s.text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i != -1 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		switch strings.ToUpper(verb) {
+		case "LHLO":
+			s.reset()
+			_ = s.text.PrintfLine("250-%s", setting.Domain)
+			_ = s.text.PrintfLine("250-PIPELINING")
+			_ = s.text.PrintfLine("250-8BITMIME")
+			_ = s.text.PrintfLine("250-ENHANCEDSTATUSCODES")
+			_ = s.text.PrintfLine("250 SIZE %d", s.maxSize)
+		case "HELO", "EHLO":
+			s.reply(500, "5.5.1 Use LHLO")
+		case "MAIL":
+			if s.hasSender {
+				s.reply(503, "5.5.1 Sender already specified")
+			} else if _, ok := parsePath(arg, "FROM:"); !ok {
+				s.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+			} else {
+				s.hasSender = true
+				s.reply(250, "2.1.0 OK")
+			}
+		case "RCPT":
+			if !s.hasSender {
+				s.reply(503, "5.5.1 Need MAIL before RCPT")
+			} else if address, ok := parsePath(arg, "TO:"); !ok {
+				s.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+			} else if t := tokenFromAddress(s.pattern, address); t == "" {
+				s.reply(550, "5.1.1 Unknown recipient")
+			} else {
+				s.tokens = append(s.tokens, t)
+				s.reply(250, "2.1.5 OK")
+			}
+		case "DATA":
+			if len(s.tokens) == 0 {
+				s.reply(503, "5.5.1 Need RCPT before DATA")
+				continue
+			}
+			s.reply(354, "Start mail input; end with <CRLF>.<CRLF>")
+			s.data(ctx)
+			s.reset()
+		case "RSET":
+			s.reset()
+			s.reply(250, "2.0.0 OK")
+		case "NOOP":
+			s.reply(250, "2.0.0 OK")
+		case "QUIT":
+			s.reply(221, "2.0.0 Bye")
+			return
+		default:
+			s.reply(500, "5.5.2 Unknown command")
+		}
+	}
+}
+
+// data reads the mail and delivers it, LMTP requires a reply for every recipient
+func (s *session) data(ctx context.Context) {
+	dr := s.text.DotReader()
+	data, err := io.ReadAll(io.LimitReader(dr, s.maxSize+1))
+	if err != nil {
+		return
+	}
+	if int64(len(data)) > s.maxSize {
+		_, _ = io.Copy(io.Discard, dr)
+		for range s.tokens {
+			s.reply(552, "5.3.4 Message too big")
+		}
+		return
+	}
+
+	for _, t := range s.tokens {
+		err := func() error {
+			ctx, _, finished := process.GetManager().AddContext(ctx, "Processing incoming mail")
+			defer finished()
+			return s.delivery(ctx, t, data)
+		}()
+
+		var rejectErr *rejectError
+		switch {
+		case err == nil:
+			s.reply(250, "2.0.0 OK")
+		case errors.As(err, &rejectErr):
+			log.Debug("Rejected incoming mail: %v", err)
+			s.reply(550, "5.7.1 %s", rejectErr.message)
+		default:
+			log.Error("Unable to process incoming mail: %v", err)
+			s.reply(451, "4.3.0 Internal error")
+		}
+	}
+}
+
+// parsePath returns the address of a MAIL or RCPT argument like "FROM:<alice@example.com> SIZE=100"
+func parsePath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", false
+	}
+	end := strings.IndexByte(arg, '>')
+	if end == -1 {
+		return "", false
+	}
+	return arg[1:end], true
+}
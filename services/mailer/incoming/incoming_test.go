@@ -0,0 +1,134 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/services/mailer/token"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenFromAddress(t *testing.T) {
+	pattern := addressPattern("incoming+%{token}@example.com")
+
+	assert.Equal(t, "abc234", tokenFromAddress(pattern, "incoming+abc234@example.com"))
+	assert.Equal(t, "ABC234", tokenFromAddress(pattern, "Incoming+ABC234@Example.com"))
+	assert.Empty(t, tokenFromAddress(pattern, "incoming+@example.com"))
+	assert.Empty(t, tokenFromAddress(pattern, "incoming+abc@example.org"))
+	assert.Empty(t, tokenFromAddress(pattern, "other+abc@example.com"))
+}
+
+func TestSession(t *testing.T) {
+	delivered := map[string]string{}
+	server, client := net.Pipe()
+	s := &session{
+		conn:    server,
+		text:    textproto.NewConn(server),
+		pattern: addressPattern("incoming+%{token}@example.com"),
+		maxSize: 100,
+		delivery: func(ctx context.Context, token string, data []byte) error {
+			if token == "invalid" {
+				return reject("invalid reply address")
+			}
+			delivered[token] = string(data)
+			return nil
+		},
+	}
+	go s.serve(context.Background())
+
+	c := textproto.NewConn(client)
+	defer c.Close()
+	expect := func(code int) {
+		_, _, err := c.ReadResponse(code)
+		assert.NoError(t, err)
+	}
+	send := func(line string, code int) {
+		assert.NoError(t, c.PrintfLine("%s", line))
+		expect(code)
+	}
+
+	expect(220)
+	send("EHLO localhost", 500)
+	send("LHLO localhost", 250)
+	send("RCPT TO:<incoming+abc@example.com>", 503)
+	send("MAIL FROM:<alice@example.com> SIZE=10", 250)
+	send("RCPT TO:<bob@example.com>", 550)
+	send("RCPT TO:<incoming+abc@example.com>", 250)
+	send("RCPT TO:<incoming+invalid@example.com>", 250)
+	send("DATA", 354)
+
+	w := c.DotWriter()
+	_, _ = w.Write([]byte("Subject: Re: test\r\n\r\nHello\r\n.dot\r\n"))
+	assert.NoError(t, w.Close())
+	expect(250)
+	_, message, err := c.ReadResponse(550)
+	assert.NoError(t, err)
+	assert.Contains(t, message, "invalid reply address")
+	assert.Equal(t, "Subject: Re: test\n\nHello\n.dot\n", delivered["abc"])
+
+	send("MAIL FROM:<alice@example.com>", 250)
+	send("RCPT TO:<incoming+big@example.com>", 250)
+	send("DATA", 354)
+	w = c.DotWriter()
+	_, _ = w.Write([]byte(strings.Repeat("x", 101)))
+	assert.NoError(t, w.Close())
+	expect(552)
+	assert.NotContains(t, delivered, "big")
+
+	send("QUIT", 221)
+}
+
+func TestDeliver(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	user5 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+
+	reply := func(ht token.HandlerType, user *user_model.User, issueID int64, body string) error {
+		mail := "From: " + user.Email + "\r\nSubject: Re: issue\r\nContent-Type: text/plain\r\n\r\n" + body
+		return deliver(db.DefaultContext, token.CreateToken(ht, user, issueID), []byte(mail))
+	}
+
+	t.Run("Reply", func(t *testing.T) {
+		assert.NoError(t, reply(token.ReplyHandlerType, user2, 1, "Sounds good\n\nOn Mon, Oct 10, 2022 at 10:00 AM user1 wrote:\n> Can you check?"))
+		unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: 1, PosterID: 2, Type: issues_model.CommentTypeComment, Content: "Sounds good"})
+	})
+
+	t.Run("AutoReply", func(t *testing.T) {
+		mail := "From: " + user2.Email + "\r\nAuto-Submitted: auto-replied\r\n\r\nOut of office"
+		assert.NoError(t, deliver(db.DefaultContext, token.CreateToken(token.ReplyHandlerType, user2, 1), []byte(mail)))
+		unittest.AssertNotExistsBean(t, &issues_model.Comment{IssueID: 1, Content: "Out of office"})
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		assert.NoError(t, reply(token.ReplyHandlerType, user2, 1, "Unsubscribe"))
+		unittest.AssertExistsAndLoadBean(t, &issues_model.IssueWatch{UserID: 2, IssueID: 1, IsWatching: false})
+
+		assert.NoError(t, reply(token.UnsubscribeHandlerType, user5, 1, ""))
+		unittest.AssertExistsAndLoadBean(t, &issues_model.IssueWatch{UserID: 5, IssueID: 1, IsWatching: false})
+	})
+
+	t.Run("NoAccess", func(t *testing.T) {
+		// issue 4 belongs to a private repository of user2
+		err := reply(token.ReplyHandlerType, user5, 4, "Let me in")
+		assert.Error(t, err)
+		assert.IsType(t, &rejectError{}, err)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		err := deliver(db.DefaultContext, "abcdefgh", []byte("Subject: test\r\n\r\ntest"))
+		assert.IsType(t, &rejectError{}, err)
+	})
+}
@@ -31,6 +31,7 @@ import (
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/translation"
+	"code.gitea.io/gitea/services/mailer/token"
 
 	"gopkg.in/gomail.v2"
 )
@@ -273,6 +274,7 @@ func composeIssueCommentMessages(ctx *mailCommentContext, lang string, recipient
 		"ActionType":      actType,
 		"ActionName":      actName,
 		"ReviewComments":  reviewComments,
+		"CanReply":        setting.IncomingEmail.Enabled,
 		"Language":        locale.Language(),
 		// helper
 		"locale":    locale,
@@ -317,6 +319,13 @@ func composeIssueCommentMessages(ctx *mailCommentContext, lang string, recipient
 			msg.SetHeader(key, value)
 		}
 
+		if setting.IncomingEmail.Enabled {
+			replyAddress := replyToAddress(token.CreateToken(token.ReplyHandlerType, recipient, ctx.Issue.ID))
+			unsubscribeAddress := replyToAddress(token.CreateToken(token.UnsubscribeHandlerType, recipient, ctx.Issue.ID))
+			msg.SetHeader("Reply-To", replyAddress)
+			msg.SetHeader("List-Unsubscribe", fmt.Sprintf("<mailto:%s>, <%s>", unsubscribeAddress, ctx.Issue.HTMLURL()))
+		}
+
 		msgs = append(msgs, msg)
 	}
 
@@ -350,6 +359,11 @@ func createReference(issue *issues_model.Issue, comment *issues_model.Comment, a
 	return fmt.Sprintf("%s/%s/%d%s@%s", issue.Repo.FullName(), path, issue.Index, extra, setting.Domain)
 }
 
+// replyToAddress returns the address replies with the token are sent to
+func replyToAddress(t string) string {
+	return strings.Replace(setting.IncomingEmail.ReplyToAddress, setting.IncomingEmailTokenPlaceholder, t, 1)
+}
+
 func generateAdditionalHeaders(ctx *mailCommentContext, reason string, recipient *user_model.User) map[string]string {
 	repo := ctx.Issue.Repo
 
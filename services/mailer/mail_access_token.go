@@ -0,0 +1,52 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/translation"
+)
+
+const (
+	mailNotifyAccessTokenExpiry base.TplName = "notify/access_token_expiry"
+)
+
+// MailAccessTokensExpiry notifies the user that some of their personal access tokens are about to expire
+func MailAccessTokensExpiry(u *user_model.User, tokens []*models.AccessToken) error {
+	if setting.MailService == nil || !u.IsActive || u.ProhibitLogin {
+		return nil
+	}
+
+	locale := translation.NewLocale(u.Language)
+	subject := locale.Tr("mail.access_token.expiry.subject", len(tokens))
+	data := map[string]interface{}{
+		"DisplayName": u.DisplayName(),
+		"Tokens":      tokens,
+		"Link":        setting.AppURL + "user/settings/applications",
+		"Subject":     subject,
+		"Language":    locale.Language(),
+		// helper
+		"locale":    locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyAccessTokenExpiry), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, access token expiry notification", u.ID)
+	SendAsync(msg)
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/translation"
+)
+
+const (
+	mailMirrorForcePush base.TplName = "notify/mirror_force_push"
+)
+
+// MailMirrorForcePush sends a notification to the repo watchers when a ref of the pull mirror was force-pushed upstream
+func MailMirrorForcePush(ctx context.Context, repo *repo_model.Repository, backup *repo_model.MirrorBackup) {
+	if setting.MailService == nil {
+		// No mail service configured
+		return
+	}
+
+	watcherIDList, err := repo_model.GetRepoWatchersIDs(ctx, repo.ID)
+	if err != nil {
+		log.Error("GetRepoWatchersIDs(%d): %v", repo.ID, err)
+		return
+	}
+
+	recipients, err := user_model.GetMaileableUsersByIDs(watcherIDList, false)
+	if err != nil {
+		log.Error("user_model.GetMaileableUsersByIDs: %v", err)
+		return
+	}
+
+	langMap := make(map[string][]string)
+	for _, user := range recipients {
+		langMap[user.Language] = append(langMap[user.Language], user.Email)
+	}
+
+	for lang, tos := range langMap {
+		if err := mailMirrorForcePushPerLang(lang, tos, repo, backup); err != nil {
+			log.Error("mailMirrorForcePush: %v", err)
+		}
+	}
+}
+
+func mailMirrorForcePushPerLang(lang string, tos []string, repo *repo_model.Repository, backup *repo_model.MirrorBackup) error {
+	locale := translation.NewLocale(lang)
+
+	refName := git.RefEndName(backup.RefName)
+	subject := locale.Tr("mail.repo.mirror.force_push.subject", refName, repo.FullName())
+	data := map[string]interface{}{
+		"Repo":      repo,
+		"Backup":    backup,
+		"RefName":   refName,
+		"Link":      repo.HTMLURL(),
+		"CommitURL": repo.HTMLURL() + "/commit/" + backup.OldCommitID,
+		"Subject":   subject,
+		"Language":  locale.Language(),
+		// helper
+		"locale":    locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailMirrorForcePush), data); err != nil {
+		return err
+	}
+
+	msgs := make([]*Message, 0, len(tos))
+	for _, to := range tos {
+		msg := NewMessage([]string{to}, subject, content.String())
+		msg.Info = fmt.Sprintf("Repo: %d, mirror force-push notification", repo.ID)
+		msgs = append(msgs, msg)
+	}
+	SendAsyncs(msgs)
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer/token"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -122,6 +123,53 @@ func TestComposeIssueMessage(t *testing.T) {
 	assert.Equal(t, "<user2/repo1/issues/1@localhost>", messageID[0], "Message-ID header doesn't match")
 }
 
+func TestComposeIssueMessageReplyTo(t *testing.T) {
+	doer, _, issue, _ := prepareMailerTest(t)
+
+	setting.IncomingEmail.Enabled = true
+	setting.IncomingEmail.ReplyToAddress = "incoming+%{token}@localhost"
+	defer func() {
+		setting.IncomingEmail.Enabled = false
+		setting.IncomingEmail.ReplyToAddress = ""
+	}()
+
+	subjectTemplates = texttmpl.Must(texttmpl.New("issue/new").Parse(subjectTpl))
+	bodyTemplates = template.Must(template.New("issue/new").Parse(bodyTpl))
+
+	recipients := []*user_model.User{
+		unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}),
+		unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5}),
+	}
+	msgs, err := composeIssueCommentMessages(&mailCommentContext{
+		Context: db.DefaultContext,
+		Issue:   issue, Doer: doer, ActionType: models.ActionCreateIssue,
+		Content: "test body",
+	}, "en-US", recipients, false, "issue create")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+
+	for i, msg := range msgs {
+		gomailMsg := msg.ToMessage()
+		replyTo := gomailMsg.GetHeader("Reply-To")
+		if assert.Len(t, replyTo, 1) {
+			assert.True(t, strings.HasPrefix(replyTo[0], "incoming+"))
+			assert.True(t, strings.HasSuffix(replyTo[0], "@localhost"))
+
+			ht, user, issueID, err := token.ExtractToken(db.DefaultContext, replyTo[0][len("incoming+"):len(replyTo[0])-len("@localhost")])
+			assert.NoError(t, err)
+			assert.Equal(t, token.ReplyHandlerType, ht)
+			assert.Equal(t, recipients[i].ID, user.ID)
+			assert.Equal(t, issue.ID, issueID)
+		}
+
+		unsubscribe := gomailMsg.GetHeader("List-Unsubscribe")
+		if assert.Len(t, unsubscribe, 1) {
+			assert.True(t, strings.HasPrefix(unsubscribe[0], "<mailto:incoming+"))
+			assert.True(t, strings.HasSuffix(unsubscribe[0], ">, <"+issue.HTMLURL()+">"))
+		}
+	}
+}
+
 func TestTemplateSelection(t *testing.T) {
 	doer, repo, issue, comment := prepareMailerTest(t)
 	recipients := []*user_model.User{{Name: "Test", Email: "test@gitea.com"}}
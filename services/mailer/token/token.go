@@ -0,0 +1,91 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package token creates and verifies the tokens which are embedded in the reply-to address of notification mails.
+package token
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// HandlerType tells what a reply to a mail does
+type HandlerType byte
+
+// The known handler types, the values are part of the tokens and must not be changed
+const (
+	// ReplyHandlerType posts the reply as comment on the issue
+	ReplyHandlerType HandlerType = 1
+	// UnsubscribeHandlerType unsubscribes the user from the issue
+	UnsubscribeHandlerType HandlerType = 2
+)
+
+const (
+	tokenVersion = 1
+	macLength    = 16
+)
+
+// The tokens are encoded case insensitive because mail servers may change the case of the local part
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidToken is returned if the token can't be decoded or its signature is wrong
+var ErrInvalidToken = errors.New("invalid token")
+
+// CreateToken creates a token for the user which triggers the handler with the data, e.g. the id of an issue.
+// The token is signed with the secret key so it can't be forged.
+func CreateToken(ht HandlerType, user *user_model.User, data int64) string {
+	payload := make([]byte, 2+2*binary.MaxVarintLen64, 2+2*binary.MaxVarintLen64+macLength)
+	payload[0] = tokenVersion
+	payload[1] = byte(ht)
+	n := 2 + binary.PutUvarint(payload[2:], uint64(user.ID))
+	n += binary.PutVarint(payload[n:], data)
+	payload = payload[:n]
+	payload = append(payload, sign(payload)...)
+
+	return strings.ToLower(encoding.EncodeToString(payload))
+}
+
+// ExtractToken verifies the token and returns the handler, the user and the data of it
+func ExtractToken(ctx context.Context, token string) (HandlerType, *user_model.User, int64, error) {
+	payload, err := encoding.DecodeString(strings.ToUpper(token))
+	if err != nil || len(payload) < 2+macLength || payload[0] != tokenVersion {
+		return 0, nil, 0, ErrInvalidToken
+	}
+
+	content, mac := payload[:len(payload)-macLength], payload[len(payload)-macLength:]
+	if !hmac.Equal(mac, sign(content)) {
+		return 0, nil, 0, ErrInvalidToken
+	}
+
+	ht := HandlerType(content[1])
+	uid, n := binary.Uvarint(content[2:])
+	if n <= 0 {
+		return 0, nil, 0, ErrInvalidToken
+	}
+	data, m := binary.Varint(content[2+n:])
+	if m <= 0 || 2+n+m != len(content) {
+		return 0, nil, 0, ErrInvalidToken
+	}
+
+	user, err := user_model.GetUserByIDCtx(ctx, int64(uid))
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	return ht, user, data, nil
+}
+
+func sign(content []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(setting.SecretKey))
+	_, _ = mac.Write([]byte("incoming-email-token"))
+	_, _ = mac.Write(content)
+	return mac.Sum(nil)[:macLength]
+}
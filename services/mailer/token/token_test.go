@@ -0,0 +1,52 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m, &unittest.TestOptions{
+		GiteaRootPath: filepath.Join("..", "..", ".."),
+		FixtureFiles:  []string{"user.yml"},
+	})
+}
+
+func TestToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	token := CreateToken(ReplyHandlerType, user, 1234)
+	assert.Equal(t, strings.ToLower(token), token)
+	assert.LessOrEqual(t, len(token), 50)
+
+	for _, tk := range []string{token, strings.ToUpper(token)} {
+		ht, u, data, err := ExtractToken(db.DefaultContext, tk)
+		assert.NoError(t, err)
+		assert.Equal(t, ReplyHandlerType, ht)
+		assert.Equal(t, user.ID, u.ID)
+		assert.EqualValues(t, 1234, data)
+	}
+
+	// the signature covers the handler and the data
+	tampered := CreateToken(UnsubscribeHandlerType, user, 1234)
+	tampered = token[:len(token)-26] + tampered[len(tampered)-26:]
+	_, _, _, err := ExtractToken(db.DefaultContext, tampered)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	for _, tk := range []string{"", "abc", "!!!!"} {
+		_, _, _, err := ExtractToken(db.DefaultContext, tk)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	}
+}
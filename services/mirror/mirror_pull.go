@@ -155,6 +155,15 @@ func parseRemoteUpdateOutput(output string) []*mirrorSyncResult {
 	return results
 }
 
+// excludeMirrorBackups returns the git arguments which exclude the backups of force-pushed refs from the mirror refspec,
+// otherwise they would be pruned. Negative refspecs require git 2.29.
+func excludeMirrorBackups(m *repo_model.Mirror) []string {
+	if git.CheckGitVersionAtLeast("2.29") != nil {
+		return nil
+	}
+	return []string{"-c", "remote." + m.GetRemoteName() + ".fetch=^" + git.MirrorBackupPrefix + "*"}
+}
+
 func pruneBrokenReferences(ctx context.Context,
 	m *repo_model.Mirror,
 	repoPath string,
@@ -169,7 +178,7 @@ func pruneBrokenReferences(ctx context.Context,
 
 	stderrBuilder.Reset()
 	stdoutBuilder.Reset()
-	pruneErr := git.NewCommand(ctx, "remote", "prune", m.GetRemoteName()).
+	pruneErr := git.NewCommand(ctx, append(excludeMirrorBackups(m), "remote", "prune", m.GetRemoteName())...).
 		SetDescription(fmt.Sprintf("Mirror.runSync %ssPrune references: %s ", wiki, m.Repo.FullName())).
 		Run(&git.RunOpts{
 			Timeout: timeout,
@@ -204,7 +213,7 @@ func runSync(ctx context.Context, m *repo_model.Mirror) ([]*mirrorSyncResult, bo
 
 	log.Trace("SyncMirrors [repo: %-v]: running git remote update...", m.Repo)
 
-	gitArgs := []string{"remote", "update"}
+	gitArgs := append(excludeMirrorBackups(m), "remote", "update")
 	if m.EnablePrune {
 		gitArgs = append(gitArgs, "--prune")
 	}
@@ -216,6 +225,15 @@ func runSync(ctx context.Context, m *repo_model.Mirror) ([]*mirrorSyncResult, bo
 		return nil, false
 	}
 
+	var oldRefs map[string]string
+	if m.DetectForcePush {
+		var err error
+		if oldRefs, err = getBranchesAndTags(ctx, repoPath); err != nil {
+			log.Error("SyncMirrors [repo: %-v]: failed to list the refs: %v", m.Repo, err)
+			return nil, false
+		}
+	}
+
 	stdoutBuilder := strings.Builder{}
 	stderrBuilder := strings.Builder{}
 	if err := git.NewCommand(ctx, gitArgs...).
@@ -279,6 +297,13 @@ func runSync(ctx context.Context, m *repo_model.Mirror) ([]*mirrorSyncResult, bo
 		log.Error("SyncMirrors [repo: %-v]: %v", m.Repo, err)
 	}
 
+	if oldRefs != nil {
+		log.Trace("SyncMirrors [repo: %-v]: detecting force-pushed refs...", m.Repo)
+		if err := backupForcePushedRefs(ctx, m, oldRefs); err != nil {
+			log.Error("SyncMirrors [repo: %-v]: failed to backup force-pushed refs: %v", m.Repo, err)
+		}
+	}
+
 	gitRepo, err := git.OpenRepository(ctx, repoPath)
 	if err != nil {
 		log.Error("SyncMirrors [repo: %-v]: failed to OpenRepository: %v", m.Repo, err)
@@ -523,6 +548,77 @@ func SyncPullMirror(ctx context.Context, repoID int64) bool {
 	return true
 }
 
+// getBranchesAndTags returns the object ids of the branches and tags by ref name
+func getBranchesAndTags(ctx context.Context, repoPath string) (map[string]string, error) {
+	stdout, _, err := git.NewCommand(ctx, "for-each-ref", "--format=%(objectname) %(refname)", git.BranchPrefix, git.TagPrefix).RunStdString(&git.RunOpts{Dir: repoPath})
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if objectID, refName, ok := strings.Cut(line, " "); ok {
+			refs[refName] = objectID
+		}
+	}
+	return refs, nil
+}
+
+// backupForcePushedRefs compares the refs before the sync with the synced refs.
+// A branch whose old tip isn't an ancestor of its new tip or a tag which was moved has been force-pushed upstream,
+// the old tip is preserved under a backup ref, the event is recorded and the watchers of the repository are notified.
+func backupForcePushedRefs(ctx context.Context, m *repo_model.Mirror, oldRefs map[string]string) error {
+	repoPath := m.Repo.RepoPath()
+	newRefs, err := getBranchesAndTags(ctx, repoPath)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for refName, oldID := range oldRefs {
+		newID, ok := newRefs[refName]
+		if !ok || newID == oldID {
+			// deleted refs are handled by the prune option of the mirror
+			continue
+		}
+		if strings.HasPrefix(refName, git.BranchPrefix) {
+			_, _, err := git.NewCommand(ctx, "merge-base", "--is-ancestor", oldID, newID).RunStdString(&git.RunOpts{Dir: repoPath})
+			if err == nil {
+				// fast-forward
+				continue
+			}
+			if !err.IsExitCode(1) {
+				return err
+			}
+		}
+
+		backup := &repo_model.MirrorBackup{
+			RepoID:        m.RepoID,
+			RefName:       refName,
+			BackupRefName: fmt.Sprintf("%s%d/%s", git.MirrorBackupPrefix, now, strings.TrimPrefix(refName, "refs/")),
+			OldCommitID:   oldID,
+			NewCommitID:   newID,
+		}
+		if _, _, err := git.NewCommand(ctx, "update-ref", backup.BackupRefName, oldID).RunStdString(&git.RunOpts{Dir: repoPath}); err != nil {
+			return err
+		}
+		if err := repo_model.InsertMirrorBackup(ctx, backup); err != nil {
+			return err
+		}
+
+		log.Trace("SyncMirrors [repo: %-v]: %s was force-pushed upstream, preserved %s as %s", m.Repo, refName, oldID, backup.BackupRefName)
+		notification.NotifyMirrorForcePush(m.Repo, backup)
+	}
+	return nil
+}
+
+// DeleteMirrorBackup removes the backup ref of a force-pushed ref and its record
+func DeleteMirrorBackup(ctx context.Context, repo *repo_model.Repository, backup *repo_model.MirrorBackup) error {
+	if _, _, err := git.NewCommand(ctx, "update-ref", "-d", backup.BackupRefName).RunStdString(&git.RunOpts{Dir: repo.RepoPath()}); err != nil {
+		return err
+	}
+	return repo_model.DeleteMirrorBackup(ctx, backup)
+}
+
 func checkAndUpdateEmptyRepository(m *repo_model.Mirror, gitRepo *git.Repository, results []*mirrorSyncResult) bool {
 	if !m.Repo.IsEmpty {
 		return true
@@ -112,6 +112,13 @@ func SyncPushMirror(ctx context.Context, mirrorID int64) bool {
 	if err != nil {
 		log.Error("SyncPushMirror [mirror: %d][repo: %-v]: %v", m.ID, m.Repo, err)
 		m.LastError = stripExitStatus.ReplaceAllLiteralString(err.Error(), "")
+
+		// the errors of the refs which could not be pushed have already been recorded
+		if !errors.Is(err, errRefsNotPushed) {
+			if err := repo_model.InsertPushMirrorSyncErrors(ctx, m, &repo_model.PushMirrorSyncError{Error: m.LastError}); err != nil {
+				log.Error("InsertPushMirrorSyncErrors [%d]: %v", m.ID, err)
+			}
+		}
 	}
 
 	m.LastUpdateUnix = timeutil.TimeStampNow()
@@ -130,7 +137,7 @@ func SyncPushMirror(ctx context.Context, mirrorID int64) bool {
 func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
 
-	performPush := func(path string) error {
+	performPush := func(path string, isWiki bool) error {
 		remoteURL, err := git.GetRemoteURL(ctx, path, m.RemoteName)
 		if err != nil {
 			log.Error("GetRemoteAddress(%s) Error %v", path, err)
@@ -156,6 +163,10 @@ func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 
 		log.Trace("Pushing %s mirror[%d] remote %s", path, m.ID, m.RemoteName)
 
+		if !isWiki {
+			return pushRefs(ctx, m, path, timeout)
+		}
+
 		if err := git.Push(ctx, path, git.PushOptions{
 			Remote:  m.RemoteName,
 			Force:   true,
@@ -170,7 +181,7 @@ func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 		return nil
 	}
 
-	err := performPush(m.Repo.RepoPath())
+	err := performPush(m.Repo.RepoPath(), false)
 	if err != nil {
 		return err
 	}
@@ -179,7 +190,7 @@ func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 		wikiPath := m.Repo.WikiPath()
 		_, err := git.GetRemoteAddress(ctx, wikiPath, m.RemoteName)
 		if err == nil {
-			err := performPush(wikiPath)
+			err := performPush(wikiPath, true)
 			if err != nil {
 				return err
 			}
@@ -191,6 +202,124 @@ func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 	return nil
 }
 
+var errRefsNotPushed = errors.New("refs could not be pushed")
+
+// pushRefs pushes the branches and tags of the repository and records the result of every ref.
+// A mirror without ref filter and force-push protection is pushed with "git push --mirror".
+func pushRefs(ctx context.Context, m *repo_model.PushMirror, path string, timeout time.Duration) error {
+	gitRepo, err := git.OpenRepository(ctx, path)
+	if err != nil {
+		log.Error("OpenRepository: %v", err)
+		return errors.New("Unexpected error")
+	}
+	defer gitRepo.Close()
+
+	localRefs, err := gitRepo.GetRefs()
+	if err != nil {
+		log.Error("GetRefs: %v", err)
+		return errors.New("Unexpected error")
+	}
+	commits := make(map[string]string, len(localRefs))
+	for _, ref := range localRefs {
+		commits[ref.Name] = ref.Object.String()
+	}
+
+	patterns, err := repo_model.ParsePushMirrorRefFilter(m.RefFilter)
+	if err != nil {
+		return err
+	}
+	syncedRefs, err := repo_model.GetPushMirrorRefs(ctx, m.ID)
+	if err != nil {
+		return err
+	}
+
+	opts := git.PushOptions{
+		Remote:  m.RemoteName,
+		Timeout: timeout,
+	}
+	var refspecs []string
+	// the refs which don't match the filter anymore are not mirrored, forget their status
+	var deletedRefNames []string
+	if !m.IsFiltered() {
+		opts.Force = true
+		opts.Mirror = true
+	} else {
+		prefix := "+"
+		if m.ProtectForcePush {
+			prefix = ""
+		}
+		for _, ref := range localRefs {
+			if repo_model.MatchPushMirrorRef(patterns, ref.Name) {
+				refspecs = append(refspecs, prefix+ref.Name+":"+ref.Name)
+			}
+		}
+		for _, ref := range syncedRefs {
+			if !repo_model.MatchPushMirrorRef(patterns, ref.RefName) {
+				deletedRefNames = append(deletedRefNames, ref.RefName)
+			} else if _, ok := commits[ref.RefName]; !ok && !m.ProtectForcePush {
+				// the ref has been deleted locally
+				refspecs = append(refspecs, ":"+ref.RefName)
+			}
+		}
+		if len(refspecs) == 0 {
+			return repo_model.UpdatePushMirrorRefs(ctx, m, nil, deletedRefNames)
+		}
+	}
+
+	results, err := git.PushRefs(ctx, path, opts, refspecs...)
+	if err != nil {
+		log.Error("Error pushing %s mirror[%d] remote %s: %v", path, m.ID, m.RemoteName, err)
+		return util.SanitizeErrorCredentialURLs(err)
+	}
+
+	refs := make([]*repo_model.PushMirrorRef, 0, len(results))
+	var syncErrors []*repo_model.PushMirrorSyncError
+	var failed []string
+	for _, result := range results {
+		if result.IsFailed() {
+			failed = append(failed, fmt.Sprintf("%s (%s)", result.To, result.Error()))
+		}
+		if !repo_model.MatchPushMirrorRef(patterns, result.To) {
+			continue
+		}
+		if result.IsDeleted() {
+			deletedRefNames = append(deletedRefNames, result.To)
+			continue
+		}
+
+		ref := &repo_model.PushMirrorRef{
+			RefName: result.To,
+			Status:  repo_model.PushMirrorRefStatusSynced,
+		}
+		if result.IsFailed() {
+			ref.Status = repo_model.PushMirrorRefStatusFailed
+			if result.Summary == "[rejected]" || result.Summary == "[remote rejected]" {
+				ref.Status = repo_model.PushMirrorRefStatusRejected
+			}
+			ref.Error = result.Error()
+			syncErrors = append(syncErrors, &repo_model.PushMirrorSyncError{
+				RefName: ref.RefName,
+				Error:   ref.Error,
+			})
+		} else {
+			ref.CommitID = commits[result.From]
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := repo_model.UpdatePushMirrorRefs(ctx, m, refs, deletedRefNames); err != nil {
+		return err
+	}
+	if err := repo_model.InsertPushMirrorSyncErrors(ctx, m, syncErrors...); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", errRefsNotPushed, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
 func pushAllLFSObjects(ctx context.Context, gitRepo *git.Repository, lfsClient lfs.Client) error {
 	contentStore := lfs.NewContentStore()
 
@@ -5,16 +5,24 @@
 package org
 
 import (
+	"context"
 	"fmt"
 
 	"code.gitea.io/gitea/models"
+	app_model "code.gitea.io/gitea/models/app"
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
+	project_model "code.gitea.io/gitea/models/project"
 	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
+	audit_service "code.gitea.io/gitea/services/audit"
 )
 
 // DeleteOrganization completely and permanently deletes everything of organization.
@@ -40,6 +48,30 @@ func DeleteOrganization(org *organization.Organization) error {
 		return models.ErrUserOwnPackages{UID: org.ID}
 	}
 
+	if err := project_model.DeleteProjectsByOwnerIDCtx(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteProjectsByOwnerIDCtx: %v", err)
+	}
+
+	if err := secret_model.DeleteSecretsByOwnerID(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteSecretsByOwnerID: %v", err)
+	}
+
+	if err := auth_model.DeleteDeployTokensByOwnerID(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteDeployTokensByOwnerID: %v", err)
+	}
+
+	if err := app_model.DeleteInstallationsByOwnerID(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteInstallationsByOwnerID: %v", err)
+	}
+
+	if err := git_model.DeletePushPolicy(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeletePushPolicy: %v", err)
+	}
+
+	if err := asymkey_model.DeletePackageSigningKeysByOwnerID(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeletePackageSigningKeysByOwnerID: %v", err)
+	}
+
 	if err := organization.DeleteOrganization(ctx, org); err != nil {
 		return fmt.Errorf("DeleteOrganization: %v", err)
 	}
@@ -66,3 +98,18 @@ func DeleteOrganization(org *organization.Organization) error {
 
 	return nil
 }
+
+// RemoveOrgUser removes the user from the organization and records that the user left each team of the organization
+func RemoveOrgUser(ctx context.Context, doer *user_model.User, remoteAddr string, org *organization.Organization, member *user_model.User) error {
+	teams, err := organization.GetUserOrgTeams(ctx, org.ID, member.ID)
+	if err != nil {
+		return fmt.Errorf("GetUserOrgTeams: %v", err)
+	}
+	if err := models.RemoveOrgUser(org.ID, member.ID); err != nil {
+		return err
+	}
+	for _, t := range teams {
+		audit_service.RecordTeamMember(ctx, doer, remoteAddr, t, member, false)
+	}
+	return nil
+}
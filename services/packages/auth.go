@@ -10,6 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"code.gitea.io/gitea/models"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
 
@@ -19,9 +22,17 @@ import (
 type packageClaims struct {
 	jwt.RegisteredClaims
 	UserID int64
+	// the scope and the restriction of the personal access token the user authenticated with
+	Scope  auth_model.AccessTokenScope `json:",omitempty"`
+	RepoID int64                       `json:",omitempty"`
+	OrgID  int64                       `json:",omitempty"`
+	// the deploy token the request was authenticated with
+	DeployTokenID int64 `json:",omitempty"`
 }
 
-func CreateAuthorizationToken(u *user_model.User) (string, error) {
+// CreateAuthorizationToken creates an authorization token for the user, the token keeps the scope and the restriction
+// of the personal access token or the deploy token if the user authenticated with one
+func CreateAuthorizationToken(u *user_model.User, accessToken *models.AccessToken, deployToken *auth_model.DeployToken) (string, error) {
 	now := time.Now()
 
 	claims := packageClaims{
@@ -31,6 +42,17 @@ func CreateAuthorizationToken(u *user_model.User) (string, error) {
 		},
 		UserID: u.ID,
 	}
+	if accessToken != nil {
+		claims.Scope = accessToken.Scope
+		claims.RepoID = accessToken.RepoID
+		claims.OrgID = accessToken.OrgID
+		if accessToken.ExpiresUnix > 0 && accessToken.ExpiresUnix.AsTime().Before(claims.ExpiresAt.Time) {
+			claims.ExpiresAt = jwt.NewNumericDate(accessToken.ExpiresUnix.AsTime())
+		}
+	}
+	if deployToken != nil {
+		claims.DeployTokenID = deployToken.ID
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString([]byte(setting.SecretKey))
@@ -41,10 +63,13 @@ func CreateAuthorizationToken(u *user_model.User) (string, error) {
 	return tokenString, nil
 }
 
-func ParseAuthorizationToken(req *http.Request) (int64, error) {
+// ParseAuthorizationToken returns the user of the authorization token and the scope and the restriction
+// of the personal access token it was created with, nil if it wasn't created with a personal access token,
+// and the deploy token it was created with, nil if it wasn't created with a deploy token
+func ParseAuthorizationToken(req *http.Request) (int64, *models.AccessToken, *auth_model.DeployToken, error) {
 	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
 	if len(parts) != 2 {
-		return 0, fmt.Errorf("no token")
+		return 0, nil, nil, fmt.Errorf("no token")
 	}
 
 	token, err := jwt.ParseWithClaims(parts[1], &packageClaims{}, func(t *jwt.Token) (interface{}, error) {
@@ -54,13 +79,28 @@ func ParseAuthorizationToken(req *http.Request) (int64, error) {
 		return []byte(setting.SecretKey), nil
 	})
 	if err != nil {
-		return 0, err
+		return 0, nil, nil, err
 	}
 
 	c, ok := token.Claims.(*packageClaims)
 	if !token.Valid || !ok {
-		return 0, fmt.Errorf("invalid token claim")
+		return 0, nil, nil, fmt.Errorf("invalid token claim")
+	}
+
+	// the deploy token is loaded again, it may have been deleted or have expired since
+	if c.DeployTokenID != 0 {
+		deployToken, err := auth_model.GetDeployTokenByID(db.DefaultContext, c.DeployTokenID)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		if deployToken.IsExpired() {
+			return 0, nil, nil, fmt.Errorf("expired deploy token")
+		}
+		return c.UserID, nil, deployToken, nil
 	}
 
-	return c.UserID, nil
+	if c.Scope == "" {
+		return c.UserID, nil, nil, nil
+	}
+	return c.UserID, &models.AccessToken{UID: c.UserID, Scope: c.Scope, RepoID: c.RepoID, OrgID: c.OrgID}, nil, nil
 }
@@ -0,0 +1,243 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/clearsign"
+)
+
+// PoolFilePath returns the path of a package file relative to the repository root
+func PoolFilePath(distribution, component, filename string) string {
+	return fmt.Sprintf("pool/%s/%s/%s", distribution, component, filename)
+}
+
+// PackageFilename returns the name of the file of a package version for an architecture
+func PackageFilename(name, version, architecture string) string {
+	return fmt.Sprintf("%s_%s_%s.deb", name, version, architecture)
+}
+
+// CompositeKey returns the composite key of the package files of a distribution component
+func CompositeKey(distribution, component string) string {
+	return distribution + "|" + component
+}
+
+// GetSigningKey returns the key used to sign the repository of the owner
+func GetSigningKey(ctx context.Context, owner *user_model.User) (*asymkey_model.PackageSigningKey, error) {
+	return asymkey_model.GetOrCreatePackageSigningKey(ctx, owner, string(packages_model.TypeDebian))
+}
+
+// Repository is the generated index of a distribution
+type Repository struct {
+	Distribution  string
+	Components    []string
+	Architectures []string
+	// Indexes contains the Packages files by their path relative to the distribution directory
+	Indexes map[string][]byte
+	Release []byte
+}
+
+type indexFile struct {
+	component    string
+	architecture string
+	control      string
+	filename     string
+	blob         *packages_model.PackageBlob
+}
+
+// BuildRepository generates the Packages and Release files of a distribution.
+// The output only depends on the stored packages, so repeated calls return identical files.
+func BuildRepository(ctx context.Context, owner *user_model.User, distribution string) (*Repository, error) {
+	pfs, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+		OwnerID:     owner.ID,
+		PackageType: string(packages_model.TypeDebian),
+		Properties: map[string]string{
+			debian_module.PropertyDistribution: distribution,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pfs) == 0 {
+		return nil, packages_model.ErrPackageNotExist
+	}
+
+	var latest timeutil.TimeStamp
+	files := make([]*indexFile, 0, len(pfs))
+	components := make(map[string]bool)
+	architectures := make(map[string]bool)
+	for _, pf := range pfs {
+		pfd, err := packages_model.GetPackageFileDescriptor(ctx, pf)
+		if err != nil {
+			return nil, err
+		}
+		f := &indexFile{
+			component:    pfd.Properties.GetByName(debian_module.PropertyComponent),
+			architecture: pfd.Properties.GetByName(debian_module.PropertyArchitecture),
+			control:      pfd.Properties.GetByName(debian_module.PropertyControl),
+			filename:     pf.Name,
+			blob:         pfd.Blob,
+		}
+		files = append(files, f)
+		components[f.component] = true
+		architectures[f.architecture] = true
+		if pf.CreatedUnix > latest {
+			latest = pf.CreatedUnix
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].filename < files[j].filename
+	})
+
+	repo := &Repository{
+		Distribution:  distribution,
+		Components:    sortedKeys(components),
+		Architectures: sortedKeys(architectures),
+		Indexes:       make(map[string][]byte),
+	}
+
+	for _, component := range repo.Components {
+		for _, architecture := range repo.Architectures {
+			var buf bytes.Buffer
+			for _, f := range files {
+				if f.component != component {
+					continue
+				}
+				// packages for all architectures are part of every index
+				if f.architecture != architecture && f.architecture != debian_module.ArchitectureAll {
+					continue
+				}
+				writePackagesEntry(&buf, distribution, component, f)
+			}
+
+			path := fmt.Sprintf("%s/binary-%s/Packages", component, architecture)
+			repo.Indexes[path] = buf.Bytes()
+
+			var gzbuf bytes.Buffer
+			gzw := gzip.NewWriter(&gzbuf)
+			if _, err := gzw.Write(buf.Bytes()); err != nil {
+				return nil, err
+			}
+			if err := gzw.Close(); err != nil {
+				return nil, err
+			}
+			repo.Indexes[path+".gz"] = gzbuf.Bytes()
+		}
+	}
+
+	repo.Release = buildRelease(owner, repo, latest)
+
+	return repo, nil
+}
+
+// https://wiki.debian.org/DebianRepository/Format#A.22Packages.22_Indices
+func writePackagesEntry(w io.Writer, distribution, component string, f *indexFile) {
+	fmt.Fprintf(w, "%s\n", f.control)
+	fmt.Fprintf(w, "Filename: %s\n", PoolFilePath(distribution, component, f.filename))
+	fmt.Fprintf(w, "Size: %d\n", f.blob.Size)
+	fmt.Fprintf(w, "MD5sum: %s\n", f.blob.HashMD5)
+	fmt.Fprintf(w, "SHA1: %s\n", f.blob.HashSHA1)
+	fmt.Fprintf(w, "SHA256: %s\n", f.blob.HashSHA256)
+	fmt.Fprintf(w, "SHA512: %s\n\n", f.blob.HashSHA512)
+}
+
+// https://wiki.debian.org/DebianRepository/Format#A.22Release.22_files
+func buildRelease(owner *user_model.User, repo *Repository, date timeutil.TimeStamp) []byte {
+	paths := make([]string, 0, len(repo.Indexes))
+	for path := range repo.Indexes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Origin: %s\n", owner.Name)
+	fmt.Fprintf(&buf, "Label: %s\n", owner.Name)
+	fmt.Fprintf(&buf, "Suite: %s\n", repo.Distribution)
+	fmt.Fprintf(&buf, "Codename: %s\n", repo.Distribution)
+	fmt.Fprintf(&buf, "Date: %s\n", date.AsTime().UTC().Format(time.RFC1123))
+	fmt.Fprintf(&buf, "Architectures: %s\n", strings.Join(repo.Architectures, " "))
+	fmt.Fprintf(&buf, "Components: %s\n", strings.Join(repo.Components, " "))
+	fmt.Fprint(&buf, "Acquire-By-Hash: no\n")
+
+	for _, sum := range []struct {
+		name string
+		hash func() hash.Hash
+	}{
+		{"MD5Sum", md5.New},
+		{"SHA1", sha1.New},
+		{"SHA256", sha256.New},
+		{"SHA512", sha512.New},
+	} {
+		fmt.Fprintf(&buf, "%s:\n", sum.name)
+		for _, path := range paths {
+			h := sum.hash()
+			h.Write(repo.Indexes[path])
+			fmt.Fprintf(&buf, " %s %d %s\n", hex.EncodeToString(h.Sum(nil)), len(repo.Indexes[path]), path)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// SignRelease returns the clear signed InRelease file and the detached signature of the Release file
+func SignRelease(ctx context.Context, owner *user_model.User, release []byte) (inRelease, signature []byte, err error) {
+	key, err := GetSigningKey(ctx, owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := key.Entity()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var clearSigned bytes.Buffer
+	w, err := clearsign.Encode(&clearSigned, e.PrivateKey, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := w.Write(release); err != nil {
+		return nil, nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	var detached bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&detached, e, bytes.NewReader(release), nil); err != nil {
+		return nil, nil, err
+	}
+
+	return clearSigned.Bytes(), detached.Bytes(), nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,352 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	goproxy_module "code.gitea.io/gitea/modules/packages/goproxy"
+	"code.gitea.io/gitea/modules/setting"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	modzip "golang.org/x/mod/zip"
+)
+
+// ErrVersionNotExist is returned if the module has no such version
+var ErrVersionNotExist = errors.New("Module version does not exist")
+
+// VersionInfo is the content of the .info file of a module version
+type VersionInfo struct {
+	Version string
+	Time    time.Time
+}
+
+// Module is a Go module served by the proxy.
+// The versions of a module are uploaded packages or tags of a repository of the owner.
+type Module struct {
+	Path string
+
+	owner    *user_model.User
+	uploaded map[string]*packages_model.PackageVersion
+
+	repo      *repo_model.Repository
+	subdir    string
+	pathMajor string
+}
+
+// RepositoryModulePrefix returns the prefix of the paths of modules hosted in repositories of the owner.
+// Module paths can't contain a port, so only the host name is used.
+func RepositoryModulePrefix(owner *user_model.User) string {
+	appURL, _ := url.Parse(setting.AppURL)
+	return path.Join(appURL.Hostname(), setting.AppSubURL, url.PathEscape(owner.Name)) + "/"
+}
+
+// GetModule looks up the module with the path for the owner.
+// Repository versions are only included if the doer can read the code of the repository
+// and allowRepo, which checks the access token of the request, accepts the repository.
+func GetModule(ctx context.Context, doer, owner *user_model.User, modulePath string, allowRepo func(*repo_model.Repository) bool) (*Module, error) {
+	m := &Module{
+		Path:     modulePath,
+		owner:    owner,
+		uploaded: make(map[string]*packages_model.PackageVersion),
+	}
+
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, owner.ID, packages_model.TypeGo, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, pv := range pvs {
+		m.uploaded[pv.Version] = pv
+	}
+
+	if err := m.loadRepository(ctx, doer, allowRepo); err != nil {
+		return nil, err
+	}
+
+	if len(m.uploaded) == 0 && m.repo == nil {
+		return nil, packages_model.ErrPackageNotExist
+	}
+	return m, nil
+}
+
+// loadRepository resolves paths like {host}/{owner}/{repo}[/{subdir}][/vN]
+func (m *Module) loadRepository(ctx context.Context, doer *user_model.User, allowRepo func(*repo_model.Repository) bool) error {
+	rest := strings.TrimPrefix(m.Path, RepositoryModulePrefix(m.owner))
+	if rest == m.Path || rest == "" {
+		return nil
+	}
+
+	pathPrefix, pathMajor, ok := module.SplitPathVersion(rest)
+	if !ok {
+		return nil
+	}
+	repoName, subdir, _ := strings.Cut(pathPrefix, "/")
+
+	repo, err := repo_model.GetRepositoryByOwnerAndNameCtx(ctx, m.owner.Name, repoName)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if repo.IsEmpty || !allowRepo(repo) {
+		return nil
+	}
+
+	perm, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return err
+	}
+	if !perm.CanRead(unit.TypeCode) {
+		return nil
+	}
+
+	m.repo = repo
+	m.subdir = subdir
+	m.pathMajor = pathMajor
+	return nil
+}
+
+// tagName returns the repository tag of a version of a module in a subdirectory
+func (m *Module) tagName(version string) string {
+	if m.subdir == "" {
+		return version
+	}
+	return m.subdir + "/" + version
+}
+
+func (m *Module) isRepositoryVersion(version string) bool {
+	return m.repo != nil &&
+		goproxy_module.IsValidVersion(version) &&
+		!strings.HasSuffix(version, "+incompatible") &&
+		module.CheckPathMajor(version, m.pathMajor) == nil
+}
+
+// UploadedVersion returns the package version of an uploaded module version or nil
+func (m *Module) UploadedVersion(version string) *packages_model.PackageVersion {
+	return m.uploaded[version]
+}
+
+// Versions returns all versions of the module sorted by semantic version precedence
+func (m *Module) Versions(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	for version := range m.uploaded {
+		seen[version] = true
+	}
+
+	if m.repo != nil {
+		gitRepo, err := git.OpenRepository(ctx, m.repo.RepoPath())
+		if err != nil {
+			return nil, err
+		}
+		defer gitRepo.Close()
+
+		tags, err := gitRepo.GetTags(0, 0)
+		if err != nil {
+			return nil, err
+		}
+		prefix := m.tagName("")
+		for _, tag := range tags {
+			if !strings.HasPrefix(tag, prefix) {
+				continue
+			}
+			if version := strings.TrimPrefix(tag, prefix); m.isRepositoryVersion(version) {
+				seen[version] = true
+			}
+		}
+	}
+
+	versions := make([]string, 0, len(seen))
+	for version := range seen {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i], versions[j]) < 0
+	})
+	return versions, nil
+}
+
+// Latest returns the highest release version or the highest pre-release version if there is no release
+func (m *Module) Latest(ctx context.Context) (*VersionInfo, error) {
+	versions, err := m.Versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrVersionNotExist
+	}
+
+	latest := versions[len(versions)-1]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if semver.Prerelease(versions[i]) == "" {
+			latest = versions[i]
+			break
+		}
+	}
+	return m.Info(ctx, latest)
+}
+
+// Info returns the information about a version of the module
+func (m *Module) Info(ctx context.Context, version string) (*VersionInfo, error) {
+	if pv, ok := m.uploaded[version]; ok {
+		return &VersionInfo{
+			Version: pv.Version,
+			Time:    pv.CreatedUnix.AsTime().UTC(),
+		}, nil
+	}
+
+	var info *VersionInfo
+	err := m.withTagCommit(ctx, version, func(commit *git.Commit) error {
+		info = &VersionInfo{
+			Version: version,
+			Time:    commit.Committer.When.UTC(),
+		}
+		return nil
+	})
+	return info, err
+}
+
+// GoMod returns the go.mod file of a version of the module
+func (m *Module) GoMod(ctx context.Context, version string) (string, error) {
+	if pv, ok := m.uploaded[version]; ok {
+		pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+		if err != nil {
+			return "", err
+		}
+		return pd.Metadata.(*goproxy_module.Metadata).GoMod, nil
+	}
+
+	var goMod string
+	err := m.withTagCommit(ctx, version, func(commit *git.Commit) error {
+		content, err := commit.GetFileContent(path.Join(m.subdir, "go.mod"), modzip.MaxGoMod)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				goMod = goproxy_module.SynthesizeGoMod(m.Path)
+				return nil
+			}
+			return err
+		}
+		goMod = content
+		return nil
+	})
+	return goMod, err
+}
+
+// WriteRepositoryZip writes the module zip file of a repository version of the module
+func (m *Module) WriteRepositoryZip(ctx context.Context, w io.Writer, version string) error {
+	return m.withTagCommit(ctx, version, func(commit *git.Commit) error {
+		tree := &commit.Tree
+		if m.subdir != "" {
+			subtree, err := commit.SubTree(m.subdir)
+			if err != nil {
+				if git.IsErrNotExist(err) {
+					return ErrVersionNotExist
+				}
+				return err
+			}
+			tree = subtree
+		}
+
+		entries, err := tree.ListEntriesRecursive()
+		if err != nil {
+			return err
+		}
+
+		files := make([]modzip.File, 0, len(entries))
+		for _, entry := range entries {
+			if entry.IsDir() || entry.IsSubModule() {
+				continue
+			}
+			files = append(files, &treeEntryFile{entry: entry})
+		}
+
+		return modzip.Create(w, module.Version{Path: m.Path, Version: version}, files)
+	})
+}
+
+func (m *Module) withTagCommit(ctx context.Context, version string, fn func(*git.Commit) error) error {
+	if !m.isRepositoryVersion(version) {
+		return ErrVersionNotExist
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, m.repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	tag := m.tagName(version)
+	if !gitRepo.IsTagExist(tag) {
+		return ErrVersionNotExist
+	}
+
+	commit, err := gitRepo.GetTagCommit(tag)
+	if err != nil {
+		return err
+	}
+	return fn(commit)
+}
+
+// treeEntryFile adapts a git tree entry to the file interface of the module zip writer
+type treeEntryFile struct {
+	entry *git.TreeEntry
+}
+
+func (f *treeEntryFile) Path() string {
+	return f.entry.Name()
+}
+
+func (f *treeEntryFile) Lstat() (fs.FileInfo, error) {
+	return f, nil
+}
+
+func (f *treeEntryFile) Open() (io.ReadCloser, error) {
+	return f.entry.Blob().DataAsync()
+}
+
+func (f *treeEntryFile) Name() string {
+	return path.Base(f.entry.Name())
+}
+
+func (f *treeEntryFile) Size() int64 {
+	return f.entry.Size()
+}
+
+func (f *treeEntryFile) Mode() fs.FileMode {
+	if f.entry.IsLink() {
+		return fs.ModeSymlink
+	}
+	if f.entry.IsExecutable() {
+		return 0o755
+	}
+	return 0o644
+}
+
+func (f *treeEntryFile) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (f *treeEntryFile) IsDir() bool {
+	return false
+}
+
+func (f *treeEntryFile) Sys() interface{} {
+	return nil
+}
@@ -157,7 +157,9 @@ func checkAndUpdateStatus(pr *issues_model.PullRequest) {
 	if !has {
 		if err := pr.UpdateColsIfNotMerged("merge_base", "status", "conflicted_files", "changed_protected_files"); err != nil {
 			log.Error("Update[%d]: %v", pr.ID, err)
+			return
 		}
+		notification.NotifyPullRequestMergeabilityChecked(pr)
 	}
 }
 
@@ -279,6 +281,7 @@ func manuallyMerged(ctx context.Context, pr *issues_model.PullRequest) bool {
 		}
 
 		notification.NotifyMergePullRequest(pr, merger)
+		retargetStackedPullRequests(ctx, pr, merger)
 
 		log.Info("manuallyMerged[%d]: Marked as manually merged into %s/%s by commit id: %s", pr.ID, pr.BaseRepo.Name, pr.BaseBranch, commit.ID.String())
 		return true
@@ -359,6 +362,7 @@ func CheckPrsForBaseBranch(baseRepo *repo_model.Repository, baseBranchName strin
 	for _, pr := range prs {
 		AddToTaskQueue(pr)
 	}
+	AddMergeQueueTask(baseRepo.ID, baseBranchName)
 
 	return nil
 }
@@ -373,5 +377,5 @@ func Init() error {
 
 	go graceful.GetManager().RunWithShutdownFns(prPatchCheckerQueue.Run)
 	go graceful.GetManager().RunWithShutdownContext(InitializePullRequests)
-	return nil
+	return initMergeQueue()
 }
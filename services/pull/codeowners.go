@@ -0,0 +1,262 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/codeowners"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// maxCodeOwnersSize is the maximum size of a CODEOWNERS file which will be read
+const maxCodeOwnersSize = 3 * 1024 * 1024
+
+// getCodeOwnersFile returns the parsed CODEOWNERS file of the commit, or nil if there is none
+func getCodeOwnersFile(commit *git.Commit) (*codeowners.File, error) {
+	for _, path := range codeowners.Paths {
+		blob, err := commit.GetBlobByPath(path)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		rc, err := blob.DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxCodeOwnersSize))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		file, warnings := codeowners.Parse(content)
+		for _, warning := range warnings {
+			log.Debug("Ignoring invalid entry in %s of commit %s: %s", path, commit.ID.String(), warning)
+		}
+		return file, nil
+	}
+	return nil, nil
+}
+
+type codeOwnerResolver struct {
+	repo  *repo_model.Repository
+	users map[string]*user_model.User
+	teams map[string]*organization.Team
+}
+
+func (r *codeOwnerResolver) resolve(ctx context.Context, owner string) (*user_model.User, *organization.Team) {
+	if codeowners.IsTeam(owner) {
+		if team, ok := r.teams[owner]; ok {
+			return nil, team
+		}
+		r.teams[owner] = nil
+
+		orgName, teamName, _ := strings.Cut(strings.TrimPrefix(owner, "@"), "/")
+		// teams of other organizations can't own files of this repository
+		if !r.repo.Owner.IsOrganization() || !strings.EqualFold(orgName, r.repo.OwnerName) {
+			return nil, nil
+		}
+		team, err := organization.GetTeam(ctx, r.repo.OwnerID, teamName)
+		if err != nil {
+			if !organization.IsErrTeamNotExist(err) {
+				log.Error("GetTeam: %v", err)
+			}
+			return nil, nil
+		}
+		r.teams[owner] = team
+		return nil, team
+	}
+
+	if user, ok := r.users[owner]; ok {
+		return user, nil
+	}
+	r.users[owner] = nil
+
+	var user *user_model.User
+	var err error
+	if codeowners.IsEmail(owner) {
+		user, err = user_model.GetUserByEmailContext(ctx, owner)
+	} else {
+		user, err = user_model.GetUserByName(ctx, strings.TrimPrefix(owner, "@"))
+	}
+	if err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			log.Error("Unable to resolve code owner %s: %v", owner, err)
+		}
+		return nil, nil
+	}
+	if !user.IsActive || user.ProhibitLogin || user.IsOrganization() {
+		return nil, nil
+	}
+	r.users[owner] = user
+	return user, nil
+}
+
+// GetCodeOwnerGroups returns the owners of the files changed by the pull request, as defined in the CODEOWNERS file of the base branch.
+// Rules none of whose owners resolve to a user or team are kept without owners, they can't be approved and are shown as such.
+func GetCodeOwnerGroups(ctx context.Context, pr *issues_model.PullRequest, gitRepo *git.Repository) ([]*issues_model.CodeOwnerGroup, error) {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return nil, err
+	}
+	if err := pr.BaseRepo.GetOwner(ctx); err != nil {
+		return nil, err
+	}
+
+	baseCommit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	file, err := getCodeOwnersFile(baseCommit)
+	if err != nil || file == nil {
+		return nil, err
+	}
+
+	headRef := pr.GetGitRefName()
+	if pr.HasMerged {
+		headRef = pr.MergedCommitID
+	}
+	mergeBase := pr.MergeBase
+	if !pr.HasMerged {
+		if mergeBase, _, err = gitRepo.GetMergeBase("", git.BranchPrefix+pr.BaseBranch, headRef); err != nil {
+			return nil, fmt.Errorf("GetMergeBase: %v", err)
+		}
+	}
+	// a renamed file is owned by the owners of both of its paths
+	changedFiles, err := gitRepo.GetFilesChangedBetweenNoRenames(mergeBase, headRef)
+	if err != nil {
+		return nil, fmt.Errorf("GetFilesChangedBetweenNoRenames: %v", err)
+	}
+
+	resolver := &codeOwnerResolver{
+		repo:  pr.BaseRepo,
+		users: make(map[string]*user_model.User),
+		teams: make(map[string]*organization.Team),
+	}
+	rules := file.RulesFor(changedFiles)
+	groups := make([]*issues_model.CodeOwnerGroup, 0, len(rules))
+	for _, rule := range rules {
+		group := &issues_model.CodeOwnerGroup{Pattern: rule.Pattern}
+		for _, owner := range rule.Owners {
+			user, team := resolver.resolve(ctx, owner)
+			if user != nil {
+				group.Users = append(group.Users, user)
+			} else if team != nil {
+				group.Teams = append(group.Teams, team)
+			}
+		}
+		if group.IsEmpty() {
+			log.Debug("None of the code owners of %s in %-v could be resolved", rule.Pattern, pr.BaseRepo)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// RequestCodeOwnersReview requests reviews from the code owners of the files changed by the pull request.
+// Owners who were already requested or have already reviewed are skipped.
+func RequestCodeOwnersReview(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User) error {
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return err
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return err
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	gitRepo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	groups, err := GetCodeOwnerGroups(ctx, pr, gitRepo)
+	if err != nil {
+		return err
+	}
+
+	requestedUsers := make(map[int64]bool)
+	requestedTeams := make(map[int64]bool)
+	for _, group := range groups {
+		for _, user := range group.Users {
+			if requestedUsers[user.ID] || user.ID == pr.Issue.PosterID {
+				continue
+			}
+			requestedUsers[user.ID] = true
+
+			if review, err := issues_model.GetReviewByIssueIDAndUserID(ctx, pr.IssueID, user.ID); err != nil && !issues_model.IsErrReviewNotExist(err) {
+				return err
+			} else if review != nil {
+				continue
+			}
+
+			permission, err := access_model.GetUserRepoPermission(ctx, pr.BaseRepo, user)
+			if err != nil {
+				return err
+			}
+			if !permission.CanAccessAny(perm.AccessModeRead, unit.TypePullRequests) {
+				continue
+			}
+
+			if _, err := issue_service.ReviewRequest(pr.Issue, doer, user, true); err != nil {
+				return err
+			}
+		}
+
+		for _, team := range group.Teams {
+			if requestedTeams[team.ID] {
+				continue
+			}
+			requestedTeams[team.ID] = true
+
+			if review, err := issues_model.GetTeamReviewerByIssueIDAndTeamID(ctx, pr.IssueID, team.ID); err != nil && !issues_model.IsErrReviewNotExist(err) {
+				return err
+			} else if review != nil {
+				continue
+			}
+
+			if pr.BaseRepo.IsPrivate && !organization.HasTeamRepo(ctx, team.OrgID, team.ID, pr.BaseRepo.ID) {
+				continue
+			}
+
+			if _, err := issue_service.TeamReviewRequest(pr.Issue, doer, team, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetUnapprovedCodeOwnerGroups returns the code owner groups which still have to approve the pull request.
+// Nothing is returned if the protected branch doesn't require code owner approval.
+func GetUnapprovedCodeOwnerGroups(ctx context.Context, pr *issues_model.PullRequest, gitRepo *git.Repository) ([]*issues_model.CodeOwnerGroup, error) {
+	if err := pr.LoadProtectedBranchCtx(ctx); err != nil {
+		return nil, err
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.RequireCodeOwnerApproval {
+		return nil, nil
+	}
+
+	groups, err := GetCodeOwnerGroups(ctx, pr, gitRepo)
+	if err != nil {
+		return nil, err
+	}
+	return issues_model.FilterUnapprovedCodeOwnerGroups(ctx, pr.ProtectedBranch, pr, groups)
+}
@@ -172,6 +172,12 @@ func Merge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.U
 		return err
 	}
 
+	return handleMergedPullRequest(ctx, pr, doer)
+}
+
+// handleMergedPullRequest marks the pull request as merged by doer once pr.MergedCommitID has been
+// pushed to the base branch, and notifies and closes the issues it references
+func handleMergedPullRequest(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User) error {
 	pr.MergedUnix = timeutil.TimeStampNow()
 	pr.Merger = doer
 	pr.MergerID = doer.ID
@@ -193,6 +199,9 @@ func Merge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.U
 
 	notification.NotifyMergePullRequest(pr, doer)
 
+	// before the head branch gets deleted
+	retargetStackedPullRequests(ctx, pr, doer)
+
 	// Reset cached commit count
 	cache.Remove(pr.Issue.Repo.GetCommitsCountCacheKey(pr.BaseBranch, true))
 
@@ -225,8 +234,16 @@ func Merge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.U
 
 // rawMerge perform the merge operation without changing any pull information in database
 func rawMerge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message string) (string, error) {
+	return rawMergeOnto(ctx, pr, doer, mergeStyle, expectedHeadCommitID, message, pr.BaseBranch, pr.BaseBranch, "")
+}
+
+// rawMergeOnto merges the pull request on top of baseRef and pushes the result to targetBranch of the base repository.
+// The push is forced when targetBranch is not the base branch of the pull request.
+// If upstreamRef is set the rebase styles only replay the commits of the head branch which are not reachable from
+// this ref of the base repository.
+func rawMergeOnto(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message, baseRef, targetBranch, upstreamRef string) (string, error) {
 	// Clone base repo.
-	tmpBasePath, err := createTemporaryRepo(ctx, pr)
+	tmpBasePath, err := createTemporaryRepoWithBase(ctx, pr, baseRef)
 	if err != nil {
 		log.Error("CreateTemporaryPath: %v", err)
 		return "", err
@@ -240,6 +257,7 @@ func rawMerge(ctx context.Context, pr *issues_model.PullRequest, doer *user_mode
 	baseBranch := "base"
 	trackingBranch := "tracking"
 	stagingBranch := "staging"
+	upstreamBranch := "upstream"
 
 	if expectedHeadCommitID != "" {
 		trackingCommitID, _, err := git.NewCommand(ctx, "show-ref", "--hash", git.BranchPrefix+trackingBranch).RunStdString(&git.RunOpts{Dir: tmpBasePath})
@@ -412,8 +430,24 @@ func rawMerge(ctx context.Context, pr *issues_model.PullRequest, doer *user_mode
 		outbuf.Reset()
 		errbuf.Reset()
 
+		rebaseCmd := git.NewCommand(ctx, "rebase", baseBranch)
+		if upstreamRef != "" {
+			if err := git.NewCommand(ctx, "fetch", "--no-tags", "origin", upstreamRef+":"+upstreamBranch).
+				Run(&git.RunOpts{
+					Dir:    tmpBasePath,
+					Stdout: &outbuf,
+					Stderr: &errbuf,
+				}); err != nil {
+				log.Error("git fetch upstream %s prior to rebase [%s:%s -> %s:%s]: %v\n%s\n%s", upstreamRef, pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+				return "", fmt.Errorf("git fetch upstream %s prior to rebase [%s:%s -> %s:%s]: %v\n%s\n%s", upstreamRef, pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+			}
+			outbuf.Reset()
+			errbuf.Reset()
+			rebaseCmd = git.NewCommand(ctx, "rebase", "--onto", baseBranch, upstreamBranch)
+		}
+
 		// Rebase before merging
-		if err := git.NewCommand(ctx, "rebase", baseBranch).
+		if err := rebaseCmd.
 			Run(&git.RunOpts{
 				Dir:    tmpBasePath,
 				Stdout: &outbuf,
@@ -591,6 +625,8 @@ func rawMerge(ctx context.Context, pr *issues_model.PullRequest, doer *user_mode
 	if mergeStyle == repo_model.MergeStyleRebaseUpdate {
 		// force push the rebase result to head branch
 		pushCmd = git.NewCommand(ctx, "push", "-f", "head_repo", stagingBranch+":"+git.BranchPrefix+pr.HeadBranch)
+	} else if targetBranch != pr.BaseBranch {
+		pushCmd = git.NewCommand(ctx, "push", "-f", "origin", baseBranch+":"+git.BranchPrefix+targetBranch)
 	} else {
 		pushCmd = git.NewCommand(ctx, "push", "origin", baseBranch+":"+git.BranchPrefix+pr.BaseBranch)
 	}
@@ -795,12 +831,44 @@ func CheckPullBranchProtections(ctx context.Context, pr *issues_model.PullReques
 		}
 	}
 
+	if pr.ProtectedBranch.RequireCodeOwnerApproval {
+		gitRepo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
+		if err != nil {
+			return fmt.Errorf("OpenRepository: %v", err)
+		}
+		defer gitRepo.Close()
+
+		groups, err := GetCodeOwnerGroups(ctx, pr, gitRepo)
+		if err != nil {
+			return fmt.Errorf("GetCodeOwnerGroups: %v", err)
+		}
+		// such a rule can't be approved until it is fixed in the CODEOWNERS file
+		for _, group := range groups {
+			if group.IsEmpty() {
+				return models.ErrDisallowedToMerge{
+					Reason: fmt.Sprintf("None of the code owners of %s in the CODEOWNERS file can be resolved", group.Pattern),
+				}
+			}
+		}
+		if issues_model.MergeBlockedByCodeOwners(ctx, pr.ProtectedBranch, pr, groups) {
+			return models.ErrDisallowedToMerge{
+				Reason: "Code owners have not approved all changed files",
+			}
+		}
+	}
+
 	if issues_model.MergeBlockedByOutdatedBranch(pr.ProtectedBranch, pr) {
 		return models.ErrDisallowedToMerge{
 			Reason: "The head branch is behind the base branch",
 		}
 	}
 
+	if !pr.ProtectedBranch.IsInMergeWindow(time.Now()) {
+		return models.ErrDisallowedToMerge{
+			Reason: "The base branch is outside of its merge windows",
+		}
+	}
+
 	if skipProtectedFilesCheck {
 		return nil
 	}
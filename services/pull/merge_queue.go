@@ -0,0 +1,481 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+)
+
+// MergeQueueBranchPrefix is the prefix of the temporary branches the merge queue pushes its speculative merges to
+const MergeQueueBranchPrefix = "gitea-merge-queue/"
+
+// Reasons a pull request is removed from the merge queue, used as suffix of the "repo.pulls.merge_queue_reason_" translation keys
+const (
+	mergeQueueReasonChecksFailed = "checks_failed"
+	mergeQueueReasonConflict     = "conflict"
+	mergeQueueReasonHeadChanged  = "head_changed"
+	mergeQueueReasonBaseChanged  = "base_changed"
+	mergeQueueReasonClosed       = "closed"
+	mergeQueueReasonDisabled     = "disabled"
+	mergeQueueReasonMergeFailed  = "merge_failed"
+)
+
+// prMergeQueue represents a queue of branches whose merge queue needs to be processed
+var prMergeQueue queue.UniqueQueue
+
+// mergeQueueWorkingPool makes sure only one merge queue of a branch is processed at a time
+var mergeQueueWorkingPool = sync.NewExclusivePool()
+
+// MergeQueueBranchName returns the name of the branch the speculative merge of a queued pull request is pushed to
+func MergeQueueBranchName(pr *issues_model.PullRequest) string {
+	return fmt.Sprintf("%s%s/pr-%d", MergeQueueBranchPrefix, pr.BaseBranch, pr.Index)
+}
+
+// IsMergeQueueEnabled returns true if pull requests for the base branch of pr are merged through the merge queue
+func IsMergeQueueEnabled(ctx context.Context, pr *issues_model.PullRequest) (bool, error) {
+	if err := pr.LoadProtectedBranchCtx(ctx); err != nil {
+		return false, err
+	}
+	return pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue, nil
+}
+
+// AddToMergeQueue appends the pull request to the merge queue of its base branch.
+// Caller should check PR is ready to be merged (review and status checks)
+func AddToMergeQueue(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message string) error {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnitCtx(ctx, unit.TypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) || mergeStyle == repo_model.MergeStyleRebaseUpdate {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+
+	headCommitID, err := git.GetFullCommitID(ctx, pr.BaseRepo.RepoPath(), pr.GetGitRefName())
+	if err != nil {
+		return fmt.Errorf("GetFullCommitID: %v", err)
+	}
+	if expectedHeadCommitID != "" && expectedHeadCommitID != headCommitID {
+		return models.ErrSHADoesNotMatch{
+			GivenSHA:   expectedHeadCommitID,
+			CurrentSHA: headCommitID,
+		}
+	}
+
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := pull_model.AddToMergeQueue(ctx, &pull_model.MergeQueueEntry{
+			PullID:       pr.ID,
+			RepoID:       pr.BaseRepoID,
+			BaseBranch:   pr.BaseBranch,
+			DoerID:       doer.ID,
+			MergeStyle:   mergeStyle,
+			Message:      message,
+			HeadCommitID: headCommitID,
+		}); err != nil {
+			return err
+		}
+
+		// the merge queue takes over from a scheduled auto merge
+		if err := pull_model.DeleteScheduledAutoMerge(ctx, pr.ID); err != nil && !db.IsErrNotExist(err) {
+			return err
+		}
+
+		_, err := issues_model.CreateMergeQueueComment(ctx, issues_model.CommentTypePRAddedToMergeQueue, pr, doer, "")
+		return err
+	}, ctx); err != nil {
+		return err
+	}
+
+	AddMergeQueueTask(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// RemoveFromMergeQueue removes the pull request from the merge queue of its base branch
+func RemoveFromMergeQueue(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User) error {
+	entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := pull_model.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+			return err
+		}
+		_, err := issues_model.CreateMergeQueueComment(ctx, issues_model.CommentTypePRRemovedFromMergeQueue, pr, doer, "")
+		return err
+	}, ctx); err != nil {
+		return err
+	}
+
+	if entry.MergeCommitID != "" {
+		deleteMergeQueueBranch(ctx, pr, doer)
+	}
+
+	// the pull requests queued after this one have to be merged again without it
+	AddMergeQueueTask(entry.RepoID, entry.BaseBranch)
+	return nil
+}
+
+// MergeQueueCommitStatusUpdated processes the merge queue containing the speculative merge commit sha, if any
+func MergeQueueCommitStatusUpdated(ctx context.Context, repo *repo_model.Repository, sha string) error {
+	entries := make([]*pull_model.MergeQueueEntry, 0, 1)
+	if err := db.GetEngine(ctx).Where("repo_id = ? AND merge_commit_id = ?", repo.ID, sha).Find(&entries); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		AddMergeQueueTask(entry.RepoID, entry.BaseBranch)
+	}
+	return nil
+}
+
+// AddMergeQueueTask adds the merge queue of a branch to the processing queue
+func AddMergeQueueTask(repoID int64, branch string) {
+	if err := prMergeQueue.PushFunc(fmt.Sprintf("%d_%s", repoID, branch), func() error {
+		log.Trace("Adding merge queue of branch %s in repo %d to the merge queue processing queue", branch, repoID)
+		return nil
+	}); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Error adding merge queue of branch %s in repo %d to the merge queue processing queue: %v", branch, repoID, err)
+	}
+}
+
+// handleMergeQueue processes the merge queues of the passed branches
+func handleMergeQueue(data ...queue.Data) []queue.Data {
+	for _, d := range data {
+		var repoID int64
+		idStr, branch, ok := strings.Cut(d.(string), "_")
+		if _, err := fmt.Sscan(idStr, &repoID); !ok || err != nil {
+			log.Error("could not parse data from pr_merge_queue queue (%v): %v", d, err)
+			continue
+		}
+
+		ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(),
+			fmt.Sprintf("Process merge queue of branch %s in repo %d", branch, repoID))
+		if err := processMergeQueue(ctx, repoID, branch); err != nil {
+			log.Error("processMergeQueue[%d, %s]: %v", repoID, branch, err)
+		}
+		finished()
+	}
+	return nil
+}
+
+// AddMergeWindowMergeQueueTasks adds the non-empty merge queues of the branches whose merge windows are open to the processing queue,
+// the landing of their first pull requests waits for the merge windows
+func AddMergeWindowMergeQueueTasks(ctx context.Context) error {
+	branches, err := pull_model.GetBranchesWithMergeQueue(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, b := range branches {
+		protectedBranch, err := git_model.GetProtectedBranchBy(ctx, b.RepoID, b.BaseBranch)
+		if err != nil {
+			return err
+		}
+		if protectedBranch != nil && protectedBranch.MergeWindows != "" && protectedBranch.IsInMergeWindow(now) {
+			AddMergeQueueTask(b.RepoID, b.BaseBranch)
+		}
+	}
+	return nil
+}
+
+// initializeMergeQueues adds all the branches with a non-empty merge queue to the processing queue
+func initializeMergeQueues(ctx context.Context) {
+	branches, err := pull_model.GetBranchesWithMergeQueue(ctx)
+	if err != nil {
+		log.Error("GetBranchesWithMergeQueue: %v", err)
+		return
+	}
+	for _, b := range branches {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			AddMergeQueueTask(b.RepoID, b.BaseBranch)
+		}
+	}
+}
+
+func initMergeQueue() error {
+	prMergeQueue = queue.CreateUniqueQueue("pr_merge_queue", handleMergeQueue, "")
+	if prMergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_merge_queue Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(prMergeQueue.Run)
+	go graceful.GetManager().RunWithShutdownContext(initializeMergeQueues)
+	return nil
+}
+
+// processMergeQueue walks through the merge queue of a branch in order. Every pull request is merged
+// onto the speculative merge of the pull request before it, or onto the branch itself for the first one,
+// and is merged again whenever what it was built on changed. The first pull request is landed once the
+// required status checks of its speculative merge succeed and the merge window of the branch is open,
+// a pull request whose checks fail is ejected.
+func processMergeQueue(ctx context.Context, repoID int64, branch string) error {
+	key := fmt.Sprintf("%d_%s", repoID, branch)
+	mergeQueueWorkingPool.CheckIn(key)
+	defer mergeQueueWorkingPool.CheckOut(key)
+
+	entries, err := pull_model.GetMergeQueueEntries(ctx, repoID, branch)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+	if err != nil {
+		return err
+	}
+
+	protectedBranch, err := git_model.GetProtectedBranchBy(ctx, repoID, branch)
+	if err != nil {
+		return err
+	}
+
+	baseCommitID, err := git.GetFullCommitID(ctx, repo.RepoPath(), git.BranchPrefix+branch)
+	if err != nil && !git.IsErrNotExist(err) {
+		return err
+	}
+	enabled := err == nil && protectedBranch != nil && protectedBranch.EnableMergeQueue
+
+	// the commit and branch the next entry has to be merged onto
+	expectedBaseCommitID, baseRef := baseCommitID, branch
+	// whether all the entries before the current one have been landed or ejected
+	isHead := true
+
+	for _, entry := range entries {
+		pr, err := issues_model.GetPullRequestByID(ctx, entry.PullID)
+		if err != nil {
+			if issues_model.IsErrPullRequestNotExist(err) {
+				if err := pull_model.DeleteMergeQueueEntry(ctx, entry.PullID); err != nil && !db.IsErrNotExist(err) {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+		pr.BaseRepo = repo
+		if err := pr.LoadIssueCtx(ctx); err != nil {
+			return err
+		}
+		if err := entry.LoadDoer(ctx); err != nil {
+			return err
+		}
+
+		var reason string
+		switch {
+		case !enabled:
+			reason = mergeQueueReasonDisabled
+		case pr.HasMerged:
+			// merged outside of the merge queue, e.g. manually
+			if err := pull_model.DeleteMergeQueueEntry(ctx, entry.PullID); err != nil && !db.IsErrNotExist(err) {
+				return err
+			}
+			if entry.MergeCommitID != "" {
+				deleteMergeQueueBranch(ctx, pr, entry.Doer)
+			}
+			continue
+		case pr.Issue.IsClosed:
+			reason = mergeQueueReasonClosed
+		case pr.BaseBranch != entry.BaseBranch:
+			reason = mergeQueueReasonBaseChanged
+		default:
+			headCommitID, err := git.GetFullCommitID(ctx, repo.RepoPath(), pr.GetGitRefName())
+			if err != nil {
+				return err
+			}
+			if headCommitID != entry.HeadCommitID {
+				reason = mergeQueueReasonHeadChanged
+			}
+		}
+		if reason != "" {
+			ejectFromMergeQueue(ctx, entry, pr, reason)
+			continue
+		}
+
+		if entry.MergeCommitID == "" || entry.BaseCommitID != expectedBaseCommitID {
+			reason, err = buildMergeQueueEntry(ctx, entry, pr, baseRef, expectedBaseCommitID)
+			if err != nil {
+				return err
+			}
+			if reason != "" {
+				ejectFromMergeQueue(ctx, entry, pr, reason)
+				continue
+			}
+		}
+
+		state, err := getMergeQueueCommitStatusState(ctx, protectedBranch, repo.ID, entry.MergeCommitID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case state.IsPending():
+		case !state.IsSuccess():
+			ejectFromMergeQueue(ctx, entry, pr, mergeQueueReasonChecksFailed)
+			continue
+		case isHead && protectedBranch.IsInMergeWindow(time.Now()):
+			landed, err := landMergeQueueEntry(ctx, entry, pr)
+			if err != nil {
+				return err
+			}
+			if landed {
+				// the branch now points to the speculative merge the next entry was built on
+				expectedBaseCommitID, baseRef = entry.MergeCommitID, branch
+				continue
+			}
+		}
+
+		isHead = false
+		expectedBaseCommitID, baseRef = entry.MergeCommitID, MergeQueueBranchName(pr)
+	}
+	return nil
+}
+
+// buildMergeQueueEntry merges the pull request of the entry onto baseRef and pushes the result to its merge queue branch.
+// A non-empty reason is returned if the pull request has to be ejected from the merge queue.
+func buildMergeQueueEntry(ctx context.Context, entry *pull_model.MergeQueueEntry, pr *issues_model.PullRequest, baseRef, baseCommitID string) (string, error) {
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	mergeCommitID, err := rawMergeOnto(ctx, pr, entry.Doer, entry.MergeStyle, entry.HeadCommitID, entry.Message, baseRef, MergeQueueBranchName(pr), "")
+	if err != nil {
+		switch {
+		case models.IsErrMergeConflicts(err), models.IsErrRebaseConflicts(err), models.IsErrMergeUnrelatedHistories(err):
+			return mergeQueueReasonConflict, nil
+		case models.IsErrSHADoesNotMatch(err):
+			return mergeQueueReasonHeadChanged, nil
+		}
+		log.Error("Unable to merge pull request %d for the merge queue: %v", pr.ID, err)
+		return mergeQueueReasonMergeFailed, nil
+	}
+
+	entry.BaseCommitID = baseCommitID
+	entry.MergeCommitID = mergeCommitID
+	return "", pull_model.UpdateMergeQueueEntryCols(ctx, entry, "base_commit_id", "merge_commit_id")
+}
+
+// landMergeQueueEntry fast-forwards the base branch to the speculative merge of the entry and marks the pull request as merged.
+// It returns false if the base branch moved in the meantime, the merge queue is processed again in this case.
+func landMergeQueueEntry(ctx context.Context, entry *pull_model.MergeQueueEntry, pr *issues_model.PullRequest) (bool, error) {
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		return false, err
+	}
+	headUser := entry.Doer
+	if pr.HeadRepo != nil {
+		if err := pr.HeadRepo.GetOwner(ctx); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return false, err
+			}
+		} else {
+			headUser = pr.HeadRepo.Owner
+		}
+	}
+
+	repoPath := pr.BaseRepo.RepoPath()
+	if err := git.Push(ctx, repoPath, git.PushOptions{
+		Remote: repoPath,
+		Branch: entry.MergeCommitID + ":" + git.BranchPrefix + pr.BaseBranch,
+		Env:    repo_module.FullPushingEnvironment(headUser, entry.Doer, pr.BaseRepo, pr.BaseRepo.Name, pr.ID),
+	}); err != nil {
+		if git.IsErrPushOutOfDate(err) {
+			return false, nil
+		}
+		log.Error("Unable to land pull request %d from the merge queue: %v", pr.ID, err)
+		ejectFromMergeQueue(ctx, entry, pr, mergeQueueReasonMergeFailed)
+		return false, nil
+	}
+
+	if err := pull_model.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+		return false, err
+	}
+	deleteMergeQueueBranch(ctx, pr, entry.Doer)
+
+	pr.MergedCommitID = entry.MergeCommitID
+	if err := handleMergedPullRequest(ctx, pr, entry.Doer); err != nil {
+		log.Error("handleMergedPullRequest[%d]: %v", pr.ID, err)
+	}
+	return true, nil
+}
+
+// ejectFromMergeQueue removes the pull request of the entry from the merge queue and records why
+func ejectFromMergeQueue(ctx context.Context, entry *pull_model.MergeQueueEntry, pr *issues_model.PullRequest, reason string) {
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := pull_model.DeleteMergeQueueEntry(ctx, entry.PullID); err != nil {
+			return err
+		}
+		_, err := issues_model.CreateMergeQueueComment(ctx, issues_model.CommentTypePRRemovedFromMergeQueue, pr, entry.Doer, reason)
+		return err
+	}, ctx); err != nil {
+		log.Error("Unable to remove pull request %d from the merge queue: %v", entry.PullID, err)
+		return
+	}
+
+	if entry.MergeCommitID != "" {
+		deleteMergeQueueBranch(ctx, pr, entry.Doer)
+	}
+}
+
+// deleteMergeQueueBranch deletes the merge queue branch of the pull request
+func deleteMergeQueueBranch(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User) {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		log.Error("LoadBaseRepo: %v", err)
+		return
+	}
+
+	branch := MergeQueueBranchName(pr)
+	repoPath := pr.BaseRepo.RepoPath()
+	if !git.IsBranchExist(ctx, repoPath, branch) {
+		return
+	}
+	if err := git.Push(ctx, repoPath, git.PushOptions{
+		Remote: repoPath,
+		Branch: ":" + git.BranchPrefix + branch,
+		Env:    repo_module.PushingEnvironment(doer, pr.BaseRepo),
+	}); err != nil {
+		log.Error("Unable to delete merge queue branch %s in %-v: %v", branch, pr.BaseRepo, err)
+	}
+}
+
+// getMergeQueueCommitStatusState returns the state of the required status checks of a speculative merge commit.
+// Unlike for pull requests, a commit without any status is pending as its checks have most likely not reported yet.
+func getMergeQueueCommitStatusState(ctx context.Context, protectedBranch *git_model.ProtectedBranch, repoID int64, sha string) (structs.CommitStatusState, error) {
+	if !protectedBranch.EnableStatusCheck {
+		return structs.CommitStatusSuccess, nil
+	}
+
+	commitStatuses, _, err := git_model.GetLatestCommitStatus(ctx, repoID, sha, db.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	if len(protectedBranch.StatusCheckContexts) == 0 && len(commitStatuses) == 0 {
+		return structs.CommitStatusPending, nil
+	}
+	return MergeRequiredContextsCommitStatus(commitStatuses, protectedBranch.StatusCheckContexts), nil
+}
@@ -96,6 +96,10 @@ func NewPullRequest(ctx context.Context, repo *repo_model.Repository, pull *issu
 	}
 	defer baseGitRepo.Close()
 
+	if err := RequestCodeOwnersReview(prCtx, pr, pull.Poster); err != nil {
+		log.Error("RequestCodeOwnersReview: %v", err)
+	}
+
 	compareInfo, err := baseGitRepo.GetCompareInfo(pr.BaseRepo.RepoPath(),
 		git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName(), false, false)
 	if err != nil {
@@ -317,6 +321,11 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 			}
 
 			AddToTaskQueue(pr)
+			// a queued pull request is ejected when its head changes
+			AddMergeQueueTask(pr.BaseRepoID, pr.BaseBranch)
+			if err := RequestCodeOwnersReview(ctx, pr, doer); err != nil {
+				log.Error("RequestCodeOwnersReview: %v", err)
+			}
 			comment, err := issues_model.CreatePushPullComment(ctx, doer, pr, oldCommitID, newCommitID)
 			if err == nil && comment != nil {
 				notification.NotifyPullRequestPushCommits(doer, pr, comment)
@@ -345,6 +354,7 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 			}
 			AddToTaskQueue(pr)
 		}
+		AddMergeQueueTask(repoID, branch)
 	})
 }
 
@@ -0,0 +1,177 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// stackMaxDepth limits the number of pull requests walked below and above a pull request of a stack
+const stackMaxDepth = 20
+
+// StackedPullRequest is a pull request of a stack, the pull requests of a stack
+// target the head branches of the pull requests below them in the same repository
+type StackedPullRequest struct {
+	*issues_model.PullRequest
+	Depth     int // the number of pull requests below the pull request
+	IsCurrent bool
+}
+
+// isStackable returns true if other pull requests of its base repository can target the head branch of the pull request
+func isStackable(pr *issues_model.PullRequest) bool {
+	return pr.HeadRepoID == pr.BaseRepoID && pr.Flow == issues_model.PullRequestFlowGithub
+}
+
+// getStackedPullRequests returns the open pull requests which target the head branch of the pull request
+func getStackedPullRequests(pr *issues_model.PullRequest) ([]*issues_model.PullRequest, error) {
+	if !isStackable(pr) {
+		return nil, nil
+	}
+	return issues_model.GetUnmergedPullRequestsByBaseInfo(pr.BaseRepoID, pr.HeadBranch)
+}
+
+// getPullRequestBelow returns the open pull request whose head branch is the base branch of the pull request, nil if there is none
+func getPullRequestBelow(pr *issues_model.PullRequest) (*issues_model.PullRequest, error) {
+	prs, err := issues_model.GetUnmergedPullRequestsByHeadInfo(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	for _, below := range prs {
+		if below.BaseRepoID == pr.BaseRepoID {
+			return below, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetPullRequestStack returns the stack of an open pull request from its bottom:
+// the pull requests below it, the pull request and the pull requests stacked on it, depth-first.
+// It returns nil if the pull request isn't part of a stack.
+func GetPullRequestStack(ctx context.Context, pr *issues_model.PullRequest) ([]*StackedPullRequest, error) {
+	if pr.HasMerged {
+		return nil, nil
+	}
+
+	visited := map[int64]bool{pr.ID: true}
+	var below []*issues_model.PullRequest
+	for current := pr; len(below) < stackMaxDepth; {
+		next, err := getPullRequestBelow(current)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil || visited[next.ID] {
+			break
+		}
+		visited[next.ID] = true
+		below = append([]*issues_model.PullRequest{next}, below...)
+		current = next
+	}
+
+	stack := make([]*StackedPullRequest, 0, len(below)+1)
+	for i, p := range below {
+		stack = append(stack, &StackedPullRequest{PullRequest: p, Depth: i})
+	}
+	stack = append(stack, &StackedPullRequest{PullRequest: pr, Depth: len(below), IsCurrent: true})
+
+	var appendAbove func(p *issues_model.PullRequest, depth int) error
+	appendAbove = func(p *issues_model.PullRequest, depth int) error {
+		if depth-len(below) > stackMaxDepth {
+			return nil
+		}
+		above, err := getStackedPullRequests(p)
+		if err != nil {
+			return err
+		}
+		for _, a := range above {
+			if visited[a.ID] {
+				continue
+			}
+			visited[a.ID] = true
+			stack = append(stack, &StackedPullRequest{PullRequest: a, Depth: depth})
+			if err := appendAbove(a, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := appendAbove(pr, len(below)+1); err != nil {
+		return nil, err
+	}
+
+	if len(stack) == 1 {
+		return nil, nil
+	}
+	for _, p := range stack {
+		if err := p.LoadIssueCtx(ctx); err != nil {
+			return nil, err
+		}
+		if err := p.Issue.LoadRepo(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return stack, nil
+}
+
+// retargetStackedPullRequests retargets the pull requests stacked on a merged pull request to its base branch
+// and rebases them on it, else they would target a branch which is usually deleted after the merge
+func retargetStackedPullRequests(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User) {
+	stacked, err := getStackedPullRequests(pr)
+	if err != nil {
+		log.Error("getStackedPullRequests[%d]: %v", pr.ID, err)
+		return
+	}
+
+	for _, p := range stacked {
+		if err := retargetStackedPullRequest(ctx, p, doer, pr.HeadBranch, pr.BaseBranch, pr.GetGitRefName()); err != nil {
+			log.Error("retargetStackedPullRequest[%d] of PullRequest[%d]: %v", p.ID, pr.ID, err)
+		}
+	}
+}
+
+// retargetStackedPullRequest retargets a pull request from oldBranch to targetBranch, then rebases the commits
+// which are not reachable from mergedRef, the head of the merged pull request, on targetBranch
+func retargetStackedPullRequest(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, oldBranch, targetBranch, mergedRef string) error {
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		return fmt.Errorf("LoadRepo: %v", err)
+	}
+
+	if err := ChangeTargetBranch(ctx, pr, doer, targetBranch); err != nil {
+		return fmt.Errorf("ChangeTargetBranch: %v", err)
+	}
+	notification.NotifyPullRequestChangeTargetBranch(doer, pr, oldBranch)
+
+	if pr.CommitsBehind == 0 {
+		return nil
+	}
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		return fmt.Errorf("LoadHeadRepo: %v", err)
+	} else if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	_, rebaseAllowed, err := IsUserAllowedToUpdate(ctx, pr, doer)
+	if err != nil {
+		return fmt.Errorf("IsUserAllowedToUpdate: %v", err)
+	}
+	if !rebaseAllowed {
+		log.Trace("PullRequest[%d] was retargeted to %s but %s is not allowed to rebase it", pr.ID, targetBranch, doer.Name)
+		return nil
+	}
+	// only replay the own commits of the pull request, the commits of the merged pull request may have
+	// been squashed or rebased and would conflict with their result
+	if err := update(ctx, pr, doer, "", true, mergedRef); err != nil {
+		// the pull request keeps its conflicts, its author resolves them
+		log.Warn("Unable to rebase the stacked PullRequest[%d] on %s: %v", pr.ID, targetBranch, err)
+	}
+	return nil
+}
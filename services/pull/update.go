@@ -21,6 +21,12 @@ import (
 
 // Update updates pull request with base branch.
 func Update(ctx context.Context, pull *issues_model.PullRequest, doer *user_model.User, message string, rebase bool) error {
+	return update(ctx, pull, doer, message, rebase, "")
+}
+
+// update updates pull request with base branch, a rebase only replays the commits of the head branch
+// which are not reachable from upstreamRef of the base repository if it is set
+func update(ctx context.Context, pull *issues_model.PullRequest, doer *user_model.User, message string, rebase bool, upstreamRef string) error {
 	var (
 		pr    *issues_model.PullRequest
 		style repo_model.MergeStyle
@@ -63,7 +69,7 @@ func Update(ctx context.Context, pull *issues_model.PullRequest, doer *user_mode
 		return fmt.Errorf("HeadBranch of PR %d is up to date", pull.Index)
 	}
 
-	_, err = rawMerge(ctx, pr, doer, style, "", message)
+	_, err = rawMergeOnto(ctx, pr, doer, style, "", message, pr.BaseBranch, pr.BaseBranch, upstreamRef)
 
 	defer func() {
 		if rebase {
@@ -0,0 +1,255 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pushpolicy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const (
+	// maxViolations is the maximum number of violations reported for a push
+	maxViolations = 10
+	// MaxScannedFileSize is the size above which the files aren't scanned for secrets
+	MaxScannedFileSize = 1024 * 1024
+)
+
+// Violation is a violation of a push policy rule by a pushed commit
+type Violation struct {
+	CommitID string
+	Message  string
+}
+
+func (v *Violation) String() string {
+	return fmt.Sprintf("%s: %s", base.ShortSha(v.CommitID), v.Message)
+}
+
+type checker struct {
+	ctx        context.Context
+	repo       *git.Repository
+	env        []string
+	policy     *git_model.PushPolicy
+	violations []*Violation
+}
+
+func (c *checker) isFull() bool {
+	return len(c.violations) >= maxViolations
+}
+
+func (c *checker) addViolation(commitID, format string, args ...interface{}) {
+	if !c.isFull() {
+		c.violations = append(c.violations, &Violation{CommitID: commitID, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// Check returns the violations of the policy by the commits a push adds to the repository.
+// The commits which are already reachable from a ref of the repository aren't checked again,
+// and the environment must give access to the quarantined objects of the push.
+func Check(ctx context.Context, repo *git.Repository, policy *git_model.PushPolicy, newCommitID string, env []string) ([]*Violation, error) {
+	if newCommitID == git.EmptySHA || policy.IsEmpty() {
+		return nil, nil
+	}
+
+	stdout, _, err := git.NewCommand(ctx, "rev-list", newCommitID, "--not", "--all").RunStdString(&git.RunOpts{Dir: repo.Path, Env: env})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the new commits of %s: %w", newCommitID, err)
+	}
+	commitIDs := strings.Fields(stdout)
+	if len(commitIDs) == 0 {
+		return nil, nil
+	}
+
+	c := &checker{ctx: ctx, repo: repo, env: env, policy: policy}
+	if policy.HasCommitRules() {
+		if err := c.checkCommits(commitIDs); err != nil {
+			return nil, err
+		}
+	}
+	if policy.HasFileRules() && !c.isFull() {
+		if err := c.checkFiles(commitIDs); err != nil {
+			return nil, err
+		}
+	}
+	return c.violations, nil
+}
+
+func (c *checker) checkCommits(commitIDs []string) error {
+	messageRegexp := c.policy.GetCommitMessageRegexp()
+	return c.catFileBatch(commitIDs, func(id string, content []byte) error {
+		commit, err := git.CommitFromReader(c.repo, git.MustIDFromString(id), bytes.NewReader(content))
+		if err != nil {
+			return err
+		}
+		if c.policy.RequireSignedCommits && !asymkey_model.ParseCommitWithSignature(commit).Verified {
+			c.addViolation(id, "the commit isn't signed with a verified key")
+		}
+		if messageRegexp != nil && !messageRegexp.MatchString(commit.CommitMessage) {
+			c.addViolation(id, "the commit message doesn't match the pattern %s", messageRegexp)
+		}
+		if !c.policy.IsEmailAllowed(commit.Author.Email) {
+			c.addViolation(id, "the author email %s isn't in an allowed domain", commit.Author.Email)
+		}
+		return nil
+	})
+}
+
+func (c *checker) checkFiles(commitIDs []string) error {
+	files, err := git.GetChangedFiles(c.ctx, c.repo.Path, c.env, commitIDs)
+	if err != nil {
+		return err
+	}
+
+	patterns := c.policy.GetForbiddenPathPatterns()
+	exts := c.policy.GetForbiddenExtensions()
+	blobs := make(map[string]*git.ChangedFile, len(files))
+	blobIDs := make([]string, 0, len(files))
+	for _, file := range files {
+		lowerPath := strings.ToLower(file.Path)
+		for _, pattern := range patterns {
+			if pattern.Match(lowerPath) {
+				c.addViolation(file.CommitID, "the path %s is forbidden", file.Path)
+				break
+			}
+		}
+		if ext := path.Ext(lowerPath); ext != "" {
+			for _, forbidden := range exts {
+				if ext == forbidden {
+					c.addViolation(file.CommitID, "the file %s has the forbidden extension %s", file.Path, ext)
+					break
+				}
+			}
+		}
+		if file.BlobID != "" && blobs[file.BlobID] == nil {
+			blobs[file.BlobID] = file
+			blobIDs = append(blobIDs, file.BlobID)
+		}
+	}
+
+	secretPatterns := c.policy.GetSecretPatterns()
+	if c.isFull() || len(blobIDs) == 0 || (c.policy.MaxFileSize == 0 && len(secretPatterns) == 0) {
+		return nil
+	}
+
+	sizes, err := c.blobSizes(blobIDs)
+	if err != nil {
+		return err
+	}
+	scannedIDs := make([]string, 0, len(blobIDs))
+	for _, id := range blobIDs {
+		size := sizes[id]
+		if c.policy.MaxFileSize > 0 && size > c.policy.MaxFileSize {
+			c.addViolation(blobs[id].CommitID, "the file %s is %s, larger than the limit of %s", blobs[id].Path, base.FileSize(size), base.FileSize(c.policy.MaxFileSize))
+		}
+		if len(secretPatterns) > 0 && size <= MaxScannedFileSize {
+			scannedIDs = append(scannedIDs, id)
+		}
+	}
+	if c.isFull() || len(scannedIDs) == 0 {
+		return nil
+	}
+
+	return c.catFileBatch(scannedIDs, func(id string, content []byte) error {
+		// the binary files aren't scanned, like git does to detect them only the beginning is checked
+		if bytes.IndexByte(content[:util.Min(len(content), 8000)], 0) >= 0 {
+			return nil
+		}
+		file := blobs[id]
+		for i, line := range bytes.Split(content, []byte{'\n'}) {
+			for _, pattern := range secretPatterns {
+				if pattern.Regexp.Match(line) {
+					// the secret itself is never part of the message
+					c.addViolation(file.CommitID, "the file %s contains a secret matching %s at line %d", file.Path, pattern.Pattern, i+1)
+					return nil
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (c *checker) blobSizes(ids []string) (map[string]int64, error) {
+	stdout, _, err := git.NewCommand(c.ctx, "cat-file", "--batch-check").
+		RunStdString(&git.RunOpts{
+			Dir:   c.repo.Path,
+			Env:   c.env,
+			Stdin: strings.NewReader(strings.Join(ids, "\n") + "\n"),
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the sizes of the new files: %w", err)
+	}
+
+	sizes := make(map[string]int64, len(ids))
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected cat-file output: %q", line)
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected cat-file output: %q", line)
+		}
+		sizes[fields[0]] = size
+	}
+	return sizes, nil
+}
+
+// catFileBatch reads the objects with git cat-file --batch, which unlike the batch of the repository
+// runs in the environment of the push and can read the quarantined objects
+func (c *checker) catFileBatch(ids []string, fn func(id string, content []byte) error) error {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+
+	return git.NewCommand(c.ctx, "cat-file", "--batch").
+		Run(&git.RunOpts{
+			Dir:    c.repo.Path,
+			Env:    c.env,
+			Stdin:  strings.NewReader(strings.Join(ids, "\n") + "\n"),
+			Stdout: stdoutWriter,
+			PipelineFunc: func(ctx context.Context, cancel context.CancelFunc) error {
+				_ = stdoutWriter.Close()
+				defer func() {
+					_ = stdoutReader.Close()
+				}()
+				rd := bufio.NewReader(stdoutReader)
+				for range ids {
+					id, _, size, err := git.ReadBatchLine(rd)
+					if err != nil {
+						cancel()
+						return err
+					}
+					// the content is followed by a newline
+					content := make([]byte, size+1)
+					if _, err := io.ReadFull(rd, content); err != nil {
+						cancel()
+						return err
+					}
+					if err := fn(string(id), content[:size]); err != nil {
+						cancel()
+						return err
+					}
+				}
+				return nil
+			},
+		})
+}
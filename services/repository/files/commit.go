@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/services/automerge"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 // CreateCommitStatus creates a new CommitStatus given a bunch of parameters
@@ -51,6 +52,10 @@ func CreateCommitStatus(ctx context.Context, repo *repo_model.Repository, creato
 		}
 	}
 
+	if err := pull_service.MergeQueueCommitStatusUpdated(ctx, repo, sha); err != nil {
+		return fmt.Errorf("MergeQueueCommitStatusUpdated[repo_id: %d, user_id: %d, sha: %s]: %w", repo.ID, creator.ID, sha, err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,339 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/scim"
+	"code.gitea.io/gitea/modules/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
+
+	"xorm.io/builder"
+)
+
+// teamNamePattern matches the names the team forms accept
+var teamNamePattern = regexp.MustCompile(`^[\w.-]{1,30}$`)
+
+// GetOrganization returns the organization whose teams are the SCIM groups
+func GetOrganization(ctx context.Context) (*organization.Organization, error) {
+	if setting.SCIM.Organization == "" {
+		return nil, scim.NewError(http.StatusNotImplemented, "", "groups are not available because no organization is configured")
+	}
+	return organization.GetOrgByName(setting.SCIM.Organization)
+}
+
+// ToGroup converts a team to the SCIM group resource
+func ToGroup(ctx context.Context, t *organization.Team) (*scim.Group, error) {
+	if err := t.GetMembersCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	members := make([]scim.Member, 0, len(t.Members))
+	for _, u := range t.Members {
+		members = append(members, scim.Member{
+			Value:   strconv.FormatInt(u.ID, 10),
+			Display: u.Name,
+			Ref:     ResourceLocation("User", u.ID),
+		})
+	}
+	return &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.FormatInt(t.ID, 10),
+		DisplayName: t.Name,
+		Members:     members,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Location:     ResourceLocation("Group", t.ID),
+		},
+	}, nil
+}
+
+// GetGroup returns the team of the organization with the SCIM id
+func GetGroup(ctx context.Context, org *organization.Organization, id string) (*organization.Team, error) {
+	teamID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, organization.ErrTeamNotExist{OrgID: org.ID, Name: id}
+	}
+	t, err := organization.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if t.OrgID != org.ID {
+		return nil, organization.ErrTeamNotExist{OrgID: org.ID, TeamID: teamID}
+	}
+	return t, nil
+}
+
+// FindGroups returns the teams of the organization matching the filter, startIndex is 1-based
+func FindGroups(ctx context.Context, org *organization.Organization, filter *scim.Filter, startIndex, count int) ([]*organization.Team, int64, error) {
+	cond := builder.NewCond().And(builder.Eq{"org_id": org.ID})
+	if filter != nil {
+		switch filter.Attribute {
+		case "id":
+			id, _ := strconv.ParseInt(filter.Value, 10, 64)
+			cond = cond.And(builder.Eq{"id": id})
+		case "displayname":
+			cond = cond.And(builder.Eq{"lower_name": strings.ToLower(filter.Value)})
+		case "externalid":
+			// external ids are not stored, so no team can match
+			return []*organization.Team{}, 0, nil
+		default:
+			return nil, 0, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidFilter, "unsupported filter attribute: %s", filter.Attribute)
+		}
+	}
+
+	teams := make([]*organization.Team, 0, count)
+	sess := db.GetEngine(ctx).Where(cond)
+	if count <= 0 {
+		total, err := sess.Count(new(organization.Team))
+		return teams, total, err
+	}
+	total, err := sess.OrderBy("id").Limit(count, startIndex-1).FindAndCount(&teams)
+	return teams, total, err
+}
+
+func validateTeamName(name string) error {
+	if !teamNamePattern.MatchString(name) {
+		return scim.InvalidValue("displayName must have at most 30 characters and only contain alphanumeric characters, dashes, underscores and dots: %s", name)
+	}
+	return nil
+}
+
+// CreateGroup creates a team in the organization.
+// The team can read all units of the repositories which are added to it in Gitea.
+func CreateGroup(ctx context.Context, doer *user_model.User, remoteAddr string, org *organization.Organization, sg *scim.Group) (*organization.Team, error) {
+	if err := validateTeamName(sg.DisplayName); err != nil {
+		return nil, err
+	}
+	memberIDs, err := memberIDs(ctx, sg.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &organization.Team{
+		OrgID:      org.ID,
+		Name:       sg.DisplayName,
+		AccessMode: perm.AccessModeRead,
+		Units:      make([]*organization.TeamUnit, 0, len(unit.AllRepoUnitTypes)),
+	}
+	for _, tp := range unit.AllRepoUnitTypes {
+		t.Units = append(t.Units, &organization.TeamUnit{
+			OrgID:      org.ID,
+			Type:       tp,
+			AccessMode: perm.AccessModeRead,
+		})
+	}
+	if err := models.NewTeam(t); err != nil {
+		if organization.IsErrTeamAlreadyExist(err) {
+			return nil, scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, "a group with this displayName already exists: %s", sg.DisplayName)
+		}
+		if db.IsErrNameReserved(err) {
+			return nil, scim.InvalidValue("%v", err)
+		}
+		return nil, err
+	}
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamCreate, doer, remoteAddr, t, nil, audit_service.TeamStateOf(t))
+
+	return t, setMembers(ctx, doer, remoteAddr, t, memberIDs, scim.PatchOpAdd)
+}
+
+// ReplaceGroup renames the team and replaces its members
+func ReplaceGroup(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team, sg *scim.Group) error {
+	memberIDs, err := memberIDs(ctx, sg.Members)
+	if err != nil {
+		return err
+	}
+	if err := renameGroup(ctx, doer, remoteAddr, t, sg.DisplayName); err != nil {
+		return err
+	}
+	return setMembers(ctx, doer, remoteAddr, t, memberIDs, scim.PatchOpReplace)
+}
+
+// PatchGroup applies the patch operations to the team, only the name and the members can be changed
+func PatchGroup(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team, ops []scim.PatchOperation) error {
+	for i := range ops {
+		op := &ops[i]
+		if err := checkPatchOp(op); err != nil {
+			return err
+		}
+		kind := strings.ToLower(op.Op)
+
+		path, err := scim.ParsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		if path.Attribute != "" {
+			if err := patchGroupAttribute(ctx, doer, remoteAddr, t, kind, path, op.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// without path the value contains the attributes to change
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return scim.InvalidValue("the value of an operation without path must be an object")
+		}
+		for attr, value := range values {
+			path, err := scim.ParsePath(attr)
+			if err != nil {
+				return err
+			}
+			if err := patchGroupAttribute(ctx, doer, remoteAddr, t, kind, path, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func patchGroupAttribute(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team, kind string, path *scim.Path, value interface{}) error {
+	switch path.Attribute {
+	case "displayname":
+		if kind == scim.PatchOpRemove {
+			return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "displayName is required")
+		}
+		var name string
+		if err := scim.DecodeValue(path.Attribute, value, &name); err != nil {
+			return err
+		}
+		return renameGroup(ctx, doer, remoteAddr, t, name)
+	case "members":
+		var members []scim.Member
+		if path.Filter != nil {
+			if path.Filter.Attribute != "value" || kind != scim.PatchOpRemove {
+				return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidPath, "members can only be removed by value")
+			}
+			members = []scim.Member{{Value: path.Filter.Value}}
+		} else if value != nil {
+			if err := scim.DecodeValue(path.Attribute, value, &members); err != nil {
+				return err
+			}
+		} else if kind == scim.PatchOpRemove {
+			// removing without value removes all members
+			kind = scim.PatchOpReplace
+		}
+		ids, err := memberIDs(ctx, members)
+		if err != nil {
+			return err
+		}
+		return setMembers(ctx, doer, remoteAddr, t, ids, kind)
+	}
+	return nil
+}
+
+// DeleteGroup deletes the team, the owners team can't be deleted
+func DeleteGroup(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team) error {
+	if t.IsOwnerTeam() {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "the owners team can't be deleted")
+	}
+	if err := t.GetUnits(); err != nil {
+		return err
+	}
+	before := audit_service.TeamStateOf(t)
+	if err := models.DeleteTeam(t); err != nil {
+		return err
+	}
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamDelete, doer, remoteAddr, t, before, nil)
+	return nil
+}
+
+func renameGroup(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team, name string) error {
+	if name == t.Name {
+		return nil
+	}
+	if t.IsOwnerTeam() {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "the owners team can't be renamed")
+	}
+	if err := validateTeamName(name); err != nil {
+		return err
+	}
+	if err := t.GetUnits(); err != nil {
+		return err
+	}
+
+	before := audit_service.TeamStateOf(t)
+	t.Name = name
+	if err := models.UpdateTeam(t, false, false); err != nil {
+		if organization.IsErrTeamAlreadyExist(err) {
+			return scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, "a group with this displayName already exists: %s", name)
+		}
+		return err
+	}
+	audit_service.RecordTeam(ctx, audit_model.ActionTeamUpdate, doer, remoteAddr, t, before, audit_service.TeamStateOf(t))
+	return nil
+}
+
+// memberIDs resolves the referenced users
+func memberIDs(ctx context.Context, members []scim.Member) ([]int64, error) {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		u, err := GetUser(ctx, m.Value)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				return nil, scim.InvalidValue("unknown member: %s", m.Value)
+			}
+			return nil, err
+		}
+		ids = append(ids, u.ID)
+	}
+	return ids, nil
+}
+
+// setMembers adds, removes or replaces the members of the team
+func setMembers(ctx context.Context, doer *user_model.User, remoteAddr string, t *organization.Team, ids []int64, kind string) error {
+	if err := t.GetMembersCtx(ctx); err != nil {
+		return err
+	}
+	current := make(map[int64]*user_model.User, len(t.Members))
+	for _, u := range t.Members {
+		current[u.ID] = u
+	}
+	requested := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		requested[id] = true
+	}
+
+	if kind == scim.PatchOpAdd || kind == scim.PatchOpReplace {
+		for _, id := range ids {
+			if current[id] != nil {
+				continue
+			}
+			if err := models.AddTeamMember(t, id); err != nil {
+				return err
+			}
+			if u, err := user_model.GetUserByIDCtx(ctx, id); err == nil {
+				audit_service.RecordTeamMember(ctx, doer, remoteAddr, t, u, true)
+			}
+		}
+	}
+
+	for id, u := range current {
+		remove := (kind == scim.PatchOpRemove && requested[id]) || (kind == scim.PatchOpReplace && !requested[id])
+		if !remove {
+			continue
+		}
+		if err := models.RemoveTeamMember(t, id); err != nil {
+			if organization.IsErrLastOrgOwner(err) {
+				return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "the last owner can't be removed")
+			}
+			return err
+		}
+		audit_service.RecordTeamMember(ctx, doer, remoteAddr, t, u, false)
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/scim"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	audit_service "code.gitea.io/gitea/services/audit"
+
+	"xorm.io/builder"
+)
+
+// ResourceLocation returns the url of a SCIM resource
+func ResourceLocation(resourceType string, id int64) string {
+	return setting.AppURL + "api/scim/v2/" + resourceType + "s/" + strconv.FormatInt(id, 10)
+}
+
+// ToUser converts a user to the SCIM user resource.
+// A user is active if it is activated and allowed to sign in.
+func ToUser(u *user_model.User) *scim.User {
+	active := u.IsActive && !u.ProhibitLogin
+	created := u.CreatedUnix.AsTime()
+	updated := u.UpdatedUnix.AsTime()
+	return &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Name,
+		Name:        &scim.Name{Formatted: u.FullName},
+		DisplayName: u.FullName,
+		Emails:      []scim.Email{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &updated,
+			Location:     ResourceLocation("User", u.ID),
+		},
+	}
+}
+
+// GetUser returns the individual user with the SCIM id
+func GetUser(ctx context.Context, id string) (*user_model.User, error) {
+	uid, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, user_model.ErrUserNotExist{Name: id}
+	}
+	u, err := user_model.GetUserByIDCtx(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != user_model.UserTypeIndividual {
+		return nil, user_model.ErrUserNotExist{UID: uid}
+	}
+	return u, nil
+}
+
+// FindUsers returns the individual users matching the filter, startIndex is 1-based
+func FindUsers(ctx context.Context, filter *scim.Filter, startIndex, count int) ([]*user_model.User, int64, error) {
+	cond := builder.NewCond().And(builder.Eq{"type": user_model.UserTypeIndividual})
+	if filter != nil {
+		switch filter.Attribute {
+		case "id":
+			id, _ := strconv.ParseInt(filter.Value, 10, 64)
+			cond = cond.And(builder.Eq{"id": id})
+		case "username":
+			cond = cond.And(builder.Eq{"lower_name": strings.ToLower(filter.Value)})
+		case "emails", "emails.value":
+			cond = cond.And(builder.Eq{"email": strings.ToLower(filter.Value)})
+		case "displayname":
+			cond = cond.And(builder.Eq{"full_name": filter.Value})
+		case "externalid":
+			// external ids are not stored, so no user can match
+			return []*user_model.User{}, 0, nil
+		default:
+			return nil, 0, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidFilter, "unsupported filter attribute: %s", filter.Attribute)
+		}
+	}
+
+	users := make([]*user_model.User, 0, count)
+	sess := db.GetEngine(ctx).Where(cond)
+	if count <= 0 {
+		total, err := sess.Count(new(user_model.User))
+		return users, total, err
+	}
+	total, err := sess.OrderBy("id").Limit(count, startIndex-1).FindAndCount(&users)
+	return users, total, err
+}
+
+// userChange is the change of the attributes of a user requested by the identity provider, nil means unchanged
+type userChange struct {
+	UserName *string
+	FullName *string
+	Email    *string
+	Active   *bool
+}
+
+// CreateUser provisions a user.
+// The user gets a random password because the identity provider is expected to be used to sign in.
+func CreateUser(ctx context.Context, doer *user_model.User, remoteAddr string, su *scim.User) (*user_model.User, error) {
+	if su.UserName == "" {
+		return nil, scim.InvalidValue("userName is required")
+	}
+	email := su.PrimaryEmail()
+	if email == "" {
+		return nil, scim.InvalidValue("an email address is required")
+	}
+
+	passwd, err := util.CryptoRandomString(40)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &user_model.User{
+		Name:      su.UserName,
+		FullName:  su.FullName(),
+		Email:     email,
+		Passwd:    passwd,
+		LoginType: auth.Plain,
+	}
+	active := su.Active == nil || *su.Active
+	if err := user_model.CreateUser(u, &user_model.CreateUserOverwriteOptions{
+		IsActive: util.OptionalBoolOf(active),
+	}); err != nil {
+		return nil, err
+	}
+	if !active {
+		u.ProhibitLogin = true
+		if err := user_model.UpdateUserCols(ctx, u, "prohibit_login"); err != nil {
+			return nil, err
+		}
+	}
+
+	audit_service.Record(ctx, audit_model.ActionAdminUserCreate, doer, remoteAddr, audit_service.System(), audit_service.User(u), nil, audit_service.UserStateOf(u))
+	return u, nil
+}
+
+// ReplaceUser replaces the attributes of the user with the attributes of the SCIM user
+func ReplaceUser(ctx context.Context, doer *user_model.User, remoteAddr string, u *user_model.User, su *scim.User) error {
+	if su.UserName == "" {
+		return scim.InvalidValue("userName is required")
+	}
+	fullName := su.FullName()
+	change := &userChange{
+		UserName: &su.UserName,
+		FullName: &fullName,
+		Active:   su.Active,
+	}
+	if email := su.PrimaryEmail(); email != "" {
+		change.Email = &email
+	}
+	return updateUser(ctx, doer, remoteAddr, u, change)
+}
+
+// PatchUser applies the patch operations to the user.
+// Attributes which have no equivalent in Gitea are ignored because identity providers send them unasked.
+func PatchUser(ctx context.Context, doer *user_model.User, remoteAddr string, u *user_model.User, ops []scim.PatchOperation) error {
+	change := &userChange{}
+	name := &scim.Name{}
+	for i := range ops {
+		op := &ops[i]
+		if err := checkPatchOp(op); err != nil {
+			return err
+		}
+		if strings.EqualFold(op.Op, scim.PatchOpRemove) {
+			// none of the supported attributes can be removed
+			continue
+		}
+
+		path, err := scim.ParsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		if path.Attribute != "" {
+			if err := patchUserAttribute(change, name, path, op.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// without path the value contains the attributes to change
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return scim.InvalidValue("the value of an operation without path must be an object")
+		}
+		for attr, value := range values {
+			path, err := scim.ParsePath(attr)
+			if err != nil {
+				return err
+			}
+			if err := patchUserAttribute(change, name, path, value); err != nil {
+				return err
+			}
+		}
+	}
+	if change.FullName == nil {
+		if fullName := name.String(); fullName != "" {
+			change.FullName = &fullName
+		}
+	}
+
+	return updateUser(ctx, doer, remoteAddr, u, change)
+}
+
+func patchUserAttribute(change *userChange, name *scim.Name, path *scim.Path, value interface{}) error {
+	var s string
+	switch path.Attribute {
+	case "active":
+		// some identity providers send the flag as string
+		switch v := value.(type) {
+		case bool:
+			change.Active = &v
+		case string:
+			active, err := strconv.ParseBool(v)
+			if err != nil {
+				return scim.InvalidValue("invalid value for active: %s", v)
+			}
+			change.Active = &active
+		default:
+			return scim.InvalidValue("invalid value for active")
+		}
+	case "username":
+		if err := scim.DecodeValue(path.Attribute, value, &s); err != nil {
+			return err
+		}
+		change.UserName = &s
+	case "displayname":
+		if err := scim.DecodeValue(path.Attribute, value, &s); err != nil {
+			return err
+		}
+		change.FullName = &s
+	case "name":
+		return scim.DecodeValue(path.Attribute, value, name)
+	case "name.formatted":
+		return scim.DecodeValue(path.Attribute, value, &name.Formatted)
+	case "name.givenname":
+		return scim.DecodeValue(path.Attribute, value, &name.GivenName)
+	case "name.familyname":
+		return scim.DecodeValue(path.Attribute, value, &name.FamilyName)
+	case "emails":
+		if path.SubAttribute == "value" {
+			if err := scim.DecodeValue(path.Attribute, value, &s); err != nil {
+				return err
+			}
+		} else {
+			var emails []scim.Email
+			if _, ok := value.(map[string]interface{}); ok {
+				value = []interface{}{value}
+			}
+			if err := scim.DecodeValue(path.Attribute, value, &emails); err != nil {
+				return err
+			}
+			s = (&scim.User{Emails: emails}).PrimaryEmail()
+		}
+		if s != "" {
+			change.Email = &s
+		}
+	}
+	return nil
+}
+
+// DeactivateUser disables the user, the user can't sign in and its sessions and tokens stop working immediately
+func DeactivateUser(ctx context.Context, doer *user_model.User, remoteAddr string, u *user_model.User) error {
+	active := false
+	return updateUser(ctx, doer, remoteAddr, u, &userChange{Active: &active})
+}
+
+func updateUser(ctx context.Context, doer *user_model.User, remoteAddr string, u *user_model.User, change *userChange) error {
+	if change.UserName != nil && !strings.EqualFold(*change.UserName, u.Name) {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeMutability, "userName can't be changed")
+	}
+
+	before := audit_service.UserStateOf(u)
+	cols := make([]string, 0, 4)
+	emailChanged := false
+
+	if change.FullName != nil && *change.FullName != u.FullName {
+		u.FullName = *change.FullName
+		cols = append(cols, "full_name")
+	}
+	if change.Email != nil && !strings.EqualFold(*change.Email, u.Email) {
+		email := strings.ToLower(strings.TrimSpace(*change.Email))
+		if err := user_model.ValidateEmail(email); err != nil {
+			return scim.InvalidValue("%v", err)
+		}
+		used, err := user_model.IsEmailUsed(ctx, email)
+		if err != nil {
+			return err
+		}
+		if used {
+			return scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, "email address is already used: %s", email)
+		}
+		u.Email = email
+		cols = append(cols, "email")
+		emailChanged = true
+	}
+	if change.Active != nil && *change.Active != (u.IsActive && !u.ProhibitLogin) {
+		u.IsActive = *change.Active
+		u.ProhibitLogin = !*change.Active
+		cols = append(cols, "is_active", "prohibit_login")
+	}
+	if len(cols) == 0 {
+		return nil
+	}
+
+	if err := user_model.UpdateUser(ctx, u, emailChanged, cols...); err != nil {
+		return err
+	}
+
+	audit_service.Record(ctx, audit_model.ActionAdminUserUpdate, doer, remoteAddr, audit_service.System(), audit_service.User(u), before, audit_service.UserStateOf(u))
+	return nil
+}
+
+func checkPatchOp(op *scim.PatchOperation) error {
+	switch strings.ToLower(op.Op) {
+	case scim.PatchOpAdd, scim.PatchOpReplace, scim.PatchOpRemove:
+		return nil
+	default:
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, "unsupported patch operation: %s", op.Op)
+	}
+}
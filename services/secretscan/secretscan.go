@@ -0,0 +1,303 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secretscan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	repo_model "code.gitea.io/gitea/models/repo"
+	secretscan_model "code.gitea.io/gitea/models/secretscan"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	secretscan_module "code.gitea.io/gitea/modules/secretscan"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/typesniffer"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+func queueHandle(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		req := datum.(*secretscan_module.ScanRequest)
+		if err := scan(graceful.GetManager().ShutdownContext(), req); err != nil {
+			log.Error("Unable to scan %s of repo[%d] for secrets: %v", req.RefName, req.RepoID, err)
+		}
+	}
+	return nil
+}
+
+// Init starts the scanning of the pushed commits
+func Init() {
+	secretscan_module.StartScanning(queueHandle)
+}
+
+// scan reports the secrets in the files added by the commits of a ref update
+func scan(ctx context.Context, req *secretscan_module.ScanRequest) error {
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, req.RepoID)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	repoPath := repo.RepoPath()
+
+	commitIDs, err := newCommitIDs(ctx, repoPath, req)
+	if err != nil {
+		return err
+	}
+	files, err := git.GetChangedFiles(ctx, repoPath, nil, commitIDs)
+	if err != nil {
+		return err
+	}
+
+	// a blob is only read once even if it is added at several paths or by several commits
+	blobFiles := make(map[string][]*git.ChangedFile, len(files))
+	blobIDs := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.BlobID == "" {
+			continue
+		}
+		if _, ok := blobFiles[file.BlobID]; !ok {
+			blobIDs = append(blobIDs, file.BlobID)
+		}
+		blobFiles[file.BlobID] = append(blobFiles[file.BlobID], file)
+	}
+	if len(blobIDs) == 0 {
+		return nil
+	}
+
+	scannedIDs, err := filterBlobsBySize(ctx, repoPath, blobIDs)
+	if err != nil {
+		return err
+	}
+	if len(scannedIDs) == 0 {
+		return nil
+	}
+
+	blobFindings := make(map[string][]*secretscan_module.Finding, len(scannedIDs))
+	candidates := make([]string, 0, 10)
+	batchWriter, batchReader, cancel := git.CatFileBatch(ctx, repoPath)
+	defer cancel()
+	for _, id := range scannedIDs {
+		content, err := readBlob(batchWriter, batchReader, id)
+		if err != nil {
+			return err
+		}
+		if !typesniffer.DetectContentType(content).IsText() {
+			continue
+		}
+		findings := secretscan_module.Scan(content)
+		for _, finding := range findings {
+			if finding.Rule.ID == secretscan_module.RuleGiteaToken {
+				candidates = append(candidates, finding.Secret)
+			}
+		}
+		blobFindings[id] = findings
+	}
+
+	// the matches of the Gitea tokens are verified all at once, most of them are SHA1 sums which aren't tokens
+	tokens, err := handleGiteaTokens(ctx, candidates)
+	if err != nil {
+		return err
+	}
+
+	s := &scanner{ctx: ctx, repo: repo, tokens: tokens}
+	for _, id := range scannedIDs {
+		for _, file := range blobFiles[id] {
+			for _, finding := range blobFindings[id] {
+				if err := s.report(file, finding); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// newCommitIDs returns the commits added by the ref update, those already reachable from another ref were scanned before
+func newCommitIDs(ctx context.Context, repoPath string, req *secretscan_module.ScanRequest) ([]string, error) {
+	if req.NewCommitID == git.EmptySHA {
+		return nil, nil
+	}
+	cmd := git.NewCommand(ctx, "rev-list", req.NewCommitID)
+	if req.OldCommitID != git.EmptySHA {
+		cmd.AddArguments("^" + req.OldCommitID)
+	} else {
+		cmd.AddArguments("--not", "--exclude="+req.RefName, "--all")
+	}
+	stdout, _, err := cmd.RunStdString(&git.RunOpts{Dir: repoPath})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the new commits of %s: %w", req.NewCommitID, err)
+	}
+	return strings.Fields(stdout), nil
+}
+
+// filterBlobsBySize returns the blobs which aren't too large to be scanned
+func filterBlobsBySize(ctx context.Context, repoPath string, ids []string) ([]string, error) {
+	checkWriter, checkReader, cancel := git.CatFileBatchCheck(ctx, repoPath)
+	defer cancel()
+
+	filtered := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err := checkWriter.Write([]byte(id + "\n")); err != nil {
+			return nil, err
+		}
+		_, _, size, err := git.ReadBatchLine(checkReader)
+		if err != nil {
+			return nil, err
+		}
+		if size <= setting.SecretScanning.MaxFileSize {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+func readBlob(batchWriter git.WriteCloserError, batchReader *bufio.Reader, id string) ([]byte, error) {
+	if _, err := batchWriter.Write([]byte(id + "\n")); err != nil {
+		return nil, err
+	}
+	_, _, size, err := git.ReadBatchLine(batchReader)
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(io.LimitReader(batchReader, size))
+	if err != nil {
+		return nil, err
+	}
+	// the content is followed by a newline
+	if _, err := batchReader.Discard(1); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+type scanner struct {
+	ctx  context.Context
+	repo *repo_model.Repository
+	// the Gitea tokens found among the matches, true if they were revoked
+	tokens map[string]bool
+}
+
+// report records the alert of a secret unless it was already reported,
+// the matches of the Gitea tokens are only reported if they are tokens of this instance
+func (s *scanner) report(file *git.ChangedFile, finding *secretscan_module.Finding) error {
+	alert := &secretscan_model.Alert{
+		RepoID:      s.repo.ID,
+		Fingerprint: secretscan_model.Fingerprint(finding.Rule.ID, file.Path, finding.Secret),
+		RuleID:      finding.Rule.ID,
+		Redacted:    secretscan_module.Redact(finding.Secret),
+		CommitID:    file.CommitID,
+		Path:        file.Path,
+		Line:        finding.Line,
+	}
+
+	if finding.Rule.ID == secretscan_module.RuleGiteaToken {
+		revoked, ok := s.tokens[finding.Secret]
+		if !ok {
+			return nil
+		}
+		alert.Revoked = revoked
+	}
+
+	inserted, err := secretscan_model.InsertAlert(s.ctx, alert)
+	if err != nil {
+		return err
+	}
+	if inserted {
+		log.Info("Secret scanning: found a %s in %s of %-v at %s", finding.Rule.Name, file.Path, s.repo, file.CommitID)
+	}
+	return nil
+}
+
+// handleGiteaTokens returns which of the secrets are valid access tokens or deploy tokens of this instance
+// and revokes them if allowed, the result tells for each token if it was revoked
+func handleGiteaTokens(ctx context.Context, secrets []string) (map[string]bool, error) {
+	tokens := make(map[string]bool)
+	if len(secrets) == 0 {
+		return tokens, nil
+	}
+
+	accessTokens, err := models.FindAccessTokensBySHA(ctx, secrets)
+	if err != nil {
+		return nil, err
+	}
+	for secret, t := range accessTokens {
+		if setting.SecretScanning.AutoRevokeTokens {
+			if err := revokeAccessToken(ctx, t); err != nil {
+				return nil, err
+			}
+		}
+		tokens[secret] = setting.SecretScanning.AutoRevokeTokens
+	}
+
+	deployTokens, err := auth_model.FindDeployTokensBySHA(ctx, secrets)
+	if err != nil {
+		return nil, err
+	}
+	for secret, t := range deployTokens {
+		if _, ok := tokens[secret]; ok {
+			continue
+		}
+		if setting.SecretScanning.AutoRevokeTokens {
+			if err := revokeDeployToken(ctx, t); err != nil {
+				return nil, err
+			}
+		}
+		tokens[secret] = setting.SecretScanning.AutoRevokeTokens
+	}
+	return tokens, nil
+}
+
+func revokeAccessToken(ctx context.Context, t *models.AccessToken) error {
+	owner, err := user_model.GetUserByIDCtx(ctx, t.UID)
+	if err != nil {
+		return err
+	}
+	if err := models.DeleteAccessTokenByID(t.ID, t.UID); err != nil {
+		return err
+	}
+	log.Info("Secret scanning: revoked the leaked access token %d of %s", t.ID, owner.Name)
+	audit_service.Record(ctx, audit_model.ActionAccessTokenDelete, nil, "", audit_service.User(owner), audit_service.AccessToken(t), nil, nil)
+	return nil
+}
+
+func revokeDeployToken(ctx context.Context, t *auth_model.DeployToken) error {
+	scope, err := deployTokenScope(ctx, t)
+	if err != nil {
+		return err
+	}
+	if err := auth_model.DeleteDeployToken(ctx, t.OwnerID, t.RepoID, t.ID); err != nil {
+		return err
+	}
+	log.Info("Secret scanning: revoked the leaked deploy token %d of %s", t.ID, scope.Name)
+	audit_service.Record(ctx, audit_model.ActionDeployTokenDelete, nil, "", scope, audit_service.DeployToken(t), nil, nil)
+	return nil
+}
+
+func deployTokenScope(ctx context.Context, t *auth_model.DeployToken) (audit_model.Object, error) {
+	if t.RepoID > 0 {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, t.RepoID)
+		if err != nil {
+			return audit_model.Object{}, err
+		}
+		return audit_service.Repository(repo), nil
+	}
+	owner, err := user_model.GetUserByIDCtx(ctx, t.OwnerID)
+	if err != nil {
+		return audit_model.Object{}, err
+	}
+	return audit_service.User(owner), nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+var (
+	// ErrAccessTokenNoScope is returned if a new restricted access token has no scope
+	ErrAccessTokenNoScope = errors.New("a restricted access token requires at least one scope")
+	// ErrAccessTokenRestrictedTwice is returned if a new access token is restricted to both a repository and an organization
+	ErrAccessTokenRestrictedTwice = errors.New("an access token can't be restricted to both a repository and an organization")
+	// ErrAccessTokenExpiryInPast is returned if a new access token would already be expired
+	ErrAccessTokenExpiryInPast = errors.New("the expiry date of an access token must be in the future")
+)
+
+// AccessTokenOptions are the options of a new personal access token
+type AccessTokenOptions struct {
+	Name         string
+	Scopes       []string
+	Repository   string // the full name of the repository the token is restricted to
+	Organization string // the name of the organization the token is restricted to
+	ExpiresAt    time.Time
+}
+
+// NewAccessToken validates the options and creates a personal access token of the user,
+// the user must be able to access the repository or be a member of the organization the token is restricted to.
+func NewAccessToken(ctx context.Context, doer *user_model.User, opts *AccessTokenOptions) (*models.AccessToken, error) {
+	scope, err := auth.ParseAccessTokenScope(opts.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	repoName, orgName := strings.TrimSpace(opts.Repository), strings.TrimSpace(opts.Organization)
+
+	// like the tokens created before the scopes existed, the tokens without a scope can access everything,
+	// which the restricted tokens can't
+	if scope == "" {
+		if repoName != "" || orgName != "" {
+			return nil, ErrAccessTokenNoScope
+		}
+		scope = auth.AccessTokenScopeAll
+	}
+
+	t := &models.AccessToken{
+		UID:   doer.ID,
+		Name:  opts.Name,
+		Scope: scope,
+	}
+
+	if !opts.ExpiresAt.IsZero() {
+		if !opts.ExpiresAt.After(time.Now()) {
+			return nil, ErrAccessTokenExpiryInPast
+		}
+		t.ExpiresUnix = timeutil.TimeStamp(opts.ExpiresAt.Unix())
+	}
+
+	if repoName != "" && orgName != "" {
+		return nil, ErrAccessTokenRestrictedTwice
+	}
+	if repoName != "" {
+		ownerName, name, _ := strings.Cut(repoName, "/")
+		repo, err := repo_model.GetRepositoryByOwnerAndNameCtx(ctx, ownerName, name)
+		if err != nil {
+			return nil, err
+		}
+		perm, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+		if err != nil {
+			return nil, err
+		}
+		if !perm.HasAccess() {
+			return nil, repo_model.ErrRepoNotExist{OwnerName: ownerName, Name: name}
+		}
+		t.RepoID = repo.ID
+	}
+	if orgName != "" {
+		org, err := organization.GetOrgByName(orgName)
+		if err != nil {
+			return nil, err
+		}
+		isMember, err := organization.IsOrganizationMember(ctx, org.ID, doer.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, organization.ErrOrgNotExist{Name: orgName}
+		}
+		t.OrgID = org.ID
+	}
+
+	if err := t.ValidateRestriction(); err != nil {
+		return nil, err
+	}
+
+	if err := models.NewAccessToken(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NotifyAndDeleteExpiredAccessTokens mails the owners of the access tokens expiring within the duration,
+// every owner is only notified once per token, and deletes the expired tokens
+func NotifyAndDeleteExpiredAccessTokens(ctx context.Context, notifyBefore time.Duration) error {
+	if notifyBefore > 0 {
+		tokens, err := models.GetAccessTokensToNotifyExpiry(ctx, timeutil.TimeStampNow().AddDuration(notifyBefore))
+		if err != nil {
+			return err
+		}
+
+		// the tokens are ordered by their owner
+		ids := make([]int64, 0, len(tokens))
+		for i := 0; i < len(tokens); {
+			j := i
+			for j < len(tokens) && tokens[j].UID == tokens[i].UID {
+				ids = append(ids, tokens[j].ID)
+				j++
+			}
+			if err := mailAccessTokensExpiry(ctx, tokens[i].UID, tokens[i:j]); err != nil {
+				return err
+			}
+			i = j
+		}
+		if len(ids) > 0 {
+			if err := models.SetAccessTokensExpiryNotified(ctx, ids); err != nil {
+				return err
+			}
+		}
+	}
+
+	count, err := models.DeleteExpiredAccessTokens(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Info("Deleted %d expired access tokens", count)
+	}
+	return nil
+}
+
+func mailAccessTokensExpiry(ctx context.Context, uid int64, tokens []*models.AccessToken) error {
+	expiring := make([]*models.AccessToken, 0, len(tokens))
+	for _, t := range tokens {
+		if !t.IsExpired() {
+			expiring = append(expiring, t)
+		}
+	}
+	if len(expiring) == 0 {
+		return nil
+	}
+
+	u, err := user_model.GetUserByIDCtx(ctx, uid)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return mailer.MailAccessTokensExpiry(u, expiring)
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAccessToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	user5 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+
+	token, err := NewAccessToken(db.DefaultContext, user2, &AccessTokenOptions{
+		Name:       "restricted-to-repo",
+		Scopes:     []string{"read:issue", "write:repository"},
+		Repository: "user2/repo1",
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "write:repository,read:issue", token.Scope)
+	assert.EqualValues(t, 1, token.RepoID)
+	assert.NotZero(t, token.ExpiresUnix)
+
+	// the tokens without a scope can access everything
+	token, err = NewAccessToken(db.DefaultContext, user2, &AccessTokenOptions{Name: "all-by-default"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, auth.AccessTokenScopeAll, token.Scope)
+
+	token, err = NewAccessToken(db.DefaultContext, user2, &AccessTokenOptions{
+		Name:         "restricted-to-org",
+		Scopes:       []string{"write:package"},
+		Organization: "user3",
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, token.OrgID)
+
+	for _, c := range []struct {
+		Doer  *user_model.User
+		Opts  *AccessTokenOptions
+		Check func(error) bool
+	}{
+		{user2, &AccessTokenOptions{Name: "no-scope", Repository: "user2/repo1"}, func(err error) bool { return err == ErrAccessTokenNoScope }},
+		{user2, &AccessTokenOptions{Name: "invalid-scope", Scopes: []string{"read:everything"}}, auth.IsErrInvalidAccessTokenScope},
+		{user2, &AccessTokenOptions{Name: "expired", Scopes: []string{"all"}, ExpiresAt: time.Now().Add(-time.Hour)}, func(err error) bool { return err == ErrAccessTokenExpiryInPast }},
+		{user2, &AccessTokenOptions{Name: "twice", Scopes: []string{"read:repository"}, Repository: "user2/repo1", Organization: "user3"}, func(err error) bool { return err == ErrAccessTokenRestrictedTwice }},
+		{user2, &AccessTokenOptions{Name: "all", Scopes: []string{"all"}, Repository: "user2/repo1"}, models.IsErrAccessTokenScopeNotRestrictable},
+		{user2, &AccessTokenOptions{Name: "package", Scopes: []string{"read:package"}, Repository: "user2/repo1"}, models.IsErrAccessTokenScopeNotRestrictable},
+		{user5, &AccessTokenOptions{Name: "private", Scopes: []string{"read:repository"}, Repository: "user2/repo2"}, repo_model.IsErrRepoNotExist},
+		{user5, &AccessTokenOptions{Name: "not-member", Scopes: []string{"read:repository"}, Organization: "user3"}, organization.IsErrOrgNotExist},
+	} {
+		_, err := NewAccessToken(db.DefaultContext, c.Doer, c.Opts)
+		assert.True(t, c.Check(err), "%s: %v", c.Opts.Name, err)
+		unittest.AssertNotExistsBean(t, &models.AccessToken{UID: c.Doer.ID, Name: c.Opts.Name})
+	}
+}
+
+func TestNotifyAndDeleteExpiredAccessTokens(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	expired := &models.AccessToken{UID: 2, Name: "expired", Scope: auth.AccessTokenScopeAll, ExpiresUnix: timeutil.TimeStampNow() - 60}
+	assert.NoError(t, models.NewAccessToken(expired))
+	expiring := &models.AccessToken{UID: 2, Name: "expiring", Scope: auth.AccessTokenScopeAll, ExpiresUnix: timeutil.TimeStampNow().AddDuration(48 * time.Hour)}
+	assert.NoError(t, models.NewAccessToken(expiring))
+	later := &models.AccessToken{UID: 2, Name: "later", Scope: auth.AccessTokenScopeAll, ExpiresUnix: timeutil.TimeStampNow().AddDuration(30 * 24 * time.Hour)}
+	assert.NoError(t, models.NewAccessToken(later))
+
+	assert.NoError(t, NotifyAndDeleteExpiredAccessTokens(db.DefaultContext, 7*24*time.Hour))
+	unittest.AssertNotExistsBean(t, &models.AccessToken{ID: expired.ID})
+	assert.True(t, unittest.AssertExistsAndLoadBean(t, &models.AccessToken{ID: expiring.ID}).IsExpiryNotified)
+	assert.False(t, unittest.AssertExistsAndLoadBean(t, &models.AccessToken{ID: later.ID}).IsExpiryNotified)
+	unittest.AssertExistsAndLoadBean(t, &models.AccessToken{ID: 1})
+}
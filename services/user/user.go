@@ -26,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
+	app_service "code.gitea.io/gitea/services/app"
 	"code.gitea.io/gitea/services/packages"
 )
 
@@ -132,6 +133,10 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) error {
 		}
 	}
 
+	if err := app_service.DeleteAppsByOwnerID(ctx, u.ID); err != nil {
+		return fmt.Errorf("DeleteAppsByOwnerID: %v", err)
+	}
+
 	ctx, committer, err := db.TxContext()
 	if err != nil {
 		return err
@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	secret_model "code.gitea.io/gitea/models/secret"
 	webhook_model "code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/hostmatcher"
@@ -31,6 +32,36 @@ import (
 	"github.com/gobwas/glob"
 )
 
+// addHookHeaders sets the additional headers of the webhook, expanding the secrets they reference.
+// It returns the values of the secrets available to the webhook.
+func addHookHeaders(ctx context.Context, w *webhook_model.Webhook, req *http.Request, info *webhook_model.HookRequest) (map[string]string, error) {
+	headers := w.HookHeaders()
+	if len(headers) == 0 {
+		return nil, nil
+	}
+
+	// a webhook can only use the secrets of its own scope: repository webhooks the secrets
+	// of the repository and organization webhooks those of the organization, so that
+	// a repository admin cannot read the organization secrets through a header.
+	// System and default webhooks have no access to secrets.
+	secrets := map[string]string{}
+	var err error
+	if w.RepoID > 0 {
+		secrets, err = secret_model.GetDecryptedSecrets(ctx, 0, w.RepoID)
+	} else if w.OrgID > 0 {
+		secrets, err = secret_model.GetDecryptedSecrets(ctx, w.OrgID, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range headers {
+		req.Header.Set(h.Name, secret_model.ExpandSecrets(h.Value, secrets))
+		info.Headers[http.CanonicalHeaderKey(h.Name)] = h.Value
+	}
+	return secrets, nil
+}
+
 // Deliver deliver hook task
 func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	w, err := webhook_model.GetWebhookByID(t.HookID)
@@ -171,6 +202,13 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		}
 	}()
 
+	// the additional headers are recorded before the secrets are expanded
+	secrets, err := addHookHeaders(ctx, w, req, t.RequestInfo)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("Headers: %v", err)
+		return err
+	}
+
 	if setting.DisableWebhooks {
 		return fmt.Errorf("webhook task skipped (webhooks disabled): [%d]", t.ID)
 	}
@@ -189,8 +227,9 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	// Status code is 20x can be seen as succeed.
 	t.IsSucceed = resp.StatusCode/100 == 2
 	t.ResponseInfo.Status = resp.StatusCode
+	// the receiver may echo the headers of the request
 	for k, vals := range resp.Header {
-		t.ResponseInfo.Headers[k] = strings.Join(vals, ",")
+		t.ResponseInfo.Headers[k] = secret_model.MaskSecrets(strings.Join(vals, ","), secrets)
 	}
 
 	p, err := io.ReadAll(resp.Body)
@@ -198,6 +237,7 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		t.ResponseInfo.Body = fmt.Sprintf("read body: %s", err)
 		return err
 	}
+	p = []byte(secret_model.MaskSecrets(string(p), secrets))
 	t.ResponseInfo.Body = string(p)
 	return nil
 }
@@ -6,9 +6,14 @@ package webhook
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"code.gitea.io/gitea/models/db"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unittest"
+	webhook_model "code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +43,102 @@ func TestWebhookProxy(t *testing.T) {
 		}
 	}
 }
+
+func TestAddHookHeaders(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, err := secret_model.SetSecret(db.DefaultContext, 0, 1, "TOKEN", "s3cr3t-value")
+	assert.NoError(t, err)
+
+	w := &webhook_model.Webhook{
+		RepoID:  1,
+		Headers: "Authorization: Bearer ${{ secrets.TOKEN }}\nx-custom: plain",
+	}
+	req, err := http.NewRequest("POST", "http://localhost/hook", nil)
+	assert.NoError(t, err)
+	info := &webhook_model.HookRequest{Headers: map[string]string{}}
+
+	secrets, err := addHookHeaders(db.DefaultContext, w, req, info)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer s3cr3t-value", req.Header.Get("Authorization"))
+	assert.Equal(t, "plain", req.Header.Get("X-Custom"))
+	// the recorded delivery does not contain the value of the secret
+	assert.Equal(t, "Bearer ${{ secrets.TOKEN }}", info.Headers["Authorization"])
+	assert.Equal(t, "echo: ***", secret_model.MaskSecrets("echo: s3cr3t-value", secrets))
+
+	// system webhooks have no access to the secrets of the repositories
+	w = &webhook_model.Webhook{
+		IsSystemWebhook: true,
+		Headers:         "Authorization: Bearer ${{ secrets.TOKEN }}",
+	}
+	req, err = http.NewRequest("POST", "http://localhost/hook", nil)
+	assert.NoError(t, err)
+	_, err = addHookHeaders(db.DefaultContext, w, req, &webhook_model.HookRequest{Headers: map[string]string{}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer ", req.Header.Get("Authorization"))
+
+	// repository webhooks have no access to the secrets of the owner of the repository
+	_, err = secret_model.SetSecret(db.DefaultContext, 3, 0, "ORG_SECRET", "org-value")
+	assert.NoError(t, err)
+	w = &webhook_model.Webhook{
+		RepoID:  32,
+		Headers: "X-Org: ${{ secrets.ORG_SECRET }}",
+	}
+	req, err = http.NewRequest("POST", "http://localhost/hook", nil)
+	assert.NoError(t, err)
+	_, err = addHookHeaders(db.DefaultContext, w, req, &webhook_model.HookRequest{Headers: map[string]string{}})
+	assert.NoError(t, err)
+	assert.Equal(t, "", req.Header.Get("X-Org"))
+
+	// but the webhooks of the organization do
+	w = &webhook_model.Webhook{
+		OrgID:   3,
+		Headers: "X-Org: ${{ secrets.ORG_SECRET }}",
+	}
+	req, err = http.NewRequest("POST", "http://localhost/hook", nil)
+	assert.NoError(t, err)
+	_, err = addHookHeaders(db.DefaultContext, w, req, &webhook_model.HookRequest{Headers: map[string]string{}})
+	assert.NoError(t, err)
+	assert.Equal(t, "org-value", req.Header.Get("X-Org"))
+}
+
+func TestDeliverMasksSecrets(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, err := secret_model.SetSecret(db.DefaultContext, 0, 1, "TOKEN", "s3cr3t-value")
+	assert.NoError(t, err)
+
+	// the receiver echoes the additional header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Echo", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	oldClient := webhookHTTPClient
+	webhookHTTPClient = server.Client()
+	defer func() {
+		webhookHTTPClient = oldClient
+	}()
+
+	w := &webhook_model.Webhook{
+		RepoID:      1,
+		URL:         server.URL,
+		HTTPMethod:  http.MethodPost,
+		ContentType: webhook_model.ContentTypeJSON,
+		IsActive:    true,
+		Headers:     "Authorization: Bearer ${{ secrets.TOKEN }}",
+		HookEvent:   &webhook_model.HookEvent{PushOnly: true},
+	}
+	assert.NoError(t, w.UpdateEvent())
+	assert.NoError(t, webhook_model.CreateWebhook(db.DefaultContext, w))
+	task := &webhook_model.HookTask{RepoID: 1, HookID: w.ID, PayloadContent: "{}", EventType: webhook_model.HookEventPush}
+	assert.NoError(t, db.Insert(db.DefaultContext, task))
+
+	assert.NoError(t, Deliver(db.DefaultContext, task))
+
+	task = unittest.AssertExistsAndLoadBean(t, &webhook_model.HookTask{ID: task.ID})
+	assert.True(t, task.IsSucceed)
+	assert.Equal(t, "Bearer ***", task.ResponseInfo.Headers["X-Echo"])
+	assert.Equal(t, "Bearer ***", task.ResponseInfo.Body)
+}
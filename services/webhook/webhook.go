@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 
+	app_model "code.gitea.io/gitea/models/app"
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	webhook_model "code.gitea.io/gitea/models/webhook"
@@ -227,6 +229,19 @@ func PrepareWebhooks(repo *repo_model.Repository, event webhook_model.HookEventT
 	return addToTask(repo.ID)
 }
 
+// appEventCategory returns the category of the permissions an app needs to receive the event
+func appEventCategory(event webhook_model.HookEventType) auth_model.AccessTokenScopeCategory {
+	switch event {
+	case webhook_model.HookEventIssues, webhook_model.HookEventIssueAssign, webhook_model.HookEventIssueLabel,
+		webhook_model.HookEventIssueMilestone, webhook_model.HookEventIssueComment:
+		return auth_model.AccessTokenScopeCategoryIssue
+	case webhook_model.HookEventPackage:
+		return auth_model.AccessTokenScopeCategoryPackage
+	default:
+		return auth_model.AccessTokenScopeCategoryRepository
+	}
+}
+
 func prepareWebhooks(ctx context.Context, repo *repo_model.Repository, event webhook_model.HookEventType, p api.Payloader) error {
 	ws, err := webhook_model.ListWebhooksByOpts(ctx, &webhook_model.ListWebhookOptions{
 		RepoID:   repo.ID,
@@ -247,6 +262,26 @@ func prepareWebhooks(ctx context.Context, repo *repo_model.Repository, event web
 			return fmt.Errorf("GetActiveWebhooksByOrgID: %v", err)
 		}
 		ws = append(ws, orgHooks...)
+
+		// get hooks of the apps installed on the repository
+		apps, err := app_model.GetAppsInstalledOnRepo(ctx, repo.OwnerID, repo.ID)
+		if err != nil {
+			return fmt.Errorf("GetAppsInstalledOnRepo: %v", err)
+		}
+		for _, a := range apps {
+			// an app only receives the events it could read with its installation tokens
+			if !a.Permissions.HasLevel(appEventCategory(event), auth_model.AccessTokenScopeLevelRead) {
+				continue
+			}
+			appHooks, err := webhook_model.ListWebhooksByOpts(ctx, &webhook_model.ListWebhookOptions{
+				AppID:    a.ID,
+				IsActive: util.OptionalBoolTrue,
+			})
+			if err != nil {
+				return fmt.Errorf("GetActiveWebhooksByAppID: %v", err)
+			}
+			ws = append(ws, appHooks...)
+		}
 	}
 
 	// Add any admin-defined system webhooks
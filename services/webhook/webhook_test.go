@@ -7,6 +7,8 @@ package webhook
 import (
 	"testing"
 
+	app_model "code.gitea.io/gitea/models/app"
+	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	webhook_model "code.gitea.io/gitea/models/webhook"
@@ -77,6 +79,33 @@ func TestPrepareWebhooksBranchFilterNoMatch(t *testing.T) {
 	}
 }
 
+func TestPrepareWebhooksAppPermissions(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// the app is installed on the repository but can only read its issues
+	a := unittest.AssertExistsAndLoadBean(t, &app_model.App{ID: 1})
+	a.Permissions = "read:issue"
+	_, err := db.GetEngine(db.DefaultContext).ID(a.ID).Cols("permissions").Update(a)
+	assert.NoError(t, err)
+	w := &webhook_model.Webhook{
+		AppID:       a.ID,
+		URL:         "http://localhost/app",
+		HTTPMethod:  "POST",
+		ContentType: webhook_model.ContentTypeJSON,
+		IsActive:    true,
+		HookEvent:   &webhook_model.HookEvent{SendEverything: true},
+	}
+	assert.NoError(t, w.UpdateEvent())
+	assert.NoError(t, webhook_model.CreateWebhook(db.DefaultContext, w))
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3})
+	assert.NoError(t, PrepareWebhooks(repo, webhook_model.HookEventPush, &api.PushPayload{Commits: []*api.PayloadCommit{{}}}))
+	unittest.AssertNotExistsBean(t, &webhook_model.HookTask{HookID: w.ID, EventType: webhook_model.HookEventPush})
+
+	assert.NoError(t, PrepareWebhooks(repo, webhook_model.HookEventIssues, &api.IssuePayload{Action: api.HookIssueOpened, Issue: &api.Issue{}}))
+	unittest.AssertExistsAndLoadBean(t, &webhook_model.HookTask{HookID: w.ID, EventType: webhook_model.HookEventIssues})
+}
+
 // TODO TestHookTask_deliver
 
 // TODO TestDeliverHooks
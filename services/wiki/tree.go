@@ -0,0 +1,187 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package wiki
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// the names of the special pages, they apply to the pages of their directory and its subdirectories
+const (
+	SidebarPageName = "_Sidebar"
+	FooterPageName  = "_Footer"
+)
+
+// IsSidebarOrFooter returns true if the page is a sidebar or a footer
+func IsSidebarOrFooter(name string) bool {
+	base := path.Base(name)
+	return base == SidebarPageName || base == FooterPageName
+}
+
+// SpecialPageCandidates returns the names of the special pages which apply to the page,
+// from the directory of the page up to the root of the wiki
+func SpecialPageCandidates(name, special string) []string {
+	var candidates []string
+	for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		candidates = append(candidates, dir+"/"+special)
+	}
+	return append(candidates, special)
+}
+
+// PageEntry is a page of a wiki commit
+type PageEntry struct {
+	Name  string
+	Entry *git.TreeEntry
+}
+
+// PageList are the pages and the files of a wiki commit, including the ones in subdirectories
+type PageList struct {
+	Pages []*PageEntry
+	names map[string]bool
+	files map[string]bool
+}
+
+// ListPages lists the pages of a wiki commit, the names of the pages in subdirectories contain their directories
+func ListPages(commit *git.Commit) (*PageList, error) {
+	entries, err := commit.ListEntriesRecursive()
+	if err != nil {
+		return nil, err
+	}
+
+	list := &PageList{
+		Pages: make([]*PageEntry, 0, len(entries)),
+		names: make(map[string]bool, len(entries)),
+		files: make(map[string]bool, len(entries)),
+	}
+	for _, entry := range entries {
+		if !entry.IsRegular() {
+			continue
+		}
+		list.files[entry.Name()] = true
+		name, err := FilenameToName(entry.Name())
+		if err != nil {
+			if models.IsErrWikiInvalidFileName(err) {
+				continue
+			}
+			return nil, err
+		}
+		list.Pages = append(list.Pages, &PageEntry{Name: name, Entry: entry})
+		list.names[name] = true
+	}
+	return list, nil
+}
+
+// HasPage returns true if the wiki has a page of this name
+func (l *PageList) HasPage(name string) bool {
+	return l.names[NormalizeWikiName(name)]
+}
+
+// Names returns the names of the pages without the sidebars and the footers
+func (l *PageList) Names() []string {
+	names := make([]string, 0, len(l.Pages))
+	for _, page := range l.Pages {
+		if !IsSidebarOrFooter(page.Name) {
+			names = append(names, page.Name)
+		}
+	}
+	return names
+}
+
+// PageTreeNode is a page or a directory of the tree of the wiki pages
+type PageTreeNode struct {
+	Name     string // the last segment of the full name
+	FullName string
+	SubURL   string
+	IsPage   bool // false for the directories without a page of the same name
+	Children []*PageTreeNode
+}
+
+// Tree returns the tree of the pages, the segments of the page names are separated by slashes
+func (l *PageList) Tree() []*PageTreeNode {
+	root := &PageTreeNode{}
+	for _, name := range l.Names() {
+		node := root
+		segments := strings.Split(name, "/")
+		for i, segment := range segments {
+			var child *PageTreeNode
+			for _, c := range node.Children {
+				if c.Name == segment {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				fullName := strings.Join(segments[:i+1], "/")
+				child = &PageTreeNode{
+					Name:     segment,
+					FullName: fullName,
+					SubURL:   NameToSubURL(fullName),
+				}
+				node.Children = append(node.Children, child)
+			}
+			node = child
+		}
+		node.IsPage = true
+	}
+	sortPageTree(root.Children)
+	return root.Children
+}
+
+func sortPageTree(nodes []*PageTreeNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+	for _, node := range nodes {
+		sortPageTree(node.Children)
+	}
+}
+
+// Breadcrumbs returns the directories of the page from the root of the wiki,
+// the directories with a page of the same name are pages of the breadcrumbs
+func (l *PageList) Breadcrumbs(name string) []*PageTreeNode {
+	segments := strings.Split(name, "/")
+	breadcrumbs := make([]*PageTreeNode, 0, len(segments)-1)
+	for i := range segments[:len(segments)-1] {
+		fullName := strings.Join(segments[:i+1], "/")
+		breadcrumbs = append(breadcrumbs, &PageTreeNode{
+			Name:     segments[i],
+			FullName: fullName,
+			SubURL:   NameToSubURL(fullName),
+			IsPage:   l.HasPage(fullName),
+		})
+	}
+	return breadcrumbs
+}
+
+// BrokenLinks returns the links to wiki pages which don't exist,
+// the links are the sub-URLs collected while rendering the wiki content
+func (l *PageList) BrokenLinks(links []string) []string {
+	var broken []string
+	seen := make(map[string]bool, len(links))
+	for _, link := range links {
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		unescaped, err := url.PathUnescape(link)
+		if err != nil {
+			broken = append(broken, link)
+			continue
+		}
+		// the links to the files of the wiki are valid too
+		if l.HasPage(unescaped) || l.files[unescaped] {
+			continue
+		}
+		broken = append(broken, NormalizeWikiName(unescaped))
+	}
+	return broken
+}
@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/log"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/sync"
@@ -93,9 +94,11 @@ func InitWiki(ctx context.Context, repo *repo_model.Repository) error {
 func prepareWikiFileName(gitRepo *git.Repository, wikiName string) (bool, string, error) {
 	unescaped := wikiName + ".md"
 	escaped := NameToFilename(wikiName)
+	// the pages in subdirectories have the directories in their names
+	nested := strings.ReplaceAll(wikiName, " ", "-") + ".md"
 
-	// Look for both files
-	filesInIndex, err := gitRepo.LsTree("master", unescaped, escaped)
+	// Look for all files
+	filesInIndex, err := gitRepo.LsTree("master", unescaped, nested, escaped)
 	if err != nil {
 		if strings.Contains(err.Error(), "Not a valid object name master") {
 			return false, escaped, nil
@@ -114,6 +117,9 @@ func prepareWikiFileName(gitRepo *git.Repository, wikiName string) (bool, string
 			foundEscaped = true
 		}
 	}
+	if !foundEscaped && strings.Contains(wikiName, "/") && util.IsStringInSlice(nested, filesInIndex) {
+		return true, nested, nil
+	}
 
 	// If not return whether the escaped file exists, and the escaped filename to keep backwards compatibility.
 	return foundEscaped, escaped, nil
@@ -264,6 +270,7 @@ func updateWikiPage(ctx context.Context, doer *user_model.User, repo *repo_model
 		return fmt.Errorf("Push: %v", err)
 	}
 
+	code_indexer.UpdateWikiIndexer(repo)
 	return nil
 }
 
@@ -371,6 +378,7 @@ func DeleteWikiPage(ctx context.Context, doer *user_model.User, repo *repo_model
 		return fmt.Errorf("Push: %v", err)
 	}
 
+	code_indexer.UpdateWikiIndexer(repo)
 	return nil
 }
 
@@ -381,5 +389,6 @@ func DeleteWiki(ctx context.Context, repo *repo_model.Repository) error {
 	}
 
 	admin_model.RemoveAllWithNotice(ctx, "Delete repository wiki", repo.WikiPath())
+	code_indexer.UpdateWikiIndexer(repo)
 	return nil
 }
@@ -294,3 +294,58 @@ func TestPrepareWikiFileName_FirstPage(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Home.md", newWikiPath)
 }
+
+func TestListPages(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	gitRepo, err := git.OpenRepository(git.DefaultContext, repo.WikiPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit("master")
+	assert.NoError(t, err)
+
+	pages, err := ListPages(commit)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Home", "Page With Image", "Page With Spaced Name", "Unescaped File"}, pages.Names())
+	assert.True(t, pages.HasPage("Page-With-Image"))
+	assert.False(t, pages.HasPage("jpeg"))
+
+	assert.Equal(t, []string{"Missing", "docs/Missing Page", "Other Missing"}, pages.BrokenLinks([]string{
+		"Home", "Page-With-Spaced-Name", "Missing", "images/jpeg.jpg", "Missing", "docs%2FMissing%20Page", "Other-Missing",
+	}))
+}
+
+func TestPageListTree(t *testing.T) {
+	pages := &PageList{names: map[string]bool{}}
+	for _, name := range []string{"Home", "docs/setup/Install", "docs", "docs/_Sidebar", "api/Users", "Zebra"} {
+		pages.Pages = append(pages.Pages, &PageEntry{Name: name})
+		pages.names[name] = true
+	}
+
+	tree := pages.Tree()
+	if assert.Len(t, tree, 4) {
+		assert.Equal(t, "api", tree[0].Name)
+		assert.False(t, tree[0].IsPage)
+		assert.Equal(t, "docs", tree[1].Name)
+		assert.True(t, tree[1].IsPage)
+		if assert.Len(t, tree[1].Children, 1) {
+			setup := tree[1].Children[0]
+			assert.Equal(t, "docs/setup", setup.FullName)
+			assert.Equal(t, "docs%2Fsetup%2FInstall", setup.Children[0].SubURL)
+		}
+		assert.Equal(t, "Home", tree[2].Name)
+		assert.Equal(t, "Zebra", tree[3].Name)
+	}
+
+	breadcrumbs := pages.Breadcrumbs("docs/setup/Install")
+	if assert.Len(t, breadcrumbs, 2) {
+		assert.True(t, breadcrumbs[0].IsPage)
+		assert.Equal(t, "setup", breadcrumbs[1].Name)
+		assert.False(t, breadcrumbs[1].IsPage)
+	}
+	assert.Empty(t, pages.Breadcrumbs("Home"))
+
+	assert.Equal(t, []string{"docs/setup/_Sidebar", "docs/_Sidebar", "_Sidebar"}, SpecialPageCandidates("docs/setup/Install", SidebarPageName))
+	assert.Equal(t, []string{"_Footer"}, SpecialPageCandidates("Home", FooterPageName))
+	assert.True(t, IsSidebarOrFooter("docs/_Sidebar"))
+}
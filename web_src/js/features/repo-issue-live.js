@@ -0,0 +1,132 @@
+import initPullRequestMergeForm from './repo-issue-pr-form.js';
+
+const {notificationSettings} = window.config;
+
+// the key of a top-level item of the timeline, the merge box, the comment form and the items without an id are skipped
+function timelineItemKey(item) {
+  if (!item.matches('.timeline-item, .timeline-item-group') || item.matches('.merge.box, .form')) return '';
+  if (item.id) return item.id;
+  const el = item.querySelector('[id]');
+  return el ? el.id : '';
+}
+
+function isBeingEdited(el) {
+  if (el.contains(document.activeElement) && document.activeElement !== document.body) return true;
+  const editZone = el.querySelector('.edit-content-zone');
+  return editZone && !editZone.classList.contains('hide');
+}
+
+// insert the new comments, events and reviews, update the edited comments and remove the deleted ones
+function refreshTimeline(timeline, newTimeline) {
+  const items = new Map();
+  for (const item of timeline.children) {
+    const key = timelineItemKey(item);
+    if (key) items.set(key, item);
+  }
+
+  const newKeys = new Set();
+  let prev = null;
+  for (const newItem of Array.from(newTimeline.children)) {
+    const key = timelineItemKey(newItem);
+    if (!key) continue;
+    newKeys.add(key);
+
+    const item = items.get(key);
+    if (!item) {
+      const inserted = document.importNode(newItem, true);
+      if (prev) {
+        prev.after(inserted);
+      } else {
+        timeline.prepend(inserted);
+      }
+      prev = inserted;
+      continue;
+    }
+    prev = item;
+    if (isBeingEdited(item)) continue;
+
+    const content = item.querySelector('.render-content');
+    const newContent = newItem.querySelector('.render-content');
+    if (content && newContent && content.innerHTML !== newContent.innerHTML) {
+      content.innerHTML = newContent.innerHTML;
+      const rawContent = item.querySelector('.raw-content');
+      const newRawContent = newItem.querySelector('.raw-content');
+      if (rawContent && newRawContent) rawContent.textContent = newRawContent.textContent;
+    }
+  }
+
+  for (const [key, item] of items) {
+    if (!newKeys.has(key) && key.startsWith('issuecomment-') && !isBeingEdited(item)) {
+      item.remove();
+    }
+  }
+}
+
+// the merge box is replaced if it has changed and none of its merge forms is open
+function refreshMergeBox(newDoc) {
+  const box = document.querySelector('.timeline-item.merge.box');
+  const newBox = newDoc.querySelector('.timeline-item.merge.box');
+  if (!box || !newBox || box.contains(document.activeElement)) return;
+  const manualMergeFields = box.querySelector('.manually-merged-fields');
+  if (box.querySelector('textarea') || (manualMergeFields && manualMergeFields.style.display !== 'none')) return;
+  if (box.textContent.replace(/\s+/g, ' ') === newBox.textContent.replace(/\s+/g, ' ')) return;
+  box.replaceWith(document.importNode(newBox, true));
+  initPullRequestMergeForm();
+}
+
+function refreshStateLabel(newDoc) {
+  const selector = '#issue-title-wrapper ~ .ui.large.label';
+  const label = document.querySelector(selector);
+  const newLabel = newDoc.querySelector(selector);
+  if (label && newLabel) label.replaceWith(document.importNode(newLabel, true));
+}
+
+// the selected labels of the sidebar and of its menu are synchronized unless the menu is open
+function refreshLabels(newDoc) {
+  const menu = document.querySelector('.select-label');
+  if (!menu || menu.classList.contains('active')) return;
+
+  for (const item of document.querySelectorAll('.select-label .menu .item[data-id]')) {
+    const newItem = newDoc.querySelector(`.select-label .menu .item[data-id="${item.getAttribute('data-id')}"]`);
+    if (!newItem) continue;
+    const checked = newItem.classList.contains('checked');
+    item.classList.toggle('checked', checked);
+    item.querySelector('.octicon-check').classList.toggle('invisible', !checked);
+  }
+  for (const label of document.querySelectorAll('.ui.labels.list .item')) {
+    const newLabel = label.id ? newDoc.getElementById(label.id) : newDoc.querySelector('.ui.labels.list .no-select.item');
+    if (newLabel) label.classList.toggle('hide', newLabel.classList.contains('hide'));
+  }
+}
+
+async function refreshIssuePage() {
+  const response = await fetch(window.location.href, {headers: {'Accept': 'text/html'}});
+  if (!response.ok) return;
+  const newDoc = new DOMParser().parseFromString(await response.text(), 'text/html');
+
+  const timeline = document.querySelector('.ui.timeline[data-events-url]');
+  const newTimeline = newDoc.querySelector('.ui.timeline[data-events-url]');
+  if (!timeline || !newTimeline) return;
+
+  refreshTimeline(timeline, newTimeline);
+  refreshMergeBox(newDoc);
+  refreshStateLabel(newDoc);
+  refreshLabels(newDoc);
+}
+
+// the page of an issue or a pull request listens for its updates and refreshes its timeline, state, labels and merge box
+export function initRepoIssueLiveUpdates() {
+  const timeline = document.querySelector('.ui.timeline[data-events-url]');
+  if (!timeline || !window.EventSource || !(notificationSettings.EventSourceUpdateTime > 0)) return;
+
+  let refreshTimer = null;
+  const source = new EventSource(timeline.getAttribute('data-events-url'));
+  source.addEventListener('issue-update', () => {
+    // the updates are often sent in bursts, like a review and its comments
+    clearTimeout(refreshTimer);
+    refreshTimer = setTimeout(() => {
+      refreshIssuePage().catch((err) => console.error('unable to refresh the issue page', err));
+    }, 500);
+  });
+  window.addEventListener('beforeunload', () => source.close());
+}
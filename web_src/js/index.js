@@ -20,6 +20,7 @@ import {initAdminUserListSearchForm} from './features/admin-users.js';
 import {initMarkupAnchors} from './markup/anchors.js';
 import {initNotificationCount, initNotificationsTable} from './features/notification.js';
 import {initRepoIssueContentHistory} from './features/repo-issue-content.js';
+import {initRepoIssueLiveUpdates} from './features/repo-issue-live.js';
 import {initStopwatch} from './features/stopwatch.js';
 import {initFindFileInRepo} from './features/repo-findfile.js';
 import {initCommentContent, initMarkupContent} from './markup/content.js';
@@ -161,6 +162,7 @@ $(document).ready(() => {
   initRepoEditor();
   initRepoGraphGit();
   initRepoIssueContentHistory();
+  initRepoIssueLiveUpdates();
   initRepoIssueDue();
   initRepoIssueList();
   initRepoIssueReferenceRepositorySearch();